@@ -11,27 +11,41 @@ import (
 	"time"
 
 	"github.com/kislikjeka/moontrack/internal/infra/gateway/coingecko"
+	"github.com/kislikjeka/moontrack/internal/infra/gateway/evmrpc"
 	"github.com/kislikjeka/moontrack/internal/infra/gateway/zerion"
 	"github.com/kislikjeka/moontrack/internal/infra/postgres"
 	infraRedis "github.com/kislikjeka/moontrack/internal/infra/redis"
 	"github.com/kislikjeka/moontrack/internal/ledger"
 	"github.com/kislikjeka/moontrack/internal/module/adjustment"
 	"github.com/kislikjeka/moontrack/internal/module/defi"
+	"github.com/kislikjeka/moontrack/internal/module/derivatives"
 	"github.com/kislikjeka/moontrack/internal/module/genesis"
+	"github.com/kislikjeka/moontrack/internal/module/income"
 	"github.com/kislikjeka/moontrack/internal/module/lending"
 	"github.com/kislikjeka/moontrack/internal/module/liquidity"
+	"github.com/kislikjeka/moontrack/internal/module/nft"
 	"github.com/kislikjeka/moontrack/internal/module/portfolio"
+	"github.com/kislikjeka/moontrack/internal/module/sharing"
+	"github.com/kislikjeka/moontrack/internal/module/staking"
 	"github.com/kislikjeka/moontrack/internal/module/swap"
+	"github.com/kislikjeka/moontrack/internal/module/tagging"
+	"github.com/kislikjeka/moontrack/internal/module/taxanalysis"
+	"github.com/kislikjeka/moontrack/internal/module/taxprofile"
 	"github.com/kislikjeka/moontrack/internal/module/transactions"
 	"github.com/kislikjeka/moontrack/internal/module/transfer"
 	"github.com/kislikjeka/moontrack/internal/platform/asset"
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
+	"github.com/kislikjeka/moontrack/internal/platform/derivativeposition"
 	"github.com/kislikjeka/moontrack/internal/platform/lendingposition"
 	"github.com/kislikjeka/moontrack/internal/platform/lpposition"
+	"github.com/kislikjeka/moontrack/internal/platform/privacy"
+	"github.com/kislikjeka/moontrack/internal/platform/stakingposition"
 	"github.com/kislikjeka/moontrack/internal/platform/sync"
 	"github.com/kislikjeka/moontrack/internal/platform/taxlot"
 	"github.com/kislikjeka/moontrack/pkg/money"
 	"github.com/kislikjeka/moontrack/internal/platform/user"
 	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
 	"github.com/kislikjeka/moontrack/internal/transport/httpapi"
 	"github.com/kislikjeka/moontrack/internal/transport/httpapi/handler"
 	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
@@ -105,24 +119,29 @@ func main() {
 	userRepo := postgres.NewUserRepository(db.Pool)
 	ledgerRepo := postgres.NewLedgerRepository(db.Pool)
 	walletRepo := postgres.NewWalletRepository(db.Pool)
+	workspaceRepo := postgres.NewWorkspaceRepository(db.Pool)
+	auditRepo := postgres.NewAuditRepository(db.Pool)
 
 	// Initialize handler registry for transaction types
 	handlerRegistry := ledger.NewRegistry()
 
 	// Initialize services
-	userSvc := user.NewService(userRepo, log)
+	auditSvc := audit.NewService(auditRepo, log)
+	userSvc := user.NewService(userRepo, auditSvc, log)
 	jwtSvc := middleware.NewJWTService(cfg.JWTSecret)
 	ledgerSvc := ledger.NewService(ledgerRepo, handlerRegistry, log)
-	walletSvc := wallet.NewService(walletRepo, log)
+	workspaceSvc := workspace.NewService(workspaceRepo, workspace.NewLogSender(log), auditSvc, log)
+	walletSvc := wallet.NewService(walletRepo, workspaceSvc, auditSvc, log)
 
 	// Register tax lot hook (cost basis tracking)
 	taxLotRepo := postgres.NewTaxLotRepository(db.Pool)
 	taxLotHook := ledger.NewTaxLotHook(taxLotRepo, ledgerRepo, log)
 	ledgerSvc.RegisterPostBalanceHook(taxLotHook)
+	ledgerSvc.SetTaxLotRebuilder(ledger.NewTaxLotRebuilder(taxLotRepo, ledgerRepo, log))
 	log.Info("TaxLot hook registered")
 
 	// Initialize tax lot service (cost basis API)
-	taxLotSvc := taxlot.NewService(taxLotRepo, ledgerRepo, walletRepo, log)
+	taxLotSvc := taxlot.NewService(taxLotRepo, ledgerRepo, walletRepo, workspaceSvc, auditSvc, log)
 
 	// Register transaction handlers with the registry
 
@@ -132,33 +151,33 @@ func main() {
 	log.Info("Registered asset adjustment handler")
 
 	// Transfer handlers (blockchain-native transfers)
-	transferInHandler := transfer.NewTransferInHandler(walletRepo, log)
+	transferInHandler := transfer.NewTransferInHandler(walletRepo, workspaceSvc, log)
 	handlerRegistry.Register(transferInHandler)
 	log.Info("Registered transfer in handler")
 
-	transferOutHandler := transfer.NewTransferOutHandler(walletRepo, log)
+	transferOutHandler := transfer.NewTransferOutHandler(walletRepo, workspaceSvc, log)
 	handlerRegistry.Register(transferOutHandler)
 	log.Info("Registered transfer out handler")
 
-	internalTransferHandler := transfer.NewInternalTransferHandler(walletRepo, log)
+	internalTransferHandler := transfer.NewInternalTransferHandler(walletRepo, workspaceSvc, log)
 	handlerRegistry.Register(internalTransferHandler)
 	log.Info("Registered internal transfer handler")
 
 	// Swap handler (DEX token swaps)
-	swapHandler := swap.NewSwapHandler(walletRepo, log)
+	swapHandler := swap.NewSwapHandler(walletRepo, workspaceSvc, log)
 	handlerRegistry.Register(swapHandler)
 	log.Info("Registered swap handler")
 
 	// DeFi handlers (deposit, withdraw, claim)
-	defiDepositHandler := defi.NewDeFiDepositHandler(walletRepo, log)
+	defiDepositHandler := defi.NewDeFiDepositHandler(walletRepo, workspaceSvc, log)
 	handlerRegistry.Register(defiDepositHandler)
 	log.Info("Registered defi deposit handler")
 
-	defiWithdrawHandler := defi.NewDeFiWithdrawHandler(walletRepo, log)
+	defiWithdrawHandler := defi.NewDeFiWithdrawHandler(walletRepo, workspaceSvc, log)
 	handlerRegistry.Register(defiWithdrawHandler)
 	log.Info("Registered defi withdraw handler")
 
-	defiClaimHandler := defi.NewDeFiClaimHandler(walletRepo, log)
+	defiClaimHandler := defi.NewDeFiClaimHandler(walletRepo, workspaceSvc, log)
 	handlerRegistry.Register(defiClaimHandler)
 	log.Info("Registered defi claim handler")
 
@@ -168,45 +187,103 @@ func main() {
 	log.Info("Registered genesis balance handler")
 
 	// LP handlers (Uniswap V3 liquidity pool operations)
-	lpDepositHandler := liquidity.NewLPDepositHandler(walletRepo, log)
+	lpDepositHandler := liquidity.NewLPDepositHandler(walletRepo, workspaceSvc, log)
 	handlerRegistry.Register(lpDepositHandler)
 	log.Info("Registered LP deposit handler")
 
-	lpWithdrawHandler := liquidity.NewLPWithdrawHandler(walletRepo, log)
+	lpWithdrawHandler := liquidity.NewLPWithdrawHandler(walletRepo, workspaceSvc, log)
 	handlerRegistry.Register(lpWithdrawHandler)
 	log.Info("Registered LP withdraw handler")
 
-	lpClaimFeesHandler := liquidity.NewLPClaimFeesHandler(walletRepo, log)
+	lpClaimFeesHandler := liquidity.NewLPClaimFeesHandler(walletRepo, workspaceSvc, log)
 	handlerRegistry.Register(lpClaimFeesHandler)
 	log.Info("Registered LP claim fees handler")
 
-	// Lending handlers (AAVE supply, withdraw, borrow, repay, claim)
-	lendingSupplyHandler := lending.NewLendingSupplyHandler(walletRepo, log)
+	// Lending handlers (supply, withdraw, borrow, repay, claim, accrued interest, liquidation)
+	lendingSupplyHandler := lending.NewLendingSupplyHandler(walletRepo, workspaceSvc, log)
 	handlerRegistry.Register(lendingSupplyHandler)
 
-	lendingWithdrawHandler := lending.NewLendingWithdrawHandler(walletRepo, log)
+	lendingWithdrawHandler := lending.NewLendingWithdrawHandler(walletRepo, workspaceSvc, log)
 	handlerRegistry.Register(lendingWithdrawHandler)
 
-	lendingBorrowHandler := lending.NewLendingBorrowHandler(walletRepo, log)
+	lendingBorrowHandler := lending.NewLendingBorrowHandler(walletRepo, workspaceSvc, log)
 	handlerRegistry.Register(lendingBorrowHandler)
 
-	lendingRepayHandler := lending.NewLendingRepayHandler(walletRepo, log)
+	lendingRepayHandler := lending.NewLendingRepayHandler(walletRepo, workspaceSvc, log)
 	handlerRegistry.Register(lendingRepayHandler)
 
-	lendingClaimHandler := lending.NewLendingClaimHandler(walletRepo, log)
+	lendingClaimHandler := lending.NewLendingClaimHandler(walletRepo, workspaceSvc, log)
 	handlerRegistry.Register(lendingClaimHandler)
-	log.Info("Registered lending handlers (supply, withdraw, borrow, repay, claim)")
+
+	lendingInterestHandler := lending.NewLendingInterestHandler(walletRepo, workspaceSvc, log)
+	handlerRegistry.Register(lendingInterestHandler)
+
+	lendingBorrowInterestHandler := lending.NewLendingBorrowInterestHandler(walletRepo, workspaceSvc, log)
+	handlerRegistry.Register(lendingBorrowInterestHandler)
+
+	lendingLiquidationHandler := lending.NewLendingLiquidationHandler(walletRepo, workspaceSvc, log)
+	handlerRegistry.Register(lendingLiquidationHandler)
+	log.Info("Registered lending handlers (supply, withdraw, borrow, repay, claim, interest, liquidation)")
+
+	// Staking handlers (native and liquid staking, rewards)
+	stakeHandler := staking.NewStakeHandler(walletRepo, workspaceSvc, log)
+	handlerRegistry.Register(stakeHandler)
+
+	unstakeHandler := staking.NewUnstakeHandler(walletRepo, workspaceSvc, log)
+	handlerRegistry.Register(unstakeHandler)
+
+	stakingRewardHandler := staking.NewStakingRewardHandler(walletRepo, workspaceSvc, log)
+	handlerRegistry.Register(stakingRewardHandler)
+	log.Info("Registered staking handlers (stake, unstake, reward)")
+
+	// Derivatives handlers (perpetuals margin, realized PnL, funding)
+	derivativeOpenHandler := derivatives.NewDerivativeOpenHandler(walletRepo, workspaceSvc, log)
+	handlerRegistry.Register(derivativeOpenHandler)
+
+	derivativeCloseHandler := derivatives.NewDerivativeCloseHandler(walletRepo, workspaceSvc, log)
+	handlerRegistry.Register(derivativeCloseHandler)
+
+	derivativeFundingHandler := derivatives.NewDerivativeFundingHandler(walletRepo, workspaceSvc, log)
+	handlerRegistry.Register(derivativeFundingHandler)
+	log.Info("Registered derivatives handlers (open, close, funding)")
+
+	// NFT handlers (buy, sell, mint, transfers)
+	nftBuyHandler := nft.NewNFTBuyHandler(walletRepo, workspaceSvc, log)
+	handlerRegistry.Register(nftBuyHandler)
+
+	nftSellHandler := nft.NewNFTSellHandler(walletRepo, workspaceSvc, log)
+	handlerRegistry.Register(nftSellHandler)
+
+	nftMintHandler := nft.NewNFTMintHandler(walletRepo, workspaceSvc, log)
+	handlerRegistry.Register(nftMintHandler)
+
+	nftTransferInHandler := nft.NewNFTTransferInHandler(walletRepo, workspaceSvc, log)
+	handlerRegistry.Register(nftTransferInHandler)
+
+	nftTransferOutHandler := nft.NewNFTTransferOutHandler(walletRepo, workspaceSvc, log)
+	handlerRegistry.Register(nftTransferOutHandler)
+	log.Info("Registered NFT handlers (buy, sell, mint, transfer in, transfer out)")
 
 	// LP Position tracking
 	lpPositionRepo := postgres.NewLPPositionRepo(db.Pool)
-	lpPositionSvc := lpposition.NewService(lpPositionRepo, log)
+	lpPositionSvc := lpposition.NewService(lpPositionRepo, walletSvc, log)
 	log.Info("LP Position service initialized")
 
 	// Lending Position tracking
 	lendingPositionRepo := postgres.NewLendingPositionRepo(db.Pool)
-	lendingPositionSvc := lendingposition.NewService(lendingPositionRepo, log)
+	lendingPositionSvc := lendingposition.NewService(lendingPositionRepo, walletSvc, log)
 	log.Info("Lending Position service initialized")
 
+	// Staking Position tracking
+	stakingPositionRepo := postgres.NewStakingPositionRepo(db.Pool)
+	stakingPositionSvc := stakingposition.NewService(stakingPositionRepo, walletSvc, log)
+	log.Info("Staking Position service initialized")
+
+	// Derivative Position tracking
+	derivativePositionRepo := postgres.NewDerivativePositionRepo(db.Pool)
+	derivativePositionSvc := derivativeposition.NewService(derivativePositionRepo, walletSvc, log)
+	log.Info("Derivative Position service initialized")
+
 	// Initialize decimal resolver (cascading: assets table → zerion_assets table → hardcoded)
 	zerionAssetRepo := postgres.NewZerionAssetRepository(db.Pool)
 	assetDecimalSrc := asset.NewDecimalSource(assetRepo)
@@ -214,17 +291,48 @@ func main() {
 	decimalResolver := money.NewDecimalResolver(assetDecimalSrc, zerionDecimalSrc)
 	log.Info("Decimal resolver initialized")
 
+	// Zerion client backs blockchain sync and NFT floor prices
+	var zerionClient *zerion.Client
+	var nftFloorPrices portfolio.FloorPriceProvider
+	if cfg.ZerionAPIKey != "" {
+		zerionClient = zerion.NewClient(cfg.ZerionAPIKey, log)
+		nftFloorPrices = zerion.NewNFTPriceAdapter(zerionClient)
+	}
+
 	// Initialize portfolio service (using price adapter for symbol→CoinGecko resolution)
 	walletAdapter := portfolio.NewWalletRepositoryAdapter(walletRepo)
-	portfolioPriceAdapter := portfolio.NewPortfolioPriceAdapter(assetSvc)
+	portfolioPriceAdapter := portfolio.NewPortfolioPriceAdapter(assetSvc, nftFloorPrices)
 	wacAdapter := portfolio.NewWACAdapter(taxLotSvc)
 	portfolioSvc := portfolio.NewPortfolioService(ledgerRepo, walletAdapter, portfolioPriceAdapter, wacAdapter, decimalResolver)
+
+	// Initialize data export and account erasure
+	privacySvc := privacy.NewService(postgres.NewPrivacyRepository(db.Pool), userSvc, auditSvc, log)
+
+	// Initialize share links (read-only public snapshots)
+	shareLinkRepo := postgres.NewShareLinkRepository(db.Pool)
+	shareLinkSvc := sharing.NewService(shareLinkRepo, sharing.NewTokenSigner(cfg.JWTSecret), walletSvc, portfolioSvc, taxLotSvc, auditSvc, log)
 	log.Info("Portfolio service initialized")
 
 	// Initialize transaction service (read-only, for enriched views)
-	transactionSvc := transactions.NewTransactionService(ledgerSvc, walletRepo, decimalResolver)
+	transactionSvc := transactions.NewTransactionService(ledgerSvc, walletRepo, workspaceSvc, decimalResolver)
 	log.Info("Transaction service initialized")
 
+	// Initialize user tags and notes on transactions
+	tagSvc := tagging.NewService(postgres.NewTagRepository(db.Pool), transactionSvc, auditSvc, log)
+
+	// Initialize income classification and reporting
+	incomeSvc := income.NewService(postgres.NewIncomeRepository(db.Pool), ledgerSvc, walletRepo, workspaceSvc, auditSvc, log)
+
+	// Initialize wash-sale and loss-harvesting analysis
+	taxAnalysisSvc := taxanalysis.NewService(taxLotSvc, portfolioPriceAdapter, log)
+
+	// Initialize concentrated-liquidity range simulation and IL analysis
+	lpSimulator := lpposition.NewSimulator(lpPositionRepo, walletSvc, portfolioPriceAdapter, log)
+	lpPerformanceSvc := lpposition.NewPerformanceService(lpPositionRepo, walletSvc, portfolioPriceAdapter, log)
+
+	// Initialize tax profiles and the capital gains report
+	taxProfileSvc := taxprofile.NewService(postgres.NewTaxProfileRepository(db.Pool), taxLotSvc, auditSvc, log)
+
 	// Initialize blockchain sync service
 	var syncSvc *sync.Service
 	if cfg.ZerionAPIKey != "" {
@@ -236,13 +344,19 @@ func main() {
 		}
 		syncAssetAdapter := sync.NewSyncAssetAdapter(assetSvc)
 
-		zerionClient := zerion.NewClient(cfg.ZerionAPIKey, log)
 		zerionProvider := zerion.NewSyncAdapter(zerionClient)
 		log.Info("Zerion sync provider initialized")
 
 		rawTxRepo := postgres.NewRawTransactionRepository(db.Pool)
 
-		syncSvc = sync.NewService(syncConfig, walletRepo, ledgerSvc, syncAssetAdapter, log, zerionProvider, zerionProvider, rawTxRepo, zerionAssetRepo, lpPositionSvc, lendingPositionSvc)
+		// Uniswap V3 range and uncollected fees are read directly from chain nodes
+		var lpRangeProvider sync.LPRangeDataProvider
+		if len(cfg.EVMRPCURLs) > 0 {
+			lpRangeProvider = evmrpc.NewUniswapV3Reader(evmrpc.NewClient(cfg.EVMRPCURLs, log))
+			log.Info("EVM RPC LP range reader initialized", "chains", len(cfg.EVMRPCURLs))
+		}
+
+		syncSvc = sync.NewService(syncConfig, walletRepo, ledgerSvc, syncAssetAdapter, log, zerionProvider, zerionProvider, rawTxRepo, zerionAssetRepo, lpPositionSvc, lendingPositionSvc, stakingPositionSvc, derivativePositionSvc, lpRangeProvider)
 		log.Info("Sync service initialized",
 			"poll_interval", cfg.SyncPollInterval,
 			"provider", "zerion")
@@ -256,13 +370,23 @@ func main() {
 	if syncSvc != nil {
 		walletSyncSvc = syncSvc
 	}
-	walletHandler := handler.NewWalletHandler(walletSvc, walletSyncSvc)
-	transactionHandler := handler.NewTransactionHandler(ledgerSvc, transactionSvc, assetSvc)
+	walletHandler := handler.NewWalletHandler(walletSvc, walletSyncSvc, auditSvc)
+	transactionHandler := handler.NewTransactionHandler(ledgerSvc, transactionSvc, assetSvc, auditSvc)
 	portfolioHandler := handler.NewPortfolioHandler(portfolioSvc)
 	assetHandler := handler.NewAssetHandler(assetSvc)
 	taxLotHandler := handler.NewTaxLotHandler(taxLotSvc, decimalResolver)
-	lpPositionHTTPHandler := handler.NewLPPositionHandler(lpPositionSvc)
+	lpPositionHTTPHandler := handler.NewLPPositionHandler(lpPositionSvc, lpSimulator, lpPerformanceSvc)
 	lendingPositionHTTPHandler := handler.NewLendingPositionHandler(lendingPositionSvc)
+	stakingPositionHTTPHandler := handler.NewStakingPositionHandler(stakingPositionSvc)
+	derivativePositionHTTPHandler := handler.NewDerivativePositionHandler(derivativePositionSvc)
+	workspaceHandler := handler.NewWorkspaceHandler(workspaceSvc)
+	shareLinkHandler := handler.NewShareLinkHandler(shareLinkSvc)
+	auditHandler := handler.NewAuditHandler(auditSvc)
+	privacyHandler := handler.NewPrivacyHandler(privacySvc)
+	tagHandler := handler.NewTagHandler(tagSvc)
+	incomeHandler := handler.NewIncomeHandler(incomeSvc, decimalResolver)
+	taxAnalysisHandler := handler.NewTaxAnalysisHandler(taxAnalysisSvc, decimalResolver)
+	taxProfileHandler := handler.NewTaxProfileHandler(taxProfileSvc, decimalResolver)
 	docsHandler := handler.NewDocsHandler(openAPISpec)
 
 	// Create JWT middleware
@@ -277,6 +401,12 @@ func main() {
 		}
 	}
 
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		log.Error("Invalid TRUSTED_PROXIES", "error", err)
+		os.Exit(1)
+	}
+
 	// Create HTTP router
 	routerCfg := httpapi.Config{
 		Logger:             log,
@@ -289,8 +419,20 @@ func main() {
 		TaxLotHandler:      taxLotHandler,
 		LPPositionHandler:      lpPositionHTTPHandler,
 		LendingPositionHandler: lendingPositionHTTPHandler,
+		StakingPositionHandler: stakingPositionHTTPHandler,
+		DerivativePositionHandler: derivativePositionHTTPHandler,
+		WorkspaceHandler:       workspaceHandler,
+		ShareLinkHandler:       shareLinkHandler,
+		AuditHandler:           auditHandler,
+		PrivacyHandler:         privacyHandler,
+		TagHandler:             tagHandler,
+		IncomeHandler:          incomeHandler,
+		TaxAnalysisHandler:     taxAnalysisHandler,
+		TaxProfileHandler:      taxProfileHandler,
 		DocsHandler:            docsHandler,
 		JWTMiddleware:      jwtMiddleware,
+		RateLimitStore:     infraRedis.NewRateLimitStore(redisClient, log),
+		TrustedProxies:     trustedProxies,
 	}
 	r := httpapi.NewRouter(routerCfg)
 
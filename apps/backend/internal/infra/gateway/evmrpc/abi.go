@@ -0,0 +1,87 @@
+package evmrpc
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+const wordSize = 32
+
+var twoTo256 = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// selector returns the 4-byte function selector of a canonical signature
+func selector(signature string) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(signature))
+	return h.Sum(nil)[:4]
+}
+
+// encodeCall concatenates a selector with static ABI-encoded arguments
+func encodeCall(sel []byte, args ...[]byte) []byte {
+	data := make([]byte, 0, len(sel)+len(args)*wordSize)
+	data = append(data, sel...)
+	for _, a := range args {
+		data = append(data, a...)
+	}
+	return data
+}
+
+// uintWord left-pads a non-negative integer to a 32-byte word
+func uintWord(v *big.Int) []byte {
+	return v.FillBytes(make([]byte, wordSize))
+}
+
+// addressWord left-pads a 0x-prefixed address to a 32-byte word
+func addressWord(address string) ([]byte, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(address, "0x"))
+	if err != nil || len(raw) != 20 {
+		return nil, fmt.Errorf("invalid address %q", address)
+	}
+	word := make([]byte, wordSize)
+	copy(word[wordSize-20:], raw)
+	return word, nil
+}
+
+// word returns the i-th 32-byte word of ABI return data
+func word(data []byte, i int) ([]byte, error) {
+	start := i * wordSize
+	if len(data) < start+wordSize {
+		return nil, fmt.Errorf("return data too short: %d bytes, need word %d", len(data), i)
+	}
+	return data[start : start+wordSize], nil
+}
+
+func wordUint(data []byte, i int) (*big.Int, error) {
+	w, err := word(data, i)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(w), nil
+}
+
+// wordInt decodes a sign-extended two's complement integer (int24 ticks)
+func wordInt(data []byte, i int) (int, error) {
+	v, err := wordUint(data, i)
+	if err != nil {
+		return 0, err
+	}
+	if v.Bit(255) == 1 {
+		v.Sub(v, twoTo256)
+	}
+	if !v.IsInt64() {
+		return 0, fmt.Errorf("word %d overflows int", i)
+	}
+	return int(v.Int64()), nil
+}
+
+func wordAddress(data []byte, i int) (string, error) {
+	w, err := word(data, i)
+	if err != nil {
+		return "", err
+	}
+	return "0x" + hex.EncodeToString(w[wordSize-20:]), nil
+}
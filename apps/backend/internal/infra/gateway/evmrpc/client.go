@@ -0,0 +1,122 @@
+package evmrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+const requestTimeout = 15 * time.Second
+
+// Client is a minimal JSON-RPC client for read-only contract calls against
+// EVM nodes, with one endpoint per chain keyed by Zerion chain name
+type Client struct {
+	urls       map[string]string
+	httpClient *http.Client
+	nextID     atomic.Int64
+	logger     *logger.Logger
+}
+
+// NewClient creates a new JSON-RPC client for the given chain → URL endpoints
+func NewClient(urls map[string]string, log *logger.Logger) *Client {
+	return &Client{
+		urls: urls,
+		httpClient: &http.Client{
+			Timeout: requestTimeout,
+		},
+		logger: log.WithField("component", "evmrpc"),
+	}
+}
+
+// Supports reports whether an endpoint is configured for the chain
+func (c *Client) Supports(chainID string) bool {
+	_, ok := c.urls[chainID]
+	return ok
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int64         `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result string    `json:"result"`
+	Error  *rpcError `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type callMsg struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+	Data string `json:"data"`
+}
+
+// Call executes eth_call against the latest block and returns the raw return
+// data. from may be empty; it matters for calls that check msg.sender.
+func (c *Client) Call(ctx context.Context, chainID, from, to string, data []byte) ([]byte, error) {
+	url, ok := c.urls[chainID]
+	if !ok {
+		return nil, fmt.Errorf("no RPC endpoint for chain %s", chainID)
+	}
+
+	payload, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      c.nextID.Add(1),
+		Method:  "eth_call",
+		Params:  []interface{}{callMsg{From: from, To: to, Data: "0x" + hex.EncodeToString(data)}, "latest"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	c.logger.Debug("eth_call", "chain", chainID, "to", to, "status_code", resp.StatusCode, "duration_ms", time.Since(start).Milliseconds())
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("RPC error: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var out rpcResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if out.Error != nil {
+		return nil, fmt.Errorf("eth_call reverted: %s (code %d)", out.Error.Message, out.Error.Code)
+	}
+
+	result, err := hex.DecodeString(strings.TrimPrefix(out.Result, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid result hex: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,144 @@
+package evmrpc
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/kislikjeka/moontrack/internal/platform/lpposition"
+	"github.com/kislikjeka/moontrack/internal/platform/sync"
+)
+
+const uniswapV3Protocol = "uniswap v3"
+
+// uniswapV3Deployment holds the periphery and core contracts on one chain
+type uniswapV3Deployment struct {
+	positionManager string
+	factory         string
+}
+
+// Uniswap V3 deployments keyed by Zerion chain name
+var uniswapV3Deployments = map[string]uniswapV3Deployment{
+	"ethereum": {positionManager: "0xC36442b4a4522E871399CD717aBDC8a5E789FE88", factory: "0x1F98431c8aD98523631AE4a59f267346ea31F984"},
+	"arbitrum": {positionManager: "0xC36442b4a4522E871399CD717aBDC8a5E789FE88", factory: "0x1F98431c8aD98523631AE4a59f267346ea31F984"},
+	"optimism": {positionManager: "0xC36442b4a4522E871399CD717aBDC8a5E789FE88", factory: "0x1F98431c8aD98523631AE4a59f267346ea31F984"},
+	"polygon":  {positionManager: "0xC36442b4a4522E871399CD717aBDC8a5E789FE88", factory: "0x1F98431c8aD98523631AE4a59f267346ea31F984"},
+	"base":     {positionManager: "0x03a520b32C04BF3bEEf7BEb72E919cf822Ed34f1", factory: "0x33128a8fC17869897dcE68Ed26A0D7A6a2De1e2F"},
+}
+
+var (
+	selPositions = selector("positions(uint256)")
+	selCollect   = selector("collect((uint256,address,uint128,uint128))")
+	selGetPool   = selector("getPool(address,address,uint24)")
+	selSlot0     = selector("slot0()")
+
+	maxUint128 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+)
+
+// UniswapV3Reader reads position NFT state from the NonfungiblePositionManager
+// and its pool over JSON-RPC
+type UniswapV3Reader struct {
+	client *Client
+}
+
+// Compile-time check that UniswapV3Reader implements LPRangeDataProvider
+var _ sync.LPRangeDataProvider = (*UniswapV3Reader)(nil)
+
+// NewUniswapV3Reader creates a new Uniswap V3 position reader
+func NewUniswapV3Reader(client *Client) *UniswapV3Reader {
+	return &UniswapV3Reader{client: client}
+}
+
+// GetLPRangeState returns the position's tick range, the pool's current tick
+// and its uncollected fees. Fees come from simulating collect() as the owner,
+// which also accrues fees earned since the position was last touched.
+// Returns nil for other protocols and for chains without a deployment or RPC endpoint.
+func (r *UniswapV3Reader) GetLPRangeState(ctx context.Context, chainID, protocol, nftTokenID, owner string) (*lpposition.RangeState, error) {
+	if !strings.EqualFold(protocol, uniswapV3Protocol) {
+		return nil, nil
+	}
+	deployment, ok := uniswapV3Deployments[chainID]
+	if !ok || !r.client.Supports(chainID) {
+		return nil, nil
+	}
+
+	tokenID, ok := new(big.Int).SetString(nftTokenID, 10)
+	if !ok || tokenID.Sign() < 0 {
+		return nil, fmt.Errorf("invalid position token ID %q", nftTokenID)
+	}
+
+	// positions(tokenId) → (nonce, operator, token0, token1, fee, tickLower,
+	// tickUpper, liquidity, feeGrowthInside0, feeGrowthInside1, owed0, owed1)
+	pos, err := r.client.Call(ctx, chainID, "", deployment.positionManager, encodeCall(selPositions, uintWord(tokenID)))
+	if err != nil {
+		return nil, fmt.Errorf("positions(%s): %w", nftTokenID, err)
+	}
+	token0, err := wordAddress(pos, 2)
+	if err != nil {
+		return nil, err
+	}
+	token1, err := wordAddress(pos, 3)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := wordUint(pos, 4)
+	if err != nil {
+		return nil, err
+	}
+	state := &lpposition.RangeState{}
+	if state.TickLower, err = wordInt(pos, 5); err != nil {
+		return nil, err
+	}
+	if state.TickUpper, err = wordInt(pos, 6); err != nil {
+		return nil, err
+	}
+
+	if state.CurrentTick, err = r.currentTick(ctx, chainID, deployment.factory, token0, token1, fee); err != nil {
+		return nil, err
+	}
+
+	ownerWord, err := addressWord(owner)
+	if err != nil {
+		return nil, err
+	}
+	collect := encodeCall(selCollect, uintWord(tokenID), ownerWord, uintWord(maxUint128), uintWord(maxUint128))
+	fees, err := r.client.Call(ctx, chainID, owner, deployment.positionManager, collect)
+	if err != nil {
+		return nil, fmt.Errorf("collect(%s): %w", nftTokenID, err)
+	}
+	if state.UncollectedToken0, err = wordUint(fees, 0); err != nil {
+		return nil, err
+	}
+	if state.UncollectedToken1, err = wordUint(fees, 1); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// currentTick resolves the pool through the factory and reads slot0().tick
+func (r *UniswapV3Reader) currentTick(ctx context.Context, chainID, factory, token0, token1 string, fee *big.Int) (int, error) {
+	t0, err := addressWord(token0)
+	if err != nil {
+		return 0, err
+	}
+	t1, err := addressWord(token1)
+	if err != nil {
+		return 0, err
+	}
+	out, err := r.client.Call(ctx, chainID, "", factory, encodeCall(selGetPool, t0, t1, uintWord(fee)))
+	if err != nil {
+		return 0, fmt.Errorf("getPool: %w", err)
+	}
+	pool, err := wordAddress(out, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	slot0, err := r.client.Call(ctx, chainID, "", pool, selSlot0)
+	if err != nil {
+		return 0, fmt.Errorf("slot0: %w", err)
+	}
+	return wordInt(slot0, 1)
+}
@@ -0,0 +1,136 @@
+package evmrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+const (
+	testOwner = "0x00000000000000000000000000000000000000aa"
+	testPool  = "0x00000000000000000000000000000000000000bb"
+	testWETH  = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+	testUSDC  = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+)
+
+func intWord(v int64) []byte {
+	b := big.NewInt(v)
+	if v < 0 {
+		b.Add(b, twoTo256)
+	}
+	return uintWord(b)
+}
+
+func mustAddressWord(t *testing.T, address string) []byte {
+	w, err := addressWord(address)
+	require.NoError(t, err)
+	return w
+}
+
+// fakeNode answers eth_call by selector with canned Uniswap V3 return data
+func fakeNode(t *testing.T, calls map[string]int) *httptest.Server {
+	positions := bytes.Join([][]byte{
+		intWord(0), mustAddressWord(t, testOwner), mustAddressWord(t, testUSDC), mustAddressWord(t, testWETH),
+		intWord(500), intWord(196000), intWord(198000), intWord(1_000_000),
+		intWord(0), intWord(0), intWord(1), intWord(2),
+	}, nil)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req rpcRequest
+		require.NoError(t, json.Unmarshal(body, &req))
+		require.Equal(t, "eth_call", req.Method)
+		require.Equal(t, "latest", req.Params[1])
+
+		msg := req.Params[0].(map[string]interface{})
+		data, _ := hex.DecodeString(strings.TrimPrefix(msg["data"].(string), "0x"))
+		sel := hex.EncodeToString(data[:4])
+		calls[sel]++
+
+		var result []byte
+		switch sel {
+		case hex.EncodeToString(selPositions):
+			assert.Equal(t, uintWord(big.NewInt(4242)), data[4:36])
+			result = positions
+		case hex.EncodeToString(selGetPool):
+			assert.Equal(t, mustAddressWord(t, testUSDC), data[4:36])
+			assert.Equal(t, intWord(500), data[68:100])
+			result = mustAddressWord(t, testPool)
+		case hex.EncodeToString(selSlot0):
+			assert.Equal(t, testPool, msg["to"])
+			result = append(intWord(1), intWord(-199500)...)
+		case hex.EncodeToString(selCollect):
+			// collect only succeeds when simulated as the owner
+			assert.Equal(t, testOwner, msg["from"])
+			result = append(intWord(1_500_000), intWord(250_000_000_000_000)...)
+		default:
+			t.Fatalf("unexpected selector %s", sel)
+		}
+
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x` + hex.EncodeToString(result) + `"}`))
+	}))
+}
+
+func TestSelector_MatchesKnownSignatures(t *testing.T) {
+	assert.Equal(t, "a9059cbb", hex.EncodeToString(selector("transfer(address,uint256)")))
+	assert.Equal(t, "99fbab88", hex.EncodeToString(selPositions))
+	assert.Equal(t, "3850c7bd", hex.EncodeToString(selSlot0))
+}
+
+func TestUniswapV3Reader_ReadsRangeAndUncollectedFees(t *testing.T) {
+	calls := make(map[string]int)
+	server := fakeNode(t, calls)
+	defer server.Close()
+
+	client := NewClient(map[string]string{"ethereum": server.URL}, logger.New("development", io.Discard))
+	state, err := NewUniswapV3Reader(client).GetLPRangeState(context.Background(), "ethereum", "Uniswap V3", "4242", testOwner)
+
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, 196000, state.TickLower)
+	assert.Equal(t, 198000, state.TickUpper)
+	assert.Equal(t, -199500, state.CurrentTick)
+	assert.False(t, state.InRange())
+	assert.Equal(t, big.NewInt(1_500_000), state.UncollectedToken0)
+	assert.Equal(t, big.NewInt(250_000_000_000_000), state.UncollectedToken1)
+	assert.Len(t, calls, 4)
+}
+
+func TestUniswapV3Reader_SkipsUnsupported(t *testing.T) {
+	client := NewClient(map[string]string{"ethereum": "http://unused"}, logger.New("development", io.Discard))
+	reader := NewUniswapV3Reader(client)
+
+	state, err := reader.GetLPRangeState(context.Background(), "ethereum", "Curve", "1", testOwner)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	// Deployment known, but no RPC endpoint configured
+	state, err = reader.GetLPRangeState(context.Background(), "base", "Uniswap V3", "1", testOwner)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestClient_CallSurfacesRevert(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted: Not approved"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(map[string]string{"ethereum": server.URL}, logger.New("development", io.Discard))
+	_, err := client.Call(context.Background(), "ethereum", "", testPool, selSlot0)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Not approved")
+}
@@ -20,6 +20,7 @@ type SyncAdapter struct {
 // Compile-time check that SyncAdapter implements TransactionDataProvider
 var _ sync.TransactionDataProvider = (*SyncAdapter)(nil)
 var _ sync.PositionDataProvider = (*SyncAdapter)(nil)
+var _ sync.LPPositionDataProvider = (*SyncAdapter)(nil)
 
 // NewSyncAdapter creates a new Zerion sync adapter
 func NewSyncAdapter(client *Client) *SyncAdapter {
@@ -61,9 +62,13 @@ func convertTransaction(td TransactionData, chain string) (sync.DecodedTransacti
 	}
 
 	transfers := make([]sync.DecodedTransfer, 0, len(td.Attributes.Transfers))
+	var nftTransfers []sync.DecodedNFTTransfer
 	for _, zt := range td.Attributes.Transfers {
 		if zt.FungibleInfo == nil {
-			continue // skip NFT transfers — only track fungible assets
+			if nt, ok := convertNFTTransfer(zt); ok {
+				nftTransfers = append(nftTransfers, nt)
+			}
+			continue
 		}
 		dt := convertTransfer(zt, chain)
 		transfers = append(transfers, dt)
@@ -106,9 +111,49 @@ func convertTransaction(td TransactionData, chain string) (sync.DecodedTransacti
 		Status:        td.Attributes.Status,
 		NFTTokenID:    nftTokenID,
 		Acts:          acts,
+		NFTTransfers:  nftTransfers,
 	}, nil
 }
 
+// convertNFTTransfer maps a Zerion NFT transfer to a domain DecodedNFTTransfer.
+// Returns false for transfers without NFT info and for NFTs Zerion flags as spam.
+func convertNFTTransfer(zt ZTransfer) (sync.DecodedNFTTransfer, bool) {
+	info := zt.NftInfo
+	if info == nil || info.ContractAddress == "" || info.TokenID == "" {
+		return sync.DecodedNFTTransfer{}, false
+	}
+	if info.Flags != nil && info.Flags.IsSpam {
+		return sync.DecodedNFTTransfer{}, false
+	}
+
+	quantity := parseIntString(zt.Quantity.Int)
+	if quantity.Sign() <= 0 {
+		quantity = big.NewInt(1)
+	}
+
+	direction := sync.DirectionOut
+	if zt.Direction == "in" {
+		direction = sync.DirectionIn
+	}
+
+	var usdPrice *big.Int
+	if zt.Price != nil {
+		usdPrice = usdFloatToBigInt(*zt.Price)
+	}
+
+	return sync.DecodedNFTTransfer{
+		ContractAddress: strings.ToLower(info.ContractAddress),
+		TokenID:         info.TokenID,
+		Name:            info.Name,
+		Standard:        info.Interface,
+		Quantity:        quantity,
+		Direction:       direction,
+		Sender:          strings.ToLower(zt.Sender),
+		Recipient:       strings.ToLower(zt.Recipient),
+		USDPrice:        usdPrice,
+	}, true
+}
+
 // convertTransfer maps a Zerion ZTransfer to a domain DecodedTransfer
 func convertTransfer(zt ZTransfer, zerionChain string) sync.DecodedTransfer {
 	amount := parseIntString(zt.Quantity.Int)
@@ -235,49 +280,142 @@ func (a *SyncAdapter) GetPositions(ctx context.Context, address string) ([]sync.
 
 	result := make([]sync.OnChainPosition, 0, len(positions))
 	for _, pd := range positions {
-		chain := pd.Relationships.Chain.Data.ID
-		if chain == "" || !wallet.IsValidChain(chain) {
+		if p, ok := convertPosition(pd); ok {
+			result = append(result, p)
+		}
+	}
+
+	return result, nil
+}
+
+// GetLPPositions fetches DeFi deposit positions and groups the per-token
+// entries into one liquidity position per Zerion group ID
+func (a *SyncAdapter) GetLPPositions(ctx context.Context, address string) ([]sync.OnChainLPPosition, error) {
+	chainIDs := wallet.GetSupportedChains()
+
+	positions, err := a.client.GetDeFiPositions(ctx, address, chainIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []sync.OnChainLPPosition
+	index := make(map[string]int)
+	for _, pd := range positions {
+		token, ok := convertPosition(pd)
+		if !ok || pd.Attributes.Protocol == "" {
 			continue
 		}
 
-		var symbol string
-		var assetName string
-		var iconURL string
-		var contractAddr string
-		var decimals int
-		if pd.Attributes.FungibleInfo != nil {
-			symbol = pd.Attributes.FungibleInfo.Symbol
-			assetName = pd.Attributes.FungibleInfo.Name
-			if pd.Attributes.FungibleInfo.Icon != nil {
-				iconURL = pd.Attributes.FungibleInfo.Icon.URL
-			}
-			if impl := pd.Attributes.FungibleInfo.ImplementationByChain(chain); impl != nil {
-				contractAddr = strings.ToLower(impl.Address)
-				decimals = impl.Decimals
-			}
-			if decimals == 0 {
-				decimals = pd.Attributes.Quantity.Decimals
-			}
+		key := pd.Attributes.GroupID
+		if key == "" {
+			key = pd.ID
+		}
+		key = token.ChainID + ":" + key
+
+		i, seen := index[key]
+		if !seen {
+			i = len(result)
+			index[key] = i
+			result = append(result, sync.OnChainLPPosition{
+				ChainID:     token.ChainID,
+				Protocol:    pd.Attributes.Protocol,
+				PoolAddress: strings.ToLower(pd.Attributes.PoolAddress),
+				ValueUSD:    big.NewInt(0),
+			})
 		}
 
-		quantity := parseIntString(pd.Attributes.Quantity.Int)
+		result[i].Tokens = append(result[i].Tokens, token)
+		if pd.Attributes.Value != nil {
+			result[i].ValueUSD.Add(result[i].ValueUSD, usdFloatToBigInt(*pd.Attributes.Value))
+		}
+	}
 
-		var usdPrice *big.Int
-		if pd.Attributes.Price > 0 {
-			usdPrice = usdFloatToBigInt(pd.Attributes.Price)
+	return result, nil
+}
+
+// GetLendingPositions fetches lending deposits and loans and groups them into
+// one lending position per protocol and chain
+func (a *SyncAdapter) GetLendingPositions(ctx context.Context, address string) ([]sync.OnChainLendingPosition, error) {
+	chainIDs := wallet.GetSupportedChains()
+
+	positions, err := a.client.GetLendingPositions(ctx, address, chainIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []sync.OnChainLendingPosition
+	index := make(map[string]int)
+	for _, pd := range positions {
+		token, ok := convertPosition(pd)
+		if !ok || pd.Attributes.Protocol == "" {
+			continue
 		}
 
-		result = append(result, sync.OnChainPosition{
-			ChainID:         chain,
-			AssetSymbol:     symbol,
-			AssetName:       assetName,
-			ContractAddress: contractAddr,
-			Decimals:        decimals,
-			Quantity:        quantity,
-			USDPrice:        usdPrice,
-			IconURL:         iconURL,
-		})
+		key := token.ChainID + ":" + pd.Attributes.Protocol
+		i, seen := index[key]
+		if !seen {
+			i = len(result)
+			index[key] = i
+			result = append(result, sync.OnChainLendingPosition{
+				ChainID:  token.ChainID,
+				Protocol: pd.Attributes.Protocol,
+			})
+		}
+
+		switch pd.Attributes.PositionType {
+		case "deposit":
+			result[i].Supplied = append(result[i].Supplied, token)
+		case "loan":
+			result[i].Borrowed = append(result[i].Borrowed, token)
+		}
 	}
 
 	return result, nil
 }
+
+// convertPosition converts a Zerion position to a domain token balance,
+// skipping positions on unsupported chains
+func convertPosition(pd PositionData) (sync.OnChainPosition, bool) {
+	chain := pd.Relationships.Chain.Data.ID
+	if chain == "" || !wallet.IsValidChain(chain) {
+		return sync.OnChainPosition{}, false
+	}
+
+	var symbol string
+	var assetName string
+	var iconURL string
+	var contractAddr string
+	var decimals int
+	if pd.Attributes.FungibleInfo != nil {
+		symbol = pd.Attributes.FungibleInfo.Symbol
+		assetName = pd.Attributes.FungibleInfo.Name
+		if pd.Attributes.FungibleInfo.Icon != nil {
+			iconURL = pd.Attributes.FungibleInfo.Icon.URL
+		}
+		if impl := pd.Attributes.FungibleInfo.ImplementationByChain(chain); impl != nil {
+			contractAddr = strings.ToLower(impl.Address)
+			decimals = impl.Decimals
+		}
+		if decimals == 0 {
+			decimals = pd.Attributes.Quantity.Decimals
+		}
+	}
+
+	quantity := parseIntString(pd.Attributes.Quantity.Int)
+
+	var usdPrice *big.Int
+	if pd.Attributes.Price > 0 {
+		usdPrice = usdFloatToBigInt(pd.Attributes.Price)
+	}
+
+	return sync.OnChainPosition{
+		ChainID:         chain,
+		AssetSymbol:     symbol,
+		AssetName:       assetName,
+		ContractAddress: contractAddr,
+		Decimals:        decimals,
+		Quantity:        quantity,
+		USDPrice:        usdPrice,
+		IconURL:         iconURL,
+	}, true
+}
@@ -278,6 +278,71 @@ func TestSyncAdapter_NilFungibleInfo_SkipsNFTTransfers(t *testing.T) {
 	assert.Len(t, txs[0].Transfers, 0)
 }
 
+func TestSyncAdapter_ConvertsNFTTransfers(t *testing.T) {
+	price := 12345.5
+	txData := zerion.TransactionResponse{
+		Data: []zerion.TransactionData{
+			withChain(zerion.TransactionData{
+				ID: "tx-nft-buy",
+				Attributes: zerion.TransactionAttributes{
+					OperationType: "trade",
+					Hash:          "0xnft",
+					MinedAt:       "2024-01-01T00:00:00Z",
+					Status:        "confirmed",
+					Transfers: []zerion.ZTransfer{
+						{
+							NftInfo: &zerion.NftInfo{
+								ContractAddress: "0xBAYC",
+								TokenID:         "7",
+								Name:            "Bored Ape #7",
+								Interface:       "erc721",
+							},
+							Direction: "in",
+							Quantity:  zerion.Quantity{Int: "1"},
+							Sender:    "0xSeller",
+							Recipient: "0xB",
+							Price:     &price,
+						},
+						{
+							NftInfo: &zerion.NftInfo{
+								ContractAddress: "0xSPAM",
+								TokenID:         "1",
+								Flags:           &zerion.NftFlags{IsSpam: true},
+							},
+							Direction: "in",
+							Quantity:  zerion.Quantity{Int: "1"},
+						},
+					},
+				},
+			}, "ethereum"),
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(txData)
+	}))
+	defer server.Close()
+
+	client := zerion.NewClient("key", testLogger())
+	client.SetBaseURL(server.URL)
+	adapter := zerion.NewSyncAdapter(client)
+
+	txs, err := adapter.GetTransactions(context.Background(), "0xB", time.Time{})
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+
+	// Spam NFTs are dropped
+	require.Len(t, txs[0].NFTTransfers, 1)
+	nt := txs[0].NFTTransfers[0]
+	assert.Equal(t, "0xbayc", nt.ContractAddress)
+	assert.Equal(t, "7", nt.TokenID)
+	assert.Equal(t, "erc721", nt.Standard)
+	assert.Equal(t, sync.DirectionIn, nt.Direction)
+	assert.Equal(t, "0xseller", nt.Sender)
+	assert.Equal(t, big.NewInt(1), nt.Quantity)
+	assert.Equal(t, big.NewInt(1234550000000), nt.USDPrice)
+}
+
 func TestSyncAdapter_NilFee(t *testing.T) {
 	txData := zerion.TransactionResponse{
 		Data: []zerion.TransactionData{
@@ -482,3 +547,100 @@ func TestSyncAdapter_SkipsInvalidMinedAt(t *testing.T) {
 	require.Len(t, txs, 1)
 	assert.Equal(t, "tx-good", txs[0].ID)
 }
+
+func TestSyncAdapter_GetLPPositionsGroupsTokens(t *testing.T) {
+	lpToken := func(id, group, symbol string, value float64) zerion.PositionData {
+		v := value
+		pd := zerion.PositionData{
+			Type: "positions",
+			ID:   id,
+			Attributes: zerion.PositionAttributes{
+				PositionType: "deposit",
+				Quantity:     zerion.Quantity{Int: "1000", Decimals: 18},
+				Value:        &v,
+				FungibleInfo: &zerion.FungibleInfo{Symbol: symbol},
+				Protocol:     "Uniswap V3",
+				GroupID:      group,
+				PoolAddress:  "0xPOOL",
+			},
+		}
+		pd.Relationships.Chain.Data.ID = "ethereum"
+		return pd
+	}
+
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		json.NewEncoder(w).Encode(zerion.PositionResponse{Data: []zerion.PositionData{
+			lpToken("p1", "g1", "WETH", 2000),
+			lpToken("p2", "g1", "USDC", 1500.5),
+			lpToken("p3", "g2", "WBTC", 100),
+		}})
+	}))
+	defer server.Close()
+
+	client := zerion.NewClient("key", testLogger())
+	client.SetBaseURL(server.URL)
+	adapter := zerion.NewSyncAdapter(client)
+
+	positions, err := adapter.GetLPPositions(context.Background(), "0xtest")
+	require.NoError(t, err)
+
+	assert.Contains(t, gotQuery, "only_complex")
+	require.Len(t, positions, 2)
+	assert.Equal(t, "Uniswap V3", positions[0].Protocol)
+	assert.Equal(t, "0xpool", positions[0].PoolAddress)
+	require.Len(t, positions[0].Tokens, 2)
+	assert.Equal(t, "WETH", positions[0].Tokens[0].AssetSymbol)
+	assert.Equal(t, "USDC", positions[0].Tokens[1].AssetSymbol)
+	assert.Equal(t, big.NewInt(3500_50000000), positions[0].ValueUSD)
+	assert.Len(t, positions[1].Tokens, 1)
+}
+
+func TestSyncAdapter_GetLendingPositionsSplitsDepositsAndLoans(t *testing.T) {
+	lendingToken := func(id, positionType, protocol, symbol string) zerion.PositionData {
+		pd := zerion.PositionData{
+			Type: "positions",
+			ID:   id,
+			Attributes: zerion.PositionAttributes{
+				PositionType: positionType,
+				Quantity:     zerion.Quantity{Int: "1000", Decimals: 18},
+				Price:        1,
+				FungibleInfo: &zerion.FungibleInfo{Symbol: symbol},
+				Protocol:     protocol,
+			},
+		}
+		pd.Relationships.Chain.Data.ID = "ethereum"
+		return pd
+	}
+
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("filter[position_types]")
+		json.NewEncoder(w).Encode(zerion.PositionResponse{Data: []zerion.PositionData{
+			lendingToken("p1", "deposit", "Aave V3", "WETH"),
+			lendingToken("p2", "loan", "Aave V3", "USDC"),
+			lendingToken("p3", "deposit", "Aave V3", "wstETH"),
+			lendingToken("p4", "deposit", "Spark", "DAI"),
+		}})
+	}))
+	defer server.Close()
+
+	client := zerion.NewClient("key", testLogger())
+	client.SetBaseURL(server.URL)
+	adapter := zerion.NewSyncAdapter(client)
+
+	positions, err := adapter.GetLendingPositions(context.Background(), "0xtest")
+	require.NoError(t, err)
+
+	assert.Equal(t, "deposit,loan", gotQuery)
+	require.Len(t, positions, 2)
+	assert.Equal(t, "Aave V3", positions[0].Protocol)
+	require.Len(t, positions[0].Supplied, 2)
+	assert.Equal(t, "WETH", positions[0].Supplied[0].AssetSymbol)
+	require.Len(t, positions[0].Borrowed, 1)
+	assert.Equal(t, "USDC", positions[0].Borrowed[0].AssetSymbol)
+	assert.Equal(t, big.NewInt(100_000_000), positions[0].Borrowed[0].USDPrice)
+	assert.Equal(t, "Spark", positions[1].Protocol)
+	assert.Empty(t, positions[1].Borrowed)
+}
@@ -168,14 +168,44 @@ func (c *Client) GetTransactions(ctx context.Context, address string, chainIDs [
 // GetPositions fetches wallet positions (token balances) for an address on the given chains.
 // It handles pagination by following the absolute Links.Next URL.
 func (c *Client) GetPositions(ctx context.Context, address string, chainIDs []string) ([]PositionData, error) {
-	fetchStart := time.Now()
-	reqURL := fmt.Sprintf("%s/wallets/%s/positions/", c.baseURL, address)
-
 	params := url.Values{}
 	params.Set("filter[position_types]", "wallet")
 	params.Set("filter[chain_ids]", strings.Join(chainIDs, ","))
 	params.Set("filter[trash]", "only_non_trash")
 
+	return c.getPositions(ctx, address, params)
+}
+
+// GetDeFiPositions fetches protocol deposit positions (e.g. liquidity pools) for an
+// address on the given chains. Each underlying token is its own position; tokens of
+// the same pool share a group ID.
+func (c *Client) GetDeFiPositions(ctx context.Context, address string, chainIDs []string) ([]PositionData, error) {
+	params := url.Values{}
+	params.Set("filter[positions]", "only_complex")
+	params.Set("filter[position_types]", "deposit")
+	params.Set("filter[chain_ids]", strings.Join(chainIDs, ","))
+	params.Set("filter[trash]", "only_non_trash")
+
+	return c.getPositions(ctx, address, params)
+}
+
+// GetLendingPositions fetches lending protocol positions for an address on the
+// given chains: supplied balances ("deposit") and debts ("loan"), both
+// including accrued interest.
+func (c *Client) GetLendingPositions(ctx context.Context, address string, chainIDs []string) ([]PositionData, error) {
+	params := url.Values{}
+	params.Set("filter[positions]", "only_complex")
+	params.Set("filter[position_types]", "deposit,loan")
+	params.Set("filter[chain_ids]", strings.Join(chainIDs, ","))
+	params.Set("filter[trash]", "only_non_trash")
+
+	return c.getPositions(ctx, address, params)
+}
+
+func (c *Client) getPositions(ctx context.Context, address string, params url.Values) ([]PositionData, error) {
+	fetchStart := time.Now()
+	reqURL := fmt.Sprintf("%s/wallets/%s/positions/", c.baseURL, address)
+
 	var allPositions []PositionData
 
 	for {
@@ -203,6 +233,25 @@ func (c *Client) GetPositions(ctx context.Context, address string, chainIDs []st
 	return allPositions, nil
 }
 
+// GetNFT fetches a single NFT, including its collection's market data, by chain, contract and token ID.
+func (c *Client) GetNFT(ctx context.Context, chainID, contractAddress, tokenID string) (*NFTData, error) {
+	reqURL := fmt.Sprintf("%s/nfts/%s:%s:%s", c.baseURL, chainID, contractAddress, tokenID)
+
+	params := url.Values{}
+	params.Set("currency", "usd")
+
+	body, err := c.doRequest(ctx, http.MethodGet, reqURL, params)
+	if err != nil {
+		return nil, fmt.Errorf("GetNFT failed: %w", err)
+	}
+
+	var resp NFTResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode Zerion NFT response: %w", err)
+	}
+	return &resp.Data, nil
+}
+
 // RateLimitError represents a rate limit error from Zerion API
 type RateLimitError struct {
 	RetryAfter time.Duration
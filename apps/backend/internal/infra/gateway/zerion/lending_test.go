@@ -0,0 +1,67 @@
+package zerion_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/infra/gateway/zerion"
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/sync"
+)
+
+// Zerion responses for lending transactions on Compound V2/V3, Morpho,
+// Spark and Aave, decoded and classified end to end
+func TestSyncAdapter_LendingFixturesClassify(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/lending_transactions.json")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	client := zerion.NewClient("key", testLogger())
+	client.SetBaseURL(server.URL)
+	adapter := zerion.NewSyncAdapter(client)
+
+	txs, err := adapter.GetTransactions(context.Background(), "0x1111111111111111111111111111111111111111", time.Time{})
+	require.NoError(t, err)
+
+	expected := map[string]ledger.TransactionType{
+		// cToken mint and redeem, reported as trades
+		"compound-v2-supply": ledger.TxTypeLendingSupply,
+		"compound-v2-redeem": ledger.TxTypeLendingWithdraw,
+		// Comet: the base asset mints cUSDCv3, collateral moves no token
+		"compound-v3-supply-base":       ledger.TxTypeLendingSupply,
+		"compound-v3-supply-collateral": ledger.TxTypeLendingSupply,
+		"compound-v3-borrow":            ledger.TxTypeLendingBorrow,
+		// Morpho Blue: no receipt tokens, the act names the operation
+		"morpho-blue-supply-collateral": ledger.TxTypeLendingSupply,
+		"morpho-blue-borrow":            ledger.TxTypeLendingBorrow,
+		"morpho-blue-repay":             ledger.TxTypeLendingRepay,
+		// Spark: spTokens and debt tokens move alongside the asset
+		"spark-supply":   ledger.TxTypeLendingSupply,
+		"spark-borrow":   ledger.TxTypeLendingBorrow,
+		"spark-repay":    ledger.TxTypeLendingRepay,
+		"spark-withdraw": ledger.TxTypeLendingWithdraw,
+		// Sending an aToken to another wallet is not a lending operation
+		"aave-atoken-transfer": ledger.TxTypeTransferOut,
+		// Sent by the liquidator: no fee, the aToken and debt token burn
+		"aave-v3-liquidation": ledger.TxTypeLendingLiquidation,
+	}
+
+	require.Len(t, txs, len(expected))
+	classifier := sync.NewClassifier()
+	for _, tx := range txs {
+		want, ok := expected[tx.ID]
+		require.True(t, ok, "unexpected fixture %s", tx.ID)
+		assert.Equal(t, want, classifier.Classify(tx), tx.ID)
+	}
+}
@@ -0,0 +1,61 @@
+package zerion
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// floorPriceTTL bounds how long a collection floor is reused before refetching
+const floorPriceTTL = 10 * time.Minute
+
+// NFTPriceAdapter serves NFT floor prices from the Zerion NFT endpoint,
+// caching them briefly so portfolio views don't hit the API per request.
+type NFTPriceAdapter struct {
+	client *Client
+	mu     sync.Mutex
+	cache  map[string]cachedFloor
+}
+
+type cachedFloor struct {
+	price     *big.Int
+	fetchedAt time.Time
+}
+
+// NewNFTPriceAdapter creates a new NFT floor price adapter
+func NewNFTPriceAdapter(client *Client) *NFTPriceAdapter {
+	return &NFTPriceAdapter{
+		client: client,
+		cache:  make(map[string]cachedFloor),
+	}
+}
+
+// GetFloorPrice returns the collection floor price of an NFT in USD scaled
+// by 1e8, or zero if the collection has no floor.
+func (a *NFTPriceAdapter) GetFloorPrice(ctx context.Context, chainID, contractAddress, tokenID string) (*big.Int, error) {
+	key := chainID + ":" + contractAddress + ":" + tokenID
+
+	a.mu.Lock()
+	if c, ok := a.cache[key]; ok && time.Since(c.fetchedAt) < floorPriceTTL {
+		a.mu.Unlock()
+		return new(big.Int).Set(c.price), nil
+	}
+	a.mu.Unlock()
+
+	nft, err := a.client.GetNFT(ctx, chainID, contractAddress, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	price := big.NewInt(0)
+	if md := nft.Attributes.MarketData; md != nil && md.Prices.Floor != nil {
+		price = usdFloatToBigInt(*md.Prices.Floor)
+	}
+
+	a.mu.Lock()
+	a.cache[key] = cachedFloor{price: price, fetchedAt: time.Now()}
+	a.mu.Unlock()
+
+	return new(big.Int).Set(price), nil
+}
@@ -0,0 +1,51 @@
+package zerion_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/infra/gateway/zerion"
+)
+
+func TestNFTPriceAdapter_FloorPriceCached(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		assert.Equal(t, "/nfts/ethereum:0xbayc:7", r.URL.Path)
+		assert.Equal(t, "usd", r.URL.Query().Get("currency"))
+		w.Write([]byte(`{"data":{"type":"nfts","id":"x","attributes":{"market_data":{"prices":{"floor":25000.5}}}}}`))
+	}))
+	defer server.Close()
+
+	client := zerion.NewClient("key", testLogger())
+	client.SetBaseURL(server.URL)
+	adapter := zerion.NewNFTPriceAdapter(client)
+
+	for i := 0; i < 2; i++ {
+		price, err := adapter.GetFloorPrice(context.Background(), "ethereum", "0xbayc", "7")
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(2500050000000), price)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestNFTPriceAdapter_NoFloorIsZero(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"type":"nfts","id":"x","attributes":{}}}`))
+	}))
+	defer server.Close()
+
+	client := zerion.NewClient("key", testLogger())
+	client.SetBaseURL(server.URL)
+
+	price, err := zerion.NewNFTPriceAdapter(client).GetFloorPrice(context.Background(), "ethereum", "0xabc", "1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, price.Sign())
+}
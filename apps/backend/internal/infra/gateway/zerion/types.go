@@ -83,10 +83,16 @@ type ZTransfer struct {
 
 // NftInfo describes an NFT involved in a transfer
 type NftInfo struct {
-	ContractAddress string `json:"contract_address"`
-	TokenID         string `json:"token_id"`
-	Name            string `json:"name"`
-	Interface       string `json:"interface"` // "erc721" or "erc1155"
+	ContractAddress string    `json:"contract_address"`
+	TokenID         string    `json:"token_id"`
+	Name            string    `json:"name"`
+	Interface       string    `json:"interface"` // "erc721" or "erc1155"
+	Flags           *NftFlags `json:"flags"`
+}
+
+// NftFlags holds Zerion's classification flags for an NFT
+type NftFlags struct {
+	IsSpam bool `json:"is_spam"`
 }
 
 // FungibleInfo describes the token involved in a transfer or fee
@@ -154,11 +160,45 @@ type PositionData struct {
 	Relationships Relationships      `json:"relationships"`
 }
 
-// PositionAttributes contains the position fields
+// PositionAttributes contains the position fields. Protocol, GroupID and
+// PoolAddress are only set on DeFi (complex) positions.
 type PositionAttributes struct {
 	PositionType string        `json:"position_type"`
 	Quantity     Quantity      `json:"quantity"`
 	Value        *float64      `json:"value"`
 	Price        float64       `json:"price"`
 	FungibleInfo *FungibleInfo `json:"fungible_info"`
+	Protocol     string        `json:"protocol"`
+	GroupID      string        `json:"group_id"`
+	PoolAddress  string        `json:"pool_address"`
+}
+
+// NFTResponse is the Zerion API response for a single NFT
+type NFTResponse struct {
+	Data NFTData `json:"data"`
+}
+
+// NFTData wraps a single NFT with its type and ID
+type NFTData struct {
+	Type       string        `json:"type"`
+	ID         string        `json:"id"`
+	Attributes NFTAttributes `json:"attributes"`
+}
+
+// NFTAttributes contains the NFT fields
+type NFTAttributes struct {
+	ContractAddress string         `json:"contract_address"`
+	TokenID         string         `json:"token_id"`
+	Interface       string         `json:"interface"`
+	MarketData      *NFTMarketData `json:"market_data"`
+}
+
+// NFTMarketData holds market prices for an NFT's collection
+type NFTMarketData struct {
+	Prices NFTPrices `json:"prices"`
+}
+
+// NFTPrices holds collection prices in the requested currency
+type NFTPrices struct {
+	Floor *float64 `json:"floor"` // nil if the collection has no floor
 }
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
+)
+
+// AuditRepository implements the append-only audit log using PostgreSQL
+type AuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAuditRepository creates a new PostgreSQL audit repository
+func NewAuditRepository(pool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{pool: pool}
+}
+
+// Append inserts an audit entry
+func (r *AuditRepository) Append(ctx context.Context, entry *audit.Entry) error {
+	query := `
+		INSERT INTO audit_log (id, actor_id, action, target_type, target_id, before, after, ip, user_agent, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		entry.ID, entry.ActorID, string(entry.Action), entry.TargetType, entry.TargetID,
+		nullableJSON(entry.Before), nullableJSON(entry.After),
+		entry.IP, entry.UserAgent, entry.RequestID, entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit entry: %w", err)
+	}
+	return nil
+}
+
+// List retrieves an actor's audit entries, newest first
+func (r *AuditRepository) List(ctx context.Context, actorID uuid.UUID, filter audit.Filter) ([]*audit.Entry, error) {
+	where, args := auditWhere(actorID, filter)
+	query := `
+		SELECT id, actor_id, action, target_type, target_id, before, after, ip, user_agent, request_id, created_at
+		FROM audit_log
+		WHERE ` + where + `
+		ORDER BY created_at DESC, id DESC`
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*audit.Entry
+	for rows.Next() {
+		e := &audit.Entry{}
+		var action string
+		var before, after []byte
+		if err := rows.Scan(
+			&e.ID, &e.ActorID, &action, &e.TargetType, &e.TargetID, &before, &after,
+			&e.IP, &e.UserAgent, &e.RequestID, &e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		e.Action = audit.Action(action)
+		e.Before = before
+		e.After = after
+		result = append(result, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit log: %w", err)
+	}
+	return result, nil
+}
+
+// Count returns the number of entries matching the filter, ignoring pagination
+func (r *AuditRepository) Count(ctx context.Context, actorID uuid.UUID, filter audit.Filter) (int, error) {
+	where, args := auditWhere(actorID, filter)
+	var count int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM audit_log WHERE `+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+	return count, nil
+}
+
+func auditWhere(actorID uuid.UUID, filter audit.Filter) (string, []any) {
+	conditions := []string{"actor_id = $1"}
+	args := []any{actorID}
+
+	add := func(cond string, v any) {
+		args = append(args, v)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.Action != "" {
+		add("action = $%d", string(filter.Action))
+	}
+	if filter.TargetType != "" {
+		add("target_type = $%d", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		add("target_id = $%d", filter.TargetID)
+	}
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at <= $%d", *filter.To)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// nullableJSON maps an empty payload to SQL NULL
+func nullableJSON(raw []byte) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
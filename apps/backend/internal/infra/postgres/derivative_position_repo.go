@@ -0,0 +1,241 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kislikjeka/moontrack/internal/platform/derivativeposition"
+)
+
+type DerivativePositionRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewDerivativePositionRepo(pool *pgxpool.Pool) *DerivativePositionRepo {
+	return &DerivativePositionRepo{pool: pool}
+}
+
+func (r *DerivativePositionRepo) Create(ctx context.Context, pos *derivativeposition.DerivativePosition) error {
+	query := `
+		INSERT INTO derivative_positions (
+			id, user_id, wallet_id, chain_id, protocol, market,
+			asset, asset_decimals, asset_contract, margin,
+			total_deposited, total_withdrawn, total_deposited_usd, total_withdrawn_usd,
+			realized_pnl, realized_pnl_usd,
+			funding_paid, funding_received, funding_paid_usd, funding_received_usd,
+			status, opened_at, closed_at,
+			created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6,
+			$7, $8, $9, $10,
+			$11, $12, $13, $14,
+			$15, $16,
+			$17, $18, $19, $20,
+			$21, $22, $23,
+			$24, $25
+		)
+	`
+
+	market := sql.NullString{String: pos.Market, Valid: pos.Market != ""}
+	assetContract := sql.NullString{String: pos.AssetContract, Valid: pos.AssetContract != ""}
+
+	_, err := r.pool.Exec(ctx, query,
+		pos.ID, pos.UserID, pos.WalletID, pos.ChainID, pos.Protocol, market,
+		pos.Asset, pos.AssetDecimals, assetContract, pos.Margin.String(),
+		pos.TotalDeposited.String(), pos.TotalWithdrawn.String(), pos.TotalDepositedUSD.String(), pos.TotalWithdrawnUSD.String(),
+		pos.RealizedPnL.String(), pos.RealizedPnLUSD.String(),
+		pos.FundingPaid.String(), pos.FundingReceived.String(), pos.FundingPaidUSD.String(), pos.FundingReceivedUSD.String(),
+		string(pos.Status), pos.OpenedAt, pos.ClosedAt,
+		pos.CreatedAt, pos.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert derivative_position: %w", err)
+	}
+	return nil
+}
+
+func (r *DerivativePositionRepo) Update(ctx context.Context, pos *derivativeposition.DerivativePosition) error {
+	query := `
+		UPDATE derivative_positions SET
+			market = $1, margin = $2,
+			total_deposited = $3, total_withdrawn = $4, total_deposited_usd = $5, total_withdrawn_usd = $6,
+			realized_pnl = $7, realized_pnl_usd = $8,
+			funding_paid = $9, funding_received = $10, funding_paid_usd = $11, funding_received_usd = $12,
+			status = $13, closed_at = $14,
+			updated_at = $15
+		WHERE id = $16
+	`
+
+	market := sql.NullString{String: pos.Market, Valid: pos.Market != ""}
+
+	_, err := r.pool.Exec(ctx, query,
+		market, pos.Margin.String(),
+		pos.TotalDeposited.String(), pos.TotalWithdrawn.String(), pos.TotalDepositedUSD.String(), pos.TotalWithdrawnUSD.String(),
+		pos.RealizedPnL.String(), pos.RealizedPnLUSD.String(),
+		pos.FundingPaid.String(), pos.FundingReceived.String(), pos.FundingPaidUSD.String(), pos.FundingReceivedUSD.String(),
+		string(pos.Status), pos.ClosedAt,
+		pos.UpdatedAt, pos.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update derivative_position: %w", err)
+	}
+	return nil
+}
+
+const derivativeSelectColumns = `
+	id, user_id, wallet_id, chain_id, protocol, market,
+	asset, asset_decimals, asset_contract, margin,
+	total_deposited, total_withdrawn, total_deposited_usd, total_withdrawn_usd,
+	realized_pnl, realized_pnl_usd,
+	funding_paid, funding_received, funding_paid_usd, funding_received_usd,
+	status, opened_at, closed_at,
+	created_at, updated_at
+`
+
+func (r *DerivativePositionRepo) GetByID(ctx context.Context, id uuid.UUID) (*derivativeposition.DerivativePosition, error) {
+	query := `SELECT ` + derivativeSelectColumns + ` FROM derivative_positions WHERE id = $1`
+
+	pos, err := r.scanOneDerivative(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get derivative_position by id: %w", err)
+	}
+	return pos, nil
+}
+
+func (r *DerivativePositionRepo) FindActiveByWalletAndAsset(ctx context.Context, walletID uuid.UUID, protocol, chainID, asset string) (*derivativeposition.DerivativePosition, error) {
+	query := `SELECT ` + derivativeSelectColumns + `
+		FROM derivative_positions
+		WHERE wallet_id = $1 AND protocol = $2 AND chain_id = $3 AND asset = $4 AND status = 'active'
+		LIMIT 1`
+
+	pos, err := r.scanOneDerivative(r.pool.QueryRow(ctx, query, walletID, protocol, chainID, asset))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("find active derivative_position: %w", err)
+	}
+	return pos, nil
+}
+
+func (r *DerivativePositionRepo) ListByUser(ctx context.Context, userID uuid.UUID, status *derivativeposition.Status, walletID *uuid.UUID, chainID *string) ([]*derivativeposition.DerivativePosition, error) {
+	query := `SELECT ` + derivativeSelectColumns + ` FROM derivative_positions WHERE user_id = $1`
+	args := []any{userID}
+	argPos := 2
+
+	if status != nil {
+		query += fmt.Sprintf(" AND status = $%d", argPos)
+		args = append(args, string(*status))
+		argPos++
+	}
+	if walletID != nil {
+		query += fmt.Sprintf(" AND wallet_id = $%d", argPos)
+		args = append(args, *walletID)
+		argPos++
+	}
+	if chainID != nil {
+		query += fmt.Sprintf(" AND chain_id = $%d", argPos)
+		args = append(args, *chainID)
+	}
+
+	query += " ORDER BY opened_at DESC"
+
+	return r.scanManyDerivative(ctx, query, args...)
+}
+
+func (r *DerivativePositionRepo) ListByWallets(ctx context.Context, walletIDs []uuid.UUID, status *derivativeposition.Status, chainID *string) ([]*derivativeposition.DerivativePosition, error) {
+	query := `SELECT ` + derivativeSelectColumns + ` FROM derivative_positions WHERE wallet_id = ANY($1)`
+	args := []any{walletIDs}
+	argPos := 2
+
+	if status != nil {
+		query += fmt.Sprintf(" AND status = $%d", argPos)
+		args = append(args, string(*status))
+		argPos++
+	}
+	if chainID != nil {
+		query += fmt.Sprintf(" AND chain_id = $%d", argPos)
+		args = append(args, *chainID)
+	}
+
+	query += " ORDER BY opened_at DESC"
+
+	return r.scanManyDerivative(ctx, query, args...)
+}
+
+func (r *DerivativePositionRepo) scanOneDerivative(row pgx.Row) (*derivativeposition.DerivativePosition, error) {
+	var pos derivativeposition.DerivativePosition
+	var market, assetContract sql.NullString
+	var status string
+
+	var margin string
+	var totalDeposited, totalWithdrawn, totalDepositedUSD, totalWithdrawnUSD string
+	var realizedPnL, realizedPnLUSD string
+	var fundingPaid, fundingReceived, fundingPaidUSD, fundingReceivedUSD string
+
+	err := row.Scan(
+		&pos.ID, &pos.UserID, &pos.WalletID, &pos.ChainID, &pos.Protocol, &market,
+		&pos.Asset, &pos.AssetDecimals, &assetContract, &margin,
+		&totalDeposited, &totalWithdrawn, &totalDepositedUSD, &totalWithdrawnUSD,
+		&realizedPnL, &realizedPnLUSD,
+		&fundingPaid, &fundingReceived, &fundingPaidUSD, &fundingReceivedUSD,
+		&status, &pos.OpenedAt, &pos.ClosedAt,
+		&pos.CreatedAt, &pos.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if market.Valid {
+		pos.Market = market.String
+	}
+	if assetContract.Valid {
+		pos.AssetContract = assetContract.String
+	}
+
+	pos.Status = derivativeposition.Status(status)
+
+	pos.Margin = parseBigInt(margin)
+	pos.TotalDeposited = parseBigInt(totalDeposited)
+	pos.TotalWithdrawn = parseBigInt(totalWithdrawn)
+	pos.TotalDepositedUSD = parseBigInt(totalDepositedUSD)
+	pos.TotalWithdrawnUSD = parseBigInt(totalWithdrawnUSD)
+	pos.RealizedPnL = parseBigInt(realizedPnL)
+	pos.RealizedPnLUSD = parseBigInt(realizedPnLUSD)
+	pos.FundingPaid = parseBigInt(fundingPaid)
+	pos.FundingReceived = parseBigInt(fundingReceived)
+	pos.FundingPaidUSD = parseBigInt(fundingPaidUSD)
+	pos.FundingReceivedUSD = parseBigInt(fundingReceivedUSD)
+
+	return &pos, nil
+}
+
+func (r *DerivativePositionRepo) scanManyDerivative(ctx context.Context, query string, args ...any) ([]*derivativeposition.DerivativePosition, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query derivative_positions: %w", err)
+	}
+	defer rows.Close()
+
+	var positions []*derivativeposition.DerivativePosition
+	for rows.Next() {
+		pos, err := r.scanOneDerivative(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan derivative_position: %w", err)
+		}
+		positions = append(positions, pos)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate derivative_positions: %w", err)
+	}
+	return positions, nil
+}
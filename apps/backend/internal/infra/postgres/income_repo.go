@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/module/income"
+)
+
+// IncomeRepository implements the income repository using PostgreSQL
+type IncomeRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewIncomeRepository creates a new PostgreSQL income repository
+func NewIncomeRepository(pool *pgxpool.Pool) *IncomeRepository {
+	return &IncomeRepository{pool: pool}
+}
+
+// GetClassification retrieves the classification of a transaction
+func (r *IncomeRepository) GetClassification(ctx context.Context, txID uuid.UUID) (*income.Classification, error) {
+	query := `
+		SELECT transaction_id, category, classified_by, created_at, updated_at
+		FROM income_classifications
+		WHERE transaction_id = $1
+	`
+
+	c := &income.Classification{}
+	var classifiedBy *uuid.UUID
+	err := r.pool.QueryRow(ctx, query, txID).Scan(&c.TransactionID, &c.Category, &classifiedBy, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get income classification: %w", err)
+	}
+	if classifiedBy != nil {
+		c.ClassifiedBy = *classifiedBy
+	}
+	return c, nil
+}
+
+// UpsertClassification creates or replaces the classification of a transaction
+func (r *IncomeRepository) UpsertClassification(ctx context.Context, c *income.Classification) error {
+	query := `
+		INSERT INTO income_classifications (transaction_id, category, classified_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (transaction_id) DO UPDATE
+		SET category = EXCLUDED.category, classified_by = EXCLUDED.classified_by, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.pool.Exec(ctx, query, c.TransactionID, string(c.Category), c.ClassifiedBy, c.CreatedAt, c.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert income classification: %w", err)
+	}
+	return nil
+}
+
+// DeleteClassification removes the classification of a transaction
+func (r *IncomeRepository) DeleteClassification(ctx context.Context, txID uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM income_classifications WHERE transaction_id = $1`, txID); err != nil {
+		return fmt.Errorf("failed to delete income classification: %w", err)
+	}
+	return nil
+}
+
+// ListReceipts returns the per-asset wallet increases of candidate income
+// transactions. Amounts and USD values are summed per transaction and asset.
+func (r *IncomeRepository) ListReceipts(ctx context.Context, userID uuid.UUID, from, to time.Time, defaultTypes []ledger.TransactionType) ([]*income.Receipt, error) {
+	types := make([]string, 0, len(defaultTypes))
+	for _, t := range defaultTypes {
+		types = append(types, t.String())
+	}
+
+	query := `
+		SELECT t.id, t.type, t.wallet_id, t.occurred_at, e.asset_id,
+		       SUM(e.amount)::text, COALESCE(SUM(e.usd_value), 0)::text, c.category
+		FROM transactions t
+		LEFT JOIN income_classifications c ON c.transaction_id = t.id
+		JOIN entries e ON e.transaction_id = t.id AND e.entry_type = 'asset_increase'
+		JOIN accounts a ON a.id = e.account_id AND a.type = 'CRYPTO_WALLET'
+		WHERE (c.transaction_id IS NOT NULL OR t.type = ANY($4))
+		  AND t.occurred_at >= $2 AND t.occurred_at < $3
+		  AND t.status = 'COMPLETED'
+		  AND NOT EXISTS (
+		      SELECT 1 FROM transactions rv
+		      WHERE rv.source = 'reversal' AND rv.external_id = t.id::text
+		  )
+		  AND t.wallet_id IN (
+		      SELECT w.id FROM wallets w
+		      JOIN workspace_members m ON m.workspace_id = w.workspace_id
+		      WHERE m.user_id = $1
+		  )
+		GROUP BY t.id, t.type, t.wallet_id, t.occurred_at, e.asset_id, c.category
+		ORDER BY t.occurred_at, t.id, e.asset_id
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, from, to, types)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query income receipts: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*income.Receipt
+	for rows.Next() {
+		rc := &income.Receipt{}
+		var amount, usdValue string
+		var category sql.NullString
+		if err := rows.Scan(&rc.TransactionID, &rc.TransactionType, &rc.WalletID, &rc.OccurredAt, &rc.AssetID, &amount, &usdValue, &category); err != nil {
+			return nil, fmt.Errorf("failed to scan income receipt: %w", err)
+		}
+
+		var ok bool
+		if rc.Amount, ok = new(big.Int).SetString(amount, 10); !ok {
+			return nil, fmt.Errorf("invalid receipt amount: %s", amount)
+		}
+		if rc.USDValue, ok = new(big.Int).SetString(usdValue, 10); !ok {
+			return nil, fmt.Errorf("invalid receipt usd_value: %s", usdValue)
+		}
+		if category.Valid {
+			c := income.Category(category.String)
+			rc.Classified = &c
+		}
+		result = append(result, rc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating income receipts: %w", err)
+	}
+	return result, nil
+}
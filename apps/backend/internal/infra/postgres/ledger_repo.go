@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -376,7 +377,8 @@ func (r *LedgerRepository) GetTransaction(ctx context.Context, id uuid.UUID) (*l
 	var externalID, errorMessage sql.NullString
 	var walletID sql.NullString
 
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	q := r.getQueryer(ctx)
+	err := q.QueryRow(ctx, query, id).Scan(
 		&tx.ID,
 		&tx.Type,
 		&tx.Source,
@@ -511,52 +513,35 @@ func (r *LedgerRepository) FindTransactionsBySource(ctx context.Context, source
 
 // ListTransactions lists transactions with filters and pagination
 func (r *LedgerRepository) ListTransactions(ctx context.Context, filters ledger.TransactionFilters) ([]*ledger.Transaction, error) {
-	query := `
-		SELECT id, type, source, external_id, wallet_id, status, version, occurred_at, recorded_at, raw_data, metadata, error_message
-		FROM transactions
-		WHERE 1=1
-	`
-
-	args := make([]interface{}, 0)
-	argPos := 1
-
-	if filters.WalletID != nil {
-		query += fmt.Sprintf(" AND wallet_id = $%d", argPos)
-		args = append(args, *filters.WalletID)
-		argPos++
+	if !filters.SortBy.IsValid() {
+		return nil, ledger.ErrInvalidSortField
 	}
-
-	if filters.UserID != nil {
-		query += fmt.Sprintf(" AND wallet_id IN (SELECT id FROM wallets WHERE user_id = $%d)", argPos)
-		args = append(args, *filters.UserID)
-		argPos++
+	sortColumn := string(ledger.SortByOccurredAt)
+	if filters.SortBy != "" {
+		sortColumn = string(filters.SortBy)
 	}
-
-	if filters.Type != nil {
-		query += fmt.Sprintf(" AND type = $%d", argPos)
-		args = append(args, *filters.Type)
-		argPos++
+	direction, cmp := "DESC", "<"
+	if filters.SortAsc {
+		direction, cmp = "ASC", ">"
 	}
 
-	if filters.Status != nil {
-		query += fmt.Sprintf(" AND status = $%d", argPos)
-		args = append(args, string(*filters.Status))
-		argPos++
-	}
+	where, args := transactionFilterClause(filters)
+	argPos := len(args) + 1
 
-	if filters.FromDate != nil {
-		query += fmt.Sprintf(" AND occurred_at >= $%d", argPos)
-		args = append(args, *filters.FromDate)
-		argPos++
-	}
+	query := `
+		SELECT id, type, source, external_id, wallet_id, status, version, occurred_at, recorded_at, raw_data, metadata, error_message
+		FROM transactions t
+	` + where
 
-	if filters.ToDate != nil {
-		query += fmt.Sprintf(" AND occurred_at <= $%d", argPos)
-		args = append(args, *filters.ToDate)
-		argPos++
+	// Keyset pagination: (sort value, id) is unique, so rows inserted between
+	// page requests never shift or repeat the remaining pages
+	if filters.Cursor != nil {
+		query += fmt.Sprintf(" AND (%s, id) %s ($%d, $%d)", sortColumn, cmp, argPos, argPos+1)
+		args = append(args, filters.Cursor.SortValue, filters.Cursor.ID)
+		argPos += 2
 	}
 
-	query += " ORDER BY occurred_at DESC"
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s", sortColumn, direction, direction)
 
 	if filters.Limit > 0 {
 		query += fmt.Sprintf(" LIMIT $%d", argPos)
@@ -564,10 +549,9 @@ func (r *LedgerRepository) ListTransactions(ctx context.Context, filters ledger.
 		argPos++
 	}
 
-	if filters.Offset > 0 {
+	if filters.Offset > 0 && filters.Cursor == nil {
 		query += fmt.Sprintf(" OFFSET $%d", argPos)
 		args = append(args, filters.Offset)
-		argPos++
 	}
 
 	rows, err := r.pool.Query(ctx, query, args...)
@@ -640,6 +624,119 @@ func (r *LedgerRepository) ListTransactions(ctx context.Context, filters ledger.
 	return transactions, nil
 }
 
+// CountTransactions counts transactions matching filters, ignoring sort and pagination
+func (r *LedgerRepository) CountTransactions(ctx context.Context, filters ledger.TransactionFilters) (int, error) {
+	where, args := transactionFilterClause(filters)
+
+	var count int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM transactions t `+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count transactions: %w", err)
+	}
+	return count, nil
+}
+
+// transactionFilterClause builds the WHERE clause shared by ListTransactions
+// and CountTransactions. Reversals and reversed transactions are always
+// hidden. Raw data fields are read from the keys written by the sync
+// handlers (chain_id, protocol, tx_hash, from_address, to_address).
+func transactionFilterClause(filters ledger.TransactionFilters) (string, []interface{}) {
+	where := `
+		WHERE type <> 'reversal'
+		  AND NOT EXISTS (
+		      SELECT 1 FROM transactions r
+		      WHERE r.source = 'reversal' AND r.external_id = t.id::text
+		  )
+	`
+
+	args := make([]interface{}, 0)
+	argPos := 1
+	add := func(format string, value interface{}) {
+		where += fmt.Sprintf(format, argPos)
+		args = append(args, value)
+		argPos++
+	}
+
+	if filters.WalletID != nil {
+		add(" AND wallet_id = $%d", *filters.WalletID)
+	}
+
+	if filters.UserID != nil {
+		add(" AND wallet_id IN (SELECT w.id FROM wallets w JOIN workspace_members m ON m.workspace_id = w.workspace_id WHERE m.user_id = $%d)", *filters.UserID)
+	}
+
+	if filters.TagID != nil && filters.UserID != nil {
+		where += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM transaction_tags tt JOIN tags g ON g.id = tt.tag_id WHERE tt.transaction_id = t.id AND tt.tag_id = $%d AND g.user_id = $%d)", argPos, argPos+1)
+		args = append(args, *filters.TagID, *filters.UserID)
+		argPos += 2
+	}
+
+	if filters.Type != nil {
+		add(" AND type = $%d", *filters.Type)
+	}
+
+	if filters.Direction != nil {
+		types := make([]string, 0)
+		for _, t := range ledger.TransactionTypesWithDirection(*filters.Direction) {
+			types = append(types, t.String())
+		}
+		add(" AND type = ANY($%d)", types)
+	}
+
+	if filters.Status != nil {
+		add(" AND status = $%d", string(*filters.Status))
+	}
+
+	if filters.AssetID != nil {
+		add(" AND EXISTS (SELECT 1 FROM entries e WHERE e.transaction_id = t.id AND upper(e.asset_id) = upper($%d))", *filters.AssetID)
+	}
+
+	if filters.ChainID != nil {
+		add(" AND lower(raw_data->>'chain_id') = lower($%d)", *filters.ChainID)
+	}
+
+	if filters.Protocol != nil {
+		add(" AND lower(raw_data->>'protocol') = lower($%d)", *filters.Protocol)
+	}
+
+	if filters.TxHash != nil {
+		add(" AND lower(raw_data->>'tx_hash') = lower($%d)", *filters.TxHash)
+	}
+
+	if filters.Counterparty != nil {
+		add(" AND lower($%d) IN (lower(raw_data->>'from_address'), lower(raw_data->>'to_address'))", *filters.Counterparty)
+	}
+
+	if filters.MinUSD != nil {
+		add(" AND EXISTS (SELECT 1 FROM entries e WHERE e.transaction_id = t.id AND e.usd_value >= $%d::numeric)", filters.MinUSD.String())
+	}
+
+	if filters.MaxUSD != nil {
+		add(" AND COALESCE((SELECT MAX(e.usd_value) FROM entries e WHERE e.transaction_id = t.id), 0) <= $%d::numeric", filters.MaxUSD.String())
+	}
+
+	if filters.Search != nil {
+		add(` AND EXISTS (
+			SELECT 1 FROM jsonb_each_text(COALESCE(t.raw_data, '{}'::jsonb) || COALESCE(t.metadata, '{}'::jsonb)) kv
+			WHERE kv.value ILIKE $%d ESCAPE '\'
+		)`, "%"+escapeLike(*filters.Search)+"%")
+	}
+
+	if filters.FromDate != nil {
+		add(" AND occurred_at >= $%d", *filters.FromDate)
+	}
+
+	if filters.ToDate != nil {
+		add(" AND occurred_at <= $%d", *filters.ToDate)
+	}
+
+	return where, args
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 // Entry operations
 
 // GetEntriesByTransaction retrieves all entries for a transaction
@@ -651,7 +748,8 @@ func (r *LedgerRepository) GetEntriesByTransaction(ctx context.Context, transact
 		ORDER BY created_at ASC
 	`
 
-	rows, err := r.pool.Query(ctx, query, transactionID)
+	q := r.getQueryer(ctx)
+	rows, err := q.Query(ctx, query, transactionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query entries: %w", err)
 	}
@@ -679,10 +777,11 @@ func (r *LedgerRepository) GetEntriesByAccount(ctx context.Context, accountID uu
 		SELECT id, transaction_id, account_id, debit_credit, entry_type, amount, asset_id, usd_rate, usd_value, occurred_at, created_at, metadata
 		FROM entries
 		WHERE account_id = $1
-		ORDER BY occurred_at ASC
+		ORDER BY occurred_at ASC, created_at ASC
 	`
 
-	rows, err := r.pool.Query(ctx, query, accountID)
+	q := r.getQueryer(ctx)
+	rows, err := q.Query(ctx, query, accountID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query entries: %w", err)
 	}
@@ -907,6 +1006,37 @@ func (r *LedgerRepository) GetAccountBalances(ctx context.Context, accountID uui
 	return balances, nil
 }
 
+// HasEntriesAfter reports whether the account has entries in the asset dated strictly after the given time
+func (r *LedgerRepository) HasEntriesAfter(ctx context.Context, accountID uuid.UUID, assetID string, after time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM entries
+			WHERE account_id = $1 AND asset_id = $2 AND occurred_at > $3
+		)
+	`
+
+	var exists bool
+	q := r.getQueryer(ctx)
+	if err := q.QueryRow(ctx, query, accountID, assetID, after).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check later entries: %w", err)
+	}
+	return exists, nil
+}
+
+// MoveTransactionAnnotations re-points the tags, notes and income
+// classification of fromID to toID. toID must be a new transaction without
+// annotations of its own.
+func (r *LedgerRepository) MoveTransactionAnnotations(ctx context.Context, fromID, toID uuid.UUID) error {
+	q := r.getQueryer(ctx)
+	for _, table := range []string{"transaction_tags", "transaction_notes", "income_classifications"} {
+		query := `UPDATE ` + table + ` SET transaction_id = $2 WHERE transaction_id = $1`
+		if _, err := q.Exec(ctx, query, fromID, toID); err != nil {
+			return fmt.Errorf("failed to move %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
 // CalculateBalanceFromEntries calculates the balance from ledger entries (for verification)
 func (r *LedgerRepository) CalculateBalanceFromEntries(ctx context.Context, accountID uuid.UUID, assetID string) (*big.Int, error) {
 	query := `
@@ -519,6 +519,29 @@ func TestLedgerRepository_ListTransactions_Filters(t *testing.T) {
 	limited, err := repo.ListTransactions(ctx, ledger.TransactionFilters{Limit: 1})
 	require.NoError(t, err)
 	assert.Len(t, limited, 1)
+
+	// Keyset pagination continues after the newest transaction
+	assert.Equal(t, tx2.ID, limited[0].ID)
+	next, err := repo.ListTransactions(ctx, ledger.TransactionFilters{
+		Limit:  1,
+		Cursor: ledger.CursorAfter(limited[0], ledger.SortByOccurredAt),
+	})
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+	assert.Equal(t, tx1.ID, next[0].ID)
+
+	// Filter by direction and asset, with a matching count
+	in := "in"
+	asset := "btc"
+	filters := ledger.TransactionFilters{Direction: &in, AssetID: &asset}
+	incoming, err := repo.ListTransactions(ctx, filters)
+	require.NoError(t, err)
+	require.Len(t, incoming, 1)
+	assert.Equal(t, tx1.ID, incoming[0].ID)
+
+	count, err := repo.CountTransactions(ctx, filters)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
 }
 
 // Balance tests
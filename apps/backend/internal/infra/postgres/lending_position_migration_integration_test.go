@@ -0,0 +1,98 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func execMigration(t *testing.T, ctx context.Context, tx pgx.Tx, name string) {
+	t.Helper()
+	sql, err := os.ReadFile(filepath.Join("..", "..", "..", "migrations", name))
+	require.NoError(t, err)
+	_, err = tx.Exec(ctx, string(sql))
+	require.NoError(t, err, name)
+}
+
+func TestLendingPositionLegsMigration_CarriesInterest(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, testDB.Reset(ctx))
+
+	userID := createTestUser(t, ctx)
+	walletID := uuid.New()
+	_, err := testDB.Pool.Exec(ctx, `INSERT INTO wallets (id, user_id, name, address) VALUES ($1, $2, 'Main', $3)`,
+		walletID, userID, testAddress())
+	require.NoError(t, err)
+
+	// Schema changes roll back with the transaction
+	tx, err := testDB.Pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	execMigration(t, ctx, tx, "000036_lending_position_legs.down.sql")
+
+	openedAt := time.Now().UTC().Add(-30 * 24 * time.Hour)
+	// 1,000 USDC in, 200 out, 10 accrued; 0.1 WETH borrowed with 0.0003 accrued
+	_, err = tx.Exec(ctx, `
+		INSERT INTO lending_positions (
+			user_id, wallet_id, chain_id, protocol,
+			supply_asset, supply_amount, supply_decimals, total_supplied, total_withdrawn,
+			total_supplied_usd, total_withdrawn_usd, interest_earned_usd,
+			borrow_asset, borrow_amount, borrow_decimals, total_borrowed, total_borrowed_usd, interest_paid_usd,
+			opened_at
+		) VALUES (
+			$1, $2, 'ethereum', 'Aave V3',
+			'USDC', 810000000, 6, 1000000000, 200000000,
+			100000000000, 20000000000, 1000000000,
+			'WETH', 100300000000000000, 18, 100000000000000000, 30000000000, 90000000,
+			$3
+		)`, userID, walletID, openedAt)
+	require.NoError(t, err)
+	// 500 USDC in, 5 accrued, in a second row of the same account
+	_, err = tx.Exec(ctx, `
+		INSERT INTO lending_positions (
+			user_id, wallet_id, chain_id, protocol,
+			supply_asset, supply_amount, supply_decimals, total_supplied,
+			total_supplied_usd, interest_earned_usd, opened_at
+		) VALUES ($1, $2, 'ethereum', 'Aave V3', 'USDC', 505000000, 6, 500000000, 50000000000, 500000000, $3)`,
+		userID, walletID, openedAt.Add(time.Hour))
+	require.NoError(t, err)
+
+	execMigration(t, ctx, tx, "000036_lending_position_legs.up.sql")
+
+	rows, err := tx.Query(ctx, `
+		SELECT l.side, l.asset, l.amount::text, l.interest::text, l.interest_usd::text
+		FROM lending_position_legs l
+		JOIN lending_positions p ON p.id = l.position_id
+		WHERE p.wallet_id = $1
+		ORDER BY l.side DESC`, walletID)
+	require.NoError(t, err)
+	type leg struct{ side, asset, amount, interest, interestUSD string }
+	var legs []leg
+	for rows.Next() {
+		var l leg
+		require.NoError(t, rows.Scan(&l.side, &l.asset, &l.amount, &l.interest, &l.interestUSD))
+		legs = append(legs, l)
+	}
+	require.NoError(t, rows.Err())
+
+	require.Len(t, legs, 2)
+	assert.Equal(t, leg{"supply", "USDC", "1315000000", "15000000", "1500000000"}, legs[0])
+	assert.Equal(t, leg{"borrow", "WETH", "100300000000000000", "300000000000000", "90000000"}, legs[1])
+
+	var earned, paid string
+	require.NoError(t, tx.QueryRow(ctx, `
+		SELECT interest_earned_usd::text, interest_paid_usd::text
+		FROM lending_positions WHERE wallet_id = $1`, walletID).Scan(&earned, &paid))
+	assert.Equal(t, "1500000000", earned)
+	assert.Equal(t, "90000000", paid)
+}
@@ -21,122 +21,179 @@ func NewLendingPositionRepo(pool *pgxpool.Pool) *LendingPositionRepo {
 }
 
 func (r *LendingPositionRepo) Create(ctx context.Context, pos *lendingposition.LendingPosition) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
 	query := `
 		INSERT INTO lending_positions (
 			id, user_id, wallet_id, chain_id, protocol,
-			supply_asset, supply_amount, supply_decimals, supply_contract,
-			borrow_asset, borrow_amount, borrow_decimals, borrow_contract,
-			total_supplied, total_withdrawn, total_borrowed, total_repaid,
 			total_supplied_usd, total_withdrawn_usd, total_borrowed_usd, total_repaid_usd,
-			interest_earned_usd,
+			interest_earned_usd, interest_paid_usd,
+			total_liquidated_usd, liquidation_penalty_usd, last_liquidated_at,
 			status, opened_at, closed_at,
 			created_at, updated_at
 		) VALUES (
 			$1, $2, $3, $4, $5,
 			$6, $7, $8, $9,
-			$10, $11, $12, $13,
-			$14, $15, $16, $17,
-			$18, $19, $20, $21,
-			$22,
-			$23, $24, $25,
-			$26, $27
+			$10, $11,
+			$12, $13, $14,
+			$15, $16, $17,
+			$18, $19
 		)
 	`
 
-	supplyContract := sql.NullString{String: pos.SupplyContract, Valid: pos.SupplyContract != ""}
-	borrowAsset := sql.NullString{String: pos.BorrowAsset, Valid: pos.BorrowAsset != ""}
-	borrowContract := sql.NullString{String: pos.BorrowContract, Valid: pos.BorrowContract != ""}
-
-	_, err := r.pool.Exec(ctx, query,
+	_, err = tx.Exec(ctx, query,
 		pos.ID, pos.UserID, pos.WalletID, pos.ChainID, pos.Protocol,
-		pos.SupplyAsset, pos.SupplyAmount.String(), pos.SupplyDecimals, supplyContract,
-		borrowAsset, pos.BorrowAmount.String(), pos.BorrowDecimals, borrowContract,
-		pos.TotalSupplied.String(), pos.TotalWithdrawn.String(), pos.TotalBorrowed.String(), pos.TotalRepaid.String(),
 		pos.TotalSuppliedUSD.String(), pos.TotalWithdrawnUSD.String(), pos.TotalBorrowedUSD.String(), pos.TotalRepaidUSD.String(),
-		pos.InterestEarnedUSD.String(),
+		pos.InterestEarnedUSD.String(), bigIntOrZero(pos.InterestPaidUSD),
+		bigIntOrZero(pos.TotalLiquidatedUSD), bigIntOrZero(pos.LiquidationPenaltyUSD), pos.LastLiquidatedAt,
 		string(pos.Status), pos.OpenedAt, pos.ClosedAt,
 		pos.CreatedAt, pos.UpdatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("insert lending_position: %w", err)
 	}
+
+	if err := upsertLendingLegs(ctx, tx, pos.Legs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
 func (r *LendingPositionRepo) Update(ctx context.Context, pos *lendingposition.LendingPosition) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
 	query := `
 		UPDATE lending_positions SET
-			supply_amount = $1, borrow_asset = $2, borrow_amount = $3,
-			borrow_decimals = $4, borrow_contract = $5,
-			total_supplied = $6, total_withdrawn = $7, total_borrowed = $8, total_repaid = $9,
-			total_supplied_usd = $10, total_withdrawn_usd = $11, total_borrowed_usd = $12, total_repaid_usd = $13,
-			interest_earned_usd = $14,
-			status = $15, closed_at = $16,
-			updated_at = $17
-		WHERE id = $18
+			total_supplied_usd = $1, total_withdrawn_usd = $2, total_borrowed_usd = $3, total_repaid_usd = $4,
+			interest_earned_usd = $5, interest_paid_usd = $6,
+			supply_value_usd = $7, borrow_value_usd = $8,
+			liquidation_threshold_bps = $9, ltv_bps = $10,
+			health_factor = $11, risk_updated_at = $12,
+			total_liquidated_usd = $13, liquidation_penalty_usd = $14, last_liquidated_at = $15,
+			status = $16, closed_at = $17,
+			updated_at = $18
+		WHERE id = $19
 	`
 
-	borrowAsset := sql.NullString{String: pos.BorrowAsset, Valid: pos.BorrowAsset != ""}
-	borrowContract := sql.NullString{String: pos.BorrowContract, Valid: pos.BorrowContract != ""}
-
-	_, err := r.pool.Exec(ctx, query,
-		pos.SupplyAmount.String(), borrowAsset, pos.BorrowAmount.String(),
-		pos.BorrowDecimals, borrowContract,
-		pos.TotalSupplied.String(), pos.TotalWithdrawn.String(), pos.TotalBorrowed.String(), pos.TotalRepaid.String(),
+	_, err = tx.Exec(ctx, query,
 		pos.TotalSuppliedUSD.String(), pos.TotalWithdrawnUSD.String(), pos.TotalBorrowedUSD.String(), pos.TotalRepaidUSD.String(),
-		pos.InterestEarnedUSD.String(),
+		pos.InterestEarnedUSD.String(), bigIntOrZero(pos.InterestPaidUSD),
+		nullBigInt(pos.SupplyValueUSD), nullBigInt(pos.BorrowValueUSD),
+		nullInt(pos.LiquidationThresholdBps), nullInt(pos.LTVBps),
+		nullBigInt(pos.HealthFactor), pos.RiskUpdatedAt,
+		bigIntOrZero(pos.TotalLiquidatedUSD), bigIntOrZero(pos.LiquidationPenaltyUSD), pos.LastLiquidatedAt,
 		string(pos.Status), pos.ClosedAt,
 		pos.UpdatedAt, pos.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("update lending_position: %w", err)
 	}
+
+	if err := upsertLendingLegs(ctx, tx, pos.Legs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// upsertLendingLegs inserts new legs and overwrites existing ones. Legs are
+// never removed from a position, so there is nothing to delete.
+func upsertLendingLegs(ctx context.Context, tx pgx.Tx, legs []*lendingposition.Leg) error {
+	query := `
+		INSERT INTO lending_position_legs (
+			id, position_id, side, asset, decimals, contract,
+			amount, total_in, total_out, total_in_usd, total_out_usd,
+			interest, interest_usd,
+			usd_price, value_usd, liquidation_price_usd,
+			created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6,
+			$7, $8, $9, $10, $11,
+			$12, $13,
+			$14, $15, $16,
+			$17, $18
+		)
+		ON CONFLICT (id) DO UPDATE SET
+			contract = EXCLUDED.contract,
+			amount = EXCLUDED.amount,
+			total_in = EXCLUDED.total_in,
+			total_out = EXCLUDED.total_out,
+			total_in_usd = EXCLUDED.total_in_usd,
+			total_out_usd = EXCLUDED.total_out_usd,
+			interest = EXCLUDED.interest,
+			interest_usd = EXCLUDED.interest_usd,
+			usd_price = EXCLUDED.usd_price,
+			value_usd = EXCLUDED.value_usd,
+			liquidation_price_usd = EXCLUDED.liquidation_price_usd,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	for _, l := range legs {
+		contract := sql.NullString{String: l.Contract, Valid: l.Contract != ""}
+		_, err := tx.Exec(ctx, query,
+			l.ID, l.PositionID, string(l.Side), l.Asset, l.Decimals, contract,
+			bigIntOrZero(l.Amount), bigIntOrZero(l.TotalIn), bigIntOrZero(l.TotalOut), bigIntOrZero(l.TotalInUSD), bigIntOrZero(l.TotalOutUSD),
+			bigIntOrZero(l.Interest), bigIntOrZero(l.InterestUSD),
+			nullBigInt(l.USDPrice), nullBigInt(l.ValueUSD), nullBigInt(l.LiquidationPriceUSD),
+			l.CreatedAt, l.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("upsert lending_position_leg: %w", err)
+		}
+	}
 	return nil
 }
 
 const lendingSelectColumns = `
 	id, user_id, wallet_id, chain_id, protocol,
-	supply_asset, supply_amount, supply_decimals, supply_contract,
-	borrow_asset, borrow_amount, borrow_decimals, borrow_contract,
-	total_supplied, total_withdrawn, total_borrowed, total_repaid,
 	total_supplied_usd, total_withdrawn_usd, total_borrowed_usd, total_repaid_usd,
-	interest_earned_usd,
+	interest_earned_usd, interest_paid_usd,
+	supply_value_usd, borrow_value_usd,
+	liquidation_threshold_bps, ltv_bps,
+	health_factor, risk_updated_at,
+	total_liquidated_usd, liquidation_penalty_usd, last_liquidated_at,
 	status, opened_at, closed_at,
 	created_at, updated_at
 `
 
 func (r *LendingPositionRepo) GetByID(ctx context.Context, id uuid.UUID) (*lendingposition.LendingPosition, error) {
 	query := `SELECT ` + lendingSelectColumns + ` FROM lending_positions WHERE id = $1`
-
-	pos, err := r.scanOneLending(r.pool.QueryRow(ctx, query, id))
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("get lending_position by id: %w", err)
-	}
-	return pos, nil
+	return r.getOne(ctx, "get lending_position by id", query, id)
 }
 
-func (r *LendingPositionRepo) FindActiveByWalletAndAsset(ctx context.Context, walletID uuid.UUID, protocol, chainID, supplyAsset, borrowAsset string) (*lendingposition.LendingPosition, error) {
+func (r *LendingPositionRepo) FindActive(ctx context.Context, walletID uuid.UUID, protocol, chainID string) (*lendingposition.LendingPosition, error) {
 	query := `SELECT ` + lendingSelectColumns + `
 		FROM lending_positions
-		WHERE wallet_id = $1 AND protocol = $2 AND chain_id = $3 AND supply_asset = $4 AND status = 'active'`
-	args := []any{walletID, protocol, chainID, supplyAsset}
-
-	if borrowAsset != "" {
-		query += ` AND borrow_asset = $5`
-		args = append(args, borrowAsset)
-	}
-
-	query += ` LIMIT 1`
+		WHERE wallet_id = $1 AND protocol = $2 AND chain_id = $3 AND status = 'active'
+		LIMIT 1`
+	return r.getOne(ctx, "find active lending_position", query, walletID, protocol, chainID)
+}
 
+func (r *LendingPositionRepo) getOne(ctx context.Context, op, query string, args ...any) (*lendingposition.LendingPosition, error) {
 	pos, err := r.scanOneLending(r.pool.QueryRow(ctx, query, args...))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("find active lending_position: %w", err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := r.loadLegs(ctx, []*lendingposition.LendingPosition{pos}); err != nil {
+		return nil, err
 	}
 	return pos, nil
 }
@@ -164,26 +221,139 @@ func (r *LendingPositionRepo) ListByUser(ctx context.Context, userID uuid.UUID,
 
 	query += " ORDER BY opened_at DESC"
 
-	return r.scanManyLending(ctx, query, args...)
+	positions, err := r.scanManyLending(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadLegs(ctx, positions); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+func (r *LendingPositionRepo) ListByWallets(ctx context.Context, walletIDs []uuid.UUID, status *lendingposition.Status, chainID *string) ([]*lendingposition.LendingPosition, error) {
+	query := `SELECT ` + lendingSelectColumns + ` FROM lending_positions WHERE wallet_id = ANY($1)`
+	args := []any{walletIDs}
+	argPos := 2
+
+	if status != nil {
+		query += fmt.Sprintf(" AND status = $%d", argPos)
+		args = append(args, string(*status))
+		argPos++
+	}
+	if chainID != nil {
+		query += fmt.Sprintf(" AND chain_id = $%d", argPos)
+		args = append(args, *chainID)
+		argPos++
+	}
+
+	query += " ORDER BY opened_at DESC"
+
+	positions, err := r.scanManyLending(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadLegs(ctx, positions); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// loadLegs attaches their legs to positions with a single query
+func (r *LendingPositionRepo) loadLegs(ctx context.Context, positions []*lendingposition.LendingPosition) error {
+	if len(positions) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(positions))
+	byID := make(map[uuid.UUID]*lendingposition.LendingPosition, len(positions))
+	for i, pos := range positions {
+		ids[i] = pos.ID
+		byID[pos.ID] = pos
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, position_id, side, asset, decimals, contract,
+			amount, total_in, total_out, total_in_usd, total_out_usd,
+			interest, interest_usd,
+			usd_price, value_usd, liquidation_price_usd,
+			created_at, updated_at
+		FROM lending_position_legs
+		WHERE position_id = ANY($1)
+		ORDER BY side DESC, created_at, id
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("query lending_position_legs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l lendingposition.Leg
+		var side string
+		var contract sql.NullString
+		var amount, totalIn, totalOut, totalInUSD, totalOutUSD, interest, interestUSD string
+		var usdPrice, valueUSD, liquidationPrice sql.NullString
+
+		err := rows.Scan(
+			&l.ID, &l.PositionID, &side, &l.Asset, &l.Decimals, &contract,
+			&amount, &totalIn, &totalOut, &totalInUSD, &totalOutUSD,
+			&interest, &interestUSD,
+			&usdPrice, &valueUSD, &liquidationPrice,
+			&l.CreatedAt, &l.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("scan lending_position_leg: %w", err)
+		}
+
+		l.Side = lendingposition.Side(side)
+		if contract.Valid {
+			l.Contract = contract.String
+		}
+		l.Amount = parseBigInt(amount)
+		l.TotalIn = parseBigInt(totalIn)
+		l.TotalOut = parseBigInt(totalOut)
+		l.TotalInUSD = parseBigInt(totalInUSD)
+		l.TotalOutUSD = parseBigInt(totalOutUSD)
+		l.Interest = parseBigInt(interest)
+		l.InterestUSD = parseBigInt(interestUSD)
+		if usdPrice.Valid {
+			l.USDPrice = parseBigInt(usdPrice.String)
+		}
+		if valueUSD.Valid {
+			l.ValueUSD = parseBigInt(valueUSD.String)
+		}
+		if liquidationPrice.Valid {
+			l.LiquidationPriceUSD = parseBigInt(liquidationPrice.String)
+		}
+
+		if pos, ok := byID[l.PositionID]; ok {
+			pos.Legs = append(pos.Legs, &l)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate lending_position_legs: %w", err)
+	}
+	return nil
 }
 
 func (r *LendingPositionRepo) scanOneLending(row pgx.Row) (*lendingposition.LendingPosition, error) {
 	var pos lendingposition.LendingPosition
-	var supplyContract, borrowAsset, borrowContract sql.NullString
 	var status string
 
-	var supplyAmount, borrowAmount string
-	var totalSupplied, totalWithdrawn, totalBorrowed, totalRepaid string
 	var totalSuppliedUSD, totalWithdrawnUSD, totalBorrowedUSD, totalRepaidUSD string
-	var interestEarnedUSD string
+	var interestEarnedUSD, interestPaidUSD string
+	var totalLiquidatedUSD, liquidationPenaltyUSD string
+	var supplyValueUSD, borrowValueUSD, healthFactor sql.NullString
+	var liquidationThreshold, ltvBps sql.NullInt32
 
 	err := row.Scan(
 		&pos.ID, &pos.UserID, &pos.WalletID, &pos.ChainID, &pos.Protocol,
-		&pos.SupplyAsset, &supplyAmount, &pos.SupplyDecimals, &supplyContract,
-		&borrowAsset, &borrowAmount, &pos.BorrowDecimals, &borrowContract,
-		&totalSupplied, &totalWithdrawn, &totalBorrowed, &totalRepaid,
 		&totalSuppliedUSD, &totalWithdrawnUSD, &totalBorrowedUSD, &totalRepaidUSD,
-		&interestEarnedUSD,
+		&interestEarnedUSD, &interestPaidUSD,
+		&supplyValueUSD, &borrowValueUSD,
+		&liquidationThreshold, &ltvBps,
+		&healthFactor, &pos.RiskUpdatedAt,
+		&totalLiquidatedUSD, &liquidationPenaltyUSD, &pos.LastLiquidatedAt,
 		&status, &pos.OpenedAt, &pos.ClosedAt,
 		&pos.CreatedAt, &pos.UpdatedAt,
 	)
@@ -191,29 +361,28 @@ func (r *LendingPositionRepo) scanOneLending(row pgx.Row) (*lendingposition.Lend
 		return nil, err
 	}
 
-	if supplyContract.Valid {
-		pos.SupplyContract = supplyContract.String
-	}
-	if borrowAsset.Valid {
-		pos.BorrowAsset = borrowAsset.String
-	}
-	if borrowContract.Valid {
-		pos.BorrowContract = borrowContract.String
-	}
-
 	pos.Status = lendingposition.Status(status)
 
-	pos.SupplyAmount = parseBigInt(supplyAmount)
-	pos.BorrowAmount = parseBigInt(borrowAmount)
-	pos.TotalSupplied = parseBigInt(totalSupplied)
-	pos.TotalWithdrawn = parseBigInt(totalWithdrawn)
-	pos.TotalBorrowed = parseBigInt(totalBorrowed)
-	pos.TotalRepaid = parseBigInt(totalRepaid)
 	pos.TotalSuppliedUSD = parseBigInt(totalSuppliedUSD)
 	pos.TotalWithdrawnUSD = parseBigInt(totalWithdrawnUSD)
 	pos.TotalBorrowedUSD = parseBigInt(totalBorrowedUSD)
 	pos.TotalRepaidUSD = parseBigInt(totalRepaidUSD)
 	pos.InterestEarnedUSD = parseBigInt(interestEarnedUSD)
+	pos.InterestPaidUSD = parseBigInt(interestPaidUSD)
+	pos.TotalLiquidatedUSD = parseBigInt(totalLiquidatedUSD)
+	pos.LiquidationPenaltyUSD = parseBigInt(liquidationPenaltyUSD)
+
+	if supplyValueUSD.Valid {
+		pos.SupplyValueUSD = parseBigInt(supplyValueUSD.String)
+	}
+	if borrowValueUSD.Valid {
+		pos.BorrowValueUSD = parseBigInt(borrowValueUSD.String)
+	}
+	if healthFactor.Valid {
+		pos.HealthFactor = parseBigInt(healthFactor.String)
+	}
+	pos.LiquidationThresholdBps = intPtr(liquidationThreshold)
+	pos.LTVBps = intPtr(ltvBps)
 
 	return &pos, nil
 }
@@ -30,6 +30,10 @@ func (r *LPPositionRepo) Create(ctx context.Context, pos *lpposition.LPPosition)
 			total_deposited_token0, total_deposited_token1, total_withdrawn_token0, total_withdrawn_token1,
 			total_claimed_token0, total_claimed_token1,
 			status, opened_at, closed_at, realized_pnl_usd, apr_bps,
+			capital_usd, capital_usd_seconds, capital_updated_at,
+			current_value_usd, unrealized_pnl_usd, fee_apr_bps, total_apr_bps, valued_at,
+			tick_lower, tick_upper, current_tick, in_range,
+			uncollected_fees_token0, uncollected_fees_token1, range_updated_at,
 			created_at, updated_at
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7,
@@ -38,7 +42,11 @@ func (r *LPPositionRepo) Create(ctx context.Context, pos *lpposition.LPPosition)
 			$17, $18, $19, $20,
 			$21, $22,
 			$23, $24, $25, $26, $27,
-			$28, $29
+			$28, $29, $30,
+			$31, $32, $33, $34, $35,
+			$36, $37, $38, $39,
+			$40, $41, $42,
+			$43, $44
 		)
 	`
 
@@ -61,6 +69,10 @@ func (r *LPPositionRepo) Create(ctx context.Context, pos *lpposition.LPPosition)
 		pos.TotalWithdrawnToken0.String(), pos.TotalWithdrawnToken1.String(),
 		pos.TotalClaimedToken0.String(), pos.TotalClaimedToken1.String(),
 		string(pos.Status), pos.OpenedAt, pos.ClosedAt, realizedPnL, aprBps,
+		bigIntOrZero(pos.CapitalUSD), bigIntOrZero(pos.CapitalUSDSeconds), pos.CapitalUpdatedAt,
+		nullBigInt(pos.CurrentValueUSD), nullBigInt(pos.UnrealizedPnLUSD), nullInt(pos.FeeAPRBps), nullInt(pos.TotalAPRBps), pos.ValuedAt,
+		nullInt(pos.TickLower), nullInt(pos.TickUpper), nullInt(pos.CurrentTick), pos.InRange,
+		nullBigInt(pos.UncollectedFeesToken0), nullBigInt(pos.UncollectedFeesToken1), pos.RangeUpdatedAt,
 		pos.CreatedAt, pos.UpdatedAt,
 	)
 	if err != nil {
@@ -77,8 +89,12 @@ func (r *LPPositionRepo) Update(ctx context.Context, pos *lpposition.LPPosition)
 			total_withdrawn_token0 = $6, total_withdrawn_token1 = $7,
 			total_claimed_token0 = $8, total_claimed_token1 = $9,
 			status = $10, closed_at = $11, realized_pnl_usd = $12, apr_bps = $13,
-			updated_at = $14
-		WHERE id = $15
+			capital_usd = $14, capital_usd_seconds = $15, capital_updated_at = $16,
+			current_value_usd = $17, unrealized_pnl_usd = $18, fee_apr_bps = $19, total_apr_bps = $20, valued_at = $21,
+			tick_lower = $22, tick_upper = $23, current_tick = $24, in_range = $25,
+			uncollected_fees_token0 = $26, uncollected_fees_token1 = $27, range_updated_at = $28,
+			updated_at = $29
+		WHERE id = $30
 	`
 
 	var realizedPnL sql.NullString
@@ -96,6 +112,10 @@ func (r *LPPositionRepo) Update(ctx context.Context, pos *lpposition.LPPosition)
 		pos.TotalWithdrawnToken0.String(), pos.TotalWithdrawnToken1.String(),
 		pos.TotalClaimedToken0.String(), pos.TotalClaimedToken1.String(),
 		string(pos.Status), pos.ClosedAt, realizedPnL, aprBps,
+		bigIntOrZero(pos.CapitalUSD), bigIntOrZero(pos.CapitalUSDSeconds), pos.CapitalUpdatedAt,
+		nullBigInt(pos.CurrentValueUSD), nullBigInt(pos.UnrealizedPnLUSD), nullInt(pos.FeeAPRBps), nullInt(pos.TotalAPRBps), pos.ValuedAt,
+		nullInt(pos.TickLower), nullInt(pos.TickUpper), nullInt(pos.CurrentTick), pos.InRange,
+		nullBigInt(pos.UncollectedFeesToken0), nullBigInt(pos.UncollectedFeesToken1), pos.RangeUpdatedAt,
 		pos.UpdatedAt, pos.ID,
 	)
 	if err != nil {
@@ -111,6 +131,10 @@ const selectColumns = `
 	total_deposited_token0, total_deposited_token1, total_withdrawn_token0, total_withdrawn_token1,
 	total_claimed_token0, total_claimed_token1,
 	status, opened_at, closed_at, realized_pnl_usd, apr_bps,
+	capital_usd, capital_usd_seconds, capital_updated_at,
+	current_value_usd, unrealized_pnl_usd, fee_apr_bps, total_apr_bps, valued_at,
+	tick_lower, tick_upper, current_tick, in_range,
+	uncollected_fees_token0, uncollected_fees_token1, range_updated_at,
 	created_at, updated_at
 `
 
@@ -140,6 +164,23 @@ func (r *LPPositionRepo) GetByNFTTokenID(ctx context.Context, walletID uuid.UUID
 	return pos, nil
 }
 
+func (r *LPPositionRepo) GetOpenByContract(ctx context.Context, walletID uuid.UUID, chainID, contractAddress string) (*lpposition.LPPosition, error) {
+	query := `SELECT ` + selectColumns + `
+		FROM lp_positions
+		WHERE wallet_id = $1 AND chain_id = $2 AND contract_address = $3
+		  AND nft_token_id IS NULL AND status = 'open'
+	`
+
+	pos, err := r.scanOne(r.pool.QueryRow(ctx, query, walletID, chainID, contractAddress))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get open lp_position by contract: %w", err)
+	}
+	return pos, nil
+}
+
 func (r *LPPositionRepo) FindOpenByTokenPair(ctx context.Context, walletID uuid.UUID, chainID, protocol, token0, token1 string) ([]*lpposition.LPPosition, error) {
 	query := `SELECT ` + selectColumns + `
 		FROM lp_positions
@@ -180,6 +221,27 @@ func (r *LPPositionRepo) ListByUser(ctx context.Context, userID uuid.UUID, statu
 	return r.scanMany(ctx, query, args...)
 }
 
+func (r *LPPositionRepo) ListByWallets(ctx context.Context, walletIDs []uuid.UUID, status *lpposition.Status, chainID *string) ([]*lpposition.LPPosition, error) {
+	query := `SELECT ` + selectColumns + ` FROM lp_positions WHERE wallet_id = ANY($1)`
+	args := []any{walletIDs}
+	argPos := 2
+
+	if status != nil {
+		query += fmt.Sprintf(" AND status = $%d", argPos)
+		args = append(args, string(*status))
+		argPos++
+	}
+	if chainID != nil {
+		query += fmt.Sprintf(" AND chain_id = $%d", argPos)
+		args = append(args, *chainID)
+		argPos++
+	}
+
+	query += " ORDER BY opened_at DESC"
+
+	return r.scanMany(ctx, query, args...)
+}
+
 func (r *LPPositionRepo) scanOne(row pgx.Row) (*lpposition.LPPosition, error) {
 	var pos lpposition.LPPosition
 	var nftTokenID, contractAddress sql.NullString
@@ -190,6 +252,12 @@ func (r *LPPositionRepo) scanOne(row pgx.Row) (*lpposition.LPPosition, error) {
 
 	var depositedUSD, withdrawnUSD, claimedFeesUSD string
 	var depositedT0, depositedT1, withdrawnT0, withdrawnT1, claimedT0, claimedT1 string
+	var capitalUSD, capitalUSDSeconds string
+	var currentValue, unrealizedPnL sql.NullString
+	var feeAPRBps, totalAPRBps sql.NullInt32
+	var tickLower, tickUpper, currentTick sql.NullInt32
+	var inRange sql.NullBool
+	var uncollected0, uncollected1 sql.NullString
 
 	err := row.Scan(
 		&pos.ID, &pos.UserID, &pos.WalletID, &pos.ChainID, &pos.Protocol, &nftTokenID, &contractAddress,
@@ -198,6 +266,10 @@ func (r *LPPositionRepo) scanOne(row pgx.Row) (*lpposition.LPPosition, error) {
 		&depositedT0, &depositedT1, &withdrawnT0, &withdrawnT1,
 		&claimedT0, &claimedT1,
 		&status, &pos.OpenedAt, &pos.ClosedAt, &realizedPnL, &aprBps,
+		&capitalUSD, &capitalUSDSeconds, &pos.CapitalUpdatedAt,
+		&currentValue, &unrealizedPnL, &feeAPRBps, &totalAPRBps, &pos.ValuedAt,
+		&tickLower, &tickUpper, &currentTick, &inRange,
+		&uncollected0, &uncollected1, &pos.RangeUpdatedAt,
 		&pos.CreatedAt, &pos.UpdatedAt,
 	)
 	if err != nil {
@@ -240,6 +312,31 @@ func (r *LPPositionRepo) scanOne(row pgx.Row) (*lpposition.LPPosition, error) {
 	pos.TotalClaimedToken0 = parseBigInt(claimedT0)
 	pos.TotalClaimedToken1 = parseBigInt(claimedT1)
 
+	pos.CapitalUSD = parseBigInt(capitalUSD)
+	pos.CapitalUSDSeconds = parseBigInt(capitalUSDSeconds)
+	if currentValue.Valid {
+		pos.CurrentValueUSD = parseBigInt(currentValue.String)
+	}
+	if unrealizedPnL.Valid {
+		pos.UnrealizedPnLUSD = parseBigInt(unrealizedPnL.String)
+	}
+	pos.FeeAPRBps = intPtr(feeAPRBps)
+	pos.TotalAPRBps = intPtr(totalAPRBps)
+
+	pos.TickLower = intPtr(tickLower)
+	pos.TickUpper = intPtr(tickUpper)
+	pos.CurrentTick = intPtr(currentTick)
+	if inRange.Valid {
+		v := inRange.Bool
+		pos.InRange = &v
+	}
+	if uncollected0.Valid {
+		pos.UncollectedFeesToken0 = parseBigInt(uncollected0.String)
+	}
+	if uncollected1.Valid {
+		pos.UncollectedFeesToken1 = parseBigInt(uncollected1.String)
+	}
+
 	return &pos, nil
 }
 
@@ -264,6 +361,35 @@ func (r *LPPositionRepo) scanMany(ctx context.Context, query string, args ...any
 	return positions, nil
 }
 
+func bigIntOrZero(v *big.Int) string {
+	if v == nil {
+		return "0"
+	}
+	return v.String()
+}
+
+func nullBigInt(v *big.Int) sql.NullString {
+	if v == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: v.String(), Valid: true}
+}
+
+func nullInt(v *int) sql.NullInt32 {
+	if v == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: int32(*v), Valid: true}
+}
+
+func intPtr(v sql.NullInt32) *int {
+	if !v.Valid {
+		return nil
+	}
+	i := int(v.Int32)
+	return &i
+}
+
 func parseBigInt(s string) *big.Int {
 	v, ok := new(big.Int).SetString(s, 10)
 	if !ok {
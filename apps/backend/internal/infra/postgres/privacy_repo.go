@@ -0,0 +1,221 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kislikjeka/moontrack/internal/platform/privacy"
+)
+
+// PrivacyRepository exports and erases a user's data across all tables
+type PrivacyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPrivacyRepository creates a new PostgreSQL privacy repository
+func NewPrivacyRepository(pool *pgxpool.Pool) *PrivacyRepository {
+	return &PrivacyRepository{pool: pool}
+}
+
+// Subqueries scoping rows to a user; all take the user ID as $1
+const (
+	userWalletIDs  = `SELECT id FROM wallets WHERE user_id = $1`
+	userAccountIDs = `SELECT id FROM accounts WHERE wallet_id IN (` + userWalletIDs + `)`
+	userLotIDs     = `SELECT id FROM tax_lots WHERE account_id IN (` + userAccountIDs + `)`
+	userTxIDs      = `SELECT transaction_id FROM entries WHERE account_id IN (` + userAccountIDs + `)
+		UNION SELECT id FROM transactions WHERE wallet_id IN (` + userWalletIDs + `)`
+)
+
+// exportQueries produce one JSON array per section. Rows are serialized with
+// to_jsonb so new columns are exported without code changes.
+var exportQueries = []struct {
+	section string
+	query   string
+}{
+	{privacy.SectionProfile, `
+		SELECT COALESCE(jsonb_agg(to_jsonb(u) - 'password_hash'), '[]')
+		FROM users u WHERE u.id = $1`},
+	{privacy.SectionWallets, `
+		SELECT COALESCE(jsonb_agg(to_jsonb(w) ORDER BY w.created_at), '[]')
+		FROM wallets w WHERE w.user_id = $1`},
+	{privacy.SectionTransactions, `
+		SELECT COALESCE(jsonb_agg(
+			to_jsonb(t) || jsonb_build_object('entries', (
+				SELECT COALESCE(jsonb_agg(to_jsonb(e) ORDER BY e.created_at, e.id), '[]')
+				FROM entries e WHERE e.transaction_id = t.id
+			)) ORDER BY t.occurred_at), '[]')
+		FROM transactions t WHERE t.id IN (` + userTxIDs + `)`},
+	{privacy.SectionRawTransactions, `
+		SELECT COALESCE(jsonb_agg(to_jsonb(r) ORDER BY r.mined_at), '[]')
+		FROM raw_transactions r WHERE r.wallet_id IN (` + userWalletIDs + `)`},
+	{privacy.SectionTaxLots, `
+		SELECT COALESCE(jsonb_agg(to_jsonb(l) ORDER BY l.acquired_at), '[]')
+		FROM tax_lots l WHERE l.id IN (` + userLotIDs + `)`},
+	{privacy.SectionLotDisposals, `
+		SELECT COALESCE(jsonb_agg(to_jsonb(d) ORDER BY d.disposed_at), '[]')
+		FROM lot_disposals d WHERE d.lot_id IN (` + userLotIDs + `)`},
+	{privacy.SectionLotOverrides, `
+		SELECT COALESCE(jsonb_agg(to_jsonb(h) ORDER BY h.changed_at), '[]')
+		FROM lot_override_history h WHERE h.lot_id IN (` + userLotIDs + `)`},
+	{privacy.SectionLPPositions, `
+		SELECT COALESCE(jsonb_agg(to_jsonb(p) ORDER BY p.opened_at), '[]')
+		FROM lp_positions p WHERE p.user_id = $1`},
+	{privacy.SectionLendingPositions, `
+		SELECT COALESCE(jsonb_agg(
+			to_jsonb(p) || jsonb_build_object('legs', (
+				SELECT COALESCE(jsonb_agg(to_jsonb(l) ORDER BY l.created_at), '[]')
+				FROM lending_position_legs l WHERE l.position_id = p.id
+			)) ORDER BY p.opened_at), '[]')
+		FROM lending_positions p WHERE p.user_id = $1`},
+	{privacy.SectionStakingPositions, `
+		SELECT COALESCE(jsonb_agg(to_jsonb(p) ORDER BY p.opened_at), '[]')
+		FROM staking_positions p WHERE p.user_id = $1`},
+	{privacy.SectionDerivativePositions, `
+		SELECT COALESCE(jsonb_agg(to_jsonb(p) ORDER BY p.opened_at), '[]')
+		FROM derivative_positions p WHERE p.user_id = $1`},
+	{privacy.SectionTags, `
+		SELECT COALESCE(jsonb_agg(
+			to_jsonb(g) || jsonb_build_object('transaction_ids', (
+				SELECT COALESCE(jsonb_agg(tt.transaction_id ORDER BY tt.created_at), '[]')
+				FROM transaction_tags tt WHERE tt.tag_id = g.id
+			)) ORDER BY g.created_at), '[]')
+		FROM tags g WHERE g.user_id = $1`},
+	{privacy.SectionTransactionNotes, `
+		SELECT COALESCE(jsonb_agg(to_jsonb(n) ORDER BY n.updated_at), '[]')
+		FROM transaction_notes n WHERE n.user_id = $1`},
+	{privacy.SectionIncomeClassifications, `
+		SELECT COALESCE(jsonb_agg(to_jsonb(c) ORDER BY c.created_at), '[]')
+		FROM income_classifications c WHERE c.classified_by = $1`},
+	{privacy.SectionTaxProfile, `
+		SELECT COALESCE(jsonb_agg(to_jsonb(p)), '[]')
+		FROM tax_profiles p WHERE p.user_id = $1`},
+	{privacy.SectionAuditLog, `
+		SELECT COALESCE(jsonb_agg(to_jsonb(a) ORDER BY a.created_at), '[]')
+		FROM audit_log a WHERE a.actor_id = $1`},
+}
+
+// ExportUserData reads every section from a single snapshot so the bundle is consistent
+func (r *PrivacyRepository) ExportUserData(ctx context.Context, userID uuid.UUID) ([]privacy.Section, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin export transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	sections := make([]privacy.Section, 0, len(exportQueries))
+	for _, q := range exportQueries {
+		var data []byte
+		if err := tx.QueryRow(ctx, q.query, userID).Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", q.section, err)
+		}
+		sections = append(sections, privacy.Section{Name: q.section, Data: json.RawMessage(data)})
+	}
+	return sections, nil
+}
+
+// DeleteUserData erases the user in one transaction. Rows that do not cascade
+// from users (ledger history, tax lots, positions, audit trail) are removed
+// explicitly, children first.
+func (r *PrivacyRepository) DeleteUserData(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	// Ledger transactions and lots must be resolved before entries disappear
+	txIDs, err := collectIDs(ctx, tx, userTxIDs, userID)
+	if err != nil {
+		return fmt.Errorf("failed to collect transactions: %w", err)
+	}
+	lotIDs, err := collectIDs(ctx, tx, userLotIDs+` UNION SELECT id FROM tax_lots WHERE transaction_id = ANY($2)`, userID, txIDs)
+	if err != nil {
+		return fmt.Errorf("failed to collect tax lots: %w", err)
+	}
+
+	steps := []struct {
+		name  string
+		query string
+		args  []any
+	}{
+		// Other members' wallets in workspaces this user owns move back to
+		// their creators' personal workspaces instead of cascading away. A
+		// member who only ever added wallets to shared workspaces has no
+		// personal workspace yet, so one is created first.
+		{"member personal workspaces", `
+			INSERT INTO workspaces (owner_id, name, is_personal)
+			SELECT DISTINCT w.user_id, 'Personal', TRUE
+			FROM wallets w
+			WHERE w.user_id <> $1
+			  AND w.workspace_id IN (SELECT id FROM workspaces WHERE owner_id = $1)
+			ON CONFLICT (owner_id) WHERE is_personal DO NOTHING`,
+			[]any{userID}},
+		{"member personal memberships", `
+			INSERT INTO workspace_members (workspace_id, user_id, role)
+			SELECT p.id, p.owner_id, 'owner'
+			FROM workspaces p
+			WHERE p.is_personal AND p.owner_id IN (
+				SELECT w.user_id FROM wallets w
+				WHERE w.user_id <> $1
+				  AND w.workspace_id IN (SELECT id FROM workspaces WHERE owner_id = $1))
+			ON CONFLICT (workspace_id, user_id) DO NOTHING`,
+			[]any{userID}},
+		{"reassign shared wallets", `
+			UPDATE wallets w
+			SET workspace_id = p.id
+			FROM workspaces p
+			WHERE p.owner_id = w.user_id AND p.is_personal
+			  AND w.user_id <> $1
+			  AND w.workspace_id IN (SELECT id FROM workspaces WHERE owner_id = $1)`,
+			[]any{userID}},
+		{"lot overrides", `DELETE FROM lot_override_history WHERE lot_id = ANY($1)`, []any{lotIDs}},
+		{"lot disposals", `DELETE FROM lot_disposals WHERE lot_id = ANY($1) OR transaction_id = ANY($2)`, []any{lotIDs, txIDs}},
+		{"tax lots", `DELETE FROM tax_lots WHERE id = ANY($1)`, []any{lotIDs}},
+		{"lp positions", `DELETE FROM lp_positions WHERE user_id = $1 OR wallet_id IN (` + userWalletIDs + `)`, []any{userID}},
+		{"lending positions", `DELETE FROM lending_positions WHERE user_id = $1 OR wallet_id IN (` + userWalletIDs + `)`, []any{userID}},
+		{"staking positions", `DELETE FROM staking_positions WHERE user_id = $1 OR wallet_id IN (` + userWalletIDs + `)`, []any{userID}},
+		{"derivative positions", `DELETE FROM derivative_positions WHERE user_id = $1 OR wallet_id IN (` + userWalletIDs + `)`, []any{userID}},
+		{"raw transactions", `DELETE FROM raw_transactions WHERE wallet_id IN (` + userWalletIDs + `) OR ledger_tx_id = ANY($2)`, []any{userID, txIDs}},
+		{"entries", `DELETE FROM entries WHERE transaction_id = ANY($1)`, []any{txIDs}},
+		{"transactions", `DELETE FROM transactions WHERE id = ANY($1)`, []any{txIDs}},
+		// Permits the append-only audit_log trigger to delete for this transaction only
+		{"enable audit erasure", `SELECT set_config('moontrack.user_erasure', 'on', true)`, nil},
+		{"audit log", `DELETE FROM audit_log WHERE actor_id = $1`, []any{userID}},
+		// Cascades to wallets, accounts, balances, workspaces, memberships, share links, tags, notes and tax profile
+		{"user", `DELETE FROM users WHERE id = $1`, []any{userID}},
+	}
+
+	for _, step := range steps {
+		if _, err := tx.Exec(ctx, step.query, step.args...); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", step.name, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit user deletion: %w", err)
+	}
+	return nil
+}
+
+func collectIDs(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]uuid.UUID, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
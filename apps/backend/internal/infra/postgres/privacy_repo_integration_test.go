@@ -0,0 +1,236 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seededRow identifies a row seeded for a user by one of its columns
+type seededRow struct {
+	table  string
+	column string
+	id     uuid.UUID
+}
+
+func testAddress() string {
+	return "0x" + strings.ReplaceAll(uuid.NewString(), "-", "") + "00000000"
+}
+
+// seedUserData inserts a row into every table that holds user data and
+// returns them together with the user's shared workspace.
+func seedUserData(t *testing.T, ctx context.Context, userID uuid.UUID) ([]seededRow, uuid.UUID) {
+	t.Helper()
+	exec := func(query string, args ...any) {
+		t.Helper()
+		_, err := testDB.Pool.Exec(ctx, query, args...)
+		require.NoError(t, err)
+	}
+
+	short := userID.String()[:8]
+	now := time.Now().UTC()
+	walletID, walletAcctID, incomeAcctID := uuid.New(), uuid.New(), uuid.New()
+	txID, rawTxID := uuid.New(), uuid.New()
+	lotID, disposalID, overrideID := uuid.New(), uuid.New(), uuid.New()
+	lpID, lendingID, legID, stakingID, derivativeID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	sharedWorkspaceID, invitationID, shareLinkID := uuid.New(), uuid.New(), uuid.New()
+	tagID, auditID := uuid.New(), uuid.New()
+
+	// The personal workspace and its owner membership are created by the wallets trigger
+	exec(`INSERT INTO wallets (id, user_id, name, address) VALUES ($1, $2, $3, $4)`,
+		walletID, userID, "Wallet "+short, testAddress())
+	var personalWorkspaceID uuid.UUID
+	require.NoError(t, testDB.Pool.QueryRow(ctx,
+		`SELECT workspace_id FROM wallets WHERE id = $1`, walletID).Scan(&personalWorkspaceID))
+
+	exec(`INSERT INTO workspaces (id, name, owner_id) VALUES ($1, $2, $3)`,
+		sharedWorkspaceID, "Shared "+short, userID)
+	exec(`INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, 'owner')`,
+		sharedWorkspaceID, userID)
+	exec(`INSERT INTO workspace_invitations (id, workspace_id, email, role, token, invited_by, expires_at)
+		VALUES ($1, $2, $3, 'viewer', $4, $5, $6)`,
+		invitationID, sharedWorkspaceID, "invitee-"+short+"@example.com", strings.ReplaceAll(invitationID.String(), "-", ""), userID, now.Add(24*time.Hour))
+	exec(`INSERT INTO share_links (id, user_id, scopes, expires_at) VALUES ($1, $2, ARRAY['portfolio'], $3)`,
+		shareLinkID, userID, now.Add(24*time.Hour))
+
+	exec(`INSERT INTO accounts (id, code, type, asset_id, wallet_id, chain_id) VALUES ($1, $2, 'CRYPTO_WALLET', 'ETH', $3, 'ethereum')`,
+		walletAcctID, "wallet."+walletID.String()+".ethereum.ETH", walletID)
+	exec(`INSERT INTO accounts (id, code, type, asset_id) VALUES ($1, $2, 'INCOME', 'ETH')`,
+		incomeAcctID, "income."+short+".ETH")
+	exec(`INSERT INTO account_balances (account_id, asset_id, balance, usd_value) VALUES ($1, 'ETH', 100, 20000)`,
+		walletAcctID)
+
+	exec(`INSERT INTO transactions (id, type, source, status, occurred_at, raw_data, wallet_id)
+		VALUES ($1, 'transfer_in', 'zerion', 'COMPLETED', $2, '{}', $3)`,
+		txID, now, walletID)
+	exec(`INSERT INTO entries (transaction_id, account_id, debit_credit, entry_type, amount, asset_id, usd_rate, usd_value, occurred_at)
+		VALUES ($1, $2, 'DEBIT', 'asset_increase', 100, 'ETH', 200, 20000, $4),
+		       ($1, $3, 'CREDIT', 'income', 100, 'ETH', 200, 20000, $4)`,
+		txID, walletAcctID, incomeAcctID, now)
+	exec(`INSERT INTO raw_transactions (id, wallet_id, zerion_id, tx_hash, chain_id, operation_type, mined_at, raw_json, ledger_tx_id)
+		VALUES ($1, $2, $3, $4, 'ethereum', 'receive', $5, '{}', $6)`,
+		rawTxID, walletID, "zerion-"+short, "0x"+short, now, txID)
+
+	exec(`INSERT INTO tax_lots (id, transaction_id, account_id, asset, quantity_acquired, quantity_remaining, acquired_at, auto_cost_basis_per_unit, auto_cost_basis_source, override_cost_basis_per_unit)
+		VALUES ($1, $2, $3, 'ETH', 100, 60, $4, 200, 'fmv_at_transfer', 150)`,
+		lotID, txID, walletAcctID, now)
+	exec(`INSERT INTO lot_disposals (id, transaction_id, lot_id, quantity_disposed, proceeds_per_unit, disposal_type, disposed_at)
+		VALUES ($1, $2, $3, 40, 250, 'sale', $4)`,
+		disposalID, txID, lotID, now)
+	exec(`INSERT INTO lot_override_history (id, lot_id, previous_cost_basis, new_cost_basis, reason)
+		VALUES ($1, $2, 200, 150, 'bought OTC')`,
+		overrideID, lotID)
+
+	exec(`INSERT INTO lp_positions (id, user_id, wallet_id, chain_id, protocol, token0_symbol, token1_symbol, token0_decimals, token1_decimals, opened_at)
+		VALUES ($1, $2, $3, 'ethereum', 'uniswap-v3', 'ETH', 'USDC', 18, 6, $4)`,
+		lpID, userID, walletID, now)
+	exec(`INSERT INTO lending_positions (id, user_id, wallet_id, chain_id, protocol, opened_at)
+		VALUES ($1, $2, $3, 'ethereum', 'aave-v3', $4)`,
+		lendingID, userID, walletID, now)
+	exec(`INSERT INTO lending_position_legs (id, position_id, side, asset) VALUES ($1, $2, 'supply', 'ETH')`,
+		legID, lendingID)
+	exec(`INSERT INTO staking_positions (id, user_id, wallet_id, chain_id, protocol, asset, opened_at)
+		VALUES ($1, $2, $3, 'ethereum', 'lido', 'ETH', $4)`,
+		stakingID, userID, walletID, now)
+	exec(`INSERT INTO derivative_positions (id, user_id, wallet_id, chain_id, protocol, asset, opened_at)
+		VALUES ($1, $2, $3, 'arbitrum', 'gmx', 'ETH', $4)`,
+		derivativeID, userID, walletID, now)
+
+	exec(`INSERT INTO tags (id, user_id, name) VALUES ($1, $2, 'defi')`, tagID, userID)
+	exec(`INSERT INTO transaction_tags (transaction_id, tag_id) VALUES ($1, $2)`, txID, tagID)
+	exec(`INSERT INTO transaction_notes (transaction_id, user_id, body) VALUES ($1, $2, 'airdrop')`, txID, userID)
+	exec(`INSERT INTO income_classifications (transaction_id, category, classified_by) VALUES ($1, 'airdrop', $2)`, txID, userID)
+	exec(`INSERT INTO tax_profiles (user_id, jurisdiction) VALUES ($1, 'US')`, userID)
+	exec(`INSERT INTO audit_log (id, actor_id, action, target_type) VALUES ($1, $2, 'wallet.create', 'wallet')`, auditID, userID)
+
+	rows := []seededRow{
+		{"users", "id", userID},
+		{"workspaces", "id", personalWorkspaceID},
+		{"workspaces", "id", sharedWorkspaceID},
+		{"workspace_members", "user_id", userID},
+		{"workspace_invitations", "id", invitationID},
+		{"share_links", "id", shareLinkID},
+		{"wallets", "id", walletID},
+		{"accounts", "id", walletAcctID},
+		{"account_balances", "account_id", walletAcctID},
+		{"transactions", "id", txID},
+		{"entries", "transaction_id", txID},
+		{"raw_transactions", "id", rawTxID},
+		{"tax_lots", "id", lotID},
+		{"lot_disposals", "id", disposalID},
+		{"lot_override_history", "id", overrideID},
+		{"lp_positions", "id", lpID},
+		{"lending_positions", "id", lendingID},
+		{"lending_position_legs", "id", legID},
+		{"staking_positions", "id", stakingID},
+		{"derivative_positions", "id", derivativeID},
+		{"tags", "id", tagID},
+		{"transaction_tags", "tag_id", tagID},
+		{"transaction_notes", "transaction_id", txID},
+		{"income_classifications", "transaction_id", txID},
+		{"tax_profiles", "user_id", userID},
+		{"audit_log", "id", auditID},
+	}
+	return rows, sharedWorkspaceID
+}
+
+func countRows(t *testing.T, ctx context.Context, row seededRow) int {
+	t.Helper()
+	var n int
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s = $1`, row.table, row.column)
+	require.NoError(t, testDB.Pool.QueryRow(ctx, query, row.id).Scan(&n))
+	return n
+}
+
+func TestPrivacyRepository_DeleteUserData(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, testDB.Reset(ctx))
+	repo := NewPrivacyRepository(testDB.Pool)
+
+	userID := createTestUser(t, ctx)
+	otherID := createTestUser(t, ctx)
+	deleted, sharedWorkspaceID := seedUserData(t, ctx, userID)
+	kept, _ := seedUserData(t, ctx, otherID)
+
+	// The other user keeps a wallet in the deleted user's shared workspace
+	memberWalletID := uuid.New()
+	_, err := testDB.Pool.Exec(ctx, `INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, 'editor')`,
+		sharedWorkspaceID, otherID)
+	require.NoError(t, err)
+	_, err = testDB.Pool.Exec(ctx, `INSERT INTO wallets (id, user_id, workspace_id, name, address) VALUES ($1, $2, $3, 'Shared wallet', $4)`,
+		memberWalletID, otherID, sharedWorkspaceID, testAddress())
+	require.NoError(t, err)
+
+	require.NoError(t, repo.DeleteUserData(ctx, userID))
+
+	for _, row := range deleted {
+		assert.Zero(t, countRows(t, ctx, row), "%s row %s should be erased", row.table, row.id)
+	}
+	for _, row := range kept {
+		assert.NotZero(t, countRows(t, ctx, row), "%s row %s of another user should survive", row.table, row.id)
+	}
+
+	// The shared wallet moves back to its creator's personal workspace
+	var ownerID uuid.UUID
+	var isPersonal bool
+	require.NoError(t, testDB.Pool.QueryRow(ctx, `
+		SELECT ws.owner_id, ws.is_personal
+		FROM wallets w JOIN workspaces ws ON ws.id = w.workspace_id
+		WHERE w.id = $1`, memberWalletID).Scan(&ownerID, &isPersonal))
+	assert.Equal(t, otherID, ownerID)
+	assert.True(t, isPersonal)
+}
+
+func TestPrivacyRepository_DeleteUserData_MemberWithoutPersonalWorkspace(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, testDB.Reset(ctx))
+	repo := NewPrivacyRepository(testDB.Pool)
+
+	userID := createTestUser(t, ctx)
+	memberID := createTestUser(t, ctx)
+	_, sharedWorkspaceID := seedUserData(t, ctx, userID)
+
+	// The member only ever added a wallet to the shared workspace, so the
+	// trigger never created a personal workspace for them
+	memberWalletID, memberTxID := uuid.New(), uuid.New()
+	_, err := testDB.Pool.Exec(ctx, `INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, 'editor')`,
+		sharedWorkspaceID, memberID)
+	require.NoError(t, err)
+	_, err = testDB.Pool.Exec(ctx, `INSERT INTO wallets (id, user_id, workspace_id, name, address) VALUES ($1, $2, $3, 'Shared wallet', $4)`,
+		memberWalletID, memberID, sharedWorkspaceID, testAddress())
+	require.NoError(t, err)
+	_, err = testDB.Pool.Exec(ctx, `INSERT INTO transactions (id, type, source, status, occurred_at, raw_data, wallet_id)
+		VALUES ($1, 'transfer_in', 'zerion', 'COMPLETED', NOW(), '{}', $2)`,
+		memberTxID, memberWalletID)
+	require.NoError(t, err)
+
+	var personal int
+	require.NoError(t, testDB.Pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM workspaces WHERE owner_id = $1 AND is_personal`, memberID).Scan(&personal))
+	require.Zero(t, personal)
+
+	require.NoError(t, repo.DeleteUserData(ctx, userID))
+
+	var workspaceID, ownerID uuid.UUID
+	var isPersonal bool
+	require.NoError(t, testDB.Pool.QueryRow(ctx, `
+		SELECT ws.id, ws.owner_id, ws.is_personal
+		FROM wallets w JOIN workspaces ws ON ws.id = w.workspace_id
+		WHERE w.id = $1`, memberWalletID).Scan(&workspaceID, &ownerID, &isPersonal))
+	assert.Equal(t, memberID, ownerID)
+	assert.True(t, isPersonal)
+
+	var role string
+	require.NoError(t, testDB.Pool.QueryRow(ctx,
+		`SELECT role FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`, workspaceID, memberID).Scan(&role))
+	assert.Equal(t, "owner", role)
+	assert.Equal(t, 1, countRows(t, ctx, seededRow{"transactions", "id", memberTxID}))
+}
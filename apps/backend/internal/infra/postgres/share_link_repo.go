@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kislikjeka/moontrack/internal/module/sharing"
+)
+
+// ShareLinkRepository implements the share link repository using PostgreSQL
+type ShareLinkRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewShareLinkRepository creates a new PostgreSQL share link repository
+func NewShareLinkRepository(pool *pgxpool.Pool) *ShareLinkRepository {
+	return &ShareLinkRepository{pool: pool}
+}
+
+const shareLinkColumns = `id, user_id, label, scopes, wallet_ids, hide_amounts, expires_at, revoked_at, last_accessed_at, created_at`
+
+// Create inserts a new share link
+func (r *ShareLinkRepository) Create(ctx context.Context, link *sharing.ShareLink) error {
+	query := `
+		INSERT INTO share_links (id, user_id, label, scopes, wallet_ids, hide_amounts, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	scopes := make([]string, len(link.Scopes))
+	for i, s := range link.Scopes {
+		scopes[i] = string(s)
+	}
+	walletIDs := link.WalletIDs
+	if walletIDs == nil {
+		walletIDs = []uuid.UUID{}
+	}
+
+	_, err := r.pool.Exec(ctx, query,
+		link.ID, link.UserID, link.Label, scopes, walletIDs, link.HideAmounts, link.ExpiresAt, link.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert share link: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a share link by ID
+func (r *ShareLinkRepository) GetByID(ctx context.Context, id uuid.UUID) (*sharing.ShareLink, error) {
+	query := `SELECT ` + shareLinkColumns + ` FROM share_links WHERE id = $1`
+
+	link, err := scanShareLink(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get share link: %w", err)
+	}
+	return link, nil
+}
+
+// ListByUser retrieves all share links created by the user, newest first
+func (r *ShareLinkRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*sharing.ShareLink, error) {
+	query := `SELECT ` + shareLinkColumns + ` FROM share_links WHERE user_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query share links: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*sharing.ShareLink
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan share link: %w", err)
+		}
+		result = append(result, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating share links: %w", err)
+	}
+	return result, nil
+}
+
+// Revoke marks a share link as revoked
+func (r *ShareLinkRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE share_links SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	if _, err := r.pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+	return nil
+}
+
+// TouchAccessed records the time the link was last used
+func (r *ShareLinkRepository) TouchAccessed(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE share_links SET last_accessed_at = NOW() WHERE id = $1`
+	if _, err := r.pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to update share link access time: %w", err)
+	}
+	return nil
+}
+
+func scanShareLink(row pgx.Row) (*sharing.ShareLink, error) {
+	link := &sharing.ShareLink{}
+	var scopes []string
+	err := row.Scan(
+		&link.ID, &link.UserID, &link.Label, &scopes, &link.WalletIDs, &link.HideAmounts,
+		&link.ExpiresAt, &link.RevokedAt, &link.LastAccessedAt, &link.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	link.Scopes = make([]sharing.Scope, len(scopes))
+	for i, s := range scopes {
+		link.Scopes[i] = sharing.Scope(s)
+	}
+	return link, nil
+}
@@ -0,0 +1,242 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kislikjeka/moontrack/internal/platform/stakingposition"
+)
+
+type StakingPositionRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewStakingPositionRepo(pool *pgxpool.Pool) *StakingPositionRepo {
+	return &StakingPositionRepo{pool: pool}
+}
+
+func (r *StakingPositionRepo) Create(ctx context.Context, pos *stakingposition.StakingPosition) error {
+	query := `
+		INSERT INTO staking_positions (
+			id, user_id, wallet_id, chain_id, protocol,
+			asset, asset_decimals, staked_amount,
+			receipt_asset, receipt_decimals, receipt_contract, receipt_amount, rebasing,
+			total_staked, total_unstaked, total_staked_usd, total_unstaked_usd,
+			rewards_amount, rewards_usd,
+			status, opened_at, closed_at,
+			created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5,
+			$6, $7, $8,
+			$9, $10, $11, $12, $13,
+			$14, $15, $16, $17,
+			$18, $19,
+			$20, $21, $22,
+			$23, $24
+		)
+	`
+
+	receiptAsset := sql.NullString{String: pos.ReceiptAsset, Valid: pos.ReceiptAsset != ""}
+	receiptContract := sql.NullString{String: pos.ReceiptContract, Valid: pos.ReceiptContract != ""}
+
+	_, err := r.pool.Exec(ctx, query,
+		pos.ID, pos.UserID, pos.WalletID, pos.ChainID, pos.Protocol,
+		pos.Asset, pos.AssetDecimals, pos.StakedAmount.String(),
+		receiptAsset, pos.ReceiptDecimals, receiptContract, pos.ReceiptAmount.String(), pos.Rebasing,
+		pos.TotalStaked.String(), pos.TotalUnstaked.String(), pos.TotalStakedUSD.String(), pos.TotalUnstakedUSD.String(),
+		pos.RewardsAmount.String(), pos.RewardsUSD.String(),
+		string(pos.Status), pos.OpenedAt, pos.ClosedAt,
+		pos.CreatedAt, pos.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert staking_position: %w", err)
+	}
+	return nil
+}
+
+func (r *StakingPositionRepo) Update(ctx context.Context, pos *stakingposition.StakingPosition) error {
+	query := `
+		UPDATE staking_positions SET
+			staked_amount = $1, receipt_amount = $2,
+			total_staked = $3, total_unstaked = $4, total_staked_usd = $5, total_unstaked_usd = $6,
+			rewards_amount = $7, rewards_usd = $8,
+			status = $9, closed_at = $10,
+			updated_at = $11
+		WHERE id = $12
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		pos.StakedAmount.String(), pos.ReceiptAmount.String(),
+		pos.TotalStaked.String(), pos.TotalUnstaked.String(), pos.TotalStakedUSD.String(), pos.TotalUnstakedUSD.String(),
+		pos.RewardsAmount.String(), pos.RewardsUSD.String(),
+		string(pos.Status), pos.ClosedAt,
+		pos.UpdatedAt, pos.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update staking_position: %w", err)
+	}
+	return nil
+}
+
+const stakingSelectColumns = `
+	id, user_id, wallet_id, chain_id, protocol,
+	asset, asset_decimals, staked_amount,
+	receipt_asset, receipt_decimals, receipt_contract, receipt_amount, rebasing,
+	total_staked, total_unstaked, total_staked_usd, total_unstaked_usd,
+	rewards_amount, rewards_usd,
+	status, opened_at, closed_at,
+	created_at, updated_at
+`
+
+func (r *StakingPositionRepo) GetByID(ctx context.Context, id uuid.UUID) (*stakingposition.StakingPosition, error) {
+	query := `SELECT ` + stakingSelectColumns + ` FROM staking_positions WHERE id = $1`
+
+	pos, err := r.scanOneStaking(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get staking_position by id: %w", err)
+	}
+	return pos, nil
+}
+
+func (r *StakingPositionRepo) FindActiveByWalletAndAsset(ctx context.Context, walletID uuid.UUID, protocol, chainID, asset string) (*stakingposition.StakingPosition, error) {
+	query := `SELECT ` + stakingSelectColumns + `
+		FROM staking_positions
+		WHERE wallet_id = $1 AND protocol = $2 AND chain_id = $3 AND asset = $4 AND status = 'active'
+		LIMIT 1`
+
+	pos, err := r.scanOneStaking(r.pool.QueryRow(ctx, query, walletID, protocol, chainID, asset))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("find active staking_position: %w", err)
+	}
+	return pos, nil
+}
+
+func (r *StakingPositionRepo) ListActiveByWallet(ctx context.Context, walletID uuid.UUID) ([]*stakingposition.StakingPosition, error) {
+	query := `SELECT ` + stakingSelectColumns + `
+		FROM staking_positions
+		WHERE wallet_id = $1 AND status = 'active'
+		ORDER BY opened_at`
+
+	return r.scanManyStaking(ctx, query, walletID)
+}
+
+func (r *StakingPositionRepo) ListByUser(ctx context.Context, userID uuid.UUID, status *stakingposition.Status, walletID *uuid.UUID, chainID *string) ([]*stakingposition.StakingPosition, error) {
+	query := `SELECT ` + stakingSelectColumns + ` FROM staking_positions WHERE user_id = $1`
+	args := []any{userID}
+	argPos := 2
+
+	if status != nil {
+		query += fmt.Sprintf(" AND status = $%d", argPos)
+		args = append(args, string(*status))
+		argPos++
+	}
+	if walletID != nil {
+		query += fmt.Sprintf(" AND wallet_id = $%d", argPos)
+		args = append(args, *walletID)
+		argPos++
+	}
+	if chainID != nil {
+		query += fmt.Sprintf(" AND chain_id = $%d", argPos)
+		args = append(args, *chainID)
+	}
+
+	query += " ORDER BY opened_at DESC"
+
+	return r.scanManyStaking(ctx, query, args...)
+}
+
+func (r *StakingPositionRepo) ListByWallets(ctx context.Context, walletIDs []uuid.UUID, status *stakingposition.Status, chainID *string) ([]*stakingposition.StakingPosition, error) {
+	query := `SELECT ` + stakingSelectColumns + ` FROM staking_positions WHERE wallet_id = ANY($1)`
+	args := []any{walletIDs}
+	argPos := 2
+
+	if status != nil {
+		query += fmt.Sprintf(" AND status = $%d", argPos)
+		args = append(args, string(*status))
+		argPos++
+	}
+	if chainID != nil {
+		query += fmt.Sprintf(" AND chain_id = $%d", argPos)
+		args = append(args, *chainID)
+	}
+
+	query += " ORDER BY opened_at DESC"
+
+	return r.scanManyStaking(ctx, query, args...)
+}
+
+func (r *StakingPositionRepo) scanOneStaking(row pgx.Row) (*stakingposition.StakingPosition, error) {
+	var pos stakingposition.StakingPosition
+	var receiptAsset, receiptContract sql.NullString
+	var status string
+
+	var stakedAmount, receiptAmount string
+	var totalStaked, totalUnstaked, totalStakedUSD, totalUnstakedUSD string
+	var rewardsAmount, rewardsUSD string
+
+	err := row.Scan(
+		&pos.ID, &pos.UserID, &pos.WalletID, &pos.ChainID, &pos.Protocol,
+		&pos.Asset, &pos.AssetDecimals, &stakedAmount,
+		&receiptAsset, &pos.ReceiptDecimals, &receiptContract, &receiptAmount, &pos.Rebasing,
+		&totalStaked, &totalUnstaked, &totalStakedUSD, &totalUnstakedUSD,
+		&rewardsAmount, &rewardsUSD,
+		&status, &pos.OpenedAt, &pos.ClosedAt,
+		&pos.CreatedAt, &pos.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if receiptAsset.Valid {
+		pos.ReceiptAsset = receiptAsset.String
+	}
+	if receiptContract.Valid {
+		pos.ReceiptContract = receiptContract.String
+	}
+
+	pos.Status = stakingposition.Status(status)
+
+	pos.StakedAmount = parseBigInt(stakedAmount)
+	pos.ReceiptAmount = parseBigInt(receiptAmount)
+	pos.TotalStaked = parseBigInt(totalStaked)
+	pos.TotalUnstaked = parseBigInt(totalUnstaked)
+	pos.TotalStakedUSD = parseBigInt(totalStakedUSD)
+	pos.TotalUnstakedUSD = parseBigInt(totalUnstakedUSD)
+	pos.RewardsAmount = parseBigInt(rewardsAmount)
+	pos.RewardsUSD = parseBigInt(rewardsUSD)
+
+	return &pos, nil
+}
+
+func (r *StakingPositionRepo) scanManyStaking(ctx context.Context, query string, args ...any) ([]*stakingposition.StakingPosition, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query staking_positions: %w", err)
+	}
+	defer rows.Close()
+
+	var positions []*stakingposition.StakingPosition
+	for rows.Next() {
+		pos, err := r.scanOneStaking(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan staking_position: %w", err)
+		}
+		positions = append(positions, pos)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate staking_positions: %w", err)
+	}
+	return positions, nil
+}
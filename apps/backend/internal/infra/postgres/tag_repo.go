@@ -0,0 +1,292 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kislikjeka/moontrack/internal/module/tagging"
+)
+
+// TagRepository implements the tagging repository using PostgreSQL
+type TagRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewTagRepository creates a new PostgreSQL tag repository
+func NewTagRepository(pool *pgxpool.Pool) *TagRepository {
+	return &TagRepository{pool: pool}
+}
+
+const tagColumns = `id, user_id, name, category, color, created_at, updated_at`
+
+// CreateTag inserts a new tag
+func (r *TagRepository) CreateTag(ctx context.Context, tag *tagging.Tag) error {
+	query := `
+		INSERT INTO tags (id, user_id, name, category, color, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		tag.ID, tag.UserID, tag.Name, tag.Category, tag.Color, tag.CreatedAt, tag.UpdatedAt,
+	)
+	if err != nil {
+		if isTagUniqueViolation(err) {
+			return tagging.ErrDuplicateTag
+		}
+		return fmt.Errorf("failed to insert tag: %w", err)
+	}
+	return nil
+}
+
+// GetTag retrieves a tag by ID
+func (r *TagRepository) GetTag(ctx context.Context, id uuid.UUID) (*tagging.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags WHERE id = $1`
+
+	tag, err := scanTag(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	return tag, nil
+}
+
+// ListTags retrieves all tags of a user ordered by category and name
+func (r *TagRepository) ListTags(ctx context.Context, userID uuid.UUID) ([]*tagging.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags WHERE user_id = $1 ORDER BY category, lower(name)`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	return collectTags(rows)
+}
+
+// UpdateTag updates a tag's editable fields
+func (r *TagRepository) UpdateTag(ctx context.Context, tag *tagging.Tag) error {
+	query := `
+		UPDATE tags SET name = $2, category = $3, color = $4, updated_at = $5
+		WHERE id = $1
+	`
+
+	cmd, err := r.pool.Exec(ctx, query, tag.ID, tag.Name, tag.Category, tag.Color, tag.UpdatedAt)
+	if err != nil {
+		if isTagUniqueViolation(err) {
+			return tagging.ErrDuplicateTag
+		}
+		return fmt.Errorf("failed to update tag: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return tagging.ErrTagNotFound
+	}
+	return nil
+}
+
+// DeleteTag deletes a tag; its transaction assignments cascade
+func (r *TagRepository) DeleteTag(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM tags WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	return nil
+}
+
+// SetTransactionTags replaces the user's tags on a transaction atomically.
+// Tags of other users on the same transaction are left alone.
+func (r *TagRepository) SetTransactionTags(ctx context.Context, userID, txID uuid.UUID, tagIDs []uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM transaction_tags
+		WHERE transaction_id = $1
+		  AND tag_id IN (SELECT id FROM tags WHERE user_id = $2)
+	`, txID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to clear transaction tags: %w", err)
+	}
+
+	if len(tagIDs) > 0 {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO transaction_tags (transaction_id, tag_id)
+			SELECT $1, id FROM tags WHERE id = ANY($2) AND user_id = $3
+		`, txID, tagIDs, userID)
+		if err != nil {
+			return fmt.Errorf("failed to insert transaction tags: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction tags: %w", err)
+	}
+	return nil
+}
+
+// ListTransactionTags retrieves the user's tags on a transaction
+func (r *TagRepository) ListTransactionTags(ctx context.Context, userID, txID uuid.UUID) ([]*tagging.Tag, error) {
+	query := `
+		SELECT g.id, g.user_id, g.name, g.category, g.color, g.created_at, g.updated_at
+		FROM tags g
+		JOIN transaction_tags tt ON tt.tag_id = g.id
+		WHERE tt.transaction_id = $1 AND g.user_id = $2
+		ORDER BY g.category, lower(g.name)
+	`
+
+	rows, err := r.pool.Query(ctx, query, txID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transaction tags: %w", err)
+	}
+	return collectTags(rows)
+}
+
+// GetNote retrieves the user's note on a transaction
+func (r *TagRepository) GetNote(ctx context.Context, userID, txID uuid.UUID) (*tagging.Note, error) {
+	query := `
+		SELECT transaction_id, user_id, body, updated_at
+		FROM transaction_notes
+		WHERE transaction_id = $1 AND user_id = $2
+	`
+
+	note := &tagging.Note{}
+	err := r.pool.QueryRow(ctx, query, txID, userID).Scan(&note.TransactionID, &note.UserID, &note.Body, &note.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get note: %w", err)
+	}
+	return note, nil
+}
+
+// UpsertNote creates or replaces the user's note on a transaction
+func (r *TagRepository) UpsertNote(ctx context.Context, note *tagging.Note) error {
+	query := `
+		INSERT INTO transaction_notes (transaction_id, user_id, body, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (transaction_id, user_id) DO UPDATE SET body = EXCLUDED.body, updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.pool.Exec(ctx, query, note.TransactionID, note.UserID, note.Body, note.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to upsert note: %w", err)
+	}
+	return nil
+}
+
+// DeleteNote removes the user's note on a transaction
+func (r *TagRepository) DeleteNote(ctx context.Context, userID, txID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM transaction_notes WHERE transaction_id = $1 AND user_id = $2`, txID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete note: %w", err)
+	}
+	return nil
+}
+
+// AggregateFlows sums the USD value of wallet increases (in) and decreases
+// (out) per tag and period. Periods are truncated in UTC. Transactions the
+// user can no longer view, reversed transactions and reversals are excluded.
+func (r *TagRepository) AggregateFlows(ctx context.Context, userID uuid.UUID, q tagging.FlowQuery) ([]*tagging.TagFlow, error) {
+	query := `
+		SELECT g.id, g.name, g.category,
+		       date_trunc($2::text, t.occurred_at AT TIME ZONE 'UTC') AS period_start,
+		       COALESCE(SUM(e.usd_value) FILTER (WHERE e.entry_type IN ('asset_increase', 'collateral_increase')), 0)::text,
+		       COALESCE(SUM(e.usd_value) FILTER (WHERE e.entry_type IN ('asset_decrease', 'collateral_decrease')), 0)::text,
+		       COUNT(DISTINCT t.id)
+		FROM tags g
+		JOIN transaction_tags tt ON tt.tag_id = g.id
+		JOIN transactions t ON t.id = tt.transaction_id
+		JOIN entries e ON e.transaction_id = t.id
+		JOIN accounts a ON a.id = e.account_id AND a.type IN ('CRYPTO_WALLET', 'COLLATERAL')
+		WHERE g.user_id = $1
+		  AND t.occurred_at >= $3 AND t.occurred_at < $4
+		  AND t.status = 'COMPLETED'
+		  AND t.type <> 'reversal'
+		  AND NOT EXISTS (
+		      SELECT 1 FROM transactions rv
+		      WHERE rv.source = 'reversal' AND rv.external_id = t.id::text
+		  )
+		  AND t.wallet_id IN (
+		      SELECT w.id FROM wallets w
+		      JOIN workspace_members m ON m.workspace_id = w.workspace_id
+		      WHERE m.user_id = $1
+		  )
+	`
+	args := []any{userID, string(q.Period), q.From, q.To}
+	if len(q.TagIDs) > 0 {
+		query += ` AND g.id = ANY($5)`
+		args = append(args, q.TagIDs)
+	}
+	query += `
+		GROUP BY g.id, g.name, g.category, period_start
+		ORDER BY period_start, g.category, lower(g.name)
+	`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate tag flows: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*tagging.TagFlow
+	for rows.Next() {
+		f := &tagging.TagFlow{}
+		var usdIn, usdOut string
+		if err := rows.Scan(&f.TagID, &f.TagName, &f.Category, &f.PeriodStart, &usdIn, &usdOut, &f.TransactionCount); err != nil {
+			return nil, fmt.Errorf("failed to scan tag flow: %w", err)
+		}
+		var ok bool
+		if f.USDIn, ok = new(big.Int).SetString(usdIn, 10); !ok {
+			return nil, fmt.Errorf("invalid usd_in value: %s", usdIn)
+		}
+		if f.USDOut, ok = new(big.Int).SetString(usdOut, 10); !ok {
+			return nil, fmt.Errorf("invalid usd_out value: %s", usdOut)
+		}
+		result = append(result, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tag flows: %w", err)
+	}
+	return result, nil
+}
+
+func scanTag(row pgx.Row) (*tagging.Tag, error) {
+	tag := &tagging.Tag{}
+	err := row.Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.Category, &tag.Color, &tag.CreatedAt, &tag.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func collectTags(rows pgx.Rows) ([]*tagging.Tag, error) {
+	defer rows.Close()
+
+	var result []*tagging.Tag
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		result = append(result, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+	return result, nil
+}
+
+// isTagUniqueViolation checks if the error is a unique constraint violation
+func isTagUniqueViolation(err error) bool {
+	errStr := err.Error()
+	return strings.Contains(errStr, "duplicate key") ||
+		strings.Contains(errStr, "23505")
+}
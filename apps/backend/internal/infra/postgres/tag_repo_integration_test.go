@@ -0,0 +1,101 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/module/tagging"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// adjustmentStub books raw_data["amount"] ETH into the wallet at $2,000
+type adjustmentStub struct {
+	ledger.BaseHandler
+}
+
+func (h *adjustmentStub) ValidateData(context.Context, map[string]interface{}) error { return nil }
+
+func (h *adjustmentStub) Handle(_ context.Context, data map[string]interface{}) ([]*ledger.Entry, error) {
+	amount, ok := new(big.Int).SetString(data["amount"].(string), 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount")
+	}
+	walletID := data["wallet_id"].(string)
+	rate := big.NewInt(2000_00000000)
+	usd := new(big.Int).Mul(amount, rate)
+	usd.Quo(usd, big.NewInt(1e18))
+	return []*ledger.Entry{
+		{
+			ID: uuid.New(), DebitCredit: ledger.Debit, EntryType: ledger.EntryTypeAssetIncrease,
+			Amount: amount, AssetID: "ETH", USDRate: rate, USDValue: usd,
+			Metadata: map[string]interface{}{"account_code": "wallet." + walletID + ".ethereum.ETH", "wallet_id": walletID, "chain_id": "ethereum"},
+		},
+		{
+			ID: uuid.New(), DebitCredit: ledger.Credit, EntryType: ledger.EntryTypeIncome,
+			Amount: new(big.Int).Set(amount), AssetID: "ETH", USDRate: rate, USDValue: new(big.Int).Set(usd),
+			Metadata: map[string]interface{}{"account_code": "income.adjustment.ETH"},
+		},
+	}, nil
+}
+
+func TestTagRepository_AmendedTransactionKeepsTags(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, testDB.Reset(ctx))
+	tags := NewTagRepository(testDB.Pool)
+
+	registry := ledger.NewRegistry()
+	require.NoError(t, registry.Register(&adjustmentStub{BaseHandler: ledger.NewBaseHandler(ledger.TxTypeAssetAdjustment)}))
+	svc := ledger.NewService(NewLedgerRepository(testDB.Pool), registry, logger.NewDefault("test"))
+
+	userID := createTestUser(t, ctx)
+	walletID := uuid.New()
+	_, err := testDB.Pool.Exec(ctx, `INSERT INTO wallets (id, user_id, name, address) VALUES ($1, $2, 'Main', $3)`,
+		walletID, userID, testAddress())
+	require.NoError(t, err)
+
+	occurredAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	data := map[string]interface{}{"wallet_id": walletID.String(), "amount": "1000000000000000000"}
+	original, err := svc.RecordTransaction(ctx, ledger.TxTypeAssetAdjustment, "manual", nil, occurredAt, data)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	tag := &tagging.Tag{ID: uuid.New(), UserID: userID, Name: "salary", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, tags.CreateTag(ctx, tag))
+	require.NoError(t, tags.SetTransactionTags(ctx, userID, original.ID, []uuid.UUID{tag.ID}))
+	require.NoError(t, tags.UpsertNote(ctx, &tagging.Note{TransactionID: original.ID, UserID: userID, Body: "March pay", UpdatedAt: now}))
+
+	amended := map[string]interface{}{"wallet_id": walletID.String(), "amount": "1500000000000000000"}
+	replacement, err := svc.AmendTransaction(ctx, original.ID, original.Version, occurredAt, amended)
+	require.NoError(t, err)
+
+	onReplacement, err := tags.ListTransactionTags(ctx, userID, replacement.ID)
+	require.NoError(t, err)
+	require.Len(t, onReplacement, 1)
+	assert.Equal(t, tag.ID, onReplacement[0].ID)
+	note, err := tags.GetNote(ctx, userID, replacement.ID)
+	require.NoError(t, err)
+	require.NotNil(t, note)
+	assert.Equal(t, "March pay", note.Body)
+
+	flows, err := tags.AggregateFlows(ctx, userID, tagging.FlowQuery{
+		Period: tagging.PeriodYear,
+		From:   occurredAt.Add(-24 * time.Hour),
+		To:     now.Add(24 * time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, flows, 1)
+	assert.Equal(t, tag.ID, flows[0].TagID)
+	assert.Equal(t, 1, flows[0].TransactionCount)
+	// 1.5 ETH at $2,000
+	assert.Equal(t, big.NewInt(3000_00000000), flows[0].USDIn)
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kislikjeka/moontrack/internal/module/taxprofile"
+)
+
+// TaxProfileRepository implements the tax profile repository using PostgreSQL
+type TaxProfileRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewTaxProfileRepository creates a new PostgreSQL tax profile repository
+func NewTaxProfileRepository(pool *pgxpool.Pool) *TaxProfileRepository {
+	return &TaxProfileRepository{pool: pool}
+}
+
+// GetProfile retrieves a user's tax profile
+func (r *TaxProfileRepository) GetProfile(ctx context.Context, userID uuid.UUID) (*taxprofile.Profile, error) {
+	query := `
+		SELECT user_id, jurisdiction, created_at, updated_at
+		FROM tax_profiles
+		WHERE user_id = $1
+	`
+
+	p := &taxprofile.Profile{}
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&p.UserID, &p.Jurisdiction, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get tax profile: %w", err)
+	}
+	return p, nil
+}
+
+// UpsertProfile creates or replaces a user's tax profile
+func (r *TaxProfileRepository) UpsertProfile(ctx context.Context, p *taxprofile.Profile) error {
+	query := `
+		INSERT INTO tax_profiles (user_id, jurisdiction, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET jurisdiction = EXCLUDED.jurisdiction, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.pool.Exec(ctx, query, p.UserID, string(p.Jurisdiction), p.CreatedAt, p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert tax profile: %w", err)
+	}
+	return nil
+}
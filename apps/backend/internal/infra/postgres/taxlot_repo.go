@@ -234,6 +234,25 @@ func (r *TaxLotRepository) GetLotsByTransaction(ctx context.Context, txID uuid.U
 	return r.collectTaxLots(rows)
 }
 
+// DeleteTaxLot removes a lot together with its override history.
+// Used when the transaction that created the lot is reversed.
+func (r *TaxLotRepository) DeleteTaxLot(ctx context.Context, lotID uuid.UUID) error {
+	q := r.getQueryer(ctx)
+
+	if _, err := q.Exec(ctx, `DELETE FROM lot_override_history WHERE lot_id = $1`, lotID); err != nil {
+		return fmt.Errorf("failed to delete lot override history: %w", err)
+	}
+
+	tag, err := q.Exec(ctx, `DELETE FROM tax_lots WHERE id = $1`, lotID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tax lot: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("tax lot not found: %w", pgx.ErrNoRows)
+	}
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // Disposal CRUD
 // ---------------------------------------------------------------------------
@@ -307,6 +326,20 @@ func (r *TaxLotRepository) GetDisposalsByLot(ctx context.Context, lotID uuid.UUI
 	return r.collectDisposals(rows)
 }
 
+// DeleteDisposal removes a disposal row.
+// Used when the transaction that made the disposal is reversed.
+func (r *TaxLotRepository) DeleteDisposal(ctx context.Context, id uuid.UUID) error {
+	q := r.getQueryer(ctx)
+	tag, err := q.Exec(ctx, `DELETE FROM lot_disposals WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete disposal: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("disposal not found: %w", pgx.ErrNoRows)
+	}
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // Override management
 // ---------------------------------------------------------------------------
@@ -27,8 +27,9 @@ func NewWalletRepository(pool *pgxpool.Pool) *WalletRepository {
 // Create creates a new wallet
 func (r *WalletRepository) Create(ctx context.Context, w *wallet.Wallet) error {
 	query := `
-		INSERT INTO wallets (id, user_id, name, address, sync_status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO wallets (id, user_id, name, address, sync_status, created_at, updated_at, workspace_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING workspace_id
 	`
 
 	now := time.Now()
@@ -44,7 +45,13 @@ func (r *WalletRepository) Create(ctx context.Context, w *wallet.Wallet) error {
 		w.SyncStatus = wallet.SyncStatusPending
 	}
 
-	_, err := r.pool.Exec(ctx, query,
+	// A nil workspace is left NULL so the insert trigger assigns the personal workspace
+	var workspaceID *uuid.UUID
+	if w.WorkspaceID != uuid.Nil {
+		workspaceID = &w.WorkspaceID
+	}
+
+	err := r.pool.QueryRow(ctx, query,
 		w.ID,
 		w.UserID,
 		w.Name,
@@ -52,7 +59,8 @@ func (r *WalletRepository) Create(ctx context.Context, w *wallet.Wallet) error {
 		w.SyncStatus,
 		w.CreatedAt,
 		w.UpdatedAt,
-	)
+		workspaceID,
+	).Scan(&w.WorkspaceID)
 
 	if err != nil {
 		errStr := err.Error()
@@ -74,7 +82,7 @@ func (r *WalletRepository) Create(ctx context.Context, w *wallet.Wallet) error {
 // GetByID retrieves a wallet by ID
 func (r *WalletRepository) GetByID(ctx context.Context, id uuid.UUID) (*wallet.Wallet, error) {
 	query := `
-		SELECT id, user_id, name, address, sync_status, last_sync_at, sync_error, sync_started_at, created_at, updated_at, sync_phase, collect_cursor_at
+		SELECT id, user_id, name, address, sync_status, last_sync_at, sync_error, sync_started_at, created_at, updated_at, sync_phase, collect_cursor_at, workspace_id
 		FROM wallets
 		WHERE id = $1
 	`
@@ -93,6 +101,7 @@ func (r *WalletRepository) GetByID(ctx context.Context, id uuid.UUID) (*wallet.W
 		&w.UpdatedAt,
 		&w.SyncPhase,
 		&w.CollectCursorAt,
+		&w.WorkspaceID,
 	)
 
 	if err != nil {
@@ -108,7 +117,7 @@ func (r *WalletRepository) GetByID(ctx context.Context, id uuid.UUID) (*wallet.W
 // GetByUserID retrieves all wallets for a user
 func (r *WalletRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*wallet.Wallet, error) {
 	query := `
-		SELECT id, user_id, name, address, sync_status, last_sync_at, sync_error, sync_started_at, created_at, updated_at, sync_phase, collect_cursor_at
+		SELECT id, user_id, name, address, sync_status, last_sync_at, sync_error, sync_started_at, created_at, updated_at, sync_phase, collect_cursor_at, workspace_id
 		FROM wallets
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -136,6 +145,55 @@ func (r *WalletRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([
 			&w.UpdatedAt,
 			&w.SyncPhase,
 			&w.CollectCursorAt,
+			&w.WorkspaceID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan wallet: %w", err)
+		}
+		wallets = append(wallets, w)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating wallets: %w", err)
+	}
+
+	return wallets, nil
+}
+
+// GetAccessibleByUserID retrieves all wallets in workspaces the user is a member of.
+// If workspaceID is non-nil, only wallets in that workspace are returned.
+func (r *WalletRepository) GetAccessibleByUserID(ctx context.Context, userID uuid.UUID, workspaceID *uuid.UUID) ([]*wallet.Wallet, error) {
+	query := `
+		SELECT w.id, w.user_id, w.name, w.address, w.sync_status, w.last_sync_at, w.sync_error, w.sync_started_at, w.created_at, w.updated_at, w.sync_phase, w.collect_cursor_at, w.workspace_id
+		FROM wallets w
+		JOIN workspace_members m ON m.workspace_id = w.workspace_id
+		WHERE m.user_id = $1 AND ($2::uuid IS NULL OR w.workspace_id = $2)
+		ORDER BY w.created_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query accessible wallets: %w", err)
+	}
+	defer rows.Close()
+
+	var wallets []*wallet.Wallet
+	for rows.Next() {
+		w := &wallet.Wallet{}
+		err := rows.Scan(
+			&w.ID,
+			&w.UserID,
+			&w.Name,
+			&w.Address,
+			&w.SyncStatus,
+			&w.LastSyncAt,
+			&w.SyncError,
+			&w.SyncStartedAt,
+			&w.CreatedAt,
+			&w.UpdatedAt,
+			&w.SyncPhase,
+			&w.CollectCursorAt,
+			&w.WorkspaceID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan wallet: %w", err)
@@ -223,7 +281,7 @@ func (r *WalletRepository) ExistsByUserAndAddress(ctx context.Context, userID uu
 // GetWalletsForSync retrieves wallets that need syncing (pending, error, synced, or stale syncing)
 func (r *WalletRepository) GetWalletsForSync(ctx context.Context) ([]*wallet.Wallet, error) {
 	query := `
-		SELECT id, user_id, name, address, sync_status, last_sync_at, sync_error, sync_started_at, created_at, updated_at, sync_phase, collect_cursor_at
+		SELECT id, user_id, name, address, sync_status, last_sync_at, sync_error, sync_started_at, created_at, updated_at, sync_phase, collect_cursor_at, workspace_id
 		FROM wallets
 		WHERE sync_status IN ('pending', 'error', 'synced')
 		   OR (sync_status = 'syncing' AND sync_started_at < NOW() - INTERVAL '15 minutes')
@@ -258,6 +316,7 @@ func (r *WalletRepository) GetWalletsForSync(ctx context.Context) ([]*wallet.Wal
 			&w.UpdatedAt,
 			&w.SyncPhase,
 			&w.CollectCursorAt,
+			&w.WorkspaceID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan wallet: %w", err)
@@ -275,7 +334,7 @@ func (r *WalletRepository) GetWalletsForSync(ctx context.Context) ([]*wallet.Wal
 // GetWalletsByAddressAndUserID retrieves wallets with a given address for a specific user
 func (r *WalletRepository) GetWalletsByAddressAndUserID(ctx context.Context, address string, userID uuid.UUID) ([]*wallet.Wallet, error) {
 	query := `
-		SELECT id, user_id, name, address, sync_status, last_sync_at, sync_error, sync_started_at, created_at, updated_at, sync_phase, collect_cursor_at
+		SELECT id, user_id, name, address, sync_status, last_sync_at, sync_error, sync_started_at, created_at, updated_at, sync_phase, collect_cursor_at, workspace_id
 		FROM wallets
 		WHERE lower(address) = lower($1) AND user_id = $2
 	`
@@ -302,6 +361,7 @@ func (r *WalletRepository) GetWalletsByAddressAndUserID(ctx context.Context, add
 			&w.UpdatedAt,
 			&w.SyncPhase,
 			&w.CollectCursorAt,
+			&w.WorkspaceID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan wallet: %w", err)
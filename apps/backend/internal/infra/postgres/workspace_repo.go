@@ -0,0 +1,341 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
+)
+
+// WorkspaceRepository implements the workspace repository using PostgreSQL
+type WorkspaceRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewWorkspaceRepository creates a new PostgreSQL workspace repository
+func NewWorkspaceRepository(pool *pgxpool.Pool) *WorkspaceRepository {
+	return &WorkspaceRepository{pool: pool}
+}
+
+// Create inserts a workspace and its owner membership in a single transaction
+func (r *WorkspaceRepository) Create(ctx context.Context, ws *workspace.Workspace) error {
+	now := time.Now().UTC()
+	ws.CreatedAt = now
+	ws.UpdatedAt = now
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO workspaces (id, name, owner_id, is_personal, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, ws.ID, ws.Name, ws.OwnerID, ws.IsPersonal, ws.CreatedAt, ws.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert workspace: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, ws.ID, ws.OwnerID, string(workspace.RoleOwner), now)
+	if err != nil {
+		return fmt.Errorf("failed to insert owner membership: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit workspace: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a workspace by ID
+func (r *WorkspaceRepository) GetByID(ctx context.Context, id uuid.UUID) (*workspace.Workspace, error) {
+	query := `
+		SELECT id, name, owner_id, is_personal, created_at, updated_at
+		FROM workspaces
+		WHERE id = $1
+	`
+	return r.scanWorkspace(r.pool.QueryRow(ctx, query, id))
+}
+
+// GetPersonal retrieves the user's personal workspace
+func (r *WorkspaceRepository) GetPersonal(ctx context.Context, userID uuid.UUID) (*workspace.Workspace, error) {
+	query := `
+		SELECT id, name, owner_id, is_personal, created_at, updated_at
+		FROM workspaces
+		WHERE owner_id = $1 AND is_personal
+	`
+	return r.scanWorkspace(r.pool.QueryRow(ctx, query, userID))
+}
+
+func (r *WorkspaceRepository) scanWorkspace(row pgx.Row) (*workspace.Workspace, error) {
+	ws := &workspace.Workspace{}
+	err := row.Scan(&ws.ID, &ws.Name, &ws.OwnerID, &ws.IsPersonal, &ws.CreatedAt, &ws.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	return ws, nil
+}
+
+// ListByUser retrieves all workspaces the user is a member of
+func (r *WorkspaceRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*workspace.Workspace, error) {
+	query := `
+		SELECT w.id, w.name, w.owner_id, w.is_personal, w.created_at, w.updated_at, m.role
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = $1
+		ORDER BY w.is_personal DESC, w.created_at ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspaces: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*workspace.Workspace
+	for rows.Next() {
+		ws := &workspace.Workspace{}
+		var role string
+		if err := rows.Scan(&ws.ID, &ws.Name, &ws.OwnerID, &ws.IsPersonal, &ws.CreatedAt, &ws.UpdatedAt, &role); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace: %w", err)
+		}
+		ws.Role = workspace.Role(role)
+		result = append(result, ws)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating workspaces: %w", err)
+	}
+	return result, nil
+}
+
+// GetMember retrieves a single membership
+func (r *WorkspaceRepository) GetMember(ctx context.Context, workspaceID, userID uuid.UUID) (*workspace.Member, error) {
+	query := `
+		SELECT m.workspace_id, m.user_id, u.email, m.role, m.created_at
+		FROM workspace_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1 AND m.user_id = $2
+	`
+
+	m := &workspace.Member{}
+	var role string
+	err := r.pool.QueryRow(ctx, query, workspaceID, userID).Scan(&m.WorkspaceID, &m.UserID, &m.Email, &role, &m.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get workspace member: %w", err)
+	}
+	m.Role = workspace.Role(role)
+	return m, nil
+}
+
+// ListMembers retrieves all members of a workspace
+func (r *WorkspaceRepository) ListMembers(ctx context.Context, workspaceID uuid.UUID) ([]*workspace.Member, error) {
+	query := `
+		SELECT m.workspace_id, m.user_id, u.email, m.role, m.created_at
+		FROM workspace_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1
+		ORDER BY m.created_at ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspace members: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*workspace.Member
+	for rows.Next() {
+		m := &workspace.Member{}
+		var role string
+		if err := rows.Scan(&m.WorkspaceID, &m.UserID, &m.Email, &role, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan workspace member: %w", err)
+		}
+		m.Role = workspace.Role(role)
+		result = append(result, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating workspace members: %w", err)
+	}
+	return result, nil
+}
+
+// AddMember inserts a membership
+func (r *WorkspaceRepository) AddMember(ctx context.Context, member *workspace.Member) error {
+	query := `
+		INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := r.pool.Exec(ctx, query, member.WorkspaceID, member.UserID, string(member.Role), member.CreatedAt)
+	if err != nil {
+		if isWorkspaceUniqueViolation(err) {
+			return workspace.ErrAlreadyMember
+		}
+		return fmt.Errorf("failed to insert workspace member: %w", err)
+	}
+	return nil
+}
+
+// UpdateMemberRole changes a member's role
+func (r *WorkspaceRepository) UpdateMemberRole(ctx context.Context, workspaceID, userID uuid.UUID, role workspace.Role) error {
+	query := `UPDATE workspace_members SET role = $1 WHERE workspace_id = $2 AND user_id = $3`
+	result, err := r.pool.Exec(ctx, query, string(role), workspaceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update workspace member: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return workspace.ErrMemberNotFound
+	}
+	return nil
+}
+
+// RemoveMember deletes a membership
+func (r *WorkspaceRepository) RemoveMember(ctx context.Context, workspaceID, userID uuid.UUID) error {
+	query := `DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`
+	result, err := r.pool.Exec(ctx, query, workspaceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete workspace member: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return workspace.ErrMemberNotFound
+	}
+	return nil
+}
+
+// CreateInvitation inserts an invitation
+func (r *WorkspaceRepository) CreateInvitation(ctx context.Context, inv *workspace.Invitation) error {
+	query := `
+		INSERT INTO workspace_invitations (id, workspace_id, email, role, token, invited_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		inv.ID, inv.WorkspaceID, inv.Email, string(inv.Role), inv.Token, inv.InvitedBy, inv.ExpiresAt, inv.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert workspace invitation: %w", err)
+	}
+	return nil
+}
+
+const invitationColumns = `id, workspace_id, email, role, token, invited_by, expires_at, accepted_at, accepted_by, created_at`
+
+// GetInvitationByToken retrieves an invitation by its secret token
+func (r *WorkspaceRepository) GetInvitationByToken(ctx context.Context, token string) (*workspace.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM workspace_invitations WHERE token = $1`
+
+	inv, err := scanInvitation(r.pool.QueryRow(ctx, query, token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get workspace invitation: %w", err)
+	}
+	return inv, nil
+}
+
+// ListPendingInvitations retrieves unaccepted, unexpired invitations for a workspace
+func (r *WorkspaceRepository) ListPendingInvitations(ctx context.Context, workspaceID uuid.UUID) ([]*workspace.Invitation, error) {
+	query := `SELECT ` + invitationColumns + `
+		FROM workspace_invitations
+		WHERE workspace_id = $1 AND accepted_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspace invitations: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*workspace.Invitation
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan workspace invitation: %w", err)
+		}
+		result = append(result, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating workspace invitations: %w", err)
+	}
+	return result, nil
+}
+
+// AcceptInvitation marks the invitation accepted and inserts the membership
+// in one transaction. The conditional update claims the invitation first, so
+// of two concurrent accepts only one gets a membership.
+func (r *WorkspaceRepository) AcceptInvitation(ctx context.Context, id uuid.UUID, member *workspace.Member) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	result, err := tx.Exec(ctx, `
+		UPDATE workspace_invitations
+		SET accepted_at = NOW(), accepted_by = $1
+		WHERE id = $2 AND accepted_at IS NULL AND expires_at > NOW()
+	`, member.UserID, id)
+	if err != nil {
+		return fmt.Errorf("failed to update workspace invitation: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return workspace.ErrInvitationExpired
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, member.WorkspaceID, member.UserID, string(member.Role), member.CreatedAt)
+	if err != nil {
+		if isWorkspaceUniqueViolation(err) {
+			return workspace.ErrAlreadyMember
+		}
+		return fmt.Errorf("failed to insert workspace member: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit invitation acceptance: %w", err)
+	}
+	return nil
+}
+
+func scanInvitation(row pgx.Row) (*workspace.Invitation, error) {
+	inv := &workspace.Invitation{}
+	var role string
+	err := row.Scan(
+		&inv.ID, &inv.WorkspaceID, &inv.Email, &role, &inv.Token, &inv.InvitedBy,
+		&inv.ExpiresAt, &inv.AcceptedAt, &inv.AcceptedBy, &inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	inv.Role = workspace.Role(role)
+	return inv, nil
+}
+
+// isWorkspaceUniqueViolation checks if the error is a unique constraint violation
+func isWorkspaceUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	errStr := err.Error()
+	return strings.Contains(errStr, "duplicate key") ||
+		strings.Contains(errStr, "23505")
+}
@@ -0,0 +1,88 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/kislikjeka/moontrack/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitKeyPrefix is the prefix for rate limit window keys
+const RateLimitKeyPrefix = "ratelimit:"
+
+// slidingWindowScript implements a sliding-window log on a sorted set.
+// Timestamps come from the Redis server clock so every API replica agrees.
+//
+// KEYS[1] = window key
+// ARGV[1] = limit, ARGV[2] = window (ms), ARGV[3] = unique member suffix
+// Returns {allowed (0/1), remaining, reset_ms}
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, now .. '-' .. ARGV[3])
+	redis.call('PEXPIRE', key, window)
+	count = count + 1
+	allowed = 1
+end
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, limit - count, reset}
+`)
+
+// RateLimitStore is a Redis-backed sliding-window rate limiter shared by all API replicas
+type RateLimitStore struct {
+	client *redis.Client
+	logger *logger.Logger
+}
+
+// NewRateLimitStore creates a new Redis rate limit store
+func NewRateLimitStore(client *redis.Client, log *logger.Logger) *RateLimitStore {
+	return &RateLimitStore{
+		client: client,
+		logger: log.WithField("component", "rate_limit"),
+	}
+}
+
+// Allow records a request against key and reports whether it fits in the window.
+// reset is the time until the oldest request in the window expires.
+func (s *RateLimitStore) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, int, time.Duration, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return false, 0, 0, fmt.Errorf("failed to generate request id: %w", err)
+	}
+
+	res, err := slidingWindowScript.Run(ctx, s.client,
+		[]string{RateLimitKeyPrefix + key},
+		limit, window.Milliseconds(), hex.EncodeToString(suffix),
+	).Int64Slice()
+	if err != nil {
+		return false, 0, 0, fmt.Errorf("failed to evaluate rate limit: %w", err)
+	}
+	if len(res) != 3 {
+		return false, 0, 0, fmt.Errorf("unexpected rate limit result: %v", res)
+	}
+
+	remaining := int(res[1])
+	if remaining < 0 {
+		remaining = 0
+	}
+	return res[0] == 1, remaining, time.Duration(res[2]) * time.Millisecond, nil
+}
@@ -0,0 +1,62 @@
+package ledger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TransactionSortField is a column transactions can be listed by
+type TransactionSortField string
+
+const (
+	SortByOccurredAt TransactionSortField = "occurred_at"
+	SortByRecordedAt TransactionSortField = "recorded_at"
+)
+
+// IsValid checks if the sort field is supported; empty means the default
+func (f TransactionSortField) IsValid() bool {
+	switch f {
+	case "", SortByOccurredAt, SortByRecordedAt:
+		return true
+	}
+	return false
+}
+
+// TransactionCursor marks a position in a sorted transaction listing.
+// The ID breaks ties between transactions with the same sort value, so
+// pages stay stable while new transactions are inserted.
+type TransactionCursor struct {
+	SortValue time.Time `json:"v"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// CursorAfter returns the cursor continuing after tx for the given sort field
+func CursorAfter(tx *Transaction, sortBy TransactionSortField) *TransactionCursor {
+	value := tx.OccurredAt
+	if sortBy == SortByRecordedAt {
+		value = tx.RecordedAt
+	}
+	return &TransactionCursor{SortValue: value, ID: tx.ID}
+}
+
+// Encode returns the opaque string form handed to API clients
+func (c *TransactionCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseTransactionCursor decodes a cursor produced by Encode
+func ParseTransactionCursor(s string) (*TransactionCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c TransactionCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil || c.SortValue.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
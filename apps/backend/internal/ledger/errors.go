@@ -35,6 +35,19 @@ var (
 	ErrOccurredAtAfterRecorded  = errors.New("occurred_at cannot be after recorded_at")
 )
 
+// Reversal and amendment errors
+var (
+	ErrTransactionNotReversible   = errors.New("transaction cannot be reversed")
+	ErrTransactionAlreadyReversed = errors.New("transaction has already been reversed")
+	ErrVersionConflict            = errors.New("transaction was modified concurrently")
+)
+
+// Listing errors
+var (
+	ErrInvalidCursor    = errors.New("invalid pagination cursor")
+	ErrInvalidSortField = errors.New("invalid sort field")
+)
+
 // Balance errors
 var (
 	ErrNegativeBalance = errors.New("balance cannot be negative")
@@ -43,6 +43,30 @@ const (
 	TxTypeLendingBorrow   TransactionType = "lending_borrow"   // Borrow asset from lending protocol
 	TxTypeLendingRepay    TransactionType = "lending_repay"    // Repay borrowed asset
 	TxTypeLendingClaim    TransactionType = "lending_claim"    // Claim lending rewards/interest
+
+	TxTypeLendingInterest       TransactionType = "lending_interest"        // Supply interest accrued into the deposit
+	TxTypeLendingBorrowInterest TransactionType = "lending_borrow_interest" // Borrow interest accrued onto the debt
+	TxTypeLendingLiquidation    TransactionType = "lending_liquidation"     // Collateral seized by a liquidator to repay debt
+
+	// Staking transaction types
+	TxTypeStake         TransactionType = "stake"          // Stake asset natively or via a liquid-staking protocol
+	TxTypeUnstake       TransactionType = "unstake"        // Unstake asset or redeem a liquid-staking token
+	TxTypeStakingReward TransactionType = "staking_reward" // Staking reward, claimed or accrued by rebasing
+
+	// Derivatives transaction types
+	TxTypeDerivativeOpen    TransactionType = "derivative_open"    // Post margin to open or add to a perp position
+	TxTypeDerivativeClose   TransactionType = "derivative_close"   // Close a perp position, realizing PnL and releasing margin
+	TxTypeDerivativeFunding TransactionType = "derivative_funding" // Funding payment paid or received on a perp position
+
+	// NFT transaction types
+	TxTypeNFTBuy         TransactionType = "nft_buy"          // Buy an NFT with fungible tokens
+	TxTypeNFTSell        TransactionType = "nft_sell"         // Sell an NFT for fungible tokens
+	TxTypeNFTMint        TransactionType = "nft_mint"         // Mint a new NFT, optionally paying a mint price
+	TxTypeNFTTransferIn  TransactionType = "nft_transfer_in"  // Receive an NFT without payment
+	TxTypeNFTTransferOut TransactionType = "nft_transfer_out" // Send an NFT without payment
+
+	// Correction transaction types
+	TxTypeReversal TransactionType = "reversal" // Compensating transaction that voids another
 )
 
 // AllTransactionTypes returns all valid transaction types
@@ -67,6 +91,21 @@ func AllTransactionTypes() []TransactionType {
 		TxTypeLendingBorrow,
 		TxTypeLendingRepay,
 		TxTypeLendingClaim,
+		TxTypeLendingInterest,
+		TxTypeLendingBorrowInterest,
+		TxTypeLendingLiquidation,
+		TxTypeStake,
+		TxTypeUnstake,
+		TxTypeStakingReward,
+		TxTypeDerivativeOpen,
+		TxTypeDerivativeClose,
+		TxTypeDerivativeFunding,
+		TxTypeNFTBuy,
+		TxTypeNFTSell,
+		TxTypeNFTMint,
+		TxTypeNFTTransferIn,
+		TxTypeNFTTransferOut,
+		TxTypeReversal,
 	}
 }
 
@@ -79,7 +118,14 @@ func (t TransactionType) IsValid() bool {
 		TxTypeGenesisBalance,
 		TxTypeLPDeposit, TxTypeLPWithdraw, TxTypeLPClaimFees,
 		TxTypeLendingSupply, TxTypeLendingWithdraw, TxTypeLendingBorrow,
-		TxTypeLendingRepay, TxTypeLendingClaim:
+		TxTypeLendingRepay, TxTypeLendingClaim,
+		TxTypeLendingInterest, TxTypeLendingBorrowInterest,
+		TxTypeLendingLiquidation,
+		TxTypeStake, TxTypeUnstake, TxTypeStakingReward,
+		TxTypeDerivativeOpen, TxTypeDerivativeClose, TxTypeDerivativeFunding,
+		TxTypeNFTBuy, TxTypeNFTSell, TxTypeNFTMint,
+		TxTypeNFTTransferIn, TxTypeNFTTransferOut,
+		TxTypeReversal:
 		return true
 	}
 	return false
@@ -131,11 +177,84 @@ func (t TransactionType) Label() string {
 		return "Lending Repay"
 	case TxTypeLendingClaim:
 		return "Lending Claim"
+	case TxTypeLendingInterest:
+		return "Lending Interest"
+	case TxTypeLendingBorrowInterest:
+		return "Borrow Interest"
+	case TxTypeLendingLiquidation:
+		return "Liquidation"
+	case TxTypeStake:
+		return "Stake"
+	case TxTypeUnstake:
+		return "Unstake"
+	case TxTypeStakingReward:
+		return "Staking Reward"
+	case TxTypeDerivativeOpen:
+		return "Open Position"
+	case TxTypeDerivativeClose:
+		return "Close Position"
+	case TxTypeDerivativeFunding:
+		return "Funding Payment"
+	case TxTypeNFTBuy:
+		return "NFT Buy"
+	case TxTypeNFTSell:
+		return "NFT Sell"
+	case TxTypeNFTMint:
+		return "NFT Mint"
+	case TxTypeNFTTransferIn:
+		return "NFT Transfer In"
+	case TxTypeNFTTransferOut:
+		return "NFT Transfer Out"
+	case TxTypeReversal:
+		return "Reversal"
 	default:
 		return "Unknown"
 	}
 }
 
+// Direction returns the primary flow of value for the wallet, matching the
+// direction shown in transaction lists: "in", "out", "internal", "swap" or
+// "adjustment". Reversals and funding payments, which can go either way,
+// have no direction.
+func (t TransactionType) Direction() string {
+	switch t {
+	case TxTypeTransferIn, TxTypeManualIncome, TxTypeGenesisBalance,
+		TxTypeDefiWithdraw, TxTypeDefiClaim,
+		TxTypeLPWithdraw, TxTypeLPClaimFees,
+		TxTypeLendingWithdraw, TxTypeLendingBorrow, TxTypeLendingClaim,
+		TxTypeLendingInterest,
+		TxTypeUnstake, TxTypeStakingReward,
+		TxTypeDerivativeClose,
+		TxTypeNFTMint, TxTypeNFTTransferIn:
+		return "in"
+	case TxTypeTransferOut, TxTypeManualOutcome,
+		TxTypeDefiDeposit, TxTypeLPDeposit,
+		TxTypeLendingSupply, TxTypeLendingRepay, TxTypeLendingBorrowInterest,
+		TxTypeLendingLiquidation,
+		TxTypeStake, TxTypeDerivativeOpen, TxTypeNFTTransferOut:
+		return "out"
+	case TxTypeInternalTransfer:
+		return "internal"
+	case TxTypeSwap, TxTypeNFTBuy, TxTypeNFTSell:
+		return "swap"
+	case TxTypeAssetAdjustment:
+		return "adjustment"
+	default:
+		return ""
+	}
+}
+
+// TransactionTypesWithDirection returns the transaction types whose Direction is direction
+func TransactionTypesWithDirection(direction string) []TransactionType {
+	var types []TransactionType
+	for _, t := range AllTransactionTypes() {
+		if direction != "" && t.Direction() == direction {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
 // TransactionStatus represents the status of a transaction
 type TransactionStatus string
 
@@ -227,6 +346,23 @@ func (t *Transaction) IsFailed() bool {
 	return t.Status == TransactionStatusFailed
 }
 
+// ReversalOf returns the ID of the transaction this one reverses,
+// or nil if it is not a reversal
+func (t *Transaction) ReversalOf() *uuid.UUID {
+	if t.Type != TxTypeReversal || t.Metadata == nil {
+		return nil
+	}
+	idStr, ok := t.Metadata["reversal_of"].(string)
+	if !ok {
+		return nil
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
 // TotalDebitAmount returns the sum of all debit amounts
 func (t *Transaction) TotalDebitAmount() *big.Int {
 	total := new(big.Int)
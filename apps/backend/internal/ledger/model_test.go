@@ -2,6 +2,7 @@ package ledger_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -72,8 +73,8 @@ func TestTransactionType_Label(t *testing.T) {
 func TestAllTransactionTypes(t *testing.T) {
 	allTypes := ledger.AllTransactionTypes()
 
-	// Should contain all 19 types (10 base + genesis + 3 LP + 5 lending)
-	require.Len(t, allTypes, 19)
+	// Should contain all 34 types (10 base + genesis + 3 LP + 8 lending + 3 staking + 3 derivatives + 5 NFT + reversal)
+	require.Len(t, allTypes, 34)
 
 	// Verify DeFi and LP types are included
 	typeSet := make(map[ledger.TransactionType]bool)
@@ -94,6 +95,21 @@ func TestAllTransactionTypes(t *testing.T) {
 	assert.True(t, typeSet[ledger.TxTypeLendingBorrow], "AllTransactionTypes should include lending_borrow")
 	assert.True(t, typeSet[ledger.TxTypeLendingRepay], "AllTransactionTypes should include lending_repay")
 	assert.True(t, typeSet[ledger.TxTypeLendingClaim], "AllTransactionTypes should include lending_claim")
+	assert.True(t, typeSet[ledger.TxTypeLendingInterest], "AllTransactionTypes should include lending_interest")
+	assert.True(t, typeSet[ledger.TxTypeLendingBorrowInterest], "AllTransactionTypes should include lending_borrow_interest")
+	assert.True(t, typeSet[ledger.TxTypeLendingLiquidation], "AllTransactionTypes should include lending_liquidation")
+	assert.True(t, typeSet[ledger.TxTypeStake], "AllTransactionTypes should include stake")
+	assert.True(t, typeSet[ledger.TxTypeUnstake], "AllTransactionTypes should include unstake")
+	assert.True(t, typeSet[ledger.TxTypeStakingReward], "AllTransactionTypes should include staking_reward")
+	assert.True(t, typeSet[ledger.TxTypeDerivativeOpen], "AllTransactionTypes should include derivative_open")
+	assert.True(t, typeSet[ledger.TxTypeDerivativeClose], "AllTransactionTypes should include derivative_close")
+	assert.True(t, typeSet[ledger.TxTypeDerivativeFunding], "AllTransactionTypes should include derivative_funding")
+	assert.True(t, typeSet[ledger.TxTypeNFTBuy], "AllTransactionTypes should include nft_buy")
+	assert.True(t, typeSet[ledger.TxTypeNFTSell], "AllTransactionTypes should include nft_sell")
+	assert.True(t, typeSet[ledger.TxTypeNFTMint], "AllTransactionTypes should include nft_mint")
+	assert.True(t, typeSet[ledger.TxTypeNFTTransferIn], "AllTransactionTypes should include nft_transfer_in")
+	assert.True(t, typeSet[ledger.TxTypeNFTTransferOut], "AllTransactionTypes should include nft_transfer_out")
+	assert.True(t, typeSet[ledger.TxTypeReversal], "AllTransactionTypes should include reversal")
 }
 
 // =============================================================================
@@ -172,3 +188,46 @@ func TestAccount_Validate_InvalidType(t *testing.T) {
 	err := account.Validate()
 	assert.ErrorIs(t, err, ledger.ErrInvalidAccountType)
 }
+
+func TestTransactionType_Direction(t *testing.T) {
+	assert.Equal(t, "in", ledger.TxTypeTransferIn.Direction())
+	assert.Equal(t, "out", ledger.TxTypeLPDeposit.Direction())
+	assert.Equal(t, "swap", ledger.TxTypeSwap.Direction())
+	assert.Empty(t, ledger.TxTypeReversal.Direction())
+	assert.Empty(t, ledger.TxTypeDerivativeFunding.Direction())
+
+	for _, txType := range ledger.TransactionTypesWithDirection("internal") {
+		assert.Equal(t, ledger.TxTypeInternalTransfer, txType)
+	}
+	assert.Empty(t, ledger.TransactionTypesWithDirection("sideways"))
+}
+
+func TestHeldOverYears(t *testing.T) {
+	acquired := time.Date(2023, time.March, 1, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		disposed time.Time
+		want     bool
+	}{
+		{"before anniversary", time.Date(2024, time.February, 29, 23, 0, 0, 0, time.UTC), false},
+		{"anniversary after 366 days", time.Date(2024, time.March, 1, 20, 0, 0, 0, time.UTC), false},
+		{"day after anniversary", time.Date(2024, time.March, 2, 1, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ledger.HeldOverYears(acquired, tt.disposed, 1))
+		})
+	}
+}
+
+func TestTransactionCursor_RoundTrip(t *testing.T) {
+	tx := &ledger.Transaction{ID: uuid.New(), OccurredAt: time.Date(2025, 3, 1, 12, 0, 0, 123456000, time.UTC)}
+
+	parsed, err := ledger.ParseTransactionCursor(ledger.CursorAfter(tx, ledger.SortByOccurredAt).Encode())
+	require.NoError(t, err)
+	assert.Equal(t, tx.ID, parsed.ID)
+	assert.True(t, tx.OccurredAt.Equal(parsed.SortValue))
+
+	_, err = ledger.ParseTransactionCursor("not-a-cursor")
+	assert.ErrorIs(t, err, ledger.ErrInvalidCursor)
+}
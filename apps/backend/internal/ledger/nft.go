@@ -0,0 +1,33 @@
+package ledger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NFTAssetPrefix marks ledger asset IDs that identify a single NFT
+const NFTAssetPrefix = "NFT:"
+
+// NFTAssetID returns the ledger asset ID of an NFT: NFT:{chain}:{contract}:{token_id}.
+// Each token is its own asset, so its balance is a whole number of units
+// (always 1 for ERC-721) and it carries its own tax lots.
+func NFTAssetID(chainID, contractAddress, tokenID string) string {
+	return fmt.Sprintf("%s%s:%s:%s", NFTAssetPrefix, chainID, strings.ToLower(contractAddress), tokenID)
+}
+
+// IsNFTAsset reports whether the asset ID identifies an NFT
+func IsNFTAsset(assetID string) bool {
+	return strings.HasPrefix(assetID, NFTAssetPrefix)
+}
+
+// ParseNFTAssetID splits an NFT asset ID into chain, contract address and token ID
+func ParseNFTAssetID(assetID string) (chainID, contractAddress, tokenID string, ok bool) {
+	if !IsNFTAsset(assetID) {
+		return "", "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(assetID, NFTAssetPrefix), ":", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
@@ -0,0 +1,29 @@
+package ledger_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+)
+
+func TestNFTAssetID_RoundTrip(t *testing.T) {
+	id := ledger.NFTAssetID("ethereum", "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D", "42")
+	assert.Equal(t, "NFT:ethereum:0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d:42", id)
+	assert.True(t, ledger.IsNFTAsset(id))
+
+	chain, contract, tokenID, ok := ledger.ParseNFTAssetID(id)
+	assert.True(t, ok)
+	assert.Equal(t, "ethereum", chain)
+	assert.Equal(t, "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d", contract)
+	assert.Equal(t, "42", tokenID)
+}
+
+func TestParseNFTAssetID_RejectsFungibleAndMalformed(t *testing.T) {
+	for _, id := range []string{"ETH", "NFT:", "NFT:ethereum:0xabc", "NFT::0xabc:1"} {
+		_, _, _, ok := ledger.ParseNFTAssetID(id)
+		assert.False(t, ok, id)
+	}
+	assert.False(t, ledger.IsNFTAsset("ETH"))
+}
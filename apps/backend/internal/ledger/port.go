@@ -3,6 +3,7 @@ package ledger
 import (
 	"context"
 	"math/big"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -21,10 +22,12 @@ type Repository interface {
 	GetTransaction(ctx context.Context, id uuid.UUID) (*Transaction, error)
 	FindTransactionsBySource(ctx context.Context, source string, externalID string) (*Transaction, error)
 	ListTransactions(ctx context.Context, filters TransactionFilters) ([]*Transaction, error)
+	CountTransactions(ctx context.Context, filters TransactionFilters) (int, error)
 
 	// Entry operations (read-only - entries are immutable)
 	GetEntriesByTransaction(ctx context.Context, transactionID uuid.UUID) ([]*Entry, error)
 	GetEntriesByAccount(ctx context.Context, accountID uuid.UUID) ([]*Entry, error)
+	HasEntriesAfter(ctx context.Context, accountID uuid.UUID, assetID string, after time.Time) (bool, error)
 
 	// Balance operations
 	GetAccountBalance(ctx context.Context, accountID uuid.UUID, assetID string) (*AccountBalance, error)
@@ -33,20 +36,42 @@ type Repository interface {
 	GetAccountBalances(ctx context.Context, accountID uuid.UUID) ([]*AccountBalance, error)
 	CalculateBalanceFromEntries(ctx context.Context, accountID uuid.UUID, assetID string) (*big.Int, error)
 
+	// MoveTransactionAnnotations re-points user tags, notes and income
+	// classifications from one transaction to another
+	MoveTransactionAnnotations(ctx context.Context, fromID, toID uuid.UUID) error
+
 	// Transaction management
 	BeginTx(ctx context.Context) (context.Context, error)
 	CommitTx(ctx context.Context) error
 	RollbackTx(ctx context.Context) error
 }
 
-// TransactionFilters defines filters for listing transactions
+// TransactionFilters defines filters for listing transactions.
+// Asset, chain, protocol, tx hash and counterparty match case-insensitively.
 type TransactionFilters struct {
-	UserID   *uuid.UUID
-	WalletID *uuid.UUID
-	Type     *string
-	Status   *TransactionStatus
-	FromDate *string
-	ToDate   *string
-	Limit    int
-	Offset   int
+	UserID       *uuid.UUID
+	WalletID     *uuid.UUID
+	Type         *string
+	Status       *TransactionStatus
+	TagID        *uuid.UUID // Tag owned by UserID; requires UserID
+	AssetID      *string    // Any entry in the asset
+	ChainID      *string
+	Protocol     *string
+	TxHash       *string
+	Counterparty *string  // Sender or receiver address
+	MinUSD       *big.Int // Largest entry USD value (scaled by 10^8), inclusive
+	MaxUSD       *big.Int
+	Direction    *string // See TransactionType.Direction
+	Search       *string // Substring of any top-level metadata or raw data value
+	FromDate     *string
+	ToDate       *string
+
+	SortBy  TransactionSortField // Defaults to occurred_at
+	SortAsc bool                 // Newest first by default
+
+	// Cursor continues after the last transaction of the previous page and
+	// takes precedence over Offset. It must come from the same sort.
+	Cursor *TransactionCursor
+	Limit  int
+	Offset int
 }
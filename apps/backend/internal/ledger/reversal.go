@@ -0,0 +1,294 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReversalSource is the source of compensating transactions. Reversals use the
+// original transaction ID as external ID, so UNIQUE(source, external_id)
+// guarantees a transaction can be reversed at most once.
+const ReversalSource = "reversal"
+
+// ReverseTransaction voids a completed transaction by posting a compensating
+// transaction with mirrored entries. Entries stay immutable: the original is
+// kept and the pair nets to zero on every account.
+//
+// Balances are updated through the regular commit path, so a reversal that
+// would drive an account negative (e.g. income that was already spent) is
+// rejected. Post-balance hooks see a TxTypeReversal transaction and are
+// expected to unwind their own side effects (see NewTaxLotHook).
+func (s *Service) ReverseTransaction(ctx context.Context, txID uuid.UUID, reason string) (*Transaction, error) {
+	start := time.Now()
+	txLog := s.logger.WithField("reversal_of", txID.String())
+
+	original, err := s.getReversible(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := buildReversal(original, reason, time.Now())
+
+	txLog = txLog.WithField("tx_id", tx.ID.String())
+	txLog.Info("reversing transaction",
+		"original_type", string(original.Type),
+		"entry_count", len(tx.Entries))
+
+	if err := s.validator.validate(ctx, tx); err != nil {
+		txLog.Error("reversal validation failed", "error", err)
+		return nil, err
+	}
+
+	// Restoring the original's disposals changes which lots later disposals should have consumed
+	after := s.rebuildStep(balancePairs(original.Entries), original.OccurredAt)
+	if err := s.committer.commitAll(ctx, []*Transaction{tx}, nil, after); err != nil {
+		txLog.Error("reversal commit failed", "error", err)
+		return nil, err
+	}
+
+	txLog.WithDuration(time.Since(start)).Info("transaction reversed")
+	return tx, nil
+}
+
+// AmendTransaction replaces a completed transaction with a corrected version
+// built from rawData by the original type's handler. The original is reversed
+// and the replacement recorded in one DB transaction, so either both land or
+// neither does.
+//
+// expectedVersion must match the original's Version; the replacement carries
+// the next version. Tags, notes and income classifications move to the
+// replacement. With a TaxLotRebuilder set, lots of every affected
+// account/asset are rebuilt from the earlier of the two dates.
+func (s *Service) AmendTransaction(
+	ctx context.Context,
+	txID uuid.UUID,
+	expectedVersion int,
+	occurredAt time.Time,
+	rawData map[string]interface{},
+) (*Transaction, error) {
+	start := time.Now()
+	txLog := s.logger.WithField("amends", txID.String())
+
+	original, err := s.getReversible(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	if original.Version != expectedVersion {
+		return nil, ErrVersionConflict
+	}
+
+	h, err := s.handlerRegistry.Get(original.Type)
+	if err != nil {
+		return nil, fmt.Errorf("transaction type not supported: %w", err)
+	}
+	if err := h.ValidateData(ctx, rawData); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	entries, err := h.Handle(ctx, rawData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate entries: %w", err)
+	}
+
+	now := time.Now()
+	replacement := &Transaction{
+		ID:         uuid.New(),
+		Type:       original.Type,
+		Source:     original.Source,
+		WalletID:   walletIDFromRawData(rawData),
+		Status:     TransactionStatusCompleted,
+		Version:    original.Version + 1,
+		OccurredAt: occurredAt,
+		RecordedAt: now,
+		RawData:    rawData,
+		Metadata:   map[string]interface{}{"amends": original.ID.String()},
+		Entries:    entries,
+	}
+	for _, entry := range replacement.Entries {
+		entry.TransactionID = replacement.ID
+	}
+
+	if err := s.accountResolver.resolveAccounts(ctx, replacement); err != nil {
+		return nil, fmt.Errorf("failed to resolve accounts: %w", err)
+	}
+
+	reversal := buildReversal(original, "amended", now)
+	reversal.Metadata["replaced_by"] = replacement.ID.String()
+
+	txLog = txLog.WithField("tx_id", replacement.ID.String())
+	txLog.Info("amending transaction",
+		"tx_type", string(original.Type),
+		"version", replacement.Version)
+
+	// The reversal is checked against current balances; the replacement is
+	// applied after it, so its balance effect is enforced by the committer.
+	if err := s.validator.validate(ctx, reversal); err != nil {
+		txLog.Error("reversal validation failed", "error", err)
+		return nil, err
+	}
+	if err := s.validator.validateStructure(replacement); err != nil {
+		txLog.Error("replacement validation failed", "error", err)
+		return nil, err
+	}
+
+	from := original.OccurredAt
+	if occurredAt.Before(from) {
+		from = occurredAt
+	}
+	pairs := balancePairs(append(append([]*Entry{}, original.Entries...), replacement.Entries...))
+
+	// Later disposals may have consumed the original's lots; rewind them first
+	// so the reversal can void those lots, then rebuild everything in date order.
+	before := s.rewindStep(pairs, from)
+	rebuild := s.rebuildStep(pairs, from)
+	after := func(ctx context.Context) error {
+		if err := s.repo.MoveTransactionAnnotations(ctx, original.ID, replacement.ID); err != nil {
+			return fmt.Errorf("failed to carry over annotations: %w", err)
+		}
+		if rebuild != nil {
+			return rebuild(ctx)
+		}
+		return nil
+	}
+	if err := s.committer.commitAll(ctx, []*Transaction{reversal, replacement}, before, after); err != nil {
+		txLog.Error("amendment commit failed", "error", err)
+		return nil, err
+	}
+
+	txLog.WithDuration(time.Since(start)).Info("transaction amended")
+	return replacement, nil
+}
+
+// getReversible loads a transaction and checks that it can be reversed
+func (s *Service) getReversible(ctx context.Context, txID uuid.UUID) (*Transaction, error) {
+	original, err := s.repo.GetTransaction(ctx, txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if original == nil {
+		return nil, fmt.Errorf("failed to get transaction: %s not found", txID)
+	}
+	if !original.IsCompleted() || original.Type == TxTypeReversal || len(original.Entries) == 0 {
+		return nil, ErrTransactionNotReversible
+	}
+
+	// Lookup errors are treated as "not reversed"; the unique constraint on
+	// (source, external_id) still rejects a concurrent duplicate.
+	if existing, err := s.repo.FindTransactionsBySource(ctx, ReversalSource, txID.String()); err == nil && existing != nil {
+		return nil, ErrTransactionAlreadyReversed
+	}
+
+	return original, nil
+}
+
+func (s *Service) rewindStep(pairs []AccountAsset, from time.Time) dbStep {
+	if s.rebuilder == nil {
+		return nil
+	}
+	return func(ctx context.Context) error {
+		return s.rebuilder.Rewind(ctx, pairs, from)
+	}
+}
+
+func (s *Service) rebuildStep(pairs []AccountAsset, from time.Time) dbStep {
+	if s.rebuilder == nil {
+		return nil
+	}
+	return func(ctx context.Context) error {
+		return s.rebuilder.Rebuild(ctx, pairs, from)
+	}
+}
+
+// buildReversal creates the compensating transaction for original.
+// Each entry keeps its account, asset, amount and USD valuation; only the
+// debit/credit side and the balance direction are flipped.
+func buildReversal(original *Transaction, reason string, now time.Time) *Transaction {
+	externalID := original.ID.String()
+	tx := &Transaction{
+		ID:         uuid.New(),
+		Type:       TxTypeReversal,
+		Source:     ReversalSource,
+		ExternalID: &externalID,
+		WalletID:   original.WalletID,
+		Status:     TransactionStatusCompleted,
+		Version:    1,
+		OccurredAt: now,
+		RecordedAt: now,
+		RawData: map[string]interface{}{
+			"reversal_of":   original.ID.String(),
+			"original_type": string(original.Type),
+			"reason":        reason,
+		},
+		Metadata: map[string]interface{}{
+			"reversal_of": original.ID.String(),
+			"reason":      reason,
+		},
+	}
+	if original.WalletID != nil {
+		tx.RawData["wallet_id"] = original.WalletID.String()
+	}
+
+	tx.Entries = make([]*Entry, 0, len(original.Entries))
+	for _, e := range original.Entries {
+		metadata := make(map[string]interface{}, len(e.Metadata)+1)
+		for k, v := range e.Metadata {
+			metadata[k] = v
+		}
+		metadata["reversed_entry_id"] = e.ID.String()
+
+		tx.Entries = append(tx.Entries, &Entry{
+			ID:            uuid.New(),
+			TransactionID: tx.ID,
+			AccountID:     e.AccountID,
+			DebitCredit:   oppositeSide(e.DebitCredit),
+			EntryType:     mirrorEntryType(e.EntryType),
+			Amount:        new(big.Int).Set(e.Amount),
+			AssetID:       e.AssetID,
+			USDRate:       copyBigInt(e.USDRate),
+			USDValue:      copyBigInt(e.USDValue),
+			OccurredAt:    now,
+			CreatedAt:     now,
+			Metadata:      metadata,
+		})
+	}
+
+	return tx
+}
+
+func oppositeSide(dc DebitCredit) DebitCredit {
+	if dc == Debit {
+		return Credit
+	}
+	return Debit
+}
+
+// mirrorEntryType flips the balance direction of balance-affecting entry types.
+// Nominal types (income, expense, gas_fee, clearing) carry no balance and are kept.
+func mirrorEntryType(et EntryType) EntryType {
+	switch et {
+	case EntryTypeAssetIncrease:
+		return EntryTypeAssetDecrease
+	case EntryTypeAssetDecrease:
+		return EntryTypeAssetIncrease
+	case EntryTypeCollateralIncrease:
+		return EntryTypeCollateralDecrease
+	case EntryTypeCollateralDecrease:
+		return EntryTypeCollateralIncrease
+	case EntryTypeLiabilityIncrease:
+		return EntryTypeLiabilityDecrease
+	case EntryTypeLiabilityDecrease:
+		return EntryTypeLiabilityIncrease
+	default:
+		return et
+	}
+}
+
+func copyBigInt(v *big.Int) *big.Int {
+	if v == nil {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(v)
+}
@@ -0,0 +1,453 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// reversalLedgerRepo extends mockLedgerRepo with stored transactions and
+// balances so ReverseTransaction can run end-to-end.
+type reversalLedgerRepo struct {
+	*mockLedgerRepo
+	txs         map[uuid.UUID]*Transaction
+	balances    map[uuid.UUID]*big.Int
+	annotations map[uuid.UUID][]string
+}
+
+func newReversalLedgerRepo(accounts ...*Account) *reversalLedgerRepo {
+	m := &mockLedgerRepo{accounts: make(map[uuid.UUID]*Account)}
+	for _, a := range accounts {
+		m.accounts[a.ID] = a
+	}
+	return &reversalLedgerRepo{
+		mockLedgerRepo: m,
+		txs:            make(map[uuid.UUID]*Transaction),
+		balances:       make(map[uuid.UUID]*big.Int),
+		annotations:    make(map[uuid.UUID][]string),
+	}
+}
+
+func (r *reversalLedgerRepo) CreateTransaction(_ context.Context, tx *Transaction) error {
+	r.txs[tx.ID] = tx
+	return nil
+}
+
+func (r *reversalLedgerRepo) GetTransaction(_ context.Context, id uuid.UUID) (*Transaction, error) {
+	if tx, ok := r.txs[id]; ok {
+		return tx, nil
+	}
+	return nil, errors.New("transaction not found")
+}
+
+func (r *reversalLedgerRepo) FindTransactionsBySource(_ context.Context, source, externalID string) (*Transaction, error) {
+	for _, tx := range r.txs {
+		if tx.Source == source && tx.ExternalID != nil && *tx.ExternalID == externalID {
+			return tx, nil
+		}
+	}
+	return nil, errors.New("transaction not found")
+}
+
+func (r *reversalLedgerRepo) GetOrCreateAccount(_ context.Context, a *Account) (*Account, error) {
+	for _, existing := range r.accounts {
+		if existing.Code == a.Code {
+			return existing, nil
+		}
+	}
+	r.accounts[a.ID] = a
+	return a, nil
+}
+
+func (r *reversalLedgerRepo) GetEntriesByAccount(_ context.Context, accountID uuid.UUID) ([]*Entry, error) {
+	var result []*Entry
+	for _, tx := range r.txs {
+		for _, e := range tx.Entries {
+			if e.AccountID == accountID {
+				result = append(result, e)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].OccurredAt.Before(result[j].OccurredAt) })
+	return result, nil
+}
+
+func (r *reversalLedgerRepo) HasEntriesAfter(_ context.Context, accountID uuid.UUID, asset string, after time.Time) (bool, error) {
+	for _, tx := range r.txs {
+		for _, e := range tx.Entries {
+			if e.AccountID == accountID && e.AssetID == asset && e.OccurredAt.After(after) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (r *reversalLedgerRepo) MoveTransactionAnnotations(_ context.Context, fromID, toID uuid.UUID) error {
+	r.annotations[toID] = append(r.annotations[toID], r.annotations[fromID]...)
+	delete(r.annotations, fromID)
+	return nil
+}
+
+func (r *reversalLedgerRepo) balance(id uuid.UUID) *big.Int {
+	if b, ok := r.balances[id]; ok {
+		return b
+	}
+	return big.NewInt(0)
+}
+
+func (r *reversalLedgerRepo) GetAccountBalance(_ context.Context, id uuid.UUID, asset string) (*AccountBalance, error) {
+	return &AccountBalance{AccountID: id, AssetID: asset, Balance: r.balance(id), USDValue: big.NewInt(0)}, nil
+}
+
+func (r *reversalLedgerRepo) GetAccountBalanceForUpdate(ctx context.Context, id uuid.UUID, asset string) (*AccountBalance, error) {
+	return r.GetAccountBalance(ctx, id, asset)
+}
+
+func (r *reversalLedgerRepo) UpsertAccountBalance(_ context.Context, b *AccountBalance) error {
+	r.balances[b.AccountID] = new(big.Int).Set(b.Balance)
+	return nil
+}
+
+// recordDirect stores tx and applies its balance changes and hook effects
+// without going through a handler.
+func recordDirect(t *testing.T, svc *Service, tx *Transaction) {
+	t.Helper()
+	if err := svc.committer.commit(context.Background(), tx); err != nil {
+		t.Fatalf("failed to record %s: %v", tx.Type, err)
+	}
+}
+
+func completedTx(txType TransactionType, entries ...*Entry) *Transaction {
+	return completedTxAt(time.Now().Add(-time.Hour), txType, entries...)
+}
+
+func completedTxAt(occurredAt time.Time, txType TransactionType, entries ...*Entry) *Transaction {
+	tx := &Transaction{
+		ID:         uuid.New(),
+		Type:       txType,
+		Source:     "manual",
+		Status:     TransactionStatusCompleted,
+		Version:    1,
+		OccurredAt: occurredAt,
+		RecordedAt: time.Now(),
+		Metadata:   map[string]interface{}{},
+		Entries:    entries,
+	}
+	for _, e := range entries {
+		e.TransactionID = tx.ID
+		e.OccurredAt = occurredAt
+	}
+	return tx
+}
+
+func newReversalService(repo *reversalLedgerRepo, lots *mockTaxLotRepo) *Service {
+	svc := NewService(repo, NewRegistry(), newTestLogger())
+	svc.RegisterPostBalanceHook(NewTaxLotHook(lots, repo, newTestLogger()))
+	return svc
+}
+
+func TestReverseTransaction_Income_RestoresBalanceAndVoidsLot(t *testing.T) {
+	walletAcctID, incomeAcctID := uuid.New(), uuid.New()
+	repo := newReversalLedgerRepo(walletAccount(walletAcctID), incomeAccount(incomeAcctID))
+	lots := &mockTaxLotRepo{}
+	svc := newReversalService(repo, lots)
+
+	original := completedTx(TxTypeManualIncome,
+		makeEntry(walletAcctID, Debit, EntryTypeAssetIncrease, 1000, "ETH", nil),
+		makeEntry(incomeAcctID, Credit, EntryTypeIncome, 1000, "ETH", nil),
+	)
+	recordDirect(t, svc, original)
+	if len(lots.lots) != 1 {
+		t.Fatalf("expected 1 lot after income, got %d", len(lots.lots))
+	}
+
+	reversal, err := svc.ReverseTransaction(context.Background(), original.ID, "typo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if reversal.Type != TxTypeReversal || reversal.Source != ReversalSource {
+		t.Errorf("expected reversal/%s, got %s/%s", ReversalSource, reversal.Type, reversal.Source)
+	}
+	if got := reversal.ReversalOf(); got == nil || *got != original.ID {
+		t.Errorf("expected reversal to link to %s, got %v", original.ID, got)
+	}
+	if reversal.Entries[0].DebitCredit != Credit || reversal.Entries[0].EntryType != EntryTypeAssetDecrease {
+		t.Errorf("expected wallet entry mirrored to CREDIT asset_decrease, got %s %s",
+			reversal.Entries[0].DebitCredit, reversal.Entries[0].EntryType)
+	}
+	if reversal.Entries[1].DebitCredit != Debit || reversal.Entries[1].EntryType != EntryTypeIncome {
+		t.Errorf("expected income entry mirrored to DEBIT income, got %s %s",
+			reversal.Entries[1].DebitCredit, reversal.Entries[1].EntryType)
+	}
+	if repo.balance(walletAcctID).Sign() != 0 {
+		t.Errorf("expected wallet balance 0, got %s", repo.balance(walletAcctID))
+	}
+	if len(lots.lots) != 0 {
+		t.Errorf("expected lot to be voided, got %d lots", len(lots.lots))
+	}
+}
+
+func TestReverseTransaction_Outcome_RestoresConsumedLots(t *testing.T) {
+	walletAcctID, incomeAcctID, expenseAcctID := uuid.New(), uuid.New(), uuid.New()
+	repo := newReversalLedgerRepo(walletAccount(walletAcctID), incomeAccount(incomeAcctID), expenseAccount(expenseAcctID))
+	lots := &mockTaxLotRepo{}
+	svc := newReversalService(repo, lots)
+
+	recordDirect(t, svc, completedTx(TxTypeManualIncome,
+		makeEntry(walletAcctID, Debit, EntryTypeAssetIncrease, 1000, "ETH", nil),
+		makeEntry(incomeAcctID, Credit, EntryTypeIncome, 1000, "ETH", nil),
+	))
+	outcome := completedTx(TxTypeManualOutcome,
+		makeEntry(expenseAcctID, Debit, EntryTypeExpense, 400, "ETH", nil),
+		makeEntry(walletAcctID, Credit, EntryTypeAssetDecrease, 400, "ETH", nil),
+	)
+	recordDirect(t, svc, outcome)
+	if lots.lots[0].QuantityRemaining.Cmp(big.NewInt(600)) != 0 {
+		t.Fatalf("expected remaining 600 after outcome, got %s", lots.lots[0].QuantityRemaining)
+	}
+
+	if _, err := svc.ReverseTransaction(context.Background(), outcome.ID, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if lots.lots[0].QuantityRemaining.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("expected remaining restored to 1000, got %s", lots.lots[0].QuantityRemaining)
+	}
+	if len(lots.disposals) != 0 {
+		t.Errorf("expected disposals to be removed, got %d", len(lots.disposals))
+	}
+	if repo.balance(walletAcctID).Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("expected wallet balance 1000, got %s", repo.balance(walletAcctID))
+	}
+}
+
+func TestReverseTransaction_RefusesWhenLotConsumedDownstream(t *testing.T) {
+	walletAcctID, incomeAcctID, expenseAcctID := uuid.New(), uuid.New(), uuid.New()
+	repo := newReversalLedgerRepo(walletAccount(walletAcctID), incomeAccount(incomeAcctID), expenseAccount(expenseAcctID))
+	lots := &mockTaxLotRepo{}
+	svc := newReversalService(repo, lots)
+
+	income := completedTx(TxTypeManualIncome,
+		makeEntry(walletAcctID, Debit, EntryTypeAssetIncrease, 1000, "ETH", nil),
+		makeEntry(incomeAcctID, Credit, EntryTypeIncome, 1000, "ETH", nil),
+	)
+	recordDirect(t, svc, income)
+	recordDirect(t, svc, completedTx(TxTypeManualIncome,
+		makeEntry(walletAcctID, Debit, EntryTypeAssetIncrease, 1000, "ETH", nil),
+		makeEntry(incomeAcctID, Credit, EntryTypeIncome, 1000, "ETH", nil),
+	))
+	// Balance still covers the reversal, but FIFO consumed the first lot
+	recordDirect(t, svc, completedTx(TxTypeManualOutcome,
+		makeEntry(expenseAcctID, Debit, EntryTypeExpense, 100, "ETH", nil),
+		makeEntry(walletAcctID, Credit, EntryTypeAssetDecrease, 100, "ETH", nil),
+	))
+
+	_, err := svc.ReverseTransaction(context.Background(), income.ID, "")
+	if !errors.Is(err, ErrLotConsumed) {
+		t.Fatalf("expected ErrLotConsumed, got %v", err)
+	}
+}
+
+func TestReverseTransaction_RefusesNegativeBalance(t *testing.T) {
+	walletAcctID, incomeAcctID := uuid.New(), uuid.New()
+	repo := newReversalLedgerRepo(walletAccount(walletAcctID), incomeAccount(incomeAcctID))
+	svc := NewService(repo, NewRegistry(), newTestLogger())
+
+	income := completedTx(TxTypeManualIncome,
+		makeEntry(walletAcctID, Debit, EntryTypeAssetIncrease, 1000, "ETH", nil),
+		makeEntry(incomeAcctID, Credit, EntryTypeIncome, 1000, "ETH", nil),
+	)
+	recordDirect(t, svc, income)
+	repo.balances[walletAcctID] = big.NewInt(300) // spent elsewhere
+
+	_, err := svc.ReverseTransaction(context.Background(), income.ID, "")
+	var negErr *NegativeBalanceError
+	if !errors.As(err, &negErr) {
+		t.Fatalf("expected NegativeBalanceError, got %v", err)
+	}
+}
+
+func TestReverseTransaction_RefusesTwice(t *testing.T) {
+	walletAcctID, incomeAcctID := uuid.New(), uuid.New()
+	repo := newReversalLedgerRepo(walletAccount(walletAcctID), incomeAccount(incomeAcctID))
+	svc := NewService(repo, NewRegistry(), newTestLogger())
+
+	income := completedTx(TxTypeManualIncome,
+		makeEntry(walletAcctID, Debit, EntryTypeAssetIncrease, 1000, "ETH", nil),
+		makeEntry(incomeAcctID, Credit, EntryTypeIncome, 1000, "ETH", nil),
+	)
+	recordDirect(t, svc, income)
+
+	reversal, err := svc.ReverseTransaction(context.Background(), income.ID, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := svc.ReverseTransaction(context.Background(), income.ID, ""); !errors.Is(err, ErrTransactionAlreadyReversed) {
+		t.Errorf("expected ErrTransactionAlreadyReversed, got %v", err)
+	}
+	if _, err := svc.ReverseTransaction(context.Background(), reversal.ID, ""); !errors.Is(err, ErrTransactionNotReversible) {
+		t.Errorf("expected ErrTransactionNotReversible for a reversal, got %v", err)
+	}
+}
+
+func TestMirrorEntryType(t *testing.T) {
+	tests := map[EntryType]EntryType{
+		EntryTypeAssetIncrease:      EntryTypeAssetDecrease,
+		EntryTypeAssetDecrease:      EntryTypeAssetIncrease,
+		EntryTypeCollateralIncrease: EntryTypeCollateralDecrease,
+		EntryTypeCollateralDecrease: EntryTypeCollateralIncrease,
+		EntryTypeLiabilityIncrease:  EntryTypeLiabilityDecrease,
+		EntryTypeLiabilityDecrease:  EntryTypeLiabilityIncrease,
+		EntryTypeIncome:             EntryTypeIncome,
+		EntryTypeGasFee:             EntryTypeGasFee,
+	}
+	for in, want := range tests {
+		if got := mirrorEntryType(in); got != want {
+			t.Errorf("mirrorEntryType(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+// stubIncomeHandler books rawData["amount"] of ETH as income into the test wallet.
+type stubIncomeHandler struct {
+	BaseHandler
+}
+
+func (h *stubIncomeHandler) ValidateData(context.Context, map[string]interface{}) error { return nil }
+
+func (h *stubIncomeHandler) Handle(_ context.Context, data map[string]interface{}) ([]*Entry, error) {
+	amount := data["amount"].(int64)
+	occurredAt := data["occurred_at"].(time.Time)
+	wallet := makeEntry(uuid.Nil, Debit, EntryTypeAssetIncrease, amount, "ETH",
+		map[string]interface{}{"account_code": "wallet.test.eth.ETH"})
+	income := makeEntry(uuid.Nil, Credit, EntryTypeIncome, amount, "ETH",
+		map[string]interface{}{"account_code": "income.eth.ETH"})
+	wallet.OccurredAt, income.OccurredAt = occurredAt, occurredAt
+	return []*Entry{wallet, income}, nil
+}
+
+func newAmendService(t *testing.T, repo *reversalLedgerRepo, lots *mockTaxLotRepo) *Service {
+	t.Helper()
+	registry := NewRegistry()
+	if err := registry.Register(&stubIncomeHandler{BaseHandler: NewBaseHandler(TxTypeManualIncome)}); err != nil {
+		t.Fatalf("failed to register handler: %v", err)
+	}
+	svc := NewService(repo, registry, newTestLogger())
+	svc.RegisterPostBalanceHook(NewTaxLotHook(lots, repo, newTestLogger()))
+	svc.SetTaxLotRebuilder(NewTaxLotRebuilder(lots, repo, newTestLogger()))
+	return svc
+}
+
+func TestAmendTransaction_BackdatedIncome_ReplaysFIFO(t *testing.T) {
+	walletAcctID, incomeAcctID, expenseAcctID := uuid.New(), uuid.New(), uuid.New()
+	repo := newReversalLedgerRepo(walletAccount(walletAcctID), incomeAccount(incomeAcctID), expenseAccount(expenseAcctID))
+	lots := &mockTaxLotRepo{}
+	svc := newAmendService(t, repo, lots)
+
+	now := time.Now()
+	incomeA := completedTxAt(now.Add(-3*time.Hour), TxTypeManualIncome,
+		makeEntry(walletAcctID, Debit, EntryTypeAssetIncrease, 1000, "ETH", nil),
+		makeEntry(incomeAcctID, Credit, EntryTypeIncome, 1000, "ETH", nil),
+	)
+	recordDirect(t, svc, incomeA)
+	incomeB := completedTxAt(now.Add(-2*time.Hour), TxTypeManualIncome,
+		makeEntry(walletAcctID, Debit, EntryTypeAssetIncrease, 1000, "ETH", nil),
+		makeEntry(incomeAcctID, Credit, EntryTypeIncome, 1000, "ETH", nil),
+	)
+	recordDirect(t, svc, incomeB)
+	recordDirect(t, svc, completedTxAt(now.Add(-time.Hour), TxTypeManualOutcome,
+		makeEntry(expenseAcctID, Debit, EntryTypeExpense, 500, "ETH", nil),
+		makeEntry(walletAcctID, Credit, EntryTypeAssetDecrease, 500, "ETH", nil),
+	))
+	if remaining := lots.lots[0].QuantityRemaining; remaining.Cmp(big.NewInt(500)) != 0 {
+		t.Fatalf("expected lot A remaining 500 before amend, got %s", remaining)
+	}
+
+	// Move income B before income A: the outcome should now consume B's lot
+	amendedAt := now.Add(-4 * time.Hour)
+	replacement, err := svc.AmendTransaction(context.Background(), incomeB.ID, 1, amendedAt,
+		map[string]interface{}{"amount": int64(1000), "occurred_at": amendedAt})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if replacement.Version != 2 {
+		t.Errorf("expected replacement version 2, got %d", replacement.Version)
+	}
+	if replacement.Metadata["amends"] != incomeB.ID.String() {
+		t.Errorf("expected replacement to reference %s, got %v", incomeB.ID, replacement.Metadata["amends"])
+	}
+	lotsA, _ := lots.GetLotsByTransaction(context.Background(), incomeA.ID)
+	if len(lotsA) != 1 || lotsA[0].QuantityRemaining.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("expected lot A fully restored, got %v", lotsA)
+	}
+
+	newLots, _ := lots.GetLotsByTransaction(context.Background(), replacement.ID)
+	if len(newLots) != 1 {
+		t.Fatalf("expected 1 lot for replacement, got %d", len(newLots))
+	}
+	if newLots[0].QuantityRemaining.Cmp(big.NewInt(500)) != 0 {
+		t.Errorf("expected replacement lot remaining 500, got %s", newLots[0].QuantityRemaining)
+	}
+	if oldLots, _ := lots.GetLotsByTransaction(context.Background(), incomeB.ID); len(oldLots) != 0 {
+		t.Errorf("expected original lot voided, got %d", len(oldLots))
+	}
+	if repo.balance(walletAcctID).Cmp(big.NewInt(1500)) != 0 {
+		t.Errorf("expected wallet balance 1500, got %s", repo.balance(walletAcctID))
+	}
+}
+
+func TestAmendTransaction_VersionConflict(t *testing.T) {
+	walletAcctID, incomeAcctID := uuid.New(), uuid.New()
+	repo := newReversalLedgerRepo(walletAccount(walletAcctID), incomeAccount(incomeAcctID))
+	svc := newAmendService(t, repo, &mockTaxLotRepo{})
+
+	income := completedTx(TxTypeManualIncome,
+		makeEntry(walletAcctID, Debit, EntryTypeAssetIncrease, 1000, "ETH", nil),
+		makeEntry(incomeAcctID, Credit, EntryTypeIncome, 1000, "ETH", nil),
+	)
+	recordDirect(t, svc, income)
+
+	_, err := svc.AmendTransaction(context.Background(), income.ID, 2, income.OccurredAt,
+		map[string]interface{}{"amount": int64(900), "occurred_at": income.OccurredAt})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+	if repo.balance(walletAcctID).Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("expected balance untouched, got %s", repo.balance(walletAcctID))
+	}
+}
+
+func TestAmendTransaction_CarriesOverAnnotations(t *testing.T) {
+	walletAcctID, incomeAcctID := uuid.New(), uuid.New()
+	repo := newReversalLedgerRepo(walletAccount(walletAcctID), incomeAccount(incomeAcctID))
+	svc := newAmendService(t, repo, &mockTaxLotRepo{})
+
+	income := completedTx(TxTypeManualIncome,
+		makeEntry(walletAcctID, Debit, EntryTypeAssetIncrease, 1000, "ETH", nil),
+		makeEntry(incomeAcctID, Credit, EntryTypeIncome, 1000, "ETH", nil),
+	)
+	recordDirect(t, svc, income)
+	repo.annotations[income.ID] = []string{"tag:salary", "note", "income:salary"}
+
+	replacement, err := svc.AmendTransaction(context.Background(), income.ID, 1, income.OccurredAt,
+		map[string]interface{}{"amount": int64(900), "occurred_at": income.OccurredAt})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := repo.annotations[replacement.ID]; len(got) != 3 {
+		t.Errorf("expected annotations on the replacement, got %v", got)
+	}
+	if got := repo.annotations[income.ID]; len(got) != 0 {
+		t.Errorf("expected no annotations left on the original, got %v", got)
+	}
+}
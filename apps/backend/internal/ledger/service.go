@@ -23,6 +23,7 @@ type Service struct {
 	accountResolver *accountResolver
 	validator       *transactionValidator
 	committer       *transactionCommitter
+	rebuilder       *TaxLotRebuilder // optional
 	logger          *logger.Logger
 }
 
@@ -112,16 +113,7 @@ func (s *Service) RecordTransaction(
 	}
 
 	// Extract wallet_id from rawData for the denormalized column
-	if walletIDStr, ok := rawData["wallet_id"].(string); ok {
-		if wid, err := uuid.Parse(walletIDStr); err == nil {
-			tx.WalletID = &wid
-		}
-	} else if walletIDStr, ok := rawData["source_wallet_id"].(string); ok {
-		// internal_transfer uses source_wallet_id
-		if wid, err := uuid.Parse(walletIDStr); err == nil {
-			tx.WalletID = &wid
-		}
-	}
+	tx.WalletID = walletIDFromRawData(rawData)
 
 	// Set transaction ID on all entries
 	for _, entry := range tx.Entries {
@@ -151,8 +143,8 @@ func (s *Service) RecordTransaction(
 		return tx, err
 	}
 
-	// Step 7: Commit the transaction
-	if err := s.committer.commit(ctx, tx); err != nil {
+	// Step 7: Commit the transaction; a backdated one also rebuilds the lots it reorders
+	if err := s.committer.commitAll(ctx, []*Transaction{tx}, nil, s.backdateStep(tx)); err != nil {
 		txLog.Error("transaction commit failed", "error", err)
 		errorMsg := fmt.Sprintf("failed to commit: %v", err)
 		tx.Status = TransactionStatusFailed
@@ -176,6 +168,11 @@ func (s *Service) ListTransactions(ctx context.Context, filters TransactionFilte
 	return s.repo.ListTransactions(ctx, filters)
 }
 
+// CountTransactions counts transactions matching filters, ignoring pagination
+func (s *Service) CountTransactions(ctx context.Context, filters TransactionFilters) (int, error) {
+	return s.repo.CountTransactions(ctx, filters)
+}
+
 // GetAccountBalance retrieves the current balance for an account/asset
 func (s *Service) GetAccountBalance(ctx context.Context, accountID uuid.UUID, assetID string) (*AccountBalance, error) {
 	return s.repo.GetAccountBalance(ctx, accountID, assetID)
@@ -242,6 +239,22 @@ func (s *Service) RegisterPostBalanceHook(hook PostBalanceHook) {
 	s.committer.postBalanceHooks = append(s.committer.postBalanceHooks, hook)
 }
 
+// SetTaxLotRebuilder enables tax lot rebuilds when backdated transactions,
+// reversals and amendments change the order of history. Without it, lots are
+// only processed at record time and unwound on reversal.
+func (s *Service) SetTaxLotRebuilder(r *TaxLotRebuilder) {
+	s.rebuilder = r
+}
+
+func (s *Service) backdateStep(tx *Transaction) dbStep {
+	if s.rebuilder == nil {
+		return nil
+	}
+	return func(ctx context.Context) error {
+		return s.rebuilder.RebuildIfBackdated(ctx, tx)
+	}
+}
+
 // createFailedTransaction creates a failed transaction record
 func (s *Service) createFailedTransaction(
 	transactionType TransactionType,
@@ -267,6 +280,23 @@ func (s *Service) createFailedTransaction(
 	}
 }
 
+// walletIDFromRawData returns the primary wallet of a transaction's raw data
+func walletIDFromRawData(rawData map[string]interface{}) *uuid.UUID {
+	walletIDStr, ok := rawData["wallet_id"].(string)
+	if !ok {
+		// internal_transfer uses source_wallet_id
+		walletIDStr, ok = rawData["source_wallet_id"].(string)
+	}
+	if !ok {
+		return nil
+	}
+	wid, err := uuid.Parse(walletIDStr)
+	if err != nil {
+		return nil
+	}
+	return &wid
+}
+
 // accountResolver resolves account references in ledger entries
 type accountResolver struct {
 	repo   Repository
@@ -401,6 +431,21 @@ func newTransactionValidator(repo Repository, log *logger.Logger) *transactionVa
 }
 
 func (v *transactionValidator) validate(ctx context.Context, tx *Transaction) error {
+	if err := v.validateStructure(tx); err != nil {
+		return err
+	}
+
+	if err := v.validateAccountBalances(ctx, tx); err != nil {
+		return fmt.Errorf("account balance validation failed: %w", err)
+	}
+
+	return nil
+}
+
+// validateStructure checks the transaction and its entries without looking at
+// current balances. Used on its own when the transaction is committed after
+// others in the same DB transaction, where the committer enforces balances.
+func (v *transactionValidator) validateStructure(tx *Transaction) error {
 	if err := tx.Validate(); err != nil {
 		return fmt.Errorf("transaction validation failed: %w", err)
 	}
@@ -415,10 +460,6 @@ func (v *transactionValidator) validate(ctx context.Context, tx *Transaction) er
 		return fmt.Errorf("balance validation failed: %w", err)
 	}
 
-	if err := v.validateAccountBalances(ctx, tx); err != nil {
-		return fmt.Errorf("account balance validation failed: %w", err)
-	}
-
 	return nil
 }
 
@@ -514,7 +555,17 @@ func newTransactionCommitter(repo Repository, log *logger.Logger) *transactionCo
 	return &transactionCommitter{repo: repo, logger: log}
 }
 
+// dbStep is extra work run inside the DB transaction alongside the writes
+type dbStep func(ctx context.Context) error
+
 func (c *transactionCommitter) commit(ctx context.Context, tx *Transaction) error {
+	return c.commitAll(ctx, []*Transaction{tx}, nil, nil)
+}
+
+// commitAll persists the transactions in order within a single DB transaction.
+// before and after, when set, run inside the same DB transaction around the writes.
+func (c *transactionCommitter) commitAll(ctx context.Context, txs []*Transaction, before, after dbStep) error {
+	tx := txs[0]
 	c.logger.Debug("beginning db transaction", "tx_id", tx.ID.String())
 
 	// Begin database transaction for atomicity
@@ -533,6 +584,37 @@ func (c *transactionCommitter) commit(ctx context.Context, tx *Transaction) erro
 		}
 	}()
 
+	if before != nil {
+		if err := before(txCtx); err != nil {
+			return err
+		}
+	}
+
+	for _, tx := range txs {
+		if err := c.persist(txCtx, tx); err != nil {
+			return err
+		}
+	}
+
+	if after != nil {
+		if err := after(txCtx); err != nil {
+			return err
+		}
+	}
+
+	// Commit the DB transaction
+	if err := c.repo.CommitTx(txCtx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	committed = true
+	c.logger.Debug("db transaction committed", "tx_id", tx.ID.String())
+	return nil
+}
+
+// persist writes a transaction, its balance changes and hook effects.
+// Must be called inside a DB transaction.
+func (c *transactionCommitter) persist(txCtx context.Context, tx *Transaction) error {
 	// Create the transaction and entries within the DB transaction
 	if err := c.repo.CreateTransaction(txCtx, tx); err != nil {
 		return fmt.Errorf("failed to create transaction: %w", err)
@@ -553,13 +635,6 @@ func (c *transactionCommitter) commit(ctx context.Context, tx *Transaction) erro
 		}
 	}
 
-	// Commit the DB transaction
-	if err := c.repo.CommitTx(txCtx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	committed = true
-	c.logger.Debug("db transaction committed", "tx_id", tx.ID.String())
 	return nil
 }
 
@@ -6,4 +6,5 @@ import "errors"
 var (
 	ErrInsufficientLots = errors.New("insufficient lots for disposal")
 	ErrLotNotFound      = errors.New("tax lot not found")
+	ErrLotConsumed      = errors.New("tax lot has already been consumed by a later transaction")
 )
@@ -62,8 +62,17 @@ func (m *mockTaxLotRepo) UpdateLotRemaining(_ context.Context, lotID uuid.UUID,
 	return ErrLotNotFound
 }
 
-func (m *mockTaxLotRepo) GetLotsByAccount(_ context.Context, _ uuid.UUID, _ string) ([]*TaxLot, error) {
-	return nil, nil
+func (m *mockTaxLotRepo) GetLotsByAccount(_ context.Context, accountID uuid.UUID, asset string) ([]*TaxLot, error) {
+	var result []*TaxLot
+	for _, l := range m.lots {
+		if l.AccountID == accountID && l.Asset == asset {
+			result = append(result, l)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].AcquiredAt.Before(result[j].AcquiredAt)
+	})
+	return result, nil
 }
 
 func (m *mockTaxLotRepo) GetLotsByTransaction(_ context.Context, txID uuid.UUID) ([]*TaxLot, error) {
@@ -76,17 +85,49 @@ func (m *mockTaxLotRepo) GetLotsByTransaction(_ context.Context, txID uuid.UUID)
 	return result, nil
 }
 
+func (m *mockTaxLotRepo) DeleteTaxLot(_ context.Context, lotID uuid.UUID) error {
+	for i, l := range m.lots {
+		if l.ID == lotID {
+			m.lots = append(m.lots[:i], m.lots[i+1:]...)
+			return nil
+		}
+	}
+	return ErrLotNotFound
+}
+
 func (m *mockTaxLotRepo) CreateDisposal(_ context.Context, disposal *LotDisposal) error {
 	m.disposals = append(m.disposals, disposal)
 	return nil
 }
 
-func (m *mockTaxLotRepo) GetDisposalsByTransaction(_ context.Context, _ uuid.UUID) ([]*LotDisposal, error) {
-	return nil, nil
+func (m *mockTaxLotRepo) GetDisposalsByTransaction(_ context.Context, txID uuid.UUID) ([]*LotDisposal, error) {
+	var result []*LotDisposal
+	for _, d := range m.disposals {
+		if d.TransactionID == txID {
+			result = append(result, d)
+		}
+	}
+	return result, nil
 }
 
-func (m *mockTaxLotRepo) GetDisposalsByLot(_ context.Context, _ uuid.UUID) ([]*LotDisposal, error) {
-	return nil, nil
+func (m *mockTaxLotRepo) GetDisposalsByLot(_ context.Context, lotID uuid.UUID) ([]*LotDisposal, error) {
+	var result []*LotDisposal
+	for _, d := range m.disposals {
+		if d.LotID == lotID {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockTaxLotRepo) DeleteDisposal(_ context.Context, id uuid.UUID) error {
+	for i, d := range m.disposals {
+		if d.ID == id {
+			m.disposals = append(m.disposals[:i], m.disposals[i+1:]...)
+			return nil
+		}
+	}
+	return nil
 }
 
 func (m *mockTaxLotRepo) UpdateOverride(_ context.Context, _ uuid.UUID, _ *big.Int, _ string) error {
@@ -15,6 +15,7 @@ import (
 //
 // Classification is entry-type-based (not tx-type-based), so it works
 // automatically with any current or future transaction type.
+// Reversals are the exception: they unwind the original's lots instead.
 func NewTaxLotHook(repo TaxLotRepository, ledgerRepo Repository, log *logger.Logger) PostBalanceHook {
 	hookLog := log.WithField("component", "taxlot_hook")
 
@@ -23,152 +24,277 @@ func NewTaxLotHook(repo TaxLotRepository, ledgerRepo Repository, log *logger.Log
 			return nil
 		}
 
-		// Cache account lookups to avoid repeated DB hits
-		accountCache := make(map[uuid.UUID]*Account)
-		getAccount := func(accountID uuid.UUID) (*Account, error) {
-			if a, ok := accountCache[accountID]; ok {
-				return a, nil
-			}
-			a, err := ledgerRepo.GetAccount(ctx, accountID)
-			if err != nil {
-				return nil, err
-			}
-			accountCache[accountID] = a
-			return a, nil
+		if originalID := tx.ReversalOf(); originalID != nil {
+			return unwindTaxLots(ctx, repo, *originalID, hookLog)
 		}
 
-		// Separate entries into disposals and acquisitions.
-		// Process disposals first so we can link acquired lots to source lots.
-		type disposalEntry struct {
-			entry *Entry
-			acct  *Account
+		return newTaxLotApplier(repo, ledgerRepo, hookLog).apply(ctx, tx)
+	}
+}
+
+// taxLotApplier turns the wallet and collateral entries of a transaction into
+// FIFO disposals and new lots. The hook uses a fresh applier per transaction;
+// the rebuilder reuses one across a replay, restricted to the rebuilt pairs.
+type taxLotApplier struct {
+	repo       TaxLotRepository
+	ledgerRepo Repository
+	logger     *logger.Logger
+	accounts   map[uuid.UUID]*Account
+
+	// scope, when set, limits processing to these account/asset pairs
+	scope map[AccountAsset]bool
+	// prepareLot, when set, is called on every lot before it is created
+	prepareLot func(lot *TaxLot)
+}
+
+func newTaxLotApplier(repo TaxLotRepository, ledgerRepo Repository, log *logger.Logger) *taxLotApplier {
+	return &taxLotApplier{
+		repo:       repo,
+		ledgerRepo: ledgerRepo,
+		logger:     log,
+		accounts:   make(map[uuid.UUID]*Account),
+	}
+}
+
+// getAccount caches account lookups to avoid repeated DB hits
+func (a *taxLotApplier) getAccount(ctx context.Context, accountID uuid.UUID) (*Account, error) {
+	if acct, ok := a.accounts[accountID]; ok {
+		return acct, nil
+	}
+	acct, err := a.ledgerRepo.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	a.accounts[accountID] = acct
+	return acct, nil
+}
+
+func (a *taxLotApplier) apply(ctx context.Context, tx *Transaction) error {
+	// Separate entries into disposals and acquisitions.
+	// Process disposals first so we can link acquired lots to source lots.
+	type disposalEntry struct {
+		entry *Entry
+		acct  *Account
+	}
+	type acquisitionEntry struct {
+		entry *Entry
+		acct  *Account
+	}
+
+	var disposals []disposalEntry
+	var acquisitions []acquisitionEntry
+
+	for _, entry := range tx.Entries {
+		acct, err := a.getAccount(ctx, entry.AccountID)
+		if err != nil {
+			return fmt.Errorf("failed to lookup account %s for tax lot: %w", entry.AccountID, err)
 		}
-		type acquisitionEntry struct {
-			entry *Entry
-			acct  *Account
+
+		if acct.Type != AccountTypeCryptoWallet && acct.Type != AccountTypeCollateral {
+			continue
+		}
+		if a.scope != nil && !a.scope[AccountAsset{AccountID: acct.ID, Asset: entry.AssetID}] {
+			continue
 		}
 
-		var disposals []disposalEntry
-		var acquisitions []acquisitionEntry
+		switch entry.EntryType {
+		case EntryTypeAssetDecrease, EntryTypeCollateralDecrease:
+			disposals = append(disposals, disposalEntry{entry: entry, acct: acct})
+		case EntryTypeAssetIncrease, EntryTypeCollateralIncrease:
+			acquisitions = append(acquisitions, acquisitionEntry{entry: entry, acct: acct})
+		}
+	}
 
-		for _, entry := range tx.Entries {
-			acct, err := getAccount(entry.AccountID)
-			if err != nil {
-				return fmt.Errorf("failed to lookup account %s for tax lot: %w", entry.AccountID, err)
-			}
+	// Track disposals by (accountID, asset) → first consumed lot ID, for linking.
+	// Track disposal results per asset for internal-transfer lot linking
+	// and cost basis carry-over.
+	type disposalResult struct {
+		firstLotID *uuid.UUID
+		disposals  []*LotDisposal
+	}
+	disposalResults := make(map[string]*disposalResult) // key: asset
 
-			if acct.Type != AccountTypeCryptoWallet && acct.Type != AccountTypeCollateral {
-				continue
-			}
+	// --- Process disposals ---
+	for _, d := range disposals {
+		dt := classifyDisposalType(tx, d.entry)
+		proceedsPerUnit := d.entry.USDRate
+		if proceedsPerUnit == nil {
+			proceedsPerUnit = big.NewInt(0)
+		}
+
+		lotDisposals, err := DisposeFIFO(
+			ctx, a.repo,
+			d.acct.ID, d.entry.AssetID,
+			d.entry.Amount,
+			proceedsPerUnit,
+			dt,
+			tx.ID,
+			d.entry.OccurredAt,
+		)
+		if err == ErrInsufficientLots {
+			a.logger.Warn("insufficient lots for disposal, continuing",
+				"tx_id", tx.ID.String(),
+				"account_id", d.acct.ID.String(),
+				"asset", d.entry.AssetID,
+				"amount", d.entry.Amount.String())
+			// Don't fail the transaction
+		} else if err != nil {
+			return err
+		}
 
-			switch entry.EntryType {
-			case EntryTypeAssetDecrease, EntryTypeCollateralDecrease:
-				disposals = append(disposals, disposalEntry{entry: entry, acct: acct})
-			case EntryTypeAssetIncrease, EntryTypeCollateralIncrease:
-				acquisitions = append(acquisitions, acquisitionEntry{entry: entry, acct: acct})
+		// Accumulate disposal results for this asset
+		if len(lotDisposals) > 0 {
+			dr, exists := disposalResults[d.entry.AssetID]
+			if !exists {
+				id := lotDisposals[0].LotID
+				dr = &disposalResult{firstLotID: &id}
+				disposalResults[d.entry.AssetID] = dr
 			}
+			dr.disposals = append(dr.disposals, lotDisposals...)
 		}
+	}
 
-		// Track disposals by (accountID, asset) → first consumed lot ID, for linking.
-		// Track disposal results per asset for internal-transfer lot linking
-		// and cost basis carry-over.
-		type disposalResult struct {
-			firstLotID *uuid.UUID
-			disposals  []*LotDisposal
+	// --- Process acquisitions ---
+	for _, acq := range acquisitions {
+		costBasisPerUnit := acq.entry.USDRate
+		if costBasisPerUnit == nil {
+			costBasisPerUnit = big.NewInt(0)
 		}
-		disposalResults := make(map[string]*disposalResult) // key: asset
 
-		// --- Process disposals ---
-		for _, d := range disposals {
-			dt := classifyDisposalType(tx, d.entry)
-			proceedsPerUnit := d.entry.USDRate
-			if proceedsPerUnit == nil {
-				proceedsPerUnit = big.NewInt(0)
+		source := classifyCostBasisSource(tx, acq.entry)
+
+		var linkedLotID *uuid.UUID
+		if source == CostBasisLinkedTransfer || source == CostBasisLendingCarryOver ||
+			source == CostBasisStakingCarryOver || source == CostBasisMarginCarryOver {
+			var sourceDisposals []*LotDisposal
+			if dr, ok := disposalResults[acq.entry.AssetID]; ok {
+				linkedLotID = dr.firstLotID
+				sourceDisposals = dr.disposals
+			} else if a.scope != nil {
+				// The sending side is outside the rebuilt scope; its disposals are still on record
+				existing, err := a.existingDisposals(ctx, tx.ID, acq.entry.AssetID)
+				if err != nil {
+					return err
+				}
+				if len(existing) > 0 {
+					linkedLotID = &existing[0].LotID
+					sourceDisposals = existing
+				}
 			}
 
-			lotDisposals, err := DisposeFIFO(
-				ctx, repo,
-				d.acct.ID, d.entry.AssetID,
-				d.entry.Amount,
-				proceedsPerUnit,
-				dt,
-				tx.ID,
-				d.entry.OccurredAt,
-			)
-			if err == ErrInsufficientLots {
-				hookLog.Warn("insufficient lots for disposal, continuing",
-					"tx_id", tx.ID.String(),
-					"account_id", d.acct.ID.String(),
-					"asset", d.entry.AssetID,
-					"amount", d.entry.Amount.String())
-				// Don't fail the transaction
-			} else if err != nil {
-				return err
+			// Carry over weighted-average cost basis from consumed source lots
+			// instead of using FMV at transfer time.
+			waCost := weightedAvgCostBasis(ctx, a.repo, sourceDisposals)
+			if waCost != nil {
+				costBasisPerUnit = waCost
 			}
+		}
 
-			// Accumulate disposal results for this asset
-			if len(lotDisposals) > 0 {
-				dr, exists := disposalResults[d.entry.AssetID]
-				if !exists {
-					id := lotDisposals[0].LotID
-					dr = &disposalResult{firstLotID: &id}
-					disposalResults[d.entry.AssetID] = dr
-				}
-				dr.disposals = append(dr.disposals, lotDisposals...)
-			}
+		lot := &TaxLot{
+			ID:                   uuid.New(),
+			TransactionID:        tx.ID,
+			AccountID:            acq.acct.ID,
+			Asset:                acq.entry.AssetID,
+			QuantityAcquired:     new(big.Int).Set(acq.entry.Amount),
+			QuantityRemaining:    new(big.Int).Set(acq.entry.Amount),
+			AcquiredAt:           acq.entry.OccurredAt,
+			AutoCostBasisPerUnit: new(big.Int).Set(costBasisPerUnit),
+			AutoCostBasisSource:  source,
+			LinkedSourceLotID:    linkedLotID,
+			CreatedAt:            time.Now(),
+		}
+		if a.prepareLot != nil {
+			a.prepareLot(lot)
 		}
 
-		// --- Process acquisitions ---
-		for _, a := range acquisitions {
-			costBasisPerUnit := a.entry.USDRate
-			if costBasisPerUnit == nil {
-				costBasisPerUnit = big.NewInt(0)
-			}
+		if err := a.repo.CreateTaxLot(ctx, lot); err != nil {
+			return err
+		}
 
-			source := classifyCostBasisSource(tx)
+		a.logger.Debug("created tax lot",
+			"lot_id", lot.ID.String(),
+			"tx_id", tx.ID.String(),
+			"asset", lot.Asset,
+			"quantity", lot.QuantityAcquired.String(),
+			"source", string(lot.AutoCostBasisSource))
+	}
 
-			var linkedLotID *uuid.UUID
-			if tx.Type == TxTypeInternalTransfer || tx.Type == TxTypeLendingSupply || tx.Type == TxTypeLendingWithdraw {
-				if dr, ok := disposalResults[a.entry.AssetID]; ok {
-					linkedLotID = dr.firstLotID
+	return nil
+}
 
-					// Carry over weighted-average cost basis from consumed source lots
-					// instead of using FMV at transfer time.
-					waCost := weightedAvgCostBasis(ctx, repo, dr.disposals)
-					if waCost != nil {
-						costBasisPerUnit = waCost
-					}
-				}
-			}
+// existingDisposals returns the recorded disposals of txID on lots of the given asset
+func (a *taxLotApplier) existingDisposals(ctx context.Context, txID uuid.UUID, asset string) ([]*LotDisposal, error) {
+	all, err := a.repo.GetDisposalsByTransaction(ctx, txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disposals of %s: %w", txID, err)
+	}
 
-			lot := &TaxLot{
-				ID:                   uuid.New(),
-				TransactionID:        tx.ID,
-				AccountID:            a.acct.ID,
-				Asset:                a.entry.AssetID,
-				QuantityAcquired:     new(big.Int).Set(a.entry.Amount),
-				QuantityRemaining:    new(big.Int).Set(a.entry.Amount),
-				AcquiredAt:           a.entry.OccurredAt,
-				AutoCostBasisPerUnit: new(big.Int).Set(costBasisPerUnit),
-				AutoCostBasisSource:  source,
-				LinkedSourceLotID:    linkedLotID,
-				CreatedAt:            time.Now(),
-			}
+	var result []*LotDisposal
+	for _, d := range all {
+		lot, err := a.repo.GetTaxLot(ctx, d.LotID)
+		if err != nil {
+			continue
+		}
+		if lot.Asset == asset {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
 
-			if err := repo.CreateTaxLot(ctx, lot); err != nil {
-				return err
-			}
+// unwindTaxLots undoes the tax-lot effects of a reversed transaction:
+// quantities it disposed are returned to their source lots and the lots it
+// created are voided. Fails with ErrLotConsumed if any created lot has already
+// been disposed of by a later transaction, since voiding it would orphan
+// those disposals.
+func unwindTaxLots(ctx context.Context, repo TaxLotRepository, originalTxID uuid.UUID, log *logger.Logger) error {
+	created, err := repo.GetLotsByTransaction(ctx, originalTxID)
+	if err != nil {
+		return fmt.Errorf("failed to get lots created by %s: %w", originalTxID, err)
+	}
 
-			hookLog.Debug("created tax lot",
-				"lot_id", lot.ID.String(),
-				"tx_id", tx.ID.String(),
-				"asset", lot.Asset,
-				"quantity", lot.QuantityAcquired.String(),
-				"source", string(lot.AutoCostBasisSource))
+	for _, lot := range created {
+		consumed, err := repo.GetDisposalsByLot(ctx, lot.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get disposals for lot %s: %w", lot.ID, err)
+		}
+		if len(consumed) > 0 {
+			return fmt.Errorf("%w: lot %s", ErrLotConsumed, lot.ID)
 		}
+	}
 
-		return nil
+	disposals, err := repo.GetDisposalsByTransaction(ctx, originalTxID)
+	if err != nil {
+		return fmt.Errorf("failed to get disposals of %s: %w", originalTxID, err)
+	}
+
+	for _, d := range disposals {
+		lot, err := repo.GetTaxLotForUpdate(ctx, d.LotID)
+		if err != nil {
+			return fmt.Errorf("failed to lock lot %s: %w", d.LotID, err)
+		}
+
+		restored := new(big.Int).Add(lot.QuantityRemaining, d.QuantityDisposed)
+		if err := repo.UpdateLotRemaining(ctx, lot.ID, restored); err != nil {
+			return err
+		}
+		if err := repo.DeleteDisposal(ctx, d.ID); err != nil {
+			return err
+		}
 	}
+
+	for _, lot := range created {
+		if err := repo.DeleteTaxLot(ctx, lot.ID); err != nil {
+			return err
+		}
+	}
+
+	log.Debug("unwound tax lots",
+		"original_tx_id", originalTxID.String(),
+		"restored_disposals", len(disposals),
+		"voided_lots", len(created))
+
+	return nil
 }
 
 // classifyDisposalType determines the disposal type from the transaction and entry.
@@ -179,21 +305,41 @@ func classifyDisposalType(tx *Transaction, entry *Entry) DisposalType {
 			return DisposalTypeGasFee
 		}
 	}
+	if isStakingTransfer(entry) {
+		return DisposalTypeStakingTransfer
+	}
+	if isMarginTransfer(entry) {
+		return DisposalTypeMarginTransfer
+	}
 
 	switch tx.Type {
 	case TxTypeInternalTransfer:
 		return DisposalTypeInternalTransfer
 	case TxTypeLendingSupply, TxTypeLendingWithdraw:
 		return DisposalTypeLendingTransfer
+	case TxTypeLendingLiquidation:
+		return DisposalTypeLiquidation
 	}
 
 	return DisposalTypeSale
 }
 
 // classifyCostBasisSource determines the cost basis source from the transaction type.
-func classifyCostBasisSource(tx *Transaction) CostBasisSource {
+// Staking moves that keep the same asset are marked on the entry; liquid-staking
+// exchanges into a receipt token and NFT trades are priced like a swap.
+// Margin posted to or released from a derivatives account is marked the same
+// way, so realized PnL booked in the same transaction keeps its market price.
+func classifyCostBasisSource(tx *Transaction, entry *Entry) CostBasisSource {
+	if isStakingTransfer(entry) {
+		return CostBasisStakingCarryOver
+	}
+	if isMarginTransfer(entry) {
+		return CostBasisMarginCarryOver
+	}
+
 	switch tx.Type {
-	case TxTypeSwap:
+	case TxTypeSwap, TxTypeStake, TxTypeUnstake,
+		TxTypeNFTBuy, TxTypeNFTSell, TxTypeNFTMint:
 		return CostBasisSwapPrice
 	case TxTypeInternalTransfer:
 		return CostBasisLinkedTransfer
@@ -206,6 +352,26 @@ func classifyCostBasisSource(tx *Transaction) CostBasisSource {
 	}
 }
 
+// isStakingTransfer reports whether the entry moves an asset between the wallet
+// and its staked account without changing the asset
+func isStakingTransfer(entry *Entry) bool {
+	if entry == nil || entry.Metadata == nil {
+		return false
+	}
+	et, ok := entry.Metadata["entry_type"].(string)
+	return ok && et == "staking_transfer"
+}
+
+// isMarginTransfer reports whether the entry moves margin between the wallet
+// and its derivatives margin account
+func isMarginTransfer(entry *Entry) bool {
+	if entry == nil || entry.Metadata == nil {
+		return false
+	}
+	et, ok := entry.Metadata["entry_type"].(string)
+	return ok && et == "margin_transfer"
+}
+
 // weightedAvgCostBasis computes a weighted-average cost basis from consumed
 // source lots. Used for internal transfers so cost basis carries over
 // rather than using FMV at transfer time.
@@ -36,12 +36,21 @@ func (m *mockLedgerRepo) FindTransactionsBySource(context.Context, string, strin
 func (m *mockLedgerRepo) ListTransactions(context.Context, TransactionFilters) ([]*Transaction, error) {
 	return nil, nil
 }
+func (m *mockLedgerRepo) CountTransactions(context.Context, TransactionFilters) (int, error) {
+	return 0, nil
+}
 func (m *mockLedgerRepo) GetEntriesByTransaction(context.Context, uuid.UUID) ([]*Entry, error) {
 	return nil, nil
 }
 func (m *mockLedgerRepo) GetEntriesByAccount(context.Context, uuid.UUID) ([]*Entry, error) {
 	return nil, nil
 }
+func (m *mockLedgerRepo) HasEntriesAfter(context.Context, uuid.UUID, string, time.Time) (bool, error) {
+	return false, nil
+}
+func (m *mockLedgerRepo) MoveTransactionAnnotations(context.Context, uuid.UUID, uuid.UUID) error {
+	return nil
+}
 func (m *mockLedgerRepo) GetAccountBalance(_ context.Context, id uuid.UUID, asset string) (*AccountBalance, error) {
 	return &AccountBalance{AccountID: id, AssetID: asset, Balance: big.NewInt(0), USDValue: big.NewInt(0), LastUpdated: time.Now()}, nil
 }
@@ -417,6 +426,194 @@ func TestTaxLotHook_InternalTransfer_DisposalPlusLinkedLot(t *testing.T) {
 	}
 }
 
+func TestTaxLotHook_Stake_CarriesCostBasisToStakedAccount(t *testing.T) {
+	walletAcctID := uuid.New()
+	stakedAcctID := uuid.New()
+
+	existingLot := &TaxLot{
+		ID:                   uuid.New(),
+		TransactionID:        uuid.New(),
+		AccountID:            walletAcctID,
+		Asset:                "ETH",
+		QuantityAcquired:     big.NewInt(1000),
+		QuantityRemaining:    big.NewInt(1000),
+		AcquiredAt:           time.Now().Add(-time.Hour),
+		AutoCostBasisPerUnit: big.NewInt(200_000_000),
+		AutoCostBasisSource:  CostBasisFMVAtTransfer,
+		CreatedAt:            time.Now(),
+	}
+
+	stakedAcct := &Account{
+		ID:      stakedAcctID,
+		Code:    "staked.Solana.w.eth.ETH",
+		Type:    AccountTypeCollateral,
+		AssetID: "ETH",
+	}
+
+	taxLotRepo := &mockTaxLotRepo{lots: []*TaxLot{existingLot}}
+	ledgerRepo := &mockLedgerRepo{accounts: map[uuid.UUID]*Account{
+		walletAcctID: walletAccount(walletAcctID),
+		stakedAcctID: stakedAcct,
+	}}
+
+	hook := NewTaxLotHook(taxLotRepo, ledgerRepo, newTestLogger())
+
+	marker := map[string]interface{}{"entry_type": "staking_transfer"}
+	tx := &Transaction{
+		ID:   uuid.New(),
+		Type: TxTypeStake,
+		Entries: []*Entry{
+			makeEntry(stakedAcctID, Debit, EntryTypeCollateralIncrease, 400, "ETH", marker),
+			makeEntry(walletAcctID, Credit, EntryTypeAssetDecrease, 400, "ETH", marker),
+		},
+	}
+
+	if err := hook(context.Background(), tx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(taxLotRepo.disposals) != 1 {
+		t.Fatalf("expected 1 disposal, got %d", len(taxLotRepo.disposals))
+	}
+	if taxLotRepo.disposals[0].DisposalType != DisposalTypeStakingTransfer {
+		t.Errorf("expected disposal type staking_transfer, got %s", taxLotRepo.disposals[0].DisposalType)
+	}
+
+	if len(taxLotRepo.lots) != 2 {
+		t.Fatalf("expected 2 lots, got %d", len(taxLotRepo.lots))
+	}
+	newLot := taxLotRepo.lots[1]
+	if newLot.AutoCostBasisSource != CostBasisStakingCarryOver {
+		t.Errorf("expected source staking_carry_over, got %s", newLot.AutoCostBasisSource)
+	}
+	if newLot.LinkedSourceLotID == nil || *newLot.LinkedSourceLotID != existingLot.ID {
+		t.Error("expected staked lot to link to the wallet lot")
+	}
+	if newLot.AutoCostBasisPerUnit.Cmp(big.NewInt(200_000_000)) != 0 {
+		t.Errorf("expected cost basis carry-over 200000000, got %s", newLot.AutoCostBasisPerUnit)
+	}
+}
+
+func TestTaxLotHook_DerivativeClose_CarriesMarginAndBuysProfit(t *testing.T) {
+	walletAcctID := uuid.New()
+	marginAcctID := uuid.New()
+	incomeAcctID := uuid.New()
+
+	marginLot := &TaxLot{
+		ID:                   uuid.New(),
+		TransactionID:        uuid.New(),
+		AccountID:            marginAcctID,
+		Asset:                "ETH",
+		QuantityAcquired:     big.NewInt(1000),
+		QuantityRemaining:    big.NewInt(1000),
+		AcquiredAt:           time.Now().Add(-time.Hour),
+		AutoCostBasisPerUnit: big.NewInt(150_000_000),
+		AutoCostBasisSource:  CostBasisMarginCarryOver,
+		CreatedAt:            time.Now(),
+	}
+
+	taxLotRepo := &mockTaxLotRepo{lots: []*TaxLot{marginLot}}
+	ledgerRepo := &mockLedgerRepo{accounts: map[uuid.UUID]*Account{
+		walletAcctID: walletAccount(walletAcctID),
+		marginAcctID: {ID: marginAcctID, Code: "margin.GMX.w.arbitrum.ETH", Type: AccountTypeCollateral, AssetID: "ETH"},
+		incomeAcctID: incomeAccount(incomeAcctID),
+	}}
+
+	hook := NewTaxLotHook(taxLotRepo, ledgerRepo, newTestLogger())
+
+	marker := map[string]interface{}{"entry_type": "margin_transfer"}
+	tx := &Transaction{
+		ID:   uuid.New(),
+		Type: TxTypeDerivativeClose,
+		Entries: []*Entry{
+			makeEntry(marginAcctID, Credit, EntryTypeCollateralDecrease, 1000, "ETH", marker),
+			makeEntry(walletAcctID, Debit, EntryTypeAssetIncrease, 1000, "ETH", marker),
+			makeEntry(walletAcctID, Debit, EntryTypeAssetIncrease, 300, "ETH", nil),
+			makeEntry(incomeAcctID, Credit, EntryTypeIncome, 300, "ETH", nil),
+		},
+	}
+
+	if err := hook(context.Background(), tx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(taxLotRepo.disposals) != 1 {
+		t.Fatalf("expected 1 disposal, got %d", len(taxLotRepo.disposals))
+	}
+	if taxLotRepo.disposals[0].DisposalType != DisposalTypeMarginTransfer {
+		t.Errorf("expected disposal type margin_transfer, got %s", taxLotRepo.disposals[0].DisposalType)
+	}
+
+	if len(taxLotRepo.lots) != 3 {
+		t.Fatalf("expected 3 lots, got %d", len(taxLotRepo.lots))
+	}
+	returned, profit := taxLotRepo.lots[1], taxLotRepo.lots[2]
+	if returned.AutoCostBasisSource != CostBasisMarginCarryOver {
+		t.Errorf("expected source margin_carry_over, got %s", returned.AutoCostBasisSource)
+	}
+	if returned.LinkedSourceLotID == nil || *returned.LinkedSourceLotID != marginLot.ID {
+		t.Error("expected returned margin lot to link to the margin lot")
+	}
+	if returned.AutoCostBasisPerUnit.Cmp(big.NewInt(150_000_000)) != 0 {
+		t.Errorf("expected cost basis carry-over 150000000, got %s", returned.AutoCostBasisPerUnit)
+	}
+	if profit.AutoCostBasisSource != CostBasisFMVAtTransfer || profit.LinkedSourceLotID != nil {
+		t.Errorf("expected profit acquired at market price, got %s", profit.AutoCostBasisSource)
+	}
+}
+
+func TestTaxLotHook_Liquidation_DisposesCollateralLot(t *testing.T) {
+	collateralAcctID := uuid.New()
+	liabilityAcctID := uuid.New()
+	clearingAcctID := uuid.New()
+
+	existingLot := &TaxLot{
+		ID:                   uuid.New(),
+		TransactionID:        uuid.New(),
+		AccountID:            collateralAcctID,
+		Asset:                "ETH",
+		QuantityAcquired:     big.NewInt(1000),
+		QuantityRemaining:    big.NewInt(1000),
+		AcquiredAt:           time.Now().Add(-time.Hour),
+		AutoCostBasisPerUnit: big.NewInt(100_000_000),
+		AutoCostBasisSource:  CostBasisLendingCarryOver,
+		CreatedAt:            time.Now(),
+	}
+
+	taxLotRepo := &mockTaxLotRepo{lots: []*TaxLot{existingLot}}
+	ledgerRepo := &mockLedgerRepo{accounts: map[uuid.UUID]*Account{
+		collateralAcctID: {ID: collateralAcctID, Code: "collateral.aave.test.eth.ETH", Type: AccountTypeCollateral, AssetID: "ETH"},
+		liabilityAcctID:  {ID: liabilityAcctID, Code: "liability.aave.test.eth.USDC", Type: AccountTypeLiability, AssetID: "USDC"},
+		clearingAcctID:   {ID: clearingAcctID, Code: "clearing.eth.ETH", Type: AccountTypeClearing, AssetID: "ETH"},
+	}}
+
+	hook := NewTaxLotHook(taxLotRepo, ledgerRepo, newTestLogger())
+
+	tx := &Transaction{
+		ID:   uuid.New(),
+		Type: TxTypeLendingLiquidation,
+		Entries: []*Entry{
+			makeEntry(collateralAcctID, Credit, EntryTypeCollateralDecrease, 400, "ETH", nil),
+			makeEntry(clearingAcctID, Debit, EntryTypeClearing, 400, "ETH", nil),
+			makeEntry(liabilityAcctID, Debit, EntryTypeLiabilityDecrease, 300, "USDC", nil),
+		},
+	}
+
+	if err := hook(context.Background(), tx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(taxLotRepo.disposals) != 1 {
+		t.Fatalf("expected 1 disposal, got %d", len(taxLotRepo.disposals))
+	}
+	if taxLotRepo.disposals[0].DisposalType != DisposalTypeLiquidation {
+		t.Errorf("expected disposal type liquidation, got %s", taxLotRepo.disposals[0].DisposalType)
+	}
+	if existingLot.QuantityRemaining.Cmp(big.NewInt(600)) != 0 {
+		t.Errorf("expected remaining 600, got %s", existingLot.QuantityRemaining)
+	}
+}
+
 func TestTaxLotHook_NonWalletEntries_Skipped(t *testing.T) {
 	incomeAcctID := uuid.New()
 	expenseAcctID := uuid.New()
@@ -16,6 +16,8 @@ const (
 	CostBasisLinkedTransfer       CostBasisSource = "linked_transfer"
 	CostBasisGenesisApproximation CostBasisSource = "genesis_approximation"
 	CostBasisLendingCarryOver     CostBasisSource = "lending_carry_over"
+	CostBasisStakingCarryOver     CostBasisSource = "staking_carry_over"
+	CostBasisMarginCarryOver      CostBasisSource = "margin_carry_over"
 )
 
 // DisposalType describes how the asset was disposed of
@@ -26,6 +28,9 @@ const (
 	DisposalTypeInternalTransfer DisposalType = "internal_transfer"
 	DisposalTypeGasFee            DisposalType = "gas_fee"
 	DisposalTypeLendingTransfer   DisposalType = "lending_transfer"
+	DisposalTypeStakingTransfer   DisposalType = "staking_transfer"
+	DisposalTypeLiquidation       DisposalType = "liquidation"
+	DisposalTypeMarginTransfer    DisposalType = "margin_transfer"
 )
 
 // TaxLot represents a batch of asset acquired in a single transaction.
@@ -62,6 +67,18 @@ func (l *TaxLot) IsOpen() bool {
 	return l.QuantityRemaining != nil && l.QuantityRemaining.Sign() > 0
 }
 
+// HeldOverYears reports whether an asset acquired at acquiredAt and disposed
+// of at disposedAt was held for more than the given number of calendar years.
+// Days are compared in UTC, so the anniversary itself is not enough: a lot
+// bought on 1 March 2023 is held over a year from 2 March 2024, 367 days later.
+func HeldOverYears(acquiredAt, disposedAt time.Time, years int) bool {
+	acquired := acquiredAt.UTC()
+	disposed := disposedAt.UTC()
+	anniversary := time.Date(acquired.Year()+years, acquired.Month(), acquired.Day(), 0, 0, 0, 0, time.UTC)
+	disposedDay := time.Date(disposed.Year(), disposed.Month(), disposed.Day(), 0, 0, 0, 0, time.UTC)
+	return disposedDay.After(anniversary)
+}
+
 // LotDisposal records the consumption of a tax lot during a disposal event.
 type LotDisposal struct {
 	ID               uuid.UUID
@@ -19,11 +19,13 @@ type TaxLotRepository interface {
 	UpdateLotRemaining(ctx context.Context, lotID uuid.UUID, newRemaining *big.Int) error
 	GetLotsByAccount(ctx context.Context, accountID uuid.UUID, asset string) ([]*TaxLot, error)
 	GetLotsByTransaction(ctx context.Context, txID uuid.UUID) ([]*TaxLot, error)
+	DeleteTaxLot(ctx context.Context, lotID uuid.UUID) error
 
 	// Disposal CRUD
 	CreateDisposal(ctx context.Context, disposal *LotDisposal) error
 	GetDisposalsByTransaction(ctx context.Context, txID uuid.UUID) ([]*LotDisposal, error)
 	GetDisposalsByLot(ctx context.Context, lotID uuid.UUID) ([]*LotDisposal, error)
+	DeleteDisposal(ctx context.Context, id uuid.UUID) error
 
 	// Override management
 	UpdateOverride(ctx context.Context, lotID uuid.UUID, costBasis *big.Int, reason string) error
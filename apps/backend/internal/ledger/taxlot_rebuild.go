@@ -0,0 +1,328 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// TaxLotRebuilder recomputes lots and disposals when the order of history
+// changes: a backdated manual transaction, a late-arriving synced one, or an
+// amendment that moves a transaction in time.
+//
+// A rebuild from a point in time deletes every lot acquired and every
+// disposal made at or after it on the given pairs, then replays the
+// remaining history through the same logic as NewTaxLotHook, in OccurredAt
+// order. Cost-basis overrides are carried over to the lot recreated for the
+// same source transaction, account and asset.
+//
+// It extends TaxLotReplayer: older lots are rewound in place, and Rewind is
+// available on its own for steps that only need disposals removed.
+//
+// All methods expect to run inside the ledger DB transaction.
+type TaxLotRebuilder struct {
+	*TaxLotReplayer
+}
+
+// NewTaxLotRebuilder creates a new tax lot rebuilder
+func NewTaxLotRebuilder(repo TaxLotRepository, ledgerRepo Repository, log *logger.Logger) *TaxLotRebuilder {
+	return &TaxLotRebuilder{
+		TaxLotReplayer: &TaxLotReplayer{
+			repo:       repo,
+			ledgerRepo: ledgerRepo,
+			logger:     log.WithField("component", "taxlot_rebuild"),
+		},
+	}
+}
+
+// lotKey matches a rebuilt lot to the lot it replaces
+type lotKey struct {
+	TransactionID uuid.UUID
+	AccountID     uuid.UUID
+	Asset         string
+}
+
+// savedOverride is a cost-basis override lifted off a lot deleted by a rebuild
+type savedOverride struct {
+	lot      *TaxLot
+	history  []*LotOverrideHistory
+	newLotID uuid.UUID
+}
+
+// Rebuild deletes lots and disposals dated at or after from on the given
+// pairs and recomputes them by replaying the ledger. A zero from rebuilds
+// the pairs from scratch.
+//
+// Reversal transactions and the transactions they reverse are skipped.
+// The receiving side of a transfer sent from the pairs within the window is
+// rebuilt too, since its lots carry over the sender's cost basis. Transfer
+// lots whose sending side is outside the pairs link to the disposals already
+// on record for that side.
+func (r *TaxLotRebuilder) Rebuild(ctx context.Context, pairs []AccountAsset, from time.Time) error {
+	start := time.Now()
+
+	scope, err := r.trackedScope(ctx, pairs)
+	if err != nil {
+		return err
+	}
+	if len(scope) == 0 {
+		return nil
+	}
+
+	txs, err := r.expandToCounterparties(ctx, scope, from)
+	if err != nil {
+		return err
+	}
+
+	overrides := make(map[lotKey][]*savedOverride)
+	for p := range scope {
+		if err := r.dropPair(ctx, p, from, overrides); err != nil {
+			return err
+		}
+	}
+
+	var restored []*savedOverride
+	applier := newTaxLotApplier(r.repo, r.ledgerRepo, r.logger)
+	applier.scope = scope
+	applier.prepareLot = func(lot *TaxLot) {
+		key := lotKey{TransactionID: lot.TransactionID, AccountID: lot.AccountID, Asset: lot.Asset}
+		saved := overrides[key]
+		if len(saved) == 0 {
+			return
+		}
+		o := saved[0]
+		overrides[key] = saved[1:]
+
+		lot.OverrideCostBasisPerUnit = o.lot.OverrideCostBasisPerUnit
+		lot.OverrideReason = o.lot.OverrideReason
+		lot.OverrideAt = o.lot.OverrideAt
+		o.newLotID = lot.ID
+		restored = append(restored, o)
+	}
+
+	for _, tx := range txs {
+		if err := applier.apply(ctx, tx); err != nil {
+			return fmt.Errorf("failed to replay transaction %s: %w", tx.ID, err)
+		}
+	}
+
+	for _, o := range restored {
+		for _, h := range o.history {
+			h.LotID = o.newLotID
+			if err := r.repo.CreateOverrideHistory(ctx, h); err != nil {
+				return err
+			}
+		}
+	}
+	for key, left := range overrides {
+		for range left {
+			r.logger.Warn("cost basis override dropped: source transaction no longer creates a lot",
+				"tx_id", key.TransactionID.String(),
+				"account_id", key.AccountID.String(),
+				"asset", key.Asset)
+		}
+	}
+
+	r.logger.WithDuration(time.Since(start)).Info("tax lots rebuilt",
+		"pairs", len(scope),
+		"from", from,
+		"transactions", len(txs),
+		"overrides_restored", len(restored))
+
+	return nil
+}
+
+// RebuildAccounts rebuilds every asset ever held in the given accounts from scratch.
+func (r *TaxLotRebuilder) RebuildAccounts(ctx context.Context, accountIDs []uuid.UUID) error {
+	var pairs []AccountAsset
+	for _, id := range accountIDs {
+		entries, err := r.ledgerRepo.GetEntriesByAccount(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get entries for %s: %w", id, err)
+		}
+		for _, e := range entries {
+			pairs = append(pairs, AccountAsset{AccountID: id, Asset: e.AssetID})
+		}
+	}
+	return r.Rebuild(ctx, pairs, time.Time{})
+}
+
+// RebuildIfBackdated rebuilds the pairs tx changes from tx.OccurredAt when
+// later-dated entries already exist on them, i.e. when tx was inserted into
+// the past and lots were consumed without it.
+func (r *TaxLotRebuilder) RebuildIfBackdated(ctx context.Context, tx *Transaction) error {
+	if tx.ReversalOf() != nil {
+		return nil
+	}
+
+	var stale []AccountAsset
+	for _, p := range balancePairs(tx.Entries) {
+		later, err := r.ledgerRepo.HasEntriesAfter(ctx, p.AccountID, p.Asset, tx.OccurredAt)
+		if err != nil {
+			return err
+		}
+		if later {
+			stale = append(stale, p)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	r.logger.Info("backdated transaction, rebuilding tax lots",
+		"tx_id", tx.ID.String(),
+		"occurred_at", tx.OccurredAt,
+		"pairs", len(stale))
+
+	return r.Rebuild(ctx, stale, tx.OccurredAt)
+}
+
+// expandToCounterparties adds to scope the tracked pairs that receive a
+// cost-basis carry-over from a disposal on the scope within the window, until
+// no transfer leads out of it. It returns the transactions to replay.
+func (r *TaxLotRebuilder) expandToCounterparties(ctx context.Context, scope map[AccountAsset]bool, from time.Time) ([]*Transaction, error) {
+	for {
+		txs, err := r.transactionsSince(ctx, scope, from)
+		if err != nil {
+			return nil, err
+		}
+
+		var receiving []AccountAsset
+		for _, tx := range txs {
+			receiving = append(receiving, carryOverCounterparties(tx, scope)...)
+		}
+		added, err := r.trackedScope(ctx, receiving)
+		if err != nil {
+			return nil, err
+		}
+		if len(added) == 0 {
+			return txs, nil
+		}
+		for p := range added {
+			scope[p] = true
+		}
+	}
+}
+
+// carryOverCounterparties returns the pairs outside scope that acquire an
+// asset in tx with cost basis carried over from a disposal on scope
+func carryOverCounterparties(tx *Transaction, scope map[AccountAsset]bool) []AccountAsset {
+	disposed := make(map[string]bool)
+	for _, e := range tx.Entries {
+		if (e.EntryType == EntryTypeAssetDecrease || e.EntryType == EntryTypeCollateralDecrease) &&
+			scope[AccountAsset{AccountID: e.AccountID, Asset: e.AssetID}] {
+			disposed[e.AssetID] = true
+		}
+	}
+
+	var pairs []AccountAsset
+	for _, e := range tx.Entries {
+		if e.EntryType != EntryTypeAssetIncrease && e.EntryType != EntryTypeCollateralIncrease {
+			continue
+		}
+		p := AccountAsset{AccountID: e.AccountID, Asset: e.AssetID}
+		if scope[p] || !disposed[e.AssetID] {
+			continue
+		}
+		switch classifyCostBasisSource(tx, e) {
+		case CostBasisLinkedTransfer, CostBasisLendingCarryOver, CostBasisStakingCarryOver, CostBasisMarginCarryOver:
+			pairs = append(pairs, p)
+		}
+	}
+	return pairs
+}
+
+// dropPair deletes lots acquired at or after from, with their disposals,
+// collecting their overrides into overrides, then rewinds the older lots.
+func (r *TaxLotRebuilder) dropPair(ctx context.Context, p AccountAsset, from time.Time, overrides map[lotKey][]*savedOverride) error {
+	lots, err := r.repo.GetLotsByAccount(ctx, p.AccountID, p.Asset)
+	if err != nil {
+		return fmt.Errorf("failed to get lots for %s/%s: %w", p.AccountID, p.Asset, err)
+	}
+
+	for _, lot := range lots {
+		if lot.AcquiredAt.Before(from) {
+			continue
+		}
+
+		disposals, err := r.repo.GetDisposalsByLot(ctx, lot.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get disposals for lot %s: %w", lot.ID, err)
+		}
+		for _, d := range disposals {
+			if err := r.repo.DeleteDisposal(ctx, d.ID); err != nil {
+				return err
+			}
+		}
+
+		if lot.OverrideCostBasisPerUnit != nil {
+			history, err := r.repo.GetOverrideHistory(ctx, lot.ID)
+			if err != nil {
+				return fmt.Errorf("failed to get override history for lot %s: %w", lot.ID, err)
+			}
+			key := lotKey{TransactionID: lot.TransactionID, AccountID: lot.AccountID, Asset: lot.Asset}
+			overrides[key] = append(overrides[key], &savedOverride{lot: lot, history: history})
+		}
+		if err := r.repo.DeleteTaxLot(ctx, lot.ID); err != nil {
+			return err
+		}
+	}
+
+	return r.rewindPair(ctx, p, from)
+}
+
+// transactionsSince returns the completed transactions with entries on the
+// scope dated at or after from, in replay order. Reversals and the
+// transactions they reverse net to zero and are left out.
+func (r *TaxLotRebuilder) transactionsSince(ctx context.Context, scope map[AccountAsset]bool, from time.Time) ([]*Transaction, error) {
+	accounts := make(map[uuid.UUID]bool)
+	for p := range scope {
+		accounts[p.AccountID] = true
+	}
+
+	txs := make(map[uuid.UUID]*Transaction)
+	reversed := make(map[uuid.UUID]bool)
+	for accountID := range accounts {
+		entries, err := r.ledgerRepo.GetEntriesByAccount(ctx, accountID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get entries for %s: %w", accountID, err)
+		}
+		for _, e := range entries {
+			if !scope[AccountAsset{AccountID: accountID, Asset: e.AssetID}] || e.OccurredAt.Before(from) {
+				continue
+			}
+			if _, ok := txs[e.TransactionID]; ok {
+				continue
+			}
+			tx, err := r.ledgerRepo.GetTransaction(ctx, e.TransactionID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get transaction %s for rebuild: %w", e.TransactionID, err)
+			}
+			txs[tx.ID] = tx
+			if originalID := tx.ReversalOf(); originalID != nil {
+				reversed[*originalID] = true
+			}
+		}
+	}
+
+	result := make([]*Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if !tx.IsCompleted() || tx.ReversalOf() != nil || reversed[tx.ID] {
+			continue
+		}
+		result = append(result, tx)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].OccurredAt.Equal(result[j].OccurredAt) {
+			return result[i].OccurredAt.Before(result[j].OccurredAt)
+		}
+		return result[i].RecordedAt.Before(result[j].RecordedAt)
+	})
+
+	return result, nil
+}
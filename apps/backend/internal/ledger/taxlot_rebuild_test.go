@@ -0,0 +1,198 @@
+package ledger
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// rebuildFixture records income A (1000 ETH, -3h) and an outcome of 500 ETH
+// (-1h) so that A's lot is half consumed.
+type rebuildFixture struct {
+	repo         *reversalLedgerRepo
+	lots         *mockTaxLotRepo
+	svc          *Service
+	walletAcctID uuid.UUID
+	incomeAcctID uuid.UUID
+	incomeA      *Transaction
+	now          time.Time
+}
+
+func newRebuildFixture(t *testing.T) *rebuildFixture {
+	t.Helper()
+	walletAcctID, incomeAcctID, expenseAcctID := uuid.New(), uuid.New(), uuid.New()
+	repo := newReversalLedgerRepo(walletAccount(walletAcctID), incomeAccount(incomeAcctID), expenseAccount(expenseAcctID))
+	lots := &mockTaxLotRepo{}
+	svc := newAmendService(t, repo, lots)
+
+	now := time.Now()
+	incomeA := completedTxAt(now.Add(-3*time.Hour), TxTypeManualIncome,
+		makeEntry(walletAcctID, Debit, EntryTypeAssetIncrease, 1000, "ETH", nil),
+		makeEntry(incomeAcctID, Credit, EntryTypeIncome, 1000, "ETH", nil),
+	)
+	recordDirect(t, svc, incomeA)
+	recordDirect(t, svc, completedTxAt(now.Add(-time.Hour), TxTypeManualOutcome,
+		makeEntry(expenseAcctID, Debit, EntryTypeExpense, 500, "ETH", nil),
+		makeEntry(walletAcctID, Credit, EntryTypeAssetDecrease, 500, "ETH", nil),
+	))
+
+	return &rebuildFixture{
+		repo:         repo,
+		lots:         lots,
+		svc:          svc,
+		walletAcctID: walletAcctID,
+		incomeAcctID: incomeAcctID,
+		incomeA:      incomeA,
+		now:          now,
+	}
+}
+
+func (f *rebuildFixture) lotOf(t *testing.T, txID uuid.UUID) *TaxLot {
+	t.Helper()
+	lots, _ := f.lots.GetLotsByTransaction(context.Background(), txID)
+	if len(lots) != 1 {
+		t.Fatalf("expected 1 lot for %s, got %d", txID, len(lots))
+	}
+	return lots[0]
+}
+
+func TestRecordTransaction_Backdated_RebuildsLots(t *testing.T) {
+	f := newRebuildFixture(t)
+
+	occurredAt := f.now.Add(-4 * time.Hour)
+	incomeB, err := f.svc.RecordTransaction(context.Background(), TxTypeManualIncome, "manual", nil, occurredAt,
+		map[string]interface{}{"amount": int64(1000), "occurred_at": occurredAt})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// B is now the oldest lot, so the outcome consumes it instead of A
+	if remaining := f.lotOf(t, incomeB.ID).QuantityRemaining; remaining.Cmp(big.NewInt(500)) != 0 {
+		t.Errorf("expected backdated lot remaining 500, got %s", remaining)
+	}
+	if remaining := f.lotOf(t, f.incomeA.ID).QuantityRemaining; remaining.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("expected lot A untouched, got %s", remaining)
+	}
+	if len(f.lots.disposals) != 1 {
+		t.Fatalf("expected 1 disposal after rebuild, got %d", len(f.lots.disposals))
+	}
+}
+
+func TestRecordTransaction_InOrder_KeepsLots(t *testing.T) {
+	f := newRebuildFixture(t)
+	lotA := f.lotOf(t, f.incomeA.ID)
+
+	occurredAt := f.now
+	if _, err := f.svc.RecordTransaction(context.Background(), TxTypeManualIncome, "manual", nil, occurredAt,
+		map[string]interface{}{"amount": int64(1000), "occurred_at": occurredAt}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if f.lotOf(t, f.incomeA.ID) != lotA {
+		t.Errorf("expected lot A not to be rebuilt")
+	}
+	if lotA.QuantityRemaining.Cmp(big.NewInt(500)) != 0 {
+		t.Errorf("expected lot A remaining 500, got %s", lotA.QuantityRemaining)
+	}
+}
+
+func TestTaxLotRebuilder_PreservesOverride(t *testing.T) {
+	f := newRebuildFixture(t)
+
+	override := big.NewInt(150_000_000_00)
+	reason := "bought OTC"
+	oldLot := f.lotOf(t, f.incomeA.ID)
+	oldLot.OverrideCostBasisPerUnit = override
+	oldLot.OverrideReason = &reason
+
+	rebuilder := NewTaxLotRebuilder(f.lots, f.repo, newTestLogger())
+	if err := rebuilder.RebuildAccounts(context.Background(), []uuid.UUID{f.walletAcctID}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	newLot := f.lotOf(t, f.incomeA.ID)
+	if newLot.ID == oldLot.ID {
+		t.Fatalf("expected lot to be recreated")
+	}
+	if newLot.OverrideCostBasisPerUnit == nil || newLot.OverrideCostBasisPerUnit.Cmp(override) != 0 {
+		t.Errorf("expected override %s carried over, got %v", override, newLot.OverrideCostBasisPerUnit)
+	}
+	if newLot.OverrideReason == nil || *newLot.OverrideReason != reason {
+		t.Errorf("expected override reason carried over, got %v", newLot.OverrideReason)
+	}
+	if newLot.QuantityRemaining.Cmp(big.NewInt(500)) != 0 {
+		t.Errorf("expected rebuilt lot remaining 500, got %s", newLot.QuantityRemaining)
+	}
+}
+
+func TestTaxLotRebuilder_SkipsReversedTransactions(t *testing.T) {
+	f := newRebuildFixture(t)
+
+	incomeB := completedTxAt(f.now.Add(-4*time.Hour), TxTypeManualIncome,
+		makeEntry(f.walletAcctID, Debit, EntryTypeAssetIncrease, 200, "ETH", nil),
+		makeEntry(f.incomeAcctID, Credit, EntryTypeIncome, 200, "ETH", nil),
+	)
+	recordDirect(t, f.svc, incomeB)
+	if _, err := f.svc.ReverseTransaction(context.Background(), incomeB.ID, "duplicate"); err != nil {
+		t.Fatalf("unexpected error reversing: %v", err)
+	}
+
+	rebuilder := NewTaxLotRebuilder(f.lots, f.repo, newTestLogger())
+	if err := rebuilder.RebuildAccounts(context.Background(), []uuid.UUID{f.walletAcctID}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(f.lots.lots) != 1 {
+		t.Fatalf("expected only lot A after rebuild, got %d lots", len(f.lots.lots))
+	}
+	if remaining := f.lotOf(t, f.incomeA.ID).QuantityRemaining; remaining.Cmp(big.NewInt(500)) != 0 {
+		t.Errorf("expected lot A remaining 500, got %s", remaining)
+	}
+}
+
+func TestRecordTransaction_BackdatedBeforeTransfer_RebuildsReceivingLot(t *testing.T) {
+	f := newRebuildFixture(t)
+	ctx := context.Background()
+
+	// A sends 200 ETH to B after the outcome; B's lot carries A's $200 basis
+	destAcctID := uuid.New()
+	f.repo.accounts[destAcctID] = walletAccount(destAcctID)
+	transfer := completedTxAt(f.now.Add(-30*time.Minute), TxTypeInternalTransfer,
+		makeEntry(destAcctID, Debit, EntryTypeAssetIncrease, 200, "ETH", nil),
+		makeEntry(f.walletAcctID, Credit, EntryTypeAssetDecrease, 200, "ETH", nil),
+	)
+	recordDirect(t, f.svc, transfer)
+
+	// A buy at $100 is inserted before everything else
+	buy := completedTxAt(f.now.Add(-4*time.Hour), TxTypeManualIncome,
+		makeEntry(f.walletAcctID, Debit, EntryTypeAssetIncrease, 1000, "ETH", nil),
+		makeEntry(f.incomeAcctID, Credit, EntryTypeIncome, 1000, "ETH", nil),
+	)
+	buy.Entries[0].USDRate = big.NewInt(100_000_000_00)
+	recordDirect(t, f.svc, buy)
+
+	rebuilder := NewTaxLotRebuilder(f.lots, f.repo, newTestLogger())
+	if err := rebuilder.RebuildIfBackdated(ctx, buy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The outcome and the transfer now consume the backdated lot
+	buyLot := f.lotOf(t, buy.ID)
+	if buyLot.QuantityRemaining.Cmp(big.NewInt(300)) != 0 {
+		t.Errorf("expected backdated lot remaining 300, got %s", buyLot.QuantityRemaining)
+	}
+
+	received := f.lotOf(t, transfer.ID)
+	if received.AccountID != destAcctID {
+		t.Fatalf("expected transfer lot on receiving account, got %s", received.AccountID)
+	}
+	if received.AutoCostBasisPerUnit.Cmp(big.NewInt(100_000_000_00)) != 0 {
+		t.Errorf("expected carried cost basis $100, got %s", received.AutoCostBasisPerUnit)
+	}
+	if received.LinkedSourceLotID == nil || *received.LinkedSourceLotID != buyLot.ID {
+		t.Errorf("expected receiving lot linked to backdated lot %s, got %v", buyLot.ID, received.LinkedSourceLotID)
+	}
+}
@@ -0,0 +1,137 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// AccountAsset identifies a single balance: one asset held in one account.
+type AccountAsset struct {
+	AccountID uuid.UUID
+	Asset     string
+}
+
+// TaxLotReplayer rewinds FIFO lot consumption when the order of history
+// changes, e.g. when a transaction is amended to an earlier date.
+// Lots themselves are left in place (keeping IDs, overrides and transfer
+// links); only disposals dated at or after the rewind point are removed.
+// TaxLotRebuilder builds on it to recompute what was rewound.
+//
+// All methods expect to run inside the ledger DB transaction.
+type TaxLotReplayer struct {
+	repo       TaxLotRepository
+	ledgerRepo Repository
+	logger     *logger.Logger
+}
+
+// NewTaxLotReplayer creates a new tax lot replayer
+func NewTaxLotReplayer(repo TaxLotRepository, ledgerRepo Repository, log *logger.Logger) *TaxLotReplayer {
+	return &TaxLotReplayer{
+		repo:       repo,
+		ledgerRepo: ledgerRepo,
+		logger:     log.WithField("component", "taxlot_replay"),
+	}
+}
+
+// Rewind removes disposals dated at or after from on the given pairs and
+// returns the disposed quantities to their lots.
+func (r *TaxLotReplayer) Rewind(ctx context.Context, pairs []AccountAsset, from time.Time) error {
+	scope, err := r.trackedScope(ctx, pairs)
+	if err != nil {
+		return err
+	}
+	for p := range scope {
+		if err := r.rewindPair(ctx, p, from); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trackedScope keeps the pairs whose accounts hold lots (see NewTaxLotHook).
+func (r *TaxLotReplayer) trackedScope(ctx context.Context, pairs []AccountAsset) (map[AccountAsset]bool, error) {
+	tracked := make(map[uuid.UUID]bool)
+	scope := make(map[AccountAsset]bool)
+	for _, p := range uniquePairs(pairs) {
+		ok, known := tracked[p.AccountID]
+		if !known {
+			acct, err := r.ledgerRepo.GetAccount(ctx, p.AccountID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to lookup account %s for replay: %w", p.AccountID, err)
+			}
+			ok = acct.Type == AccountTypeCryptoWallet || acct.Type == AccountTypeCollateral
+			tracked[p.AccountID] = ok
+		}
+		if ok {
+			scope[p] = true
+		}
+	}
+	return scope, nil
+}
+
+func (r *TaxLotReplayer) rewindPair(ctx context.Context, p AccountAsset, from time.Time) error {
+	lots, err := r.repo.GetLotsByAccount(ctx, p.AccountID, p.Asset)
+	if err != nil {
+		return fmt.Errorf("failed to get lots for %s/%s: %w", p.AccountID, p.Asset, err)
+	}
+
+	for _, lot := range lots {
+		disposals, err := r.repo.GetDisposalsByLot(ctx, lot.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get disposals for lot %s: %w", lot.ID, err)
+		}
+
+		restored := new(big.Int)
+		for _, d := range disposals {
+			if d.DisposedAt.Before(from) {
+				continue
+			}
+			if err := r.repo.DeleteDisposal(ctx, d.ID); err != nil {
+				return err
+			}
+			restored.Add(restored, d.QuantityDisposed)
+		}
+		if restored.Sign() == 0 {
+			continue
+		}
+
+		locked, err := r.repo.GetTaxLotForUpdate(ctx, lot.ID)
+		if err != nil {
+			return fmt.Errorf("failed to lock lot %s: %w", lot.ID, err)
+		}
+		if err := r.repo.UpdateLotRemaining(ctx, lot.ID, restored.Add(restored, locked.QuantityRemaining)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// balancePairs returns the account/asset pairs whose balances the entries change.
+func balancePairs(entries []*Entry) []AccountAsset {
+	var pairs []AccountAsset
+	for _, e := range entries {
+		if entryBalanceChange(e) == nil {
+			continue
+		}
+		pairs = append(pairs, AccountAsset{AccountID: e.AccountID, Asset: e.AssetID})
+	}
+	return uniquePairs(pairs)
+}
+
+func uniquePairs(pairs []AccountAsset) []AccountAsset {
+	seen := make(map[AccountAsset]bool, len(pairs))
+	result := make([]AccountAsset, 0, len(pairs))
+	for _, p := range pairs {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		result = append(result, p)
+	}
+	return result
+}
@@ -108,6 +108,11 @@ func (m *MockLedgerRepository) ListTransactions(ctx context.Context, filters led
 	return args.Get(0).([]*ledger.Transaction), args.Error(1)
 }
 
+func (m *MockLedgerRepository) CountTransactions(ctx context.Context, filters ledger.TransactionFilters) (int, error) {
+	args := m.Called(ctx, filters)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockLedgerRepository) GetEntriesByTransaction(ctx context.Context, txID uuid.UUID) ([]*ledger.Entry, error) {
 	args := m.Called(ctx, txID)
 	if args.Get(0) == nil {
@@ -124,6 +129,16 @@ func (m *MockLedgerRepository) GetEntriesByAccount(ctx context.Context, accountI
 	return args.Get(0).([]*ledger.Entry), args.Error(1)
 }
 
+func (m *MockLedgerRepository) HasEntriesAfter(ctx context.Context, accountID uuid.UUID, assetID string, after time.Time) (bool, error) {
+	args := m.Called(ctx, accountID, assetID, after)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLedgerRepository) MoveTransactionAnnotations(ctx context.Context, fromID, toID uuid.UUID) error {
+	args := m.Called(ctx, fromID, toID)
+	return args.Error(0)
+}
+
 func (m *MockLedgerRepository) UpsertAccountBalance(ctx context.Context, balance *ledger.AccountBalance) error {
 	args := m.Called(ctx, balance)
 	return args.Error(0)
@@ -12,5 +12,5 @@ var (
 	ErrInvalidAssetID  = errors.New("invalid asset symbol")
 	ErrInvalidDecimals = errors.New("invalid decimals: must be non-negative")
 	ErrWalletNotFound  = errors.New("wallet not found")
-	ErrUnauthorized    = errors.New("unauthorized: wallet does not belong to user")
+	ErrUnauthorized    = errors.New("unauthorized: user cannot record transactions on wallet")
 )
@@ -10,7 +10,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
-	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 	"github.com/kislikjeka/moontrack/pkg/money"
 )
@@ -20,14 +20,16 @@ import (
 type DeFiClaimHandler struct {
 	ledger.BaseHandler
 	walletRepo WalletRepository
+	access     wallet.AccessChecker
 	logger     *logger.Logger
 }
 
 // NewDeFiClaimHandler creates a new DeFi claim handler
-func NewDeFiClaimHandler(walletRepo WalletRepository, log *logger.Logger) *DeFiClaimHandler {
+func NewDeFiClaimHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *DeFiClaimHandler {
 	return &DeFiClaimHandler{
 		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeDefiClaim),
 		walletRepo:  walletRepo,
+		access:      access,
 		logger:      log.WithField("component", "defi_claim"),
 	}
 }
@@ -83,10 +85,8 @@ func (h *DeFiClaimHandler) ValidateData(ctx context.Context, data map[string]int
 		return ErrWalletNotFound
 	}
 
-	if userID, ok := middleware.GetUserIDFromContext(ctx); ok && userID != uuid.Nil {
-		if w.UserID != userID {
-			return ErrUnauthorized
-		}
+	if err := authorizeWallet(ctx, h.access, w); err != nil {
+		return err
 	}
 
 	return nil
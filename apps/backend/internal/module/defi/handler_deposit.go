@@ -3,12 +3,14 @@ package defi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
 	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
 	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
@@ -24,14 +26,16 @@ type WalletRepository interface {
 type DeFiDepositHandler struct {
 	ledger.BaseHandler
 	walletRepo WalletRepository
+	access     wallet.AccessChecker
 	logger     *logger.Logger
 }
 
 // NewDeFiDepositHandler creates a new DeFi deposit handler
-func NewDeFiDepositHandler(walletRepo WalletRepository, log *logger.Logger) *DeFiDepositHandler {
+func NewDeFiDepositHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *DeFiDepositHandler {
 	return &DeFiDepositHandler{
 		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeDefiDeposit),
 		walletRepo:  walletRepo,
+		access:      access,
 		logger:      log.WithField("component", "defi_deposit"),
 	}
 }
@@ -86,10 +90,8 @@ func (h *DeFiDepositHandler) ValidateData(ctx context.Context, data map[string]i
 		return ErrWalletNotFound
 	}
 
-	if userID, ok := middleware.GetUserIDFromContext(ctx); ok && userID != uuid.Nil {
-		if w.UserID != userID {
-			return ErrUnauthorized
-		}
+	if err := authorizeWallet(ctx, h.access, w); err != nil {
+		return err
 	}
 
 	return nil
@@ -108,3 +110,19 @@ func (h *DeFiDepositHandler) GenerateEntries(ctx context.Context, txn *DeFiTrans
 
 	return entries, nil
 }
+
+// authorizeWallet checks that the authenticated user, if any, holds the
+// editor role in the wallet's workspace.
+func authorizeWallet(ctx context.Context, access wallet.AccessChecker, w *wallet.Wallet) error {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok || userID == uuid.Nil {
+		return nil
+	}
+	if err := wallet.CheckAccess(ctx, access, w, userID, workspace.RoleEditor); err != nil {
+		if errors.Is(err, wallet.ErrUnauthorizedAccess) {
+			return ErrUnauthorized
+		}
+		return err
+	}
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/kislikjeka/moontrack/internal/ledger"
 	"github.com/kislikjeka/moontrack/internal/module/defi"
 	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
 	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 	"github.com/kislikjeka/moontrack/pkg/money"
@@ -32,6 +33,15 @@ func (m *MockWalletRepository) GetByID(ctx context.Context, walletID uuid.UUID)
 	return args.Get(0).(*wallet.Wallet), args.Error(1)
 }
 
+// MockAccessChecker is a mock implementation of wallet.AccessChecker
+type MockAccessChecker struct {
+	mock.Mock
+}
+
+func (m *MockAccessChecker) Authorize(ctx context.Context, workspaceID, userID uuid.UUID, required workspace.Role) error {
+	return m.Called(ctx, workspaceID, userID, required).Error(0)
+}
+
 // =============================================================================
 // Helper: verify entries balance (SUM debit == SUM credit)
 // =============================================================================
@@ -64,7 +74,7 @@ func testWallet(walletID, userID uuid.UUID) *wallet.Wallet {
 // =============================================================================
 
 func TestDeFiDepositHandler_Type(t *testing.T) {
-	handler := defi.NewDeFiDepositHandler(nil, logger.NewDefault("test"))
+	handler := defi.NewDeFiDepositHandler(nil, nil, logger.NewDefault("test"))
 	assert.Equal(t, ledger.TxTypeDefiDeposit, handler.Type())
 }
 
@@ -76,7 +86,7 @@ func TestDeFiDepositHandler_SimpleDeposit_Balance(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, walletID).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiDepositHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiDepositHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	// AAVE deposit: cbBTC out, aBascbBTC in
 	data := map[string]interface{}{
@@ -139,7 +149,7 @@ func TestDeFiDepositHandler_WithGasFee_Balance(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, walletID).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiDepositHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiDepositHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	data := map[string]interface{}{
 		"wallet_id":      walletID.String(),
@@ -201,7 +211,7 @@ func TestDeFiDepositHandler_MintOnly_Balance(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, walletID).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiDepositHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiDepositHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	// GMX mint: IN only, no OUT transfers
 	data := map[string]interface{}{
@@ -247,7 +257,7 @@ func TestDeFiDepositHandler_USDPriceFallback(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, walletID).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiDepositHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiDepositHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	// AAVE deposit with OUT having a price, IN having usd_price=0
 	// cbBTC: amount=1000000 (0.01 BTC), decimals=8, price=$67000 = 6700000000000 (scaled 1e8)
@@ -310,7 +320,7 @@ func TestDeFiDepositHandler_EntryMetadata(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, walletID).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiDepositHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiDepositHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	data := map[string]interface{}{
 		"wallet_id":      walletID.String(),
@@ -375,7 +385,7 @@ func TestDeFiDepositHandler_Validate_MissingFields(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID")).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiDepositHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiDepositHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	testCases := []struct {
 		name        string
@@ -460,7 +470,7 @@ func TestDeFiDepositHandler_Validate_WalletNotFound(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, walletID).Return(nil, nil)
 
-	handler := defi.NewDeFiDepositHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiDepositHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	data := validDepositData(walletID)
 	err := handler.ValidateData(ctx, data)
@@ -472,7 +482,7 @@ func TestDeFiDepositHandler_Validate_WalletNotFound(t *testing.T) {
 // =============================================================================
 
 func TestDeFiWithdrawHandler_Type(t *testing.T) {
-	handler := defi.NewDeFiWithdrawHandler(nil, logger.NewDefault("test"))
+	handler := defi.NewDeFiWithdrawHandler(nil, nil, logger.NewDefault("test"))
 	assert.Equal(t, ledger.TxTypeDefiWithdraw, handler.Type())
 }
 
@@ -484,7 +494,7 @@ func TestDeFiWithdrawHandler_SimpleWithdraw_Balance(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, walletID).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiWithdrawHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiWithdrawHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	// Flux Finance withdraw: fUSDC out, USDC in
 	data := map[string]interface{}{
@@ -543,7 +553,7 @@ func TestDeFiWithdrawHandler_WithGasFee_Balance(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, walletID).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiWithdrawHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiWithdrawHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	data := map[string]interface{}{
 		"wallet_id":      walletID.String(),
@@ -588,7 +598,7 @@ func TestDeFiWithdrawHandler_Validate_MissingFields(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID")).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiWithdrawHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiWithdrawHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	testCases := []struct {
 		name        string
@@ -630,7 +640,7 @@ func TestDeFiWithdrawHandler_EntryMetadata(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, walletID).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiWithdrawHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiWithdrawHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	data := map[string]interface{}{
 		"wallet_id":      walletID.String(),
@@ -673,7 +683,7 @@ func TestDeFiWithdrawHandler_EntryMetadata(t *testing.T) {
 // =============================================================================
 
 func TestDeFiClaimHandler_Type(t *testing.T) {
-	handler := defi.NewDeFiClaimHandler(nil, logger.NewDefault("test"))
+	handler := defi.NewDeFiClaimHandler(nil, nil, logger.NewDefault("test"))
 	assert.Equal(t, ledger.TxTypeDefiClaim, handler.Type())
 }
 
@@ -685,7 +695,7 @@ func TestDeFiClaimHandler_SimpleClaim_Balance(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, walletID).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiClaimHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiClaimHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	data := map[string]interface{}{
 		"wallet_id":      walletID.String(),
@@ -734,7 +744,7 @@ func TestDeFiClaimHandler_WithGasFee_Balance(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, walletID).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiClaimHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiClaimHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	data := map[string]interface{}{
 		"wallet_id":      walletID.String(),
@@ -772,7 +782,7 @@ func TestDeFiClaimHandler_MultipleRewards_Balance(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, walletID).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiClaimHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiClaimHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	data := map[string]interface{}{
 		"wallet_id":      walletID.String(),
@@ -824,7 +834,7 @@ func TestDeFiClaimHandler_Validate_NoInTransfers(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID")).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiClaimHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiClaimHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	// Claim with only OUT transfers (invalid)
 	data := map[string]interface{}{
@@ -856,7 +866,7 @@ func TestDeFiClaimHandler_EntryMetadata(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, walletID).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiClaimHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiClaimHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	data := map[string]interface{}{
 		"wallet_id":      walletID.String(),
@@ -910,7 +920,7 @@ func TestDeFiDepositHandler_USDPriceFallback_DifferentAmounts(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, walletID).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiDepositHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiDepositHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	// AAVE deposit with different IN and OUT amounts (realistic scenario)
 	// OUT: cbBTC amount=981547, decimals=8, usd_price=6795302440668
@@ -982,7 +992,7 @@ func TestDeFiDepositHandler_Validate_ZeroAmount(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID")).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiDepositHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiDepositHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	data := validDepositData(walletID)
 	data["transfers"] = []map[string]interface{}{
@@ -1011,7 +1021,7 @@ func TestDeFiDepositHandler_Validate_NegativeDecimals(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID")).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiDepositHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiDepositHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	data := validDepositData(walletID)
 	data["transfers"] = []map[string]interface{}{
@@ -1039,27 +1049,27 @@ func TestDeFiHandlers_Unauthorized(t *testing.T) {
 
 	testCases := []struct {
 		name        string
-		newHandler  func(defi.WalletRepository, *logger.Logger) interface{ ValidateData(context.Context, map[string]interface{}) error }
+		newHandler  func(defi.WalletRepository, wallet.AccessChecker, *logger.Logger) interface{ ValidateData(context.Context, map[string]interface{}) error }
 		dataFunc    func(uuid.UUID) map[string]interface{}
 	}{
 		{
 			name: "deposit handler",
-			newHandler: func(repo defi.WalletRepository, log *logger.Logger) interface{ ValidateData(context.Context, map[string]interface{}) error } {
-				return defi.NewDeFiDepositHandler(repo, log)
+			newHandler: func(repo defi.WalletRepository, access wallet.AccessChecker, log *logger.Logger) interface{ ValidateData(context.Context, map[string]interface{}) error } {
+				return defi.NewDeFiDepositHandler(repo, access, log)
 			},
 			dataFunc: validDepositData,
 		},
 		{
 			name: "withdraw handler",
-			newHandler: func(repo defi.WalletRepository, log *logger.Logger) interface{ ValidateData(context.Context, map[string]interface{}) error } {
-				return defi.NewDeFiWithdrawHandler(repo, log)
+			newHandler: func(repo defi.WalletRepository, access wallet.AccessChecker, log *logger.Logger) interface{ ValidateData(context.Context, map[string]interface{}) error } {
+				return defi.NewDeFiWithdrawHandler(repo, access, log)
 			},
 			dataFunc: validWithdrawData,
 		},
 		{
 			name: "claim handler",
-			newHandler: func(repo defi.WalletRepository, log *logger.Logger) interface{ ValidateData(context.Context, map[string]interface{}) error } {
-				return defi.NewDeFiClaimHandler(repo, log)
+			newHandler: func(repo defi.WalletRepository, access wallet.AccessChecker, log *logger.Logger) interface{ ValidateData(context.Context, map[string]interface{}) error } {
+				return defi.NewDeFiClaimHandler(repo, access, log)
 			},
 			dataFunc: validClaimData,
 		},
@@ -1073,7 +1083,10 @@ func TestDeFiHandlers_Unauthorized(t *testing.T) {
 			walletRepo := new(MockWalletRepository)
 			walletRepo.On("GetByID", mock.Anything, walletID).Return(w, nil)
 
-			handler := tc.newHandler(walletRepo, logger.NewDefault("test"))
+			access := new(MockAccessChecker)
+			access.On("Authorize", mock.Anything, w.WorkspaceID, attackerID, workspace.RoleEditor).Return(workspace.ErrNotMember)
+
+			handler := tc.newHandler(walletRepo, access, logger.NewDefault("test"))
 
 			// Context has attackerID (different user)
 			ctx := context.WithValue(context.Background(), middleware.UserIDKey, attackerID)
@@ -1097,7 +1110,7 @@ func TestDeFiDepositHandler_MultiAsset_Balance(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, walletID).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiDepositHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiDepositHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	// Multi-asset deposit: 2 OUT + 2 IN
 	data := map[string]interface{}{
@@ -1192,7 +1205,7 @@ func TestDeFiWithdrawHandler_Validate_ExpandedFields(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID")).Return(testWallet(walletID, userID), nil)
 
-	handler := defi.NewDeFiWithdrawHandler(walletRepo, logger.NewDefault("test"))
+	handler := defi.NewDeFiWithdrawHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	testCases := []struct {
 		name        string
@@ -5,10 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/google/uuid"
-
 	"github.com/kislikjeka/moontrack/internal/ledger"
-	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
 
@@ -17,14 +15,16 @@ import (
 type DeFiWithdrawHandler struct {
 	ledger.BaseHandler
 	walletRepo WalletRepository
+	access     wallet.AccessChecker
 	logger     *logger.Logger
 }
 
 // NewDeFiWithdrawHandler creates a new DeFi withdraw handler
-func NewDeFiWithdrawHandler(walletRepo WalletRepository, log *logger.Logger) *DeFiWithdrawHandler {
+func NewDeFiWithdrawHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *DeFiWithdrawHandler {
 	return &DeFiWithdrawHandler{
 		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeDefiWithdraw),
 		walletRepo:  walletRepo,
+		access:      access,
 		logger:      log.WithField("component", "defi_withdraw"),
 	}
 }
@@ -79,10 +79,8 @@ func (h *DeFiWithdrawHandler) ValidateData(ctx context.Context, data map[string]
 		return ErrWalletNotFound
 	}
 
-	if userID, ok := middleware.GetUserIDFromContext(ctx); ok && userID != uuid.Nil {
-		if w.UserID != userID {
-			return ErrUnauthorized
-		}
+	if err := authorizeWallet(ctx, h.access, w); err != nil {
+		return err
 	}
 
 	return nil
@@ -0,0 +1,247 @@
+package derivatives
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// generateOpenEntries generates entries for opening or adding to a position:
+// wallet → margin.
+//
+//	DEBIT  margin.{protocol}.{wID}.{chain}.{asset}  (collateral_increase)
+//	CREDIT wallet.{wID}.{chain}.{asset}             (asset_decrease)
+func generateOpenEntries(txn *DerivativeTransaction) []*ledger.Entry {
+	amount := txn.Amount.ToBigInt()
+	usdRate, usdValue := calcUSD(txn, amount)
+
+	return []*ledger.Entry{
+		newEntry(txn, ledger.Debit, ledger.EntryTypeCollateralIncrease, amount, usdRate, usdValue, marginMeta(txn, true)),
+		newEntry(txn, ledger.Credit, ledger.EntryTypeAssetDecrease, amount, usdRate, usdValue, walletMeta(txn, true)),
+	}
+}
+
+// generateCloseEntries generates entries for closing a position: the margin
+// released goes back to the wallet, and what the wallet received above or
+// below it is the realized PnL.
+//
+//	CREDIT margin.{protocol}.{wID}.{chain}.{asset}  (collateral_decrease, released)
+//	DEBIT  wallet.{wID}.{chain}.{asset}             (asset_increase, margin returned)
+//	DEBIT  wallet.{wID}.{chain}.{asset}             (asset_increase, profit)
+//	CREDIT income.derivatives.{chain}.{asset}       (income, profit)
+//	DEBIT  expense.derivatives.{chain}.{asset}      (expense, loss)
+//
+// The profit is booked as a separate acquisition so the margin returned keeps
+// its cost basis and the profit is acquired at market price.
+func generateCloseEntries(txn *DerivativeClose) []*ledger.Entry {
+	received := amountOrZero(txn.Amount)
+	released := amountOrZero(txn.MarginAmount)
+	returned := received
+	if released.Cmp(returned) < 0 {
+		returned = released
+	}
+
+	var entries []*ledger.Entry
+	if released.Sign() > 0 {
+		rate, value := calcUSD(&txn.DerivativeTransaction, released)
+		entries = append(entries, newEntry(&txn.DerivativeTransaction, ledger.Credit, ledger.EntryTypeCollateralDecrease, released, rate, value, marginMeta(&txn.DerivativeTransaction, true)))
+	}
+	if returned.Sign() > 0 {
+		rate, value := calcUSD(&txn.DerivativeTransaction, returned)
+		entries = append(entries, newEntry(&txn.DerivativeTransaction, ledger.Debit, ledger.EntryTypeAssetIncrease, returned, rate, value, walletMeta(&txn.DerivativeTransaction, true)))
+	}
+
+	switch pnl := new(big.Int).Sub(received, released); pnl.Sign() {
+	case 1:
+		rate, value := calcUSD(&txn.DerivativeTransaction, pnl)
+		entries = append(entries,
+			newEntry(&txn.DerivativeTransaction, ledger.Debit, ledger.EntryTypeAssetIncrease, pnl, rate, value, walletMeta(&txn.DerivativeTransaction, false)),
+			newEntry(&txn.DerivativeTransaction, ledger.Credit, ledger.EntryTypeIncome, pnl, rate, value, map[string]interface{}{
+				"account_code": fmt.Sprintf("income.derivatives.%s.%s", txn.ChainID, txn.Asset),
+			}),
+		)
+	case -1:
+		loss := pnl.Neg(pnl)
+		rate, value := calcUSD(&txn.DerivativeTransaction, loss)
+		entries = append(entries, newEntry(&txn.DerivativeTransaction, ledger.Debit, ledger.EntryTypeExpense, loss, rate, value, map[string]interface{}{
+			"account_code": fmt.Sprintf("expense.derivatives.%s.%s", txn.ChainID, txn.Asset),
+		}))
+	}
+	return entries
+}
+
+// generateFundingEntries generates entries for a funding payment settled
+// against the margin, kept apart from trading PnL.
+//
+// Received:
+//
+//	DEBIT  margin.{protocol}.{wID}.{chain}.{asset}  (collateral_increase)
+//	CREDIT income.funding.{chain}.{asset}           (income)
+//
+// Paid:
+//
+//	DEBIT  expense.funding.{chain}.{asset}          (expense)
+//	CREDIT margin.{protocol}.{wID}.{chain}.{asset}  (collateral_decrease)
+//
+// Claimed funding debits wallet.{wID}.{chain}.{asset} instead of the margin.
+func generateFundingEntries(txn *DerivativeFunding) []*ledger.Entry {
+	amount := txn.Amount.ToBigInt()
+	usdRate, usdValue := calcUSD(&txn.DerivativeTransaction, amount)
+	if txn.Claimed {
+		return []*ledger.Entry{
+			newEntry(&txn.DerivativeTransaction, ledger.Debit, ledger.EntryTypeAssetIncrease, amount, usdRate, usdValue, walletMeta(&txn.DerivativeTransaction, false)),
+			newEntry(&txn.DerivativeTransaction, ledger.Credit, ledger.EntryTypeIncome, amount, usdRate, usdValue, map[string]interface{}{
+				"account_code": fmt.Sprintf("income.funding.%s.%s", txn.ChainID, txn.Asset),
+			}),
+		}
+	}
+
+	margin := marginMeta(&txn.DerivativeTransaction, false)
+	if txn.Paid {
+		return []*ledger.Entry{
+			newEntry(&txn.DerivativeTransaction, ledger.Debit, ledger.EntryTypeExpense, amount, usdRate, usdValue, map[string]interface{}{
+				"account_code": fmt.Sprintf("expense.funding.%s.%s", txn.ChainID, txn.Asset),
+			}),
+			newEntry(&txn.DerivativeTransaction, ledger.Credit, ledger.EntryTypeCollateralDecrease, amount, usdRate, usdValue, margin),
+		}
+	}
+	return []*ledger.Entry{
+		newEntry(&txn.DerivativeTransaction, ledger.Debit, ledger.EntryTypeCollateralIncrease, amount, usdRate, usdValue, margin),
+		newEntry(&txn.DerivativeTransaction, ledger.Credit, ledger.EntryTypeIncome, amount, usdRate, usdValue, map[string]interface{}{
+			"account_code": fmt.Sprintf("income.funding.%s.%s", txn.ChainID, txn.Asset),
+		}),
+	}
+}
+
+// generateGasFeeEntries generates gas fee entries if the transaction has a fee.
+//
+//	DEBIT  gas.{chain}.{feeAsset}          (gas_fee)
+//	CREDIT wallet.{wID}.{chain}.{feeAsset} (asset_decrease)
+func generateGasFeeEntries(txn *DerivativeTransaction) []*ledger.Entry {
+	if !txn.FeeAmount.IsPositive() {
+		return nil
+	}
+
+	feeAmount := txn.FeeAmount.ToBigInt()
+	feeUSDRate := big.NewInt(0)
+	if txn.FeeUSDPrice != nil && !txn.FeeUSDPrice.IsNil() {
+		feeUSDRate = txn.FeeUSDPrice.ToBigInt()
+	}
+	feeDecimals := txn.FeeDecimals
+	if feeDecimals == 0 {
+		feeDecimals = 18
+	}
+	feeUSDValue := money.CalcUSDValue(feeAmount, feeUSDRate, feeDecimals)
+
+	walletID := txn.WalletID.String()
+	chain := txn.ChainID
+	feeAsset := txn.FeeAsset
+
+	return []*ledger.Entry{
+		{
+			ID:          uuid.New(),
+			DebitCredit: ledger.Debit,
+			EntryType:   ledger.EntryTypeGasFee,
+			Amount:      new(big.Int).Set(feeAmount),
+			AssetID:     feeAsset,
+			USDRate:     new(big.Int).Set(feeUSDRate),
+			USDValue:    new(big.Int).Set(feeUSDValue),
+			OccurredAt:  txn.OccurredAt,
+			CreatedAt:   time.Now().UTC(),
+			Metadata: map[string]interface{}{
+				"account_code": fmt.Sprintf("gas.%s.%s", chain, feeAsset),
+				"tx_hash":      txn.TxHash,
+				"chain_id":     chain,
+			},
+		},
+		{
+			ID:          uuid.New(),
+			DebitCredit: ledger.Credit,
+			EntryType:   ledger.EntryTypeAssetDecrease,
+			Amount:      new(big.Int).Set(feeAmount),
+			AssetID:     feeAsset,
+			USDRate:     new(big.Int).Set(feeUSDRate),
+			USDValue:    new(big.Int).Set(feeUSDValue),
+			OccurredAt:  txn.OccurredAt,
+			CreatedAt:   time.Now().UTC(),
+			Metadata: map[string]interface{}{
+				"wallet_id":    walletID,
+				"account_code": fmt.Sprintf("wallet.%s.%s.%s", walletID, chain, feeAsset),
+				"tx_hash":      txn.TxHash,
+				"chain_id":     chain,
+				"entry_type":   "gas_payment",
+			},
+		},
+	}
+}
+
+func newEntry(txn *DerivativeTransaction, dc ledger.DebitCredit, et ledger.EntryType, amount, usdRate, usdValue *big.Int, meta map[string]interface{}) *ledger.Entry {
+	meta["tx_hash"] = txn.TxHash
+	meta["chain_id"] = txn.ChainID
+	meta["protocol"] = txn.Protocol
+	if txn.Market != "" {
+		meta["market"] = txn.Market
+	}
+	return &ledger.Entry{
+		ID:          uuid.New(),
+		DebitCredit: dc,
+		EntryType:   et,
+		Amount:      new(big.Int).Set(amount),
+		AssetID:     txn.Asset,
+		USDRate:     new(big.Int).Set(usdRate),
+		USDValue:    new(big.Int).Set(usdValue),
+		OccurredAt:  txn.OccurredAt,
+		CreatedAt:   time.Now().UTC(),
+		Metadata:    meta,
+	}
+}
+
+// marginMeta returns the metadata of the wallet's margin account on the
+// protocol. transfer marks margin moving to or from the wallet, so the tax
+// lot hook carries its cost basis over.
+func marginMeta(txn *DerivativeTransaction, transfer bool) map[string]interface{} {
+	walletID := txn.WalletID.String()
+	meta := map[string]interface{}{
+		"wallet_id":        walletID,
+		"account_code":     fmt.Sprintf("margin.%s.%s.%s.%s", txn.Protocol, walletID, txn.ChainID, txn.Asset),
+		"account_type":     "COLLATERAL",
+		"contract_address": txn.ContractAddress,
+	}
+	if transfer {
+		meta["entry_type"] = "margin_transfer"
+	}
+	return meta
+}
+
+func walletMeta(txn *DerivativeTransaction, transfer bool) map[string]interface{} {
+	walletID := txn.WalletID.String()
+	meta := map[string]interface{}{
+		"wallet_id":    walletID,
+		"account_code": fmt.Sprintf("wallet.%s.%s.%s", walletID, txn.ChainID, txn.Asset),
+	}
+	if transfer {
+		meta["entry_type"] = "margin_transfer"
+	}
+	return meta
+}
+
+// calcUSD returns the USD rate of the margin asset and the USD value of amount.
+func calcUSD(txn *DerivativeTransaction, amount *big.Int) (*big.Int, *big.Int) {
+	usdRate := big.NewInt(0)
+	if txn.USDPrice != nil && !txn.USDPrice.IsNil() {
+		usdRate = txn.USDPrice.ToBigInt()
+	}
+	return usdRate, money.CalcUSDValue(amount, usdRate, txn.Decimals)
+}
+
+func amountOrZero(v *money.BigInt) *big.Int {
+	if v.IsNil() {
+		return big.NewInt(0)
+	}
+	return v.ToBigInt()
+}
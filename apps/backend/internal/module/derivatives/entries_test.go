@@ -0,0 +1,193 @@
+package derivatives
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+func assertEntriesBalanced(t *testing.T, entries []*ledger.Entry) {
+	t.Helper()
+	debitSum := new(big.Int)
+	creditSum := new(big.Int)
+	for _, e := range entries {
+		if e.DebitCredit == ledger.Debit {
+			debitSum.Add(debitSum, e.Amount)
+		} else {
+			creditSum.Add(creditSum, e.Amount)
+		}
+	}
+	assert.Equal(t, 0, debitSum.Cmp(creditSum),
+		"entries must balance: debits=%s credits=%s", debitSum.String(), creditSum.String())
+}
+
+func baseTxn() *DerivativeTransaction {
+	return &DerivativeTransaction{
+		WalletID:        uuid.New(),
+		TxHash:          "0xabc123",
+		ChainID:         "arbitrum",
+		OccurredAt:      time.Now().UTC(),
+		Protocol:        "GMX V2",
+		Market:          "ETH-USD",
+		Asset:           "USDC",
+		Amount:          money.NewBigInt(big.NewInt(1_000_000_000)), // 1000 USDC
+		Decimals:        6,
+		USDPrice:        money.NewBigInt(big.NewInt(100_000_000)), // $1 scaled 10^8
+		ContractAddress: "0xusdc",
+	}
+}
+
+func closeTxn(received, released int64) *DerivativeClose {
+	txn := &DerivativeClose{DerivativeTransaction: *baseTxn()}
+	txn.Amount = money.NewBigInt(big.NewInt(received))
+	txn.MarginAmount = money.NewBigInt(big.NewInt(released))
+	return txn
+}
+
+func TestGenerateOpenEntries(t *testing.T) {
+	txn := baseTxn()
+	entries := generateOpenEntries(txn)
+
+	require.Len(t, entries, 2)
+	assertEntriesBalanced(t, entries)
+
+	assert.Equal(t, ledger.Debit, entries[0].DebitCredit)
+	assert.Equal(t, ledger.EntryTypeCollateralIncrease, entries[0].EntryType)
+	assert.Equal(t, "margin.GMX V2."+txn.WalletID.String()+".arbitrum.USDC", entries[0].Metadata["account_code"])
+	assert.Equal(t, "COLLATERAL", entries[0].Metadata["account_type"])
+	assert.Equal(t, "margin_transfer", entries[0].Metadata["entry_type"])
+	assert.Equal(t, "ETH-USD", entries[0].Metadata["market"])
+
+	assert.Equal(t, ledger.Credit, entries[1].DebitCredit)
+	assert.Equal(t, ledger.EntryTypeAssetDecrease, entries[1].EntryType)
+	assert.Contains(t, entries[1].Metadata["account_code"], "wallet.")
+	assert.Equal(t, "margin_transfer", entries[1].Metadata["entry_type"])
+	assert.Equal(t, 0, entries[1].USDValue.Cmp(big.NewInt(100_000_000_000)))
+}
+
+func TestGenerateCloseEntries_Profit(t *testing.T) {
+	entries := generateCloseEntries(closeTxn(1_250_000_000, 1_000_000_000))
+
+	require.Len(t, entries, 4)
+	assertEntriesBalanced(t, entries)
+
+	assert.Equal(t, ledger.EntryTypeCollateralDecrease, entries[0].EntryType)
+	assert.Equal(t, 0, entries[0].Amount.Cmp(big.NewInt(1_000_000_000)))
+
+	// Margin returned carries its cost basis over
+	assert.Equal(t, ledger.EntryTypeAssetIncrease, entries[1].EntryType)
+	assert.Equal(t, 0, entries[1].Amount.Cmp(big.NewInt(1_000_000_000)))
+	assert.Equal(t, "margin_transfer", entries[1].Metadata["entry_type"])
+
+	// Profit is acquired at market price
+	assert.Equal(t, ledger.EntryTypeAssetIncrease, entries[2].EntryType)
+	assert.Equal(t, 0, entries[2].Amount.Cmp(big.NewInt(250_000_000)))
+	assert.Nil(t, entries[2].Metadata["entry_type"])
+
+	assert.Equal(t, ledger.EntryTypeIncome, entries[3].EntryType)
+	assert.Equal(t, "income.derivatives.arbitrum.USDC", entries[3].Metadata["account_code"])
+	assert.Equal(t, 0, entries[3].USDValue.Cmp(big.NewInt(25_000_000_000)))
+}
+
+func TestGenerateCloseEntries_Loss(t *testing.T) {
+	entries := generateCloseEntries(closeTxn(700_000_000, 1_000_000_000))
+
+	require.Len(t, entries, 3)
+	assertEntriesBalanced(t, entries)
+
+	assert.Equal(t, ledger.EntryTypeCollateralDecrease, entries[0].EntryType)
+	assert.Equal(t, 0, entries[0].Amount.Cmp(big.NewInt(1_000_000_000)))
+	assert.Equal(t, ledger.EntryTypeAssetIncrease, entries[1].EntryType)
+	assert.Equal(t, 0, entries[1].Amount.Cmp(big.NewInt(700_000_000)))
+
+	assert.Equal(t, ledger.EntryTypeExpense, entries[2].EntryType)
+	assert.Equal(t, "expense.derivatives.arbitrum.USDC", entries[2].Metadata["account_code"])
+	assert.Equal(t, 0, entries[2].Amount.Cmp(big.NewInt(300_000_000)))
+}
+
+func TestGenerateCloseEntries_TotalLoss(t *testing.T) {
+	entries := generateCloseEntries(closeTxn(0, 1_000_000_000))
+
+	require.Len(t, entries, 2)
+	assertEntriesBalanced(t, entries)
+	assert.Equal(t, ledger.EntryTypeCollateralDecrease, entries[0].EntryType)
+	assert.Equal(t, ledger.EntryTypeExpense, entries[1].EntryType)
+	assert.Equal(t, 0, entries[1].Amount.Cmp(big.NewInt(1_000_000_000)))
+}
+
+func TestGenerateCloseEntries_Breakeven(t *testing.T) {
+	entries := generateCloseEntries(closeTxn(1_000_000_000, 1_000_000_000))
+
+	require.Len(t, entries, 2)
+	assertEntriesBalanced(t, entries)
+	assert.Equal(t, ledger.EntryTypeCollateralDecrease, entries[0].EntryType)
+	assert.Equal(t, ledger.EntryTypeAssetIncrease, entries[1].EntryType)
+}
+
+func TestGenerateFundingEntries_Received(t *testing.T) {
+	txn := &DerivativeFunding{DerivativeTransaction: *baseTxn()}
+	entries := generateFundingEntries(txn)
+
+	require.Len(t, entries, 2)
+	assertEntriesBalanced(t, entries)
+
+	assert.Equal(t, ledger.Debit, entries[0].DebitCredit)
+	assert.Equal(t, ledger.EntryTypeCollateralIncrease, entries[0].EntryType)
+	assert.Contains(t, entries[0].Metadata["account_code"], "margin.")
+	assert.Nil(t, entries[0].Metadata["entry_type"])
+
+	assert.Equal(t, ledger.Credit, entries[1].DebitCredit)
+	assert.Equal(t, ledger.EntryTypeIncome, entries[1].EntryType)
+	assert.Equal(t, "income.funding.arbitrum.USDC", entries[1].Metadata["account_code"])
+}
+
+func TestGenerateFundingEntries_Paid(t *testing.T) {
+	txn := &DerivativeFunding{DerivativeTransaction: *baseTxn(), Paid: true}
+	entries := generateFundingEntries(txn)
+
+	require.Len(t, entries, 2)
+	assertEntriesBalanced(t, entries)
+
+	assert.Equal(t, ledger.Debit, entries[0].DebitCredit)
+	assert.Equal(t, ledger.EntryTypeExpense, entries[0].EntryType)
+	assert.Equal(t, "expense.funding.arbitrum.USDC", entries[0].Metadata["account_code"])
+
+	assert.Equal(t, ledger.Credit, entries[1].DebitCredit)
+	assert.Equal(t, ledger.EntryTypeCollateralDecrease, entries[1].EntryType)
+	assert.Contains(t, entries[1].Metadata["account_code"], "margin.")
+}
+
+func TestGenerateFundingEntries_Claimed(t *testing.T) {
+	txn := &DerivativeFunding{DerivativeTransaction: *baseTxn(), Claimed: true}
+	entries := generateFundingEntries(txn)
+
+	require.Len(t, entries, 2)
+	assertEntriesBalanced(t, entries)
+
+	assert.Equal(t, ledger.EntryTypeAssetIncrease, entries[0].EntryType)
+	assert.Contains(t, entries[0].Metadata["account_code"], "wallet.")
+	assert.Equal(t, ledger.EntryTypeIncome, entries[1].EntryType)
+	assert.Equal(t, "income.funding.arbitrum.USDC", entries[1].Metadata["account_code"])
+
+	txn.Paid = true
+	assert.ErrorIs(t, txn.Validate(), ErrClaimedFundingPaid)
+}
+
+func TestDerivativeClose_Validate(t *testing.T) {
+	assert.NoError(t, closeTxn(0, 1).Validate())
+	assert.NoError(t, closeTxn(1, 0).Validate())
+	assert.ErrorIs(t, closeTxn(0, 0).Validate(), ErrEmptyClose)
+	assert.ErrorIs(t, closeTxn(-1, 1).Validate(), ErrInvalidAmount)
+	assert.ErrorIs(t, closeTxn(1, -1).Validate(), ErrInvalidMarginAmount)
+
+	txn := closeTxn(1, 1)
+	txn.Protocol = ""
+	assert.ErrorIs(t, txn.Validate(), ErrInvalidProtocol)
+}
@@ -0,0 +1,18 @@
+package derivatives
+
+import "errors"
+
+var (
+	ErrInvalidWalletID     = errors.New("invalid wallet ID")
+	ErrInvalidChainID      = errors.New("invalid chain ID")
+	ErrInvalidTxHash       = errors.New("invalid transaction hash")
+	ErrInvalidProtocol     = errors.New("invalid protocol: must not be empty")
+	ErrInvalidAsset        = errors.New("invalid asset: must not be empty")
+	ErrInvalidAmount       = errors.New("invalid amount: must be positive")
+	ErrInvalidMarginAmount = errors.New("invalid margin amount: must not be negative")
+	ErrEmptyClose          = errors.New("invalid close: amount received or margin released must be positive")
+	ErrClaimedFundingPaid  = errors.New("invalid funding: only funding received can be claimed")
+	ErrInvalidDecimals     = errors.New("invalid decimals: must be positive")
+	ErrWalletNotFound      = errors.New("wallet not found")
+	ErrUnauthorized        = errors.New("unauthorized: user cannot record transactions on wallet")
+)
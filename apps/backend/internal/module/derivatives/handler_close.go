@@ -0,0 +1,58 @@
+package derivatives
+
+import (
+	"context"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// DerivativeCloseHandler handles perp positions being closed, booking the realized PnL.
+type DerivativeCloseHandler struct {
+	ledger.BaseHandler
+	walletRepo WalletRepository
+	access     wallet.AccessChecker
+	logger     *logger.Logger
+}
+
+func NewDerivativeCloseHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *DerivativeCloseHandler {
+	return &DerivativeCloseHandler{
+		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeDerivativeClose),
+		walletRepo:  walletRepo,
+		access:      access,
+		logger:      log.WithField("component", "derivative_close"),
+	}
+}
+
+func (h *DerivativeCloseHandler) Handle(ctx context.Context, data map[string]interface{}) ([]*ledger.Entry, error) {
+	var txn DerivativeClose
+	if err := unmarshalData(data, &txn); err != nil {
+		return nil, err
+	}
+
+	if err := h.ValidateData(ctx, data); err != nil {
+		return nil, err
+	}
+
+	entries := generateCloseEntries(&txn)
+	if gasEntries := generateGasFeeEntries(&txn.DerivativeTransaction); gasEntries != nil {
+		entries = append(entries, gasEntries...)
+	}
+
+	h.logger.Debug("derivative close entries generated", "entry_count", len(entries))
+	return entries, nil
+}
+
+func (h *DerivativeCloseHandler) ValidateData(ctx context.Context, data map[string]interface{}) error {
+	var txn DerivativeClose
+	if err := unmarshalData(data, &txn); err != nil {
+		return err
+	}
+
+	if err := txn.Validate(); err != nil {
+		return err
+	}
+
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
+}
@@ -0,0 +1,58 @@
+package derivatives
+
+import (
+	"context"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// DerivativeFundingHandler handles funding payments paid or received on a perp position.
+type DerivativeFundingHandler struct {
+	ledger.BaseHandler
+	walletRepo WalletRepository
+	access     wallet.AccessChecker
+	logger     *logger.Logger
+}
+
+func NewDerivativeFundingHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *DerivativeFundingHandler {
+	return &DerivativeFundingHandler{
+		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeDerivativeFunding),
+		walletRepo:  walletRepo,
+		access:      access,
+		logger:      log.WithField("component", "derivative_funding"),
+	}
+}
+
+func (h *DerivativeFundingHandler) Handle(ctx context.Context, data map[string]interface{}) ([]*ledger.Entry, error) {
+	var txn DerivativeFunding
+	if err := unmarshalData(data, &txn); err != nil {
+		return nil, err
+	}
+
+	if err := h.ValidateData(ctx, data); err != nil {
+		return nil, err
+	}
+
+	entries := generateFundingEntries(&txn)
+	if gasEntries := generateGasFeeEntries(&txn.DerivativeTransaction); gasEntries != nil {
+		entries = append(entries, gasEntries...)
+	}
+
+	h.logger.Debug("derivative funding entries generated", "entry_count", len(entries))
+	return entries, nil
+}
+
+func (h *DerivativeFundingHandler) ValidateData(ctx context.Context, data map[string]interface{}) error {
+	var txn DerivativeFunding
+	if err := unmarshalData(data, &txn); err != nil {
+		return err
+	}
+
+	if err := txn.Validate(); err != nil {
+		return err
+	}
+
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
+}
@@ -0,0 +1,58 @@
+package derivatives
+
+import (
+	"context"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// DerivativeOpenHandler handles margin posted to open or add to a perp position.
+type DerivativeOpenHandler struct {
+	ledger.BaseHandler
+	walletRepo WalletRepository
+	access     wallet.AccessChecker
+	logger     *logger.Logger
+}
+
+func NewDerivativeOpenHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *DerivativeOpenHandler {
+	return &DerivativeOpenHandler{
+		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeDerivativeOpen),
+		walletRepo:  walletRepo,
+		access:      access,
+		logger:      log.WithField("component", "derivative_open"),
+	}
+}
+
+func (h *DerivativeOpenHandler) Handle(ctx context.Context, data map[string]interface{}) ([]*ledger.Entry, error) {
+	var txn DerivativeTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return nil, err
+	}
+
+	if err := h.ValidateData(ctx, data); err != nil {
+		return nil, err
+	}
+
+	entries := generateOpenEntries(&txn)
+	if gasEntries := generateGasFeeEntries(&txn); gasEntries != nil {
+		entries = append(entries, gasEntries...)
+	}
+
+	h.logger.Debug("derivative open entries generated", "entry_count", len(entries))
+	return entries, nil
+}
+
+func (h *DerivativeOpenHandler) ValidateData(ctx context.Context, data map[string]interface{}) error {
+	var txn DerivativeTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return err
+	}
+
+	if err := txn.Validate(); err != nil {
+		return err
+	}
+
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
+}
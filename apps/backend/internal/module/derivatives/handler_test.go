@@ -0,0 +1,176 @@
+package derivatives_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/module/derivatives"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
+	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+type MockWalletRepository struct {
+	mock.Mock
+}
+
+func (m *MockWalletRepository) GetByID(ctx context.Context, walletID uuid.UUID) (*wallet.Wallet, error) {
+	args := m.Called(ctx, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Wallet), args.Error(1)
+}
+
+func assertEntriesBalanced(t *testing.T, entries []*ledger.Entry) {
+	t.Helper()
+	debitSum := new(big.Int)
+	creditSum := new(big.Int)
+	for _, e := range entries {
+		if e.DebitCredit == ledger.Debit {
+			debitSum.Add(debitSum, e.Amount)
+		} else {
+			creditSum.Add(creditSum, e.Amount)
+		}
+	}
+	assert.Equal(t, 0, debitSum.Cmp(creditSum),
+		"entries must balance: debits=%s credits=%s", debitSum.String(), creditSum.String())
+}
+
+func buildTestData(walletID uuid.UUID) map[string]interface{} {
+	return map[string]interface{}{
+		"wallet_id":        walletID.String(),
+		"tx_hash":          "0xtest123",
+		"chain_id":         "arbitrum",
+		"occurred_at":      time.Now().UTC().Format(time.RFC3339),
+		"protocol":         "GMX V2",
+		"asset":            "USDC",
+		"amount":           "1000000000",
+		"decimals":         float64(6),
+		"usd_price":        "100000000",
+		"contract_address": "0xusdc",
+	}
+}
+
+// MockAccessChecker is a mock implementation of wallet.AccessChecker
+type MockAccessChecker struct {
+	mock.Mock
+}
+
+func (m *MockAccessChecker) Authorize(ctx context.Context, workspaceID, userID uuid.UUID, required workspace.Role) error {
+	return m.Called(ctx, workspaceID, userID, required).Error(0)
+}
+
+// newEditorAccess grants the editor role to userID and denies everyone else
+func newEditorAccess(userID uuid.UUID) *MockAccessChecker {
+	access := new(MockAccessChecker)
+	access.On("Authorize", mock.Anything, mock.Anything, userID, workspace.RoleEditor).Return(nil)
+	access.On("Authorize", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(workspace.ErrNotMember)
+	return access
+}
+
+func setupHandler(t *testing.T) (uuid.UUID, uuid.UUID, *MockWalletRepository, *MockAccessChecker, *logger.Logger, context.Context) {
+	t.Helper()
+	userID := uuid.New()
+	walletID := uuid.New()
+
+	mockRepo := new(MockWalletRepository)
+	mockRepo.On("GetByID", mock.Anything, walletID).Return(&wallet.Wallet{ID: walletID, UserID: userID}, nil)
+
+	log := logger.NewDefault("test")
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, userID)
+
+	return userID, walletID, mockRepo, newEditorAccess(userID), log, ctx
+}
+
+func TestDerivativeOpenHandler_WithGasFee(t *testing.T) {
+	_, walletID, mockRepo, access, log, ctx := setupHandler(t)
+
+	handler := derivatives.NewDerivativeOpenHandler(mockRepo, access, log)
+	assert.Equal(t, ledger.TxTypeDerivativeOpen, handler.Type())
+
+	data := buildTestData(walletID)
+	data["fee_asset"] = "ETH"
+	data["fee_amount"] = "500000000000000"
+	data["fee_decimals"] = float64(18)
+	data["fee_usd_price"] = "200000000000"
+
+	entries, err := handler.Handle(ctx, data)
+
+	require.NoError(t, err)
+	require.Len(t, entries, 4) // 2 open + 2 gas
+	assertEntriesBalanced(t, entries)
+}
+
+func TestDerivativeOpenHandler_ValidateData_MissingProtocol(t *testing.T) {
+	_, walletID, mockRepo, access, log, ctx := setupHandler(t)
+
+	handler := derivatives.NewDerivativeOpenHandler(mockRepo, access, log)
+	data := buildTestData(walletID)
+	delete(data, "protocol")
+
+	err := handler.ValidateData(ctx, data)
+	assert.ErrorIs(t, err, derivatives.ErrInvalidProtocol)
+}
+
+func TestDerivativeCloseHandler_Handle(t *testing.T) {
+	_, walletID, mockRepo, access, log, ctx := setupHandler(t)
+
+	handler := derivatives.NewDerivativeCloseHandler(mockRepo, access, log)
+	assert.Equal(t, ledger.TxTypeDerivativeClose, handler.Type())
+
+	data := buildTestData(walletID)
+	data["margin_amount"] = "800000000"
+
+	entries, err := handler.Handle(ctx, data)
+
+	require.NoError(t, err)
+	require.Len(t, entries, 4) // margin released + returned, profit + income
+	assertEntriesBalanced(t, entries)
+}
+
+func TestDerivativeCloseHandler_ValidateData_NothingReleased(t *testing.T) {
+	_, walletID, mockRepo, access, log, ctx := setupHandler(t)
+
+	handler := derivatives.NewDerivativeCloseHandler(mockRepo, access, log)
+	data := buildTestData(walletID)
+	data["amount"] = "0"
+
+	err := handler.ValidateData(ctx, data)
+	assert.ErrorIs(t, err, derivatives.ErrEmptyClose)
+}
+
+func TestDerivativeFundingHandler_Handle(t *testing.T) {
+	_, walletID, mockRepo, access, log, ctx := setupHandler(t)
+
+	handler := derivatives.NewDerivativeFundingHandler(mockRepo, access, log)
+	assert.Equal(t, ledger.TxTypeDerivativeFunding, handler.Type())
+
+	data := buildTestData(walletID)
+	data["paid"] = true
+
+	entries, err := handler.Handle(ctx, data)
+
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assertEntriesBalanced(t, entries)
+	assert.Equal(t, ledger.EntryTypeExpense, entries[0].EntryType)
+}
+
+func TestDerivativeHandler_Unauthorized(t *testing.T) {
+	_, walletID, mockRepo, access, log, _ := setupHandler(t)
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, uuid.New())
+
+	handler := derivatives.NewDerivativeOpenHandler(mockRepo, access, log)
+	err := handler.ValidateData(ctx, buildTestData(walletID))
+	assert.ErrorIs(t, err, derivatives.ErrUnauthorized)
+}
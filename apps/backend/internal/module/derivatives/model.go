@@ -0,0 +1,110 @@
+package derivatives
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// DerivativeTransaction represents margin moving into or out of a perpetuals
+// protocol (GMX, Hyperliquid). Asset is the margin asset; Market is the
+// traded market, when known (ETH-USD).
+type DerivativeTransaction struct {
+	WalletID        uuid.UUID     `json:"wallet_id"`
+	TxHash          string        `json:"tx_hash"`
+	ChainID         string        `json:"chain_id"`
+	OccurredAt      time.Time     `json:"occurred_at"`
+	Protocol        string        `json:"protocol"`
+	Market          string        `json:"market,omitempty"`
+	Asset           string        `json:"asset"`
+	Amount          *money.BigInt `json:"amount"`
+	Decimals        int           `json:"decimals"`
+	USDPrice        *money.BigInt `json:"usd_price,omitempty"`
+	ContractAddress string        `json:"contract_address,omitempty"`
+	FeeAsset        string        `json:"fee_asset,omitempty"`
+	FeeAmount       *money.BigInt `json:"fee_amount,omitempty"`
+	FeeDecimals     int           `json:"fee_decimals,omitempty"`
+	FeeUSDPrice     *money.BigInt `json:"fee_usd_price,omitempty"`
+}
+
+// Validate validates the derivative transaction data.
+func (t *DerivativeTransaction) Validate() error {
+	if err := t.validateHeader(); err != nil {
+		return err
+	}
+	if !t.Amount.IsPositive() {
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+func (t *DerivativeTransaction) validateHeader() error {
+	if t.WalletID == uuid.Nil {
+		return ErrInvalidWalletID
+	}
+	if t.TxHash == "" {
+		return ErrInvalidTxHash
+	}
+	if t.ChainID == "" {
+		return ErrInvalidChainID
+	}
+	if t.Protocol == "" {
+		return ErrInvalidProtocol
+	}
+	if t.Asset == "" {
+		return ErrInvalidAsset
+	}
+	if t.Decimals <= 0 {
+		return ErrInvalidDecimals
+	}
+	return nil
+}
+
+// DerivativeClose represents a position being closed. Amount is what the
+// wallet received back and MarginAmount the margin the position released;
+// the difference is the realized PnL. A position liquidated or closed at a
+// total loss receives nothing.
+type DerivativeClose struct {
+	DerivativeTransaction
+	MarginAmount *money.BigInt `json:"margin_amount"`
+}
+
+// Validate validates the close data.
+func (t *DerivativeClose) Validate() error {
+	if err := t.validateHeader(); err != nil {
+		return err
+	}
+	if !t.Amount.IsNil() && t.Amount.Sign() < 0 {
+		return ErrInvalidAmount
+	}
+	if !t.MarginAmount.IsNil() && t.MarginAmount.Sign() < 0 {
+		return ErrInvalidMarginAmount
+	}
+	if !t.Amount.IsPositive() && !t.MarginAmount.IsPositive() {
+		return ErrEmptyClose
+	}
+	return nil
+}
+
+// DerivativeFunding represents a funding payment on a position. Longs pay
+// shorts when the perp trades above the index and the other way round, so
+// the payment is either paid or received. Funding settles against the
+// margin, except funding claimed into the wallet (GMX V2).
+type DerivativeFunding struct {
+	DerivativeTransaction
+	Paid    bool `json:"paid"`
+	Claimed bool `json:"claimed,omitempty"`
+}
+
+// Validate validates the funding data.
+func (t *DerivativeFunding) Validate() error {
+	if err := t.DerivativeTransaction.Validate(); err != nil {
+		return err
+	}
+	if t.Paid && t.Claimed {
+		return ErrClaimedFundingPaid
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package income
+
+import "errors"
+
+var (
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrAccessDenied        = errors.New("editor access to the wallet is required")
+	ErrNotClassifiable     = errors.New("only incoming transfers, manual income and reward claims can be classified as income")
+	ErrInvalidCategory     = errors.New("category must be one of reward, staking, airdrop, interest, salary")
+	ErrInvalidYear         = errors.New("invalid report year")
+)
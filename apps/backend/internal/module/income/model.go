@@ -0,0 +1,132 @@
+package income
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+)
+
+// Category is the kind of taxable income an acquisition represents
+type Category string
+
+const (
+	CategoryReward   Category = "reward"
+	CategoryStaking  Category = "staking"
+	CategoryAirdrop  Category = "airdrop"
+	CategoryInterest Category = "interest"
+	CategorySalary   Category = "salary"
+)
+
+// AllCategories returns the income categories in report order
+func AllCategories() []Category {
+	return []Category{CategoryReward, CategoryStaking, CategoryAirdrop, CategoryInterest, CategorySalary}
+}
+
+// IsValid checks if the category is known
+func (c Category) IsValid() bool {
+	for _, known := range AllCategories() {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultCategory returns the category a transaction type is reported under
+// without a user classification. Only reward claims are income by default.
+func DefaultCategory(t ledger.TransactionType) (Category, bool) {
+	switch t {
+	case ledger.TxTypeDefiClaim, ledger.TxTypeLPClaimFees:
+		return CategoryReward, true
+	case ledger.TxTypeLendingClaim, ledger.TxTypeLendingInterest:
+		return CategoryInterest, true
+	case ledger.TxTypeStakingReward:
+		return CategoryStaking, true
+	}
+	return "", false
+}
+
+// DefaultIncomeTypes returns the transaction types that are income by default
+func DefaultIncomeTypes() []ledger.TransactionType {
+	var types []ledger.TransactionType
+	for _, t := range ledger.AllTransactionTypes() {
+		if _, ok := DefaultCategory(t); ok {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// IsClassifiable reports whether users may classify a transaction type as
+// income: plain incoming transfers and manual income, plus the reward
+// claims whose default category can be changed.
+func IsClassifiable(t ledger.TransactionType) bool {
+	if _, ok := DefaultCategory(t); ok {
+		return true
+	}
+	return t == ledger.TxTypeTransferIn || t == ledger.TxTypeManualIncome
+}
+
+// Source tells whether an income event was classified by a user or by default
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceUser    Source = "user"
+)
+
+// Classification marks a transaction as income of a category
+type Classification struct {
+	TransactionID uuid.UUID
+	Category      Category
+	ClassifiedBy  uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Receipt is one asset a candidate income transaction brought into a
+// wallet, valued at fair market value when received
+type Receipt struct {
+	TransactionID   uuid.UUID
+	TransactionType ledger.TransactionType
+	WalletID        uuid.UUID
+	OccurredAt      time.Time
+	AssetID         string
+	Amount          *big.Int  // Base units
+	USDValue        *big.Int  // Scaled by 10^8
+	Classified      *Category // User classification, if any
+}
+
+// Event is a receipt resolved to its income category
+type Event struct {
+	Receipt
+	Category Category
+	Source   Source
+}
+
+// AssetTotal sums the income received in one asset
+type AssetTotal struct {
+	AssetID  string
+	Amount   *big.Int
+	USDValue *big.Int
+	Count    int
+}
+
+// CategoryTotal sums the income of one category, per asset
+type CategoryTotal struct {
+	Category Category
+	USDValue *big.Int
+	Assets   []*AssetTotal // Ordered by USD value, largest first
+}
+
+// Report is the ordinary income received in a calendar year (UTC)
+type Report struct {
+	Year       int
+	From       time.Time
+	To         time.Time
+	TotalUSD   *big.Int
+	Categories []*CategoryTotal // Only categories with income, in AllCategories order
+}
@@ -0,0 +1,34 @@
+package income
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+)
+
+// Repository persists income classifications and reads income receipts.
+// Lookups return nil, nil when not found.
+type Repository interface {
+	GetClassification(ctx context.Context, txID uuid.UUID) (*Classification, error)
+	UpsertClassification(ctx context.Context, c *Classification) error
+	DeleteClassification(ctx context.Context, txID uuid.UUID) error
+
+	// ListReceipts returns the wallet increases of completed transactions in
+	// [from, to) that are classified, or whose type is in defaultTypes, on
+	// wallets the user can view. Reversed transactions are excluded.
+	ListReceipts(ctx context.Context, userID uuid.UUID, from, to time.Time, defaultTypes []ledger.TransactionType) ([]*Receipt, error)
+}
+
+// TransactionSource loads ledger transactions
+type TransactionSource interface {
+	GetTransaction(ctx context.Context, id uuid.UUID) (*ledger.Transaction, error)
+}
+
+// WalletReader loads wallets for access checks
+type WalletReader interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*wallet.Wallet, error)
+}
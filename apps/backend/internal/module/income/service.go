@@ -0,0 +1,257 @@
+package income
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// Service classifies acquisitions as ordinary income and reports them.
+//
+// Income is valued at the USD value recorded on the wallet entries, i.e.
+// the fair market value at receipt, which is also the cost basis of the
+// lots the acquisition created.
+type Service struct {
+	repo    Repository
+	txs     TransactionSource
+	wallets WalletReader
+	access  wallet.AccessChecker
+	audit   audit.Recorder // optional
+	logger  *logger.Logger
+}
+
+// NewService creates a new income service
+func NewService(repo Repository, txs TransactionSource, wallets WalletReader, access wallet.AccessChecker, recorder audit.Recorder, log *logger.Logger) *Service {
+	return &Service{
+		repo:    repo,
+		txs:     txs,
+		wallets: wallets,
+		access:  access,
+		audit:   recorder,
+		logger:  log.WithField("component", "income"),
+	}
+}
+
+// Classify marks a transaction as income of the given category, replacing
+// any earlier classification. Requires editor access to the wallet.
+func (s *Service) Classify(ctx context.Context, userID, txID uuid.UUID, category Category) (*Classification, error) {
+	if !category.IsValid() {
+		return nil, ErrInvalidCategory
+	}
+	if err := s.authorizeTransaction(ctx, userID, txID); err != nil {
+		return nil, err
+	}
+
+	before, err := s.repo.GetClassification(ctx, txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get classification: %w", err)
+	}
+
+	now := time.Now().UTC()
+	c := &Classification{
+		TransactionID: txID,
+		Category:      category,
+		ClassifiedBy:  userID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if before != nil {
+		c.CreatedAt = before.CreatedAt
+	}
+	if err := s.repo.UpsertClassification(ctx, c); err != nil {
+		return nil, fmt.Errorf("failed to save classification: %w", err)
+	}
+
+	s.record(ctx, userID, txID, before, c)
+	s.logger.Info("transaction classified as income", "tx_id", txID, "category", category, "user_id", userID)
+	return c, nil
+}
+
+// Unclassify removes the user classification; the transaction falls back
+// to its default category, if its type has one.
+func (s *Service) Unclassify(ctx context.Context, userID, txID uuid.UUID) error {
+	if err := s.authorizeTransaction(ctx, userID, txID); err != nil {
+		return err
+	}
+
+	before, err := s.repo.GetClassification(ctx, txID)
+	if err != nil {
+		return fmt.Errorf("failed to get classification: %w", err)
+	}
+	if before == nil {
+		return nil
+	}
+	if err := s.repo.DeleteClassification(ctx, txID); err != nil {
+		return fmt.Errorf("failed to delete classification: %w", err)
+	}
+
+	s.record(ctx, userID, txID, before, nil)
+	return nil
+}
+
+// ListEvents returns the income events of a calendar year in time order,
+// optionally limited to one category
+func (s *Service) ListEvents(ctx context.Context, userID uuid.UUID, year int, category *Category) ([]*Event, error) {
+	if category != nil && !category.IsValid() {
+		return nil, ErrInvalidCategory
+	}
+	events, err := s.events(ctx, userID, year)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return events, nil
+	}
+
+	filtered := make([]*Event, 0, len(events))
+	for _, e := range events {
+		if e.Category == *category {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered, nil
+}
+
+// GetReport sums a calendar year's income per category and asset
+func (s *Service) GetReport(ctx context.Context, userID uuid.UUID, year int) (*Report, error) {
+	events, err := s.events(ctx, userID, year)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := yearRange(year)
+	report := &Report{Year: year, From: from, To: to, TotalUSD: new(big.Int)}
+
+	byCategory := make(map[Category]map[string]*AssetTotal)
+	for _, e := range events {
+		assets, ok := byCategory[e.Category]
+		if !ok {
+			assets = make(map[string]*AssetTotal)
+			byCategory[e.Category] = assets
+		}
+		total, ok := assets[e.AssetID]
+		if !ok {
+			total = &AssetTotal{AssetID: e.AssetID, Amount: new(big.Int), USDValue: new(big.Int)}
+			assets[e.AssetID] = total
+		}
+		total.Amount.Add(total.Amount, e.Amount)
+		total.USDValue.Add(total.USDValue, e.USDValue)
+		total.Count++
+	}
+
+	for _, category := range AllCategories() {
+		assets, ok := byCategory[category]
+		if !ok {
+			continue
+		}
+		ct := &CategoryTotal{Category: category, USDValue: new(big.Int)}
+		for _, a := range assets {
+			ct.Assets = append(ct.Assets, a)
+			ct.USDValue.Add(ct.USDValue, a.USDValue)
+		}
+		sort.Slice(ct.Assets, func(i, j int) bool {
+			if c := ct.Assets[i].USDValue.Cmp(ct.Assets[j].USDValue); c != 0 {
+				return c > 0
+			}
+			return ct.Assets[i].AssetID < ct.Assets[j].AssetID
+		})
+		report.Categories = append(report.Categories, ct)
+		report.TotalUSD.Add(report.TotalUSD, ct.USDValue)
+	}
+
+	return report, nil
+}
+
+// events loads a year's receipts and resolves their categories
+func (s *Service) events(ctx context.Context, userID uuid.UUID, year int) ([]*Event, error) {
+	if year < 2009 || year > time.Now().UTC().Year() {
+		return nil, ErrInvalidYear
+	}
+	from, to := yearRange(year)
+
+	receipts, err := s.repo.ListReceipts(ctx, userID, from, to, DefaultIncomeTypes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list income receipts: %w", err)
+	}
+
+	events := make([]*Event, 0, len(receipts))
+	for _, r := range receipts {
+		e := &Event{Receipt: *r}
+		if r.Classified != nil {
+			e.Category, e.Source = *r.Classified, SourceUser
+		} else if category, ok := DefaultCategory(r.TransactionType); ok {
+			e.Category, e.Source = category, SourceDefault
+		} else {
+			continue
+		}
+		events = append(events, e)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].OccurredAt.Before(events[j].OccurredAt)
+	})
+	return events, nil
+}
+
+// authorizeTransaction checks the transaction can be classified and the
+// user may edit its wallet. Wallets the user cannot see read as not found.
+func (s *Service) authorizeTransaction(ctx context.Context, userID, txID uuid.UUID) error {
+	tx, err := s.txs.GetTransaction(ctx, txID)
+	if err != nil || tx == nil || tx.WalletID == nil {
+		return ErrTransactionNotFound
+	}
+
+	w, err := s.wallets.GetByID(ctx, *tx.WalletID)
+	if err != nil || w == nil {
+		return ErrTransactionNotFound
+	}
+	if err := s.access.Authorize(ctx, w.WorkspaceID, userID, workspace.RoleEditor); err != nil {
+		switch {
+		case errors.Is(err, workspace.ErrNotMember):
+			return ErrTransactionNotFound
+		case errors.Is(err, workspace.ErrInsufficientRole):
+			return ErrAccessDenied
+		}
+		return fmt.Errorf("failed to check workspace access: %w", err)
+	}
+
+	if !IsClassifiable(tx.Type) {
+		return ErrNotClassifiable
+	}
+	return nil
+}
+
+func (s *Service) record(ctx context.Context, userID, txID uuid.UUID, before, after *Classification) {
+	if s.audit == nil {
+		return
+	}
+	event := audit.Event{
+		ActorID:    userID,
+		Action:     audit.ActionIncomeClassify,
+		TargetType: audit.TargetTransaction,
+		TargetID:   txID.String(),
+	}
+	if before != nil {
+		event.Before = map[string]any{"category": before.Category}
+	}
+	if after != nil {
+		event.After = map[string]any{"category": after.Category}
+	}
+	s.audit.Record(ctx, event)
+}
+
+// yearRange returns [Jan 1, next Jan 1) in UTC
+func yearRange(year int) (time.Time, time.Time) {
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(1, 0, 0)
+}
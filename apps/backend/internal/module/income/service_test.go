@@ -0,0 +1,204 @@
+package income
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// mockRepo is an in-memory implementation of Repository for testing.
+type mockRepo struct {
+	classifications map[uuid.UUID]*Classification
+	receipts        []*Receipt
+}
+
+func (r *mockRepo) GetClassification(_ context.Context, txID uuid.UUID) (*Classification, error) {
+	return r.classifications[txID], nil
+}
+
+func (r *mockRepo) UpsertClassification(_ context.Context, c *Classification) error {
+	r.classifications[c.TransactionID] = c
+	return nil
+}
+
+func (r *mockRepo) DeleteClassification(_ context.Context, txID uuid.UUID) error {
+	delete(r.classifications, txID)
+	return nil
+}
+
+func (r *mockRepo) ListReceipts(_ context.Context, _ uuid.UUID, from, to time.Time, defaultTypes []ledger.TransactionType) ([]*Receipt, error) {
+	var result []*Receipt
+	for _, rc := range r.receipts {
+		if rc.OccurredAt.Before(from) || !rc.OccurredAt.Before(to) {
+			continue
+		}
+		cp := *rc
+		if c, ok := r.classifications[rc.TransactionID]; ok {
+			cp.Classified = &c.Category
+		}
+		candidate := cp.Classified != nil
+		for _, t := range defaultTypes {
+			candidate = candidate || t == rc.TransactionType
+		}
+		if candidate {
+			result = append(result, &cp)
+		}
+	}
+	return result, nil
+}
+
+type mockTxs map[uuid.UUID]*ledger.Transaction
+
+func (m mockTxs) GetTransaction(_ context.Context, id uuid.UUID) (*ledger.Transaction, error) {
+	return m[id], nil
+}
+
+type mockWallets map[uuid.UUID]*wallet.Wallet
+
+func (m mockWallets) GetByID(_ context.Context, id uuid.UUID) (*wallet.Wallet, error) {
+	return m[id], nil
+}
+
+// mockAccess grants each user a fixed role in every workspace
+type mockAccess map[uuid.UUID]workspace.Role
+
+func (m mockAccess) Authorize(_ context.Context, _, userID uuid.UUID, required workspace.Role) error {
+	role, ok := m[userID]
+	if !ok {
+		return workspace.ErrNotMember
+	}
+	if required == workspace.RoleEditor && role == workspace.RoleViewer {
+		return workspace.ErrInsufficientRole
+	}
+	return nil
+}
+
+type fixture struct {
+	svc      *Service
+	repo     *mockRepo
+	txs      mockTxs
+	walletID uuid.UUID
+	editor   uuid.UUID
+	viewer   uuid.UUID
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	walletID, editor, viewer := uuid.New(), uuid.New(), uuid.New()
+	repo := &mockRepo{classifications: make(map[uuid.UUID]*Classification)}
+	txs := mockTxs{}
+	wallets := mockWallets{walletID: {ID: walletID, WorkspaceID: uuid.New()}}
+	access := mockAccess{editor: workspace.RoleEditor, viewer: workspace.RoleViewer}
+
+	return &fixture{
+		svc:      NewService(repo, txs, wallets, access, nil, logger.NewDefault("test")),
+		repo:     repo,
+		txs:      txs,
+		walletID: walletID,
+		editor:   editor,
+		viewer:   viewer,
+	}
+}
+
+// addReceipt records a transaction and the single asset it brought in
+func (f *fixture) addReceipt(txType ledger.TransactionType, occurredAt time.Time, asset string, usd int64) uuid.UUID {
+	id := uuid.New()
+	f.txs[id] = &ledger.Transaction{ID: id, Type: txType, WalletID: &f.walletID, OccurredAt: occurredAt}
+	f.repo.receipts = append(f.repo.receipts, &Receipt{
+		TransactionID:   id,
+		TransactionType: txType,
+		WalletID:        f.walletID,
+		OccurredAt:      occurredAt,
+		AssetID:         asset,
+		Amount:          big.NewInt(1000),
+		USDValue:        big.NewInt(usd),
+	})
+	return id
+}
+
+func TestClassify_Authorization(t *testing.T) {
+	f := newFixture(t)
+	airdrop := f.addReceipt(ledger.TxTypeTransferIn, time.Now(), "ARB", 100)
+	swap := f.addReceipt(ledger.TxTypeSwap, time.Now(), "ETH", 100)
+
+	_, err := f.svc.Classify(context.Background(), f.editor, airdrop, "gift")
+	assert.ErrorIs(t, err, ErrInvalidCategory)
+
+	_, err = f.svc.Classify(context.Background(), f.viewer, airdrop, CategoryAirdrop)
+	assert.ErrorIs(t, err, ErrAccessDenied)
+
+	_, err = f.svc.Classify(context.Background(), uuid.New(), airdrop, CategoryAirdrop)
+	assert.ErrorIs(t, err, ErrTransactionNotFound)
+
+	_, err = f.svc.Classify(context.Background(), f.editor, swap, CategoryAirdrop)
+	assert.ErrorIs(t, err, ErrNotClassifiable)
+
+	c, err := f.svc.Classify(context.Background(), f.editor, airdrop, CategoryAirdrop)
+	require.NoError(t, err)
+	assert.Equal(t, f.editor, c.ClassifiedBy)
+}
+
+func TestGetReport_DefaultsAndClassifications(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	year := time.Now().UTC().Year() - 1
+	day := func(d int) time.Time { return time.Date(year, time.March, d, 12, 0, 0, 0, time.UTC) }
+
+	f.addReceipt(ledger.TxTypeDefiClaim, day(1), "CRV", 500)
+	f.addReceipt(ledger.TxTypeDefiClaim, day(2), "CRV", 300)
+	f.addReceipt(ledger.TxTypeLendingClaim, day(3), "USDC", 200)
+	f.addReceipt(ledger.TxTypeStakingReward, day(3), "stETH", 100)
+	fees := f.addReceipt(ledger.TxTypeLPClaimFees, day(4), "ETH", 1000)
+	airdrop := f.addReceipt(ledger.TxTypeTransferIn, day(5), "ARB", 700)
+	// A plain transfer and next year's claim are left out
+	f.addReceipt(ledger.TxTypeTransferIn, day(6), "ETH", 9999)
+	f.addReceipt(ledger.TxTypeDefiClaim, time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC), "CRV", 50)
+
+	_, err := f.svc.Classify(ctx, f.editor, airdrop, CategoryAirdrop)
+	require.NoError(t, err)
+	_, err = f.svc.Classify(ctx, f.editor, fees, CategoryStaking)
+	require.NoError(t, err)
+
+	report, err := f.svc.GetReport(ctx, f.viewer, year)
+	require.NoError(t, err)
+	assert.Equal(t, "2800", report.TotalUSD.String())
+
+	require.Len(t, report.Categories, 4)
+	reward := report.Categories[0]
+	assert.Equal(t, CategoryReward, reward.Category)
+	require.Len(t, reward.Assets, 1)
+	assert.Equal(t, "800", reward.USDValue.String())
+	assert.Equal(t, 2, reward.Assets[0].Count)
+	assert.Equal(t, "2000", reward.Assets[0].Amount.String())
+
+	assert.Equal(t, CategoryStaking, report.Categories[1].Category)
+	assert.Equal(t, "1100", report.Categories[1].USDValue.String())
+	assert.Equal(t, CategoryAirdrop, report.Categories[2].Category)
+	assert.Equal(t, CategoryInterest, report.Categories[3].Category)
+
+	// Removing the classification restores the default category
+	require.NoError(t, f.svc.Unclassify(ctx, f.editor, fees))
+	category := CategoryReward
+	events, err := f.svc.ListEvents(ctx, f.viewer, year, &category)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, SourceDefault, events[2].Source)
+	assert.Equal(t, fees, events[2].TransactionID)
+}
+
+func TestGetReport_InvalidYear(t *testing.T) {
+	f := newFixture(t)
+
+	_, err := f.svc.GetReport(context.Background(), f.editor, time.Now().UTC().Year()+1)
+	assert.ErrorIs(t, err, ErrInvalidYear)
+}
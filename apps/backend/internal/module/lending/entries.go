@@ -273,6 +273,197 @@ func generateClaimEntries(txn *LendingTransaction) []*ledger.Entry {
 	}
 }
 
+// generateInterestEntries generates entries for supply interest accrued into
+// the deposit (aToken growth): income → collateral.
+//
+//	DEBIT  collateral.{protocol}.{wID}.{chain}.{asset}  (collateral_increase)
+//	CREDIT income.lending.{chain}.{asset}               (income)
+func generateInterestEntries(txn *LendingTransaction) []*ledger.Entry {
+	amount := txn.Amount.ToBigInt()
+	usdRate, usdValue := calcUSD(txn)
+
+	walletID := txn.WalletID.String()
+	chain := txn.ChainID
+
+	return []*ledger.Entry{
+		{
+			ID:          uuid.New(),
+			DebitCredit: ledger.Debit,
+			EntryType:   ledger.EntryTypeCollateralIncrease,
+			Amount:      new(big.Int).Set(amount),
+			AssetID:     txn.Asset,
+			USDRate:     new(big.Int).Set(usdRate),
+			USDValue:    new(big.Int).Set(usdValue),
+			OccurredAt:  txn.OccurredAt,
+			CreatedAt:   time.Now().UTC(),
+			Metadata: map[string]interface{}{
+				"wallet_id":        walletID,
+				"account_code":     fmt.Sprintf("collateral.%s.%s.%s.%s", txn.Protocol, walletID, chain, txn.Asset),
+				"account_type":     "COLLATERAL",
+				"tx_hash":          txn.TxHash,
+				"chain_id":         chain,
+				"protocol":         txn.Protocol,
+				"contract_address": txn.ContractAddress,
+			},
+		},
+		{
+			ID:          uuid.New(),
+			DebitCredit: ledger.Credit,
+			EntryType:   ledger.EntryTypeIncome,
+			Amount:      new(big.Int).Set(amount),
+			AssetID:     txn.Asset,
+			USDRate:     new(big.Int).Set(usdRate),
+			USDValue:    new(big.Int).Set(usdValue),
+			OccurredAt:  txn.OccurredAt,
+			CreatedAt:   time.Now().UTC(),
+			Metadata: map[string]interface{}{
+				"account_code": fmt.Sprintf("income.lending.%s.%s", chain, txn.Asset),
+				"tx_hash":      txn.TxHash,
+				"chain_id":     chain,
+				"protocol":     txn.Protocol,
+			},
+		},
+	}
+}
+
+// generateBorrowInterestEntries generates entries for borrow interest accrued
+// onto the debt (debt token growth): liability → expense.
+//
+//	DEBIT  expense.lending.{chain}.{asset}             (expense)
+//	CREDIT liability.{protocol}.{wID}.{chain}.{asset}  (liability_increase)
+func generateBorrowInterestEntries(txn *LendingTransaction) []*ledger.Entry {
+	amount := txn.Amount.ToBigInt()
+	usdRate, usdValue := calcUSD(txn)
+
+	walletID := txn.WalletID.String()
+	chain := txn.ChainID
+
+	return []*ledger.Entry{
+		{
+			ID:          uuid.New(),
+			DebitCredit: ledger.Debit,
+			EntryType:   ledger.EntryTypeExpense,
+			Amount:      new(big.Int).Set(amount),
+			AssetID:     txn.Asset,
+			USDRate:     new(big.Int).Set(usdRate),
+			USDValue:    new(big.Int).Set(usdValue),
+			OccurredAt:  txn.OccurredAt,
+			CreatedAt:   time.Now().UTC(),
+			Metadata: map[string]interface{}{
+				"account_code": fmt.Sprintf("expense.lending.%s.%s", chain, txn.Asset),
+				"tx_hash":      txn.TxHash,
+				"chain_id":     chain,
+				"protocol":     txn.Protocol,
+			},
+		},
+		{
+			ID:          uuid.New(),
+			DebitCredit: ledger.Credit,
+			EntryType:   ledger.EntryTypeLiabilityIncrease,
+			Amount:      new(big.Int).Set(amount),
+			AssetID:     txn.Asset,
+			USDRate:     new(big.Int).Set(usdRate),
+			USDValue:    new(big.Int).Set(usdValue),
+			OccurredAt:  txn.OccurredAt,
+			CreatedAt:   time.Now().UTC(),
+			Metadata: map[string]interface{}{
+				"wallet_id":        walletID,
+				"account_code":     fmt.Sprintf("liability.%s.%s.%s.%s", txn.Protocol, walletID, chain, txn.Asset),
+				"account_type":     "LIABILITY",
+				"tx_hash":          txn.TxHash,
+				"chain_id":         chain,
+				"protocol":         txn.Protocol,
+				"contract_address": txn.ContractAddress,
+			},
+		},
+	}
+}
+
+// generateLiquidationEntries generates entries for a liquidation: the
+// liquidator repays debt and seizes collateral worth more than it repaid. The
+// seized collateral is swapped for the repaid debt through clearing, and the
+// part worth more than the debt is the liquidation penalty, booked as a loss.
+//
+//	CREDIT collateral.{protocol}.{wID}.{chain}.{asset}  (collateral_decrease)
+//	DEBIT  clearing.{chain}.{asset}                     (clearing)
+//	DEBIT  expense.liquidation.{chain}.{asset}          (expense, penalty)
+//	DEBIT  liability.{protocol}.{wID}.{chain}.{debt}    (liability_decrease)
+//	CREDIT clearing.{chain}.{debt}                      (clearing)
+//
+// Without prices on both sides the penalty cannot be measured and the whole
+// seized amount goes through clearing.
+func generateLiquidationEntries(txn *LendingLiquidation) []*ledger.Entry {
+	seized := txn.Amount.ToBigInt()
+	seizedRate, seizedValue := calcUSD(&txn.LendingTransaction)
+
+	repaid := txn.DebtAmount.ToBigInt()
+	repaidRate := big.NewInt(0)
+	if txn.DebtUSDPrice != nil && !txn.DebtUSDPrice.IsNil() {
+		repaidRate = txn.DebtUSDPrice.ToBigInt()
+	}
+	repaidValue := money.CalcUSDValue(repaid, repaidRate, txn.DebtDecimals)
+
+	penalty, penaltyValue := big.NewInt(0), big.NewInt(0)
+	if seizedRate.Sign() > 0 && repaidRate.Sign() > 0 && seizedValue.Cmp(repaidValue) > 0 {
+		penaltyValue.Sub(seizedValue, repaidValue)
+		penalty.Mul(seized, penaltyValue)
+		penalty.Div(penalty, seizedValue)
+	}
+	cleared := new(big.Int).Sub(seized, penalty)
+	clearedValue := new(big.Int).Sub(seizedValue, penaltyValue)
+
+	walletID := txn.WalletID.String()
+	chain := txn.ChainID
+
+	entry := func(dc ledger.DebitCredit, et ledger.EntryType, asset string, amount, usdRate, usdValue *big.Int, meta map[string]interface{}) *ledger.Entry {
+		meta["tx_hash"] = txn.TxHash
+		meta["chain_id"] = chain
+		meta["protocol"] = txn.Protocol
+		return &ledger.Entry{
+			ID:          uuid.New(),
+			DebitCredit: dc,
+			EntryType:   et,
+			Amount:      new(big.Int).Set(amount),
+			AssetID:     asset,
+			USDRate:     new(big.Int).Set(usdRate),
+			USDValue:    new(big.Int).Set(usdValue),
+			OccurredAt:  txn.OccurredAt,
+			CreatedAt:   time.Now().UTC(),
+			Metadata:    meta,
+		}
+	}
+
+	entries := []*ledger.Entry{
+		entry(ledger.Credit, ledger.EntryTypeCollateralDecrease, txn.Asset, seized, seizedRate, seizedValue, map[string]interface{}{
+			"wallet_id":        walletID,
+			"account_code":     fmt.Sprintf("collateral.%s.%s.%s.%s", txn.Protocol, walletID, chain, txn.Asset),
+			"account_type":     "COLLATERAL",
+			"contract_address": txn.ContractAddress,
+		}),
+		entry(ledger.Debit, ledger.EntryTypeClearing, txn.Asset, cleared, seizedRate, clearedValue, map[string]interface{}{
+			"account_code": fmt.Sprintf("clearing.%s.%s", chain, txn.Asset),
+			"account_type": "CLEARING",
+		}),
+	}
+	if penalty.Sign() > 0 {
+		entries = append(entries, entry(ledger.Debit, ledger.EntryTypeExpense, txn.Asset, penalty, seizedRate, penaltyValue, map[string]interface{}{
+			"account_code": fmt.Sprintf("expense.liquidation.%s.%s", chain, txn.Asset),
+		}))
+	}
+	return append(entries,
+		entry(ledger.Debit, ledger.EntryTypeLiabilityDecrease, txn.DebtAsset, repaid, repaidRate, repaidValue, map[string]interface{}{
+			"wallet_id":        walletID,
+			"account_code":     fmt.Sprintf("liability.%s.%s.%s.%s", txn.Protocol, walletID, chain, txn.DebtAsset),
+			"account_type":     "LIABILITY",
+			"contract_address": txn.DebtContractAddress,
+		}),
+		entry(ledger.Credit, ledger.EntryTypeClearing, txn.DebtAsset, repaid, repaidRate, repaidValue, map[string]interface{}{
+			"account_code": fmt.Sprintf("clearing.%s.%s", chain, txn.DebtAsset),
+			"account_type": "CLEARING",
+		}),
+	)
+}
+
 // generateGasFeeEntries generates gas fee entries if the transaction has a fee.
 //
 //	DEBIT  gas.{chain}.{feeAsset}          (gas_fee)
@@ -145,6 +145,98 @@ func TestGenerateClaimEntries(t *testing.T) {
 	assert.Contains(t, entries[1].Metadata["account_code"].(string), "income.lending.")
 }
 
+func TestGenerateInterestEntries(t *testing.T) {
+	txn := baseTxn()
+	entries := generateInterestEntries(txn)
+
+	require.Len(t, entries, 2)
+	assertEntriesBalanced(t, entries)
+
+	// Interest grows the deposit, not the wallet
+	assert.Equal(t, ledger.Debit, entries[0].DebitCredit)
+	assert.Equal(t, ledger.EntryTypeCollateralIncrease, entries[0].EntryType)
+	assert.Contains(t, entries[0].Metadata["account_code"], "collateral.Aave V3.")
+
+	assert.Equal(t, ledger.Credit, entries[1].DebitCredit)
+	assert.Equal(t, ledger.EntryTypeIncome, entries[1].EntryType)
+	assert.Equal(t, "income.lending.ethereum.ETH", entries[1].Metadata["account_code"])
+}
+
+func TestGenerateBorrowInterestEntries(t *testing.T) {
+	txn := baseTxn()
+	entries := generateBorrowInterestEntries(txn)
+
+	require.Len(t, entries, 2)
+	assertEntriesBalanced(t, entries)
+
+	assert.Equal(t, ledger.Debit, entries[0].DebitCredit)
+	assert.Equal(t, ledger.EntryTypeExpense, entries[0].EntryType)
+	assert.Equal(t, "expense.lending.ethereum.ETH", entries[0].Metadata["account_code"])
+
+	// Interest grows the debt
+	assert.Equal(t, ledger.Credit, entries[1].DebitCredit)
+	assert.Equal(t, ledger.EntryTypeLiabilityIncrease, entries[1].EntryType)
+	assert.Equal(t, "LIABILITY", entries[1].Metadata["account_type"])
+}
+
+func liquidationTxn() *LendingLiquidation {
+	return &LendingLiquidation{
+		LendingTransaction: *baseTxn(),
+		DebtAsset:          "USDC",
+		DebtAmount:         money.NewBigInt(big.NewInt(1_900_000_000)), // 1900 USDC
+		DebtDecimals:       6,
+		DebtUSDPrice:       money.NewBigInt(big.NewInt(100_000_000)), // $1
+	}
+}
+
+func TestGenerateLiquidationEntries(t *testing.T) {
+	// 1 ETH worth $2000 seized for $1900 of debt: $100 penalty
+	txn := liquidationTxn()
+	entries := generateLiquidationEntries(txn)
+
+	require.Len(t, entries, 5)
+
+	assert.Equal(t, ledger.Credit, entries[0].DebitCredit)
+	assert.Equal(t, ledger.EntryTypeCollateralDecrease, entries[0].EntryType)
+	assert.Equal(t, "COLLATERAL", entries[0].Metadata["account_type"])
+	assert.Equal(t, "1000000000000000000", entries[0].Amount.String())
+	assert.Equal(t, big.NewInt(200_000_000_000).String(), entries[0].USDValue.String())
+
+	assert.Equal(t, ledger.EntryTypeClearing, entries[1].EntryType)
+	assert.Equal(t, "clearing.ethereum.ETH", entries[1].Metadata["account_code"])
+	assert.Equal(t, "950000000000000000", entries[1].Amount.String())
+	assert.Equal(t, big.NewInt(190_000_000_000).String(), entries[1].USDValue.String())
+
+	assert.Equal(t, ledger.Debit, entries[2].DebitCredit)
+	assert.Equal(t, ledger.EntryTypeExpense, entries[2].EntryType)
+	assert.Equal(t, "expense.liquidation.ethereum.ETH", entries[2].Metadata["account_code"])
+	assert.Equal(t, "50000000000000000", entries[2].Amount.String())
+	assert.Equal(t, big.NewInt(10_000_000_000).String(), entries[2].USDValue.String())
+
+	assert.Equal(t, ledger.Debit, entries[3].DebitCredit)
+	assert.Equal(t, ledger.EntryTypeLiabilityDecrease, entries[3].EntryType)
+	assert.Contains(t, entries[3].Metadata["account_code"], "liability.Aave V3.")
+	assert.Equal(t, "USDC", entries[3].AssetID)
+
+	assert.Equal(t, ledger.Credit, entries[4].DebitCredit)
+	assert.Equal(t, "clearing.ethereum.USDC", entries[4].Metadata["account_code"])
+
+	// Each side balances in its own asset
+	assert.Equal(t, 0, new(big.Int).Add(entries[1].Amount, entries[2].Amount).Cmp(entries[0].Amount))
+	assert.Equal(t, 0, entries[3].Amount.Cmp(entries[4].Amount))
+}
+
+func TestGenerateLiquidationEntries_NoPrice(t *testing.T) {
+	txn := liquidationTxn()
+	txn.DebtUSDPrice = nil
+	entries := generateLiquidationEntries(txn)
+
+	// Without a debt price the penalty cannot be measured
+	require.Len(t, entries, 4)
+	assert.Equal(t, ledger.EntryTypeClearing, entries[1].EntryType)
+	assert.Equal(t, 0, entries[1].Amount.Cmp(entries[0].Amount))
+}
+
 func TestGenerateGasFeeEntries(t *testing.T) {
 	txn := baseTxn()
 	txn.FeeAsset = "ETH"
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
 
@@ -11,13 +12,15 @@ import (
 type LendingClaimHandler struct {
 	ledger.BaseHandler
 	walletRepo WalletRepository
+	access     wallet.AccessChecker
 	logger     *logger.Logger
 }
 
-func NewLendingClaimHandler(walletRepo WalletRepository, log *logger.Logger) *LendingClaimHandler {
+func NewLendingClaimHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *LendingClaimHandler {
 	return &LendingClaimHandler{
 		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeLendingClaim),
 		walletRepo:  walletRepo,
+		access:      access,
 		logger:      log.WithField("component", "lending_claim"),
 	}
 }
@@ -51,5 +54,5 @@ func (h *LendingClaimHandler) ValidateData(ctx context.Context, data map[string]
 		return err
 	}
 
-	return validateWalletOwnership(ctx, h.walletRepo, txn.WalletID)
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
 }
@@ -0,0 +1,56 @@
+package lending
+
+import (
+	"context"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// LendingInterestHandler handles supply interest accrued into a lending deposit.
+type LendingInterestHandler struct {
+	ledger.BaseHandler
+	walletRepo WalletRepository
+	access     wallet.AccessChecker
+	logger     *logger.Logger
+}
+
+func NewLendingInterestHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *LendingInterestHandler {
+	return &LendingInterestHandler{
+		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeLendingInterest),
+		walletRepo:  walletRepo,
+		access:      access,
+		logger:      log.WithField("component", "lending_interest"),
+	}
+}
+
+func (h *LendingInterestHandler) Handle(ctx context.Context, data map[string]interface{}) ([]*ledger.Entry, error) {
+	var txn LendingTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return nil, err
+	}
+
+	if err := h.ValidateData(ctx, data); err != nil {
+		return nil, err
+	}
+
+	// Accruals happen without an on-chain transaction, so there is no gas
+	entries := generateInterestEntries(&txn)
+
+	h.logger.Debug("lending interest entries generated", "entry_count", len(entries))
+	return entries, nil
+}
+
+func (h *LendingInterestHandler) ValidateData(ctx context.Context, data map[string]interface{}) error {
+	var txn LendingTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return err
+	}
+
+	if err := txn.Validate(); err != nil {
+		return err
+	}
+
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
+}
@@ -0,0 +1,56 @@
+package lending
+
+import (
+	"context"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// LendingLiquidationHandler handles collateral seized by a liquidator to repay debt.
+type LendingLiquidationHandler struct {
+	ledger.BaseHandler
+	walletRepo WalletRepository
+	access     wallet.AccessChecker
+	logger     *logger.Logger
+}
+
+func NewLendingLiquidationHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *LendingLiquidationHandler {
+	return &LendingLiquidationHandler{
+		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeLendingLiquidation),
+		walletRepo:  walletRepo,
+		access:      access,
+		logger:      log.WithField("component", "lending_liquidation"),
+	}
+}
+
+func (h *LendingLiquidationHandler) Handle(ctx context.Context, data map[string]interface{}) ([]*ledger.Entry, error) {
+	var txn LendingLiquidation
+	if err := unmarshalData(data, &txn); err != nil {
+		return nil, err
+	}
+
+	if err := h.ValidateData(ctx, data); err != nil {
+		return nil, err
+	}
+
+	// The liquidator sends the transaction and pays its gas
+	entries := generateLiquidationEntries(&txn)
+
+	h.logger.Debug("lending liquidation entries generated", "entry_count", len(entries))
+	return entries, nil
+}
+
+func (h *LendingLiquidationHandler) ValidateData(ctx context.Context, data map[string]interface{}) error {
+	var txn LendingLiquidation
+	if err := unmarshalData(data, &txn); err != nil {
+		return err
+	}
+
+	if err := txn.Validate(); err != nil {
+		return err
+	}
+
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
 
@@ -11,13 +12,15 @@ import (
 type LendingRepayHandler struct {
 	ledger.BaseHandler
 	walletRepo WalletRepository
+	access     wallet.AccessChecker
 	logger     *logger.Logger
 }
 
-func NewLendingRepayHandler(walletRepo WalletRepository, log *logger.Logger) *LendingRepayHandler {
+func NewLendingRepayHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *LendingRepayHandler {
 	return &LendingRepayHandler{
 		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeLendingRepay),
 		walletRepo:  walletRepo,
+		access:      access,
 		logger:      log.WithField("component", "lending_repay"),
 	}
 }
@@ -51,5 +54,5 @@ func (h *LendingRepayHandler) ValidateData(ctx context.Context, data map[string]
 		return err
 	}
 
-	return validateWalletOwnership(ctx, h.walletRepo, txn.WalletID)
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
 
@@ -11,13 +12,15 @@ import (
 type LendingSupplyHandler struct {
 	ledger.BaseHandler
 	walletRepo WalletRepository
+	access     wallet.AccessChecker
 	logger     *logger.Logger
 }
 
-func NewLendingSupplyHandler(walletRepo WalletRepository, log *logger.Logger) *LendingSupplyHandler {
+func NewLendingSupplyHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *LendingSupplyHandler {
 	return &LendingSupplyHandler{
 		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeLendingSupply),
 		walletRepo:  walletRepo,
+		access:      access,
 		logger:      log.WithField("component", "lending_supply"),
 	}
 }
@@ -51,5 +54,5 @@ func (h *LendingSupplyHandler) ValidateData(ctx context.Context, data map[string
 		return err
 	}
 
-	return validateWalletOwnership(ctx, h.walletRepo, txn.WalletID)
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/kislikjeka/moontrack/internal/ledger"
 	"github.com/kislikjeka/moontrack/internal/module/lending"
 	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
 	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 	"github.com/kislikjeka/moontrack/pkg/money"
@@ -68,7 +69,24 @@ func buildTestData(walletID uuid.UUID) map[string]interface{} {
 	}
 }
 
-func setupHandler(t *testing.T) (uuid.UUID, uuid.UUID, *MockWalletRepository, *logger.Logger, context.Context) {
+// MockAccessChecker is a mock implementation of wallet.AccessChecker
+type MockAccessChecker struct {
+	mock.Mock
+}
+
+func (m *MockAccessChecker) Authorize(ctx context.Context, workspaceID, userID uuid.UUID, required workspace.Role) error {
+	return m.Called(ctx, workspaceID, userID, required).Error(0)
+}
+
+// newEditorAccess grants the editor role to userID and denies everyone else
+func newEditorAccess(userID uuid.UUID) *MockAccessChecker {
+	access := new(MockAccessChecker)
+	access.On("Authorize", mock.Anything, mock.Anything, userID, workspace.RoleEditor).Return(nil)
+	access.On("Authorize", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(workspace.ErrNotMember)
+	return access
+}
+
+func setupHandler(t *testing.T) (uuid.UUID, uuid.UUID, *MockWalletRepository, *MockAccessChecker, *logger.Logger, context.Context) {
 	t.Helper()
 	userID := uuid.New()
 	walletID := uuid.New()
@@ -79,15 +97,15 @@ func setupHandler(t *testing.T) (uuid.UUID, uuid.UUID, *MockWalletRepository, *l
 	log := logger.NewDefault("test")
 	ctx := context.WithValue(context.Background(), middleware.UserIDKey, userID)
 
-	return userID, walletID, mockRepo, log, ctx
+	return userID, walletID, mockRepo, newEditorAccess(userID), log, ctx
 }
 
 // === Supply Handler ===
 
 func TestLendingSupplyHandler_Handle(t *testing.T) {
-	_, walletID, mockRepo, log, ctx := setupHandler(t)
+	_, walletID, mockRepo, access, log, ctx := setupHandler(t)
 
-	handler := lending.NewLendingSupplyHandler(mockRepo, log)
+	handler := lending.NewLendingSupplyHandler(mockRepo, access, log)
 	assert.Equal(t, ledger.TxTypeLendingSupply, handler.Type())
 
 	data := buildTestData(walletID)
@@ -99,9 +117,9 @@ func TestLendingSupplyHandler_Handle(t *testing.T) {
 }
 
 func TestLendingSupplyHandler_WithGasFee(t *testing.T) {
-	_, walletID, mockRepo, log, ctx := setupHandler(t)
+	_, walletID, mockRepo, access, log, ctx := setupHandler(t)
 
-	handler := lending.NewLendingSupplyHandler(mockRepo, log)
+	handler := lending.NewLendingSupplyHandler(mockRepo, access, log)
 	data := buildTestData(walletID)
 	data["fee_asset"] = "ETH"
 	data["fee_amount"] = "500000000000000"
@@ -116,9 +134,9 @@ func TestLendingSupplyHandler_WithGasFee(t *testing.T) {
 }
 
 func TestLendingSupplyHandler_ValidateData_MissingAsset(t *testing.T) {
-	_, walletID, mockRepo, log, ctx := setupHandler(t)
+	_, walletID, mockRepo, access, log, ctx := setupHandler(t)
 
-	handler := lending.NewLendingSupplyHandler(mockRepo, log)
+	handler := lending.NewLendingSupplyHandler(mockRepo, access, log)
 	data := buildTestData(walletID)
 	delete(data, "asset")
 
@@ -127,13 +145,13 @@ func TestLendingSupplyHandler_ValidateData_MissingAsset(t *testing.T) {
 }
 
 func TestLendingSupplyHandler_ValidateData_Unauthorized(t *testing.T) {
-	_, walletID, _, log, _ := setupHandler(t)
+	_, walletID, _, access, log, _ := setupHandler(t)
 
 	attackerID := uuid.New()
 	mockRepo := new(MockWalletRepository)
 	mockRepo.On("GetByID", mock.Anything, walletID).Return(testWallet(walletID, uuid.New()), nil)
 
-	handler := lending.NewLendingSupplyHandler(mockRepo, log)
+	handler := lending.NewLendingSupplyHandler(mockRepo, access, log)
 	data := buildTestData(walletID)
 	ctx := context.WithValue(context.Background(), middleware.UserIDKey, attackerID)
 
@@ -144,9 +162,9 @@ func TestLendingSupplyHandler_ValidateData_Unauthorized(t *testing.T) {
 // === Withdraw Handler ===
 
 func TestLendingWithdrawHandler_Handle(t *testing.T) {
-	_, walletID, mockRepo, log, ctx := setupHandler(t)
+	_, walletID, mockRepo, access, log, ctx := setupHandler(t)
 
-	handler := lending.NewLendingWithdrawHandler(mockRepo, log)
+	handler := lending.NewLendingWithdrawHandler(mockRepo, access, log)
 	assert.Equal(t, ledger.TxTypeLendingWithdraw, handler.Type())
 
 	entries, err := handler.Handle(ctx, buildTestData(walletID))
@@ -159,9 +177,9 @@ func TestLendingWithdrawHandler_Handle(t *testing.T) {
 // === Borrow Handler ===
 
 func TestLendingBorrowHandler_Handle(t *testing.T) {
-	_, walletID, mockRepo, log, ctx := setupHandler(t)
+	_, walletID, mockRepo, access, log, ctx := setupHandler(t)
 
-	handler := lending.NewLendingBorrowHandler(mockRepo, log)
+	handler := lending.NewLendingBorrowHandler(mockRepo, access, log)
 	assert.Equal(t, ledger.TxTypeLendingBorrow, handler.Type())
 
 	data := buildTestData(walletID)
@@ -178,9 +196,9 @@ func TestLendingBorrowHandler_Handle(t *testing.T) {
 // === Repay Handler ===
 
 func TestLendingRepayHandler_Handle(t *testing.T) {
-	_, walletID, mockRepo, log, ctx := setupHandler(t)
+	_, walletID, mockRepo, access, log, ctx := setupHandler(t)
 
-	handler := lending.NewLendingRepayHandler(mockRepo, log)
+	handler := lending.NewLendingRepayHandler(mockRepo, access, log)
 	assert.Equal(t, ledger.TxTypeLendingRepay, handler.Type())
 
 	data := buildTestData(walletID)
@@ -197,9 +215,9 @@ func TestLendingRepayHandler_Handle(t *testing.T) {
 // === Claim Handler ===
 
 func TestLendingClaimHandler_Handle(t *testing.T) {
-	_, walletID, mockRepo, log, ctx := setupHandler(t)
+	_, walletID, mockRepo, access, log, ctx := setupHandler(t)
 
-	handler := lending.NewLendingClaimHandler(mockRepo, log)
+	handler := lending.NewLendingClaimHandler(mockRepo, access, log)
 	assert.Equal(t, ledger.TxTypeLendingClaim, handler.Type())
 
 	data := buildTestData(walletID)
@@ -212,6 +230,38 @@ func TestLendingClaimHandler_Handle(t *testing.T) {
 	assertEntriesBalanced(t, entries)
 }
 
+// === Liquidation Handler ===
+
+func TestLendingLiquidationHandler_Handle(t *testing.T) {
+	_, walletID, mockRepo, access, log, ctx := setupHandler(t)
+
+	handler := lending.NewLendingLiquidationHandler(mockRepo, access, log)
+	assert.Equal(t, ledger.TxTypeLendingLiquidation, handler.Type())
+
+	data := buildTestData(walletID)
+	data["debt_asset"] = "USDC"
+	data["debt_amount"] = "1900000000"
+	data["debt_decimals"] = float64(6)
+	data["debt_usd_price"] = "100000000"
+
+	entries, err := handler.Handle(ctx, data)
+
+	require.NoError(t, err)
+	// Collateral, clearing, penalty, liability, clearing; no gas
+	require.Len(t, entries, 5)
+	assertEntriesBalanced(t, entries)
+}
+
+func TestLendingLiquidationHandler_ValidateData_MissingDebt(t *testing.T) {
+	_, walletID, mockRepo, access, log, ctx := setupHandler(t)
+
+	handler := lending.NewLendingLiquidationHandler(mockRepo, access, log)
+	data := buildTestData(walletID)
+
+	err := handler.ValidateData(ctx, data)
+	assert.ErrorIs(t, err, lending.ErrInvalidDebtAsset)
+}
+
 // === Model Validation ===
 
 func TestLendingTransaction_Validate(t *testing.T) {
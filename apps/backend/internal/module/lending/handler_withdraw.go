@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
 
@@ -11,13 +12,15 @@ import (
 type LendingWithdrawHandler struct {
 	ledger.BaseHandler
 	walletRepo WalletRepository
+	access     wallet.AccessChecker
 	logger     *logger.Logger
 }
 
-func NewLendingWithdrawHandler(walletRepo WalletRepository, log *logger.Logger) *LendingWithdrawHandler {
+func NewLendingWithdrawHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *LendingWithdrawHandler {
 	return &LendingWithdrawHandler{
 		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeLendingWithdraw),
 		walletRepo:  walletRepo,
+		access:      access,
 		logger:      log.WithField("component", "lending_withdraw"),
 	}
 }
@@ -51,5 +54,5 @@ func (h *LendingWithdrawHandler) ValidateData(ctx context.Context, data map[stri
 		return err
 	}
 
-	return validateWalletOwnership(ctx, h.walletRepo, txn.WalletID)
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
 }
@@ -53,14 +53,14 @@ func setupLendingTest(t *testing.T) (*ledger.Service, *postgres.LedgerRepository
 	log := logger.New("test", io.Discard)
 
 	// Register transfer_in for seeding initial wallet balance
-	registry.Register(transfer.NewTransferInHandler(walletRepo, log))
+	registry.Register(transfer.NewTransferInHandler(walletRepo, nil, log))
 
 	// Register lending handlers
-	registry.Register(lending.NewLendingSupplyHandler(walletRepo, log))
-	registry.Register(lending.NewLendingWithdrawHandler(walletRepo, log))
-	registry.Register(lending.NewLendingBorrowHandler(walletRepo, log))
-	registry.Register(lending.NewLendingRepayHandler(walletRepo, log))
-	registry.Register(lending.NewLendingClaimHandler(walletRepo, log))
+	registry.Register(lending.NewLendingSupplyHandler(walletRepo, nil, log))
+	registry.Register(lending.NewLendingWithdrawHandler(walletRepo, nil, log))
+	registry.Register(lending.NewLendingBorrowHandler(walletRepo, nil, log))
+	registry.Register(lending.NewLendingRepayHandler(walletRepo, nil, log))
+	registry.Register(lending.NewLendingClaimHandler(walletRepo, nil, log))
 
 	svc := ledger.NewService(repo, registry, log)
 	return svc, repo, ctx
@@ -8,7 +8,8 @@ import (
 	"github.com/kislikjeka/moontrack/pkg/money"
 )
 
-// LendingTransaction represents an AAVE lending operation.
+// LendingTransaction represents an operation on a lending protocol (Aave,
+// Compound, Morpho, Spark).
 type LendingTransaction struct {
 	WalletID        uuid.UUID     `json:"wallet_id"`
 	TxHash          string        `json:"tx_hash"`
@@ -48,3 +49,32 @@ func (t *LendingTransaction) Validate() error {
 	}
 	return nil
 }
+
+// LendingLiquidation represents a liquidator repaying part of the debt and
+// seizing collateral in return. The embedded transaction describes the
+// collateral seized; the debt fields describe the debt that was repaid.
+type LendingLiquidation struct {
+	LendingTransaction
+	DebtAsset           string        `json:"debt_asset"`
+	DebtAmount          *money.BigInt `json:"debt_amount"`
+	DebtDecimals        int           `json:"debt_decimals"`
+	DebtUSDPrice        *money.BigInt `json:"debt_usd_price,omitempty"`
+	DebtContractAddress string        `json:"debt_contract_address,omitempty"`
+}
+
+// Validate validates the liquidation data.
+func (t *LendingLiquidation) Validate() error {
+	if err := t.LendingTransaction.Validate(); err != nil {
+		return err
+	}
+	if t.DebtAsset == "" {
+		return ErrInvalidDebtAsset
+	}
+	if t.DebtAmount == nil || t.DebtAmount.IsNil() || t.DebtAmount.Sign() <= 0 {
+		return ErrInvalidDebtAmount
+	}
+	if t.DebtDecimals <= 0 {
+		return ErrInvalidDecimals
+	}
+	return nil
+}
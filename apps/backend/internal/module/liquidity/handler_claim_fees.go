@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
 
@@ -11,13 +12,15 @@ import (
 type LPClaimFeesHandler struct {
 	ledger.BaseHandler
 	walletRepo WalletRepository
+	access     wallet.AccessChecker
 	logger     *logger.Logger
 }
 
-func NewLPClaimFeesHandler(walletRepo WalletRepository, log *logger.Logger) *LPClaimFeesHandler {
+func NewLPClaimFeesHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *LPClaimFeesHandler {
 	return &LPClaimFeesHandler{
 		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeLPClaimFees),
 		walletRepo:  walletRepo,
+		access:      access,
 		logger:      log.WithField("component", "lp_claim_fees"),
 	}
 }
@@ -49,5 +52,5 @@ func (h *LPClaimFeesHandler) ValidateData(ctx context.Context, data map[string]a
 		return err
 	}
 
-	return validateWalletOwnership(ctx, h.walletRepo, txn.WalletID)
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
 }
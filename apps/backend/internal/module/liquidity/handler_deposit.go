@@ -3,12 +3,14 @@ package liquidity
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
 	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
 	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
@@ -22,13 +24,15 @@ type WalletRepository interface {
 type LPDepositHandler struct {
 	ledger.BaseHandler
 	walletRepo WalletRepository
+	access     wallet.AccessChecker
 	logger     *logger.Logger
 }
 
-func NewLPDepositHandler(walletRepo WalletRepository, log *logger.Logger) *LPDepositHandler {
+func NewLPDepositHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *LPDepositHandler {
 	return &LPDepositHandler{
 		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeLPDeposit),
 		walletRepo:  walletRepo,
+		access:      access,
 		logger:      log.WithField("component", "lp_deposit"),
 	}
 }
@@ -60,7 +64,7 @@ func (h *LPDepositHandler) ValidateData(ctx context.Context, data map[string]any
 		return err
 	}
 
-	return validateWalletOwnership(ctx, h.walletRepo, txn.WalletID)
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
 }
 
 // unmarshalData converts map[string]any to a typed struct via JSON roundtrip.
@@ -75,8 +79,9 @@ func unmarshalData(data map[string]any, target any) error {
 	return nil
 }
 
-// validateWalletOwnership checks that the wallet exists and belongs to the authenticated user.
-func validateWalletOwnership(ctx context.Context, walletRepo WalletRepository, walletID uuid.UUID) error {
+// validateWalletAccess checks that the wallet exists and that the authenticated
+// user can record transactions on it.
+func validateWalletAccess(ctx context.Context, walletRepo WalletRepository, access wallet.AccessChecker, walletID uuid.UUID) error {
 	w, err := walletRepo.GetByID(ctx, walletID)
 	if err != nil {
 		return fmt.Errorf("failed to get wallet: %w", err)
@@ -85,11 +90,21 @@ func validateWalletOwnership(ctx context.Context, walletRepo WalletRepository, w
 		return ErrWalletNotFound
 	}
 
-	if userID, ok := middleware.GetUserIDFromContext(ctx); ok && userID != uuid.Nil {
-		if w.UserID != userID {
+	return authorizeWallet(ctx, access, w)
+}
+
+// authorizeWallet checks that the authenticated user, if any, holds the
+// editor role in the wallet's workspace.
+func authorizeWallet(ctx context.Context, access wallet.AccessChecker, w *wallet.Wallet) error {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok || userID == uuid.Nil {
+		return nil
+	}
+	if err := wallet.CheckAccess(ctx, access, w, userID, workspace.RoleEditor); err != nil {
+		if errors.Is(err, wallet.ErrUnauthorizedAccess) {
 			return ErrUnauthorized
 		}
+		return err
 	}
-
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
 
@@ -11,13 +12,15 @@ import (
 type LPWithdrawHandler struct {
 	ledger.BaseHandler
 	walletRepo WalletRepository
+	access     wallet.AccessChecker
 	logger     *logger.Logger
 }
 
-func NewLPWithdrawHandler(walletRepo WalletRepository, log *logger.Logger) *LPWithdrawHandler {
+func NewLPWithdrawHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *LPWithdrawHandler {
 	return &LPWithdrawHandler{
 		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeLPWithdraw),
 		walletRepo:  walletRepo,
+		access:      access,
 		logger:      log.WithField("component", "lp_withdraw"),
 	}
 }
@@ -49,5 +52,5 @@ func (h *LPWithdrawHandler) ValidateData(ctx context.Context, data map[string]an
 		return err
 	}
 
-	return validateWalletOwnership(ctx, h.walletRepo, txn.WalletID)
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
 }
@@ -0,0 +1,245 @@
+package nft
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// generateAcquireEntries generates entries for an NFT buy or mint. The
+// payments are exchanged for the NFTs through clearing, like a swap:
+//
+//	CREDIT wallet.{wID}.{chain}.{payment}  (asset_decrease)
+//	DEBIT  clearing.{chain}.{payment}      (clearing)
+//	DEBIT  wallet.{wID}.{chain}.{nft}      (asset_increase)
+//	CREDIT clearing.{chain}.{nft}          (clearing)
+//
+// Each NFT is valued at its share of the total paid, which becomes the cost
+// basis of its lot. A free mint has no payment legs.
+func generateAcquireEntries(txn *NFTTransaction) []*ledger.Entry {
+	var entries []*ledger.Entry
+	for i := range txn.Payments {
+		p := &txn.Payments[i]
+		entries = append(entries, paymentEntries(txn, p, false)...)
+	}
+	rates := nftRates(txn)
+	for i := range txn.NFTs {
+		entries = append(entries, nftExchangeEntries(txn, &txn.NFTs[i], rates[i], true)...)
+	}
+	return entries
+}
+
+// generateSellEntries generates entries for an NFT sale, the reverse of
+// generateAcquireEntries. Each NFT is valued at its share of the proceeds.
+//
+//	CREDIT wallet.{wID}.{chain}.{nft}      (asset_decrease)
+//	DEBIT  clearing.{chain}.{nft}          (clearing)
+//	DEBIT  wallet.{wID}.{chain}.{payment}  (asset_increase)
+//	CREDIT clearing.{chain}.{payment}      (clearing)
+func generateSellEntries(txn *NFTTransaction) []*ledger.Entry {
+	var entries []*ledger.Entry
+	rates := nftRates(txn)
+	for i := range txn.NFTs {
+		entries = append(entries, nftExchangeEntries(txn, &txn.NFTs[i], rates[i], false)...)
+	}
+	for i := range txn.Payments {
+		p := &txn.Payments[i]
+		entries = append(entries, paymentEntries(txn, p, true)...)
+	}
+	return entries
+}
+
+// generateTransferInEntries generates entries for an NFT received without
+// payment (airdrop, gift), valued at its market price when known.
+//
+//	DEBIT  wallet.{wID}.{chain}.{nft}  (asset_increase)
+//	CREDIT income.{chain}.{nft}        (income)
+func generateTransferInEntries(txn *NFTTransaction) []*ledger.Entry {
+	var entries []*ledger.Entry
+	for i := range txn.NFTs {
+		n := &txn.NFTs[i]
+		assetID := n.AssetID(txn.ChainID)
+		rate := priceOrZero(n.USDPrice)
+		entries = append(entries,
+			newEntry(txn, ledger.Debit, ledger.EntryTypeAssetIncrease, assetID, n.Amount(), rate, walletMeta(txn, assetID, n)),
+			newEntry(txn, ledger.Credit, ledger.EntryTypeIncome, assetID, n.Amount(), rate, map[string]interface{}{
+				"account_code": fmt.Sprintf("income.%s.%s", txn.ChainID, assetID),
+			}),
+		)
+	}
+	return entries
+}
+
+// generateTransferOutEntries generates entries for an NFT sent without
+// payment, the reverse of generateTransferInEntries.
+//
+//	DEBIT  expense.{chain}.{nft}        (expense)
+//	CREDIT wallet.{wID}.{chain}.{nft}   (asset_decrease)
+func generateTransferOutEntries(txn *NFTTransaction) []*ledger.Entry {
+	var entries []*ledger.Entry
+	for i := range txn.NFTs {
+		n := &txn.NFTs[i]
+		assetID := n.AssetID(txn.ChainID)
+		rate := priceOrZero(n.USDPrice)
+		entries = append(entries,
+			newEntry(txn, ledger.Debit, ledger.EntryTypeExpense, assetID, n.Amount(), rate, map[string]interface{}{
+				"account_code": fmt.Sprintf("expense.%s.%s", txn.ChainID, assetID),
+			}),
+			newEntry(txn, ledger.Credit, ledger.EntryTypeAssetDecrease, assetID, n.Amount(), rate, walletMeta(txn, assetID, n)),
+		)
+	}
+	return entries
+}
+
+// nftExchangeEntries moves one NFT into (acquire) or out of the wallet
+// against clearing.
+func nftExchangeEntries(txn *NFTTransaction, n *NFTItem, rate *big.Int, acquire bool) []*ledger.Entry {
+	assetID := n.AssetID(txn.ChainID)
+	walletSide, walletType, clearingSide := ledger.Debit, ledger.EntryTypeAssetIncrease, ledger.Credit
+	if !acquire {
+		walletSide, walletType, clearingSide = ledger.Credit, ledger.EntryTypeAssetDecrease, ledger.Debit
+	}
+	return []*ledger.Entry{
+		newEntry(txn, walletSide, walletType, assetID, n.Amount(), rate, walletMeta(txn, assetID, n)),
+		newEntry(txn, clearingSide, ledger.EntryTypeClearing, assetID, n.Amount(), rate, clearingMeta(txn, assetID)),
+	}
+}
+
+// paymentEntries moves one fungible payment out of (paid) or into
+// (received) the wallet against clearing.
+func paymentEntries(txn *NFTTransaction, p *Payment, received bool) []*ledger.Entry {
+	amount := p.Amount.ToBigInt()
+	rate := priceOrZero(p.USDPrice)
+	usdValue := money.CalcUSDValue(amount, rate, p.Decimals)
+
+	walletSide, walletType, clearingSide := ledger.Credit, ledger.EntryTypeAssetDecrease, ledger.Debit
+	if received {
+		walletSide, walletType, clearingSide = ledger.Debit, ledger.EntryTypeAssetIncrease, ledger.Credit
+	}
+
+	walletID := txn.WalletID.String()
+	wallet := newEntry(txn, walletSide, walletType, p.AssetSymbol, amount, rate, map[string]interface{}{
+		"wallet_id":        walletID,
+		"account_code":     fmt.Sprintf("wallet.%s.%s.%s", walletID, txn.ChainID, p.AssetSymbol),
+		"contract_address": p.ContractAddress,
+	})
+	clearing := newEntry(txn, clearingSide, ledger.EntryTypeClearing, p.AssetSymbol, amount, rate, clearingMeta(txn, p.AssetSymbol))
+	wallet.USDValue = usdValue
+	clearing.USDValue = new(big.Int).Set(usdValue)
+	return []*ledger.Entry{wallet, clearing}
+}
+
+// nftRates returns the USD rate per token of each NFT. The total USD value of
+// the payments is split across all tokens by quantity; without priced
+// payments each NFT falls back to its own market price.
+func nftRates(txn *NFTTransaction) []*big.Int {
+	total := big.NewInt(0)
+	for i := range txn.Payments {
+		p := &txn.Payments[i]
+		total.Add(total, money.CalcUSDValue(p.Amount.ToBigInt(), priceOrZero(p.USDPrice), p.Decimals))
+	}
+
+	units := big.NewInt(0)
+	for i := range txn.NFTs {
+		units.Add(units, txn.NFTs[i].Amount())
+	}
+
+	rates := make([]*big.Int, len(txn.NFTs))
+	for i := range txn.NFTs {
+		if total.Sign() > 0 {
+			rates[i] = new(big.Int).Div(total, units)
+		} else {
+			rates[i] = priceOrZero(txn.NFTs[i].USDPrice)
+		}
+	}
+	return rates
+}
+
+// generateGasFeeEntries generates gas fee entries if the transaction has a fee.
+//
+//	DEBIT  gas.{chain}.{feeAsset}          (gas_fee)
+//	CREDIT wallet.{wID}.{chain}.{feeAsset} (asset_decrease)
+func generateGasFeeEntries(txn *NFTTransaction) []*ledger.Entry {
+	if txn.FeeAmount == nil || txn.FeeAmount.IsNil() || txn.FeeAmount.Sign() <= 0 {
+		return nil
+	}
+
+	feeAmount := txn.FeeAmount.ToBigInt()
+	feeUSDRate := priceOrZero(txn.FeeUSDPrice)
+	feeDecimals := txn.FeeDecimals
+	if feeDecimals == 0 {
+		feeDecimals = 18
+	}
+	feeUSDValue := money.CalcUSDValue(feeAmount, feeUSDRate, feeDecimals)
+
+	walletID := txn.WalletID.String()
+	gas := newEntry(txn, ledger.Debit, ledger.EntryTypeGasFee, txn.FeeAsset, feeAmount, feeUSDRate, map[string]interface{}{
+		"account_code": fmt.Sprintf("gas.%s.%s", txn.ChainID, txn.FeeAsset),
+	})
+	wallet := newEntry(txn, ledger.Credit, ledger.EntryTypeAssetDecrease, txn.FeeAsset, feeAmount, feeUSDRate, map[string]interface{}{
+		"wallet_id":    walletID,
+		"account_code": fmt.Sprintf("wallet.%s.%s.%s", walletID, txn.ChainID, txn.FeeAsset),
+		"entry_type":   "gas_payment",
+	})
+	gas.USDValue = feeUSDValue
+	wallet.USDValue = new(big.Int).Set(feeUSDValue)
+	return []*ledger.Entry{gas, wallet}
+}
+
+// newEntry builds an entry valued at amount × rate. NFT amounts are whole
+// tokens, so the USD value is the rate times the quantity.
+func newEntry(txn *NFTTransaction, dc ledger.DebitCredit, et ledger.EntryType, assetID string, amount, rate *big.Int, meta map[string]interface{}) *ledger.Entry {
+	meta["tx_hash"] = txn.TxHash
+	meta["chain_id"] = txn.ChainID
+	if txn.Protocol != "" {
+		meta["protocol"] = txn.Protocol
+	}
+	return &ledger.Entry{
+		ID:          uuid.New(),
+		DebitCredit: dc,
+		EntryType:   et,
+		Amount:      new(big.Int).Set(amount),
+		AssetID:     assetID,
+		USDRate:     new(big.Int).Set(rate),
+		USDValue:    new(big.Int).Mul(amount, rate),
+		OccurredAt:  txn.OccurredAt,
+		CreatedAt:   time.Now().UTC(),
+		Metadata:    meta,
+	}
+}
+
+func walletMeta(txn *NFTTransaction, assetID string, n *NFTItem) map[string]interface{} {
+	walletID := txn.WalletID.String()
+	meta := map[string]interface{}{
+		"wallet_id":        walletID,
+		"account_code":     fmt.Sprintf("wallet.%s.%s.%s", walletID, txn.ChainID, assetID),
+		"contract_address": n.ContractAddress,
+		"nft_token_id":     n.TokenID,
+	}
+	if n.Name != "" {
+		meta["nft_name"] = n.Name
+	}
+	if n.Standard != "" {
+		meta["nft_standard"] = n.Standard
+	}
+	return meta
+}
+
+func clearingMeta(txn *NFTTransaction, assetID string) map[string]interface{} {
+	return map[string]interface{}{
+		"account_code": fmt.Sprintf("clearing.%s.%s", txn.ChainID, assetID),
+		"account_type": "CLEARING",
+	}
+}
+
+func priceOrZero(p *money.BigInt) *big.Int {
+	if p == nil || p.IsNil() {
+		return big.NewInt(0)
+	}
+	return p.ToBigInt()
+}
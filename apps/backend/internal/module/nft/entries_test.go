@@ -0,0 +1,143 @@
+package nft
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+func assertEntriesBalanced(t *testing.T, entries []*ledger.Entry) {
+	t.Helper()
+	debitSum := new(big.Int)
+	creditSum := new(big.Int)
+	for _, e := range entries {
+		if e.DebitCredit == ledger.Debit {
+			debitSum.Add(debitSum, e.Amount)
+		} else {
+			creditSum.Add(creditSum, e.Amount)
+		}
+	}
+	assert.Equal(t, 0, debitSum.Cmp(creditSum),
+		"entries must balance: debits=%s credits=%s", debitSum.String(), creditSum.String())
+}
+
+func baseTxn() *NFTTransaction {
+	return &NFTTransaction{
+		WalletID:   uuid.New(),
+		TxHash:     "0xabc123",
+		ChainID:    "ethereum",
+		OccurredAt: time.Now().UTC(),
+		Protocol:   "OpenSea",
+		NFTs: []NFTItem{
+			{ContractAddress: "0xBAYC", TokenID: "7", Name: "Bored Ape #7", Standard: "erc721"},
+		},
+		Payments: []Payment{
+			{
+				AssetSymbol: "ETH",
+				Amount:      money.NewBigInt(big.NewInt(2_000_000_000_000_000_000)), // 2 ETH
+				Decimals:    18,
+				USDPrice:    money.NewBigInt(big.NewInt(300_000_000_000)), // $3000
+			},
+		},
+	}
+}
+
+func findWalletEntry(entries []*ledger.Entry, assetID string) *ledger.Entry {
+	for _, e := range entries {
+		if e.AssetID == assetID && (e.EntryType == ledger.EntryTypeAssetIncrease || e.EntryType == ledger.EntryTypeAssetDecrease) {
+			return e
+		}
+	}
+	return nil
+}
+
+func TestGenerateAcquireEntries_NFTValuedAtPricePaid(t *testing.T) {
+	txn := baseTxn()
+	entries := generateAcquireEntries(txn)
+	require.Len(t, entries, 4)
+	assertEntriesBalanced(t, entries)
+
+	nft := findWalletEntry(entries, "NFT:ethereum:0xbayc:7")
+	require.NotNil(t, nft)
+	assert.Equal(t, ledger.EntryTypeAssetIncrease, nft.EntryType)
+	assert.Equal(t, int64(1), nft.Amount.Int64())
+	// 2 ETH × $3000 = $6000
+	assert.Equal(t, big.NewInt(600_000_000_000), nft.USDRate)
+	assert.Equal(t, big.NewInt(600_000_000_000), nft.USDValue)
+	assert.Equal(t, "7", nft.Metadata["nft_token_id"])
+	assert.Equal(t, "wallet."+txn.WalletID.String()+".ethereum.NFT:ethereum:0xbayc:7", nft.Metadata["account_code"])
+
+	eth := findWalletEntry(entries, "ETH")
+	require.NotNil(t, eth)
+	assert.Equal(t, ledger.EntryTypeAssetDecrease, eth.EntryType)
+	assert.Equal(t, big.NewInt(600_000_000_000), eth.USDValue)
+}
+
+func TestGenerateAcquireEntries_SweepSplitsCost(t *testing.T) {
+	txn := baseTxn()
+	txn.NFTs = append(txn.NFTs, NFTItem{ContractAddress: "0xBAYC", TokenID: "8"})
+
+	entries := generateAcquireEntries(txn)
+	require.Len(t, entries, 6)
+	assertEntriesBalanced(t, entries)
+
+	for _, id := range []string{"NFT:ethereum:0xbayc:7", "NFT:ethereum:0xbayc:8"} {
+		e := findWalletEntry(entries, id)
+		require.NotNil(t, e, id)
+		assert.Equal(t, big.NewInt(300_000_000_000), e.USDRate, id)
+	}
+}
+
+func TestGenerateAcquireEntries_FreeMintUsesMarketPrice(t *testing.T) {
+	txn := baseTxn()
+	txn.Payments = nil
+	txn.NFTs[0].USDPrice = money.NewBigInt(big.NewInt(5_000_000_000))
+
+	entries := generateAcquireEntries(txn)
+	require.Len(t, entries, 2)
+	assertEntriesBalanced(t, entries)
+	assert.Equal(t, big.NewInt(5_000_000_000), entries[0].USDRate)
+}
+
+func TestGenerateSellEntries_NFTValuedAtProceeds(t *testing.T) {
+	txn := baseTxn()
+	entries := generateSellEntries(txn)
+	require.Len(t, entries, 4)
+	assertEntriesBalanced(t, entries)
+
+	nft := findWalletEntry(entries, "NFT:ethereum:0xbayc:7")
+	require.NotNil(t, nft)
+	assert.Equal(t, ledger.EntryTypeAssetDecrease, nft.EntryType)
+	assert.Equal(t, ledger.Credit, nft.DebitCredit)
+	assert.Equal(t, big.NewInt(600_000_000_000), nft.USDRate)
+
+	eth := findWalletEntry(entries, "ETH")
+	require.NotNil(t, eth)
+	assert.Equal(t, ledger.EntryTypeAssetIncrease, eth.EntryType)
+}
+
+func TestGenerateTransferEntries(t *testing.T) {
+	txn := baseTxn()
+	txn.Payments = nil
+	txn.NFTs[0].Quantity = money.NewBigInt(big.NewInt(3)) // ERC-1155 batch
+
+	in := generateTransferInEntries(txn)
+	require.Len(t, in, 2)
+	assertEntriesBalanced(t, in)
+	assert.Equal(t, ledger.EntryTypeIncome, in[1].EntryType)
+	assert.Equal(t, "income.ethereum.NFT:ethereum:0xbayc:7", in[1].Metadata["account_code"])
+	assert.Equal(t, int64(3), in[0].Amount.Int64())
+
+	out := generateTransferOutEntries(txn)
+	require.Len(t, out, 2)
+	assertEntriesBalanced(t, out)
+	assert.Equal(t, ledger.EntryTypeExpense, out[0].EntryType)
+	assert.Equal(t, ledger.EntryTypeAssetDecrease, out[1].EntryType)
+}
@@ -0,0 +1,17 @@
+package nft
+
+import "errors"
+
+var (
+	ErrInvalidWalletID = errors.New("invalid wallet ID")
+	ErrInvalidChainID  = errors.New("invalid chain ID")
+	ErrInvalidTxHash   = errors.New("invalid transaction hash")
+	ErrNoNFTs          = errors.New("no NFTs in transaction")
+	ErrInvalidNFT      = errors.New("invalid NFT: contract address and token ID are required")
+	ErrInvalidQuantity = errors.New("invalid NFT quantity: must be positive")
+	ErrNoPayments      = errors.New("no payments in transaction")
+	ErrInvalidAsset    = errors.New("invalid payment asset: must not be empty")
+	ErrInvalidAmount   = errors.New("invalid payment amount: must be positive")
+	ErrWalletNotFound  = errors.New("wallet not found")
+	ErrUnauthorized    = errors.New("unauthorized: user cannot record transactions on wallet")
+)
@@ -0,0 +1,62 @@
+package nft
+
+import (
+	"context"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// NFTBuyHandler handles NFT purchases paid with fungible tokens.
+type NFTBuyHandler struct {
+	ledger.BaseHandler
+	walletRepo WalletRepository
+	access     wallet.AccessChecker
+	logger     *logger.Logger
+}
+
+func NewNFTBuyHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *NFTBuyHandler {
+	return &NFTBuyHandler{
+		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeNFTBuy),
+		walletRepo:  walletRepo,
+		access:      access,
+		logger:      log.WithField("component", "nft_buy"),
+	}
+}
+
+func (h *NFTBuyHandler) Handle(ctx context.Context, data map[string]interface{}) ([]*ledger.Entry, error) {
+	var txn NFTTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return nil, err
+	}
+
+	if err := h.ValidateData(ctx, data); err != nil {
+		return nil, err
+	}
+
+	entries := generateAcquireEntries(&txn)
+	if gasEntries := generateGasFeeEntries(&txn); gasEntries != nil {
+		entries = append(entries, gasEntries...)
+	}
+
+	h.logger.Debug("nft buy entries generated", "nft_count", len(txn.NFTs), "entry_count", len(entries))
+	return entries, nil
+}
+
+func (h *NFTBuyHandler) ValidateData(ctx context.Context, data map[string]interface{}) error {
+	var txn NFTTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return err
+	}
+
+	if err := txn.Validate(); err != nil {
+		return err
+	}
+
+	if len(txn.Payments) == 0 {
+		return ErrNoPayments
+	}
+
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
+}
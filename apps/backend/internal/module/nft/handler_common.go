@@ -0,0 +1,58 @@
+package nft
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
+	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+)
+
+// WalletRepository defines the interface for wallet operations.
+type WalletRepository interface {
+	GetByID(ctx context.Context, walletID uuid.UUID) (*wallet.Wallet, error)
+}
+
+func unmarshalData(data map[string]interface{}, out *NFTTransaction) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transaction data: %w", err)
+	}
+	if err := json.Unmarshal(jsonData, out); err != nil {
+		return fmt.Errorf("failed to unmarshal transaction data: %w", err)
+	}
+	return nil
+}
+
+func validateWalletAccess(ctx context.Context, walletRepo WalletRepository, access wallet.AccessChecker, walletID uuid.UUID) error {
+	w, err := walletRepo.GetByID(ctx, walletID)
+	if err != nil {
+		return fmt.Errorf("failed to get wallet: %w", err)
+	}
+	if w == nil {
+		return ErrWalletNotFound
+	}
+
+	return authorizeWallet(ctx, access, w)
+}
+
+// authorizeWallet checks that the authenticated user, if any, holds the
+// editor role in the wallet's workspace.
+func authorizeWallet(ctx context.Context, access wallet.AccessChecker, w *wallet.Wallet) error {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok || userID == uuid.Nil {
+		return nil
+	}
+	if err := wallet.CheckAccess(ctx, access, w, userID, workspace.RoleEditor); err != nil {
+		if errors.Is(err, wallet.ErrUnauthorizedAccess) {
+			return ErrUnauthorized
+		}
+		return err
+	}
+	return nil
+}
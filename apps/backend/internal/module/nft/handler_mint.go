@@ -0,0 +1,58 @@
+package nft
+
+import (
+	"context"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// NFTMintHandler handles NFT mints, free or paid.
+type NFTMintHandler struct {
+	ledger.BaseHandler
+	walletRepo WalletRepository
+	access     wallet.AccessChecker
+	logger     *logger.Logger
+}
+
+func NewNFTMintHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *NFTMintHandler {
+	return &NFTMintHandler{
+		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeNFTMint),
+		walletRepo:  walletRepo,
+		access:      access,
+		logger:      log.WithField("component", "nft_mint"),
+	}
+}
+
+func (h *NFTMintHandler) Handle(ctx context.Context, data map[string]interface{}) ([]*ledger.Entry, error) {
+	var txn NFTTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return nil, err
+	}
+
+	if err := h.ValidateData(ctx, data); err != nil {
+		return nil, err
+	}
+
+	entries := generateAcquireEntries(&txn)
+	if gasEntries := generateGasFeeEntries(&txn); gasEntries != nil {
+		entries = append(entries, gasEntries...)
+	}
+
+	h.logger.Debug("nft mint entries generated", "nft_count", len(txn.NFTs), "entry_count", len(entries))
+	return entries, nil
+}
+
+func (h *NFTMintHandler) ValidateData(ctx context.Context, data map[string]interface{}) error {
+	var txn NFTTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return err
+	}
+
+	if err := txn.Validate(); err != nil {
+		return err
+	}
+
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
+}
@@ -0,0 +1,62 @@
+package nft
+
+import (
+	"context"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// NFTSellHandler handles NFT sales for fungible tokens.
+type NFTSellHandler struct {
+	ledger.BaseHandler
+	walletRepo WalletRepository
+	access     wallet.AccessChecker
+	logger     *logger.Logger
+}
+
+func NewNFTSellHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *NFTSellHandler {
+	return &NFTSellHandler{
+		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeNFTSell),
+		walletRepo:  walletRepo,
+		access:      access,
+		logger:      log.WithField("component", "nft_sell"),
+	}
+}
+
+func (h *NFTSellHandler) Handle(ctx context.Context, data map[string]interface{}) ([]*ledger.Entry, error) {
+	var txn NFTTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return nil, err
+	}
+
+	if err := h.ValidateData(ctx, data); err != nil {
+		return nil, err
+	}
+
+	entries := generateSellEntries(&txn)
+	if gasEntries := generateGasFeeEntries(&txn); gasEntries != nil {
+		entries = append(entries, gasEntries...)
+	}
+
+	h.logger.Debug("nft sell entries generated", "nft_count", len(txn.NFTs), "entry_count", len(entries))
+	return entries, nil
+}
+
+func (h *NFTSellHandler) ValidateData(ctx context.Context, data map[string]interface{}) error {
+	var txn NFTTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return err
+	}
+
+	if err := txn.Validate(); err != nil {
+		return err
+	}
+
+	if len(txn.Payments) == 0 {
+		return ErrNoPayments
+	}
+
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
+}
@@ -0,0 +1,136 @@
+package nft_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/module/nft"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
+	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+type MockWalletRepository struct {
+	mock.Mock
+}
+
+func (m *MockWalletRepository) GetByID(ctx context.Context, walletID uuid.UUID) (*wallet.Wallet, error) {
+	args := m.Called(ctx, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Wallet), args.Error(1)
+}
+
+func buildTestData(walletID uuid.UUID) map[string]interface{} {
+	return map[string]interface{}{
+		"wallet_id":   walletID.String(),
+		"tx_hash":     "0xtest123",
+		"chain_id":    "ethereum",
+		"occurred_at": time.Now().UTC().Format(time.RFC3339),
+		"protocol":    "OpenSea",
+		"nfts": []interface{}{
+			map[string]interface{}{"contract_address": "0xbayc", "token_id": "7", "standard": "erc721"},
+		},
+		"payments": []interface{}{
+			map[string]interface{}{
+				"asset_symbol": "ETH",
+				"amount":       "1000000000000000000",
+				"decimals":     float64(18),
+				"usd_price":    "300000000000",
+			},
+		},
+	}
+}
+
+// MockAccessChecker is a mock implementation of wallet.AccessChecker
+type MockAccessChecker struct {
+	mock.Mock
+}
+
+func (m *MockAccessChecker) Authorize(ctx context.Context, workspaceID, userID uuid.UUID, required workspace.Role) error {
+	return m.Called(ctx, workspaceID, userID, required).Error(0)
+}
+
+// newEditorAccess grants the editor role to userID and denies everyone else
+func newEditorAccess(userID uuid.UUID) *MockAccessChecker {
+	access := new(MockAccessChecker)
+	access.On("Authorize", mock.Anything, mock.Anything, userID, workspace.RoleEditor).Return(nil)
+	access.On("Authorize", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(workspace.ErrNotMember)
+	return access
+}
+
+func setupHandler(t *testing.T) (uuid.UUID, *MockWalletRepository, *MockAccessChecker, *logger.Logger, context.Context) {
+	t.Helper()
+	userID := uuid.New()
+	walletID := uuid.New()
+
+	mockRepo := new(MockWalletRepository)
+	mockRepo.On("GetByID", mock.Anything, walletID).Return(&wallet.Wallet{ID: walletID, UserID: userID}, nil)
+
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, userID)
+	return walletID, mockRepo, newEditorAccess(userID), logger.NewDefault("test"), ctx
+}
+
+func TestNFTBuyHandler_WithGasFee(t *testing.T) {
+	walletID, mockRepo, access, log, ctx := setupHandler(t)
+
+	handler := nft.NewNFTBuyHandler(mockRepo, access, log)
+	assert.Equal(t, ledger.TxTypeNFTBuy, handler.Type())
+
+	data := buildTestData(walletID)
+	data["fee_asset"] = "ETH"
+	data["fee_amount"] = "500000000000000"
+	data["fee_decimals"] = float64(18)
+
+	entries, err := handler.Handle(ctx, data)
+	require.NoError(t, err)
+	require.Len(t, entries, 6) // 4 exchange + 2 gas
+}
+
+func TestNFTBuyHandler_RequiresPayment(t *testing.T) {
+	walletID, mockRepo, access, log, ctx := setupHandler(t)
+
+	data := buildTestData(walletID)
+	delete(data, "payments")
+
+	_, err := nft.NewNFTBuyHandler(mockRepo, access, log).Handle(ctx, data)
+	assert.ErrorIs(t, err, nft.ErrNoPayments)
+}
+
+func TestNFTMintHandler_FreeMint(t *testing.T) {
+	walletID, mockRepo, access, log, ctx := setupHandler(t)
+
+	data := buildTestData(walletID)
+	delete(data, "payments")
+
+	entries, err := nft.NewNFTMintHandler(mockRepo, access, log).Handle(ctx, data)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+}
+
+func TestNFTTransferInHandler_RejectsMissingTokenID(t *testing.T) {
+	walletID, mockRepo, access, log, ctx := setupHandler(t)
+
+	data := buildTestData(walletID)
+	data["nfts"] = []interface{}{map[string]interface{}{"contract_address": "0xbayc"}}
+
+	_, err := nft.NewNFTTransferInHandler(mockRepo, access, log).Handle(ctx, data)
+	assert.ErrorIs(t, err, nft.ErrInvalidNFT)
+}
+
+func TestNFTSellHandler_Unauthorized(t *testing.T) {
+	walletID, mockRepo, access, log, _ := setupHandler(t)
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, uuid.New())
+
+	_, err := nft.NewNFTSellHandler(mockRepo, access, log).Handle(ctx, buildTestData(walletID))
+	assert.ErrorIs(t, err, nft.ErrUnauthorized)
+}
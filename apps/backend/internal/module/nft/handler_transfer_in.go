@@ -0,0 +1,58 @@
+package nft
+
+import (
+	"context"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// NFTTransferInHandler handles NFTs received without payment.
+type NFTTransferInHandler struct {
+	ledger.BaseHandler
+	walletRepo WalletRepository
+	access     wallet.AccessChecker
+	logger     *logger.Logger
+}
+
+func NewNFTTransferInHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *NFTTransferInHandler {
+	return &NFTTransferInHandler{
+		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeNFTTransferIn),
+		walletRepo:  walletRepo,
+		access:      access,
+		logger:      log.WithField("component", "nft_transfer_in"),
+	}
+}
+
+func (h *NFTTransferInHandler) Handle(ctx context.Context, data map[string]interface{}) ([]*ledger.Entry, error) {
+	var txn NFTTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return nil, err
+	}
+
+	if err := h.ValidateData(ctx, data); err != nil {
+		return nil, err
+	}
+
+	entries := generateTransferInEntries(&txn)
+	if gasEntries := generateGasFeeEntries(&txn); gasEntries != nil {
+		entries = append(entries, gasEntries...)
+	}
+
+	h.logger.Debug("nft transfer in entries generated", "nft_count", len(txn.NFTs), "entry_count", len(entries))
+	return entries, nil
+}
+
+func (h *NFTTransferInHandler) ValidateData(ctx context.Context, data map[string]interface{}) error {
+	var txn NFTTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return err
+	}
+
+	if err := txn.Validate(); err != nil {
+		return err
+	}
+
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
+}
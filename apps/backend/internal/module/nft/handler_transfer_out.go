@@ -0,0 +1,58 @@
+package nft
+
+import (
+	"context"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// NFTTransferOutHandler handles NFTs sent without payment.
+type NFTTransferOutHandler struct {
+	ledger.BaseHandler
+	walletRepo WalletRepository
+	access     wallet.AccessChecker
+	logger     *logger.Logger
+}
+
+func NewNFTTransferOutHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *NFTTransferOutHandler {
+	return &NFTTransferOutHandler{
+		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeNFTTransferOut),
+		walletRepo:  walletRepo,
+		access:      access,
+		logger:      log.WithField("component", "nft_transfer_out"),
+	}
+}
+
+func (h *NFTTransferOutHandler) Handle(ctx context.Context, data map[string]interface{}) ([]*ledger.Entry, error) {
+	var txn NFTTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return nil, err
+	}
+
+	if err := h.ValidateData(ctx, data); err != nil {
+		return nil, err
+	}
+
+	entries := generateTransferOutEntries(&txn)
+	if gasEntries := generateGasFeeEntries(&txn); gasEntries != nil {
+		entries = append(entries, gasEntries...)
+	}
+
+	h.logger.Debug("nft transfer out entries generated", "nft_count", len(txn.NFTs), "entry_count", len(entries))
+	return entries, nil
+}
+
+func (h *NFTTransferOutHandler) ValidateData(ctx context.Context, data map[string]interface{}) error {
+	var txn NFTTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return err
+	}
+
+	if err := txn.Validate(); err != nil {
+		return err
+	}
+
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
+}
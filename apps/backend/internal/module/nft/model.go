@@ -0,0 +1,110 @@
+package nft
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// NFTTransaction represents an NFT buy, sell, mint or transfer.
+//
+// NFTs lists the tokens that moved; Payments lists the fungible tokens paid
+// (buy, mint) or received (sell) for them. Transfers carry no payments.
+type NFTTransaction struct {
+	WalletID    uuid.UUID     `json:"wallet_id"`
+	TxHash      string        `json:"tx_hash"`
+	ChainID     string        `json:"chain_id"`
+	OccurredAt  time.Time     `json:"occurred_at"`
+	Protocol    string        `json:"protocol,omitempty"`
+	NFTs        []NFTItem     `json:"nfts"`
+	Payments    []Payment     `json:"payments,omitempty"`
+	FeeAsset    string        `json:"fee_asset,omitempty"`
+	FeeAmount   *money.BigInt `json:"fee_amount,omitempty"`
+	FeeDecimals int           `json:"fee_decimals,omitempty"`
+	FeeUSDPrice *money.BigInt `json:"fee_usd_price,omitempty"`
+}
+
+// NFTItem is a single NFT (or ERC-1155 batch of one token ID) that moved
+type NFTItem struct {
+	ContractAddress string        `json:"contract_address"`
+	TokenID         string        `json:"token_id"`
+	Name            string        `json:"name,omitempty"`
+	Standard        string        `json:"standard,omitempty"` // "erc721" or "erc1155"
+	Quantity        *money.BigInt `json:"quantity,omitempty"` // defaults to 1
+	USDPrice        *money.BigInt `json:"usd_price,omitempty"`
+}
+
+// Payment is a fungible token paid or received for the NFTs
+type Payment struct {
+	AssetSymbol     string        `json:"asset_symbol"`
+	Amount          *money.BigInt `json:"amount"`
+	Decimals        int           `json:"decimals"`
+	USDPrice        *money.BigInt `json:"usd_price,omitempty"`
+	ContractAddress string        `json:"contract_address,omitempty"`
+}
+
+// AssetID returns the ledger asset ID of the NFT on the given chain
+func (n *NFTItem) AssetID(chainID string) string {
+	return ledger.NFTAssetID(chainID, n.ContractAddress, n.TokenID)
+}
+
+// Amount returns the number of tokens moved, 1 unless set
+func (n *NFTItem) Amount() *big.Int {
+	if n.Quantity == nil || n.Quantity.IsNil() {
+		return big.NewInt(1)
+	}
+	return n.Quantity.ToBigInt()
+}
+
+// Validate validates the NFT transaction data
+func (t *NFTTransaction) Validate() error {
+	if t.WalletID == uuid.Nil {
+		return ErrInvalidWalletID
+	}
+	if t.TxHash == "" {
+		return ErrInvalidTxHash
+	}
+	if t.ChainID == "" {
+		return ErrInvalidChainID
+	}
+	if len(t.NFTs) == 0 {
+		return ErrNoNFTs
+	}
+	for i := range t.NFTs {
+		if err := t.NFTs[i].Validate(); err != nil {
+			return err
+		}
+	}
+	for i := range t.Payments {
+		if err := t.Payments[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate validates a single NFT item
+func (n *NFTItem) Validate() error {
+	if n.ContractAddress == "" || n.TokenID == "" {
+		return ErrInvalidNFT
+	}
+	if n.Amount().Sign() <= 0 {
+		return ErrInvalidQuantity
+	}
+	return nil
+}
+
+// Validate validates a single payment
+func (p *Payment) Validate() error {
+	if p.AssetSymbol == "" {
+		return ErrInvalidAsset
+	}
+	if p.Amount == nil || p.Amount.IsNil() || p.Amount.Sign() <= 0 {
+		return ErrInvalidAmount
+	}
+	return nil
+}
@@ -9,7 +9,7 @@ import (
 
 // WalletRepositoryInterface is the interface for the wallet repository
 type WalletRepositoryInterface interface {
-	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*wallet.Wallet, error)
+	GetAccessibleByUserID(ctx context.Context, userID uuid.UUID, workspaceID *uuid.UUID) ([]*wallet.Wallet, error)
 }
 
 // WalletRepositoryAdapter adapts the wallet repository to the portfolio service interface
@@ -22,9 +22,9 @@ func NewWalletRepositoryAdapter(repo WalletRepositoryInterface) *WalletRepositor
 	return &WalletRepositoryAdapter{repo: repo}
 }
 
-// GetByUserID returns wallets for a user, converting to the portfolio service's Wallet type
+// GetByUserID returns wallets in all workspaces the user belongs to, converting to the portfolio service's Wallet type
 func (a *WalletRepositoryAdapter) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*Wallet, error) {
-	wallets, err := a.repo.GetByUserID(ctx, userID)
+	wallets, err := a.repo.GetAccessibleByUserID(ctx, userID, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -3,7 +3,9 @@ package portfolio
 import (
 	"context"
 	"math/big"
+	"time"
 
+	"github.com/kislikjeka/moontrack/internal/ledger"
 	"github.com/kislikjeka/moontrack/internal/platform/asset"
 )
 
@@ -11,30 +13,33 @@ import (
 type AssetServiceInterface interface {
 	GetAssetsBySymbol(ctx context.Context, symbol string) ([]asset.Asset, error)
 	GetCurrentPriceByCoinGeckoID(ctx context.Context, coinGeckoID string) (*big.Int, error)
+	GetHistoricalPriceByCoinGeckoID(ctx context.Context, coinGeckoID string, date time.Time) (*big.Int, error)
+}
+
+// FloorPriceProvider supplies collection floor prices for NFTs (USD scaled by 10^8).
+type FloorPriceProvider interface {
+	GetFloorPrice(ctx context.Context, chainID, contractAddress, tokenID string) (*big.Int, error)
 }
 
 // PortfolioPriceAdapter resolves asset symbols to CoinGecko IDs before price lookup.
+// NFT assets are valued at their collection floor price instead.
 type PortfolioPriceAdapter struct {
-	assetSvc AssetServiceInterface
+	assetSvc    AssetServiceInterface
+	floorPrices FloorPriceProvider // nilable — NFTs are valued at zero without it
 }
 
 // NewPortfolioPriceAdapter creates a new portfolio price adapter.
-func NewPortfolioPriceAdapter(assetSvc AssetServiceInterface) *PortfolioPriceAdapter {
-	return &PortfolioPriceAdapter{assetSvc: assetSvc}
+func NewPortfolioPriceAdapter(assetSvc AssetServiceInterface, floorPrices FloorPriceProvider) *PortfolioPriceAdapter {
+	return &PortfolioPriceAdapter{assetSvc: assetSvc, floorPrices: floorPrices}
 }
 
 // GetPriceBySymbol resolves symbol → CoinGecko ID → price.
 func (a *PortfolioPriceAdapter) GetPriceBySymbol(ctx context.Context, symbol string) (*big.Int, error) {
-	coinGeckoID := symbolToCoinGeckoID(symbol)
-	if coinGeckoID == "" {
-		// ERC-20 fallback: look up by symbol in asset DB
-		assets, err := a.assetSvc.GetAssetsBySymbol(ctx, symbol)
-		if err != nil || len(assets) == 0 {
-			return big.NewInt(0), nil
-		}
-		coinGeckoID = assets[0].CoinGeckoID
+	if chainID, contract, tokenID, ok := ledger.ParseNFTAssetID(symbol); ok {
+		return a.getFloorPrice(ctx, chainID, contract, tokenID), nil
 	}
 
+	coinGeckoID := a.resolveCoinGeckoID(ctx, symbol)
 	if coinGeckoID == "" {
 		return big.NewInt(0), nil
 	}
@@ -47,6 +52,52 @@ func (a *PortfolioPriceAdapter) GetPriceBySymbol(ctx context.Context, symbol str
 	return price, nil
 }
 
+// GetPriceBySymbolAt resolves symbol → CoinGecko ID → daily price at the given time.
+// NFTs have no floor price history and return zero.
+func (a *PortfolioPriceAdapter) GetPriceBySymbolAt(ctx context.Context, symbol string, at time.Time) (*big.Int, error) {
+	if ledger.IsNFTAsset(symbol) {
+		return big.NewInt(0), nil
+	}
+
+	coinGeckoID := a.resolveCoinGeckoID(ctx, symbol)
+	if coinGeckoID == "" {
+		return big.NewInt(0), nil
+	}
+
+	price, err := a.assetSvc.GetHistoricalPriceByCoinGeckoID(ctx, coinGeckoID, at)
+	if err != nil || price == nil {
+		return big.NewInt(0), nil
+	}
+
+	return price, nil
+}
+
+// resolveCoinGeckoID maps a symbol to its CoinGecko ID, or "" when unknown.
+func (a *PortfolioPriceAdapter) resolveCoinGeckoID(ctx context.Context, symbol string) string {
+	if coinGeckoID := symbolToCoinGeckoID(symbol); coinGeckoID != "" {
+		return coinGeckoID
+	}
+
+	// ERC-20 fallback: look up by symbol in asset DB
+	assets, err := a.assetSvc.GetAssetsBySymbol(ctx, symbol)
+	if err != nil || len(assets) == 0 {
+		return ""
+	}
+	return assets[0].CoinGeckoID
+}
+
+// getFloorPrice returns an NFT's floor price, or zero when unavailable.
+func (a *PortfolioPriceAdapter) getFloorPrice(ctx context.Context, chainID, contract, tokenID string) *big.Int {
+	if a.floorPrices == nil {
+		return big.NewInt(0)
+	}
+	price, err := a.floorPrices.GetFloorPrice(ctx, chainID, contract, tokenID)
+	if err != nil || price == nil {
+		return big.NewInt(0)
+	}
+	return price
+}
+
 // symbolToCoinGeckoID maps common native asset symbols to CoinGecko IDs.
 func symbolToCoinGeckoID(symbol string) string {
 	switch symbol {
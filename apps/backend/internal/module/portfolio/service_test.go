@@ -144,6 +144,47 @@ func TestPortfolioService_HandlesPriceAPIFailure(t *testing.T) {
 
 // Helper functions
 
+// TestPortfolioService_ValuesNFTsAtFloorPrice verifies NFTs are counted in whole
+// tokens and valued at the floor price supplied by the price adapter
+func TestPortfolioService_ValuesNFTsAtFloorPrice(t *testing.T) {
+	ctx := context.Background()
+
+	ledgerRepo := setupMockLedgerRepository()
+	walletRepo := setupMockWalletRepository()
+	floors := &mockFloorPrices{prices: map[string]*big.Int{
+		"ethereum:0xbayc:7": big.NewInt(2500000000000), // $25,000 floor
+	}}
+	priceAdapter := NewPortfolioPriceAdapter(nil, floors)
+	portfolioService := NewPortfolioService(ledgerRepo, walletRepo, priceAdapter, nil, nil)
+
+	userID := uuid.New()
+	walletID := uuid.New()
+	accountID := uuid.New()
+	nftAsset := ledger.NFTAssetID("ethereum", "0xbayc", "7")
+
+	walletRepo.SetMockWallets(userID, []*Wallet{{ID: walletID, UserID: userID, Name: "Wallet"}})
+	ledgerRepo.SetMockAccounts(walletID, []*ledger.Account{{ID: accountID, WalletID: &walletID, AssetID: nftAsset}})
+	ledgerRepo.SetMockBalances(accountID, []*ledger.AccountBalance{{AssetID: nftAsset, Balance: big.NewInt(1)}})
+
+	portfolio, err := portfolioService.GetPortfolioSummary(ctx, userID)
+
+	require.NoError(t, err)
+	require.Len(t, portfolio.AssetHoldings, 1)
+	assert.Equal(t, 0, portfolio.AssetHoldings[0].Decimals)
+	assert.Equal(t, "2500000000000", portfolio.TotalUSDValue.String())
+}
+
+type mockFloorPrices struct {
+	prices map[string]*big.Int
+}
+
+func (m *mockFloorPrices) GetFloorPrice(ctx context.Context, chainID, contractAddress, tokenID string) (*big.Int, error) {
+	if p, ok := m.prices[chainID+":"+contractAddress+":"+tokenID]; ok {
+		return p, nil
+	}
+	return nil, errors.New("no floor")
+}
+
 func setupMockLedgerRepository() *MockLedgerRepository {
 	return &MockLedgerRepository{
 		accounts:        make(map[uuid.UUID][]*ledger.Account),
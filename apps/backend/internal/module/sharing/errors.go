@@ -0,0 +1,15 @@
+package sharing
+
+import "errors"
+
+var (
+	ErrInvalidScope     = errors.New("invalid share scope")
+	ErrMissingScope     = errors.New("at least one scope is required")
+	ErrInvalidTTL       = errors.New("share link lifetime must be positive and at most 90 days")
+	ErrLabelTooLong     = errors.New("share link label exceeds 100 characters")
+	ErrInvalidToken     = errors.New("invalid share token")
+	ErrLinkNotFound     = errors.New("share link not found")
+	ErrLinkInactive     = errors.New("share link has expired or was revoked")
+	ErrScopeNotShared   = errors.New("scope is not included in this share link")
+	ErrWalletNotVisible = errors.New("wallet not found or not accessible")
+)
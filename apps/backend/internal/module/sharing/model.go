@@ -0,0 +1,127 @@
+package sharing
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scope is a section of the portfolio a share link exposes
+type Scope string
+
+const (
+	ScopePortfolio Scope = "portfolio" // Aggregated asset allocation
+	ScopeWallets   Scope = "wallets"   // Per-wallet holdings
+	ScopeLots      Scope = "lots"      // Open tax lots for held assets
+)
+
+// IsValid checks if the scope is valid
+func (s Scope) IsValid() bool {
+	switch s {
+	case ScopePortfolio, ScopeWallets, ScopeLots:
+		return true
+	}
+	return false
+}
+
+// Default and maximum lifetime of a share link
+const (
+	DefaultTTL = 7 * 24 * time.Hour
+	MaxTTL     = 90 * 24 * time.Hour
+)
+
+// ShareLink grants unauthenticated, read-only access to part of a user's portfolio.
+// The token itself is not stored; it is a signed reference to the link ID.
+type ShareLink struct {
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	Label          string
+	Scopes         []Scope
+	WalletIDs      []uuid.UUID // Empty means every wallet the creator can view
+	HideAmounts    bool        // Expose percentages only
+	ExpiresAt      time.Time
+	RevokedAt      *time.Time
+	LastAccessedAt *time.Time
+	CreatedAt      time.Time
+}
+
+// HasScope reports whether the link exposes the given scope
+func (l *ShareLink) HasScope(scope Scope) bool {
+	for _, s := range l.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive returns true if the link is neither revoked nor expired
+func (l *ShareLink) IsActive(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
+
+// includesWallet reports whether the wallet is within the link's scope
+func (l *ShareLink) includesWallet(walletID uuid.UUID) bool {
+	if len(l.WalletIDs) == 0 {
+		return true
+	}
+	for _, id := range l.WalletIDs {
+		if id == walletID {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateParams holds the inputs for creating a share link
+type CreateParams struct {
+	Label       string
+	Scopes      []Scope
+	WalletIDs   []uuid.UUID
+	HideAmounts bool
+	TTL         time.Duration // Zero means DefaultTTL
+}
+
+// PortfolioSnapshot is the shared view of a portfolio.
+// Absolute fields are nil when the link hides amounts.
+type PortfolioSnapshot struct {
+	TotalUSDValue *big.Int
+	Assets        []AssetShare
+	Wallets       []WalletShare // Only populated for the wallets scope
+	HideAmounts   bool
+	GeneratedAt   time.Time
+}
+
+// AssetShare is one asset's position and share of the snapshot total
+type AssetShare struct {
+	AssetID  string
+	ChainID  string // Set for per-wallet entries only
+	Amount   *big.Int
+	USDValue *big.Int
+	Price    *big.Int
+	Decimals int
+	Percent  string // Share of the parent total, e.g. "12.34"
+}
+
+// WalletShare is one wallet's holdings and share of the snapshot total
+type WalletShare struct {
+	WalletID   uuid.UUID
+	WalletName string
+	USDValue   *big.Int
+	Percent    string
+	Assets     []AssetShare
+}
+
+// LotShare is a tax lot as exposed through a share link
+type LotShare struct {
+	WalletID          uuid.UUID
+	ChainID           string
+	Asset             string
+	AcquiredAt        time.Time
+	QuantityAcquired  *big.Int // nil when hidden
+	QuantityRemaining *big.Int // nil when hidden
+	RemainingPercent  string   // Remaining share of acquired quantity
+	CostBasisPerUnit  *big.Int
+	CostBasisSource   string
+}
@@ -0,0 +1,35 @@
+package sharing
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/module/portfolio"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+)
+
+// Repository persists share links. Lookups return nil, nil when not found.
+type Repository interface {
+	Create(ctx context.Context, link *ShareLink) error
+	GetByID(ctx context.Context, id uuid.UUID) (*ShareLink, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*ShareLink, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	TouchAccessed(ctx context.Context, id uuid.UUID) error
+}
+
+// WalletReader verifies the creator can view a wallet
+type WalletReader interface {
+	GetByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*wallet.Wallet, error)
+}
+
+// PortfolioReader supplies the creator's current portfolio
+type PortfolioReader interface {
+	GetPortfolioSummary(ctx context.Context, userID uuid.UUID) (*portfolio.PortfolioSummary, error)
+}
+
+// LotReader supplies tax lots on behalf of the creator
+type LotReader interface {
+	GetLotsByWallet(ctx context.Context, userID, walletID uuid.UUID, asset string, chainID string) ([]*ledger.TaxLot, error)
+}
@@ -0,0 +1,382 @@
+package sharing
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/module/portfolio"
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// Service manages share links and renders the read-only views they expose.
+// Shared data is always read on behalf of the link creator, so a link stops
+// working for wallets the creator can no longer view.
+type Service struct {
+	repo      Repository
+	signer    *TokenSigner
+	wallets   WalletReader
+	portfolio PortfolioReader
+	lots      LotReader
+	audit     audit.Recorder // optional
+	logger    *logger.Logger
+}
+
+// NewService creates a new sharing service
+func NewService(
+	repo Repository,
+	signer *TokenSigner,
+	wallets WalletReader,
+	portfolio PortfolioReader,
+	lots LotReader,
+	recorder audit.Recorder,
+	log *logger.Logger,
+) *Service {
+	return &Service{
+		repo:      repo,
+		signer:    signer,
+		wallets:   wallets,
+		portfolio: portfolio,
+		lots:      lots,
+		audit:     recorder,
+		logger:    log.WithField("component", "sharing"),
+	}
+}
+
+// Create stores a new share link and returns it with its token.
+// The token is not persisted and cannot be retrieved again.
+func (s *Service) Create(ctx context.Context, userID uuid.UUID, params CreateParams) (*ShareLink, string, error) {
+	if len(params.Scopes) == 0 {
+		return nil, "", ErrMissingScope
+	}
+	scopes := make([]Scope, 0, len(params.Scopes))
+	seen := make(map[Scope]bool, len(params.Scopes))
+	for _, scope := range params.Scopes {
+		if !scope.IsValid() {
+			return nil, "", ErrInvalidScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	ttl := params.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	if ttl < 0 || ttl > MaxTTL {
+		return nil, "", ErrInvalidTTL
+	}
+
+	label := strings.TrimSpace(params.Label)
+	if len(label) > 100 {
+		return nil, "", ErrLabelTooLong
+	}
+
+	for _, walletID := range params.WalletIDs {
+		if _, err := s.wallets.GetByID(ctx, walletID, userID); err != nil {
+			return nil, "", ErrWalletNotVisible
+		}
+	}
+
+	now := time.Now().UTC()
+	link := &ShareLink{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Label:       label,
+		Scopes:      scopes,
+		WalletIDs:   params.WalletIDs,
+		HideAmounts: params.HideAmounts,
+		ExpiresAt:   now.Add(ttl),
+		CreatedAt:   now,
+	}
+
+	token, err := s.signer.Sign(link)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := s.repo.Create(ctx, link); err != nil {
+		return nil, "", fmt.Errorf("failed to create share link: %w", err)
+	}
+
+	s.logger.Info("share link created", "link_id", link.ID, "user_id", userID, "expires_at", link.ExpiresAt)
+	s.record(ctx, userID, audit.ActionShareLinkCreate, link.ID, nil, link)
+	return link, token, nil
+}
+
+// List returns all share links created by the user, including inactive ones
+func (s *Service) List(ctx context.Context, userID uuid.UUID) ([]*ShareLink, error) {
+	links, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+	return links, nil
+}
+
+// Revoke disables a share link immediately
+func (s *Service) Revoke(ctx context.Context, userID, linkID uuid.UUID) error {
+	link, err := s.repo.GetByID(ctx, linkID)
+	if err != nil {
+		return fmt.Errorf("failed to get share link: %w", err)
+	}
+	if link == nil || link.UserID != userID {
+		return ErrLinkNotFound
+	}
+	if link.RevokedAt != nil {
+		return nil
+	}
+
+	if err := s.repo.Revoke(ctx, linkID); err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+
+	s.logger.Info("share link revoked", "link_id", linkID, "user_id", userID)
+	s.record(ctx, userID, audit.ActionShareLinkRevoke, linkID, link, nil)
+	return nil
+}
+
+// record reports a share link mutation to the audit log, if one is configured
+func (s *Service) record(ctx context.Context, actorID uuid.UUID, action audit.Action, linkID uuid.UUID, before, after *ShareLink) {
+	if s.audit == nil {
+		return
+	}
+	event := audit.Event{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: audit.TargetShareLink,
+		TargetID:   linkID.String(),
+	}
+	if before != nil {
+		event.Before = before
+	}
+	if after != nil {
+		event.After = after
+	}
+	s.audit.Record(ctx, event)
+}
+
+// Resolve verifies a token and returns the active link it references
+func (s *Service) Resolve(ctx context.Context, token string) (*ShareLink, error) {
+	id, err := s.signer.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share link: %w", err)
+	}
+	if link == nil {
+		return nil, ErrInvalidToken
+	}
+	if !link.IsActive(time.Now().UTC()) {
+		return nil, ErrLinkInactive
+	}
+
+	if err := s.repo.TouchAccessed(ctx, link.ID); err != nil {
+		// Access tracking is informational; never fail a read because of it
+		s.logger.Warn("failed to record share link access", "link_id", link.ID, "error", err)
+	}
+	return link, nil
+}
+
+// GetPortfolio returns the aggregated asset allocation for the link's wallets
+func (s *Service) GetPortfolio(ctx context.Context, link *ShareLink) (*PortfolioSnapshot, error) {
+	if !link.HasScope(ScopePortfolio) {
+		return nil, ErrScopeNotShared
+	}
+	return s.snapshot(ctx, link, false)
+}
+
+// GetWallets returns per-wallet holdings for the link's wallets
+func (s *Service) GetWallets(ctx context.Context, link *ShareLink) (*PortfolioSnapshot, error) {
+	if !link.HasScope(ScopeWallets) {
+		return nil, ErrScopeNotShared
+	}
+	return s.snapshot(ctx, link, true)
+}
+
+// GetLots returns open tax lots for every asset held in the link's wallets
+func (s *Service) GetLots(ctx context.Context, link *ShareLink) ([]LotShare, error) {
+	if !link.HasScope(ScopeLots) {
+		return nil, ErrScopeNotShared
+	}
+
+	summary, err := s.portfolio.GetPortfolioSummary(ctx, link.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
+	}
+
+	var result []LotShare
+	for _, wb := range summary.WalletBalances {
+		if !link.includesWallet(wb.WalletID) {
+			continue
+		}
+
+		assets := make(map[string]bool)
+		for _, ab := range wb.Assets {
+			assets[ab.AssetID] = true
+		}
+		for assetID := range assets {
+			lots, err := s.lots.GetLotsByWallet(ctx, link.UserID, wb.WalletID, assetID, "")
+			if err != nil {
+				return nil, fmt.Errorf("failed to get lots for wallet %s: %w", wb.WalletID, err)
+			}
+			for _, lot := range lots {
+				if lot.QuantityRemaining == nil || lot.QuantityRemaining.Sign() <= 0 {
+					continue
+				}
+				share := LotShare{
+					WalletID:         wb.WalletID,
+					ChainID:          lot.ChainID,
+					Asset:            lot.Asset,
+					AcquiredAt:       lot.AcquiredAt,
+					RemainingPercent: percentOf(lot.QuantityRemaining, lot.QuantityAcquired),
+					CostBasisPerUnit: lot.EffectiveCostBasisPerUnit(),
+					CostBasisSource:  string(lot.AutoCostBasisSource),
+				}
+				if !link.HideAmounts {
+					share.QuantityAcquired = lot.QuantityAcquired
+					share.QuantityRemaining = lot.QuantityRemaining
+				}
+				result = append(result, share)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].AcquiredAt.Before(result[j].AcquiredAt)
+	})
+	return result, nil
+}
+
+// snapshot builds a portfolio view restricted to the link's wallets.
+// Totals are recomputed from the included wallets so percentages add up.
+func (s *Service) snapshot(ctx context.Context, link *ShareLink, withWallets bool) (*PortfolioSnapshot, error) {
+	summary, err := s.portfolio.GetPortfolioSummary(ctx, link.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
+	}
+
+	total := big.NewInt(0)
+	type aggregate struct {
+		amount   *big.Int
+		usdValue *big.Int
+		price    *big.Int
+		decimals int
+	}
+	byAsset := make(map[string]*aggregate)
+	var wallets []portfolio.WalletBalance
+
+	for _, wb := range summary.WalletBalances {
+		if !link.includesWallet(wb.WalletID) {
+			continue
+		}
+		wallets = append(wallets, wb)
+		for _, ab := range wb.Assets {
+			agg, ok := byAsset[ab.AssetID]
+			if !ok {
+				agg = &aggregate{amount: big.NewInt(0), usdValue: big.NewInt(0), price: ab.Price, decimals: ab.Decimals}
+				byAsset[ab.AssetID] = agg
+			}
+			if ab.Amount != nil {
+				agg.amount.Add(agg.amount, ab.Amount)
+			}
+			if ab.USDValue != nil {
+				agg.usdValue.Add(agg.usdValue, ab.USDValue)
+				total.Add(total, ab.USDValue)
+			}
+		}
+	}
+
+	snap := &PortfolioSnapshot{
+		HideAmounts: link.HideAmounts,
+		GeneratedAt: time.Now().UTC(),
+	}
+	if !link.HideAmounts {
+		snap.TotalUSDValue = total
+	}
+
+	for assetID, agg := range byAsset {
+		share := AssetShare{
+			AssetID:  assetID,
+			Price:    agg.price,
+			Decimals: agg.decimals,
+			Percent:  percentOf(agg.usdValue, total),
+		}
+		if !link.HideAmounts {
+			share.Amount = agg.amount
+			share.USDValue = agg.usdValue
+		}
+		snap.Assets = append(snap.Assets, share)
+	}
+	// Largest positions first; ties broken by asset ID for a stable order
+	sort.Slice(snap.Assets, func(i, j int) bool {
+		vi, vj := byAsset[snap.Assets[i].AssetID].usdValue, byAsset[snap.Assets[j].AssetID].usdValue
+		if c := vi.Cmp(vj); c != 0 {
+			return c > 0
+		}
+		return snap.Assets[i].AssetID < snap.Assets[j].AssetID
+	})
+
+	if withWallets {
+		for _, wb := range wallets {
+			walletTotal := big.NewInt(0)
+			for _, ab := range wb.Assets {
+				if ab.USDValue != nil {
+					walletTotal.Add(walletTotal, ab.USDValue)
+				}
+			}
+
+			ws := WalletShare{
+				WalletID:   wb.WalletID,
+				WalletName: wb.WalletName,
+				Percent:    percentOf(walletTotal, total),
+			}
+			if !link.HideAmounts {
+				ws.USDValue = walletTotal
+			}
+			for _, ab := range wb.Assets {
+				share := AssetShare{
+					AssetID:  ab.AssetID,
+					ChainID:  ab.ChainID,
+					Price:    ab.Price,
+					Decimals: ab.Decimals,
+					Percent:  percentOf(ab.USDValue, walletTotal),
+				}
+				if !link.HideAmounts {
+					share.Amount = ab.Amount
+					share.USDValue = ab.USDValue
+				}
+				ws.Assets = append(ws.Assets, share)
+			}
+			snap.Wallets = append(snap.Wallets, ws)
+		}
+	}
+
+	return snap, nil
+}
+
+// percentOf returns part/total as a percentage with two decimals.
+// Returns "0.00" when the total is zero or either value is missing.
+func percentOf(part, total *big.Int) string {
+	if part == nil || total == nil || total.Sign() == 0 {
+		return "0.00"
+	}
+	// Basis points, rounded half up
+	bps := new(big.Int).Mul(part, big.NewInt(20000))
+	bps.Quo(bps, total)
+	bps.Add(bps, big.NewInt(1))
+	bps.Quo(bps, big.NewInt(2))
+
+	whole, frac := new(big.Int).QuoRem(bps, big.NewInt(100), new(big.Int))
+	return fmt.Sprintf("%s.%02d", whole.String(), frac.Int64())
+}
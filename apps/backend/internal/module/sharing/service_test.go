@@ -0,0 +1,318 @@
+package sharing
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/module/portfolio"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+const testSecret = "test-secret-that-is-at-least-32-characters"
+
+// mockRepo is an in-memory implementation of Repository for testing.
+type mockRepo struct {
+	links map[uuid.UUID]*ShareLink
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{links: make(map[uuid.UUID]*ShareLink)}
+}
+
+func (r *mockRepo) Create(_ context.Context, link *ShareLink) error {
+	r.links[link.ID] = link
+	return nil
+}
+
+func (r *mockRepo) GetByID(_ context.Context, id uuid.UUID) (*ShareLink, error) {
+	link, ok := r.links[id]
+	if !ok {
+		return nil, nil
+	}
+	cp := *link
+	return &cp, nil
+}
+
+func (r *mockRepo) ListByUser(_ context.Context, userID uuid.UUID) ([]*ShareLink, error) {
+	var result []*ShareLink
+	for _, link := range r.links {
+		if link.UserID == userID {
+			result = append(result, link)
+		}
+	}
+	return result, nil
+}
+
+func (r *mockRepo) Revoke(_ context.Context, id uuid.UUID) error {
+	now := time.Now().UTC()
+	r.links[id].RevokedAt = &now
+	return nil
+}
+
+func (r *mockRepo) TouchAccessed(_ context.Context, id uuid.UUID) error {
+	now := time.Now().UTC()
+	r.links[id].LastAccessedAt = &now
+	return nil
+}
+
+type mockWallets struct {
+	owned map[uuid.UUID]uuid.UUID // wallet → user
+}
+
+func (m *mockWallets) GetByID(_ context.Context, id uuid.UUID, userID uuid.UUID) (*wallet.Wallet, error) {
+	if m.owned[id] != userID {
+		return nil, wallet.ErrWalletNotFound
+	}
+	return &wallet.Wallet{ID: id, UserID: userID}, nil
+}
+
+type mockPortfolio struct {
+	summary *portfolio.PortfolioSummary
+}
+
+func (m *mockPortfolio) GetPortfolioSummary(_ context.Context, _ uuid.UUID) (*portfolio.PortfolioSummary, error) {
+	return m.summary, nil
+}
+
+type mockLots struct {
+	lots []*ledger.TaxLot
+}
+
+func (m *mockLots) GetLotsByWallet(_ context.Context, _, _ uuid.UUID, asset string, _ string) ([]*ledger.TaxLot, error) {
+	var result []*ledger.TaxLot
+	for _, lot := range m.lots {
+		if lot.Asset == asset {
+			result = append(result, lot)
+		}
+	}
+	return result, nil
+}
+
+type fixture struct {
+	svc     *Service
+	repo    *mockRepo
+	lots    *mockLots
+	userID  uuid.UUID
+	walletA uuid.UUID
+	walletB uuid.UUID
+}
+
+func newFixture() *fixture {
+	userID := uuid.New()
+	walletA, walletB := uuid.New(), uuid.New()
+
+	summary := &portfolio.PortfolioSummary{
+		WalletBalances: []portfolio.WalletBalance{
+			{
+				WalletID:   walletA,
+				WalletName: "Main",
+				Assets: []portfolio.AssetBalance{
+					{AssetID: "ETH", ChainID: "ethereum", Amount: big.NewInt(2), USDValue: big.NewInt(300), Price: big.NewInt(150), Decimals: 18},
+					{AssetID: "USDC", ChainID: "ethereum", Amount: big.NewInt(100), USDValue: big.NewInt(100), Price: big.NewInt(1), Decimals: 6},
+				},
+			},
+			{
+				WalletID:   walletB,
+				WalletName: "Cold",
+				Assets: []portfolio.AssetBalance{
+					{AssetID: "ETH", ChainID: "ethereum", Amount: big.NewInt(4), USDValue: big.NewInt(600), Price: big.NewInt(150), Decimals: 18},
+				},
+			},
+		},
+	}
+
+	repo := newMockRepo()
+	lots := &mockLots{}
+	wallets := &mockWallets{owned: map[uuid.UUID]uuid.UUID{walletA: userID, walletB: userID}}
+	svc := NewService(repo, NewTokenSigner(testSecret), wallets, &mockPortfolio{summary: summary}, lots, nil, logger.New("test", io.Discard))
+
+	return &fixture{svc: svc, repo: repo, lots: lots, userID: userID, walletA: walletA, walletB: walletB}
+}
+
+func TestPercentOf(t *testing.T) {
+	assert.Equal(t, "25.00", percentOf(big.NewInt(1), big.NewInt(4)))
+	assert.Equal(t, "33.33", percentOf(big.NewInt(1), big.NewInt(3)))
+	assert.Equal(t, "66.67", percentOf(big.NewInt(2), big.NewInt(3)))
+	assert.Equal(t, "100.00", percentOf(big.NewInt(7), big.NewInt(7)))
+	assert.Equal(t, "0.00", percentOf(big.NewInt(7), big.NewInt(0)))
+	assert.Equal(t, "0.00", percentOf(nil, big.NewInt(7)))
+}
+
+func TestCreate_Validation(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	_, _, err := f.svc.Create(ctx, f.userID, CreateParams{})
+	assert.ErrorIs(t, err, ErrMissingScope)
+
+	_, _, err = f.svc.Create(ctx, f.userID, CreateParams{Scopes: []Scope{"everything"}})
+	assert.ErrorIs(t, err, ErrInvalidScope)
+
+	_, _, err = f.svc.Create(ctx, f.userID, CreateParams{Scopes: []Scope{ScopePortfolio}, TTL: MaxTTL + time.Hour})
+	assert.ErrorIs(t, err, ErrInvalidTTL)
+
+	_, _, err = f.svc.Create(ctx, f.userID, CreateParams{Scopes: []Scope{ScopePortfolio}, WalletIDs: []uuid.UUID{uuid.New()}})
+	assert.ErrorIs(t, err, ErrWalletNotVisible)
+
+	_, _, err = f.svc.Create(ctx, uuid.New(), CreateParams{Scopes: []Scope{ScopePortfolio}, WalletIDs: []uuid.UUID{f.walletA}})
+	assert.ErrorIs(t, err, ErrWalletNotVisible)
+}
+
+func TestCreateAndResolve(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	link, token, err := f.svc.Create(ctx, f.userID, CreateParams{
+		Label:  "  for my accountant ",
+		Scopes: []Scope{ScopePortfolio, ScopePortfolio, ScopeLots},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "for my accountant", link.Label)
+	assert.Equal(t, []Scope{ScopePortfolio, ScopeLots}, link.Scopes)
+	assert.WithinDuration(t, time.Now().Add(DefaultTTL), link.ExpiresAt, time.Minute)
+
+	resolved, err := f.svc.Resolve(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, link.ID, resolved.ID)
+	assert.NotNil(t, f.repo.links[link.ID].LastAccessedAt)
+
+	_, err = f.svc.Resolve(ctx, token+"x")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestResolve_RejectsSessionSecretTokens(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	link, _, err := f.svc.Create(ctx, f.userID, CreateParams{Scopes: []Scope{ScopePortfolio}})
+	require.NoError(t, err)
+
+	// A token signed directly with the application secret must not be accepted
+	forged := &TokenSigner{key: []byte(testSecret)}
+	token, err := forged.Sign(link)
+	require.NoError(t, err)
+
+	_, err = f.svc.Resolve(ctx, token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestRevoke(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	link, token, err := f.svc.Create(ctx, f.userID, CreateParams{Scopes: []Scope{ScopePortfolio}})
+	require.NoError(t, err)
+
+	err = f.svc.Revoke(ctx, uuid.New(), link.ID)
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+
+	require.NoError(t, f.svc.Revoke(ctx, f.userID, link.ID))
+	require.NoError(t, f.svc.Revoke(ctx, f.userID, link.ID), "revoking twice is a no-op")
+
+	_, err = f.svc.Resolve(ctx, token)
+	assert.ErrorIs(t, err, ErrLinkInactive)
+}
+
+func TestResolve_Expired(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	link, token, err := f.svc.Create(ctx, f.userID, CreateParams{Scopes: []Scope{ScopePortfolio}})
+	require.NoError(t, err)
+
+	// Shorten the stored expiry; the row is authoritative even if the token is still valid
+	f.repo.links[link.ID].ExpiresAt = time.Now().Add(-time.Minute)
+
+	_, err = f.svc.Resolve(ctx, token)
+	assert.ErrorIs(t, err, ErrLinkInactive)
+}
+
+func TestGetPortfolio_FiltersWalletsAndRecomputesTotals(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	link, _, err := f.svc.Create(ctx, f.userID, CreateParams{
+		Scopes:    []Scope{ScopePortfolio},
+		WalletIDs: []uuid.UUID{f.walletA},
+	})
+	require.NoError(t, err)
+
+	snap, err := f.svc.GetPortfolio(ctx, link)
+	require.NoError(t, err)
+	assert.Equal(t, int64(400), snap.TotalUSDValue.Int64())
+	require.Len(t, snap.Assets, 2)
+	assert.Equal(t, "ETH", snap.Assets[0].AssetID)
+	assert.Equal(t, "75.00", snap.Assets[0].Percent)
+	assert.Equal(t, int64(2), snap.Assets[0].Amount.Int64())
+	assert.Equal(t, "25.00", snap.Assets[1].Percent)
+	assert.Empty(t, snap.Wallets)
+
+	_, err = f.svc.GetWallets(ctx, link)
+	assert.ErrorIs(t, err, ErrScopeNotShared)
+}
+
+func TestGetWallets_HideAmounts(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	link, _, err := f.svc.Create(ctx, f.userID, CreateParams{
+		Scopes:      []Scope{ScopeWallets},
+		HideAmounts: true,
+	})
+	require.NoError(t, err)
+
+	snap, err := f.svc.GetWallets(ctx, link)
+	require.NoError(t, err)
+	assert.Nil(t, snap.TotalUSDValue)
+	require.Len(t, snap.Wallets, 2)
+
+	for _, w := range snap.Wallets {
+		assert.Nil(t, w.USDValue)
+		for _, a := range w.Assets {
+			assert.Nil(t, a.Amount)
+			assert.Nil(t, a.USDValue)
+		}
+	}
+	assert.Equal(t, "40.00", snap.Wallets[0].Percent)
+	assert.Equal(t, "60.00", snap.Wallets[1].Percent)
+	assert.Equal(t, "75.00", snap.Wallets[0].Assets[0].Percent)
+
+	require.Len(t, snap.Assets, 2)
+	assert.Equal(t, "ETH", snap.Assets[0].AssetID)
+	assert.Equal(t, "90.00", snap.Assets[0].Percent)
+}
+
+func TestGetLots(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	f.lots.lots = []*ledger.TaxLot{
+		{Asset: "ETH", QuantityAcquired: big.NewInt(4), QuantityRemaining: big.NewInt(2), AcquiredAt: now, AutoCostBasisPerUnit: big.NewInt(100), AutoCostBasisSource: ledger.CostBasisSwapPrice},
+		{Asset: "ETH", QuantityAcquired: big.NewInt(1), QuantityRemaining: big.NewInt(0), AcquiredAt: now.Add(-time.Hour)},
+	}
+
+	link, _, err := f.svc.Create(ctx, f.userID, CreateParams{
+		Scopes:      []Scope{ScopeLots},
+		WalletIDs:   []uuid.UUID{f.walletA},
+		HideAmounts: true,
+	})
+	require.NoError(t, err)
+
+	lots, err := f.svc.GetLots(ctx, link)
+	require.NoError(t, err)
+	require.Len(t, lots, 1, "closed lots are not shared")
+	assert.Equal(t, f.walletA, lots[0].WalletID)
+	assert.Equal(t, "50.00", lots[0].RemainingPercent)
+	assert.Nil(t, lots[0].QuantityRemaining)
+	assert.Equal(t, int64(100), lots[0].CostBasisPerUnit.Int64())
+}
@@ -0,0 +1,66 @@
+package sharing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const tokenAudience = "share-link"
+
+// TokenSigner issues and verifies share link tokens.
+// Tokens are signed with a key derived from the application secret so they
+// can never be accepted as session tokens, and vice versa.
+type TokenSigner struct {
+	key []byte
+}
+
+// NewTokenSigner creates a signer from the application JWT secret
+func NewTokenSigner(secret string) *TokenSigner {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(tokenAudience))
+	return &TokenSigner{key: mac.Sum(nil)}
+}
+
+// Sign returns a token referencing the link that expires with it
+func (s *TokenSigner) Sign(link *ShareLink) (string, error) {
+	claims := jwt.RegisteredClaims{
+		ID:        link.ID.String(),
+		Audience:  jwt.ClaimStrings{tokenAudience},
+		ExpiresAt: jwt.NewNumericDate(link.ExpiresAt),
+		IssuedAt:  jwt.NewNumericDate(link.CreatedAt),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign share token: %w", err)
+	}
+	return token, nil
+}
+
+// Verify validates the token signature and expiry and returns the link ID
+func (s *TokenSigner) Verify(tokenString string) (uuid.UUID, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return s.key, nil
+	}, jwt.WithAudience(tokenAudience), jwt.WithExpirationRequired(), jwt.WithLeeway(5*time.Second))
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return uuid.Nil, ErrLinkInactive
+		}
+		return uuid.Nil, ErrInvalidToken
+	}
+
+	id, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return uuid.Nil, ErrInvalidToken
+	}
+	return id, nil
+}
@@ -0,0 +1,320 @@
+package staking
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// leg is one asset movement of a staking operation.
+type leg struct {
+	asset    string
+	amount   *big.Int
+	usdRate  *big.Int
+	decimals int
+	contract string
+}
+
+func (l leg) usdValue() *big.Int {
+	return money.CalcUSDValue(l.amount, l.usdRate, l.decimals)
+}
+
+// generateStakeEntries generates entries for a stake.
+//
+// Native staking moves the asset into a staked account, which keeps its
+// lots and cost basis:
+//
+//	DEBIT  staked.{protocol}.{wID}.{chain}.{asset}  (collateral_increase)
+//	CREDIT wallet.{wID}.{chain}.{asset}             (asset_decrease)
+//
+// Liquid staking exchanges the asset for the receipt token like a swap, so
+// the receipt token's lots start at its market value:
+//
+//	CREDIT wallet.{wID}.{chain}.{asset}    (asset_decrease)
+//	DEBIT  clearing.{chain}.{asset}        (clearing)
+//	DEBIT  wallet.{wID}.{chain}.{receipt}  (asset_increase)
+//	CREDIT clearing.{chain}.{receipt}      (clearing)
+func generateStakeEntries(txn *StakingTransaction) []*ledger.Entry {
+	if txn.IsLiquid() {
+		return generateExchangeEntries(txn, stakedLeg(txn), receiptLeg(txn))
+	}
+	return generateStakedTransferEntries(txn, true)
+}
+
+// generateUnstakeEntries generates entries for an unstake, the reverse of
+// generateStakeEntries.
+//
+//	DEBIT  wallet.{wID}.{chain}.{asset}             (asset_increase)
+//	CREDIT staked.{protocol}.{wID}.{chain}.{asset}  (collateral_decrease)
+//
+// or, for liquid staking, the receipt token is exchanged back for the asset.
+func generateUnstakeEntries(txn *StakingTransaction) []*ledger.Entry {
+	if txn.IsLiquid() {
+		return generateExchangeEntries(txn, receiptLeg(txn), stakedLeg(txn))
+	}
+	return generateStakedTransferEntries(txn, false)
+}
+
+// generateRewardEntries generates entries for a staking reward: income → wallet.
+// Rebasing rewards are booked the same way, in the receipt token.
+//
+//	DEBIT  wallet.{wID}.{chain}.{asset}    (asset_increase)
+//	CREDIT income.staking.{chain}.{asset}  (income)
+func generateRewardEntries(txn *StakingTransaction) []*ledger.Entry {
+	l := stakedLeg(txn)
+	usdValue := l.usdValue()
+
+	walletID := txn.WalletID.String()
+	chain := txn.ChainID
+
+	return []*ledger.Entry{
+		{
+			ID:          uuid.New(),
+			DebitCredit: ledger.Debit,
+			EntryType:   ledger.EntryTypeAssetIncrease,
+			Amount:      new(big.Int).Set(l.amount),
+			AssetID:     l.asset,
+			USDRate:     new(big.Int).Set(l.usdRate),
+			USDValue:    new(big.Int).Set(usdValue),
+			OccurredAt:  txn.OccurredAt,
+			CreatedAt:   time.Now().UTC(),
+			Metadata: map[string]interface{}{
+				"wallet_id":    walletID,
+				"account_code": fmt.Sprintf("wallet.%s.%s.%s", walletID, chain, l.asset),
+				"tx_hash":      txn.TxHash,
+				"chain_id":     chain,
+				"protocol":     txn.Protocol,
+			},
+		},
+		{
+			ID:          uuid.New(),
+			DebitCredit: ledger.Credit,
+			EntryType:   ledger.EntryTypeIncome,
+			Amount:      new(big.Int).Set(l.amount),
+			AssetID:     l.asset,
+			USDRate:     new(big.Int).Set(l.usdRate),
+			USDValue:    new(big.Int).Set(usdValue),
+			OccurredAt:  txn.OccurredAt,
+			CreatedAt:   time.Now().UTC(),
+			Metadata: map[string]interface{}{
+				"account_code": fmt.Sprintf("income.staking.%s.%s", chain, l.asset),
+				"tx_hash":      txn.TxHash,
+				"chain_id":     chain,
+				"protocol":     txn.Protocol,
+			},
+		},
+	}
+}
+
+// generateStakedTransferEntries moves the asset between the wallet and its
+// staked account. Both entries carry the staking_transfer marker so the tax
+// lot hook links the lots instead of realizing a gain.
+func generateStakedTransferEntries(txn *StakingTransaction, stake bool) []*ledger.Entry {
+	l := stakedLeg(txn)
+	usdValue := l.usdValue()
+
+	walletID := txn.WalletID.String()
+	chain := txn.ChainID
+
+	stakedType, walletType := ledger.EntryTypeCollateralIncrease, ledger.EntryTypeAssetDecrease
+	stakedSide, walletSide := ledger.Debit, ledger.Credit
+	if !stake {
+		stakedType, walletType = ledger.EntryTypeCollateralDecrease, ledger.EntryTypeAssetIncrease
+		stakedSide, walletSide = ledger.Credit, ledger.Debit
+	}
+
+	return []*ledger.Entry{
+		{
+			ID:          uuid.New(),
+			DebitCredit: stakedSide,
+			EntryType:   stakedType,
+			Amount:      new(big.Int).Set(l.amount),
+			AssetID:     l.asset,
+			USDRate:     new(big.Int).Set(l.usdRate),
+			USDValue:    new(big.Int).Set(usdValue),
+			OccurredAt:  txn.OccurredAt,
+			CreatedAt:   time.Now().UTC(),
+			Metadata: map[string]interface{}{
+				"wallet_id":        walletID,
+				"account_code":     fmt.Sprintf("staked.%s.%s.%s.%s", txn.Protocol, walletID, chain, l.asset),
+				"account_type":     "COLLATERAL",
+				"tx_hash":          txn.TxHash,
+				"chain_id":         chain,
+				"protocol":         txn.Protocol,
+				"contract_address": l.contract,
+				"entry_type":       "staking_transfer",
+			},
+		},
+		{
+			ID:          uuid.New(),
+			DebitCredit: walletSide,
+			EntryType:   walletType,
+			Amount:      new(big.Int).Set(l.amount),
+			AssetID:     l.asset,
+			USDRate:     new(big.Int).Set(l.usdRate),
+			USDValue:    new(big.Int).Set(usdValue),
+			OccurredAt:  txn.OccurredAt,
+			CreatedAt:   time.Now().UTC(),
+			Metadata: map[string]interface{}{
+				"wallet_id":    walletID,
+				"account_code": fmt.Sprintf("wallet.%s.%s.%s", walletID, chain, l.asset),
+				"tx_hash":      txn.TxHash,
+				"chain_id":     chain,
+				"protocol":     txn.Protocol,
+				"entry_type":   "staking_transfer",
+			},
+		},
+	}
+}
+
+// generateExchangeEntries swaps the out leg for the in leg through clearing.
+// If the in leg has no price, it is valued at the out leg's USD value.
+func generateExchangeEntries(txn *StakingTransaction, out, in leg) []*ledger.Entry {
+	outValue := out.usdValue()
+	if in.usdRate.Sign() == 0 && outValue.Sign() > 0 {
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(in.decimals)), nil)
+		in.usdRate = new(big.Int).Mul(outValue, scale)
+		in.usdRate.Div(in.usdRate, in.amount)
+	}
+	inValue := in.usdValue()
+
+	walletID := txn.WalletID.String()
+	chain := txn.ChainID
+
+	entry := func(dc ledger.DebitCredit, et ledger.EntryType, l leg, usdValue *big.Int, meta map[string]interface{}) *ledger.Entry {
+		meta["tx_hash"] = txn.TxHash
+		meta["chain_id"] = chain
+		meta["protocol"] = txn.Protocol
+		return &ledger.Entry{
+			ID:          uuid.New(),
+			DebitCredit: dc,
+			EntryType:   et,
+			Amount:      new(big.Int).Set(l.amount),
+			AssetID:     l.asset,
+			USDRate:     new(big.Int).Set(l.usdRate),
+			USDValue:    new(big.Int).Set(usdValue),
+			OccurredAt:  txn.OccurredAt,
+			CreatedAt:   time.Now().UTC(),
+			Metadata:    meta,
+		}
+	}
+
+	return []*ledger.Entry{
+		entry(ledger.Credit, ledger.EntryTypeAssetDecrease, out, outValue, map[string]interface{}{
+			"wallet_id":        walletID,
+			"account_code":     fmt.Sprintf("wallet.%s.%s.%s", walletID, chain, out.asset),
+			"direction":        "out",
+			"contract_address": out.contract,
+		}),
+		entry(ledger.Debit, ledger.EntryTypeClearing, out, outValue, map[string]interface{}{
+			"account_code": fmt.Sprintf("clearing.%s.%s", chain, out.asset),
+			"account_type": "CLEARING",
+			"direction":    "out",
+		}),
+		entry(ledger.Debit, ledger.EntryTypeAssetIncrease, in, inValue, map[string]interface{}{
+			"wallet_id":        walletID,
+			"account_code":     fmt.Sprintf("wallet.%s.%s.%s", walletID, chain, in.asset),
+			"direction":        "in",
+			"contract_address": in.contract,
+		}),
+		entry(ledger.Credit, ledger.EntryTypeClearing, in, inValue, map[string]interface{}{
+			"account_code": fmt.Sprintf("clearing.%s.%s", chain, in.asset),
+			"account_type": "CLEARING",
+			"direction":    "in",
+		}),
+	}
+}
+
+// generateGasFeeEntries generates gas fee entries if the transaction has a fee.
+//
+//	DEBIT  gas.{chain}.{feeAsset}          (gas_fee)
+//	CREDIT wallet.{wID}.{chain}.{feeAsset} (asset_decrease)
+func generateGasFeeEntries(txn *StakingTransaction) []*ledger.Entry {
+	if txn.FeeAmount == nil || txn.FeeAmount.IsNil() || txn.FeeAmount.Sign() <= 0 {
+		return nil
+	}
+
+	feeAmount := txn.FeeAmount.ToBigInt()
+	feeUSDRate := big.NewInt(0)
+	if txn.FeeUSDPrice != nil && !txn.FeeUSDPrice.IsNil() {
+		feeUSDRate = txn.FeeUSDPrice.ToBigInt()
+	}
+	feeDecimals := txn.FeeDecimals
+	if feeDecimals == 0 {
+		feeDecimals = 18
+	}
+	feeUSDValue := money.CalcUSDValue(feeAmount, feeUSDRate, feeDecimals)
+
+	walletID := txn.WalletID.String()
+	chain := txn.ChainID
+	feeAsset := txn.FeeAsset
+
+	return []*ledger.Entry{
+		{
+			ID:          uuid.New(),
+			DebitCredit: ledger.Debit,
+			EntryType:   ledger.EntryTypeGasFee,
+			Amount:      new(big.Int).Set(feeAmount),
+			AssetID:     feeAsset,
+			USDRate:     new(big.Int).Set(feeUSDRate),
+			USDValue:    new(big.Int).Set(feeUSDValue),
+			OccurredAt:  txn.OccurredAt,
+			CreatedAt:   time.Now().UTC(),
+			Metadata: map[string]interface{}{
+				"account_code": fmt.Sprintf("gas.%s.%s", chain, feeAsset),
+				"tx_hash":      txn.TxHash,
+				"chain_id":     chain,
+			},
+		},
+		{
+			ID:          uuid.New(),
+			DebitCredit: ledger.Credit,
+			EntryType:   ledger.EntryTypeAssetDecrease,
+			Amount:      new(big.Int).Set(feeAmount),
+			AssetID:     feeAsset,
+			USDRate:     new(big.Int).Set(feeUSDRate),
+			USDValue:    new(big.Int).Set(feeUSDValue),
+			OccurredAt:  txn.OccurredAt,
+			CreatedAt:   time.Now().UTC(),
+			Metadata: map[string]interface{}{
+				"wallet_id":    walletID,
+				"account_code": fmt.Sprintf("wallet.%s.%s.%s", walletID, chain, feeAsset),
+				"tx_hash":      txn.TxHash,
+				"chain_id":     chain,
+				"entry_type":   "gas_payment",
+			},
+		},
+	}
+}
+
+func stakedLeg(txn *StakingTransaction) leg {
+	return leg{
+		asset:    txn.Asset,
+		amount:   txn.Amount.ToBigInt(),
+		usdRate:  priceOrZero(txn.USDPrice),
+		decimals: txn.Decimals,
+		contract: txn.ContractAddress,
+	}
+}
+
+func receiptLeg(txn *StakingTransaction) leg {
+	return leg{
+		asset:    txn.ReceiptAsset,
+		amount:   txn.ReceiptAmount.ToBigInt(),
+		usdRate:  priceOrZero(txn.ReceiptUSDPrice),
+		decimals: txn.ReceiptDecimals,
+		contract: txn.ReceiptContract,
+	}
+}
+
+func priceOrZero(p *money.BigInt) *big.Int {
+	if p == nil || p.IsNil() {
+		return big.NewInt(0)
+	}
+	return p.ToBigInt()
+}
@@ -0,0 +1,121 @@
+package staking
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+func assertEntriesBalanced(t *testing.T, entries []*ledger.Entry) {
+	t.Helper()
+	debitSum := new(big.Int)
+	creditSum := new(big.Int)
+	for _, e := range entries {
+		if e.DebitCredit == ledger.Debit {
+			debitSum.Add(debitSum, e.Amount)
+		} else {
+			creditSum.Add(creditSum, e.Amount)
+		}
+	}
+	assert.Equal(t, 0, debitSum.Cmp(creditSum),
+		"entries must balance: debits=%s credits=%s", debitSum.String(), creditSum.String())
+}
+
+func baseTxn() *StakingTransaction {
+	return &StakingTransaction{
+		WalletID:   uuid.New(),
+		TxHash:     "0xabc123",
+		ChainID:    "ethereum",
+		OccurredAt: time.Now().UTC(),
+		Protocol:   "Lido",
+		Asset:      "ETH",
+		Amount:     money.NewBigInt(big.NewInt(1_000_000_000_000_000_000)), // 1 ETH
+		Decimals:   18,
+		USDPrice:   money.NewBigInt(big.NewInt(200_000_000_000)), // $2000 scaled 10^8
+	}
+}
+
+func liquidTxn() *StakingTransaction {
+	txn := baseTxn()
+	txn.ReceiptAsset = "stETH"
+	txn.ReceiptAmount = money.NewBigInt(big.NewInt(1_000_000_000_000_000_000))
+	txn.ReceiptDecimals = 18
+	txn.ReceiptContract = "0xsteth"
+	return txn
+}
+
+func TestGenerateStakeEntries_Native(t *testing.T) {
+	entries := generateStakeEntries(baseTxn())
+
+	require.Len(t, entries, 2)
+	assertEntriesBalanced(t, entries)
+
+	assert.Equal(t, ledger.Debit, entries[0].DebitCredit)
+	assert.Equal(t, ledger.EntryTypeCollateralIncrease, entries[0].EntryType)
+	assert.Contains(t, entries[0].Metadata["account_code"], "staked.Lido.")
+	assert.Equal(t, "COLLATERAL", entries[0].Metadata["account_type"])
+
+	assert.Equal(t, ledger.Credit, entries[1].DebitCredit)
+	assert.Equal(t, ledger.EntryTypeAssetDecrease, entries[1].EntryType)
+	for _, e := range entries {
+		assert.Equal(t, "staking_transfer", e.Metadata["entry_type"])
+	}
+}
+
+func TestGenerateUnstakeEntries_Native(t *testing.T) {
+	entries := generateUnstakeEntries(baseTxn())
+
+	require.Len(t, entries, 2)
+	assertEntriesBalanced(t, entries)
+	assert.Equal(t, ledger.EntryTypeCollateralDecrease, entries[0].EntryType)
+	assert.Equal(t, ledger.Credit, entries[0].DebitCredit)
+	assert.Equal(t, ledger.EntryTypeAssetIncrease, entries[1].EntryType)
+	assert.Equal(t, ledger.Debit, entries[1].DebitCredit)
+}
+
+func TestGenerateStakeEntries_LiquidPricesReceipt(t *testing.T) {
+	entries := generateStakeEntries(liquidTxn())
+
+	require.Len(t, entries, 4)
+	assertEntriesBalanced(t, entries)
+
+	assert.Equal(t, "ETH", entries[0].AssetID)
+	assert.Equal(t, ledger.EntryTypeAssetDecrease, entries[0].EntryType)
+	assert.Equal(t, "CLEARING", entries[1].Metadata["account_type"])
+	assert.Equal(t, "stETH", entries[2].AssetID)
+	assert.Equal(t, ledger.EntryTypeAssetIncrease, entries[2].EntryType)
+	assert.Nil(t, entries[2].Metadata["entry_type"], "liquid staking is an exchange, not a staking transfer")
+
+	// Receipt without a price is valued at the staked ETH
+	assert.Equal(t, 0, entries[2].USDValue.Cmp(entries[0].USDValue))
+}
+
+func TestGenerateUnstakeEntries_LiquidBurnsReceipt(t *testing.T) {
+	entries := generateUnstakeEntries(liquidTxn())
+
+	require.Len(t, entries, 4)
+	assert.Equal(t, "stETH", entries[0].AssetID)
+	assert.Equal(t, ledger.EntryTypeAssetDecrease, entries[0].EntryType)
+	assert.Equal(t, "ETH", entries[2].AssetID)
+	assert.Equal(t, ledger.EntryTypeAssetIncrease, entries[2].EntryType)
+}
+
+func TestGenerateRewardEntries(t *testing.T) {
+	txn := baseTxn()
+	txn.Asset = "stETH"
+	entries := generateRewardEntries(txn)
+
+	require.Len(t, entries, 2)
+	assertEntriesBalanced(t, entries)
+	assert.Equal(t, ledger.EntryTypeAssetIncrease, entries[0].EntryType)
+	assert.Equal(t, ledger.EntryTypeIncome, entries[1].EntryType)
+	assert.Equal(t, "income.staking.ethereum.stETH", entries[1].Metadata["account_code"])
+	assert.Equal(t, "200000000000", entries[1].USDValue.String())
+}
@@ -0,0 +1,58 @@
+package staking
+
+import (
+	"context"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// StakingRewardHandler handles claimed and rebasing staking rewards.
+type StakingRewardHandler struct {
+	ledger.BaseHandler
+	walletRepo WalletRepository
+	access     wallet.AccessChecker
+	logger     *logger.Logger
+}
+
+func NewStakingRewardHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *StakingRewardHandler {
+	return &StakingRewardHandler{
+		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeStakingReward),
+		walletRepo:  walletRepo,
+		access:      access,
+		logger:      log.WithField("component", "staking_reward"),
+	}
+}
+
+func (h *StakingRewardHandler) Handle(ctx context.Context, data map[string]interface{}) ([]*ledger.Entry, error) {
+	var txn StakingTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return nil, err
+	}
+
+	if err := h.ValidateData(ctx, data); err != nil {
+		return nil, err
+	}
+
+	entries := generateRewardEntries(&txn)
+	if gasEntries := generateGasFeeEntries(&txn); gasEntries != nil {
+		entries = append(entries, gasEntries...)
+	}
+
+	h.logger.Debug("staking reward entries generated", "entry_count", len(entries))
+	return entries, nil
+}
+
+func (h *StakingRewardHandler) ValidateData(ctx context.Context, data map[string]interface{}) error {
+	var txn StakingTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return err
+	}
+
+	if err := txn.Validate(); err != nil {
+		return err
+	}
+
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
+}
@@ -0,0 +1,58 @@
+package staking
+
+import (
+	"context"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// StakeHandler handles native and liquid staking deposits.
+type StakeHandler struct {
+	ledger.BaseHandler
+	walletRepo WalletRepository
+	access     wallet.AccessChecker
+	logger     *logger.Logger
+}
+
+func NewStakeHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *StakeHandler {
+	return &StakeHandler{
+		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeStake),
+		walletRepo:  walletRepo,
+		access:      access,
+		logger:      log.WithField("component", "stake"),
+	}
+}
+
+func (h *StakeHandler) Handle(ctx context.Context, data map[string]interface{}) ([]*ledger.Entry, error) {
+	var txn StakingTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return nil, err
+	}
+
+	if err := h.ValidateData(ctx, data); err != nil {
+		return nil, err
+	}
+
+	entries := generateStakeEntries(&txn)
+	if gasEntries := generateGasFeeEntries(&txn); gasEntries != nil {
+		entries = append(entries, gasEntries...)
+	}
+
+	h.logger.Debug("stake entries generated", "entry_count", len(entries))
+	return entries, nil
+}
+
+func (h *StakeHandler) ValidateData(ctx context.Context, data map[string]interface{}) error {
+	var txn StakingTransaction
+	if err := unmarshalData(data, &txn); err != nil {
+		return err
+	}
+
+	if err := txn.Validate(); err != nil {
+		return err
+	}
+
+	return validateWalletAccess(ctx, h.walletRepo, h.access, txn.WalletID)
+}
@@ -0,0 +1,164 @@
+package staking_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/module/staking"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
+	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+type MockWalletRepository struct {
+	mock.Mock
+}
+
+func (m *MockWalletRepository) GetByID(ctx context.Context, walletID uuid.UUID) (*wallet.Wallet, error) {
+	args := m.Called(ctx, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wallet.Wallet), args.Error(1)
+}
+
+func buildTestData(walletID uuid.UUID) map[string]interface{} {
+	return map[string]interface{}{
+		"wallet_id":   walletID.String(),
+		"tx_hash":     "0xtest123",
+		"chain_id":    "ethereum",
+		"occurred_at": time.Now().UTC().Format(time.RFC3339),
+		"protocol":    "Lido",
+		"asset":       "ETH",
+		"amount":      "1000000000000000000",
+		"decimals":    float64(18),
+		"usd_price":   "200000000000",
+	}
+}
+
+// MockAccessChecker is a mock implementation of wallet.AccessChecker
+type MockAccessChecker struct {
+	mock.Mock
+}
+
+func (m *MockAccessChecker) Authorize(ctx context.Context, workspaceID, userID uuid.UUID, required workspace.Role) error {
+	return m.Called(ctx, workspaceID, userID, required).Error(0)
+}
+
+// newEditorAccess grants the editor role to userID and denies everyone else
+func newEditorAccess(userID uuid.UUID) *MockAccessChecker {
+	access := new(MockAccessChecker)
+	access.On("Authorize", mock.Anything, mock.Anything, userID, workspace.RoleEditor).Return(nil)
+	access.On("Authorize", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(workspace.ErrNotMember)
+	return access
+}
+
+func setupHandler(t *testing.T) (uuid.UUID, *MockWalletRepository, *MockAccessChecker, *logger.Logger, context.Context) {
+	t.Helper()
+	userID := uuid.New()
+	walletID := uuid.New()
+
+	mockRepo := new(MockWalletRepository)
+	mockRepo.On("GetByID", mock.Anything, walletID).Return(&wallet.Wallet{ID: walletID, UserID: userID}, nil)
+
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, userID)
+	return walletID, mockRepo, newEditorAccess(userID), logger.NewDefault("test"), ctx
+}
+
+func TestStakeHandler_LiquidWithGasFee(t *testing.T) {
+	walletID, mockRepo, access, log, ctx := setupHandler(t)
+
+	handler := staking.NewStakeHandler(mockRepo, access, log)
+	assert.Equal(t, ledger.TxTypeStake, handler.Type())
+
+	data := buildTestData(walletID)
+	data["receipt_asset"] = "stETH"
+	data["receipt_amount"] = "1000000000000000000"
+	data["receipt_decimals"] = float64(18)
+	data["fee_asset"] = "ETH"
+	data["fee_amount"] = "500000000000000"
+	data["fee_decimals"] = float64(18)
+
+	entries, err := handler.Handle(ctx, data)
+	require.NoError(t, err)
+	require.Len(t, entries, 6) // 4 exchange + 2 gas
+}
+
+func TestUnstakeHandler_Native(t *testing.T) {
+	walletID, mockRepo, access, log, ctx := setupHandler(t)
+
+	handler := staking.NewUnstakeHandler(mockRepo, access, log)
+	assert.Equal(t, ledger.TxTypeUnstake, handler.Type())
+
+	entries, err := handler.Handle(ctx, buildTestData(walletID))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+}
+
+func TestStakingRewardHandler_Handle(t *testing.T) {
+	walletID, mockRepo, access, log, ctx := setupHandler(t)
+
+	handler := staking.NewStakingRewardHandler(mockRepo, access, log)
+	assert.Equal(t, ledger.TxTypeStakingReward, handler.Type())
+
+	entries, err := handler.Handle(ctx, buildTestData(walletID))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, ledger.EntryTypeIncome, entries[1].EntryType)
+}
+
+func TestStakeHandler_ValidateData_Unauthorized(t *testing.T) {
+	walletID, mockRepo, access, log, _ := setupHandler(t)
+	otherUserCtx := context.WithValue(context.Background(), middleware.UserIDKey, uuid.New())
+
+	handler := staking.NewStakeHandler(mockRepo, access, log)
+	err := handler.ValidateData(otherUserCtx, buildTestData(walletID))
+	assert.ErrorIs(t, err, staking.ErrUnauthorized)
+}
+
+func TestStakingTransaction_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(txn *staking.StakingTransaction)
+		wantErr error
+	}{
+		{"valid native", func(txn *staking.StakingTransaction) {}, nil},
+		{"missing asset", func(txn *staking.StakingTransaction) { txn.Asset = "" }, staking.ErrInvalidAsset},
+		{"zero amount", func(txn *staking.StakingTransaction) { txn.Amount = money.NewBigInt(big.NewInt(0)) }, staking.ErrInvalidAmount},
+		{"receipt without amount", func(txn *staking.StakingTransaction) { txn.ReceiptAsset = "stETH" }, staking.ErrInvalidReceiptAmount},
+		{"valid liquid", func(txn *staking.StakingTransaction) {
+			txn.ReceiptAsset = "rETH"
+			txn.ReceiptAmount = money.NewBigInt(big.NewInt(900))
+			txn.ReceiptDecimals = 18
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn := &staking.StakingTransaction{
+				WalletID: uuid.New(),
+				TxHash:   "0xtest",
+				ChainID:  "ethereum",
+				Asset:    "ETH",
+				Amount:   money.NewBigInt(big.NewInt(1000)),
+				Decimals: 18,
+			}
+			tt.modify(txn)
+			err := txn.Validate()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package staking
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// StakingTransaction represents a staking operation.
+//
+// Asset is the staked asset (ETH, SOL, ...). For liquid staking the receipt
+// token minted on stake or burned on unstake is described by the Receipt*
+// fields; native staking leaves them empty.
+type StakingTransaction struct {
+	WalletID        uuid.UUID     `json:"wallet_id"`
+	TxHash          string        `json:"tx_hash"`
+	ChainID         string        `json:"chain_id"`
+	OccurredAt      time.Time     `json:"occurred_at"`
+	Protocol        string        `json:"protocol,omitempty"`
+	Asset           string        `json:"asset"`
+	Amount          *money.BigInt `json:"amount"`
+	Decimals        int           `json:"decimals"`
+	USDPrice        *money.BigInt `json:"usd_price,omitempty"`
+	ContractAddress string        `json:"contract_address,omitempty"`
+	ReceiptAsset    string        `json:"receipt_asset,omitempty"`
+	ReceiptAmount   *money.BigInt `json:"receipt_amount,omitempty"`
+	ReceiptDecimals int           `json:"receipt_decimals,omitempty"`
+	ReceiptUSDPrice *money.BigInt `json:"receipt_usd_price,omitempty"`
+	ReceiptContract string        `json:"receipt_contract,omitempty"`
+	FeeAsset        string        `json:"fee_asset,omitempty"`
+	FeeAmount       *money.BigInt `json:"fee_amount,omitempty"`
+	FeeDecimals     int           `json:"fee_decimals,omitempty"`
+	FeeUSDPrice     *money.BigInt `json:"fee_usd_price,omitempty"`
+}
+
+// IsLiquid returns true if the operation mints or burns a receipt token.
+func (t *StakingTransaction) IsLiquid() bool {
+	return t.ReceiptAsset != ""
+}
+
+// Validate validates the staking transaction data.
+func (t *StakingTransaction) Validate() error {
+	if t.WalletID == uuid.Nil {
+		return ErrInvalidWalletID
+	}
+	if t.TxHash == "" {
+		return ErrInvalidTxHash
+	}
+	if t.ChainID == "" {
+		return ErrInvalidChainID
+	}
+	if t.Asset == "" {
+		return ErrInvalidAsset
+	}
+	if t.Amount == nil || t.Amount.IsNil() || t.Amount.Sign() <= 0 {
+		return ErrInvalidAmount
+	}
+	if t.Decimals <= 0 {
+		return ErrInvalidDecimals
+	}
+	if t.IsLiquid() {
+		if t.ReceiptAmount == nil || t.ReceiptAmount.IsNil() || t.ReceiptAmount.Sign() <= 0 {
+			return ErrInvalidReceiptAmount
+		}
+		if t.ReceiptDecimals <= 0 {
+			return ErrInvalidDecimals
+		}
+	}
+	return nil
+}
@@ -10,5 +10,5 @@ var (
 	ErrInvalidAmount    = errors.New("invalid amount: must be positive")
 	ErrInvalidAssetID   = errors.New("invalid asset symbol")
 	ErrWalletNotFound   = errors.New("wallet not found")
-	ErrUnauthorized     = errors.New("unauthorized: wallet does not belong to user")
+	ErrUnauthorized     = errors.New("unauthorized: user cannot record transactions on wallet")
 )
@@ -3,6 +3,7 @@ package swap
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
 	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
 	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 	"github.com/kislikjeka/moontrack/pkg/money"
@@ -27,14 +29,16 @@ type WalletRepository interface {
 type SwapHandler struct {
 	ledger.BaseHandler
 	walletRepo WalletRepository
+	access     wallet.AccessChecker
 	logger     *logger.Logger
 }
 
 // NewSwapHandler creates a new swap handler
-func NewSwapHandler(walletRepo WalletRepository, log *logger.Logger) *SwapHandler {
+func NewSwapHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *SwapHandler {
 	return &SwapHandler{
 		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeSwap),
 		walletRepo:  walletRepo,
+		access:      access,
 		logger:      log.WithField("component", "swap"),
 	}
 }
@@ -84,10 +88,8 @@ func (h *SwapHandler) ValidateData(ctx context.Context, data map[string]interfac
 		return ErrWalletNotFound
 	}
 
-	if userID, ok := middleware.GetUserIDFromContext(ctx); ok && userID != uuid.Nil {
-		if w.UserID != userID {
-			return ErrUnauthorized
-		}
+	if err := authorizeWallet(ctx, h.access, w); err != nil {
+		return err
 	}
 
 	return nil
@@ -286,3 +288,18 @@ func (txn *SwapTransaction) getFeeAmount() *big.Int {
 	return txn.FeeAmount.ToBigInt()
 }
 
+// authorizeWallet checks that the authenticated user, if any, holds the
+// editor role in the wallet's workspace.
+func authorizeWallet(ctx context.Context, access wallet.AccessChecker, w *wallet.Wallet) error {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok || userID == uuid.Nil {
+		return nil
+	}
+	if err := wallet.CheckAccess(ctx, access, w, userID, workspace.RoleEditor); err != nil {
+		if errors.Is(err, wallet.ErrUnauthorizedAccess) {
+			return ErrUnauthorized
+		}
+		return err
+	}
+	return nil
+}
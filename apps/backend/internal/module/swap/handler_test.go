@@ -36,7 +36,7 @@ func (m *MockWalletRepository) GetByID(ctx context.Context, walletID uuid.UUID)
 // =============================================================================
 
 func TestSwapHandler_Type(t *testing.T) {
-	handler := swap.NewSwapHandler(nil, logger.NewDefault("test"))
+	handler := swap.NewSwapHandler(nil, nil, logger.NewDefault("test"))
 	assert.Equal(t, ledger.TxTypeSwap, handler.Type())
 }
 
@@ -56,7 +56,7 @@ func TestSwapHandler_SimpleSwap_Balance(t *testing.T) {
 		Address: "0x1234567890123456789012345678901234567890",
 	}, nil)
 
-	handler := swap.NewSwapHandler(walletRepo, logger.NewDefault("test"))
+	handler := swap.NewSwapHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	data := map[string]interface{}{
 		"wallet_id": walletID.String(),
@@ -134,7 +134,7 @@ func TestSwapHandler_WithGasFee_Balance(t *testing.T) {
 		Address: "0x1234567890123456789012345678901234567890",
 	}, nil)
 
-	handler := swap.NewSwapHandler(walletRepo, logger.NewDefault("test"))
+	handler := swap.NewSwapHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	data := map[string]interface{}{
 		"wallet_id": walletID.String(),
@@ -207,7 +207,7 @@ func TestSwapHandler_MultiAsset(t *testing.T) {
 		Address: "0x1234567890123456789012345678901234567890",
 	}, nil)
 
-	handler := swap.NewSwapHandler(walletRepo, logger.NewDefault("test"))
+	handler := swap.NewSwapHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	// Multi-hop swap: ETH + WBTC out -> USDC + DAI in
 	data := map[string]interface{}{
@@ -292,7 +292,7 @@ func TestSwapHandler_Validate_MissingFields(t *testing.T) {
 		Address: "0x1234567890123456789012345678901234567890",
 	}, nil)
 
-	handler := swap.NewSwapHandler(walletRepo, logger.NewDefault("test"))
+	handler := swap.NewSwapHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	testCases := []struct {
 		name        string
@@ -373,7 +373,7 @@ func TestSwapHandler_Validate_NoTransfers(t *testing.T) {
 		Address: "0x1234567890123456789012345678901234567890",
 	}, nil)
 
-	handler := swap.NewSwapHandler(walletRepo, logger.NewDefault("test"))
+	handler := swap.NewSwapHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	testCases := []struct {
 		name       string
@@ -427,7 +427,7 @@ func TestSwapHandler_EntryMetadata(t *testing.T) {
 		Address: "0x1234567890123456789012345678901234567890",
 	}, nil)
 
-	handler := swap.NewSwapHandler(walletRepo, logger.NewDefault("test"))
+	handler := swap.NewSwapHandler(walletRepo, nil, logger.NewDefault("test"))
 	data := validSwapData(walletID)
 
 	entries, err := handler.Handle(ctx, data)
@@ -0,0 +1,17 @@
+package tagging
+
+import "errors"
+
+var (
+	ErrTagNotFound           = errors.New("tag not found")
+	ErrTagNameRequired       = errors.New("tag name is required")
+	ErrTagNameTooLong        = errors.New("tag name exceeds 50 characters")
+	ErrCategoryTooLong       = errors.New("tag category exceeds 50 characters")
+	ErrInvalidColor          = errors.New("tag color must be a hex color like #1a2b3c")
+	ErrDuplicateTag          = errors.New("a tag with this name already exists")
+	ErrTooManyTags           = errors.New("a transaction can carry at most 20 tags")
+	ErrNoteTooLong           = errors.New("note exceeds 5000 characters")
+	ErrTransactionNotVisible = errors.New("transaction not found or not accessible")
+	ErrInvalidPeriod         = errors.New("period must be one of day, week, month, quarter, year")
+	ErrInvalidRange          = errors.New("from must be before to")
+)
@@ -0,0 +1,95 @@
+package tagging
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Limits on user-supplied annotation values
+const (
+	MaxNameLength     = 50
+	MaxCategoryLength = 50
+	MaxNoteLength     = 5000
+	MaxTagsPerTx      = 20
+)
+
+// Tag is a user-defined label for transactions, e.g. "salary" or "airdrop".
+// Category optionally groups tags for reporting ("income", "trading").
+type Tag struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Name      string
+	Category  string
+	Color     string // "#rrggbb" or empty
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TagParams are the user-editable fields of a tag
+type TagParams struct {
+	Name     string
+	Category string
+	Color    string
+}
+
+// Note is a user's free-text note on a transaction
+type Note struct {
+	TransactionID uuid.UUID
+	UserID        uuid.UUID
+	Body          string
+	UpdatedAt     time.Time
+}
+
+// Annotations are one user's tags and note on a transaction
+type Annotations struct {
+	TransactionID uuid.UUID
+	Tags          []*Tag
+	Note          *Note // nil when the user has no note
+}
+
+// Period is the bucket size for flow aggregation
+type Period string
+
+const (
+	PeriodDay     Period = "day"
+	PeriodWeek    Period = "week"
+	PeriodMonth   Period = "month"
+	PeriodQuarter Period = "quarter"
+	PeriodYear    Period = "year"
+)
+
+// IsValid checks if the period is valid
+func (p Period) IsValid() bool {
+	switch p {
+	case PeriodDay, PeriodWeek, PeriodMonth, PeriodQuarter, PeriodYear:
+		return true
+	}
+	return false
+}
+
+// FlowQuery selects the tagged flows to aggregate. From is inclusive, To exclusive.
+type FlowQuery struct {
+	Period Period
+	From   time.Time
+	To     time.Time
+	TagIDs []uuid.UUID // Empty means every tag of the user
+}
+
+// TagFlow is the USD that entered and left wallets in transactions carrying
+// a tag, within one period. USD values are scaled 10^8.
+type TagFlow struct {
+	TagID            uuid.UUID
+	TagName          string
+	Category         string
+	PeriodStart      time.Time
+	USDIn            *big.Int
+	USDOut           *big.Int
+	TransactionCount int
+}
+
+// Net returns USDIn - USDOut
+func (f *TagFlow) Net() *big.Int {
+	return new(big.Int).Sub(f.USDIn, f.USDOut)
+}
@@ -0,0 +1,38 @@
+package tagging
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/module/transactions"
+)
+
+// Repository persists tags, their assignment to transactions and notes.
+// Lookups return nil, nil when not found.
+type Repository interface {
+	// CreateTag returns ErrDuplicateTag if the user already has a tag with the same name
+	CreateTag(ctx context.Context, tag *Tag) error
+	GetTag(ctx context.Context, id uuid.UUID) (*Tag, error)
+	ListTags(ctx context.Context, userID uuid.UUID) ([]*Tag, error)
+	// UpdateTag returns ErrDuplicateTag if the new name is taken
+	UpdateTag(ctx context.Context, tag *Tag) error
+	DeleteTag(ctx context.Context, id uuid.UUID) error
+
+	// SetTransactionTags replaces the user's tags on a transaction
+	SetTransactionTags(ctx context.Context, userID, txID uuid.UUID, tagIDs []uuid.UUID) error
+	ListTransactionTags(ctx context.Context, userID, txID uuid.UUID) ([]*Tag, error)
+
+	GetNote(ctx context.Context, userID, txID uuid.UUID) (*Note, error)
+	UpsertNote(ctx context.Context, note *Note) error
+	DeleteNote(ctx context.Context, userID, txID uuid.UUID) error
+
+	// AggregateFlows sums wallet inflows and outflows of tagged transactions
+	// the user can still view, per tag and period
+	AggregateFlows(ctx context.Context, userID uuid.UUID, q FlowQuery) ([]*TagFlow, error)
+}
+
+// TransactionReader confirms the user can view a transaction
+type TransactionReader interface {
+	GetTransaction(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*transactions.TransactionDetail, error)
+}
@@ -0,0 +1,257 @@
+package tagging
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Service manages user tags and notes on ledger transactions.
+// Annotations are private to the user who made them, even on wallets
+// shared through a workspace, and never touch the immutable ledger.
+type Service struct {
+	repo   Repository
+	txs    TransactionReader
+	audit  audit.Recorder // optional
+	logger *logger.Logger
+}
+
+// NewService creates a new tagging service
+func NewService(repo Repository, txs TransactionReader, recorder audit.Recorder, log *logger.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		txs:    txs,
+		audit:  recorder,
+		logger: log.WithField("component", "tagging"),
+	}
+}
+
+// CreateTag creates a new tag for the user
+func (s *Service) CreateTag(ctx context.Context, userID uuid.UUID, params TagParams) (*Tag, error) {
+	params, err := normalizeTagParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	tag := &Tag{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      params.Name,
+		Category:  params.Category,
+		Color:     params.Color,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.CreateTag(ctx, tag); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("tag created", "tag_id", tag.ID, "user_id", userID)
+	s.record(ctx, userID, audit.ActionTagCreate, audit.TargetTag, tag.ID, nil, tag)
+	return tag, nil
+}
+
+// ListTags returns all tags of the user, ordered by name
+func (s *Service) ListTags(ctx context.Context, userID uuid.UUID) ([]*Tag, error) {
+	tags, err := s.repo.ListTags(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	return tags, nil
+}
+
+// UpdateTag renames or recategorizes a tag
+func (s *Service) UpdateTag(ctx context.Context, userID, tagID uuid.UUID, params TagParams) (*Tag, error) {
+	tag, err := s.getOwnTag(ctx, userID, tagID)
+	if err != nil {
+		return nil, err
+	}
+	params, err = normalizeTagParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *tag
+	tag.Name = params.Name
+	tag.Category = params.Category
+	tag.Color = params.Color
+	tag.UpdatedAt = time.Now().UTC()
+	if err := s.repo.UpdateTag(ctx, tag); err != nil {
+		return nil, err
+	}
+	s.record(ctx, userID, audit.ActionTagUpdate, audit.TargetTag, tag.ID, &before, tag)
+	return tag, nil
+}
+
+// DeleteTag deletes a tag and removes it from every transaction
+func (s *Service) DeleteTag(ctx context.Context, userID, tagID uuid.UUID) error {
+	tag, err := s.getOwnTag(ctx, userID, tagID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteTag(ctx, tagID); err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	s.logger.Info("tag deleted", "tag_id", tagID, "user_id", userID)
+	s.record(ctx, userID, audit.ActionTagDelete, audit.TargetTag, tagID, tag, nil)
+	return nil
+}
+
+// GetAnnotations returns the user's tags and note on a transaction
+func (s *Service) GetAnnotations(ctx context.Context, userID, txID uuid.UUID) (*Annotations, error) {
+	if err := s.verifyTransaction(ctx, userID, txID); err != nil {
+		return nil, err
+	}
+
+	tags, err := s.repo.ListTransactionTags(ctx, userID, txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transaction tags: %w", err)
+	}
+	note, err := s.repo.GetNote(ctx, userID, txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get note: %w", err)
+	}
+
+	return &Annotations{TransactionID: txID, Tags: tags, Note: note}, nil
+}
+
+// SetTransactionTags replaces the user's tags on a transaction.
+// An empty list removes them all.
+func (s *Service) SetTransactionTags(ctx context.Context, userID, txID uuid.UUID, tagIDs []uuid.UUID) (*Annotations, error) {
+	unique := make([]uuid.UUID, 0, len(tagIDs))
+	seen := make(map[uuid.UUID]bool, len(tagIDs))
+	for _, id := range tagIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > MaxTagsPerTx {
+		return nil, ErrTooManyTags
+	}
+
+	if err := s.verifyTransaction(ctx, userID, txID); err != nil {
+		return nil, err
+	}
+	for _, id := range unique {
+		if _, err := s.getOwnTag(ctx, userID, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.SetTransactionTags(ctx, userID, txID, unique); err != nil {
+		return nil, fmt.Errorf("failed to set transaction tags: %w", err)
+	}
+	s.record(ctx, userID, audit.ActionTransactionTags, audit.TargetTransaction, txID, nil, map[string]any{"tag_ids": unique})
+	return s.GetAnnotations(ctx, userID, txID)
+}
+
+// SetNote stores the user's note on a transaction. A blank body removes it.
+func (s *Service) SetNote(ctx context.Context, userID, txID uuid.UUID, body string) (*Annotations, error) {
+	body = strings.TrimSpace(body)
+	if len(body) > MaxNoteLength {
+		return nil, ErrNoteTooLong
+	}
+	if err := s.verifyTransaction(ctx, userID, txID); err != nil {
+		return nil, err
+	}
+
+	if body == "" {
+		if err := s.repo.DeleteNote(ctx, userID, txID); err != nil {
+			return nil, fmt.Errorf("failed to delete note: %w", err)
+		}
+	} else {
+		note := &Note{TransactionID: txID, UserID: userID, Body: body, UpdatedAt: time.Now().UTC()}
+		if err := s.repo.UpsertNote(ctx, note); err != nil {
+			return nil, fmt.Errorf("failed to save note: %w", err)
+		}
+	}
+	s.record(ctx, userID, audit.ActionTransactionNote, audit.TargetTransaction, txID, nil, map[string]any{"body": body})
+	return s.GetAnnotations(ctx, userID, txID)
+}
+
+// GetFlows returns USD in/out per tag per period for the user's tagged transactions
+func (s *Service) GetFlows(ctx context.Context, userID uuid.UUID, q FlowQuery) ([]*TagFlow, error) {
+	if !q.Period.IsValid() {
+		return nil, ErrInvalidPeriod
+	}
+	if !q.From.Before(q.To) {
+		return nil, ErrInvalidRange
+	}
+	for _, id := range q.TagIDs {
+		if _, err := s.getOwnTag(ctx, userID, id); err != nil {
+			return nil, err
+		}
+	}
+
+	flows, err := s.repo.AggregateFlows(ctx, userID, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate tag flows: %w", err)
+	}
+	return flows, nil
+}
+
+// getOwnTag loads a tag, hiding tags of other users
+// record reports an annotation change to the audit log, if one is configured
+func (s *Service) record(ctx context.Context, userID uuid.UUID, action audit.Action, targetType string, targetID uuid.UUID, before, after any) {
+	if s.audit == nil {
+		return
+	}
+	s.audit.Record(ctx, audit.Event{
+		ActorID:    userID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID.String(),
+		Before:     before,
+		After:      after,
+	})
+}
+
+func (s *Service) getOwnTag(ctx context.Context, userID, tagID uuid.UUID) (*Tag, error) {
+	tag, err := s.repo.GetTag(ctx, tagID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	if tag == nil || tag.UserID != userID {
+		return nil, ErrTagNotFound
+	}
+	return tag, nil
+}
+
+func (s *Service) verifyTransaction(ctx context.Context, userID, txID uuid.UUID) error {
+	if _, err := s.txs.GetTransaction(ctx, txID, userID); err != nil {
+		return ErrTransactionNotVisible
+	}
+	return nil
+}
+
+func normalizeTagParams(p TagParams) (TagParams, error) {
+	p.Name = strings.TrimSpace(p.Name)
+	p.Category = strings.TrimSpace(p.Category)
+	p.Color = strings.ToLower(strings.TrimSpace(p.Color))
+
+	if p.Name == "" {
+		return p, ErrTagNameRequired
+	}
+	if len(p.Name) > MaxNameLength {
+		return p, ErrTagNameTooLong
+	}
+	if len(p.Category) > MaxCategoryLength {
+		return p, ErrCategoryTooLong
+	}
+	if p.Color != "" && !colorPattern.MatchString(p.Color) {
+		return p, ErrInvalidColor
+	}
+	return p, nil
+}
@@ -0,0 +1,231 @@
+package tagging
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/module/transactions"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// mockRepo is an in-memory implementation of Repository for testing.
+type mockRepo struct {
+	tags     map[uuid.UUID]*Tag
+	assigned map[uuid.UUID][]uuid.UUID // tx → tag IDs
+	notes    map[uuid.UUID]*Note       // tx → note
+	query    *FlowQuery
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{
+		tags:     make(map[uuid.UUID]*Tag),
+		assigned: make(map[uuid.UUID][]uuid.UUID),
+		notes:    make(map[uuid.UUID]*Note),
+	}
+}
+
+func (r *mockRepo) CreateTag(_ context.Context, tag *Tag) error {
+	for _, t := range r.tags {
+		if t.UserID == tag.UserID && strings.EqualFold(t.Name, tag.Name) {
+			return ErrDuplicateTag
+		}
+	}
+	r.tags[tag.ID] = tag
+	return nil
+}
+
+func (r *mockRepo) GetTag(_ context.Context, id uuid.UUID) (*Tag, error) {
+	tag, ok := r.tags[id]
+	if !ok {
+		return nil, nil
+	}
+	cp := *tag
+	return &cp, nil
+}
+
+func (r *mockRepo) ListTags(_ context.Context, userID uuid.UUID) ([]*Tag, error) {
+	var result []*Tag
+	for _, t := range r.tags {
+		if t.UserID == userID {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+func (r *mockRepo) UpdateTag(_ context.Context, tag *Tag) error {
+	r.tags[tag.ID] = tag
+	return nil
+}
+
+func (r *mockRepo) DeleteTag(_ context.Context, id uuid.UUID) error {
+	delete(r.tags, id)
+	for txID, ids := range r.assigned {
+		kept := ids[:0]
+		for _, tagID := range ids {
+			if tagID != id {
+				kept = append(kept, tagID)
+			}
+		}
+		r.assigned[txID] = kept
+	}
+	return nil
+}
+
+func (r *mockRepo) SetTransactionTags(_ context.Context, _, txID uuid.UUID, tagIDs []uuid.UUID) error {
+	r.assigned[txID] = tagIDs
+	return nil
+}
+
+func (r *mockRepo) ListTransactionTags(_ context.Context, _, txID uuid.UUID) ([]*Tag, error) {
+	var result []*Tag
+	for _, id := range r.assigned[txID] {
+		result = append(result, r.tags[id])
+	}
+	return result, nil
+}
+
+func (r *mockRepo) GetNote(_ context.Context, _, txID uuid.UUID) (*Note, error) {
+	return r.notes[txID], nil
+}
+
+func (r *mockRepo) UpsertNote(_ context.Context, note *Note) error {
+	r.notes[note.TransactionID] = note
+	return nil
+}
+
+func (r *mockRepo) DeleteNote(_ context.Context, _, txID uuid.UUID) error {
+	delete(r.notes, txID)
+	return nil
+}
+
+func (r *mockRepo) AggregateFlows(_ context.Context, _ uuid.UUID, q FlowQuery) ([]*TagFlow, error) {
+	r.query = &q
+	return nil, nil
+}
+
+// mockTxs makes every transaction in visible viewable by any user
+type mockTxs struct {
+	visible map[uuid.UUID]bool
+}
+
+func (m *mockTxs) GetTransaction(_ context.Context, id uuid.UUID, _ uuid.UUID) (*transactions.TransactionDetail, error) {
+	if !m.visible[id] {
+		return nil, errors.New("transaction not found")
+	}
+	return &transactions.TransactionDetail{}, nil
+}
+
+func newTestService(t *testing.T) (*Service, *mockRepo, uuid.UUID) {
+	t.Helper()
+	repo := newMockRepo()
+	txID := uuid.New()
+	svc := NewService(repo, &mockTxs{visible: map[uuid.UUID]bool{txID: true}}, nil, logger.NewDefault("test"))
+	return svc, repo, txID
+}
+
+func TestCreateTag_Validation(t *testing.T) {
+	svc, _, _ := newTestService(t)
+	userID := uuid.New()
+
+	_, err := svc.CreateTag(context.Background(), userID, TagParams{Name: "  "})
+	assert.ErrorIs(t, err, ErrTagNameRequired)
+
+	_, err = svc.CreateTag(context.Background(), userID, TagParams{Name: strings.Repeat("x", 51)})
+	assert.ErrorIs(t, err, ErrTagNameTooLong)
+
+	_, err = svc.CreateTag(context.Background(), userID, TagParams{Name: "salary", Color: "red"})
+	assert.ErrorIs(t, err, ErrInvalidColor)
+
+	tag, err := svc.CreateTag(context.Background(), userID, TagParams{Name: " salary ", Category: "income", Color: "#A1B2C3"})
+	require.NoError(t, err)
+	assert.Equal(t, "salary", tag.Name)
+	assert.Equal(t, "#a1b2c3", tag.Color)
+
+	_, err = svc.CreateTag(context.Background(), userID, TagParams{Name: "Salary"})
+	assert.ErrorIs(t, err, ErrDuplicateTag)
+}
+
+func TestUpdateTag_OtherUsersTagNotFound(t *testing.T) {
+	svc, _, _ := newTestService(t)
+	tag, err := svc.CreateTag(context.Background(), uuid.New(), TagParams{Name: "gift"})
+	require.NoError(t, err)
+
+	_, err = svc.UpdateTag(context.Background(), uuid.New(), tag.ID, TagParams{Name: "mine"})
+	assert.ErrorIs(t, err, ErrTagNotFound)
+
+	err = svc.DeleteTag(context.Background(), uuid.New(), tag.ID)
+	assert.ErrorIs(t, err, ErrTagNotFound)
+}
+
+func TestSetTransactionTags(t *testing.T) {
+	svc, _, txID := newTestService(t)
+	userID := uuid.New()
+	salary, err := svc.CreateTag(context.Background(), userID, TagParams{Name: "salary"})
+	require.NoError(t, err)
+
+	ann, err := svc.SetTransactionTags(context.Background(), userID, txID, []uuid.UUID{salary.ID, salary.ID})
+	require.NoError(t, err)
+	require.Len(t, ann.Tags, 1)
+	assert.Equal(t, salary.ID, ann.Tags[0].ID)
+
+	// Tags of another user cannot be attached
+	foreign, err := svc.CreateTag(context.Background(), uuid.New(), TagParams{Name: "bot trading"})
+	require.NoError(t, err)
+	_, err = svc.SetTransactionTags(context.Background(), userID, txID, []uuid.UUID{foreign.ID})
+	assert.ErrorIs(t, err, ErrTagNotFound)
+
+	// Transactions the user cannot view are hidden
+	_, err = svc.SetTransactionTags(context.Background(), userID, uuid.New(), []uuid.UUID{salary.ID})
+	assert.ErrorIs(t, err, ErrTransactionNotVisible)
+
+	ann, err = svc.SetTransactionTags(context.Background(), userID, txID, nil)
+	require.NoError(t, err)
+	assert.Empty(t, ann.Tags)
+}
+
+func TestSetNote_BlankRemoves(t *testing.T) {
+	svc, repo, txID := newTestService(t)
+	userID := uuid.New()
+
+	ann, err := svc.SetNote(context.Background(), userID, txID, "  paid by employer  ")
+	require.NoError(t, err)
+	require.NotNil(t, ann.Note)
+	assert.Equal(t, "paid by employer", ann.Note.Body)
+
+	_, err = svc.SetNote(context.Background(), userID, txID, strings.Repeat("x", MaxNoteLength+1))
+	assert.ErrorIs(t, err, ErrNoteTooLong)
+
+	ann, err = svc.SetNote(context.Background(), userID, txID, " ")
+	require.NoError(t, err)
+	assert.Nil(t, ann.Note)
+	assert.Empty(t, repo.notes)
+}
+
+func TestGetFlows_Validation(t *testing.T) {
+	svc, repo, _ := newTestService(t)
+	userID := uuid.New()
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(1, 0, 0)
+
+	_, err := svc.GetFlows(context.Background(), userID, FlowQuery{Period: "decade", From: from, To: to})
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	_, err = svc.GetFlows(context.Background(), userID, FlowQuery{Period: PeriodMonth, From: to, To: from})
+	assert.ErrorIs(t, err, ErrInvalidRange)
+
+	_, err = svc.GetFlows(context.Background(), userID, FlowQuery{Period: PeriodMonth, From: from, To: to, TagIDs: []uuid.UUID{uuid.New()}})
+	assert.ErrorIs(t, err, ErrTagNotFound)
+
+	_, err = svc.GetFlows(context.Background(), userID, FlowQuery{Period: PeriodMonth, From: from, To: to})
+	require.NoError(t, err)
+	require.NotNil(t, repo.query)
+	assert.Equal(t, PeriodMonth, repo.query.Period)
+}
@@ -0,0 +1,8 @@
+package taxanalysis
+
+import "errors"
+
+var (
+	ErrInvalidYear = errors.New("invalid tax year")
+	ErrInvalidRate = errors.New("tax rates must be between 0 and 1")
+)
@@ -0,0 +1,79 @@
+package taxanalysis
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WashSaleWindow is how far before or after a loss sale a purchase of the
+// same asset makes the loss a wash sale.
+const WashSaleWindow = 30 * 24 * time.Hour
+
+// LongTermHoldingYears is the holding period, in calendar years, after which
+// gains are long-term (see ledger.HeldOverYears).
+const LongTermHoldingYears = 1
+
+// WashSale is a loss disposal with replacement purchases inside the window.
+// USD values are scaled by 10^8; losses are negative.
+type WashSale struct {
+	DisposalID       uuid.UUID
+	TransactionID    uuid.UUID
+	LotID            uuid.UUID
+	WalletID         uuid.UUID
+	Asset            string
+	DisposedAt       time.Time
+	QuantityDisposed *big.Int
+	Loss             *big.Int // Realized loss of the whole disposal
+	DisallowedLoss   *big.Int // Positive; the part deferred into replacements
+	Replacements     []*Replacement
+}
+
+// Replacement is the part of a purchase that replaced sold units. The
+// disallowed loss is added to its cost basis.
+type Replacement struct {
+	LotID                    uuid.UUID
+	TransactionID            uuid.UUID
+	WalletID                 uuid.UUID
+	AcquiredAt               time.Time
+	Quantity                 *big.Int // Units matched to the loss sale
+	BasisAdjustment          *big.Int // Disallowed loss added to this lot
+	AdjustedCostBasisPerUnit *big.Int // Effective cost basis plus the adjustment per unit
+}
+
+// Rates are the marginal tax rates used to estimate savings, in basis points.
+type Rates struct {
+	ShortTermBps int64
+	LongTermBps  int64
+}
+
+// DefaultRates are used when the caller does not supply rates
+var DefaultRates = Rates{ShortTermBps: 2400, LongTermBps: 1500}
+
+// HarvestCandidate is an open lot trading below its cost basis.
+type HarvestCandidate struct {
+	LotID             uuid.UUID
+	WalletID          uuid.UUID
+	ChainID           string
+	Asset             string
+	AcquiredAt        time.Time
+	QuantityRemaining *big.Int
+	CostBasisPerUnit  *big.Int
+	CurrentPrice      *big.Int
+	UnrealizedLoss    *big.Int // Negative
+	LongTerm          bool
+	EstimatedSaving   *big.Int
+	// WashSaleRisk is set when the asset was bought in the last 30 days, so
+	// selling now would be a wash sale unless those units are sold too.
+	WashSaleRisk bool
+}
+
+// HarvestReport lists loss-harvesting candidates, largest loss first.
+type HarvestReport struct {
+	Rates                Rates
+	Candidates           []*HarvestCandidate
+	TotalUnrealizedLoss  *big.Int
+	TotalEstimatedSaving *big.Int
+	PricedAt             time.Time
+}
@@ -0,0 +1,20 @@
+package taxanalysis
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/taxlot"
+)
+
+// LotHistorySource supplies the user's lots and disposals across wallets
+type LotHistorySource interface {
+	GetLotHistory(ctx context.Context, userID uuid.UUID) ([]*taxlot.LotHistory, error)
+}
+
+// PriceService supplies current USD prices (scaled by 10^8); zero means unknown
+type PriceService interface {
+	GetPriceBySymbol(ctx context.Context, symbol string) (*big.Int, error)
+}
@@ -0,0 +1,145 @@
+package taxanalysis
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// Service analyses tax lots for wash sales and loss-harvesting opportunities.
+// It only reads lots; nothing it reports is written back to the ledger.
+type Service struct {
+	lots   LotHistorySource
+	prices PriceService
+	logger *logger.Logger
+}
+
+// NewService creates a new tax analysis service
+func NewService(lots LotHistorySource, prices PriceService, log *logger.Logger) *Service {
+	return &Service{
+		lots:   lots,
+		prices: prices,
+		logger: log.WithField("component", "taxanalysis"),
+	}
+}
+
+// GetWashSales returns the wash sales among a calendar year's loss sales.
+// Replacement purchases up to 30 days into the next year are considered.
+func (s *Service) GetWashSales(ctx context.Context, userID uuid.UUID, year int) ([]*WashSale, error) {
+	if year < 2009 || year > time.Now().UTC().Year() {
+		return nil, ErrInvalidYear
+	}
+
+	histories, err := s.lots.GetLotHistory(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lot history: %w", err)
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return DetectWashSales(histories, from, from.AddDate(1, 0, 0)), nil
+}
+
+// GetHarvestCandidates lists open lots whose current price is below their
+// effective cost basis, with the tax a sale would save at the given rates.
+// Assets without a current price are skipped.
+func (s *Service) GetHarvestCandidates(ctx context.Context, userID uuid.UUID, rates Rates) (*HarvestReport, error) {
+	if !validRate(rates.ShortTermBps) || !validRate(rates.LongTermBps) {
+		return nil, ErrInvalidRate
+	}
+
+	histories, err := s.lots.GetLotHistory(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lot history: %w", err)
+	}
+
+	now := time.Now().UTC()
+	report := &HarvestReport{
+		Rates:                rates,
+		TotalUnrealizedLoss:  new(big.Int),
+		TotalEstimatedSaving: new(big.Int),
+		PricedAt:             now,
+	}
+
+	// Assets bought inside the wash-sale window before today
+	recentlyBought := make(map[string]bool)
+	for _, h := range histories {
+		if h.IsPurchase() && now.Sub(h.Lot.AcquiredAt) <= WashSaleWindow {
+			recentlyBought[strings.ToUpper(h.Lot.Asset)] = true
+		}
+	}
+
+	prices := make(map[string]*big.Int)
+	for _, h := range histories {
+		lot := h.Lot
+		if !lot.IsOpen() {
+			continue
+		}
+
+		asset := strings.ToUpper(lot.Asset)
+		price, ok := prices[asset]
+		if !ok {
+			price, err = s.prices.GetPriceBySymbol(ctx, lot.Asset)
+			if err != nil {
+				s.logger.Warn("failed to get price for harvest analysis", "asset", lot.Asset, "error", err)
+				price = nil
+			}
+			prices[asset] = price
+		}
+		if price == nil || price.Sign() <= 0 {
+			continue
+		}
+
+		basis := lot.EffectiveCostBasisPerUnit()
+		if price.Cmp(basis) >= 0 {
+			continue
+		}
+
+		loss := new(big.Int).Sub(price, basis)
+		loss.Mul(loss, lot.QuantityRemaining)
+		loss.Div(loss, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(money.GetDecimals(lot.Asset))), nil))
+
+		longTerm := ledger.HeldOverYears(lot.AcquiredAt, now, LongTermHoldingYears)
+		rate := rates.ShortTermBps
+		if longTerm {
+			rate = rates.LongTermBps
+		}
+		saving := new(big.Int).Neg(loss)
+		saving.Mul(saving, big.NewInt(rate))
+		saving.Div(saving, big.NewInt(10000))
+
+		report.Candidates = append(report.Candidates, &HarvestCandidate{
+			LotID:             lot.ID,
+			WalletID:          h.WalletID,
+			ChainID:           lot.ChainID,
+			Asset:             lot.Asset,
+			AcquiredAt:        lot.AcquiredAt,
+			QuantityRemaining: lot.QuantityRemaining,
+			CostBasisPerUnit:  basis,
+			CurrentPrice:      price,
+			UnrealizedLoss:    loss,
+			LongTerm:          longTerm,
+			EstimatedSaving:   saving,
+			WashSaleRisk:      recentlyBought[asset],
+		})
+		report.TotalUnrealizedLoss.Add(report.TotalUnrealizedLoss, loss)
+		report.TotalEstimatedSaving.Add(report.TotalEstimatedSaving, saving)
+	}
+
+	sort.SliceStable(report.Candidates, func(i, j int) bool {
+		return report.Candidates[i].UnrealizedLoss.Cmp(report.Candidates[j].UnrealizedLoss) < 0
+	})
+	return report, nil
+}
+
+func validRate(bps int64) bool {
+	return bps >= 0 && bps <= 10000
+}
@@ -0,0 +1,158 @@
+package taxanalysis
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/taxlot"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+type mockLots []*taxlot.LotHistory
+
+func (m mockLots) GetLotHistory(_ context.Context, _ uuid.UUID) ([]*taxlot.LotHistory, error) {
+	return m, nil
+}
+
+type mockPrices map[string]int64
+
+func (m mockPrices) GetPriceBySymbol(_ context.Context, symbol string) (*big.Int, error) {
+	return big.NewInt(m[symbol]), nil
+}
+
+// usd scales whole dollars to the 10^8 representation
+func usd(dollars int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(dollars), big.NewInt(1e8))
+}
+
+// btc scales whole coins to satoshis
+func btc(coins int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(coins), big.NewInt(1e8))
+}
+
+func newLot(walletID uuid.UUID, acquiredAt time.Time, qty *big.Int, basis int64) *taxlot.LotHistory {
+	return &taxlot.LotHistory{
+		WalletID: walletID,
+		Lot: &ledger.TaxLot{
+			ID:                   uuid.New(),
+			TransactionID:        uuid.New(),
+			Asset:                "BTC",
+			QuantityAcquired:     qty,
+			QuantityRemaining:    new(big.Int).Set(qty),
+			AcquiredAt:           acquiredAt,
+			AutoCostBasisPerUnit: usd(basis),
+			AutoCostBasisSource:  ledger.CostBasisSwapPrice,
+		},
+	}
+}
+
+func sell(h *taxlot.LotHistory, at time.Time, qty *big.Int, price int64) {
+	h.Disposals = append(h.Disposals, &ledger.LotDisposal{
+		ID:               uuid.New(),
+		TransactionID:    uuid.New(),
+		LotID:            h.Lot.ID,
+		QuantityDisposed: qty,
+		ProceedsPerUnit:  usd(price),
+		DisposalType:     ledger.DisposalTypeSale,
+		DisposedAt:       at,
+	})
+	h.Lot.QuantityRemaining.Sub(h.Lot.QuantityRemaining, qty)
+}
+
+func TestDetectWashSales(t *testing.T) {
+	walletA, walletB := uuid.New(), uuid.New()
+	day := func(d int) time.Time { return time.Date(2024, time.March, d, 12, 0, 0, 0, time.UTC) }
+
+	sold := newLot(walletA, day(1), btc(2), 100)
+	sell(sold, day(10), btc(2), 60)
+
+	// Bought back in another wallet within 30 days; the internal transfer
+	// of that lot is not a second purchase
+	replacement := newLot(walletB, day(20), btc(1), 70)
+	moved := newLot(walletA, day(21), btc(1), 70)
+	moved.Lot.LinkedSourceLotID = &replacement.Lot.ID
+	moved.Lot.AutoCostBasisSource = ledger.CostBasisLinkedTransfer
+
+	// A gain and a loss without a repurchase are not wash sales
+	gain := newLot(walletA, day(1).AddDate(0, -2, 0), btc(1), 50)
+	sell(gain, day(11), btc(1), 60)
+	late := newLot(walletA, time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC), btc(1), 100)
+	sell(late, time.Date(2024, time.June, 2, 0, 0, 0, 0, time.UTC), btc(1), 90)
+
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	result := DetectWashSales([]*taxlot.LotHistory{sold, replacement, moved, gain, late}, from, from.AddDate(1, 0, 0))
+
+	require.Len(t, result, 1)
+	ws := result[0]
+	assert.Equal(t, sold.Lot.ID, ws.LotID)
+	assert.Equal(t, usd(-80).String(), ws.Loss.String())
+	// Only one of the two coins was replaced, so half the loss is disallowed
+	assert.Equal(t, usd(40).String(), ws.DisallowedLoss.String())
+
+	require.Len(t, ws.Replacements, 1)
+	r := ws.Replacements[0]
+	assert.Equal(t, replacement.Lot.ID, r.LotID)
+	assert.Equal(t, walletB, r.WalletID)
+	assert.Equal(t, btc(1).String(), r.Quantity.String())
+	assert.Equal(t, usd(110).String(), r.AdjustedCostBasisPerUnit.String())
+}
+
+func TestDetectWashSales_ReplacementUsedOnce(t *testing.T) {
+	walletID := uuid.New()
+	day := func(d int) time.Time { return time.Date(2024, time.May, d, 12, 0, 0, 0, time.UTC) }
+
+	first := newLot(walletID, day(1), btc(1), 100)
+	sell(first, day(5), btc(1), 80)
+	second := newLot(walletID, day(2), btc(1), 100)
+	sell(second, day(6), btc(1), 80)
+	buyBack := newLot(walletID, day(8), btc(1), 85)
+
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	result := DetectWashSales([]*taxlot.LotHistory{first, second, buyBack}, from, from.AddDate(1, 0, 0))
+
+	// The second lot replaces the first sale; the buy-back replaces the second
+	require.Len(t, result, 2)
+	assert.Equal(t, second.Lot.ID, result[0].Replacements[0].LotID)
+	assert.Equal(t, buyBack.Lot.ID, result[1].Replacements[0].LotID)
+	assert.Equal(t, usd(105).String(), result[1].Replacements[0].AdjustedCostBasisPerUnit.String())
+}
+
+func TestGetHarvestCandidates(t *testing.T) {
+	walletID := uuid.New()
+	now := time.Now().UTC()
+
+	longHeld := newLot(walletID, now.AddDate(-2, 0, 0), btc(1), 150)
+	recent := newLot(walletID, now.AddDate(0, 0, -5), btc(2), 120)
+	profitable := newLot(walletID, now.AddDate(-1, -6, 0), btc(1), 50)
+	unpriced := newLot(walletID, now.AddDate(0, -2, 0), btc(1), 10)
+	unpriced.Lot.Asset = "OBSCURE"
+
+	svc := NewService(mockLots{longHeld, recent, profitable, unpriced}, mockPrices{"BTC": usd(100).Int64()}, logger.NewDefault("test"))
+	report, err := svc.GetHarvestCandidates(context.Background(), uuid.New(), DefaultRates)
+	require.NoError(t, err)
+
+	require.Len(t, report.Candidates, 2)
+	first, second := report.Candidates[0], report.Candidates[1]
+	assert.Equal(t, longHeld.Lot.ID, first.LotID)
+	assert.True(t, first.LongTerm)
+	assert.Equal(t, usd(-50).String(), first.UnrealizedLoss.String())
+	assert.Equal(t, "750000000", first.EstimatedSaving.String()) // 15% of $50
+
+	assert.Equal(t, recent.Lot.ID, second.LotID)
+	assert.False(t, second.LongTerm)
+	assert.Equal(t, usd(-40).String(), second.UnrealizedLoss.String())
+	assert.Equal(t, "960000000", second.EstimatedSaving.String()) // 24% of $40
+	assert.True(t, second.WashSaleRisk)
+
+	assert.Equal(t, usd(-90).String(), report.TotalUnrealizedLoss.String())
+
+	_, err = svc.GetHarvestCandidates(context.Background(), uuid.New(), Rates{ShortTermBps: 12000})
+	assert.ErrorIs(t, err, ErrInvalidRate)
+}
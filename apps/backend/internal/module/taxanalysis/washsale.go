@@ -0,0 +1,163 @@
+package taxanalysis
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/taxlot"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// acquisition is a lot that counts as a purchase for wash-sale matching,
+// with the quantity not yet used to replace an earlier loss sale
+type acquisition struct {
+	history   *taxlot.LotHistory
+	available *big.Int
+}
+
+type lossSale struct {
+	history  *taxlot.LotHistory
+	disposal *ledger.LotDisposal
+	loss     *big.Int
+}
+
+// DetectWashSales finds loss sales disposed in [from, to) that have purchases
+// of the same asset within WashSaleWindow before or after them, in any of the
+// given wallets.
+//
+// Sales are processed in time order and each purchased unit replaces at most
+// one sold unit, earliest purchase first. Units must still be held at the
+// time of the sale to replace it. Lots created by internal transfers
+// or lending moves are not purchases. Lots are never modified: the returned
+// adjustments are what the replacement lots' basis would become.
+func DetectWashSales(histories []*taxlot.LotHistory, from, to time.Time) []*WashSale {
+	acquisitions := make(map[string][]*acquisition)
+	var sales []*lossSale
+
+	for _, h := range histories {
+		asset := strings.ToUpper(h.Lot.Asset)
+		if h.IsPurchase() {
+			acquisitions[asset] = append(acquisitions[asset], &acquisition{
+				history:   h,
+				available: new(big.Int).Set(h.Lot.QuantityAcquired),
+			})
+		}
+
+		for _, d := range h.Disposals {
+			if d.DisposalType != ledger.DisposalTypeSale || d.ProceedsPerUnit == nil {
+				continue
+			}
+			if d.DisposedAt.Before(from) || !d.DisposedAt.Before(to) {
+				continue
+			}
+			if loss := realizedGain(h.Lot, d); loss.Sign() < 0 {
+				sales = append(sales, &lossSale{history: h, disposal: d, loss: loss})
+			}
+		}
+	}
+
+	for _, list := range acquisitions {
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].history.Lot.AcquiredAt.Before(list[j].history.Lot.AcquiredAt)
+		})
+	}
+	sort.SliceStable(sales, func(i, j int) bool {
+		return sales[i].disposal.DisposedAt.Before(sales[j].disposal.DisposedAt)
+	})
+
+	var result []*WashSale
+	for _, s := range sales {
+		lot := s.history.Lot
+		ws := &WashSale{
+			DisposalID:       s.disposal.ID,
+			TransactionID:    s.disposal.TransactionID,
+			LotID:            lot.ID,
+			WalletID:         s.history.WalletID,
+			Asset:            lot.Asset,
+			DisposedAt:       s.disposal.DisposedAt,
+			QuantityDisposed: s.disposal.QuantityDisposed,
+			Loss:             s.loss,
+			DisallowedLoss:   new(big.Int),
+		}
+
+		unmatched := new(big.Int).Set(s.disposal.QuantityDisposed)
+		absLoss := new(big.Int).Neg(s.loss)
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(money.GetDecimals(lot.Asset))), nil)
+
+		for _, a := range acquisitions[strings.ToUpper(lot.Asset)] {
+			if unmatched.Sign() == 0 {
+				break
+			}
+			if !withinWindow(a.history.Lot.AcquiredAt, s.disposal.DisposedAt) {
+				continue
+			}
+			// Units already sold by the time of the loss sale, including
+			// by the sale itself, cannot replace it
+			usable := minInt(a.available, heldAt(a.history, s.disposal.DisposedAt))
+			if usable.Sign() <= 0 {
+				continue
+			}
+
+			matched := minInt(unmatched, usable)
+			unmatched.Sub(unmatched, matched)
+			a.available.Sub(a.available, matched)
+
+			adjustment := new(big.Int).Mul(absLoss, matched)
+			adjustment.Div(adjustment, s.disposal.QuantityDisposed)
+			ws.DisallowedLoss.Add(ws.DisallowedLoss, adjustment)
+
+			perUnit := new(big.Int).Mul(adjustment, scale)
+			perUnit.Div(perUnit, matched)
+			perUnit.Add(perUnit, a.history.Lot.EffectiveCostBasisPerUnit())
+
+			ws.Replacements = append(ws.Replacements, &Replacement{
+				LotID:                    a.history.Lot.ID,
+				TransactionID:            a.history.Lot.TransactionID,
+				WalletID:                 a.history.WalletID,
+				AcquiredAt:               a.history.Lot.AcquiredAt,
+				Quantity:                 matched,
+				BasisAdjustment:          adjustment,
+				AdjustedCostBasisPerUnit: perUnit,
+			})
+		}
+
+		if len(ws.Replacements) > 0 {
+			result = append(result, ws)
+		}
+	}
+	return result
+}
+
+// realizedGain returns (proceeds - cost) * qty in USD scaled by 10^8
+func realizedGain(lot *ledger.TaxLot, d *ledger.LotDisposal) *big.Int {
+	gain := new(big.Int).Sub(d.ProceedsPerUnit, lot.EffectiveCostBasisPerUnit())
+	gain.Mul(gain, d.QuantityDisposed)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(money.GetDecimals(lot.Asset))), nil)
+	return gain.Div(gain, scale)
+}
+
+// heldAt returns the quantity of a lot not yet disposed of at the given time
+func heldAt(h *taxlot.LotHistory, at time.Time) *big.Int {
+	held := new(big.Int).Set(h.Lot.QuantityAcquired)
+	for _, d := range h.Disposals {
+		if !d.DisposedAt.After(at) {
+			held.Sub(held, d.QuantityDisposed)
+		}
+	}
+	return held
+}
+
+func withinWindow(acquiredAt, disposedAt time.Time) bool {
+	diff := acquiredAt.Sub(disposedAt)
+	return diff >= -WashSaleWindow && diff <= WashSaleWindow
+}
+
+func minInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
@@ -0,0 +1,8 @@
+package taxprofile
+
+import "errors"
+
+var (
+	ErrInvalidJurisdiction = errors.New("unsupported tax jurisdiction")
+	ErrInvalidYear         = errors.New("invalid tax year")
+)
@@ -0,0 +1,102 @@
+package taxprofile
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/taxlot"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// maxLinkDepth bounds the walk back through internally transferred lots
+const maxLinkDepth = 32
+
+// fifoDisposals reports the taxable disposals in [from, to) against the lots
+// the ledger consumed. The holding period starts when the units were first
+// acquired, not when they last moved between the user's wallets.
+func fifoDisposals(histories []*taxlot.LotHistory, rules Rules, from, to time.Time) []*Disposal {
+	lots := make(map[uuid.UUID]*ledger.TaxLot, len(histories))
+	for _, h := range histories {
+		lots[h.Lot.ID] = h.Lot
+	}
+
+	var result []*Disposal
+	for _, h := range histories {
+		lot := h.Lot
+		for _, d := range h.Disposals {
+			if !isTaxable(d) || d.DisposedAt.Before(from) || !d.DisposedAt.Before(to) {
+				continue
+			}
+
+			scale := decimalScale(lot.Asset)
+			proceeds := new(big.Int)
+			if d.ProceedsPerUnit != nil {
+				proceeds.Mul(d.ProceedsPerUnit, d.QuantityDisposed)
+				proceeds.Div(proceeds, scale)
+			}
+			cost := new(big.Int).Mul(lot.EffectiveCostBasisPerUnit(), d.QuantityDisposed)
+			cost.Div(cost, scale)
+
+			acquiredAt := originalAcquisition(lot, lots)
+			term := TermShort
+			if rules.IsLongTerm(acquiredAt, d.DisposedAt) {
+				term = TermLong
+			}
+
+			lotID := lot.ID
+			result = append(result, &Disposal{
+				TransactionID: d.TransactionID,
+				Asset:         lot.Asset,
+				DisposedAt:    d.DisposedAt,
+				Quantity:      d.QuantityDisposed,
+				Proceeds:      proceeds,
+				Cost:          cost,
+				Gain:          new(big.Int).Sub(proceeds, cost),
+				Rule:          MatchFIFOLot,
+				LotID:         &lotID,
+				AcquiredAt:    &acquiredAt,
+				Term:          term,
+			})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DisposedAt.Before(result[j].DisposedAt)
+	})
+	return result
+}
+
+// originalAcquisition follows internal-transfer links back to the lot that
+// first acquired the units
+func originalAcquisition(lot *ledger.TaxLot, lots map[uuid.UUID]*ledger.TaxLot) time.Time {
+	acquiredAt := lot.AcquiredAt
+	for i := 0; i < maxLinkDepth && lot.LinkedSourceLotID != nil; i++ {
+		source, ok := lots[*lot.LinkedSourceLotID]
+		if !ok {
+			break
+		}
+		lot = source
+		if lot.AcquiredAt.Before(acquiredAt) {
+			acquiredAt = lot.AcquiredAt
+		}
+	}
+	return acquiredAt
+}
+
+// isTaxable reports whether a disposal realizes a gain. Moves between the
+// user's own accounts do not; collateral seized in a liquidation is sold.
+func isTaxable(d *ledger.LotDisposal) bool {
+	switch d.DisposalType {
+	case ledger.DisposalTypeSale, ledger.DisposalTypeGasFee, ledger.DisposalTypeLiquidation:
+		return true
+	}
+	return false
+}
+
+func decimalScale(asset string) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(money.GetDecimals(asset))), nil)
+}
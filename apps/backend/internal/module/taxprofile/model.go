@@ -0,0 +1,153 @@
+package taxprofile
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+)
+
+// Jurisdiction identifies the tax rules a user reports under
+type Jurisdiction string
+
+const (
+	JurisdictionUS Jurisdiction = "US"
+	JurisdictionUK Jurisdiction = "UK"
+)
+
+// DefaultJurisdiction applies to users who have not chosen a profile
+const DefaultJurisdiction = JurisdictionUS
+
+// AllJurisdictions returns the supported jurisdictions
+func AllJurisdictions() []Jurisdiction {
+	return []Jurisdiction{JurisdictionUS, JurisdictionUK}
+}
+
+// IsValid returns true if the jurisdiction is supported
+func (j Jurisdiction) IsValid() bool {
+	switch j {
+	case JurisdictionUS, JurisdictionUK:
+		return true
+	}
+	return false
+}
+
+// CostBasisMethod selects how disposals are matched to acquisitions
+type CostBasisMethod string
+
+const (
+	// MethodFIFO uses the lots the ledger consumed, oldest first
+	MethodFIFO CostBasisMethod = "fifo"
+	// MethodSection104 applies the UK same-day and 30-day rules, then the
+	// per-asset average-cost pool, ignoring lot identity
+	MethodSection104 CostBasisMethod = "section_104"
+)
+
+// Rules are the jurisdiction-specific parameters of the capital gains report.
+// Both supported jurisdictions treat crypto-to-crypto swaps and fee payments
+// as disposals, so every sale and gas disposal is reported.
+type Rules struct {
+	Jurisdiction Jurisdiction
+	Method       CostBasisMethod
+	// LongTermAfterYears is the holding period in calendar years beyond which
+	// a gain is long-term; zero when the jurisdiction makes no such distinction
+	LongTermAfterYears int
+	// TaxYearStart is the month and day the tax year begins
+	TaxYearStartMonth time.Month
+	TaxYearStartDay   int
+}
+
+// RulesFor returns the rules of a jurisdiction
+func RulesFor(j Jurisdiction) Rules {
+	switch j {
+	case JurisdictionUK:
+		return Rules{Jurisdiction: j, Method: MethodSection104, TaxYearStartMonth: time.April, TaxYearStartDay: 6}
+	default:
+		return Rules{Jurisdiction: JurisdictionUS, Method: MethodFIFO, LongTermAfterYears: 1, TaxYearStartMonth: time.January, TaxYearStartDay: 1}
+	}
+}
+
+// IsLongTerm reports whether a disposal of an asset acquired at acquiredAt
+// gives a long-term gain
+func (r Rules) IsLongTerm(acquiredAt, disposedAt time.Time) bool {
+	return r.LongTermAfterYears > 0 && ledger.HeldOverYears(acquiredAt, disposedAt, r.LongTermAfterYears)
+}
+
+// TaxYear returns [start, next start) of the tax year beginning in the given
+// calendar year, in UTC. The UK year 2024 runs 6 April 2024 – 5 April 2025.
+func (r Rules) TaxYear(year int) (time.Time, time.Time) {
+	from := time.Date(year, r.TaxYearStartMonth, r.TaxYearStartDay, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(1, 0, 0)
+}
+
+// Profile is a user's chosen tax jurisdiction
+type Profile struct {
+	UserID       uuid.UUID
+	Jurisdiction Jurisdiction
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Rules returns the rules of the profile's jurisdiction
+func (p *Profile) Rules() Rules {
+	return RulesFor(p.Jurisdiction)
+}
+
+// MatchRule records how a disposal was matched to its cost
+type MatchRule string
+
+const (
+	MatchFIFOLot         MatchRule = "fifo_lot"
+	MatchSameDay         MatchRule = "same_day"
+	MatchBedAndBreakfast MatchRule = "bed_and_breakfast" // 30-day rule
+	MatchSection104      MatchRule = "section_104"
+)
+
+// Term classifies a gain by holding period
+type Term string
+
+const (
+	TermShort Term = "short"
+	TermLong  Term = "long"
+)
+
+// Disposal is one matched part of a taxable disposal. A sale drawing on
+// several lots or matching rules appears once per part. USD values are
+// scaled by 10^8.
+type Disposal struct {
+	TransactionID uuid.UUID
+	Asset         string
+	DisposedAt    time.Time
+	Quantity      *big.Int
+	Proceeds      *big.Int
+	Cost          *big.Int
+	Gain          *big.Int // Negative for a loss
+	Rule          MatchRule
+	LotID         *uuid.UUID // FIFO only
+	AcquiredAt    *time.Time // FIFO only; start of the holding period
+	Term          Term       // Empty when the jurisdiction has no terms
+}
+
+// Pool is the Section 104 holding of an asset at the end of the tax year
+type Pool struct {
+	Asset    string
+	Quantity *big.Int
+	Cost     *big.Int
+}
+
+// Report is the capital gains report of one tax year under a profile
+type Report struct {
+	Rules         Rules
+	TaxYear       int
+	From          time.Time
+	To            time.Time
+	Disposals     []*Disposal
+	TotalProceeds *big.Int
+	TotalCost     *big.Int
+	TotalGain     *big.Int
+	ShortTermGain *big.Int // FIFO jurisdictions only
+	LongTermGain  *big.Int // FIFO jurisdictions only
+	Pools         []*Pool  // Section 104 only
+}
@@ -0,0 +1,20 @@
+package taxprofile
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/taxlot"
+)
+
+// Repository persists tax profiles
+type Repository interface {
+	GetProfile(ctx context.Context, userID uuid.UUID) (*Profile, error)
+	UpsertProfile(ctx context.Context, p *Profile) error
+}
+
+// LotHistorySource supplies the user's lots and disposals across wallets
+type LotHistorySource interface {
+	GetLotHistory(ctx context.Context, userID uuid.UUID) ([]*taxlot.LotHistory, error)
+}
@@ -0,0 +1,267 @@
+package taxprofile
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/taxlot"
+)
+
+// bedAndBreakfastDays is the window after a disposal in which a repurchase
+// is matched to it before the pool
+const bedAndBreakfastDays = 30
+
+// ukAcquisition is all purchases of an asset on one day, which HMRC treats as
+// a single acquisition
+type ukAcquisition struct {
+	day       time.Time
+	quantity  *big.Int
+	cost      *big.Int
+	remaining *big.Int
+}
+
+// costOf returns the cost of part of the day's acquisition
+func (a *ukAcquisition) costOf(qty *big.Int) *big.Int {
+	cost := new(big.Int).Mul(a.cost, qty)
+	return cost.Div(cost, a.quantity)
+}
+
+// ukDisposal is one transaction's taxable disposals of an asset, which may
+// span several lots
+type ukDisposal struct {
+	transactionID uuid.UUID
+	asset         string
+	at            time.Time
+	day           time.Time
+	quantity      *big.Int
+	proceeds      *big.Int
+	remaining     *big.Int
+	parts         []*Disposal
+}
+
+// match records that qty of the disposal is matched at the given cost
+func (d *ukDisposal) match(rule MatchRule, qty, cost *big.Int) {
+	// The last part takes the rounding remainder of the proceeds
+	allocated := new(big.Int)
+	for _, p := range d.parts {
+		allocated.Add(allocated, p.Proceeds)
+	}
+	d.remaining.Sub(d.remaining, qty)
+	proceeds := new(big.Int).Sub(d.proceeds, allocated)
+	if d.remaining.Sign() > 0 {
+		proceeds.Mul(d.proceeds, qty)
+		proceeds.Div(proceeds, d.quantity)
+	}
+
+	d.parts = append(d.parts, &Disposal{
+		TransactionID: d.transactionID,
+		Asset:         d.asset,
+		DisposedAt:    d.at,
+		Quantity:      new(big.Int).Set(qty),
+		Proceeds:      proceeds,
+		Cost:          cost,
+		Gain:          new(big.Int).Sub(proceeds, cost),
+		Rule:          rule,
+	})
+}
+
+// section104Disposals reports the taxable disposals in [from, to) under the
+// UK share identification rules, applied per asset across all wallets:
+// acquisitions on the same day first, then acquisitions in the following 30
+// days, then the Section 104 pool at average cost. Lots only supply the
+// quantities, dates and prices; which lot the ledger consumed is irrelevant.
+//
+// It also returns each asset's pool at the end of the year. Units disposed of
+// beyond the pool's holding, e.g. from an incomplete history, get zero cost.
+func section104Disposals(histories []*taxlot.LotHistory, from, to time.Time) ([]*Disposal, []*Pool) {
+	acquisitions := make(map[string]map[time.Time]*ukAcquisition)
+	disposals := make(map[string]map[uuid.UUID]*ukDisposal)
+
+	for _, h := range histories {
+		lot := h.Lot
+		asset := strings.ToUpper(lot.Asset)
+		scale := decimalScale(lot.Asset)
+
+		if h.IsPurchase() {
+			if acquisitions[asset] == nil {
+				acquisitions[asset] = make(map[time.Time]*ukAcquisition)
+			}
+			day := truncateDay(lot.AcquiredAt)
+			a, ok := acquisitions[asset][day]
+			if !ok {
+				a = &ukAcquisition{day: day, quantity: new(big.Int), cost: new(big.Int), remaining: new(big.Int)}
+				acquisitions[asset][day] = a
+			}
+			cost := new(big.Int).Mul(lot.EffectiveCostBasisPerUnit(), lot.QuantityAcquired)
+			a.cost.Add(a.cost, cost.Div(cost, scale))
+			a.quantity.Add(a.quantity, lot.QuantityAcquired)
+			a.remaining.Add(a.remaining, lot.QuantityAcquired)
+		}
+
+		for _, ld := range h.Disposals {
+			if !isTaxable(ld) {
+				continue
+			}
+			if disposals[asset] == nil {
+				disposals[asset] = make(map[uuid.UUID]*ukDisposal)
+			}
+			d, ok := disposals[asset][ld.TransactionID]
+			if !ok {
+				d = &ukDisposal{
+					transactionID: ld.TransactionID,
+					asset:         lot.Asset,
+					at:            ld.DisposedAt,
+					day:           truncateDay(ld.DisposedAt),
+					quantity:      new(big.Int),
+					proceeds:      new(big.Int),
+					remaining:     new(big.Int),
+				}
+				disposals[asset][ld.TransactionID] = d
+			}
+			d.quantity.Add(d.quantity, ld.QuantityDisposed)
+			d.remaining.Add(d.remaining, ld.QuantityDisposed)
+			if ld.ProceedsPerUnit != nil {
+				proceeds := new(big.Int).Mul(ld.ProceedsPerUnit, ld.QuantityDisposed)
+				d.proceeds.Add(d.proceeds, proceeds.Div(proceeds, scale))
+			}
+		}
+	}
+
+	assets := make(map[string]bool)
+	for asset := range acquisitions {
+		assets[asset] = true
+	}
+	for asset := range disposals {
+		assets[asset] = true
+	}
+
+	var result []*Disposal
+	var pools []*Pool
+	for asset := range assets {
+		parts, pool := matchAsset(asset, acquisitions[asset], disposals[asset], from, to)
+		result = append(result, parts...)
+		if pool.Quantity.Sign() > 0 {
+			pools = append(pools, pool)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].DisposedAt.Equal(result[j].DisposedAt) {
+			return result[i].DisposedAt.Before(result[j].DisposedAt)
+		}
+		return result[i].Asset < result[j].Asset
+	})
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Asset < pools[j].Asset })
+	return result, pools
+}
+
+// matchAsset applies the three matching rules to one asset
+func matchAsset(asset string, byDay map[time.Time]*ukAcquisition, byTx map[uuid.UUID]*ukDisposal, from, to time.Time) ([]*Disposal, *Pool) {
+	days := make([]*ukAcquisition, 0, len(byDay))
+	for _, a := range byDay {
+		days = append(days, a)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].day.Before(days[j].day) })
+
+	sales := make([]*ukDisposal, 0, len(byTx))
+	for _, d := range byTx {
+		sales = append(sales, d)
+	}
+	sort.Slice(sales, func(i, j int) bool {
+		if !sales[i].at.Equal(sales[j].at) {
+			return sales[i].at.Before(sales[j].at)
+		}
+		return sales[i].transactionID.String() < sales[j].transactionID.String()
+	})
+
+	// Same-day rule
+	for _, d := range sales {
+		if a, ok := byDay[d.day]; ok {
+			matchAcquisition(d, a, MatchSameDay)
+		}
+	}
+
+	// Bed-and-breakfast rule, earliest repurchase first
+	for _, d := range sales {
+		limit := d.day.AddDate(0, 0, bedAndBreakfastDays)
+		for _, a := range days {
+			if d.remaining.Sign() == 0 || a.day.After(limit) {
+				break
+			}
+			if a.day.After(d.day) {
+				matchAcquisition(d, a, MatchBedAndBreakfast)
+			}
+		}
+	}
+
+	// Section 104 pool
+	pool := &Pool{Asset: asset, Quantity: new(big.Int), Cost: new(big.Int)}
+	next := 0
+	addUntil := func(day time.Time) {
+		for ; next < len(days) && days[next].day.Before(day); next++ {
+			a := days[next]
+			if a.remaining.Sign() == 0 {
+				continue
+			}
+			pool.Cost.Add(pool.Cost, a.costOf(a.remaining))
+			pool.Quantity.Add(pool.Quantity, a.remaining)
+		}
+	}
+
+	for _, d := range sales {
+		if !d.at.Before(to) {
+			break
+		}
+		addUntil(d.day)
+		if d.remaining.Sign() == 0 {
+			continue
+		}
+
+		qty := new(big.Int).Set(d.remaining)
+		cost := new(big.Int)
+		if pool.Quantity.Sign() > 0 {
+			fromPool := minInt(qty, pool.Quantity)
+			cost.Mul(pool.Cost, fromPool)
+			cost.Div(cost, pool.Quantity)
+			pool.Cost.Sub(pool.Cost, cost)
+			pool.Quantity.Sub(pool.Quantity, fromPool)
+		}
+		d.match(MatchSection104, qty, cost)
+	}
+	addUntil(to)
+
+	var result []*Disposal
+	for _, d := range sales {
+		if d.at.Before(from) || !d.at.Before(to) {
+			continue
+		}
+		result = append(result, d.parts...)
+	}
+	return result, pool
+}
+
+// matchAcquisition matches as much of the disposal as the acquisition has left
+func matchAcquisition(d *ukDisposal, a *ukAcquisition, rule MatchRule) {
+	qty := minInt(d.remaining, a.remaining)
+	if qty.Sign() == 0 {
+		return
+	}
+	a.remaining.Sub(a.remaining, qty)
+	d.match(rule, qty, a.costOf(qty))
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func minInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
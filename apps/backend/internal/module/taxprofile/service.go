@@ -0,0 +1,134 @@
+package taxprofile
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// Service manages users' tax profiles and produces capital gains reports
+// under the rules of the chosen jurisdiction
+type Service struct {
+	repo   Repository
+	lots   LotHistorySource
+	audit  audit.Recorder // optional
+	logger *logger.Logger
+}
+
+// NewService creates a new tax profile service
+func NewService(repo Repository, lots LotHistorySource, recorder audit.Recorder, log *logger.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		lots:   lots,
+		audit:  recorder,
+		logger: log.WithField("component", "taxprofile"),
+	}
+}
+
+// GetProfile returns the user's tax profile, or an unsaved profile for the
+// default jurisdiction if none was chosen
+func (s *Service) GetProfile(ctx context.Context, userID uuid.UUID) (*Profile, error) {
+	p, err := s.repo.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tax profile: %w", err)
+	}
+	if p == nil {
+		p = &Profile{UserID: userID, Jurisdiction: DefaultJurisdiction}
+	}
+	return p, nil
+}
+
+// SetProfile sets the user's jurisdiction
+func (s *Service) SetProfile(ctx context.Context, userID uuid.UUID, jurisdiction Jurisdiction) (*Profile, error) {
+	if !jurisdiction.IsValid() {
+		return nil, ErrInvalidJurisdiction
+	}
+
+	existing, err := s.repo.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tax profile: %w", err)
+	}
+
+	now := time.Now().UTC()
+	p := &Profile{UserID: userID, Jurisdiction: jurisdiction, CreatedAt: now, UpdatedAt: now}
+	if existing != nil {
+		p.CreatedAt = existing.CreatedAt
+	}
+	if err := s.repo.UpsertProfile(ctx, p); err != nil {
+		return nil, fmt.Errorf("failed to save tax profile: %w", err)
+	}
+
+	s.logger.Info("tax profile updated", "user_id", userID, "jurisdiction", jurisdiction)
+	if s.audit != nil {
+		// The jurisdiction decides how gains are matched and classified
+		event := audit.Event{
+			ActorID:    userID,
+			Action:     audit.ActionTaxProfileUpdate,
+			TargetType: audit.TargetTaxProfile,
+			TargetID:   userID.String(),
+			After:      map[string]any{"jurisdiction": jurisdiction},
+		}
+		if existing != nil {
+			event.Before = map[string]any{"jurisdiction": existing.Jurisdiction}
+		}
+		s.audit.Record(ctx, event)
+	}
+	return p, nil
+}
+
+// GetReport returns the capital gains of the tax year starting in the given
+// calendar year, matched by the method of the user's jurisdiction
+func (s *Service) GetReport(ctx context.Context, userID uuid.UUID, year int) (*Report, error) {
+	if year < 2009 || year > time.Now().UTC().Year() {
+		return nil, ErrInvalidYear
+	}
+
+	profile, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	rules := profile.Rules()
+
+	histories, err := s.lots.GetLotHistory(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lot history: %w", err)
+	}
+
+	from, to := rules.TaxYear(year)
+	report := &Report{
+		Rules:         rules,
+		TaxYear:       year,
+		From:          from,
+		To:            to,
+		TotalProceeds: new(big.Int),
+		TotalCost:     new(big.Int),
+		TotalGain:     new(big.Int),
+	}
+
+	switch rules.Method {
+	case MethodSection104:
+		report.Disposals, report.Pools = section104Disposals(histories, from, to)
+	default:
+		report.Disposals = fifoDisposals(histories, rules, from, to)
+		report.ShortTermGain, report.LongTermGain = new(big.Int), new(big.Int)
+	}
+
+	for _, d := range report.Disposals {
+		report.TotalProceeds.Add(report.TotalProceeds, d.Proceeds)
+		report.TotalCost.Add(report.TotalCost, d.Cost)
+		report.TotalGain.Add(report.TotalGain, d.Gain)
+		switch d.Term {
+		case TermShort:
+			report.ShortTermGain.Add(report.ShortTermGain, d.Gain)
+		case TermLong:
+			report.LongTermGain.Add(report.LongTermGain, d.Gain)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,210 @@
+package taxprofile
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/taxlot"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// mockRepo is an in-memory implementation of Repository for testing.
+type mockRepo map[uuid.UUID]*Profile
+
+func (m mockRepo) GetProfile(_ context.Context, userID uuid.UUID) (*Profile, error) {
+	return m[userID], nil
+}
+
+func (m mockRepo) UpsertProfile(_ context.Context, p *Profile) error {
+	m[p.UserID] = p
+	return nil
+}
+
+type mockLots []*taxlot.LotHistory
+
+func (m mockLots) GetLotHistory(_ context.Context, _ uuid.UUID) ([]*taxlot.LotHistory, error) {
+	return m, nil
+}
+
+// usd scales whole currency units to the 10^8 representation
+func usd(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), big.NewInt(1e8))
+}
+
+// btc scales whole coins to satoshis
+func btc(coins int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(coins), big.NewInt(1e8))
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 10, 0, 0, 0, time.UTC)
+}
+
+func buy(at time.Time, qty *big.Int, price int64) *taxlot.LotHistory {
+	return &taxlot.LotHistory{
+		WalletID: uuid.New(),
+		Lot: &ledger.TaxLot{
+			ID:                   uuid.New(),
+			TransactionID:        uuid.New(),
+			Asset:                "BTC",
+			QuantityAcquired:     qty,
+			QuantityRemaining:    new(big.Int).Set(qty),
+			AcquiredAt:           at,
+			AutoCostBasisPerUnit: usd(price),
+			AutoCostBasisSource:  ledger.CostBasisSwapPrice,
+		},
+	}
+}
+
+func dispose(h *taxlot.LotHistory, disposalType ledger.DisposalType, at time.Time, qty *big.Int, price int64) {
+	h.Disposals = append(h.Disposals, &ledger.LotDisposal{
+		ID:               uuid.New(),
+		TransactionID:    uuid.New(),
+		LotID:            h.Lot.ID,
+		QuantityDisposed: qty,
+		ProceedsPerUnit:  usd(price),
+		DisposalType:     disposalType,
+		DisposedAt:       at,
+	})
+	h.Lot.QuantityRemaining.Sub(h.Lot.QuantityRemaining, qty)
+}
+
+func newService(lots mockLots) (*Service, mockRepo) {
+	repo := mockRepo{}
+	return NewService(repo, lots, nil, logger.NewDefault("test")), repo
+}
+
+func TestSetProfile(t *testing.T) {
+	svc, _ := newService(nil)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	p, err := svc.GetProfile(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, JurisdictionUS, p.Jurisdiction)
+
+	_, err = svc.SetProfile(ctx, userID, "FR")
+	assert.ErrorIs(t, err, ErrInvalidJurisdiction)
+
+	_, err = svc.SetProfile(ctx, userID, JurisdictionUK)
+	require.NoError(t, err)
+	p, err = svc.GetProfile(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, MethodSection104, p.Rules().Method)
+}
+
+func TestGetReport_USFIFO(t *testing.T) {
+	// Bought in 2022 and moved to another wallet before the sale: the
+	// holding period runs from the original purchase
+	original := buy(date(2022, time.January, 10), btc(1), 100)
+	dispose(original, ledger.DisposalTypeInternalTransfer, date(2023, time.June, 1), btc(1), 120)
+	moved := buy(date(2023, time.June, 1), btc(1), 100)
+	moved.Lot.LinkedSourceLotID = &original.Lot.ID
+	moved.Lot.AutoCostBasisSource = ledger.CostBasisLinkedTransfer
+	dispose(moved, ledger.DisposalTypeSale, date(2023, time.June, 10), btc(1), 150)
+
+	recent := buy(date(2023, time.May, 1), btc(1), 200)
+	dispose(recent, ledger.DisposalTypeSale, date(2023, time.July, 1), btc(1), 150)
+
+	svc, _ := newService(mockLots{original, moved, recent})
+	report, err := svc.GetReport(context.Background(), uuid.New(), 2023)
+	require.NoError(t, err)
+
+	require.Len(t, report.Disposals, 2)
+	assert.Equal(t, TermLong, report.Disposals[0].Term)
+	assert.Equal(t, usd(50).String(), report.Disposals[0].Gain.String())
+	assert.Equal(t, TermShort, report.Disposals[1].Term)
+	assert.Equal(t, usd(-50).String(), report.Disposals[1].Gain.String())
+
+	assert.Equal(t, usd(50).String(), report.LongTermGain.String())
+	assert.Equal(t, usd(-50).String(), report.ShortTermGain.String())
+	assert.Equal(t, "0", report.TotalGain.String())
+	assert.Empty(t, report.Pools)
+}
+
+func TestGetReport_USLongTermNeedsMoreThanACalendarYear(t *testing.T) {
+	// 2024 is a leap year: the anniversary is 366 days after the purchase
+	// and still short-term; the day after is long-term
+	onAnniversary := buy(date(2023, time.March, 1), btc(1), 100)
+	dispose(onAnniversary, ledger.DisposalTypeSale, date(2024, time.March, 1), btc(1), 150)
+	dayAfter := buy(date(2023, time.March, 1), btc(1), 100)
+	dispose(dayAfter, ledger.DisposalTypeSale, date(2024, time.March, 2), btc(1), 150)
+
+	svc, _ := newService(mockLots{onAnniversary, dayAfter})
+	report, err := svc.GetReport(context.Background(), uuid.New(), 2024)
+	require.NoError(t, err)
+
+	require.Len(t, report.Disposals, 2)
+	assert.Equal(t, TermShort, report.Disposals[0].Term)
+	assert.Equal(t, TermLong, report.Disposals[1].Term)
+}
+
+func TestGetReport_LiquidationIsTaxable(t *testing.T) {
+	// Collateral seized by a liquidator is sold; moving it into the
+	// lending protocol was not
+	lot := buy(date(2023, time.January, 10), btc(2), 100)
+	dispose(lot, ledger.DisposalTypeLendingTransfer, date(2023, time.February, 1), btc(2), 110)
+	supplied := buy(date(2023, time.February, 1), btc(2), 100)
+	supplied.Lot.LinkedSourceLotID = &lot.Lot.ID
+	supplied.Lot.AutoCostBasisSource = ledger.CostBasisLendingCarryOver
+	dispose(supplied, ledger.DisposalTypeLiquidation, date(2023, time.March, 1), btc(1), 80)
+
+	svc, _ := newService(mockLots{lot, supplied})
+	report, err := svc.GetReport(context.Background(), uuid.New(), 2023)
+	require.NoError(t, err)
+
+	require.Len(t, report.Disposals, 1)
+	assert.Equal(t, usd(-20).String(), report.Disposals[0].Gain.String())
+}
+
+func TestGetReport_UKSection104(t *testing.T) {
+	first := buy(date(2023, time.May, 1), btc(10), 100)
+	second := buy(date(2023, time.June, 1), btc(10), 200)
+	// The ledger sold from the oldest lot, but UK rules ignore lot identity
+	dispose(first, ledger.DisposalTypeSale, date(2023, time.July, 1), btc(5), 300)
+	sameDay := buy(date(2023, time.July, 1).Add(4*time.Hour), btc(2), 250)
+	rebought := buy(date(2023, time.July, 15), btc(1), 280)
+
+	svc, repo := newService(mockLots{first, second, sameDay, rebought})
+	userID := uuid.New()
+	repo[userID] = &Profile{UserID: userID, Jurisdiction: JurisdictionUK}
+
+	report, err := svc.GetReport(context.Background(), userID, 2023)
+	require.NoError(t, err)
+	assert.Equal(t, date(2023, time.April, 6).Truncate(24*time.Hour), report.From)
+
+	require.Len(t, report.Disposals, 3)
+	expected := []struct {
+		rule MatchRule
+		qty  int64
+		cost int64
+		gain int64
+	}{
+		{MatchSameDay, 2, 500, 100},
+		{MatchBedAndBreakfast, 1, 280, 20},
+		// Pool holds 20 BTC at a cost of 3000
+		{MatchSection104, 2, 300, 300},
+	}
+	for i, e := range expected {
+		d := report.Disposals[i]
+		assert.Equal(t, e.rule, d.Rule)
+		assert.Equal(t, btc(e.qty).String(), d.Quantity.String())
+		assert.Equal(t, usd(e.cost).String(), d.Cost.String())
+		assert.Equal(t, usd(e.gain).String(), d.Gain.String())
+		assert.Empty(t, d.Term)
+	}
+	assert.Equal(t, usd(1500).String(), report.TotalProceeds.String())
+	assert.Equal(t, usd(420).String(), report.TotalGain.String())
+	assert.Nil(t, report.ShortTermGain)
+
+	require.Len(t, report.Pools, 1)
+	assert.Equal(t, btc(18).String(), report.Pools[0].Quantity.String())
+	assert.Equal(t, usd(2700).String(), report.Pools[0].Cost.String())
+}
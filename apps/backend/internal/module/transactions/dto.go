@@ -18,11 +18,19 @@ type TransactionListItem struct {
 	ChainID       string `json:"chain_id,omitempty"` // Zerion chain name, e.g. "ethereum", "base"
 }
 
+// TransactionList is one page of enriched transactions
+type TransactionList struct {
+	Items      []TransactionListItem
+	Total      int    // All transactions matching the filters, across pages
+	NextCursor string // Empty on the last page
+}
+
 // TransactionDetail represents a transaction in detail view
 type TransactionDetail struct {
 	TransactionListItem
 	Source     string                 `json:"source"`
 	ExternalID *string                `json:"external_id,omitempty"`
+	Version    int                    `json:"version"`
 	RecordedAt string                 `json:"recorded_at"`
 	Notes      string                 `json:"notes,omitempty"`
 	RawData    map[string]interface{} `json:"raw_data,omitempty"`
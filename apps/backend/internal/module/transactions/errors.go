@@ -0,0 +1,8 @@
+package transactions
+
+import "errors"
+
+var (
+	ErrWalletNotFound = errors.New("wallet not found")
+	ErrAccessDenied   = errors.New("insufficient permissions for this wallet")
+)
@@ -2,6 +2,7 @@ package transactions
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/kislikjeka/moontrack/internal/ledger"
 	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
 	"github.com/kislikjeka/moontrack/pkg/money"
 )
 
@@ -22,6 +24,7 @@ type WalletRepository interface {
 type TransactionService struct {
 	ledgerService  *ledger.Service
 	walletRepo     WalletRepository
+	access         wallet.AccessChecker
 	readerRegistry *ReaderRegistry
 	resolver       *money.DecimalResolver
 }
@@ -30,24 +33,43 @@ type TransactionService struct {
 func NewTransactionService(
 	ledgerService *ledger.Service,
 	walletRepo WalletRepository,
+	access wallet.AccessChecker,
 	resolver *money.DecimalResolver,
 ) *TransactionService {
 	return &TransactionService{
 		ledgerService:  ledgerService,
 		walletRepo:     walletRepo,
+		access:         access,
 		readerRegistry: NewReaderRegistry(),
 		resolver:       resolver,
 	}
 }
 
-// ListTransactions returns enriched transactions for the given filters
-func (s *TransactionService) ListTransactions(ctx context.Context, filters ledger.TransactionFilters) ([]TransactionListItem, error) {
+// ListTransactions returns a page of enriched transactions for the given filters
+func (s *TransactionService) ListTransactions(ctx context.Context, filters ledger.TransactionFilters) (*TransactionList, error) {
+	// Fetch one extra row to know whether another page follows
+	pageSize := filters.Limit
+	if pageSize > 0 {
+		filters.Limit = pageSize + 1
+	}
+
 	// Get raw transactions from ledger
 	transactions, err := s.ledgerService.ListTransactions(ctx, filters)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list transactions: %w", err)
 	}
 
+	total, err := s.ledgerService.CountTransactions(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count transactions: %w", err)
+	}
+
+	page := &TransactionList{Total: total}
+	if pageSize > 0 && len(transactions) > pageSize {
+		transactions = transactions[:pageSize]
+		page.NextCursor = ledger.CursorAfter(transactions[pageSize-1], filters.SortBy).Encode()
+	}
+
 	// Collect unique wallet IDs
 	walletIDs := make(map[uuid.UUID]bool)
 	for _, tx := range transactions {
@@ -80,8 +102,9 @@ func (s *TransactionService) ListTransactions(ctx context.Context, filters ledge
 		}
 		result = append(result, *item)
 	}
+	page.Items = result
 
-	return result, nil
+	return page, nil
 }
 
 // GetTransaction returns a single transaction with full details and entries
@@ -104,13 +127,10 @@ func (s *TransactionService) GetTransaction(ctx context.Context, id uuid.UUID, u
 		return nil, fmt.Errorf("failed to parse transaction: %w", err)
 	}
 
-	// Authorization check: verify user owns the wallet
-	w, err := s.walletRepo.GetByID(ctx, fields.WalletID)
-	if err != nil || w == nil {
-		return nil, fmt.Errorf("wallet not found")
-	}
-	if w.UserID != userID {
-		return nil, fmt.Errorf("transaction not found") // Return 404 to prevent ID enumeration
+	// Authorization check: verify user can view the wallet's workspace
+	w, err := s.VerifyWalletAccess(ctx, fields.WalletID, userID, workspace.RoleViewer)
+	if err != nil {
+		return nil, fmt.Errorf("transaction not found: %w", err) // Return 404 to prevent ID enumeration
 	}
 
 	// Build response
@@ -141,6 +161,7 @@ func (s *TransactionService) GetTransaction(ctx context.Context, id uuid.UUID, u
 		},
 		Source:     tx.Source,
 		ExternalID: tx.ExternalID,
+		Version:    tx.Version,
 		RecordedAt: tx.RecordedAt.Format(time.RFC3339),
 		Notes:      fields.Notes,
 		RawData:    tx.RawData,
@@ -150,6 +171,23 @@ func (s *TransactionService) GetTransaction(ctx context.Context, id uuid.UUID, u
 	return detail, nil
 }
 
+// VerifyWalletAccess checks that the user holds at least the required workspace role for the wallet
+func (s *TransactionService) VerifyWalletAccess(ctx context.Context, walletID, userID uuid.UUID, required workspace.Role) (*wallet.Wallet, error) {
+	w, err := s.walletRepo.GetByID(ctx, walletID)
+	if err != nil || w == nil {
+		return nil, ErrWalletNotFound
+	}
+
+	if err := s.access.Authorize(ctx, w.WorkspaceID, userID, required); err != nil {
+		if errors.Is(err, workspace.ErrNotMember) || errors.Is(err, workspace.ErrInsufficientRole) {
+			return nil, ErrAccessDenied
+		}
+		return nil, fmt.Errorf("failed to check workspace access: %w", err)
+	}
+
+	return w, nil
+}
+
 // toListItem converts a domain transaction to a list item DTO
 func (s *TransactionService) toListItem(ctx context.Context, tx *ledger.Transaction, wallets map[uuid.UUID]*wallet.Wallet) (*TransactionListItem, error) {
 	reader, ok := s.readerRegistry.GetReader(tx.Type)
@@ -18,7 +18,7 @@ var (
 
 	// Authorization errors
 	ErrWalletNotFound        = errors.New("wallet not found")
-	ErrUnauthorized          = errors.New("unauthorized: wallet does not belong to user")
+	ErrUnauthorized          = errors.New("unauthorized: user cannot record transactions on wallet")
 
 	// Duplicate detection
 	ErrDuplicateTransfer     = errors.New("transfer already recorded")
@@ -3,6 +3,7 @@ package transfer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
 	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
 	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
@@ -20,6 +22,7 @@ import (
 type TransferInHandler struct {
 	ledger.BaseHandler
 	walletRepo WalletRepository
+	access     wallet.AccessChecker
 	logger     *logger.Logger
 }
 
@@ -28,11 +31,29 @@ type WalletRepository interface {
 	GetByID(ctx context.Context, walletID uuid.UUID) (*wallet.Wallet, error)
 }
 
+// authorizeWallet checks that the authenticated user, if any, can record
+// transactions on the wallet: owners and editors of its workspace can.
+// Background jobs such as sync carry no user and are not checked.
+func authorizeWallet(ctx context.Context, access wallet.AccessChecker, w *wallet.Wallet) error {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok || userID == uuid.Nil {
+		return nil
+	}
+	if err := wallet.CheckAccess(ctx, access, w, userID, workspace.RoleEditor); err != nil {
+		if errors.Is(err, wallet.ErrUnauthorizedAccess) {
+			return ErrUnauthorized
+		}
+		return err
+	}
+	return nil
+}
+
 // NewTransferInHandler creates a new transfer in handler
-func NewTransferInHandler(walletRepo WalletRepository, log *logger.Logger) *TransferInHandler {
+func NewTransferInHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *TransferInHandler {
 	return &TransferInHandler{
 		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeTransferIn),
 		walletRepo:  walletRepo,
+		access:      access,
 		logger:      log.WithField("component", "transfer"),
 	}
 }
@@ -88,11 +109,9 @@ func (h *TransferInHandler) ValidateData(ctx context.Context, data map[string]in
 		return ErrWalletNotFound
 	}
 
-	// Verify wallet ownership - user can only record transactions on their own wallets
-	if userID, ok := middleware.GetUserIDFromContext(ctx); ok && userID != uuid.Nil {
-		if w.UserID != userID {
-			return ErrUnauthorized
-		}
+	// Verify wallet access - editors of the wallet's workspace can record transactions
+	if err := authorizeWallet(ctx, h.access, w); err != nil {
+		return err
 	}
 
 	return nil
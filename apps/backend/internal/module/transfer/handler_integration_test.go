@@ -52,9 +52,9 @@ func setupTransferTest(t *testing.T) (*ledger.Service, *postgres.LedgerRepositor
 
 	// Register transfer handlers
 	log := logger.New("test", io.Discard)
-	registry.Register(transfer.NewTransferInHandler(walletRepo, log))
-	registry.Register(transfer.NewTransferOutHandler(walletRepo, log))
-	registry.Register(transfer.NewInternalTransferHandler(walletRepo, log))
+	registry.Register(transfer.NewTransferInHandler(walletRepo, nil, log))
+	registry.Register(transfer.NewTransferOutHandler(walletRepo, nil, log))
+	registry.Register(transfer.NewInternalTransferHandler(walletRepo, nil, log))
 
 	svc := ledger.NewService(repo, registry, logger.New("test", io.Discard))
 	return svc, repo, ctx
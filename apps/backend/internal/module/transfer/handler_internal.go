@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
@@ -19,14 +20,16 @@ import (
 type InternalTransferHandler struct {
 	ledger.BaseHandler
 	walletRepo WalletRepository
+	access     wallet.AccessChecker
 	logger     *logger.Logger
 }
 
 // NewInternalTransferHandler creates a new internal transfer handler
-func NewInternalTransferHandler(walletRepo WalletRepository, log *logger.Logger) *InternalTransferHandler {
+func NewInternalTransferHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *InternalTransferHandler {
 	return &InternalTransferHandler{
 		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeInternalTransfer),
 		walletRepo:  walletRepo,
+		access:      access,
 		logger:      log.WithField("component", "transfer"),
 	}
 }
@@ -91,16 +94,18 @@ func (h *InternalTransferHandler) ValidateData(ctx context.Context, data map[str
 		return ErrWalletNotFound
 	}
 
-	// Verify both wallets belong to the same user
-	if srcWallet.UserID != dstWallet.UserID {
-		return ErrUnauthorized
-	}
-
-	// Verify wallet ownership - user can only record transactions on their own wallets
+	// Verify wallet access - the user must be able to record transactions on
+	// both wallets, which may sit in different workspaces
 	if userID, ok := middleware.GetUserIDFromContext(ctx); ok && userID != uuid.Nil {
-		if srcWallet.UserID != userID || dstWallet.UserID != userID {
-			return ErrUnauthorized
+		if err := authorizeWallet(ctx, h.access, srcWallet); err != nil {
+			return err
 		}
+		return authorizeWallet(ctx, h.access, dstWallet)
+	}
+
+	// Background sync carries no user and only pairs wallets of the same owner
+	if srcWallet.UserID != dstWallet.UserID {
+		return ErrUnauthorized
 	}
 
 	return nil
@@ -10,7 +10,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
-	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
 
@@ -19,14 +19,16 @@ import (
 type TransferOutHandler struct {
 	ledger.BaseHandler
 	walletRepo WalletRepository
+	access     wallet.AccessChecker
 	logger     *logger.Logger
 }
 
 // NewTransferOutHandler creates a new transfer out handler
-func NewTransferOutHandler(walletRepo WalletRepository, log *logger.Logger) *TransferOutHandler {
+func NewTransferOutHandler(walletRepo WalletRepository, access wallet.AccessChecker, log *logger.Logger) *TransferOutHandler {
 	return &TransferOutHandler{
 		BaseHandler: ledger.NewBaseHandler(ledger.TxTypeTransferOut),
 		walletRepo:  walletRepo,
+		access:      access,
 		logger:      log.WithField("component", "transfer"),
 	}
 }
@@ -82,11 +84,9 @@ func (h *TransferOutHandler) ValidateData(ctx context.Context, data map[string]i
 		return ErrWalletNotFound
 	}
 
-	// Verify wallet ownership - user can only record transactions on their own wallets
-	if userID, ok := middleware.GetUserIDFromContext(ctx); ok && userID != uuid.Nil {
-		if w.UserID != userID {
-			return ErrUnauthorized
-		}
+	// Verify wallet access - editors of the wallet's workspace can record transactions
+	if err := authorizeWallet(ctx, h.access, w); err != nil {
+		return err
 	}
 
 	// Note: For blockchain-synced transactions, we don't check balance here
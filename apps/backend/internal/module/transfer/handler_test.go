@@ -14,6 +14,7 @@ import (
 	"github.com/kislikjeka/moontrack/internal/ledger"
 	"github.com/kislikjeka/moontrack/internal/module/transfer"
 	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
 	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 	"github.com/kislikjeka/moontrack/pkg/money"
@@ -32,6 +33,15 @@ func (m *MockWalletRepository) GetByID(ctx context.Context, walletID uuid.UUID)
 	return args.Get(0).(*wallet.Wallet), args.Error(1)
 }
 
+// MockAccessChecker is a mock implementation of wallet.AccessChecker
+type MockAccessChecker struct {
+	mock.Mock
+}
+
+func (m *MockAccessChecker) Authorize(ctx context.Context, workspaceID, userID uuid.UUID, required workspace.Role) error {
+	return m.Called(ctx, workspaceID, userID, required).Error(0)
+}
+
 // =============================================================================
 // TransferInHandler Tests
 // =============================================================================
@@ -77,7 +87,7 @@ func TestTransferInHandler_GenerateEntries_Balance(t *testing.T) {
 				Address: "0x1234567890123456789012345678901234567890",
 			}, nil)
 
-			handler := transfer.NewTransferInHandler(walletRepo, logger.NewDefault("test"))
+			handler := transfer.NewTransferInHandler(walletRepo, nil, logger.NewDefault("test"))
 
 			data := map[string]interface{}{
 				"wallet_id":        walletID.String(),
@@ -208,7 +218,7 @@ func TestTransferInHandler_ValidateData(t *testing.T) {
 				Address: "0x1234567890123456789012345678901234567890",
 			}, nil)
 
-			handler := transfer.NewTransferInHandler(walletRepo, logger.NewDefault("test"))
+			handler := transfer.NewTransferInHandler(walletRepo, nil, logger.NewDefault("test"))
 
 			data := map[string]interface{}{
 				"wallet_id":        walletID.String(),
@@ -245,15 +255,105 @@ func TestTransferInHandler_CrossUserWallet_ReturnsUnauthorized(t *testing.T) {
 	// Create context with attacker's user ID
 	ctx := context.WithValue(context.Background(), middleware.UserIDKey, attacker)
 
+	workspaceID := uuid.New()
+
 	walletRepo := new(MockWalletRepository)
 	// Wallet belongs to walletOwner
 	walletRepo.On("GetByID", ctx, walletID).Return(&wallet.Wallet{
-		ID:      walletID,
-		UserID:  walletOwner,
-		Address: "0x1234567890123456789012345678901234567890",
+		ID:          walletID,
+		UserID:      walletOwner,
+		WorkspaceID: workspaceID,
+		Address:     "0x1234567890123456789012345678901234567890",
+	}, nil)
+
+	access := new(MockAccessChecker)
+	access.On("Authorize", ctx, workspaceID, attacker, workspace.RoleEditor).Return(workspace.ErrNotMember)
+
+	handler := transfer.NewTransferInHandler(walletRepo, access, logger.NewDefault("test"))
+
+	data := map[string]interface{}{
+		"wallet_id":        walletID.String(),
+		"asset_id":         "ETH",
+		"decimals":         18,
+		"amount":           money.NewBigIntFromInt64(1000000000000000000).String(),
+		"chain_id":         "ethereum",
+		"tx_hash":          "0xabc123",
+		"block_number":     int64(12345678),
+		"from_address":     "0xsender",
+		"contract_address": "",
+		"occurred_at":      time.Now().Add(-1 * time.Hour).Format(time.RFC3339),
+		"unique_id":        "unique123",
+	}
+
+	err := handler.ValidateData(ctx, data)
+	assert.ErrorIs(t, err, transfer.ErrUnauthorized)
+}
+
+// TestTransferInHandler_SharedWalletEditor_Allowed tests that an editor of a
+// shared workspace can record transactions on a wallet they don't own
+func TestTransferInHandler_SharedWalletEditor_Allowed(t *testing.T) {
+	walletOwner := uuid.New()
+	editor := uuid.New()
+	walletID := uuid.New()
+	workspaceID := uuid.New()
+
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, editor)
+
+	walletRepo := new(MockWalletRepository)
+	walletRepo.On("GetByID", ctx, walletID).Return(&wallet.Wallet{
+		ID:          walletID,
+		UserID:      walletOwner,
+		WorkspaceID: workspaceID,
+		Address:     "0x1234567890123456789012345678901234567890",
+	}, nil)
+
+	access := new(MockAccessChecker)
+	access.On("Authorize", ctx, workspaceID, editor, workspace.RoleEditor).Return(nil)
+
+	handler := transfer.NewTransferInHandler(walletRepo, access, logger.NewDefault("test"))
+
+	data := map[string]interface{}{
+		"wallet_id":        walletID.String(),
+		"asset_id":         "ETH",
+		"decimals":         18,
+		"amount":           money.NewBigIntFromInt64(1000000000000000000).String(),
+		"usd_rate":         money.NewBigIntFromInt64(200000000000).String(),
+		"chain_id":         "ethereum",
+		"tx_hash":          "0xabc123",
+		"block_number":     int64(12345678),
+		"from_address":     "0xsender",
+		"contract_address": "",
+		"occurred_at":      time.Now().Add(-1 * time.Hour).Format(time.RFC3339),
+		"unique_id":        "unique123",
+	}
+
+	entries, err := handler.Handle(ctx, data)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+	access.AssertExpectations(t)
+}
+
+// TestTransferInHandler_SharedWalletViewer_ReturnsUnauthorized tests that a
+// viewer of a shared workspace cannot record transactions
+func TestTransferInHandler_SharedWalletViewer_ReturnsUnauthorized(t *testing.T) {
+	viewer := uuid.New()
+	walletID := uuid.New()
+	workspaceID := uuid.New()
+
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, viewer)
+
+	walletRepo := new(MockWalletRepository)
+	walletRepo.On("GetByID", ctx, walletID).Return(&wallet.Wallet{
+		ID:          walletID,
+		UserID:      uuid.New(),
+		WorkspaceID: workspaceID,
+		Address:     "0x1234567890123456789012345678901234567890",
 	}, nil)
 
-	handler := transfer.NewTransferInHandler(walletRepo, logger.NewDefault("test"))
+	access := new(MockAccessChecker)
+	access.On("Authorize", ctx, workspaceID, viewer, workspace.RoleEditor).Return(workspace.ErrInsufficientRole)
+
+	handler := transfer.NewTransferInHandler(walletRepo, access, logger.NewDefault("test"))
 
 	data := map[string]interface{}{
 		"wallet_id":        walletID.String(),
@@ -281,7 +381,7 @@ func TestTransferInHandler_WalletNotFound(t *testing.T) {
 	walletRepo := new(MockWalletRepository)
 	walletRepo.On("GetByID", ctx, walletID).Return(nil, nil)
 
-	handler := transfer.NewTransferInHandler(walletRepo, logger.NewDefault("test"))
+	handler := transfer.NewTransferInHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	data := map[string]interface{}{
 		"wallet_id":        walletID.String(),
@@ -340,7 +440,7 @@ func TestTransferOutHandler_GenerateEntries_Balance(t *testing.T) {
 				Address: "0x1234567890123456789012345678901234567890",
 			}, nil)
 
-			handler := transfer.NewTransferOutHandler(walletRepo, logger.NewDefault("test"))
+			handler := transfer.NewTransferOutHandler(walletRepo, nil, logger.NewDefault("test"))
 
 			data := map[string]interface{}{
 				"wallet_id":        walletID.String(),
@@ -399,7 +499,7 @@ func TestTransferOutHandler_WithGas_GenerateEntries_Balance(t *testing.T) {
 		Address: "0x1234567890123456789012345678901234567890",
 	}, nil)
 
-	handler := transfer.NewTransferOutHandler(walletRepo, logger.NewDefault("test"))
+	handler := transfer.NewTransferOutHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	data := map[string]interface{}{
 		"wallet_id":        walletID.String(),
@@ -490,7 +590,7 @@ func TestTransferOutHandler_ValidateData(t *testing.T) {
 				Address: "0x1234567890123456789012345678901234567890",
 			}, nil)
 
-			handler := transfer.NewTransferOutHandler(walletRepo, logger.NewDefault("test"))
+			handler := transfer.NewTransferOutHandler(walletRepo, nil, logger.NewDefault("test"))
 
 			data := map[string]interface{}{
 				"wallet_id":        walletID.String(),
@@ -540,7 +640,7 @@ func TestInternalTransferHandler_GenerateEntries_Balance(t *testing.T) {
 		Address: "0x2222222222222222222222222222222222222222",
 	}, nil)
 
-	handler := transfer.NewInternalTransferHandler(walletRepo, logger.NewDefault("test"))
+	handler := transfer.NewInternalTransferHandler(walletRepo, nil, logger.NewDefault("test"))
 
 	data := map[string]interface{}{
 		"source_wallet_id": sourceWalletID.String(),
@@ -643,7 +743,7 @@ func TestInternalTransferHandler_ValidateData(t *testing.T) {
 				Address: "0x1111111111111111111111111111111111111111",
 			}, nil)
 
-			handler := transfer.NewInternalTransferHandler(walletRepo, logger.NewDefault("test"))
+			handler := transfer.NewInternalTransferHandler(walletRepo, nil, logger.NewDefault("test"))
 
 			data := map[string]interface{}{
 				"source_wallet_id": sourceWalletID.String(),
@@ -680,12 +780,15 @@ func TestInternalTransferHandler_CrossUserWallet_ReturnsUnauthorized(t *testing.
 	// Create context with attacker's user ID
 	ctx := context.WithValue(context.Background(), middleware.UserIDKey, attacker)
 
+	sourceWorkspaceID := uuid.New()
+
 	walletRepo := new(MockWalletRepository)
 	// Source wallet belongs to sourceOwner
 	walletRepo.On("GetByID", ctx, sourceWalletID).Return(&wallet.Wallet{
-		ID:      sourceWalletID,
-		UserID:  sourceOwner,
-		Address: "0x1111111111111111111111111111111111111111",
+		ID:          sourceWalletID,
+		UserID:      sourceOwner,
+		WorkspaceID: sourceWorkspaceID,
+		Address:     "0x1111111111111111111111111111111111111111",
 	}, nil)
 	walletRepo.On("GetByID", ctx, destWalletID).Return(&wallet.Wallet{
 		ID:      destWalletID,
@@ -693,7 +796,10 @@ func TestInternalTransferHandler_CrossUserWallet_ReturnsUnauthorized(t *testing.
 		Address: "0x2222222222222222222222222222222222222222",
 	}, nil)
 
-	handler := transfer.NewInternalTransferHandler(walletRepo, logger.NewDefault("test"))
+	access := new(MockAccessChecker)
+	access.On("Authorize", ctx, sourceWorkspaceID, attacker, workspace.RoleEditor).Return(workspace.ErrNotMember)
+
+	handler := transfer.NewInternalTransferHandler(walletRepo, access, logger.NewDefault("test"))
 
 	data := map[string]interface{}{
 		"source_wallet_id": sourceWalletID.String(),
@@ -718,16 +824,16 @@ func TestInternalTransferHandler_CrossUserWallet_ReturnsUnauthorized(t *testing.
 // =============================================================================
 
 func TestTransferInHandler_Type(t *testing.T) {
-	handler := transfer.NewTransferInHandler(nil, logger.NewDefault("test"))
+	handler := transfer.NewTransferInHandler(nil, nil, logger.NewDefault("test"))
 	assert.Equal(t, ledger.TxTypeTransferIn, handler.Type())
 }
 
 func TestTransferOutHandler_Type(t *testing.T) {
-	handler := transfer.NewTransferOutHandler(nil, logger.NewDefault("test"))
+	handler := transfer.NewTransferOutHandler(nil, nil, logger.NewDefault("test"))
 	assert.Equal(t, ledger.TxTypeTransferOut, handler.Type())
 }
 
 func TestInternalTransferHandler_Type(t *testing.T) {
-	handler := transfer.NewInternalTransferHandler(nil, logger.NewDefault("test"))
+	handler := transfer.NewInternalTransferHandler(nil, nil, logger.NewDefault("test"))
 	assert.Equal(t, ledger.TxTypeInternalTransfer, handler.Type())
 }
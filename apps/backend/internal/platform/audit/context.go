@@ -0,0 +1,22 @@
+package audit
+
+import "context"
+
+type contextKey struct{}
+
+// RequestMeta describes the HTTP request that triggered an audited action
+type RequestMeta struct {
+	IP        string
+	UserAgent string
+}
+
+// WithRequestMeta attaches request metadata to the context
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, contextKey{}, meta)
+}
+
+// RequestMetaFromContext returns the request metadata, if any
+func RequestMetaFromContext(ctx context.Context) (RequestMeta, bool) {
+	meta, ok := ctx.Value(contextKey{}).(RequestMeta)
+	return meta, ok
+}
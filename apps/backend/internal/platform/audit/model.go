@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Action identifies what a user did, namespaced by target type
+type Action string
+
+const (
+	ActionLogin              Action = "auth.login"
+	ActionLoginFailed        Action = "auth.login_failed"
+	ActionUserExport         Action = "user.export_data"
+	ActionUserDelete         Action = "user.delete"
+	ActionWalletCreate       Action = "wallet.create"
+	ActionWalletUpdate       Action = "wallet.update"
+	ActionWalletDelete       Action = "wallet.delete"
+	ActionWalletSync         Action = "wallet.sync"
+	ActionTransactionCreate  Action = "transaction.create"
+	ActionTransactionUpdate  Action = "transaction.update"
+	ActionTransactionReverse Action = "transaction.reverse"
+	ActionTransactionTags    Action = "transaction.set_tags"
+	ActionTransactionNote    Action = "transaction.set_note"
+	ActionLotOverride        Action = "lot.override_cost_basis"
+	ActionLotRebuild         Action = "lot.rebuild"
+	ActionIncomeClassify     Action = "transaction.classify_income"
+	ActionTagCreate          Action = "tag.create"
+	ActionTagUpdate          Action = "tag.update"
+	ActionTagDelete          Action = "tag.delete"
+	ActionShareLinkCreate    Action = "share_link.create"
+	ActionShareLinkRevoke    Action = "share_link.revoke"
+	ActionWorkspaceInvite    Action = "workspace.invite"
+	ActionWorkspaceJoin      Action = "workspace.accept_invitation"
+	ActionMemberRoleUpdate   Action = "workspace.update_member_role"
+	ActionMemberRemove       Action = "workspace.remove_member"
+	ActionTaxProfileUpdate   Action = "tax_profile.update"
+)
+
+// Target types
+const (
+	TargetUser        = "user"
+	TargetWallet      = "wallet"
+	TargetTransaction = "transaction"
+	TargetTaxLot      = "tax_lot"
+	TargetTag         = "tag"
+	TargetShareLink   = "share_link"
+	TargetWorkspace   = "workspace"
+	TargetTaxProfile  = "tax_profile"
+)
+
+// Event is what a service reports when a user mutates state.
+// Before and After are marshalled to JSON; either may be nil.
+type Event struct {
+	ActorID    uuid.UUID
+	Action     Action
+	TargetType string
+	TargetID   string
+	Before     any
+	After      any
+}
+
+// Entry is a persisted, immutable audit record
+type Entry struct {
+	ID         uuid.UUID
+	ActorID    uuid.UUID
+	Action     Action
+	TargetType string
+	TargetID   string
+	Before     json.RawMessage
+	After      json.RawMessage
+	IP         string
+	UserAgent  string
+	RequestID  string
+	CreatedAt  time.Time
+}
+
+// Filter narrows an actor's audit trail. Zero values are ignored.
+type Filter struct {
+	Action     Action
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
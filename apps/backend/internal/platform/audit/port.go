@@ -0,0 +1,20 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Repository persists audit entries. It exposes no update or delete on purpose.
+type Repository interface {
+	Append(ctx context.Context, entry *Entry) error
+	List(ctx context.Context, actorID uuid.UUID, filter Filter) ([]*Entry, error)
+	Count(ctx context.Context, actorID uuid.UUID, filter Filter) (int, error)
+}
+
+// Recorder is implemented by the audit service and consumed by services
+// that mutate user-owned state
+type Recorder interface {
+	Record(ctx context.Context, event Event)
+}
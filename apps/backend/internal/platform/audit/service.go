@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// Service records and browses the audit trail
+type Service struct {
+	repo   Repository
+	logger *logger.Logger
+}
+
+// NewService creates a new audit service
+func NewService(repo Repository, log *logger.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: log.WithField("component", "audit"),
+	}
+}
+
+// Record appends an entry for the event, enriched with the request's IP,
+// user agent and request ID when present in the context.
+// Failures are logged rather than returned: the mutation being audited has
+// already happened and must not be reported as failed.
+func (s *Service) Record(ctx context.Context, event Event) {
+	entry, err := newEntry(ctx, event)
+	if err != nil {
+		s.logger.Error("failed to build audit entry", "action", event.Action, "error", err)
+		return
+	}
+
+	if err := s.repo.Append(ctx, entry); err != nil {
+		s.logger.Error("failed to append audit entry",
+			"action", event.Action,
+			"actor_id", event.ActorID,
+			"target_id", event.TargetID,
+			"error", err)
+	}
+}
+
+// List returns a page of the actor's audit trail, newest first, and the total count
+func (s *Service) List(ctx context.Context, actorID uuid.UUID, filter Filter) ([]*Entry, int, error) {
+	entries, err := s.repo.List(ctx, actorID, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	total, err := s.repo.Count(ctx, actorID, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+	return entries, total, nil
+}
+
+func newEntry(ctx context.Context, event Event) (*Entry, error) {
+	before, err := marshalState(event.Before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal before state: %w", err)
+	}
+	after, err := marshalState(event.After)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal after state: %w", err)
+	}
+
+	entry := &Entry{
+		ID:         uuid.New(),
+		ActorID:    event.ActorID,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		Before:     before,
+		After:      after,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if meta, ok := RequestMetaFromContext(ctx); ok {
+		entry.IP = meta.IP
+		entry.UserAgent = meta.UserAgent
+	}
+	if reqID, ok := ctx.Value(logger.RequestIDKey).(string); ok {
+		entry.RequestID = reqID
+	}
+	return entry, nil
+}
+
+func marshalState(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if raw, ok := v.(json.RawMessage); ok {
+		return raw, nil
+	}
+	return json.Marshal(v)
+}
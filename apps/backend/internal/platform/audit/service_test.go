@@ -0,0 +1,114 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+type mockRepo struct {
+	entries   []*Entry
+	appendErr error
+}
+
+func (r *mockRepo) Append(_ context.Context, entry *Entry) error {
+	if r.appendErr != nil {
+		return r.appendErr
+	}
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *mockRepo) List(_ context.Context, actorID uuid.UUID, filter Filter) ([]*Entry, error) {
+	var result []*Entry
+	for _, e := range r.entries {
+		if e.ActorID == actorID && (filter.Action == "" || e.Action == filter.Action) {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (r *mockRepo) Count(ctx context.Context, actorID uuid.UUID, filter Filter) (int, error) {
+	entries, _ := r.List(ctx, actorID, filter)
+	return len(entries), nil
+}
+
+func TestRecord_EnrichesFromContext(t *testing.T) {
+	repo := &mockRepo{}
+	svc := NewService(repo, logger.New("test", io.Discard))
+
+	ctx := WithRequestMeta(context.Background(), RequestMeta{IP: "203.0.113.7", UserAgent: "curl/8"})
+	ctx = context.WithValue(ctx, logger.RequestIDKey, "req-42")
+
+	actor := uuid.New()
+	svc.Record(ctx, Event{
+		ActorID:    actor,
+		Action:     ActionWalletUpdate,
+		TargetType: TargetWallet,
+		TargetID:   "w1",
+		Before:     map[string]string{"name": "old"},
+		After:      map[string]string{"name": "new"},
+	})
+
+	require.Len(t, repo.entries, 1)
+	e := repo.entries[0]
+	assert.Equal(t, actor, e.ActorID)
+	assert.Equal(t, "203.0.113.7", e.IP)
+	assert.Equal(t, "curl/8", e.UserAgent)
+	assert.Equal(t, "req-42", e.RequestID)
+	assert.JSONEq(t, `{"name":"old"}`, string(e.Before))
+	assert.JSONEq(t, `{"name":"new"}`, string(e.After))
+	assert.NotEqual(t, uuid.Nil, e.ID)
+}
+
+func TestRecord_NilStatesStayEmpty(t *testing.T) {
+	repo := &mockRepo{}
+	svc := NewService(repo, logger.New("test", io.Discard))
+
+	svc.Record(context.Background(), Event{ActorID: uuid.New(), Action: ActionWalletSync, TargetType: TargetWallet})
+
+	require.Len(t, repo.entries, 1)
+	assert.Nil(t, repo.entries[0].Before)
+	assert.Nil(t, repo.entries[0].After)
+	assert.Empty(t, repo.entries[0].IP)
+}
+
+func TestRecord_SwallowsRepositoryErrors(t *testing.T) {
+	repo := &mockRepo{appendErr: errors.New("db down")}
+	svc := NewService(repo, logger.New("test", io.Discard))
+
+	assert.NotPanics(t, func() {
+		svc.Record(context.Background(), Event{ActorID: uuid.New(), Action: ActionLogin})
+	})
+}
+
+func TestRecord_UnmarshalableStateIsDropped(t *testing.T) {
+	repo := &mockRepo{}
+	svc := NewService(repo, logger.New("test", io.Discard))
+
+	svc.Record(context.Background(), Event{ActorID: uuid.New(), Action: ActionLogin, After: make(chan int)})
+	assert.Empty(t, repo.entries)
+}
+
+func TestList_ReturnsTotal(t *testing.T) {
+	repo := &mockRepo{}
+	svc := NewService(repo, logger.New("test", io.Discard))
+	actor := uuid.New()
+
+	svc.Record(context.Background(), Event{ActorID: actor, Action: ActionLogin})
+	svc.Record(context.Background(), Event{ActorID: actor, Action: ActionWalletCreate})
+	svc.Record(context.Background(), Event{ActorID: uuid.New(), Action: ActionLogin})
+
+	entries, total, err := svc.List(context.Background(), actor, Filter{Action: ActionLogin})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, 1, total)
+}
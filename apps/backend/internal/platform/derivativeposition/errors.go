@@ -0,0 +1,5 @@
+package derivativeposition
+
+import "errors"
+
+var ErrPositionNotFound = errors.New("derivative position not found")
@@ -0,0 +1,63 @@
+package derivativeposition
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusActive Status = "active"
+	StatusClosed Status = "closed"
+)
+
+// DerivativePosition tracks the margin a wallet keeps on one perpetuals
+// protocol (GMX, Hyperliquid) in one margin asset, with the PnL realized
+// and the funding settled against it.
+type DerivativePosition struct {
+	ID       uuid.UUID
+	UserID   uuid.UUID
+	WalletID uuid.UUID
+	ChainID  string
+	Protocol string
+	Market   string // last market traded, empty if unknown
+
+	Asset         string // margin asset
+	AssetDecimals int
+	AssetContract string
+	Margin        *big.Int // margin still posted, in asset units
+
+	TotalDeposited    *big.Int
+	TotalWithdrawn    *big.Int
+	TotalDepositedUSD *big.Int
+	TotalWithdrawnUSD *big.Int
+
+	RealizedPnL    *big.Int // signed, in asset units
+	RealizedPnLUSD *big.Int // signed
+
+	FundingPaid        *big.Int
+	FundingReceived    *big.Int
+	FundingPaidUSD     *big.Int
+	FundingReceivedUSD *big.Int
+
+	Status   Status
+	OpenedAt time.Time
+	ClosedAt *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NetPnLUSD returns the realized PnL net of funding.
+func (p *DerivativePosition) NetPnLUSD() *big.Int {
+	net := new(big.Int).Add(p.RealizedPnLUSD, p.FundingReceivedUSD)
+	return net.Sub(net, p.FundingPaidUSD)
+}
+
+// ShouldClose returns true once no margin remains posted.
+func (p *DerivativePosition) ShouldClose() bool {
+	return p.Margin.Sign() <= 0
+}
@@ -0,0 +1,16 @@
+package derivativeposition
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	Create(ctx context.Context, pos *DerivativePosition) error
+	Update(ctx context.Context, pos *DerivativePosition) error
+	GetByID(ctx context.Context, id uuid.UUID) (*DerivativePosition, error)
+	FindActiveByWalletAndAsset(ctx context.Context, walletID uuid.UUID, protocol, chainID, asset string) (*DerivativePosition, error)
+	ListByUser(ctx context.Context, userID uuid.UUID, status *Status, walletID *uuid.UUID, chainID *string) ([]*DerivativePosition, error)
+	ListByWallets(ctx context.Context, walletIDs []uuid.UUID, status *Status, chainID *string) ([]*DerivativePosition, error)
+}
@@ -0,0 +1,245 @@
+package derivativeposition
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+type Service struct {
+	repo    Repository
+	wallets wallet.Reader
+	logger  *logger.Logger
+}
+
+func NewService(repo Repository, wallets wallet.Reader, log *logger.Logger) *Service {
+	return &Service{
+		repo:    repo,
+		wallets: wallets,
+		logger:  log.WithField("component", "derivativeposition"),
+	}
+}
+
+// FindOrCreate looks up an active position by wallet+protocol+chain+asset, or
+// creates a new one. A known market replaces the one on record.
+func (s *Service) FindOrCreate(
+	ctx context.Context,
+	userID, walletID uuid.UUID,
+	protocol, chainID, market, asset string, assetDecimals int, assetContract string,
+	openedAt time.Time,
+) (*DerivativePosition, error) {
+	existing, err := s.repo.FindActiveByWalletAndAsset(ctx, walletID, protocol, chainID, asset)
+	if err != nil {
+		return nil, fmt.Errorf("find active position: %w", err)
+	}
+	if existing != nil {
+		if market != "" && market != existing.Market {
+			existing.Market = market
+			existing.UpdatedAt = time.Now().UTC()
+			if err := s.repo.Update(ctx, existing); err != nil {
+				return nil, fmt.Errorf("update position market: %w", err)
+			}
+		}
+		return existing, nil
+	}
+
+	pos := &DerivativePosition{
+		ID:       uuid.New(),
+		UserID:   userID,
+		WalletID: walletID,
+		ChainID:  chainID,
+		Protocol: protocol,
+		Market:   market,
+
+		Asset:         asset,
+		AssetDecimals: assetDecimals,
+		AssetContract: assetContract,
+		Margin:        big.NewInt(0),
+
+		TotalDeposited:    big.NewInt(0),
+		TotalWithdrawn:    big.NewInt(0),
+		TotalDepositedUSD: big.NewInt(0),
+		TotalWithdrawnUSD: big.NewInt(0),
+
+		RealizedPnL:    big.NewInt(0),
+		RealizedPnLUSD: big.NewInt(0),
+
+		FundingPaid:        big.NewInt(0),
+		FundingReceived:    big.NewInt(0),
+		FundingPaidUSD:     big.NewInt(0),
+		FundingReceivedUSD: big.NewInt(0),
+
+		Status:   StatusActive,
+		OpenedAt: openedAt,
+
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	if err := s.repo.Create(ctx, pos); err != nil {
+		return nil, fmt.Errorf("create position: %w", err)
+	}
+
+	s.logger.Info("derivative position created",
+		"position_id", pos.ID,
+		"protocol", protocol,
+		"asset", asset,
+	)
+
+	return pos, nil
+}
+
+// FindActive returns the active position for wallet+protocol+chain+asset, or
+// nil if there is none.
+func (s *Service) FindActive(ctx context.Context, walletID uuid.UUID, protocol, chainID, asset string) (*DerivativePosition, error) {
+	return s.repo.FindActiveByWalletAndAsset(ctx, walletID, protocol, chainID, asset)
+}
+
+// RecordOpen adds margin posted to the position.
+func (s *Service) RecordOpen(ctx context.Context, positionID uuid.UUID, amount, usdValue *big.Int) error {
+	pos, err := s.getPosition(ctx, positionID)
+	if err != nil {
+		return err
+	}
+
+	pos.Margin.Add(pos.Margin, amount)
+	pos.TotalDeposited.Add(pos.TotalDeposited, amount)
+	pos.TotalDepositedUSD.Add(pos.TotalDepositedUSD, usdValue)
+	pos.UpdatedAt = time.Now().UTC()
+
+	return s.repo.Update(ctx, pos)
+}
+
+// RecordClose releases margin from the position and books the difference to
+// what the wallet received as realized PnL. May close position as of the
+// close transaction's time at.
+func (s *Service) RecordClose(ctx context.Context, positionID uuid.UUID, received, receivedUSD, released, releasedUSD *big.Int, at time.Time) error {
+	pos, err := s.getPosition(ctx, positionID)
+	if err != nil {
+		return err
+	}
+
+	pos.Margin.Sub(pos.Margin, released)
+	if pos.Margin.Sign() < 0 {
+		pos.Margin.SetInt64(0)
+	}
+	pos.TotalWithdrawn.Add(pos.TotalWithdrawn, received)
+	pos.TotalWithdrawnUSD.Add(pos.TotalWithdrawnUSD, receivedUSD)
+	pos.RealizedPnL.Add(pos.RealizedPnL, new(big.Int).Sub(received, released))
+	pos.RealizedPnLUSD.Add(pos.RealizedPnLUSD, new(big.Int).Sub(receivedUSD, releasedUSD))
+	pos.UpdatedAt = time.Now().UTC()
+
+	if pos.ShouldClose() {
+		s.closePosition(pos, at)
+	}
+
+	return s.repo.Update(ctx, pos)
+}
+
+// RecordFunding settles a funding payment against the margin.
+func (s *Service) RecordFunding(ctx context.Context, positionID uuid.UUID, amount, usdValue *big.Int, paid bool) error {
+	pos, err := s.getPosition(ctx, positionID)
+	if err != nil {
+		return err
+	}
+
+	if paid {
+		pos.Margin.Sub(pos.Margin, amount)
+		pos.FundingPaid.Add(pos.FundingPaid, amount)
+		pos.FundingPaidUSD.Add(pos.FundingPaidUSD, usdValue)
+	} else {
+		pos.Margin.Add(pos.Margin, amount)
+		pos.FundingReceived.Add(pos.FundingReceived, amount)
+		pos.FundingReceivedUSD.Add(pos.FundingReceivedUSD, usdValue)
+	}
+	pos.UpdatedAt = time.Now().UTC()
+
+	return s.repo.Update(ctx, pos)
+}
+
+// RecordFundingClaim adds funding received that was claimed into the wallet
+// and so never settled against the margin.
+func (s *Service) RecordFundingClaim(ctx context.Context, positionID uuid.UUID, amount, usdValue *big.Int) error {
+	pos, err := s.getPosition(ctx, positionID)
+	if err != nil {
+		return err
+	}
+
+	pos.FundingReceived.Add(pos.FundingReceived, amount)
+	pos.FundingReceivedUSD.Add(pos.FundingReceivedUSD, usdValue)
+	pos.UpdatedAt = time.Now().UTC()
+
+	return s.repo.Update(ctx, pos)
+}
+
+// GetByID returns a position by ID.
+func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*DerivativePosition, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListByUser returns positions for a user, with optional filters.
+func (s *Service) ListByUser(ctx context.Context, userID uuid.UUID, status *Status, walletID *uuid.UUID, chainID *string) ([]*DerivativePosition, error) {
+	return s.repo.ListByUser(ctx, userID, status, walletID, chainID)
+}
+
+// GetAccessible returns a position on a wallet the user can view. Positions
+// the user cannot view read as ErrPositionNotFound.
+func (s *Service) GetAccessible(ctx context.Context, userID, id uuid.UUID) (*DerivativePosition, error) {
+	pos, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get position: %w", err)
+	}
+	if pos == nil {
+		return nil, ErrPositionNotFound
+	}
+
+	ok, err := wallet.CanView(ctx, s.wallets, pos.WalletID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("check wallet access: %w", err)
+	}
+	if !ok {
+		return nil, ErrPositionNotFound
+	}
+	return pos, nil
+}
+
+// ListAccessible returns positions on the wallets the user can view through
+// their workspaces, with optional filters.
+func (s *Service) ListAccessible(ctx context.Context, userID uuid.UUID, status *Status, walletID *uuid.UUID, chainID *string) ([]*DerivativePosition, error) {
+	walletIDs, err := wallet.AccessibleIDs(ctx, s.wallets, userID, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve accessible wallets: %w", err)
+	}
+	if len(walletIDs) == 0 {
+		return nil, nil
+	}
+	return s.repo.ListByWallets(ctx, walletIDs, status, chainID)
+}
+
+func (s *Service) getPosition(ctx context.Context, id uuid.UUID) (*DerivativePosition, error) {
+	pos, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get position: %w", err)
+	}
+	if pos == nil {
+		return nil, fmt.Errorf("position not found: %s", id)
+	}
+	return pos, nil
+}
+
+func (s *Service) closePosition(pos *DerivativePosition, closedAt time.Time) {
+	closedAt = closedAt.UTC()
+	pos.Status = StatusClosed
+	pos.ClosedAt = &closedAt
+
+	s.logger.Info("derivative position closed",
+		"position_id", pos.ID,
+		"realized_pnl_usd", pos.RealizedPnLUSD,
+	)
+}
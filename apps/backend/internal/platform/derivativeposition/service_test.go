@@ -0,0 +1,223 @@
+package derivativeposition
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// mockRepo is an in-memory implementation of Repository for testing.
+type mockRepo struct {
+	positions map[uuid.UUID]*DerivativePosition
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{positions: make(map[uuid.UUID]*DerivativePosition)}
+}
+
+func (r *mockRepo) Create(_ context.Context, pos *DerivativePosition) error {
+	r.positions[pos.ID] = pos
+	return nil
+}
+
+func (r *mockRepo) Update(_ context.Context, pos *DerivativePosition) error {
+	r.positions[pos.ID] = pos
+	return nil
+}
+
+func (r *mockRepo) GetByID(_ context.Context, id uuid.UUID) (*DerivativePosition, error) {
+	pos, ok := r.positions[id]
+	if !ok {
+		return nil, nil
+	}
+	return pos, nil
+}
+
+func (r *mockRepo) FindActiveByWalletAndAsset(_ context.Context, walletID uuid.UUID, protocol, chainID, asset string) (*DerivativePosition, error) {
+	for _, pos := range r.positions {
+		if pos.WalletID == walletID && pos.Protocol == protocol && pos.ChainID == chainID &&
+			pos.Asset == asset && pos.Status == StatusActive {
+			return pos, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *mockRepo) ListByUser(_ context.Context, userID uuid.UUID, status *Status, walletID *uuid.UUID, chainID *string) ([]*DerivativePosition, error) {
+	var result []*DerivativePosition
+	for _, pos := range r.positions {
+		if pos.UserID != userID {
+			continue
+		}
+		if status != nil && pos.Status != *status {
+			continue
+		}
+		if walletID != nil && pos.WalletID != *walletID {
+			continue
+		}
+		if chainID != nil && pos.ChainID != *chainID {
+			continue
+		}
+		result = append(result, pos)
+	}
+	return result, nil
+}
+
+func (r *mockRepo) ListByWallets(_ context.Context, walletIDs []uuid.UUID, status *Status, chainID *string) ([]*DerivativePosition, error) {
+	var result []*DerivativePosition
+	for _, pos := range r.positions {
+		if !slices.Contains(walletIDs, pos.WalletID) {
+			continue
+		}
+		if status != nil && pos.Status != *status {
+			continue
+		}
+		if chainID != nil && pos.ChainID != *chainID {
+			continue
+		}
+		result = append(result, pos)
+	}
+	return result, nil
+}
+
+// mockWallets maps each wallet to the users who can view it
+type mockWallets map[uuid.UUID][]uuid.UUID
+
+func (m mockWallets) GetByID(_ context.Context, id, userID uuid.UUID) (*wallet.Wallet, error) {
+	viewers, ok := m[id]
+	if !ok {
+		return nil, wallet.ErrWalletNotFound
+	}
+	if !slices.Contains(viewers, userID) {
+		return nil, wallet.ErrUnauthorizedAccess
+	}
+	return &wallet.Wallet{ID: id}, nil
+}
+
+func (m mockWallets) List(_ context.Context, userID uuid.UUID) ([]*wallet.Wallet, error) {
+	var result []*wallet.Wallet
+	for id, viewers := range m {
+		if slices.Contains(viewers, userID) {
+			result = append(result, &wallet.Wallet{ID: id})
+		}
+	}
+	return result, nil
+}
+
+func newTestService() (*Service, *mockRepo) {
+	repo := newMockRepo()
+	log := logger.New("test", io.Discard)
+	svc := NewService(repo, mockWallets{}, log)
+	return svc, repo
+}
+
+func TestFindOrCreate_ReusesActivePosition(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+	userID, walletID := uuid.New(), uuid.New()
+
+	pos, err := svc.FindOrCreate(ctx, userID, walletID, "GMX V2", "arbitrum", "", "USDC", 6, "0xusdc", time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, pos.Market)
+
+	again, err := svc.FindOrCreate(ctx, userID, walletID, "GMX V2", "arbitrum", "ETH-USD", "USDC", 6, "0xusdc", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, pos.ID, again.ID, "should return existing position")
+	assert.Equal(t, "ETH-USD", again.Market, "known market should be recorded")
+
+	other, err := svc.FindOrCreate(ctx, userID, walletID, "Hyperliquid", "arbitrum", "", "USDC", 6, "0xusdc", time.Now())
+	require.NoError(t, err)
+	assert.NotEqual(t, pos.ID, other.ID)
+}
+
+func TestRecordClose_ProfitClosesPosition(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := context.Background()
+
+	pos, err := svc.FindOrCreate(ctx, uuid.New(), uuid.New(), "GMX V2", "arbitrum", "ETH-USD", "USDC", 6, "", time.Now())
+	require.NoError(t, err)
+	require.NoError(t, svc.RecordOpen(ctx, pos.ID, big.NewInt(1000), big.NewInt(100)))
+	closedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, svc.RecordClose(ctx, pos.ID, big.NewInt(1250), big.NewInt(125), big.NewInt(1000), big.NewInt(100), closedAt))
+
+	updated := repo.positions[pos.ID]
+	assert.Equal(t, 0, updated.Margin.Sign())
+	assert.Equal(t, big.NewInt(1250), updated.TotalWithdrawn)
+	assert.Equal(t, big.NewInt(250), updated.RealizedPnL)
+	assert.Equal(t, big.NewInt(25), updated.RealizedPnLUSD)
+	assert.Equal(t, StatusClosed, updated.Status)
+	require.NotNil(t, updated.ClosedAt)
+	assert.Equal(t, closedAt, *updated.ClosedAt, "closed as of the close transaction")
+}
+
+func TestRecordClose_PartialLossKeepsPositionOpen(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := context.Background()
+
+	pos, err := svc.FindOrCreate(ctx, uuid.New(), uuid.New(), "GMX V2", "arbitrum", "", "USDC", 6, "", time.Now())
+	require.NoError(t, err)
+	require.NoError(t, svc.RecordOpen(ctx, pos.ID, big.NewInt(1000), big.NewInt(100)))
+	require.NoError(t, svc.RecordClose(ctx, pos.ID, big.NewInt(300), big.NewInt(30), big.NewInt(400), big.NewInt(40), time.Now()))
+
+	updated := repo.positions[pos.ID]
+	assert.Equal(t, big.NewInt(600), updated.Margin)
+	assert.Equal(t, big.NewInt(-100), updated.RealizedPnL)
+	assert.Equal(t, big.NewInt(-10), updated.RealizedPnLUSD)
+	assert.Equal(t, StatusActive, updated.Status)
+}
+
+func TestRecordFunding_NetPnL(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := context.Background()
+
+	pos, err := svc.FindOrCreate(ctx, uuid.New(), uuid.New(), "Hyperliquid", "arbitrum", "BTC-USD", "USDC", 6, "", time.Now())
+	require.NoError(t, err)
+	require.NoError(t, svc.RecordOpen(ctx, pos.ID, big.NewInt(1000), big.NewInt(100)))
+	require.NoError(t, svc.RecordFunding(ctx, pos.ID, big.NewInt(50), big.NewInt(5), true))
+	require.NoError(t, svc.RecordFunding(ctx, pos.ID, big.NewInt(20), big.NewInt(2), false))
+	require.NoError(t, svc.RecordFundingClaim(ctx, pos.ID, big.NewInt(10), big.NewInt(1)))
+	require.NoError(t, svc.RecordClose(ctx, pos.ID, big.NewInt(1070), big.NewInt(107), big.NewInt(970), big.NewInt(97), time.Now()))
+
+	updated := repo.positions[pos.ID]
+	assert.Equal(t, big.NewInt(50), updated.FundingPaid)
+	assert.Equal(t, big.NewInt(30), updated.FundingReceived)
+	assert.Equal(t, StatusClosed, updated.Status, "claimed funding does not touch the margin")
+	// 10 realized, 2 net funding paid
+	assert.Equal(t, big.NewInt(8), updated.NetPnLUSD())
+}
+
+func TestListAccessible_IncludesSharedWalletPositions(t *testing.T) {
+	repo := newMockRepo()
+	ctx := context.Background()
+	owner, member, outsider, walletID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	svc := NewService(repo, mockWallets{walletID: {owner, member}}, logger.New("test", io.Discard))
+
+	pos, err := svc.FindOrCreate(ctx, owner, walletID, "GMX V2", "arbitrum", "ETH-USD", "USDC", 6, "0xusdc", time.Now())
+	require.NoError(t, err)
+
+	positions, err := svc.ListAccessible(ctx, member, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	assert.Equal(t, pos.ID, positions[0].ID)
+
+	got, err := svc.GetAccessible(ctx, member, pos.ID)
+	require.NoError(t, err)
+	assert.Equal(t, pos.ID, got.ID)
+
+	positions, err = svc.ListAccessible(ctx, outsider, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, positions)
+
+	_, err = svc.GetAccessible(ctx, outsider, pos.ID)
+	assert.ErrorIs(t, err, ErrPositionNotFound)
+}
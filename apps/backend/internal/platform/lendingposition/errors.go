@@ -0,0 +1,5 @@
+package lendingposition
+
+import "errors"
+
+var ErrPositionNotFound = errors.New("lending position not found")
@@ -2,6 +2,7 @@ package lendingposition
 
 import (
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,17 @@ const (
 	StatusClosed Status = "closed"
 )
 
+// Side tells whether a leg is collateral supplied or debt borrowed
+type Side string
+
+const (
+	SideSupply Side = "supply"
+	SideBorrow Side = "borrow"
+)
+
+// LendingPosition is a wallet's account on a lending protocol on one chain.
+// Like an AAVE account, it can supply several collateral assets and borrow
+// several assets at once; each asset is a leg.
 type LendingPosition struct {
 	ID       uuid.UUID
 	UserID   uuid.UUID
@@ -21,27 +33,31 @@ type LendingPosition struct {
 	ChainID  string
 	Protocol string
 
-	SupplyAsset    string
-	SupplyAmount   *big.Int // current supply balance
-	SupplyDecimals int
-	SupplyContract string
-
-	BorrowAsset    string
-	BorrowAmount   *big.Int // current borrow balance
-	BorrowDecimals int
-	BorrowContract string
-
-	TotalSupplied  *big.Int
-	TotalWithdrawn *big.Int
-	TotalBorrowed  *big.Int
-	TotalRepaid    *big.Int
+	Legs []*Leg
 
+	// USD totals across all legs at the prices of each event
 	TotalSuppliedUSD  *big.Int
 	TotalWithdrawnUSD *big.Int
 	TotalBorrowedUSD  *big.Int
 	TotalRepaidUSD    *big.Int
 
 	InterestEarnedUSD *big.Int
+	InterestPaidUSD   *big.Int
+
+	// Collateral seized by liquidators at the prices of each liquidation,
+	// and the part of it worth more than the debt repaid
+	TotalLiquidatedUSD    *big.Int
+	LiquidationPenaltyUSD *big.Int
+	LastLiquidatedAt      *time.Time
+
+	// Market value and liquidation risk at current prices, refreshed on
+	// sync; nil until refreshed
+	SupplyValueUSD          *big.Int
+	BorrowValueUSD          *big.Int
+	LiquidationThresholdBps *int // collateral-value weighted
+	LTVBps                  *int
+	HealthFactor            *big.Int // scaled by 1e4 (10000 = 1.0), nil without debt
+	RiskUpdatedAt           *time.Time
 
 	Status   Status
 	OpenedAt time.Time
@@ -51,7 +67,104 @@ type LendingPosition struct {
 	UpdatedAt time.Time
 }
 
-// ShouldClose returns true if both supply and borrow balances are zero or negative.
+// Leg is one asset supplied to or borrowed from a lending account
+type Leg struct {
+	ID         uuid.UUID
+	PositionID uuid.UUID
+	Side       Side
+	Asset      string
+	Decimals   int
+	Contract   string
+
+	Amount *big.Int // current balance, accrued interest included
+
+	TotalIn     *big.Int // supplied or borrowed
+	TotalOut    *big.Int // withdrawn or repaid
+	TotalInUSD  *big.Int
+	TotalOutUSD *big.Int
+
+	// Interest accrued on this leg: earned on supply legs, owed on borrow legs
+	Interest    *big.Int
+	InterestUSD *big.Int
+
+	// Market value at the last refresh; nil until refreshed
+	USDPrice *big.Int
+	ValueUSD *big.Int
+	// Supply legs only: price of this asset (scaled by 1e8) at which the
+	// account becomes liquidatable if all other prices hold; nil when no
+	// drop of this asset alone can liquidate the account
+	LiquidationPriceUSD *big.Int
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// AssetInfo identifies the asset of a leg
+type AssetInfo struct {
+	Symbol   string
+	Decimals int
+	Contract string
+}
+
+// Leg returns the leg for asset on side, or nil. Legs match by contract
+// when both sides know it, else by symbol.
+func (p *LendingPosition) Leg(side Side, asset AssetInfo) *Leg {
+	for _, l := range p.Legs {
+		if l.Side != side {
+			continue
+		}
+		if asset.Contract != "" && l.Contract != "" {
+			if strings.EqualFold(l.Contract, asset.Contract) {
+				return l
+			}
+			continue
+		}
+		if strings.EqualFold(l.Asset, asset.Symbol) {
+			return l
+		}
+	}
+	return nil
+}
+
+// LegsBySide returns the position's supply or borrow legs
+func (p *LendingPosition) LegsBySide(side Side) []*Leg {
+	var legs []*Leg
+	for _, l := range p.Legs {
+		if l.Side == side {
+			legs = append(legs, l)
+		}
+	}
+	return legs
+}
+
+// ShouldClose returns true if every leg's balance is zero or negative.
 func (p *LendingPosition) ShouldClose() bool {
-	return p.SupplyAmount.Sign() <= 0 && p.BorrowAmount.Sign() <= 0
+	for _, l := range p.Legs {
+		if l.Amount.Sign() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// newLeg returns an empty leg of the position
+func (p *LendingPosition) newLeg(side Side, asset AssetInfo) *Leg {
+	now := time.Now().UTC()
+	return &Leg{
+		ID:          uuid.New(),
+		PositionID:  p.ID,
+		Side:        side,
+		Asset:       asset.Symbol,
+		Decimals:    asset.Decimals,
+		Contract:    asset.Contract,
+		Amount:      big.NewInt(0),
+		TotalIn:     big.NewInt(0),
+		TotalOut:    big.NewInt(0),
+		TotalInUSD:  big.NewInt(0),
+		TotalOutUSD: big.NewInt(0),
+		Interest:    big.NewInt(0),
+		InterestUSD: big.NewInt(0),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
 }
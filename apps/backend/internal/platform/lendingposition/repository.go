@@ -6,10 +6,12 @@ import (
 	"github.com/google/uuid"
 )
 
+// Repository persists lending positions together with their legs
 type Repository interface {
 	Create(ctx context.Context, pos *LendingPosition) error
 	Update(ctx context.Context, pos *LendingPosition) error
 	GetByID(ctx context.Context, id uuid.UUID) (*LendingPosition, error)
-	FindActiveByWalletAndAsset(ctx context.Context, walletID uuid.UUID, protocol, chainID, supplyAsset, borrowAsset string) (*LendingPosition, error)
+	FindActive(ctx context.Context, walletID uuid.UUID, protocol, chainID string) (*LendingPosition, error)
 	ListByUser(ctx context.Context, userID uuid.UUID, status *Status, walletID *uuid.UUID, chainID *string) ([]*LendingPosition, error)
+	ListByWallets(ctx context.Context, walletIDs []uuid.UUID, status *Status, chainID *string) ([]*LendingPosition, error)
 }
@@ -0,0 +1,138 @@
+package lendingposition
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// HealthFactorScale is the fixed-point scale of HealthFactor (10000 = 1.0)
+const HealthFactorScale = 10_000
+
+// defaultLiquidationThresholdBps applies to collateral without known parameters
+const defaultLiquidationThresholdBps = 7500
+
+// liquidationThresholds are the liquidation thresholds of common collateral
+// assets, in basis points, as configured on the Aave V3 Ethereum market
+var liquidationThresholds = map[string]int{
+	"ETH":    8300,
+	"WETH":   8300,
+	"WSTETH": 8100,
+	"RETH":   7700,
+	"CBETH":  7700,
+	"WEETH":  7750,
+	"WBTC":   7800,
+	"CBBTC":  7800,
+	"USDC":   7800,
+	"USDT":   7800,
+	"DAI":    7700,
+	"LINK":   6800,
+	"AAVE":   7300,
+}
+
+// LiquidationThresholdBps returns the liquidation threshold for collateral
+// of the given asset, in basis points
+func LiquidationThresholdBps(asset string) int {
+	if bps, ok := liquidationThresholds[strings.ToUpper(asset)]; ok {
+		return bps
+	}
+	return defaultLiquidationThresholdBps
+}
+
+// Risk is a snapshot of an account's liquidation risk at current prices
+type Risk struct {
+	SupplyValueUSD          *big.Int
+	BorrowValueUSD          *big.Int
+	LiquidationThresholdBps *int     // collateral-value weighted, nil without collateral value
+	LTVBps                  *int     // nil without collateral value
+	HealthFactor            *big.Int // scaled by HealthFactorScale, nil without debt
+
+	// Per leg, keyed by leg ID
+	LegValueUSD         map[uuid.UUID]*big.Int
+	LiquidationPriceUSD map[uuid.UUID]*big.Int // supply legs that can trigger liquidation
+}
+
+// ComputeRisk values every leg at prices (keyed by leg ID, USD scaled by
+// 1e8; legs without a price are worth zero) and derives the account's risk:
+//
+//	LTV           = Σ borrowUSD / Σ supplyUSD
+//	health factor = Σ (supplyUSD × liquidationThreshold) / Σ borrowUSD
+//
+// The liquidation price of a supply leg is the price of its asset at which
+// the health factor falls to 1, assuming every other price holds.
+func (p *LendingPosition) ComputeRisk(prices map[uuid.UUID]*big.Int) Risk {
+	risk := Risk{
+		SupplyValueUSD:      big.NewInt(0),
+		BorrowValueUSD:      big.NewInt(0),
+		LegValueUSD:         make(map[uuid.UUID]*big.Int),
+		LiquidationPriceUSD: make(map[uuid.UUID]*big.Int),
+	}
+
+	// Σ supplyUSD × threshold, in USD·bps
+	weighted := big.NewInt(0)
+	for _, l := range p.Legs {
+		value := money.CalcUSDValue(nonNegative(l.Amount), prices[l.ID], l.Decimals)
+		risk.LegValueUSD[l.ID] = value
+
+		switch l.Side {
+		case SideSupply:
+			risk.SupplyValueUSD.Add(risk.SupplyValueUSD, value)
+			weighted.Add(weighted, new(big.Int).Mul(value, big.NewInt(int64(LiquidationThresholdBps(l.Asset)))))
+		case SideBorrow:
+			risk.BorrowValueUSD.Add(risk.BorrowValueUSD, value)
+		}
+	}
+
+	if risk.SupplyValueUSD.Sign() > 0 {
+		risk.LiquidationThresholdBps = bpsOf(weighted, risk.SupplyValueUSD, 1)
+		risk.LTVBps = bpsOf(risk.BorrowValueUSD, risk.SupplyValueUSD, 10_000)
+	}
+
+	if risk.BorrowValueUSD.Sign() <= 0 {
+		return risk
+	}
+
+	risk.HealthFactor = new(big.Int).Quo(weighted, risk.BorrowValueUSD)
+
+	// Threshold-weighted collateral that keeps the health factor at 1
+	required := new(big.Int).Mul(risk.BorrowValueUSD, big.NewInt(10_000))
+	for _, l := range p.LegsBySide(SideSupply) {
+		amount := nonNegative(l.Amount)
+		if amount.Sign() <= 0 {
+			continue
+		}
+		threshold := big.NewInt(int64(LiquidationThresholdBps(l.Asset)))
+		others := new(big.Int).Sub(weighted, new(big.Int).Mul(risk.LegValueUSD[l.ID], threshold))
+		shortfall := new(big.Int).Sub(required, others)
+		if shortfall.Sign() <= 0 {
+			continue
+		}
+
+		price := shortfall.Mul(shortfall, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(l.Decimals)), nil))
+		price.Quo(price, new(big.Int).Mul(amount, threshold))
+		risk.LiquidationPriceUSD[l.ID] = price
+	}
+
+	return risk
+}
+
+// bpsOf returns num × scale / den as an int, or nil when it overflows
+func bpsOf(num, den *big.Int, scale int64) *int {
+	v := new(big.Int).Mul(num, big.NewInt(scale))
+	v.Quo(v, den)
+	if !v.IsInt64() || v.Int64() > 1<<31-1 {
+		return nil
+	}
+	i := int(v.Int64())
+	return &i
+}
+
+func nonNegative(v *big.Int) *big.Int {
+	if v == nil || v.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return v
+}
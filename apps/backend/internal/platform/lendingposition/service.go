@@ -8,31 +8,33 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
 
 type Service struct {
-	repo   Repository
-	logger *logger.Logger
+	repo    Repository
+	wallets wallet.Reader
+	logger  *logger.Logger
 }
 
-func NewService(repo Repository, log *logger.Logger) *Service {
+func NewService(repo Repository, wallets wallet.Reader, log *logger.Logger) *Service {
 	return &Service{
-		repo:   repo,
-		logger: log.WithField("component", "lendingposition"),
+		repo:    repo,
+		wallets: wallets,
+		logger:  log.WithField("component", "lendingposition"),
 	}
 }
 
-// FindOrCreate looks up an active lending position by wallet+protocol+chain+asset,
-// or creates a new one.
+// FindOrCreate looks up the active lending account of a wallet on a
+// protocol and chain, or creates a new one.
 func (s *Service) FindOrCreate(
 	ctx context.Context,
 	userID, walletID uuid.UUID,
-	protocol, chainID, supplyAsset string,
-	supplyDecimals int, supplyContract string,
+	protocol, chainID string,
 	openedAt time.Time,
 ) (*LendingPosition, error) {
-	existing, err := s.repo.FindActiveByWalletAndAsset(ctx, walletID, protocol, chainID, supplyAsset, "")
+	existing, err := s.repo.FindActive(ctx, walletID, protocol, chainID)
 	if err != nil {
 		return nil, fmt.Errorf("find active position: %w", err)
 	}
@@ -47,24 +49,16 @@ func (s *Service) FindOrCreate(
 		ChainID:  chainID,
 		Protocol: protocol,
 
-		SupplyAsset:    supplyAsset,
-		SupplyAmount:   big.NewInt(0),
-		SupplyDecimals: supplyDecimals,
-		SupplyContract: supplyContract,
-
-		BorrowAmount: big.NewInt(0),
-
-		TotalSupplied:  big.NewInt(0),
-		TotalWithdrawn: big.NewInt(0),
-		TotalBorrowed:  big.NewInt(0),
-		TotalRepaid:    big.NewInt(0),
-
 		TotalSuppliedUSD:  big.NewInt(0),
 		TotalWithdrawnUSD: big.NewInt(0),
 		TotalBorrowedUSD:  big.NewInt(0),
 		TotalRepaidUSD:    big.NewInt(0),
 
 		InterestEarnedUSD: big.NewInt(0),
+		InterestPaidUSD:   big.NewInt(0),
+
+		TotalLiquidatedUSD:    big.NewInt(0),
+		LiquidationPenaltyUSD: big.NewInt(0),
 
 		Status:   StatusActive,
 		OpenedAt: openedAt,
@@ -80,38 +74,115 @@ func (s *Service) FindOrCreate(
 	s.logger.Info("lending position created",
 		"position_id", pos.ID,
 		"protocol", protocol,
-		"supply_asset", supplyAsset,
+		"chain_id", chainID,
 	)
 
 	return pos, nil
 }
 
-// RecordSupply adds to supply totals and current supply balance.
-func (s *Service) RecordSupply(ctx context.Context, positionID uuid.UUID, amount, usdValue *big.Int) error {
+// RecordSupply adds to the asset's supply leg and the supply totals.
+func (s *Service) RecordSupply(ctx context.Context, positionID uuid.UUID, asset AssetInfo, amount, usdValue *big.Int) error {
+	return s.recordMove(ctx, positionID, SideSupply, asset, amount, usdValue, true)
+}
+
+// RecordWithdraw subtracts from the asset's supply leg. May close position.
+func (s *Service) RecordWithdraw(ctx context.Context, positionID uuid.UUID, asset AssetInfo, amount, usdValue *big.Int) error {
+	return s.recordMove(ctx, positionID, SideSupply, asset, amount, usdValue, false)
+}
+
+// RecordBorrow adds to the asset's borrow leg and the borrow totals.
+func (s *Service) RecordBorrow(ctx context.Context, positionID uuid.UUID, asset AssetInfo, amount, usdValue *big.Int) error {
+	return s.recordMove(ctx, positionID, SideBorrow, asset, amount, usdValue, true)
+}
+
+// RecordRepay subtracts from the asset's borrow leg. May close position.
+func (s *Service) RecordRepay(ctx context.Context, positionID uuid.UUID, asset AssetInfo, amount, usdValue *big.Int) error {
+	return s.recordMove(ctx, positionID, SideBorrow, asset, amount, usdValue, false)
+}
+
+// recordMove applies a supply, withdraw, borrow or repay to the leg of asset
+// on side, creating the leg on first use.
+func (s *Service) recordMove(ctx context.Context, positionID uuid.UUID, side Side, asset AssetInfo, amount, usdValue *big.Int, in bool) error {
 	pos, err := s.getPosition(ctx, positionID)
 	if err != nil {
 		return err
 	}
 
-	pos.SupplyAmount.Add(pos.SupplyAmount, amount)
-	pos.TotalSupplied.Add(pos.TotalSupplied, amount)
-	pos.TotalSuppliedUSD.Add(pos.TotalSuppliedUSD, usdValue)
+	leg := s.leg(pos, side, asset)
+	if in {
+		leg.Amount.Add(leg.Amount, amount)
+		leg.TotalIn.Add(leg.TotalIn, amount)
+		leg.TotalInUSD.Add(leg.TotalInUSD, usdValue)
+	} else {
+		leg.Amount.Sub(leg.Amount, amount)
+		leg.TotalOut.Add(leg.TotalOut, amount)
+		leg.TotalOutUSD.Add(leg.TotalOutUSD, usdValue)
+	}
+	leg.UpdatedAt = time.Now().UTC()
+
+	switch {
+	case side == SideSupply && in:
+		pos.TotalSuppliedUSD.Add(pos.TotalSuppliedUSD, usdValue)
+	case side == SideSupply:
+		pos.TotalWithdrawnUSD.Add(pos.TotalWithdrawnUSD, usdValue)
+	case in:
+		pos.TotalBorrowedUSD.Add(pos.TotalBorrowedUSD, usdValue)
+	default:
+		pos.TotalRepaidUSD.Add(pos.TotalRepaidUSD, usdValue)
+	}
 	pos.UpdatedAt = time.Now().UTC()
 
+	if !in && pos.ShouldClose() {
+		s.closePosition(pos)
+	}
+
 	return s.repo.Update(ctx, pos)
 }
 
-// RecordWithdraw subtracts from supply balance. May close position.
-func (s *Service) RecordWithdraw(ctx context.Context, positionID uuid.UUID, amount, usdValue *big.Int) error {
+// RecordLiquidation records a liquidator seizing collateral to repay debt:
+// the seized amount leaves the collateral leg, the repaid amount leaves the
+// debt leg, and the value seized above the debt repaid is the penalty. May
+// close position.
+func (s *Service) RecordLiquidation(
+	ctx context.Context,
+	positionID uuid.UUID,
+	collateral AssetInfo, seized, seizedUSD *big.Int,
+	debt AssetInfo, repaid, repaidUSD *big.Int,
+	at time.Time,
+) error {
 	pos, err := s.getPosition(ctx, positionID)
 	if err != nil {
 		return err
 	}
 
-	pos.SupplyAmount.Sub(pos.SupplyAmount, amount)
-	pos.TotalWithdrawn.Add(pos.TotalWithdrawn, amount)
-	pos.TotalWithdrawnUSD.Add(pos.TotalWithdrawnUSD, usdValue)
-	pos.UpdatedAt = time.Now().UTC()
+	now := time.Now().UTC()
+	supply := s.leg(pos, SideSupply, collateral)
+	supply.Amount.Sub(supply.Amount, seized)
+	supply.TotalOut.Add(supply.TotalOut, seized)
+	supply.TotalOutUSD.Add(supply.TotalOutUSD, seizedUSD)
+	supply.UpdatedAt = now
+
+	borrow := s.leg(pos, SideBorrow, debt)
+	borrow.Amount.Sub(borrow.Amount, repaid)
+	borrow.TotalOut.Add(borrow.TotalOut, repaid)
+	borrow.TotalOutUSD.Add(borrow.TotalOutUSD, repaidUSD)
+	borrow.UpdatedAt = now
+
+	pos.TotalLiquidatedUSD.Add(pos.TotalLiquidatedUSD, seizedUSD)
+	pos.TotalRepaidUSD.Add(pos.TotalRepaidUSD, repaidUSD)
+	if penalty := new(big.Int).Sub(seizedUSD, repaidUSD); penalty.Sign() > 0 {
+		pos.LiquidationPenaltyUSD.Add(pos.LiquidationPenaltyUSD, penalty)
+	}
+	pos.LastLiquidatedAt = &at
+	pos.UpdatedAt = now
+
+	s.logger.Warn("lending position liquidated",
+		"position_id", pos.ID,
+		"collateral", collateral.Symbol,
+		"debt", debt.Symbol,
+		"seized_usd", seizedUSD,
+		"repaid_usd", repaidUSD,
+	)
 
 	if pos.ShouldClose() {
 		s.closePosition(pos)
@@ -120,58 +191,71 @@ func (s *Service) RecordWithdraw(ctx context.Context, positionID uuid.UUID, amou
 	return s.repo.Update(ctx, pos)
 }
 
-// RecordBorrow sets borrow asset info and adds to borrow totals.
-func (s *Service) RecordBorrow(
-	ctx context.Context,
-	positionID uuid.UUID,
-	borrowAsset string, borrowDecimals int, borrowContract string,
-	amount, usdValue *big.Int,
-) error {
+// RecordClaim adds to interest earned.
+func (s *Service) RecordClaim(ctx context.Context, positionID uuid.UUID, usdValue *big.Int) error {
 	pos, err := s.getPosition(ctx, positionID)
 	if err != nil {
 		return err
 	}
 
-	pos.BorrowAsset = borrowAsset
-	pos.BorrowDecimals = borrowDecimals
-	pos.BorrowContract = borrowContract
-	pos.BorrowAmount.Add(pos.BorrowAmount, amount)
-	pos.TotalBorrowed.Add(pos.TotalBorrowed, amount)
-	pos.TotalBorrowedUSD.Add(pos.TotalBorrowedUSD, usdValue)
+	pos.InterestEarnedUSD.Add(pos.InterestEarnedUSD, usdValue)
 	pos.UpdatedAt = time.Now().UTC()
 
 	return s.repo.Update(ctx, pos)
 }
 
-// RecordRepay subtracts from borrow balance. May close position.
-func (s *Service) RecordRepay(ctx context.Context, positionID uuid.UUID, amount, usdValue *big.Int) error {
+// RecordInterest adds interest accrued on a leg to its balance: earned on a
+// supply leg, owed on a borrow leg.
+func (s *Service) RecordInterest(ctx context.Context, positionID uuid.UUID, side Side, asset AssetInfo, amount, usdValue *big.Int) error {
 	pos, err := s.getPosition(ctx, positionID)
 	if err != nil {
 		return err
 	}
 
-	pos.BorrowAmount.Sub(pos.BorrowAmount, amount)
-	pos.TotalRepaid.Add(pos.TotalRepaid, amount)
-	pos.TotalRepaidUSD.Add(pos.TotalRepaidUSD, usdValue)
-	pos.UpdatedAt = time.Now().UTC()
+	leg := s.leg(pos, side, asset)
+	leg.Amount.Add(leg.Amount, amount)
+	leg.Interest.Add(leg.Interest, amount)
+	leg.InterestUSD.Add(leg.InterestUSD, usdValue)
+	leg.UpdatedAt = time.Now().UTC()
 
-	if pos.ShouldClose() {
-		s.closePosition(pos)
+	if side == SideSupply {
+		pos.InterestEarnedUSD.Add(pos.InterestEarnedUSD, usdValue)
+	} else {
+		pos.InterestPaidUSD.Add(pos.InterestPaidUSD, usdValue)
 	}
+	pos.UpdatedAt = time.Now().UTC()
 
 	return s.repo.Update(ctx, pos)
 }
 
-// RecordClaim adds to interest earned.
-func (s *Service) RecordClaim(ctx context.Context, positionID uuid.UUID, usdValue *big.Int) error {
+// RecordRisk stores the account's market value, LTV, health factor and the
+// liquidation price of each collateral at the given USD prices (keyed by leg
+// ID, scaled by 1e8). Closed positions are left unchanged.
+func (s *Service) RecordRisk(ctx context.Context, positionID uuid.UUID, prices map[uuid.UUID]*big.Int, at time.Time) error {
 	pos, err := s.getPosition(ctx, positionID)
 	if err != nil {
 		return err
 	}
+	if pos.Status != StatusActive {
+		return nil
+	}
 
-	pos.InterestEarnedUSD.Add(pos.InterestEarnedUSD, usdValue)
+	risk := pos.ComputeRisk(prices)
+	pos.SupplyValueUSD = risk.SupplyValueUSD
+	pos.BorrowValueUSD = risk.BorrowValueUSD
+	pos.LiquidationThresholdBps = risk.LiquidationThresholdBps
+	pos.LTVBps = risk.LTVBps
+	pos.HealthFactor = risk.HealthFactor
+	pos.RiskUpdatedAt = &at
 	pos.UpdatedAt = time.Now().UTC()
 
+	for _, l := range pos.Legs {
+		l.USDPrice = prices[l.ID]
+		l.ValueUSD = risk.LegValueUSD[l.ID]
+		l.LiquidationPriceUSD = risk.LiquidationPriceUSD[l.ID]
+		l.UpdatedAt = pos.UpdatedAt
+	}
+
 	return s.repo.Update(ctx, pos)
 }
 
@@ -185,6 +269,40 @@ func (s *Service) ListByUser(ctx context.Context, userID uuid.UUID, status *Stat
 	return s.repo.ListByUser(ctx, userID, status, walletID, chainID)
 }
 
+// GetAccessible returns a position on a wallet the user can view. Positions
+// the user cannot view read as ErrPositionNotFound.
+func (s *Service) GetAccessible(ctx context.Context, userID, id uuid.UUID) (*LendingPosition, error) {
+	pos, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get position: %w", err)
+	}
+	if pos == nil {
+		return nil, ErrPositionNotFound
+	}
+
+	ok, err := wallet.CanView(ctx, s.wallets, pos.WalletID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("check wallet access: %w", err)
+	}
+	if !ok {
+		return nil, ErrPositionNotFound
+	}
+	return pos, nil
+}
+
+// ListAccessible returns positions on the wallets the user can view through
+// their workspaces, with optional filters.
+func (s *Service) ListAccessible(ctx context.Context, userID uuid.UUID, status *Status, walletID *uuid.UUID, chainID *string) ([]*LendingPosition, error) {
+	walletIDs, err := wallet.AccessibleIDs(ctx, s.wallets, userID, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve accessible wallets: %w", err)
+	}
+	if len(walletIDs) == 0 {
+		return nil, nil
+	}
+	return s.repo.ListByWallets(ctx, walletIDs, status, chainID)
+}
+
 func (s *Service) getPosition(ctx context.Context, id uuid.UUID) (*LendingPosition, error) {
 	pos, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -196,6 +314,25 @@ func (s *Service) getPosition(ctx context.Context, id uuid.UUID) (*LendingPositi
 	return pos, nil
 }
 
+// leg returns the position's leg for asset on side, adding it if missing
+func (s *Service) leg(pos *LendingPosition, side Side, asset AssetInfo) *Leg {
+	if l := pos.Leg(side, asset); l != nil {
+		if l.Contract == "" && asset.Contract != "" {
+			l.Contract = asset.Contract
+		}
+		return l
+	}
+
+	l := pos.newLeg(side, asset)
+	pos.Legs = append(pos.Legs, l)
+	s.logger.Debug("lending leg added",
+		"position_id", pos.ID,
+		"side", side,
+		"asset", asset.Symbol,
+	)
+	return l
+}
+
 func (s *Service) closePosition(pos *LendingPosition) {
 	now := time.Now().UTC()
 	pos.Status = StatusClosed
@@ -204,5 +341,6 @@ func (s *Service) closePosition(pos *LendingPosition) {
 	s.logger.Info("lending position closed",
 		"position_id", pos.ID,
 		"interest_earned_usd", pos.InterestEarnedUSD,
+		"interest_paid_usd", pos.InterestPaidUSD,
 	)
 }
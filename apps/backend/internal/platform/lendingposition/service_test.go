@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"math/big"
+	"slices"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
 
@@ -41,13 +43,10 @@ func (r *mockRepo) GetByID(_ context.Context, id uuid.UUID) (*LendingPosition, e
 	return pos, nil
 }
 
-func (r *mockRepo) FindActiveByWalletAndAsset(_ context.Context, walletID uuid.UUID, protocol, chainID, supplyAsset, borrowAsset string) (*LendingPosition, error) {
+func (r *mockRepo) FindActive(_ context.Context, walletID uuid.UUID, protocol, chainID string) (*LendingPosition, error) {
 	for _, pos := range r.positions {
-		if pos.WalletID == walletID && pos.Protocol == protocol && pos.ChainID == chainID &&
-			pos.SupplyAsset == supplyAsset && pos.Status == StatusActive {
-			if borrowAsset == "" || pos.BorrowAsset == borrowAsset {
-				return pos, nil
-			}
+		if pos.WalletID == walletID && pos.Protocol == protocol && pos.ChainID == chainID && pos.Status == StatusActive {
+			return pos, nil
 		}
 	}
 	return nil, nil
@@ -73,13 +72,61 @@ func (r *mockRepo) ListByUser(_ context.Context, userID uuid.UUID, status *Statu
 	return result, nil
 }
 
+func (r *mockRepo) ListByWallets(_ context.Context, walletIDs []uuid.UUID, status *Status, chainID *string) ([]*LendingPosition, error) {
+	var result []*LendingPosition
+	for _, pos := range r.positions {
+		if !slices.Contains(walletIDs, pos.WalletID) {
+			continue
+		}
+		if status != nil && pos.Status != *status {
+			continue
+		}
+		if chainID != nil && pos.ChainID != *chainID {
+			continue
+		}
+		result = append(result, pos)
+	}
+	return result, nil
+}
+
+// mockWallets maps each wallet to the users who can view it
+type mockWallets map[uuid.UUID][]uuid.UUID
+
+func (m mockWallets) GetByID(_ context.Context, id, userID uuid.UUID) (*wallet.Wallet, error) {
+	viewers, ok := m[id]
+	if !ok {
+		return nil, wallet.ErrWalletNotFound
+	}
+	if !slices.Contains(viewers, userID) {
+		return nil, wallet.ErrUnauthorizedAccess
+	}
+	return &wallet.Wallet{ID: id}, nil
+}
+
+func (m mockWallets) List(_ context.Context, userID uuid.UUID) ([]*wallet.Wallet, error) {
+	var result []*wallet.Wallet
+	for id, viewers := range m {
+		if slices.Contains(viewers, userID) {
+			result = append(result, &wallet.Wallet{ID: id})
+		}
+	}
+	return result, nil
+}
+
 func newTestService() (*Service, *mockRepo) {
 	repo := newMockRepo()
 	log := logger.New("test", io.Discard)
-	svc := NewService(repo, log)
+	svc := NewService(repo, mockWallets{}, log)
 	return svc, repo
 }
 
+var (
+	eth  = AssetInfo{Symbol: "ETH", Decimals: 18, Contract: ""}
+	weth = AssetInfo{Symbol: "WETH", Decimals: 18, Contract: "0xweth"}
+	usdc = AssetInfo{Symbol: "USDC", Decimals: 6, Contract: "0xusdc"}
+	dai  = AssetInfo{Symbol: "DAI", Decimals: 18, Contract: "0xdai"}
+)
+
 func createTestPosition(repo *mockRepo) *LendingPosition {
 	pos := &LendingPosition{
 		ID:       uuid.New(),
@@ -88,24 +135,16 @@ func createTestPosition(repo *mockRepo) *LendingPosition {
 		ChainID:  "ethereum",
 		Protocol: "Aave V3",
 
-		SupplyAsset:    "ETH",
-		SupplyAmount:   big.NewInt(0),
-		SupplyDecimals: 18,
-		SupplyContract: "0xeth",
-
-		BorrowAmount: big.NewInt(0),
-
-		TotalSupplied:  big.NewInt(0),
-		TotalWithdrawn: big.NewInt(0),
-		TotalBorrowed:  big.NewInt(0),
-		TotalRepaid:    big.NewInt(0),
-
 		TotalSuppliedUSD:  big.NewInt(0),
 		TotalWithdrawnUSD: big.NewInt(0),
 		TotalBorrowedUSD:  big.NewInt(0),
 		TotalRepaidUSD:    big.NewInt(0),
 
 		InterestEarnedUSD: big.NewInt(0),
+		InterestPaidUSD:   big.NewInt(0),
+
+		TotalLiquidatedUSD:    big.NewInt(0),
+		LiquidationPenaltyUSD: big.NewInt(0),
 
 		Status:    StatusActive,
 		OpenedAt:  time.Now().UTC().Add(-24 * time.Hour),
@@ -116,39 +155,67 @@ func createTestPosition(repo *mockRepo) *LendingPosition {
 	return pos
 }
 
+// addLeg seeds a leg holding amount
+func addLeg(pos *LendingPosition, side Side, asset AssetInfo, amount *big.Int) *Leg {
+	leg := pos.newLeg(side, asset)
+	leg.Amount.Set(amount)
+	leg.TotalIn.Set(amount)
+	pos.Legs = append(pos.Legs, leg)
+	return leg
+}
+
 func TestRecordSupply_UpdatesAggregates(t *testing.T) {
 	svc, repo := newTestService()
 	pos := createTestPosition(repo)
 	ctx := context.Background()
 
-	err := svc.RecordSupply(ctx, pos.ID, big.NewInt(1000), big.NewInt(500))
+	err := svc.RecordSupply(ctx, pos.ID, eth, big.NewInt(1000), big.NewInt(500))
 	require.NoError(t, err)
 
 	updated := repo.positions[pos.ID]
-	assert.Equal(t, big.NewInt(1000), updated.SupplyAmount)
-	assert.Equal(t, big.NewInt(1000), updated.TotalSupplied)
+	require.Len(t, updated.Legs, 1)
+	leg := updated.Legs[0]
+	assert.Equal(t, SideSupply, leg.Side)
+	assert.Equal(t, "ETH", leg.Asset)
+	assert.Equal(t, big.NewInt(1000), leg.Amount)
+	assert.Equal(t, big.NewInt(1000), leg.TotalIn)
+	assert.Equal(t, big.NewInt(500), leg.TotalInUSD)
 	assert.Equal(t, big.NewInt(500), updated.TotalSuppliedUSD)
 	assert.Equal(t, StatusActive, updated.Status)
 }
 
+func TestRecordSupply_MultipleCollaterals(t *testing.T) {
+	svc, repo := newTestService()
+	pos := createTestPosition(repo)
+	ctx := context.Background()
+
+	require.NoError(t, svc.RecordSupply(ctx, pos.ID, weth, big.NewInt(1000), big.NewInt(300)))
+	require.NoError(t, svc.RecordSupply(ctx, pos.ID, AssetInfo{Symbol: "wstETH", Decimals: 18, Contract: "0xwsteth"}, big.NewInt(200), big.NewInt(70)))
+	require.NoError(t, svc.RecordSupply(ctx, pos.ID, weth, big.NewInt(500), big.NewInt(150)))
+
+	updated := repo.positions[pos.ID]
+	require.Len(t, updated.LegsBySide(SideSupply), 2)
+	assert.Equal(t, big.NewInt(1500), updated.Leg(SideSupply, weth).Amount)
+	assert.Equal(t, big.NewInt(520), updated.TotalSuppliedUSD)
+}
+
 func TestRecordWithdraw_ClosesWhenFullyWithdrawn(t *testing.T) {
 	svc, repo := newTestService()
 	pos := createTestPosition(repo)
 	ctx := context.Background()
 
 	// Supply first
-	pos.SupplyAmount = big.NewInt(1000)
-	pos.TotalSupplied = big.NewInt(1000)
-	pos.TotalSuppliedUSD = big.NewInt(500)
+	addLeg(pos, SideSupply, eth, big.NewInt(1000))
 
 	// Withdraw everything
-	err := svc.RecordWithdraw(ctx, pos.ID, big.NewInt(1000), big.NewInt(600))
+	err := svc.RecordWithdraw(ctx, pos.ID, eth, big.NewInt(1000), big.NewInt(600))
 	require.NoError(t, err)
 
 	updated := repo.positions[pos.ID]
 	assert.Equal(t, StatusClosed, updated.Status)
 	assert.NotNil(t, updated.ClosedAt)
-	assert.Equal(t, 0, updated.SupplyAmount.Sign(), "supply amount should be zero")
+	assert.Equal(t, 0, updated.Leg(SideSupply, eth).Amount.Sign(), "supply amount should be zero")
+	assert.Equal(t, big.NewInt(600), updated.TotalWithdrawnUSD)
 }
 
 func TestRecordWithdraw_StaysOpenWithBorrow(t *testing.T) {
@@ -156,11 +223,11 @@ func TestRecordWithdraw_StaysOpenWithBorrow(t *testing.T) {
 	pos := createTestPosition(repo)
 	ctx := context.Background()
 
-	pos.SupplyAmount = big.NewInt(1000)
-	pos.BorrowAmount = big.NewInt(500) // Outstanding borrow
+	addLeg(pos, SideSupply, eth, big.NewInt(1000))
+	addLeg(pos, SideBorrow, usdc, big.NewInt(500)) // Outstanding borrow
 
 	// Withdraw all supply
-	err := svc.RecordWithdraw(ctx, pos.ID, big.NewInt(1000), big.NewInt(600))
+	err := svc.RecordWithdraw(ctx, pos.ID, eth, big.NewInt(1000), big.NewInt(600))
 	require.NoError(t, err)
 
 	updated := repo.positions[pos.ID]
@@ -168,21 +235,36 @@ func TestRecordWithdraw_StaysOpenWithBorrow(t *testing.T) {
 	assert.Nil(t, updated.ClosedAt)
 }
 
-func TestRecordBorrow_SetsBorrowAsset(t *testing.T) {
+func TestRecordWithdraw_StaysOpenWithOtherCollateral(t *testing.T) {
 	svc, repo := newTestService()
 	pos := createTestPosition(repo)
 	ctx := context.Background()
 
-	err := svc.RecordBorrow(ctx, pos.ID, "USDC", 6, "0xusdc", big.NewInt(2000), big.NewInt(2000))
+	addLeg(pos, SideSupply, eth, big.NewInt(1000))
+	addLeg(pos, SideSupply, dai, big.NewInt(1000))
+
+	err := svc.RecordWithdraw(ctx, pos.ID, eth, big.NewInt(1000), big.NewInt(600))
 	require.NoError(t, err)
+	assert.Equal(t, StatusActive, repo.positions[pos.ID].Status)
+}
+
+func TestRecordBorrow_AddsBorrowLegs(t *testing.T) {
+	svc, repo := newTestService()
+	pos := createTestPosition(repo)
+	ctx := context.Background()
+
+	require.NoError(t, svc.RecordBorrow(ctx, pos.ID, usdc, big.NewInt(2000), big.NewInt(2000)))
+	require.NoError(t, svc.RecordBorrow(ctx, pos.ID, dai, big.NewInt(3000), big.NewInt(3000)))
 
 	updated := repo.positions[pos.ID]
-	assert.Equal(t, "USDC", updated.BorrowAsset)
-	assert.Equal(t, 6, updated.BorrowDecimals)
-	assert.Equal(t, "0xusdc", updated.BorrowContract)
-	assert.Equal(t, big.NewInt(2000), updated.BorrowAmount)
-	assert.Equal(t, big.NewInt(2000), updated.TotalBorrowed)
-	assert.Equal(t, big.NewInt(2000), updated.TotalBorrowedUSD)
+	require.Len(t, updated.LegsBySide(SideBorrow), 2)
+	leg := updated.Leg(SideBorrow, usdc)
+	require.NotNil(t, leg)
+	assert.Equal(t, 6, leg.Decimals)
+	assert.Equal(t, "0xusdc", leg.Contract)
+	assert.Equal(t, big.NewInt(2000), leg.Amount)
+	assert.Equal(t, big.NewInt(2000), leg.TotalIn)
+	assert.Equal(t, big.NewInt(5000), updated.TotalBorrowedUSD)
 }
 
 func TestRecordRepay_ReducesDebt(t *testing.T) {
@@ -190,15 +272,16 @@ func TestRecordRepay_ReducesDebt(t *testing.T) {
 	pos := createTestPosition(repo)
 	ctx := context.Background()
 
-	pos.BorrowAsset = "USDC"
-	pos.BorrowAmount = big.NewInt(2000)
+	addLeg(pos, SideBorrow, usdc, big.NewInt(2000))
 
-	err := svc.RecordRepay(ctx, pos.ID, big.NewInt(1500), big.NewInt(1500))
+	err := svc.RecordRepay(ctx, pos.ID, usdc, big.NewInt(1500), big.NewInt(1500))
 	require.NoError(t, err)
 
 	updated := repo.positions[pos.ID]
-	assert.Equal(t, big.NewInt(500), updated.BorrowAmount)
-	assert.Equal(t, big.NewInt(1500), updated.TotalRepaid)
+	leg := updated.Leg(SideBorrow, usdc)
+	assert.Equal(t, big.NewInt(500), leg.Amount)
+	assert.Equal(t, big.NewInt(1500), leg.TotalOut)
+	assert.Equal(t, big.NewInt(1500), leg.TotalOutUSD)
 	assert.Equal(t, big.NewInt(1500), updated.TotalRepaidUSD)
 	assert.Equal(t, StatusActive, updated.Status)
 }
@@ -208,10 +291,10 @@ func TestRecordRepay_ClosesWhenFullyRepaidAndNoSupply(t *testing.T) {
 	pos := createTestPosition(repo)
 	ctx := context.Background()
 
-	pos.BorrowAmount = big.NewInt(500)
-	pos.SupplyAmount = big.NewInt(0)
+	addLeg(pos, SideBorrow, usdc, big.NewInt(500))
+	addLeg(pos, SideSupply, eth, big.NewInt(0))
 
-	err := svc.RecordRepay(ctx, pos.ID, big.NewInt(500), big.NewInt(500))
+	err := svc.RecordRepay(ctx, pos.ID, usdc, big.NewInt(500), big.NewInt(500))
 	require.NoError(t, err)
 
 	updated := repo.positions[pos.ID]
@@ -219,6 +302,52 @@ func TestRecordRepay_ClosesWhenFullyRepaidAndNoSupply(t *testing.T) {
 	assert.NotNil(t, updated.ClosedAt)
 }
 
+func TestRecordLiquidation_SeizesCollateralAndRepaysDebt(t *testing.T) {
+	svc, repo := newTestService()
+	pos := createTestPosition(repo)
+	ctx := context.Background()
+
+	addLeg(pos, SideSupply, weth, big.NewInt(1000))
+	addLeg(pos, SideSupply, dai, big.NewInt(1000))
+	addLeg(pos, SideBorrow, usdc, big.NewInt(2000))
+
+	at := time.Now().UTC()
+	err := svc.RecordLiquidation(ctx, pos.ID, weth, big.NewInt(400), big.NewInt(1050), usdc, big.NewInt(1000), big.NewInt(1000), at)
+	require.NoError(t, err)
+
+	updated := repo.positions[pos.ID]
+	supply := updated.Leg(SideSupply, weth)
+	assert.Equal(t, big.NewInt(600), supply.Amount)
+	assert.Equal(t, big.NewInt(400), supply.TotalOut)
+	assert.Equal(t, big.NewInt(1050), supply.TotalOutUSD)
+	assert.Equal(t, big.NewInt(1000), updated.Leg(SideBorrow, usdc).Amount)
+	assert.Equal(t, big.NewInt(1000), updated.Leg(SideSupply, dai).Amount, "other collateral untouched")
+
+	assert.Equal(t, big.NewInt(1050), updated.TotalLiquidatedUSD)
+	assert.Equal(t, big.NewInt(50), updated.LiquidationPenaltyUSD)
+	assert.Equal(t, big.NewInt(1000), updated.TotalRepaidUSD)
+	assert.Equal(t, 0, updated.TotalWithdrawnUSD.Sign(), "seized collateral is not a withdrawal")
+	require.NotNil(t, updated.LastLiquidatedAt)
+	assert.Equal(t, at, *updated.LastLiquidatedAt)
+	assert.Equal(t, StatusActive, updated.Status)
+}
+
+func TestRecordLiquidation_ClosesWhenFullyLiquidated(t *testing.T) {
+	svc, repo := newTestService()
+	pos := createTestPosition(repo)
+	ctx := context.Background()
+
+	addLeg(pos, SideSupply, weth, big.NewInt(1000))
+	addLeg(pos, SideBorrow, usdc, big.NewInt(2000))
+
+	err := svc.RecordLiquidation(ctx, pos.ID, weth, big.NewInt(1000), big.NewInt(2100), usdc, big.NewInt(2000), big.NewInt(2000), time.Now().UTC())
+	require.NoError(t, err)
+
+	updated := repo.positions[pos.ID]
+	assert.Equal(t, StatusClosed, updated.Status)
+	assert.Equal(t, big.NewInt(100), updated.LiquidationPenaltyUSD)
+}
+
 func TestRecordClaim_AddsInterest(t *testing.T) {
 	svc, repo := newTestService()
 	pos := createTestPosition(repo)
@@ -237,14 +366,117 @@ func TestRecordClaim_AddsInterest(t *testing.T) {
 	assert.Equal(t, big.NewInt(150), updated.InterestEarnedUSD)
 }
 
+func TestRecordInterest_GrowsLegBalances(t *testing.T) {
+	svc, repo := newTestService()
+	pos := createTestPosition(repo)
+	addLeg(pos, SideSupply, eth, big.NewInt(1000))
+	addLeg(pos, SideBorrow, usdc, big.NewInt(400))
+	ctx := context.Background()
+
+	require.NoError(t, svc.RecordInterest(ctx, pos.ID, SideSupply, eth, big.NewInt(10), big.NewInt(20)))
+	require.NoError(t, svc.RecordInterest(ctx, pos.ID, SideBorrow, usdc, big.NewInt(4), big.NewInt(8)))
+
+	updated := repo.positions[pos.ID]
+	supply := updated.Leg(SideSupply, eth)
+	borrow := updated.Leg(SideBorrow, usdc)
+	assert.Equal(t, big.NewInt(1010), supply.Amount)
+	assert.Equal(t, big.NewInt(10), supply.Interest)
+	assert.Equal(t, big.NewInt(20), supply.InterestUSD)
+	assert.Equal(t, big.NewInt(404), borrow.Amount)
+	assert.Equal(t, big.NewInt(4), borrow.Interest)
+	assert.Equal(t, big.NewInt(20), updated.InterestEarnedUSD)
+	assert.Equal(t, big.NewInt(8), updated.InterestPaidUSD)
+	// Interest is not a supply or borrow
+	assert.Equal(t, big.NewInt(1000), supply.TotalIn)
+	assert.Equal(t, big.NewInt(400), borrow.TotalIn)
+}
+
+func TestRecordRisk_ComputesHealthFactorAndLiquidationPrice(t *testing.T) {
+	svc, repo := newTestService()
+	pos := createTestPosition(repo)
+	tenETH, _ := new(big.Int).SetString("10000000000000000000", 10)
+	supply := addLeg(pos, SideSupply, eth, tenETH)
+	borrow := addLeg(pos, SideBorrow, usdc, big.NewInt(8_000_000_000)) // 8000 USDC
+	ctx := context.Background()
+	at := time.Now().UTC()
+
+	prices := map[uuid.UUID]*big.Int{
+		supply.ID: big.NewInt(200_000_000_000),
+		borrow.ID: big.NewInt(100_000_000),
+	}
+	require.NoError(t, svc.RecordRisk(ctx, pos.ID, prices, at))
+
+	updated := repo.positions[pos.ID]
+	assert.Equal(t, big.NewInt(2_000_000_000_000), updated.SupplyValueUSD)
+	assert.Equal(t, big.NewInt(800_000_000_000), updated.BorrowValueUSD)
+	require.NotNil(t, updated.LiquidationThresholdBps)
+	assert.Equal(t, 8300, *updated.LiquidationThresholdBps)
+	require.NotNil(t, updated.LTVBps)
+	assert.Equal(t, 4000, *updated.LTVBps)
+	// 20000 × 0.83 / 8000 = 2.075
+	assert.Equal(t, big.NewInt(20_750), updated.HealthFactor)
+	assert.Equal(t, &at, updated.RiskUpdatedAt)
+
+	// 8000 / (10 × 0.83) = 963.855...
+	assert.Equal(t, big.NewInt(96_385_542_168), supply.LiquidationPriceUSD)
+	assert.Equal(t, big.NewInt(2_000_000_000_000), supply.ValueUSD)
+	assert.Equal(t, big.NewInt(800_000_000_000), borrow.ValueUSD)
+	assert.Nil(t, borrow.LiquidationPriceUSD)
+}
+
+func TestRecordRisk_MultipleCollaterals(t *testing.T) {
+	svc, repo := newTestService()
+	pos := createTestPosition(repo)
+	oneETH := big.NewInt(1e18)
+	tenKDAI, _ := new(big.Int).SetString("10000000000000000000000", 10)
+	ethLeg := addLeg(pos, SideSupply, eth, oneETH)
+	daiLeg := addLeg(pos, SideSupply, dai, tenKDAI)
+	usdcLeg := addLeg(pos, SideBorrow, usdc, big.NewInt(9_000_000_000)) // 9000 USDC
+	ctx := context.Background()
+
+	prices := map[uuid.UUID]*big.Int{
+		ethLeg.ID:  big.NewInt(200_000_000_000),
+		daiLeg.ID:  big.NewInt(100_000_000),
+		usdcLeg.ID: big.NewInt(100_000_000),
+	}
+	require.NoError(t, svc.RecordRisk(ctx, pos.ID, prices, time.Now().UTC()))
+
+	updated := repo.positions[pos.ID]
+	// $2000 × 0.83 + $10000 × 0.77 = $9360 liquidation capacity
+	assert.Equal(t, big.NewInt(1_200_000_000_000), updated.SupplyValueUSD)
+	assert.Equal(t, 7800, *updated.LiquidationThresholdBps)
+	assert.Equal(t, 7500, *updated.LTVBps)
+	assert.Equal(t, big.NewInt(10_400), updated.HealthFactor)
+	// ETH can fall until $2000 × 0.83 - $360 is gone: (9000 - 7700) / 0.83 = $1566.26
+	assert.Equal(t, big.NewInt(156_626_506_024), ethLeg.LiquidationPriceUSD)
+	// DAI: (9000 - 1660) / (10000 × 0.77) = $0.9532
+	assert.Equal(t, big.NewInt(95_324_675), daiLeg.LiquidationPriceUSD)
+}
+
+func TestRecordRisk_NoDebt(t *testing.T) {
+	svc, repo := newTestService()
+	pos := createTestPosition(repo)
+	leg := addLeg(pos, SideSupply, eth, big.NewInt(1e18))
+	ctx := context.Background()
+
+	prices := map[uuid.UUID]*big.Int{leg.ID: big.NewInt(200_000_000_000)}
+	require.NoError(t, svc.RecordRisk(ctx, pos.ID, prices, time.Now().UTC()))
+
+	updated := repo.positions[pos.ID]
+	assert.Equal(t, big.NewInt(200_000_000_000), updated.SupplyValueUSD)
+	require.NotNil(t, updated.LTVBps)
+	assert.Equal(t, 0, *updated.LTVBps)
+	assert.Nil(t, updated.HealthFactor)
+	assert.Nil(t, leg.LiquidationPriceUSD)
+}
+
 func TestFindOrCreate_ReusesExisting(t *testing.T) {
 	svc, repo := newTestService()
 	pos := createTestPosition(repo)
 	ctx := context.Background()
 
 	found, err := svc.FindOrCreate(ctx, pos.UserID, pos.WalletID,
-		pos.Protocol, pos.ChainID, pos.SupplyAsset,
-		pos.SupplyDecimals, pos.SupplyContract,
+		pos.Protocol, pos.ChainID,
 		time.Now(),
 	)
 	require.NoError(t, err)
@@ -256,22 +488,47 @@ func TestFindOrCreate_CreatesNew(t *testing.T) {
 	ctx := context.Background()
 
 	pos, err := svc.FindOrCreate(ctx, uuid.New(), uuid.New(),
-		"Aave V3", "ethereum", "WBTC",
-		8, "0xwbtc",
+		"Aave V3", "ethereum",
 		time.Now(),
 	)
 	require.NoError(t, err)
 	assert.NotNil(t, pos)
 	assert.Equal(t, StatusActive, pos.Status)
-	assert.Equal(t, "WBTC", pos.SupplyAsset)
-	assert.Equal(t, 8, pos.SupplyDecimals)
+	assert.Equal(t, "Aave V3", pos.Protocol)
+	assert.Empty(t, pos.Legs)
 }
 
 func TestGetPosition_NotFound(t *testing.T) {
 	svc, _ := newTestService()
 	ctx := context.Background()
 
-	err := svc.RecordSupply(ctx, uuid.New(), big.NewInt(100), big.NewInt(50))
+	err := svc.RecordSupply(ctx, uuid.New(), eth, big.NewInt(100), big.NewInt(50))
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "position not found")
 }
+
+func TestListAccessible_IncludesSharedWalletPositions(t *testing.T) {
+	repo := newMockRepo()
+	ctx := context.Background()
+	owner, member, outsider := uuid.New(), uuid.New(), uuid.New()
+
+	pos := createTestPosition(repo)
+	pos.UserID = owner
+	svc := NewService(repo, mockWallets{pos.WalletID: {owner, member}}, logger.New("test", io.Discard))
+
+	positions, err := svc.ListAccessible(ctx, member, nil, &pos.WalletID, nil)
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	assert.Equal(t, pos.ID, positions[0].ID)
+
+	got, err := svc.GetAccessible(ctx, member, pos.ID)
+	require.NoError(t, err)
+	assert.Equal(t, pos.ID, got.ID)
+
+	positions, err = svc.ListAccessible(ctx, outsider, nil, &pos.WalletID, nil)
+	require.NoError(t, err)
+	assert.Empty(t, positions)
+
+	_, err = svc.GetAccessible(ctx, outsider, pos.ID)
+	assert.ErrorIs(t, err, ErrPositionNotFound)
+}
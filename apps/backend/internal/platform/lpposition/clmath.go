@@ -0,0 +1,256 @@
+package lpposition
+
+import (
+	"math/big"
+	"strings"
+)
+
+// Uniswap V3 tick bounds (TickMath.MIN_TICK / MAX_TICK)
+const (
+	MinTick = -887272
+	MaxTick = 887272
+)
+
+// pricePrec is the big.Float precision used for human-readable price conversions
+const pricePrec = 256
+
+var (
+	q96        = new(big.Int).Lsh(big.NewInt(1), 96)
+	maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	// tickRatios are the Q128 multipliers from TickMath.getSqrtRatioAtTick,
+	// one per bit of |tick| starting at 0x1
+	tickRatios = []*big.Int{
+		hexInt("fffcb933bd6fad37aa2d162d1a594001"),
+		hexInt("fff97272373d413259a46990580e213a"),
+		hexInt("fff2e50f5f656932ef12357cf3c7fdcc"),
+		hexInt("ffe5caca7e10e4e61c3624eaa0941cd0"),
+		hexInt("ffcb9843d60f6159c9db58835c926644"),
+		hexInt("ff973b41fa98c081472e6896dfb254c0"),
+		hexInt("ff2ea16466c96a3843ec78b326b52861"),
+		hexInt("fe5dee046a99a2a811c461f1969c3053"),
+		hexInt("fcbe86c7900a88aedcffc83b479aa3a4"),
+		hexInt("f987a7253ac413176f2b074cf7815e54"),
+		hexInt("f3392b0822b70005940c7a398e4b70f3"),
+		hexInt("e7159475a2c29b7443b29c7fa6e889d9"),
+		hexInt("d097f3bdfd2022b8845ad8f792aa5825"),
+		hexInt("a9f746462d870fdf8a65dc1f90e061e5"),
+		hexInt("70d869a156d2a1b890bb3df62baf32f7"),
+		hexInt("31be135f97d08fd981231505542fcfa6"),
+		hexInt("9aa508b5b7a84e1c677de54f3e99bc9"),
+		hexInt("5d6af8dedb81196699c329225ee604"),
+		hexInt("2216e584f5fa1ea926041bedfe98"),
+		hexInt("48a170391f7dc42444e8fa2"),
+	}
+)
+
+func hexInt(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("lpposition: invalid hex constant " + s)
+	}
+	return v
+}
+
+// SqrtRatioAtTick returns sqrt(1.0001^tick) as a Q64.96 fixed-point number,
+// bit-for-bit identical to TickMath.getSqrtRatioAtTick
+func SqrtRatioAtTick(tick int) (*big.Int, error) {
+	if tick < MinTick || tick > MaxTick {
+		return nil, ErrTickOutOfRange
+	}
+
+	absTick := tick
+	if absTick < 0 {
+		absTick = -absTick
+	}
+
+	ratio := new(big.Int).Lsh(big.NewInt(1), 128)
+	if absTick&1 != 0 {
+		ratio.Set(tickRatios[0])
+	}
+	for bit := 1; bit < len(tickRatios); bit++ {
+		if absTick&(1<<bit) != 0 {
+			ratio.Mul(ratio, tickRatios[bit])
+			ratio.Rsh(ratio, 128)
+		}
+	}
+	if tick > 0 {
+		ratio.Div(maxUint256, ratio)
+	}
+
+	// Round up when shifting from Q128.128 to Q64.96
+	sqrtPrice := new(big.Int).Rsh(ratio, 32)
+	if new(big.Int).And(ratio, big.NewInt(0xffffffff)).Sign() != 0 {
+		sqrtPrice.Add(sqrtPrice, big.NewInt(1))
+	}
+	return sqrtPrice, nil
+}
+
+// TickAtSqrtRatio returns the greatest tick whose sqrt ratio is <= sqrtPriceX96
+func TickAtSqrtRatio(sqrtPriceX96 *big.Int) (int, error) {
+	minRatio, _ := SqrtRatioAtTick(MinTick)
+	maxRatio, _ := SqrtRatioAtTick(MaxTick)
+	if sqrtPriceX96.Cmp(minRatio) < 0 || sqrtPriceX96.Cmp(maxRatio) >= 0 {
+		return 0, ErrPriceOutOfRange
+	}
+
+	lo, hi := MinTick, MaxTick
+	for lo < hi {
+		mid := lo + (hi-lo+1)/2
+		ratio, _ := SqrtRatioAtTick(mid)
+		if ratio.Cmp(sqrtPriceX96) <= 0 {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo, nil
+}
+
+// AmountsForLiquidity returns the token0/token1 base-unit amounts held by
+// liquidity in [sqrtA, sqrtB] at the current sqrt price, matching
+// LiquidityAmounts.getAmountsForLiquidity. Below the range everything is
+// token0, above it everything is token1.
+func AmountsForLiquidity(sqrtPriceX96, sqrtA, sqrtB, liquidity *big.Int) (amount0, amount1 *big.Int) {
+	sqrtA, sqrtB = orderedSqrt(sqrtA, sqrtB)
+
+	switch {
+	case sqrtPriceX96.Cmp(sqrtA) <= 0:
+		return amount0ForLiquidity(sqrtA, sqrtB, liquidity), big.NewInt(0)
+	case sqrtPriceX96.Cmp(sqrtB) < 0:
+		return amount0ForLiquidity(sqrtPriceX96, sqrtB, liquidity), amount1ForLiquidity(sqrtA, sqrtPriceX96, liquidity)
+	default:
+		return big.NewInt(0), amount1ForLiquidity(sqrtA, sqrtB, liquidity)
+	}
+}
+
+// LiquidityForAmounts returns the largest liquidity that amount0/amount1 can
+// fund in [sqrtA, sqrtB] at the current sqrt price, matching
+// LiquidityAmounts.getLiquidityForAmounts
+func LiquidityForAmounts(sqrtPriceX96, sqrtA, sqrtB, amount0, amount1 *big.Int) *big.Int {
+	sqrtA, sqrtB = orderedSqrt(sqrtA, sqrtB)
+
+	switch {
+	case sqrtPriceX96.Cmp(sqrtA) <= 0:
+		return liquidityForAmount0(sqrtA, sqrtB, amount0)
+	case sqrtPriceX96.Cmp(sqrtB) < 0:
+		l0 := liquidityForAmount0(sqrtPriceX96, sqrtB, amount0)
+		l1 := liquidityForAmount1(sqrtA, sqrtPriceX96, amount1)
+		if l0.Cmp(l1) < 0 {
+			return l0
+		}
+		return l1
+	default:
+		return liquidityForAmount1(sqrtA, sqrtB, amount1)
+	}
+}
+
+func orderedSqrt(a, b *big.Int) (*big.Int, *big.Int) {
+	if a.Cmp(b) > 0 {
+		return b, a
+	}
+	return a, b
+}
+
+// amount0 = L * 2^96 * (sqrtB - sqrtA) / sqrtB / sqrtA
+func amount0ForLiquidity(sqrtA, sqrtB, liquidity *big.Int) *big.Int {
+	num := new(big.Int).Lsh(liquidity, 96)
+	num.Mul(num, new(big.Int).Sub(sqrtB, sqrtA))
+	num.Div(num, sqrtB)
+	return num.Div(num, sqrtA)
+}
+
+// amount1 = L * (sqrtB - sqrtA) / 2^96
+func amount1ForLiquidity(sqrtA, sqrtB, liquidity *big.Int) *big.Int {
+	num := new(big.Int).Mul(liquidity, new(big.Int).Sub(sqrtB, sqrtA))
+	return num.Rsh(num, 96)
+}
+
+// L = amount0 * (sqrtA * sqrtB / 2^96) / (sqrtB - sqrtA)
+func liquidityForAmount0(sqrtA, sqrtB, amount0 *big.Int) *big.Int {
+	intermediate := new(big.Int).Mul(sqrtA, sqrtB)
+	intermediate.Rsh(intermediate, 96)
+	num := new(big.Int).Mul(amount0, intermediate)
+	return num.Div(num, new(big.Int).Sub(sqrtB, sqrtA))
+}
+
+// L = amount1 * 2^96 / (sqrtB - sqrtA)
+func liquidityForAmount1(sqrtA, sqrtB, amount1 *big.Int) *big.Int {
+	num := new(big.Int).Lsh(amount1, 96)
+	return num.Div(num, new(big.Int).Sub(sqrtB, sqrtA))
+}
+
+// PriceToSqrtPriceX96 converts a human price (token1 per token0) into a Q64.96
+// sqrt price over base units
+func PriceToSqrtPriceX96(price *big.Float, token0Decimals, token1Decimals int) (*big.Int, error) {
+	if price == nil || price.Sign() <= 0 {
+		return nil, ErrInvalidPrice
+	}
+
+	raw := new(big.Float).SetPrec(pricePrec).Set(price)
+	raw.Mul(raw, pow10Float(token1Decimals))
+	raw.Quo(raw, pow10Float(token0Decimals))
+	raw.Sqrt(raw)
+	raw.Mul(raw, new(big.Float).SetPrec(pricePrec).SetInt(q96))
+
+	sqrtPrice, _ := raw.Int(nil)
+	if sqrtPrice.Sign() <= 0 {
+		return nil, ErrPriceOutOfRange
+	}
+	return sqrtPrice, nil
+}
+
+// SqrtPriceX96ToPrice converts a Q64.96 sqrt price back into a human price
+// (token1 per token0)
+func SqrtPriceX96ToPrice(sqrtPriceX96 *big.Int, token0Decimals, token1Decimals int) *big.Float {
+	p := new(big.Float).SetPrec(pricePrec).SetInt(sqrtPriceX96)
+	p.Quo(p, new(big.Float).SetPrec(pricePrec).SetInt(q96))
+	p.Mul(p, p)
+	p.Mul(p, pow10Float(token0Decimals))
+	return p.Quo(p, pow10Float(token1Decimals))
+}
+
+// TickToPrice returns the human price (token1 per token0) at a tick
+func TickToPrice(tick, token0Decimals, token1Decimals int) (*big.Float, error) {
+	sqrtPrice, err := SqrtRatioAtTick(tick)
+	if err != nil {
+		return nil, err
+	}
+	return SqrtPriceX96ToPrice(sqrtPrice, token0Decimals, token1Decimals), nil
+}
+
+// PriceToTick returns the greatest tick at or below a human price
+func PriceToTick(price *big.Float, token0Decimals, token1Decimals int) (int, error) {
+	sqrtPrice, err := PriceToSqrtPriceX96(price, token0Decimals, token1Decimals)
+	if err != nil {
+		return 0, err
+	}
+	return TickAtSqrtRatio(sqrtPrice)
+}
+
+// ParsePrice parses a decimal price string into a big.Float at price precision
+func ParsePrice(s string) (*big.Float, error) {
+	p, ok := new(big.Float).SetPrec(pricePrec).SetString(strings.TrimSpace(s))
+	if !ok || p.Sign() <= 0 || p.IsInf() {
+		return nil, ErrInvalidPrice
+	}
+	return p, nil
+}
+
+// FormatPrice renders a price as a plain decimal string with trailing zeros trimmed
+func FormatPrice(p *big.Float) string {
+	if p == nil {
+		return "0"
+	}
+	s := p.Text('f', 18)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	return s
+}
+
+func pow10Float(decimals int) *big.Float {
+	v := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return new(big.Float).SetPrec(pricePrec).SetInt(v)
+}
@@ -0,0 +1,137 @@
+package lpposition
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqrtRatioAtTick_KnownValues(t *testing.T) {
+	tests := []struct {
+		tick int
+		want string
+	}{
+		{0, "79228162514264337593543950336"},
+		{MinTick, "4295128739"},
+		{MaxTick, "1461446703485210103287273052203988822378723970342"},
+	}
+
+	for _, tt := range tests {
+		got, err := SqrtRatioAtTick(tt.tick)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got.String(), "tick %d", tt.tick)
+	}
+
+	_, err := SqrtRatioAtTick(MaxTick + 1)
+	assert.ErrorIs(t, err, ErrTickOutOfRange)
+}
+
+// TestSqrtRatioAtTick_MatchesFloat checks every bit multiplier against
+// sqrt(1.0001^tick) computed at high precision
+func TestSqrtRatioAtTick_MatchesFloat(t *testing.T) {
+	base := new(big.Float).SetPrec(512)
+	base.SetString("1.0001")
+
+	for bit := 0; bit < 20; bit++ {
+		for _, sign := range []int{1, -1} {
+			tick := sign * (1 << bit)
+
+			expected := new(big.Float).SetPrec(512).SetInt64(1)
+			pow := new(big.Float).SetPrec(512).Set(base)
+			for n := 1 << bit; n > 0; n >>= 1 {
+				if n&1 != 0 {
+					expected.Mul(expected, pow)
+				}
+				pow.Mul(pow, pow)
+			}
+			if sign < 0 {
+				expected.Quo(new(big.Float).SetPrec(512).SetInt64(1), expected)
+			}
+			expected.Sqrt(expected)
+			expected.Mul(expected, new(big.Float).SetPrec(512).SetInt(q96))
+
+			got, err := SqrtRatioAtTick(tick)
+			require.NoError(t, err)
+
+			// Allow a couple of wei of rounding on top of 1e-18 relative error
+			diff := new(big.Float).SetPrec(512).SetInt(got)
+			diff.Sub(diff, expected)
+			diff.Abs(diff)
+			tolerance := new(big.Float).SetPrec(512).Mul(expected, big.NewFloat(1e-18))
+			tolerance.Add(tolerance, big.NewFloat(2))
+			assert.True(t, diff.Cmp(tolerance) <= 0, "tick %d off by %s", tick, diff.Text('g', 5))
+		}
+	}
+}
+
+func TestTickAtSqrtRatio_RoundTrips(t *testing.T) {
+	for _, tick := range []int{MinTick, -200000, -1, 0, 1, 202919, MaxTick - 1} {
+		sqrtPrice, err := SqrtRatioAtTick(tick)
+		require.NoError(t, err)
+
+		got, err := TickAtSqrtRatio(sqrtPrice)
+		require.NoError(t, err)
+		assert.Equal(t, tick, got)
+
+		// One below the tick's ratio belongs to the previous tick
+		if tick > MinTick {
+			got, err = TickAtSqrtRatio(new(big.Int).Sub(sqrtPrice, big.NewInt(1)))
+			require.NoError(t, err)
+			assert.Equal(t, tick-1, got)
+		}
+	}
+}
+
+func TestPriceToTick_AccountsForDecimals(t *testing.T) {
+	// WETH (18) / USDC (6) at $2000: raw price 2000e-12 lands near tick -200311
+	price, err := ParsePrice("2000")
+	require.NoError(t, err)
+
+	tick, err := PriceToTick(price, 18, 6)
+	require.NoError(t, err)
+	assert.Equal(t, -200312, tick)
+
+	back, err := TickToPrice(tick, 18, 6)
+	require.NoError(t, err)
+	f, _ := back.Float64()
+	assert.InDelta(t, 2000, f, 0.2)
+}
+
+func TestAmountsForLiquidity_RoundTripsLiquidity(t *testing.T) {
+	sqrtA, _ := SqrtRatioAtTick(-1000)
+	sqrtB, _ := SqrtRatioAtTick(1000)
+	sqrtP, _ := SqrtRatioAtTick(0)
+
+	amount0 := big.NewInt(1_000_000_000_000_000_000)
+	amount1 := big.NewInt(1_000_000_000_000_000_000)
+	liquidity := LiquidityForAmounts(sqrtP, sqrtA, sqrtB, amount0, amount1)
+	require.Positive(t, liquidity.Sign())
+
+	got0, got1 := AmountsForLiquidity(sqrtP, sqrtA, sqrtB, liquidity)
+	assert.True(t, got0.Cmp(amount0) <= 0)
+	assert.True(t, got1.Cmp(amount1) <= 0)
+	assert.InDelta(t, 1e18, float64(got0.Int64()), 1e6)
+	assert.InDelta(t, 1e18, float64(got1.Int64()), 1e6)
+
+	// Out of range the position is single-sided
+	below, _ := SqrtRatioAtTick(-2000)
+	got0, got1 = AmountsForLiquidity(below, sqrtA, sqrtB, liquidity)
+	assert.Positive(t, got0.Sign())
+	assert.Zero(t, got1.Sign())
+
+	above, _ := SqrtRatioAtTick(2000)
+	got0, got1 = AmountsForLiquidity(above, sqrtA, sqrtB, liquidity)
+	assert.Zero(t, got0.Sign())
+	assert.Positive(t, got1.Sign())
+}
+
+func TestFormatPrice(t *testing.T) {
+	p, err := ParsePrice("1234.5000")
+	require.NoError(t, err)
+	assert.Equal(t, "1234.5", FormatPrice(p))
+
+	_, err = ParsePrice("-1")
+	assert.ErrorIs(t, err, ErrInvalidPrice)
+}
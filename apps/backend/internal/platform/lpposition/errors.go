@@ -0,0 +1,17 @@
+package lpposition
+
+import "errors"
+
+var (
+	ErrPositionNotFound = errors.New("LP position not found")
+	ErrRangeRequired    = errors.New("position range is unknown: provide tick_lower/tick_upper or price_lower/price_upper")
+	ErrInvalidRange     = errors.New("lower bound must be below upper bound")
+	ErrTickOutOfRange   = errors.New("tick out of range")
+	ErrPriceOutOfRange  = errors.New("price out of range")
+	ErrInvalidPrice     = errors.New("price must be a positive decimal")
+	ErrPriceUnavailable = errors.New("current price unavailable: provide current_price")
+	ErrNoLiquidity      = errors.New("position has no liquidity to simulate")
+	ErrInvalidGrid      = errors.New("invalid price grid")
+	ErrTooManyPrices    = errors.New("too many prices to simulate")
+	ErrMissingPrices    = errors.New("token prices unavailable for valuation")
+)
@@ -48,10 +48,49 @@ type LPPosition struct {
 	RealizedPnLUSD *big.Int
 	APRBps         *int // basis points, nil if not calculated
 
+	// Capital still deployed in USD, and its running integral over time in
+	// USD·seconds up to CapitalUpdatedAt; the basis for time-weighted APR
+	CapitalUSD        *big.Int
+	CapitalUSDSeconds *big.Int
+	CapitalUpdatedAt  *time.Time
+
+	// Live metrics for open positions, refreshed on sync; nil until valued
+	CurrentValueUSD  *big.Int
+	UnrealizedPnLUSD *big.Int
+	FeeAPRBps        *int
+	TotalAPRBps      *int
+	ValuedAt         *time.Time
+
+	// Concentrated-liquidity state read on-chain, refreshed on sync; nil
+	// until read (and always for fungible LP tokens)
+	TickLower             *int
+	TickUpper             *int
+	CurrentTick           *int
+	InRange               *bool
+	UncollectedFeesToken0 *big.Int
+	UncollectedFeesToken1 *big.Int
+	RangeUpdatedAt        *time.Time
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
+// RangeState is an on-chain snapshot of a concentrated-liquidity position:
+// its tick range, the pool's current tick, and fees earned but not collected.
+type RangeState struct {
+	TickLower         int
+	TickUpper         int
+	CurrentTick       int
+	UncollectedToken0 *big.Int
+	UncollectedToken1 *big.Int
+}
+
+// InRange reports whether the pool price is inside the position's range.
+// Matches Uniswap V3, where liquidity is active for tickLower <= tick < tickUpper.
+func (r RangeState) InRange() bool {
+	return r.CurrentTick >= r.TickLower && r.CurrentTick < r.TickUpper
+}
+
 // RemainingToken0 returns deposited - withdrawn for token0.
 // May be negative due to impermanent loss.
 func (p *LPPosition) RemainingToken0() *big.Int {
@@ -79,3 +118,39 @@ func (p *LPPosition) IsFullyWithdrawn() bool {
 	net := new(big.Int).Add(remaining0, remaining1)
 	return net.Sign() <= 0
 }
+
+// TimeWeightedCapital returns the USD·seconds of capital deployed from the
+// first deposit up to at.
+func (p *LPPosition) TimeWeightedCapital(at time.Time) *big.Int {
+	twc := new(big.Int)
+	if p.CapitalUSDSeconds != nil {
+		twc.Set(p.CapitalUSDSeconds)
+	}
+	if p.CapitalUSD != nil && p.CapitalUpdatedAt != nil && at.After(*p.CapitalUpdatedAt) {
+		elapsed := big.NewInt(int64(at.Sub(*p.CapitalUpdatedAt).Seconds()))
+		twc.Add(twc, elapsed.Mul(elapsed, p.CapitalUSD))
+	}
+	return twc
+}
+
+// adjustCapital accrues capital up to at, then moves it by delta (clamped at
+// zero). Callers must apply events in chronological order, as sync does by
+// processing raw transactions by mined_at: the integral is only extended
+// forward, so an event older than the last adjustment changes capital from
+// that point on rather than from its own time.
+func (p *LPPosition) adjustCapital(delta *big.Int, at time.Time) {
+	p.CapitalUSDSeconds = p.TimeWeightedCapital(at)
+	if p.CapitalUpdatedAt == nil || at.After(*p.CapitalUpdatedAt) {
+		p.CapitalUpdatedAt = &at
+	}
+
+	capital := new(big.Int)
+	if p.CapitalUSD != nil {
+		capital.Set(p.CapitalUSD)
+	}
+	capital.Add(capital, delta)
+	if capital.Sign() < 0 {
+		capital.SetInt64(0)
+	}
+	p.CapitalUSD = capital
+}
@@ -0,0 +1,231 @@
+package lpposition
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// PriceHistoryService supplies current and daily historical USD prices
+// (scaled by 10^8); zero means unknown
+type PriceHistoryService interface {
+	PriceService
+	GetPriceBySymbolAt(ctx context.Context, symbol string, at time.Time) (*big.Int, error)
+}
+
+// Performance compares an LP position against simply holding its deposits.
+// All token amounts are valued at the same prices, so the difference between
+// LP and HODL is impermanent loss rather than market movement.
+type Performance struct {
+	ValuedAt    time.Time
+	Token0Price *big.Int
+	Token1Price *big.Int
+
+	HODLValueUSD *big.Int
+	LPValueUSD   *big.Int
+	// Estimated is set when LP value includes tokens still in the pool,
+	// whose composition is modelled rather than observed
+	Estimated bool
+
+	// ImpermanentLossUSD is LP − HODL; negative means the LP underperformed
+	ImpermanentLossUSD *big.Int
+	ImpermanentLossBps int
+
+	FeesUSD *big.Int
+	// NetUSD is fee income net of impermanent loss
+	NetUSD *big.Int
+}
+
+// CalculatePerformance values a position's aggregates at token0/token1 USD
+// prices. For open positions the tokens still in the pool are rebalanced to
+// the current price ratio (see EstimateHoldings).
+func CalculatePerformance(pos *LPPosition, price0, price1 *big.Int, at time.Time) *Performance {
+	value := func(amount0, amount1 *big.Int) *big.Int {
+		v := money.CalcUSDValue(amount0, price0, pos.Token0Decimals)
+		return v.Add(v, money.CalcUSDValue(amount1, price1, pos.Token1Decimals))
+	}
+
+	perf := &Performance{
+		ValuedAt:     at,
+		Token0Price:  price0,
+		Token1Price:  price1,
+		HODLValueUSD: value(pos.TotalDepositedToken0, pos.TotalDepositedToken1),
+		LPValueUSD:   value(pos.TotalWithdrawnToken0, pos.TotalWithdrawnToken1),
+		FeesUSD:      value(pos.TotalClaimedToken0, pos.TotalClaimedToken1),
+	}
+
+	if pos.Status == StatusOpen {
+		held0, held1 := EstimateHoldings(pos, price0, price1)
+		perf.LPValueUSD.Add(perf.LPValueUSD, value(held0, held1))
+		perf.Estimated = held0.Sign() > 0 || held1.Sign() > 0
+	}
+
+	perf.ImpermanentLossUSD = new(big.Int).Sub(perf.LPValueUSD, perf.HODLValueUSD)
+	if perf.HODLValueUSD.Sign() > 0 {
+		bps := new(big.Int).Mul(perf.ImpermanentLossUSD, big.NewInt(10000))
+		perf.ImpermanentLossBps = int(bps.Quo(bps, perf.HODLValueUSD).Int64())
+	}
+	perf.NetUSD = new(big.Int).Add(perf.ImpermanentLossUSD, perf.FeesUSD)
+
+	return perf
+}
+
+// EstimateHoldings returns the tokens still in the pool at current USD prices.
+// When the position's tick range is known, the remainders fix its liquidity
+// in that range and AmountsForLiquidity gives the composition at the market
+// price. Otherwise two-sided remainders keep their product constant (k = x·y)
+// as on a full-range curve, and one-sided remainders are returned unchanged.
+func EstimateHoldings(pos *LPPosition, price0, price1 *big.Int) (*big.Int, *big.Int) {
+	held0 := nonNegative(pos.RemainingToken0())
+	held1 := nonNegative(pos.RemainingToken1())
+	if price0.Sign() == 0 || price1.Sign() == 0 {
+		return held0, held1
+	}
+	if amount0, amount1, ok := rangeHoldings(pos, held0, held1, price0, price1); ok {
+		return amount0, amount1
+	}
+	if held0.Sign() == 0 || held1.Sign() == 0 {
+		return held0, held1
+	}
+
+	// Raw price of one base unit of token0 in base units of token1
+	rawPrice := new(big.Float).SetPrec(pricePrec).SetInt(price0)
+	rawPrice.Quo(rawPrice, new(big.Float).SetPrec(pricePrec).SetInt(price1))
+	rawPrice.Mul(rawPrice, pow10Float(pos.Token1Decimals))
+	rawPrice.Quo(rawPrice, pow10Float(pos.Token0Decimals))
+	sqrtPrice := rawPrice.Sqrt(rawPrice)
+
+	k := new(big.Float).SetPrec(pricePrec).SetInt(new(big.Int).Mul(held0, held1))
+	l := k.Sqrt(k)
+
+	amount0, _ := new(big.Float).SetPrec(pricePrec).Quo(l, sqrtPrice).Int(nil)
+	amount1, _ := new(big.Float).SetPrec(pricePrec).Mul(l, sqrtPrice).Int(nil)
+	return amount0, amount1
+}
+
+// rangeHoldings re-prices the remainders within the position's stored tick
+// range. ok is false when the range or a price is unusable.
+func rangeHoldings(pos *LPPosition, held0, held1, price0, price1 *big.Int) (amount0, amount1 *big.Int, ok bool) {
+	if pos.TickLower == nil || pos.TickUpper == nil {
+		return nil, nil, false
+	}
+	rng := PriceRange{TickLower: *pos.TickLower, TickUpper: *pos.TickUpper}
+	if rng.Validate() != nil {
+		return nil, nil, false
+	}
+
+	price := new(big.Float).SetPrec(pricePrec).SetInt(price0)
+	price.Quo(price, new(big.Float).SetPrec(pricePrec).SetInt(price1))
+	sqrtP, err := PriceToSqrtPriceX96(price, pos.Token0Decimals, pos.Token1Decimals)
+	if err != nil {
+		return nil, nil, false
+	}
+	sqrtA, _ := SqrtRatioAtTick(rng.TickLower)
+	sqrtB, _ := SqrtRatioAtTick(rng.TickUpper)
+
+	liquidity := liquidityForHoldings(sqrtA, sqrtB, held0, held1)
+	if liquidity.Sign() <= 0 {
+		return nil, nil, false
+	}
+	amount0, amount1 = AmountsForLiquidity(sqrtP, sqrtA, sqrtB, liquidity)
+	return amount0, amount1, true
+}
+
+// liquidityForHoldings returns the liquidity in [sqrtA, sqrtB] that holds
+// exactly amount0 and amount1 at some price within the range, without
+// knowing that price. With real sqrt prices a < b it is the positive root of
+//
+//	(a/b − 1)·L² + (amount0·a + amount1/b)·L + amount0·amount1 = 0
+//
+// which reduces to L = √(x·y) over the full range.
+func liquidityForHoldings(sqrtA, sqrtB, amount0, amount1 *big.Int) *big.Int {
+	float := func(v *big.Int) *big.Float { return new(big.Float).SetPrec(pricePrec).SetInt(v) }
+
+	a := float(sqrtA)
+	a.Quo(a, float(q96))
+	b := float(sqrtB)
+	b.Quo(b, float(q96))
+	x, y := float(amount0), float(amount1)
+
+	// |a/b − 1|, the quadratic coefficient is negative since a < b
+	quad := new(big.Float).SetPrec(pricePrec).Quo(a, b)
+	quad.Sub(big.NewFloat(1), quad)
+
+	lin := new(big.Float).SetPrec(pricePrec).Mul(x, a)
+	lin.Add(lin, new(big.Float).SetPrec(pricePrec).Quo(y, b))
+
+	disc := new(big.Float).SetPrec(pricePrec).Mul(lin, lin)
+	xy := new(big.Float).SetPrec(pricePrec).Mul(x, y)
+	xy.Mul(xy, quad)
+	disc.Add(disc, xy.Mul(xy, big.NewFloat(4)))
+
+	l := new(big.Float).SetPrec(pricePrec).Sqrt(disc)
+	l.Add(l, lin)
+	l.Quo(l, quad.Mul(quad, big.NewFloat(2)))
+	liquidity, _ := l.Int(nil)
+	return liquidity
+}
+
+// PerformanceService values LP positions against a HODL baseline
+type PerformanceService struct {
+	repo    Repository
+	wallets wallet.Reader
+	prices  PriceHistoryService
+	logger  *logger.Logger
+}
+
+// NewPerformanceService creates a new LP performance service
+func NewPerformanceService(repo Repository, wallets wallet.Reader, prices PriceHistoryService, log *logger.Logger) *PerformanceService {
+	return &PerformanceService{
+		repo:    repo,
+		wallets: wallets,
+		prices:  prices,
+		logger:  log.WithField("component", "lp_performance"),
+	}
+}
+
+// GetPerformance compares one of the user's positions with holding its
+// deposits: open positions at current prices, closed positions at prices on
+// the close date
+func (s *PerformanceService) GetPerformance(ctx context.Context, userID, positionID uuid.UUID) (*Performance, error) {
+	pos, err := getAccessible(ctx, s.repo, s.wallets, userID, positionID)
+	if err != nil {
+		return nil, err
+	}
+
+	at := time.Now().UTC()
+	if pos.Status == StatusClosed && pos.ClosedAt != nil {
+		at = *pos.ClosedAt
+	}
+
+	price0 := s.priceAt(ctx, pos, pos.Token0Symbol, at)
+	price1 := s.priceAt(ctx, pos, pos.Token1Symbol, at)
+	if price0.Sign() == 0 || price1.Sign() == 0 {
+		return nil, ErrMissingPrices
+	}
+
+	return CalculatePerformance(pos, price0, price1, at), nil
+}
+
+func (s *PerformanceService) priceAt(ctx context.Context, pos *LPPosition, symbol string, at time.Time) *big.Int {
+	var (
+		price *big.Int
+		err   error
+	)
+	if pos.Status == StatusClosed {
+		price, err = s.prices.GetPriceBySymbolAt(ctx, symbol, at)
+	} else {
+		price, err = s.prices.GetPriceBySymbol(ctx, symbol)
+	}
+	if err != nil || price == nil {
+		s.logger.Debug("no USD price for LP token", "symbol", symbol, "position_id", pos.ID, "error", err)
+		return big.NewInt(0)
+	}
+	return price
+}
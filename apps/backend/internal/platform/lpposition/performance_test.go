@@ -0,0 +1,182 @@
+package lpposition
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// mockPriceHistory serves current prices from mockPrices and records
+// historical lookups
+type mockPriceHistory struct {
+	mockPrices
+	historical map[string]*big.Int
+	askedAt    []time.Time
+}
+
+func (m *mockPriceHistory) GetPriceBySymbolAt(_ context.Context, symbol string, at time.Time) (*big.Int, error) {
+	m.askedAt = append(m.askedAt, at)
+	if p, ok := m.historical[symbol]; ok {
+		return p, nil
+	}
+	return big.NewInt(0), nil
+}
+
+func usd(v int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(v), big.NewInt(100_000_000))
+}
+
+func weth(milli int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(milli), big.NewInt(1_000_000_000_000_000))
+}
+
+func usdc(v int64) *big.Int {
+	return big.NewInt(v * 1_000_000)
+}
+
+func TestCalculatePerformance_ClosedPosition(t *testing.T) {
+	pos := ethUSDCPosition(uuid.New())
+	pos.Status = StatusClosed
+	pos.TotalWithdrawnToken0 = weth(900)
+	pos.TotalWithdrawnToken1 = usdc(2200)
+	pos.TotalClaimedToken0 = weth(10)
+	pos.TotalClaimedToken1 = usdc(20)
+
+	perf := CalculatePerformance(pos, usd(2500), usd(1), time.Now())
+
+	assert.Equal(t, usd(4500), perf.HODLValueUSD)
+	assert.Equal(t, usd(4450), perf.LPValueUSD)
+	assert.Equal(t, usd(-50), perf.ImpermanentLossUSD)
+	assert.Equal(t, -111, perf.ImpermanentLossBps)
+	assert.Equal(t, usd(45), perf.FeesUSD)
+	assert.Equal(t, usd(-5), perf.NetUSD)
+	assert.False(t, perf.Estimated)
+}
+
+func TestCalculatePerformance_OpenPositionRebalancesRemainder(t *testing.T) {
+	// Deposited 1 WETH + 2000 USDC at $2000; WETH is now 4x at $8000.
+	// A full-range pool loses 20% against HODL on a 4x move.
+	pos := ethUSDCPosition(uuid.New())
+
+	perf := CalculatePerformance(pos, usd(8000), usd(1), time.Now())
+
+	assert.True(t, perf.Estimated)
+	assert.Equal(t, usd(10000), perf.HODLValueUSD)
+
+	lp, _ := new(big.Float).Quo(new(big.Float).SetInt(perf.LPValueUSD), big.NewFloat(1e8)).Float64()
+	assert.InDelta(t, 8000, lp, 0.01)
+	assert.InDelta(t, -2000, perf.ImpermanentLossBps, 1)
+	assert.Zero(t, perf.FeesUSD.Sign())
+}
+
+func TestCalculatePerformance_NoPriceMoveHasNoLoss(t *testing.T) {
+	pos := ethUSDCPosition(uuid.New())
+
+	perf := CalculatePerformance(pos, usd(2000), usd(1), time.Now())
+
+	loss, _ := new(big.Float).Quo(new(big.Float).SetInt(perf.ImpermanentLossUSD), big.NewFloat(1e8)).Float64()
+	assert.InDelta(t, 0, loss, 0.01)
+	assert.Equal(t, 0, perf.ImpermanentLossBps)
+}
+
+func TestEstimateHoldings_UsesConcentratedRange(t *testing.T) {
+	// A position in [1500, 2500] whose remainders are exactly what its
+	// liquidity holds at $2000
+	lower, _ := PriceToTick(mustPrice(t, "1500"), 18, 6)
+	upper, _ := PriceToTick(mustPrice(t, "2500"), 18, 6)
+	sqrtA, _ := SqrtRatioAtTick(lower)
+	sqrtB, _ := SqrtRatioAtTick(upper)
+	sqrtP, err := PriceToSqrtPriceX96(mustPrice(t, "2000"), 18, 6)
+	require.NoError(t, err)
+	liquidity, _ := new(big.Int).SetString("1000000000000000", 10)
+	deposited0, deposited1 := AmountsForLiquidity(sqrtP, sqrtA, sqrtB, liquidity)
+
+	pos := ethUSDCPosition(uuid.New())
+	pos.TickLower, pos.TickUpper = &lower, &upper
+	pos.TotalDepositedToken0, pos.TotalDepositedToken1 = deposited0, deposited1
+
+	// Unchanged price keeps the composition
+	held0, held1 := EstimateHoldings(pos, usd(2000), usd(1))
+	assertClose(t, deposited0, held0)
+	assertClose(t, deposited1, held1)
+
+	// Above the range everything has been sold into token1
+	held0, held1 = EstimateHoldings(pos, usd(3000), usd(1))
+	assert.Zero(t, held0.Sign())
+	assertClose(t, amount1ForLiquidity(sqrtA, sqrtB, liquidity), held1)
+
+	// Below the range everything has been bought back into token0
+	held0, held1 = EstimateHoldings(pos, usd(1000), usd(1))
+	assertClose(t, amount0ForLiquidity(sqrtA, sqrtB, liquidity), held0)
+	assert.Zero(t, held1.Sign())
+}
+
+func TestEstimateHoldings_WithoutRangeKeepsConstantProduct(t *testing.T) {
+	pos := ethUSDCPosition(uuid.New())
+
+	// 4x price move: x·y = k gives 0.5 WETH + 4000 USDC
+	held0, held1 := EstimateHoldings(pos, usd(8000), usd(1))
+	assertClose(t, weth(500), held0)
+	assertClose(t, usdc(4000), held1)
+}
+
+// assertClose checks two base-unit amounts agree to within 1e-9 relative
+func assertClose(t *testing.T, want, got *big.Int) {
+	t.Helper()
+	diff := new(big.Int).Sub(want, got)
+	tolerance := new(big.Int).Quo(want, big.NewInt(1_000_000_000))
+	assert.True(t, diff.CmpAbs(tolerance) <= 0, "want %s, got %s", want, got)
+}
+
+func TestPerformanceService_ClosedPositionUsesCloseDatePrices(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepo()
+	userID := uuid.New()
+
+	closedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	pos := ethUSDCPosition(userID)
+	pos.Status = StatusClosed
+	pos.ClosedAt = &closedAt
+	pos.TotalWithdrawnToken0 = weth(1000)
+	pos.TotalWithdrawnToken1 = usdc(2000)
+	require.NoError(t, repo.Create(ctx, pos))
+
+	prices := &mockPriceHistory{
+		mockPrices: mockPrices{"WETH": usd(9999), "USDC": usd(1)},
+		historical: map[string]*big.Int{"WETH": usd(3000), "USDC": usd(1)},
+	}
+	svc := NewPerformanceService(repo, mockWallets{pos.WalletID: {userID}}, prices, logger.New("development", io.Discard))
+
+	perf, err := svc.GetPerformance(ctx, userID, pos.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, closedAt, perf.ValuedAt)
+	assert.Equal(t, []time.Time{closedAt, closedAt}, prices.askedAt)
+	assert.Equal(t, usd(5000), perf.HODLValueUSD)
+	assert.Equal(t, usd(5000), perf.LPValueUSD)
+}
+
+func TestPerformanceService_Errors(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepo()
+	userID := uuid.New()
+	pos := ethUSDCPosition(userID)
+	require.NoError(t, repo.Create(ctx, pos))
+
+	prices := &mockPriceHistory{mockPrices: mockPrices{"WETH": usd(2000)}}
+	svc := NewPerformanceService(repo, mockWallets{pos.WalletID: {userID}}, prices, logger.New("development", io.Discard))
+
+	_, err := svc.GetPerformance(ctx, uuid.New(), pos.ID)
+	assert.ErrorIs(t, err, ErrPositionNotFound)
+
+	_, err = svc.GetPerformance(ctx, userID, pos.ID)
+	assert.ErrorIs(t, err, ErrMissingPrices)
+}
@@ -8,18 +8,21 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
 
 type Service struct {
-	repo   Repository
-	logger *logger.Logger
+	repo    Repository
+	wallets wallet.Reader
+	logger  *logger.Logger
 }
 
-func NewService(repo Repository, log *logger.Logger) *Service {
+func NewService(repo Repository, wallets wallet.Reader, log *logger.Logger) *Service {
 	return &Service{
-		repo:   repo,
-		logger: log.WithField("component", "lpposition"),
+		repo:    repo,
+		wallets: wallets,
+		logger:  log.WithField("component", "lpposition"),
 	}
 }
 
@@ -29,7 +32,8 @@ type TokenInfo struct {
 	Decimals int
 }
 
-// FindOrCreate looks up an LP position by NFT token ID, or creates a new one.
+// FindOrCreate looks up an LP position by NFT token ID, or for fungible
+// pools the open position in the LP token contract, or creates a new one.
 func (s *Service) FindOrCreate(ctx context.Context, userID, walletID uuid.UUID, chainID, protocol, nftTokenID, contractAddress string, token0, token1 TokenInfo, openedAt time.Time) (*LPPosition, error) {
 	if nftTokenID != "" {
 		pos, err := s.repo.GetByNFTTokenID(ctx, walletID, chainID, nftTokenID)
@@ -39,6 +43,14 @@ func (s *Service) FindOrCreate(ctx context.Context, userID, walletID uuid.UUID,
 		if pos != nil {
 			return pos, nil
 		}
+	} else if contractAddress != "" {
+		pos, err := s.repo.GetOpenByContract(ctx, walletID, chainID, contractAddress)
+		if err != nil {
+			return nil, fmt.Errorf("find by contract: %w", err)
+		}
+		if pos != nil {
+			return pos, nil
+		}
 	}
 
 	pos := &LPPosition{
@@ -67,6 +79,9 @@ func (s *Service) FindOrCreate(ctx context.Context, userID, walletID uuid.UUID,
 		TotalClaimedToken0:   big.NewInt(0),
 		TotalClaimedToken1:   big.NewInt(0),
 
+		CapitalUSD:        big.NewInt(0),
+		CapitalUSDSeconds: big.NewInt(0),
+
 		Status:   StatusOpen,
 		OpenedAt: openedAt,
 
@@ -81,6 +96,7 @@ func (s *Service) FindOrCreate(ctx context.Context, userID, walletID uuid.UUID,
 	s.logger.Info("LP position created",
 		"position_id", pos.ID,
 		"nft_token_id", nftTokenID,
+		"contract_address", contractAddress,
 		"token0", token0.Symbol,
 		"token1", token1.Symbol,
 	)
@@ -113,8 +129,18 @@ func (s *Service) FindOpenByTokenPair(ctx context.Context, walletID uuid.UUID, c
 	return positions[0], nil // oldest first (repo sorts by opened_at ASC)
 }
 
-// RecordDeposit updates aggregates after a deposit.
-func (s *Service) RecordDeposit(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int) error {
+// FindOpenByContract finds the open position in a fungible pool by its LP
+// token contract. Returns nil if none is open.
+func (s *Service) FindOpenByContract(ctx context.Context, walletID uuid.UUID, chainID, contractAddress string) (*LPPosition, error) {
+	pos, err := s.repo.GetOpenByContract(ctx, walletID, chainID, contractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("find by contract: %w", err)
+	}
+	return pos, nil
+}
+
+// RecordDeposit updates aggregates after a deposit made at the given time.
+func (s *Service) RecordDeposit(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int, at time.Time) error {
 	pos, err := s.repo.GetByID(ctx, positionID)
 	if err != nil {
 		return fmt.Errorf("get position: %w", err)
@@ -126,13 +152,15 @@ func (s *Service) RecordDeposit(ctx context.Context, positionID uuid.UUID, token
 	pos.TotalDepositedUSD.Add(pos.TotalDepositedUSD, usdValue)
 	pos.TotalDepositedToken0.Add(pos.TotalDepositedToken0, token0Amt)
 	pos.TotalDepositedToken1.Add(pos.TotalDepositedToken1, token1Amt)
+	pos.adjustCapital(usdValue, at)
 	pos.UpdatedAt = time.Now().UTC()
 
 	return s.repo.Update(ctx, pos)
 }
 
-// RecordWithdraw updates aggregates after a withdrawal. Closes position if fully withdrawn.
-func (s *Service) RecordWithdraw(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int) error {
+// RecordWithdraw updates aggregates after a withdrawal made at the given time.
+// Closes position if fully withdrawn.
+func (s *Service) RecordWithdraw(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int, at time.Time) error {
 	pos, err := s.repo.GetByID(ctx, positionID)
 	if err != nil {
 		return fmt.Errorf("get position: %w", err)
@@ -144,17 +172,19 @@ func (s *Service) RecordWithdraw(ctx context.Context, positionID uuid.UUID, toke
 	pos.TotalWithdrawnUSD.Add(pos.TotalWithdrawnUSD, usdValue)
 	pos.TotalWithdrawnToken0.Add(pos.TotalWithdrawnToken0, token0Amt)
 	pos.TotalWithdrawnToken1.Add(pos.TotalWithdrawnToken1, token1Amt)
+	pos.adjustCapital(new(big.Int).Neg(usdValue), at)
 	pos.UpdatedAt = time.Now().UTC()
 
 	if pos.IsFullyWithdrawn() {
-		s.closePosition(pos)
+		s.closePosition(pos, at)
 	}
 
 	return s.repo.Update(ctx, pos)
 }
 
-// RecordClaimFees updates aggregates after a fee claim.
-func (s *Service) RecordClaimFees(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int) error {
+// RecordClaimFees updates aggregates after a fee claim made at the given time.
+// Closes an already fully withdrawn position at the claim time.
+func (s *Service) RecordClaimFees(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int, at time.Time) error {
 	pos, err := s.repo.GetByID(ctx, positionID)
 	if err != nil {
 		return fmt.Errorf("get position: %w", err)
@@ -169,25 +199,36 @@ func (s *Service) RecordClaimFees(ctx context.Context, positionID uuid.UUID, tok
 	pos.UpdatedAt = time.Now().UTC()
 
 	if pos.IsFullyWithdrawn() && pos.Status != StatusClosed {
-		s.closePosition(pos)
+		s.closePosition(pos, at)
 	}
 
 	return s.repo.Update(ctx, pos)
 }
 
-func (s *Service) closePosition(pos *LPPosition) {
-	now := time.Now().UTC()
+func (s *Service) closePosition(pos *LPPosition, closedAt time.Time) {
 	pos.Status = StatusClosed
-	pos.ClosedAt = &now
+	pos.ClosedAt = &closedAt
 
 	// realized PnL = withdrawn + claimed - deposited
 	pnl := new(big.Int).Add(pos.TotalWithdrawnUSD, pos.TotalClaimedFeesUSD)
 	pnl.Sub(pnl, pos.TotalDepositedUSD)
 	pos.RealizedPnLUSD = pnl
 
-	// APR in basis points = (pnl / deposited) / years * 10000
-	if pos.TotalDepositedUSD.Sign() > 0 && pos.ClosedAt != nil {
-		duration := pos.ClosedAt.Sub(pos.OpenedAt)
+	// Live metrics only describe open positions; the tick range is kept
+	pos.CurrentValueUSD = nil
+	pos.UnrealizedPnLUSD = nil
+	pos.FeeAPRBps = nil
+	pos.TotalAPRBps = nil
+	pos.CurrentTick = nil
+	pos.InRange = nil
+	pos.UncollectedFeesToken0 = nil
+	pos.UncollectedFeesToken1 = nil
+
+	if twc := pos.TimeWeightedCapital(closedAt); twc.Sign() > 0 {
+		pos.APRBps = annualizedBps(pnl, twc)
+	} else if pos.TotalDepositedUSD.Sign() > 0 {
+		// Positions without capital history: APR in basis points = (pnl / deposited) / years * 10000
+		duration := closedAt.Sub(pos.OpenedAt)
 		if duration > 0 {
 			// apr = pnl * 10000 * seconds_per_year / (deposited * duration_seconds)
 			secondsPerYear := big.NewInt(365 * 24 * 3600)
@@ -209,6 +250,94 @@ func (s *Service) closePosition(pos *LPPosition) {
 	)
 }
 
+// RecordValuation stores the current USD value of an open position and
+// derives unrealized PnL and APRs from it. Fee APR counts claimed fees only;
+// both APRs are annualized over time-weighted capital.
+func (s *Service) RecordValuation(ctx context.Context, positionID uuid.UUID, valueUSD *big.Int, at time.Time) error {
+	pos, err := s.repo.GetByID(ctx, positionID)
+	if err != nil {
+		return fmt.Errorf("get position: %w", err)
+	}
+	if pos == nil {
+		return fmt.Errorf("position not found: %s", positionID)
+	}
+	if pos.Status != StatusOpen {
+		return nil
+	}
+
+	applyValuation(pos, valueUSD, at)
+	pos.UpdatedAt = time.Now().UTC()
+
+	return s.repo.Update(ctx, pos)
+}
+
+// applyValuation sets live metrics on an open position valued at valueUSD.
+// unrealized PnL = value + withdrawn + claimed - deposited, which equals the
+// realized PnL the position would book if fully withdrawn at that value.
+func applyValuation(pos *LPPosition, valueUSD *big.Int, at time.Time) {
+	pnl := new(big.Int).Add(valueUSD, pos.TotalWithdrawnUSD)
+	pnl.Add(pnl, pos.TotalClaimedFeesUSD)
+	pnl.Sub(pnl, pos.TotalDepositedUSD)
+
+	pos.CurrentValueUSD = new(big.Int).Set(valueUSD)
+	pos.UnrealizedPnLUSD = pnl
+	pos.ValuedAt = &at
+	pos.FeeAPRBps = nil
+	pos.TotalAPRBps = nil
+
+	if twc := pos.TimeWeightedCapital(at); twc.Sign() > 0 {
+		pos.FeeAPRBps = annualizedBps(pos.TotalClaimedFeesUSD, twc)
+		pos.TotalAPRBps = annualizedBps(pnl, twc)
+	}
+}
+
+// annualizedBps returns amount / average capital / years in basis points,
+// i.e. amount * 10000 * seconds_per_year / capital_usd_seconds
+func annualizedBps(amount, capitalUSDSeconds *big.Int) *int {
+	secondsPerYear := big.NewInt(365 * 24 * 3600)
+	numerator := new(big.Int).Mul(amount, big.NewInt(10000))
+	numerator.Mul(numerator, secondsPerYear)
+	bps := int(numerator.Quo(numerator, capitalUSDSeconds).Int64())
+	return &bps
+}
+
+// RecordRangeState stores an on-chain range snapshot for an open position.
+func (s *Service) RecordRangeState(ctx context.Context, positionID uuid.UUID, state RangeState, at time.Time) error {
+	pos, err := s.repo.GetByID(ctx, positionID)
+	if err != nil {
+		return fmt.Errorf("get position: %w", err)
+	}
+	if pos == nil {
+		return fmt.Errorf("position not found: %s", positionID)
+	}
+	if pos.Status != StatusOpen {
+		return nil
+	}
+
+	applyRangeState(pos, state, at)
+	pos.UpdatedAt = time.Now().UTC()
+
+	return s.repo.Update(ctx, pos)
+}
+
+func applyRangeState(pos *LPPosition, state RangeState, at time.Time) {
+	inRange := state.InRange()
+	pos.TickLower = &state.TickLower
+	pos.TickUpper = &state.TickUpper
+	pos.CurrentTick = &state.CurrentTick
+	pos.InRange = &inRange
+	pos.UncollectedFeesToken0 = bigIntOrZero(state.UncollectedToken0)
+	pos.UncollectedFeesToken1 = bigIntOrZero(state.UncollectedToken1)
+	pos.RangeUpdatedAt = &at
+}
+
+func bigIntOrZero(v *big.Int) *big.Int {
+	if v == nil {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(v)
+}
+
 // GetByID returns a position by ID.
 func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*LPPosition, error) {
 	return s.repo.GetByID(ctx, id)
@@ -218,3 +347,42 @@ func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*LPPosition, error
 func (s *Service) ListByUser(ctx context.Context, userID uuid.UUID, status *Status, walletID *uuid.UUID, chainID *string) ([]*LPPosition, error) {
 	return s.repo.ListByUser(ctx, userID, status, walletID, chainID)
 }
+
+// GetAccessible returns a position on a wallet the user can view.
+func (s *Service) GetAccessible(ctx context.Context, userID, id uuid.UUID) (*LPPosition, error) {
+	return getAccessible(ctx, s.repo, s.wallets, userID, id)
+}
+
+// ListAccessible returns positions on the wallets the user can view through
+// their workspaces, with optional filters.
+func (s *Service) ListAccessible(ctx context.Context, userID uuid.UUID, status *Status, walletID *uuid.UUID, chainID *string) ([]*LPPosition, error) {
+	walletIDs, err := wallet.AccessibleIDs(ctx, s.wallets, userID, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve accessible wallets: %w", err)
+	}
+	if len(walletIDs) == 0 {
+		return nil, nil
+	}
+	return s.repo.ListByWallets(ctx, walletIDs, status, chainID)
+}
+
+// getAccessible loads a position and checks the user can view its wallet.
+// Positions the user cannot view read as ErrPositionNotFound.
+func getAccessible(ctx context.Context, repo Repository, wallets wallet.Reader, userID, id uuid.UUID) (*LPPosition, error) {
+	pos, err := repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get position: %w", err)
+	}
+	if pos == nil {
+		return nil, ErrPositionNotFound
+	}
+
+	ok, err := wallet.CanView(ctx, wallets, pos.WalletID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("check wallet access: %w", err)
+	}
+	if !ok {
+		return nil, ErrPositionNotFound
+	}
+	return pos, nil
+}
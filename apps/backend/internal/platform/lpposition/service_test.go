@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"math/big"
+	"slices"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
 
@@ -50,6 +52,16 @@ func (r *mockRepo) GetByNFTTokenID(_ context.Context, walletID uuid.UUID, chainI
 	return nil, nil
 }
 
+func (r *mockRepo) GetOpenByContract(_ context.Context, walletID uuid.UUID, chainID, contractAddress string) (*LPPosition, error) {
+	for _, pos := range r.positions {
+		if pos.WalletID == walletID && pos.ChainID == chainID && pos.NFTTokenID == "" &&
+			pos.ContractAddress == contractAddress && pos.Status == StatusOpen {
+			return pos, nil
+		}
+	}
+	return nil, nil
+}
+
 func (r *mockRepo) FindOpenByTokenPair(_ context.Context, walletID uuid.UUID, chainID, protocol, token0, token1 string) ([]*LPPosition, error) {
 	var result []*LPPosition
 	for _, pos := range r.positions {
@@ -83,10 +95,51 @@ func (r *mockRepo) ListByUser(_ context.Context, userID uuid.UUID, status *Statu
 	return result, nil
 }
 
+func (r *mockRepo) ListByWallets(_ context.Context, walletIDs []uuid.UUID, status *Status, chainID *string) ([]*LPPosition, error) {
+	var result []*LPPosition
+	for _, pos := range r.positions {
+		if !slices.Contains(walletIDs, pos.WalletID) {
+			continue
+		}
+		if status != nil && pos.Status != *status {
+			continue
+		}
+		if chainID != nil && pos.ChainID != *chainID {
+			continue
+		}
+		result = append(result, pos)
+	}
+	return result, nil
+}
+
+// mockWallets maps each wallet to the users who can view it
+type mockWallets map[uuid.UUID][]uuid.UUID
+
+func (m mockWallets) GetByID(_ context.Context, id, userID uuid.UUID) (*wallet.Wallet, error) {
+	viewers, ok := m[id]
+	if !ok {
+		return nil, wallet.ErrWalletNotFound
+	}
+	if !slices.Contains(viewers, userID) {
+		return nil, wallet.ErrUnauthorizedAccess
+	}
+	return &wallet.Wallet{ID: id}, nil
+}
+
+func (m mockWallets) List(_ context.Context, userID uuid.UUID) ([]*wallet.Wallet, error) {
+	var result []*wallet.Wallet
+	for id, viewers := range m {
+		if slices.Contains(viewers, userID) {
+			result = append(result, &wallet.Wallet{ID: id})
+		}
+	}
+	return result, nil
+}
+
 func newTestService() (*Service, *mockRepo) {
 	repo := newMockRepo()
 	log := logger.New("test", io.Discard)
-	svc := NewService(repo, log)
+	svc := NewService(repo, mockWallets{}, log)
 	return svc, repo
 }
 
@@ -127,7 +180,7 @@ func TestRecordDeposit_UpdatesAggregates(t *testing.T) {
 	pos := createTestPosition(repo)
 	ctx := context.Background()
 
-	err := svc.RecordDeposit(ctx, pos.ID, big.NewInt(1000), big.NewInt(2000), big.NewInt(500))
+	err := svc.RecordDeposit(ctx, pos.ID, big.NewInt(1000), big.NewInt(2000), big.NewInt(500), time.Now())
 	require.NoError(t, err)
 
 	updated := repo.positions[pos.ID]
@@ -148,7 +201,7 @@ func TestRecordWithdraw_ClosesWhenFullyWithdrawn(t *testing.T) {
 	pos.TotalDepositedUSD = big.NewInt(500)
 
 	// Withdraw everything
-	err := svc.RecordWithdraw(ctx, pos.ID, big.NewInt(1000), big.NewInt(2000), big.NewInt(600))
+	err := svc.RecordWithdraw(ctx, pos.ID, big.NewInt(1000), big.NewInt(2000), big.NewInt(600), time.Now())
 	require.NoError(t, err)
 
 	updated := repo.positions[pos.ID]
@@ -165,18 +218,18 @@ func TestMultiDepositWithdraw(t *testing.T) {
 	ctx := context.Background()
 
 	// Deposit $100 worth
-	err := svc.RecordDeposit(ctx, pos.ID, big.NewInt(100), big.NewInt(200), big.NewInt(100))
+	err := svc.RecordDeposit(ctx, pos.ID, big.NewInt(100), big.NewInt(200), big.NewInt(100), time.Now())
 	require.NoError(t, err)
 
 	// Withdraw $30 worth (partial)
-	err = svc.RecordWithdraw(ctx, pos.ID, big.NewInt(30), big.NewInt(60), big.NewInt(30))
+	err = svc.RecordWithdraw(ctx, pos.ID, big.NewInt(30), big.NewInt(60), big.NewInt(30), time.Now())
 	require.NoError(t, err)
 
 	updated := repo.positions[pos.ID]
 	assert.Equal(t, StatusOpen, updated.Status, "should remain open after partial withdraw")
 
 	// Deposit $50 more
-	err = svc.RecordDeposit(ctx, pos.ID, big.NewInt(50), big.NewInt(100), big.NewInt(50))
+	err = svc.RecordDeposit(ctx, pos.ID, big.NewInt(50), big.NewInt(100), big.NewInt(50), time.Now())
 	require.NoError(t, err)
 
 	assert.Equal(t, big.NewInt(150), updated.TotalDepositedUSD)
@@ -184,7 +237,7 @@ func TestMultiDepositWithdraw(t *testing.T) {
 	assert.Equal(t, big.NewInt(300), updated.TotalDepositedToken1)
 
 	// Withdraw all remaining: 150-30=120 token0, 300-60=240 token1
-	err = svc.RecordWithdraw(ctx, pos.ID, big.NewInt(120), big.NewInt(240), big.NewInt(130))
+	err = svc.RecordWithdraw(ctx, pos.ID, big.NewInt(120), big.NewInt(240), big.NewInt(130), time.Now())
 	require.NoError(t, err)
 
 	updated = repo.positions[pos.ID]
@@ -207,11 +260,11 @@ func TestClosePosition_CalculatesPnLAndAPR(t *testing.T) {
 	pos.TotalDepositedToken1 = big.NewInt(200)
 
 	// Claim fees worth 50 USD
-	err := svc.RecordClaimFees(ctx, pos.ID, big.NewInt(5), big.NewInt(10), big.NewInt(50))
+	err := svc.RecordClaimFees(ctx, pos.ID, big.NewInt(5), big.NewInt(10), big.NewInt(50), time.Now())
 	require.NoError(t, err)
 
 	// Withdraw all with value 1100 USD
-	err = svc.RecordWithdraw(ctx, pos.ID, big.NewInt(100), big.NewInt(200), big.NewInt(1100))
+	err = svc.RecordWithdraw(ctx, pos.ID, big.NewInt(100), big.NewInt(200), big.NewInt(1100), time.Now())
 	require.NoError(t, err)
 
 	updated := repo.positions[pos.ID]
@@ -237,7 +290,7 @@ func TestRecordWithdraw_ClosesWithImpermanentLoss(t *testing.T) {
 	// Withdraw with impermanent loss: over-withdraw token0, under-withdraw token1.
 	// token0: 1050 withdrawn (50 over), token1: 1960 withdrawn (40 under).
 	// Net remaining = (1000-1050) + (2000-1960) = -50 + 40 = -10 <= 0 → closed.
-	err := svc.RecordWithdraw(ctx, pos.ID, big.NewInt(1050), big.NewInt(1960), big.NewInt(500))
+	err := svc.RecordWithdraw(ctx, pos.ID, big.NewInt(1050), big.NewInt(1960), big.NewInt(500), time.Now())
 	require.NoError(t, err)
 
 	updated := repo.positions[pos.ID]
@@ -256,7 +309,7 @@ func TestRecordWithdraw_StaysOpenWithPositiveNetRemaining(t *testing.T) {
 	pos.TotalDepositedUSD = big.NewInt(500)
 
 	// Partial withdraw: net remaining = (1000-500) + (2000-800) = 500 + 1200 = 1700 > 0
-	err := svc.RecordWithdraw(ctx, pos.ID, big.NewInt(500), big.NewInt(800), big.NewInt(250))
+	err := svc.RecordWithdraw(ctx, pos.ID, big.NewInt(500), big.NewInt(800), big.NewInt(250), time.Now())
 	require.NoError(t, err)
 
 	updated := repo.positions[pos.ID]
@@ -282,12 +335,14 @@ func TestRecordClaimFees_ClosesAlreadyWithdrawnPosition(t *testing.T) {
 	pos.Status = StatusOpen
 
 	// Claim fees
-	err := svc.RecordClaimFees(ctx, pos.ID, big.NewInt(5), big.NewInt(10), big.NewInt(3))
+	claimedAt := time.Now().UTC().Add(-time.Hour)
+	err := svc.RecordClaimFees(ctx, pos.ID, big.NewInt(5), big.NewInt(10), big.NewInt(3), claimedAt)
 	require.NoError(t, err)
 
 	updated := repo.positions[pos.ID]
 	assert.Equal(t, StatusClosed, updated.Status, "claim should close already-withdrawn position")
-	assert.NotNil(t, updated.ClosedAt)
+	require.NotNil(t, updated.ClosedAt)
+	assert.True(t, claimedAt.Equal(*updated.ClosedAt), "position should close at the claim time")
 	// PnL = withdrawn(500) + claimed(3) - deposited(500) = 3
 	assert.Equal(t, big.NewInt(3), updated.RealizedPnLUSD)
 }
@@ -302,7 +357,7 @@ func TestRecordClaimFees_UpdatesAggregates(t *testing.T) {
 	pos.TotalDepositedToken1 = big.NewInt(2000)
 	pos.TotalDepositedUSD = big.NewInt(500)
 
-	err := svc.RecordClaimFees(ctx, pos.ID, big.NewInt(50), big.NewInt(100), big.NewInt(25))
+	err := svc.RecordClaimFees(ctx, pos.ID, big.NewInt(50), big.NewInt(100), big.NewInt(25), time.Now())
 	require.NoError(t, err)
 
 	updated := repo.positions[pos.ID]
@@ -379,3 +434,162 @@ func TestFindOpenByTokenPair_WarnsOnMultiple(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotNil(t, found)
 }
+
+func TestRecordValuation_ComputesLiveMetrics(t *testing.T) {
+	svc, repo := newTestService()
+	pos := createTestPosition(repo)
+	ctx := context.Background()
+
+	// $1000 deployed for a full year, then $50 of fees claimed
+	start := time.Now().UTC().Add(-365 * 24 * time.Hour)
+	require.NoError(t, svc.RecordDeposit(ctx, pos.ID, big.NewInt(100), big.NewInt(200), big.NewInt(1000), start))
+	require.NoError(t, svc.RecordClaimFees(ctx, pos.ID, big.NewInt(5), big.NewInt(10), big.NewInt(50), start.Add(180*24*time.Hour)))
+
+	err := svc.RecordValuation(ctx, pos.ID, big.NewInt(1100), start.Add(365*24*time.Hour))
+	require.NoError(t, err)
+
+	updated := repo.positions[pos.ID]
+	assert.Equal(t, StatusOpen, updated.Status)
+	assert.Equal(t, big.NewInt(1100), updated.CurrentValueUSD)
+	// unrealized = value(1100) + withdrawn(0) + claimed(50) - deposited(1000)
+	assert.Equal(t, big.NewInt(150), updated.UnrealizedPnLUSD)
+	require.NotNil(t, updated.FeeAPRBps)
+	require.NotNil(t, updated.TotalAPRBps)
+	assert.Equal(t, 500, *updated.FeeAPRBps)
+	assert.Equal(t, 1500, *updated.TotalAPRBps)
+	assert.NotNil(t, updated.ValuedAt)
+}
+
+func TestRecordValuation_AnnualizesOverTimeWeightedCapital(t *testing.T) {
+	svc, repo := newTestService()
+	pos := createTestPosition(repo)
+	ctx := context.Background()
+
+	// $1000 for the first half of the year, $2000 for the second half:
+	// average capital is $1500
+	start := time.Now().UTC().Add(-365 * 24 * time.Hour)
+	half := start.Add(365 * 12 * time.Hour)
+	require.NoError(t, svc.RecordDeposit(ctx, pos.ID, big.NewInt(100), big.NewInt(200), big.NewInt(1000), start))
+	require.NoError(t, svc.RecordDeposit(ctx, pos.ID, big.NewInt(100), big.NewInt(200), big.NewInt(1000), half))
+	require.NoError(t, svc.RecordClaimFees(ctx, pos.ID, big.NewInt(5), big.NewInt(10), big.NewInt(150), half))
+
+	require.NoError(t, svc.RecordValuation(ctx, pos.ID, big.NewInt(2000), start.Add(365*24*time.Hour)))
+
+	updated := repo.positions[pos.ID]
+	assert.Equal(t, big.NewInt(2000), updated.CapitalUSD)
+	// fees 150 / avg capital 1500 = 10%
+	assert.Equal(t, 1000, *updated.FeeAPRBps)
+}
+
+func TestRecordValuation_IgnoresClosedPositions(t *testing.T) {
+	svc, repo := newTestService()
+	pos := createTestPosition(repo)
+	pos.Status = StatusClosed
+
+	require.NoError(t, svc.RecordValuation(context.Background(), pos.ID, big.NewInt(100), time.Now()))
+	assert.Nil(t, repo.positions[pos.ID].CurrentValueUSD)
+}
+
+func TestClosePosition_ClearsLiveMetricsAndUsesCapitalHistory(t *testing.T) {
+	svc, repo := newTestService()
+	pos := createTestPosition(repo)
+	ctx := context.Background()
+
+	start := time.Now().UTC().Add(-2 * 365 * 24 * time.Hour)
+	require.NoError(t, svc.RecordDeposit(ctx, pos.ID, big.NewInt(100), big.NewInt(200), big.NewInt(1000), start))
+	require.NoError(t, svc.RecordValuation(ctx, pos.ID, big.NewInt(1050), start.Add(24*time.Hour)))
+
+	closeAt := start.Add(365 * 24 * time.Hour)
+	require.NoError(t, svc.RecordWithdraw(ctx, pos.ID, big.NewInt(100), big.NewInt(200), big.NewInt(1100), closeAt))
+
+	updated := repo.positions[pos.ID]
+	assert.Equal(t, StatusClosed, updated.Status)
+	assert.Equal(t, closeAt, *updated.ClosedAt)
+	assert.Nil(t, updated.CurrentValueUSD)
+	assert.Nil(t, updated.TotalAPRBps)
+	// 100 on 1000 over exactly one year of deployed capital
+	require.NotNil(t, updated.APRBps)
+	assert.Equal(t, 1000, *updated.APRBps)
+}
+
+func TestRecordRangeState_StoresSnapshot(t *testing.T) {
+	svc, repo := newTestService()
+	pos := createTestPosition(repo)
+	at := time.Now().UTC()
+
+	state := RangeState{
+		TickLower:         -200,
+		TickUpper:         200,
+		CurrentTick:       200,
+		UncollectedToken0: big.NewInt(7),
+		UncollectedToken1: big.NewInt(11),
+	}
+	require.NoError(t, svc.RecordRangeState(context.Background(), pos.ID, state, at))
+
+	updated := repo.positions[pos.ID]
+	assert.Equal(t, -200, *updated.TickLower)
+	assert.Equal(t, 200, *updated.TickUpper)
+	assert.Equal(t, 200, *updated.CurrentTick)
+	// The upper tick is exclusive
+	assert.False(t, *updated.InRange)
+	assert.Equal(t, big.NewInt(7), updated.UncollectedFeesToken0)
+	assert.Equal(t, big.NewInt(11), updated.UncollectedFeesToken1)
+	assert.Equal(t, at, *updated.RangeUpdatedAt)
+
+	state.CurrentTick = -200
+	require.NoError(t, svc.RecordRangeState(context.Background(), pos.ID, state, at))
+	assert.True(t, *repo.positions[pos.ID].InRange)
+}
+
+func TestClosePosition_ClearsRangeStateButKeepsTicks(t *testing.T) {
+	svc, repo := newTestService()
+	pos := createTestPosition(repo)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	require.NoError(t, svc.RecordDeposit(ctx, pos.ID, big.NewInt(100), big.NewInt(200), big.NewInt(1000), now))
+	state := RangeState{TickLower: -10, TickUpper: 10, CurrentTick: 0, UncollectedToken0: big.NewInt(1), UncollectedToken1: big.NewInt(1)}
+	require.NoError(t, svc.RecordRangeState(ctx, pos.ID, state, now))
+	require.NoError(t, svc.RecordWithdraw(ctx, pos.ID, big.NewInt(100), big.NewInt(200), big.NewInt(1000), now))
+
+	updated := repo.positions[pos.ID]
+	assert.Equal(t, StatusClosed, updated.Status)
+	assert.Equal(t, -10, *updated.TickLower)
+	assert.Equal(t, 10, *updated.TickUpper)
+	assert.Nil(t, updated.CurrentTick)
+	assert.Nil(t, updated.InRange)
+	assert.Nil(t, updated.UncollectedFeesToken0)
+}
+
+func TestListAccessible_IncludesSharedWalletPositions(t *testing.T) {
+	repo := newMockRepo()
+	ctx := context.Background()
+	owner, member, outsider := uuid.New(), uuid.New(), uuid.New()
+
+	pos := createTestPosition(repo)
+	pos.UserID = owner
+	other := createTestPosition(repo)
+	svc := NewService(repo, mockWallets{
+		pos.WalletID:   {owner, member},
+		other.WalletID: {other.UserID},
+	}, logger.New("test", io.Discard))
+
+	positions, err := svc.ListAccessible(ctx, member, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	assert.Equal(t, pos.ID, positions[0].ID)
+
+	got, err := svc.GetAccessible(ctx, member, pos.ID)
+	require.NoError(t, err)
+	assert.Equal(t, pos.ID, got.ID)
+
+	positions, err = svc.ListAccessible(ctx, outsider, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, positions)
+
+	_, err = svc.GetAccessible(ctx, outsider, pos.ID)
+	assert.ErrorIs(t, err, ErrPositionNotFound)
+
+	_, err = svc.GetAccessible(ctx, member, other.ID)
+	assert.ErrorIs(t, err, ErrPositionNotFound)
+}
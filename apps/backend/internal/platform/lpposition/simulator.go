@@ -0,0 +1,406 @@
+package lpposition
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+const (
+	defaultGridPoints = 50
+	maxGridPoints     = 500
+
+	// MaxPricePoints caps the extra prices a single simulation evaluates
+	MaxPricePoints = maxGridPoints
+)
+
+// PriceService supplies current USD prices (scaled by 10^8); zero means unknown
+type PriceService interface {
+	GetPriceBySymbol(ctx context.Context, symbol string) (*big.Int, error)
+}
+
+// PriceRange is a concentrated-liquidity range in ticks, [TickLower, TickUpper)
+type PriceRange struct {
+	TickLower int
+	TickUpper int
+}
+
+// Validate checks the ticks are ordered and within Uniswap V3 bounds
+func (r PriceRange) Validate() error {
+	if r.TickLower < MinTick || r.TickUpper > MaxTick {
+		return ErrTickOutOfRange
+	}
+	if r.TickLower >= r.TickUpper {
+		return ErrInvalidRange
+	}
+	return nil
+}
+
+// SimulationInput is a fully resolved simulation: range, liquidity and prices
+// are all known
+type SimulationInput struct {
+	Range          PriceRange
+	Liquidity      *big.Int
+	Token0Decimals int
+	Token1Decimals int
+
+	// CurrentPrice is the pool price as token1 per token0
+	CurrentPrice *big.Float
+	// Token1USDRate values token1 in USD (scaled by 10^8); token0 is valued
+	// through the simulated price. Nil leaves USD values at zero.
+	Token1USDRate *big.Int
+
+	// Prices are extra price points to evaluate, at most MaxPricePoints
+	Prices []*big.Float
+
+	// GridMin/GridMax bound the chart curve; nil defaults to half the lower
+	// bound and one and a half times the upper bound
+	GridMin    *big.Float
+	GridMax    *big.Float
+	GridPoints int
+}
+
+// SimulationPoint is the position's composition and value at one price
+type SimulationPoint struct {
+	Price    *big.Float
+	Tick     int
+	Amount0  *big.Int
+	Amount1  *big.Int
+	ValueUSD *big.Int
+	InRange  bool
+}
+
+// Simulation is the result of simulating a position across prices
+type Simulation struct {
+	// Position is the stored position simulated; nil for ad hoc input
+	Position *LPPosition
+
+	Range      PriceRange
+	PriceLower *big.Float
+	PriceUpper *big.Float
+	Liquidity  *big.Int
+
+	Current SimulationPoint
+	// BelowRange is the position once price exits through the lower bound:
+	// fully converted to token0
+	BelowRange SimulationPoint
+	// AboveRange is the position once price exits through the upper bound:
+	// fully converted to token1
+	AboveRange SimulationPoint
+
+	Points []SimulationPoint
+	Curve  []SimulationPoint
+}
+
+// Simulate computes token composition and USD value of a concentrated-liquidity
+// position at the current price, at both range exits, at the requested prices
+// and along a price grid
+func Simulate(in SimulationInput) (*Simulation, error) {
+	if err := in.Range.Validate(); err != nil {
+		return nil, err
+	}
+	if in.Liquidity == nil || in.Liquidity.Sign() <= 0 {
+		return nil, ErrNoLiquidity
+	}
+	if in.CurrentPrice == nil || in.CurrentPrice.Sign() <= 0 {
+		return nil, ErrInvalidPrice
+	}
+	if len(in.Prices) > MaxPricePoints {
+		return nil, ErrTooManyPrices
+	}
+
+	sqrtA, _ := SqrtRatioAtTick(in.Range.TickLower)
+	sqrtB, _ := SqrtRatioAtTick(in.Range.TickUpper)
+	sim := &Simulation{
+		Range:      in.Range,
+		PriceLower: SqrtPriceX96ToPrice(sqrtA, in.Token0Decimals, in.Token1Decimals),
+		PriceUpper: SqrtPriceX96ToPrice(sqrtB, in.Token0Decimals, in.Token1Decimals),
+		Liquidity:  new(big.Int).Set(in.Liquidity),
+	}
+
+	var err error
+	if sim.Current, err = in.pointAtPrice(in.CurrentPrice, sqrtA, sqrtB); err != nil {
+		return nil, fmt.Errorf("current price: %w", err)
+	}
+	sim.BelowRange = in.pointAtSqrt(sqrtA, in.Range.TickLower, sqrtA, sqrtB)
+	sim.AboveRange = in.pointAtSqrt(sqrtB, in.Range.TickUpper, sqrtA, sqrtB)
+
+	for _, p := range in.Prices {
+		point, err := in.pointAtPrice(p, sqrtA, sqrtB)
+		if err != nil {
+			return nil, fmt.Errorf("price %s: %w", FormatPrice(p), err)
+		}
+		sim.Points = append(sim.Points, point)
+	}
+
+	grid, err := in.grid(sim.PriceLower, sim.PriceUpper)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range grid {
+		point, err := in.pointAtPrice(p, sqrtA, sqrtB)
+		if err != nil {
+			return nil, fmt.Errorf("grid price %s: %w", FormatPrice(p), err)
+		}
+		sim.Curve = append(sim.Curve, point)
+	}
+
+	return sim, nil
+}
+
+func (in SimulationInput) pointAtPrice(price *big.Float, sqrtA, sqrtB *big.Int) (SimulationPoint, error) {
+	sqrtP, err := PriceToSqrtPriceX96(price, in.Token0Decimals, in.Token1Decimals)
+	if err != nil {
+		return SimulationPoint{}, err
+	}
+	tick, err := TickAtSqrtRatio(sqrtP)
+	if err != nil {
+		return SimulationPoint{}, err
+	}
+
+	point := in.pointAtSqrt(sqrtP, tick, sqrtA, sqrtB)
+	point.Price = new(big.Float).SetPrec(pricePrec).Set(price)
+	point.ValueUSD = in.valueUSD(point.Price, point.Amount0, point.Amount1)
+	return point, nil
+}
+
+func (in SimulationInput) pointAtSqrt(sqrtP *big.Int, tick int, sqrtA, sqrtB *big.Int) SimulationPoint {
+	amount0, amount1 := AmountsForLiquidity(sqrtP, sqrtA, sqrtB, in.Liquidity)
+	price := SqrtPriceX96ToPrice(sqrtP, in.Token0Decimals, in.Token1Decimals)
+
+	return SimulationPoint{
+		Price:    price,
+		Tick:     tick,
+		Amount0:  amount0,
+		Amount1:  amount1,
+		ValueUSD: in.valueUSD(price, amount0, amount1),
+		InRange:  sqrtP.Cmp(sqrtA) >= 0 && sqrtP.Cmp(sqrtB) < 0,
+	}
+}
+
+// valueUSD prices token0 at price × token1 rate, so the value reflects the
+// simulated pool price rather than today's market price of token0
+func (in SimulationInput) valueUSD(price *big.Float, amount0, amount1 *big.Int) *big.Int {
+	if in.Token1USDRate == nil || in.Token1USDRate.Sign() == 0 {
+		return big.NewInt(0)
+	}
+
+	rate0F := new(big.Float).SetPrec(pricePrec).SetInt(in.Token1USDRate)
+	rate0F.Mul(rate0F, price)
+	rate0, _ := rate0F.Int(nil)
+
+	value := money.CalcUSDValue(amount0, rate0, in.Token0Decimals)
+	return value.Add(value, money.CalcUSDValue(amount1, in.Token1USDRate, in.Token1Decimals))
+}
+
+// grid returns evenly spaced prices between the grid bounds, inclusive
+func (in SimulationInput) grid(priceLower, priceUpper *big.Float) ([]*big.Float, error) {
+	n := in.GridPoints
+	if n == 0 {
+		n = defaultGridPoints
+	}
+	if n < 2 || n > maxGridPoints {
+		return nil, ErrInvalidGrid
+	}
+
+	lo := in.GridMin
+	if lo == nil {
+		lo = new(big.Float).SetPrec(pricePrec).Quo(priceLower, big.NewFloat(2))
+	}
+	hi := in.GridMax
+	if hi == nil {
+		hi = new(big.Float).SetPrec(pricePrec).Mul(priceUpper, big.NewFloat(1.5))
+	}
+	if lo.Sign() <= 0 || lo.Cmp(hi) >= 0 {
+		return nil, ErrInvalidGrid
+	}
+
+	step := new(big.Float).SetPrec(pricePrec).Sub(hi, lo)
+	step.Quo(step, new(big.Float).SetInt64(int64(n-1)))
+
+	prices := make([]*big.Float, n)
+	for i := range prices {
+		p := new(big.Float).SetPrec(pricePrec).Mul(step, new(big.Float).SetInt64(int64(i)))
+		prices[i] = p.Add(p, lo)
+	}
+	return prices, nil
+}
+
+// SimulationRequest describes a simulation of a stored position. Any field
+// left nil is resolved from the position or from current market prices.
+type SimulationRequest struct {
+	// Range as ticks, or as human prices (token1 per token0) when the
+	// on-chain range is not known
+	TickLower  *int
+	TickUpper  *int
+	PriceLower *big.Float
+	PriceUpper *big.Float
+
+	// Liquidity overrides the value derived from the position's remaining amounts
+	Liquidity *big.Int
+
+	CurrentPrice  *big.Float
+	Token1USDRate *big.Int
+
+	Prices     []*big.Float
+	GridMin    *big.Float
+	GridMax    *big.Float
+	GridPoints int
+}
+
+// Simulator runs range simulations against stored LP positions
+type Simulator struct {
+	repo    Repository
+	wallets wallet.Reader
+	prices  PriceService
+	logger  *logger.Logger
+}
+
+// NewSimulator creates a new LP range simulator
+func NewSimulator(repo Repository, wallets wallet.Reader, prices PriceService, log *logger.Logger) *Simulator {
+	return &Simulator{
+		repo:    repo,
+		wallets: wallets,
+		prices:  prices,
+		logger:  log.WithField("component", "lp_simulator"),
+	}
+}
+
+// SimulatePosition simulates one of the user's positions. When liquidity is
+// not supplied it is derived from the position's remaining token amounts at
+// the current price.
+func (s *Simulator) SimulatePosition(ctx context.Context, userID, positionID uuid.UUID, req SimulationRequest) (*Simulation, error) {
+	if len(req.Prices) > MaxPricePoints {
+		return nil, ErrTooManyPrices
+	}
+
+	pos, err := getAccessible(ctx, s.repo, s.wallets, userID, positionID)
+	if err != nil {
+		return nil, err
+	}
+
+	rng, err := resolveRange(pos, req)
+	if err != nil {
+		return nil, err
+	}
+
+	price0 := s.usdPrice(ctx, pos.Token0Symbol)
+	price1 := s.usdPrice(ctx, pos.Token1Symbol)
+
+	current := req.CurrentPrice
+	if current == nil {
+		if price0.Sign() == 0 || price1.Sign() == 0 {
+			return nil, ErrPriceUnavailable
+		}
+		current = new(big.Float).SetPrec(pricePrec).SetInt(price0)
+		current.Quo(current, new(big.Float).SetPrec(pricePrec).SetInt(price1))
+	}
+
+	rate1 := req.Token1USDRate
+	if rate1 == nil {
+		rate1 = price1
+		if rate1.Sign() == 0 && price0.Sign() > 0 {
+			// Quote token has no market price; infer it through the pool price
+			r := new(big.Float).SetPrec(pricePrec).SetInt(price0)
+			rate1, _ = r.Quo(r, current).Int(nil)
+		}
+	}
+
+	liquidity := req.Liquidity
+	if liquidity == nil {
+		liquidity, err = liquidityFromPosition(pos, rng, current)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sim, err := Simulate(SimulationInput{
+		Range:          rng,
+		Liquidity:      liquidity,
+		Token0Decimals: pos.Token0Decimals,
+		Token1Decimals: pos.Token1Decimals,
+		CurrentPrice:   current,
+		Token1USDRate:  rate1,
+		Prices:         req.Prices,
+		GridMin:        req.GridMin,
+		GridMax:        req.GridMax,
+		GridPoints:     req.GridPoints,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sim.Position = pos
+	return sim, nil
+}
+
+func (s *Simulator) usdPrice(ctx context.Context, symbol string) *big.Int {
+	if s.prices == nil || symbol == "" {
+		return big.NewInt(0)
+	}
+	price, err := s.prices.GetPriceBySymbol(ctx, symbol)
+	if err != nil || price == nil {
+		s.logger.Debug("no USD price for LP token", "symbol", symbol, "error", err)
+		return big.NewInt(0)
+	}
+	return price
+}
+
+// resolveRange prefers explicit ticks, then manual price bounds, then the
+// range last read on-chain
+func resolveRange(pos *LPPosition, req SimulationRequest) (PriceRange, error) {
+	var rng PriceRange
+	switch {
+	case req.TickLower != nil && req.TickUpper != nil:
+		rng = PriceRange{TickLower: *req.TickLower, TickUpper: *req.TickUpper}
+	case req.PriceLower != nil && req.PriceUpper != nil:
+		lower, err := PriceToTick(req.PriceLower, pos.Token0Decimals, pos.Token1Decimals)
+		if err != nil {
+			return PriceRange{}, fmt.Errorf("price_lower: %w", err)
+		}
+		upper, err := PriceToTick(req.PriceUpper, pos.Token0Decimals, pos.Token1Decimals)
+		if err != nil {
+			return PriceRange{}, fmt.Errorf("price_upper: %w", err)
+		}
+		rng = PriceRange{TickLower: lower, TickUpper: upper}
+	case pos.TickLower != nil && pos.TickUpper != nil:
+		rng = PriceRange{TickLower: *pos.TickLower, TickUpper: *pos.TickUpper}
+	default:
+		return PriceRange{}, ErrRangeRequired
+	}
+
+	if err := rng.Validate(); err != nil {
+		return PriceRange{}, err
+	}
+	return rng, nil
+}
+
+func liquidityFromPosition(pos *LPPosition, rng PriceRange, current *big.Float) (*big.Int, error) {
+	amount0 := nonNegative(pos.RemainingToken0())
+	amount1 := nonNegative(pos.RemainingToken1())
+
+	sqrtP, err := PriceToSqrtPriceX96(current, pos.Token0Decimals, pos.Token1Decimals)
+	if err != nil {
+		return nil, err
+	}
+	sqrtA, _ := SqrtRatioAtTick(rng.TickLower)
+	sqrtB, _ := SqrtRatioAtTick(rng.TickUpper)
+
+	liquidity := LiquidityForAmounts(sqrtP, sqrtA, sqrtB, amount0, amount1)
+	if liquidity.Sign() <= 0 {
+		return nil, ErrNoLiquidity
+	}
+	return liquidity, nil
+}
+
+func nonNegative(v *big.Int) *big.Int {
+	if v.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return v
+}
@@ -0,0 +1,208 @@
+package lpposition
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+type mockPrices map[string]*big.Int
+
+func (m mockPrices) GetPriceBySymbol(_ context.Context, symbol string) (*big.Int, error) {
+	if p, ok := m[symbol]; ok {
+		return p, nil
+	}
+	return big.NewInt(0), nil
+}
+
+func mustPrice(t *testing.T, s string) *big.Float {
+	t.Helper()
+	p, err := ParsePrice(s)
+	require.NoError(t, err)
+	return p
+}
+
+// ethUSDCPosition is a WETH/USDC position holding 1 WETH + 2000 USDC
+func ethUSDCPosition(userID uuid.UUID) *LPPosition {
+	deposited0, _ := new(big.Int).SetString("1000000000000000000", 10)
+	return &LPPosition{
+		ID:                   uuid.New(),
+		UserID:               userID,
+		WalletID:             uuid.New(),
+		ChainID:              "ethereum",
+		Protocol:             "Uniswap V3",
+		Token0Symbol:         "WETH",
+		Token1Symbol:         "USDC",
+		Token0Decimals:       18,
+		Token1Decimals:       6,
+		TotalDepositedToken0: deposited0,
+		TotalDepositedToken1: big.NewInt(2_000_000_000),
+		TotalWithdrawnToken0: big.NewInt(0),
+		TotalWithdrawnToken1: big.NewInt(0),
+		TotalClaimedToken0:   big.NewInt(0),
+		TotalClaimedToken1:   big.NewInt(0),
+		Status:               StatusOpen,
+		OpenedAt:             time.Now().UTC(),
+	}
+}
+
+func TestSimulate_RangeExits(t *testing.T) {
+	lower, _ := PriceToTick(mustPrice(t, "1500"), 18, 6)
+	upper, _ := PriceToTick(mustPrice(t, "2500"), 18, 6)
+	rng := PriceRange{TickLower: lower, TickUpper: upper}
+
+	sqrtP, _ := PriceToSqrtPriceX96(mustPrice(t, "2000"), 18, 6)
+	sqrtA, _ := SqrtRatioAtTick(lower)
+	sqrtB, _ := SqrtRatioAtTick(upper)
+	amount0, _ := new(big.Int).SetString("1000000000000000000", 10)
+	liquidity := LiquidityForAmounts(sqrtP, sqrtA, sqrtB, amount0, big.NewInt(10_000_000_000))
+
+	sim, err := Simulate(SimulationInput{
+		Range:          rng,
+		Liquidity:      liquidity,
+		Token0Decimals: 18,
+		Token1Decimals: 6,
+		CurrentPrice:   mustPrice(t, "2000"),
+		Token1USDRate:  big.NewInt(100_000_000), // USDC = $1
+		Prices:         []*big.Float{mustPrice(t, "1000"), mustPrice(t, "3000")},
+		GridPoints:     11,
+	})
+	require.NoError(t, err)
+
+	assert.True(t, sim.Current.InRange)
+	assert.Positive(t, sim.Current.Amount0.Sign())
+	assert.Positive(t, sim.Current.Amount1.Sign())
+
+	// Exiting down leaves only WETH, exiting up only USDC
+	assert.Positive(t, sim.BelowRange.Amount0.Sign())
+	assert.Zero(t, sim.BelowRange.Amount1.Sign())
+	assert.Zero(t, sim.AboveRange.Amount0.Sign())
+	assert.Positive(t, sim.AboveRange.Amount1.Sign())
+
+	// Beyond the range composition is frozen; only the WETH price moves value
+	below := sim.Points[0]
+	assert.False(t, below.InRange)
+	assert.Equal(t, sim.BelowRange.Amount0, below.Amount0)
+	assert.True(t, below.ValueUSD.Cmp(sim.BelowRange.ValueUSD) < 0)
+
+	above := sim.Points[1]
+	assert.False(t, above.InRange)
+	assert.Equal(t, sim.AboveRange.Amount1, above.Amount1)
+	assert.Equal(t, sim.AboveRange.ValueUSD, above.ValueUSD)
+
+	// Value at the current price is the pool holdings with WETH priced at $2000
+	weth, _ := new(big.Float).Quo(new(big.Float).SetInt(sim.Current.Amount0), big.NewFloat(1e18)).Float64()
+	usdc, _ := new(big.Float).Quo(new(big.Float).SetInt(sim.Current.Amount1), big.NewFloat(1e6)).Float64()
+	value, _ := new(big.Float).Quo(new(big.Float).SetInt(sim.Current.ValueUSD), big.NewFloat(1e8)).Float64()
+	assert.InDelta(t, weth*2000+usdc, value, 0.01)
+
+	require.Len(t, sim.Curve, 11)
+	for i := 1; i < len(sim.Curve); i++ {
+		assert.True(t, sim.Curve[i].Price.Cmp(sim.Curve[i-1].Price) > 0)
+		assert.True(t, sim.Curve[i].ValueUSD.Cmp(sim.Curve[i-1].ValueUSD) >= 0)
+	}
+}
+
+func TestSimulate_RejectsInvalidInput(t *testing.T) {
+	base := SimulationInput{
+		Range:        PriceRange{TickLower: -100, TickUpper: 100},
+		Liquidity:    big.NewInt(1_000_000),
+		CurrentPrice: big.NewFloat(1),
+	}
+
+	in := base
+	in.Range = PriceRange{TickLower: 100, TickUpper: -100}
+	_, err := Simulate(in)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+
+	in = base
+	in.Liquidity = big.NewInt(0)
+	_, err = Simulate(in)
+	assert.ErrorIs(t, err, ErrNoLiquidity)
+
+	in = base
+	in.GridPoints = maxGridPoints + 1
+	_, err = Simulate(in)
+	assert.ErrorIs(t, err, ErrInvalidGrid)
+
+	in = base
+	in.Prices = make([]*big.Float, MaxPricePoints+1)
+	for i := range in.Prices {
+		in.Prices[i] = big.NewFloat(1)
+	}
+	_, err = Simulate(in)
+	assert.ErrorIs(t, err, ErrTooManyPrices)
+}
+
+func TestSimulator_SimulatePosition_ManualPriceRange(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepo()
+	userID := uuid.New()
+	pos := ethUSDCPosition(userID)
+	require.NoError(t, repo.Create(ctx, pos))
+
+	prices := mockPrices{"WETH": big.NewInt(200_000_000_000), "USDC": big.NewInt(100_000_000)}
+	sim := NewSimulator(repo, mockWallets{pos.WalletID: {userID}}, prices, logger.New("development", io.Discard))
+
+	result, err := sim.SimulatePosition(ctx, userID, pos.ID, SimulationRequest{
+		PriceLower: mustPrice(t, "1500"),
+		PriceUpper: mustPrice(t, "2500"),
+	})
+	require.NoError(t, err)
+
+	// Current price comes from market prices; liquidity from remaining amounts
+	assert.Equal(t, "2000", FormatPrice(result.Current.Price))
+	assert.True(t, result.Current.InRange)
+	assert.True(t, result.Current.Amount0.Cmp(pos.TotalDepositedToken0) <= 0)
+	assert.True(t, result.Current.Amount1.Cmp(pos.TotalDepositedToken1) <= 0)
+	assert.Len(t, result.Curve, defaultGridPoints)
+}
+
+func TestSimulator_SimulatePosition_Errors(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepo()
+	userID := uuid.New()
+	pos := ethUSDCPosition(userID)
+	require.NoError(t, repo.Create(ctx, pos))
+	sim := NewSimulator(repo, mockWallets{pos.WalletID: {userID}}, mockPrices{}, logger.New("development", io.Discard))
+
+	_, err := sim.SimulatePosition(ctx, uuid.New(), pos.ID, SimulationRequest{})
+	assert.ErrorIs(t, err, ErrPositionNotFound)
+
+	_, err = sim.SimulatePosition(ctx, userID, pos.ID, SimulationRequest{})
+	assert.ErrorIs(t, err, ErrRangeRequired)
+
+	lower, upper := -200000, -199000
+	_, err = sim.SimulatePosition(ctx, userID, pos.ID, SimulationRequest{TickLower: &lower, TickUpper: &upper})
+	assert.ErrorIs(t, err, ErrPriceUnavailable)
+
+	_, err = sim.SimulatePosition(ctx, userID, pos.ID, SimulationRequest{Prices: make([]*big.Float, MaxPricePoints+1)})
+	assert.ErrorIs(t, err, ErrTooManyPrices)
+}
+
+func TestSimulator_SimulatePosition_UsesStoredRange(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockRepo()
+	userID := uuid.New()
+	pos := ethUSDCPosition(userID)
+	lower, upper := -201000, -199000
+	pos.TickLower, pos.TickUpper = &lower, &upper
+	require.NoError(t, repo.Create(ctx, pos))
+
+	prices := mockPrices{"WETH": big.NewInt(200_000_000_000), "USDC": big.NewInt(100_000_000)}
+	sim := NewSimulator(repo, mockWallets{pos.WalletID: {userID}}, prices, logger.New("development", io.Discard))
+
+	result, err := sim.SimulatePosition(ctx, userID, pos.ID, SimulationRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, PriceRange{TickLower: lower, TickUpper: upper}, result.Range)
+	require.NotNil(t, result.Position)
+	assert.Equal(t, pos.ID, result.Position.ID)
+}
@@ -0,0 +1,9 @@
+package privacy
+
+import "errors"
+
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrPasswordRequired = errors.New("password confirmation is required")
+	ErrInvalidPassword  = errors.New("invalid password")
+)
@@ -0,0 +1,41 @@
+package privacy
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Export section names, also used as file names inside the ZIP bundle
+const (
+	SectionProfile               = "profile"
+	SectionWallets               = "wallets"
+	SectionTransactions          = "transactions" // Includes ledger entries
+	SectionRawTransactions       = "raw_transactions"
+	SectionTaxLots               = "tax_lots"
+	SectionLotDisposals          = "lot_disposals"
+	SectionLotOverrides          = "lot_overrides"
+	SectionLPPositions           = "lp_positions"
+	SectionLendingPositions      = "lending_positions"
+	SectionStakingPositions      = "staking_positions"
+	SectionDerivativePositions   = "derivative_positions"
+	SectionTags                  = "tags" // Includes tagged transaction IDs
+	SectionTransactionNotes      = "transaction_notes"
+	SectionIncomeClassifications = "income_classifications" // Made by the user
+	SectionTaxProfile            = "tax_profile"
+	SectionAuditLog              = "audit_log"
+)
+
+// Section is one dataset of the export, already encoded as JSON
+type Section struct {
+	Name string
+	Data json.RawMessage
+}
+
+// Export is the complete set of data held about a user
+type Export struct {
+	UserID      uuid.UUID
+	GeneratedAt time.Time
+	Sections    []Section
+}
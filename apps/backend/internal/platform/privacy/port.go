@@ -0,0 +1,23 @@
+package privacy
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/user"
+)
+
+// Repository reads and erases everything stored about a user
+type Repository interface {
+	// ExportUserData returns every section of the user's data
+	ExportUserData(ctx context.Context, userID uuid.UUID) ([]Section, error)
+
+	// DeleteUserData removes the user and all dependent rows in a single transaction
+	DeleteUserData(ctx context.Context, userID uuid.UUID) error
+}
+
+// UserReader loads the account whose password confirms a deletion
+type UserReader interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*user.User, error)
+}
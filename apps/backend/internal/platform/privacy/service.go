@@ -0,0 +1,136 @@
+package privacy
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
+	"github.com/kislikjeka/moontrack/internal/platform/user"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// Service implements data export and account erasure
+type Service struct {
+	repo   Repository
+	users  UserReader
+	audit  audit.Recorder // optional
+	logger *logger.Logger
+}
+
+// NewService creates a new privacy service
+func NewService(repo Repository, users UserReader, recorder audit.Recorder, log *logger.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		users:  users,
+		audit:  recorder,
+		logger: log.WithField("component", "privacy"),
+	}
+}
+
+// Export collects all data held about the user
+func (s *Service) Export(ctx context.Context, userID uuid.UUID) (*Export, error) {
+	sections, err := s.repo.ExportUserData(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export user data: %w", err)
+	}
+
+	s.logger.Info("user data exported", "user_id", userID, "sections", len(sections))
+	s.record(ctx, userID, audit.ActionUserExport)
+	return &Export{
+		UserID:      userID,
+		GeneratedAt: time.Now().UTC(),
+		Sections:    sections,
+	}, nil
+}
+
+// DeleteAccount permanently erases the user after re-confirming their password
+func (s *Service) DeleteAccount(ctx context.Context, userID uuid.UUID, password string) error {
+	if password == "" {
+		return ErrPasswordRequired
+	}
+
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := u.CheckPassword(password); err != nil {
+		return ErrInvalidPassword
+	}
+
+	if err := s.repo.DeleteUserData(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete user data: %w", err)
+	}
+
+	s.logger.Info("user account deleted", "user_id", userID)
+	// Recorded after the erasure so the entry survives it; it names only the user ID
+	s.record(ctx, userID, audit.ActionUserDelete)
+	return nil
+}
+
+// record reports an export or erasure to the audit log, if one is configured
+func (s *Service) record(ctx context.Context, userID uuid.UUID, action audit.Action) {
+	if s.audit == nil {
+		return
+	}
+	s.audit.Record(ctx, audit.Event{
+		ActorID:    userID,
+		Action:     action,
+		TargetType: audit.TargetUser,
+		TargetID:   userID.String(),
+	})
+}
+
+// WriteJSON writes the export as a single JSON document keyed by section name
+func (e *Export) WriteJSON(w io.Writer) error {
+	doc := make(map[string]any, len(e.Sections)+2)
+	doc["user_id"] = e.UserID
+	doc["generated_at"] = e.GeneratedAt.Format(time.RFC3339)
+	for _, section := range e.Sections {
+		doc[section.Name] = section.Data
+	}
+	return json.NewEncoder(w).Encode(doc)
+}
+
+// WriteZip writes the export as a ZIP archive with one JSON file per section
+// and a manifest describing the bundle
+func (e *Export) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	manifest := struct {
+		UserID      uuid.UUID `json:"user_id"`
+		GeneratedAt string    `json:"generated_at"`
+		Files       []string  `json:"files"`
+	}{UserID: e.UserID, GeneratedAt: e.GeneratedAt.Format(time.RFC3339)}
+
+	for _, section := range e.Sections {
+		name := section.Name + ".json"
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: e.GeneratedAt})
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", name, err)
+		}
+		if _, err := f.Write(section.Data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		manifest.Files = append(manifest.Files, name)
+	}
+
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: "manifest.json", Method: zip.Deflate, Modified: e.GeneratedAt})
+	if err != nil {
+		return fmt.Errorf("failed to add manifest: %w", err)
+	}
+	if err := json.NewEncoder(f).Encode(manifest); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return zw.Close()
+}
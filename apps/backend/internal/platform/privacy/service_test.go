@@ -0,0 +1,132 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
+	"github.com/kislikjeka/moontrack/internal/platform/user"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+type mockRepo struct {
+	sections []Section
+	deleted  []uuid.UUID
+}
+
+func (r *mockRepo) ExportUserData(_ context.Context, _ uuid.UUID) ([]Section, error) {
+	return r.sections, nil
+}
+
+func (r *mockRepo) DeleteUserData(_ context.Context, userID uuid.UUID) error {
+	r.deleted = append(r.deleted, userID)
+	return nil
+}
+
+type mockUsers struct {
+	users map[uuid.UUID]*user.User
+}
+
+func (m *mockUsers) GetByID(_ context.Context, id uuid.UUID) (*user.User, error) {
+	u, ok := m.users[id]
+	if !ok {
+		return nil, user.ErrUserNotFound
+	}
+	return u, nil
+}
+
+type mockRecorder struct {
+	events []audit.Event
+}
+
+func (r *mockRecorder) Record(_ context.Context, event audit.Event) {
+	r.events = append(r.events, event)
+}
+
+func newTestService(t *testing.T) (*Service, *mockRepo, *mockRecorder, *user.User) {
+	t.Helper()
+	u := &user.User{ID: uuid.New(), Email: "a@example.com"}
+	require.NoError(t, u.SetPassword("correct-horse"))
+
+	repo := &mockRepo{sections: []Section{
+		{Name: SectionWallets, Data: json.RawMessage(`[{"id":"w1"}]`)},
+		{Name: SectionTransactions, Data: json.RawMessage(`[]`)},
+	}}
+	users := &mockUsers{users: map[uuid.UUID]*user.User{u.ID: u}}
+	recorder := &mockRecorder{}
+	return NewService(repo, users, recorder, logger.New("test", io.Discard)), repo, recorder, u
+}
+
+func TestDeleteAccount_RequiresPassword(t *testing.T) {
+	svc, repo, recorder, u := newTestService(t)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, svc.DeleteAccount(ctx, u.ID, ""), ErrPasswordRequired)
+	assert.ErrorIs(t, svc.DeleteAccount(ctx, u.ID, "wrong-password"), ErrInvalidPassword)
+	assert.ErrorIs(t, svc.DeleteAccount(ctx, uuid.New(), "correct-horse"), ErrUserNotFound)
+	assert.Empty(t, repo.deleted)
+	assert.Empty(t, recorder.events)
+
+	require.NoError(t, svc.DeleteAccount(ctx, u.ID, "correct-horse"))
+	assert.Equal(t, []uuid.UUID{u.ID}, repo.deleted)
+	require.Len(t, recorder.events, 1)
+	assert.Equal(t, audit.ActionUserDelete, recorder.events[0].Action)
+	assert.Equal(t, u.ID, recorder.events[0].ActorID)
+}
+
+func TestExport_WriteZip(t *testing.T) {
+	svc, _, _, u := newTestService(t)
+
+	export, err := svc.Export(context.Background(), u.ID)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteZip(&buf))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+
+	assert.JSONEq(t, `[{"id":"w1"}]`, files["wallets.json"])
+	assert.JSONEq(t, `[]`, files["transactions.json"])
+
+	var manifest struct {
+		UserID string   `json:"user_id"`
+		Files  []string `json:"files"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(files["manifest.json"]), &manifest))
+	assert.Equal(t, u.ID.String(), manifest.UserID)
+	assert.Equal(t, []string{"wallets.json", "transactions.json"}, manifest.Files)
+}
+
+func TestExport_WriteJSON(t *testing.T) {
+	svc, _, _, u := newTestService(t)
+
+	export, err := svc.Export(context.Background(), u.ID)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteJSON(&buf))
+
+	var doc map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.JSONEq(t, `[{"id":"w1"}]`, string(doc["wallets"]))
+	assert.Contains(t, doc, "generated_at")
+}
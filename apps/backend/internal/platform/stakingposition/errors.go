@@ -0,0 +1,5 @@
+package stakingposition
+
+import "errors"
+
+var ErrPositionNotFound = errors.New("staking position not found")
@@ -0,0 +1,79 @@
+package stakingposition
+
+import (
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusActive Status = "active"
+	StatusClosed Status = "closed"
+)
+
+// StakingPosition tracks an asset staked with one protocol. Native staking
+// keeps the asset itself in a staked account; liquid staking exchanges it for
+// a receipt token (stETH, rETH, ...) held in the wallet.
+type StakingPosition struct {
+	ID       uuid.UUID
+	UserID   uuid.UUID
+	WalletID uuid.UUID
+	ChainID  string
+	Protocol string
+
+	Asset         string
+	AssetDecimals int
+	StakedAmount  *big.Int // principal still staked, in asset units
+
+	ReceiptAsset    string // empty for native staking
+	ReceiptDecimals int
+	ReceiptContract string
+	ReceiptAmount   *big.Int // current receipt token balance
+	Rebasing        bool     // receipt balance grows as rewards accrue
+
+	TotalStaked      *big.Int
+	TotalUnstaked    *big.Int
+	TotalStakedUSD   *big.Int
+	TotalUnstakedUSD *big.Int
+
+	RewardsAmount *big.Int
+	RewardsUSD    *big.Int
+
+	Status   Status
+	OpenedAt time.Time
+	ClosedAt *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// IsLiquid returns true if the position is held as a receipt token.
+func (p *StakingPosition) IsLiquid() bool {
+	return p.ReceiptAsset != ""
+}
+
+// ShouldClose returns true once nothing remains staked: the receipt balance
+// for liquid staking, the principal for native staking.
+func (p *StakingPosition) ShouldClose() bool {
+	if p.IsLiquid() {
+		return p.ReceiptAmount.Sign() <= 0
+	}
+	return p.StakedAmount.Sign() <= 0
+}
+
+// rebasingTokens are liquid-staking receipts whose balance grows in place
+// instead of appreciating against the staked asset.
+var rebasingTokens = map[string]bool{
+	"STETH": true,
+	"EETH":  true,
+	"OETH":  true,
+}
+
+// IsRebasingToken reports whether a receipt token accrues rewards by rebasing.
+func IsRebasingToken(symbol string) bool {
+	return rebasingTokens[strings.ToUpper(symbol)]
+}
@@ -0,0 +1,17 @@
+package stakingposition
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	Create(ctx context.Context, pos *StakingPosition) error
+	Update(ctx context.Context, pos *StakingPosition) error
+	GetByID(ctx context.Context, id uuid.UUID) (*StakingPosition, error)
+	FindActiveByWalletAndAsset(ctx context.Context, walletID uuid.UUID, protocol, chainID, asset string) (*StakingPosition, error)
+	ListActiveByWallet(ctx context.Context, walletID uuid.UUID) ([]*StakingPosition, error)
+	ListByUser(ctx context.Context, userID uuid.UUID, status *Status, walletID *uuid.UUID, chainID *string) ([]*StakingPosition, error)
+	ListByWallets(ctx context.Context, walletIDs []uuid.UUID, status *Status, chainID *string) ([]*StakingPosition, error)
+}
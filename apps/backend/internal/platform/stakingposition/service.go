@@ -0,0 +1,234 @@
+package stakingposition
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+type Service struct {
+	repo    Repository
+	wallets wallet.Reader
+	logger  *logger.Logger
+}
+
+func NewService(repo Repository, wallets wallet.Reader, log *logger.Logger) *Service {
+	return &Service{
+		repo:    repo,
+		wallets: wallets,
+		logger:  log.WithField("component", "stakingposition"),
+	}
+}
+
+// FindOrCreate looks up an active staking position by wallet+protocol+chain+asset,
+// or creates a new one. receiptAsset is empty for native staking.
+func (s *Service) FindOrCreate(
+	ctx context.Context,
+	userID, walletID uuid.UUID,
+	protocol, chainID, asset string, assetDecimals int,
+	receiptAsset string, receiptDecimals int, receiptContract string,
+	openedAt time.Time,
+) (*StakingPosition, error) {
+	existing, err := s.repo.FindActiveByWalletAndAsset(ctx, walletID, protocol, chainID, asset)
+	if err != nil {
+		return nil, fmt.Errorf("find active position: %w", err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	pos := &StakingPosition{
+		ID:       uuid.New(),
+		UserID:   userID,
+		WalletID: walletID,
+		ChainID:  chainID,
+		Protocol: protocol,
+
+		Asset:         asset,
+		AssetDecimals: assetDecimals,
+		StakedAmount:  big.NewInt(0),
+
+		ReceiptAsset:    receiptAsset,
+		ReceiptDecimals: receiptDecimals,
+		ReceiptContract: receiptContract,
+		ReceiptAmount:   big.NewInt(0),
+		Rebasing:        IsRebasingToken(receiptAsset),
+
+		TotalStaked:      big.NewInt(0),
+		TotalUnstaked:    big.NewInt(0),
+		TotalStakedUSD:   big.NewInt(0),
+		TotalUnstakedUSD: big.NewInt(0),
+
+		RewardsAmount: big.NewInt(0),
+		RewardsUSD:    big.NewInt(0),
+
+		Status:   StatusActive,
+		OpenedAt: openedAt,
+
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	if err := s.repo.Create(ctx, pos); err != nil {
+		return nil, fmt.Errorf("create position: %w", err)
+	}
+
+	s.logger.Info("staking position created",
+		"position_id", pos.ID,
+		"protocol", protocol,
+		"asset", asset,
+		"receipt_asset", receiptAsset,
+	)
+
+	return pos, nil
+}
+
+// RecordStake adds to the staked principal. receiptAmount is the receipt
+// token minted for liquid staking and zero for native staking.
+func (s *Service) RecordStake(ctx context.Context, positionID uuid.UUID, amount, usdValue, receiptAmount *big.Int) error {
+	pos, err := s.getPosition(ctx, positionID)
+	if err != nil {
+		return err
+	}
+
+	pos.StakedAmount.Add(pos.StakedAmount, amount)
+	pos.ReceiptAmount.Add(pos.ReceiptAmount, receiptAmount)
+	pos.TotalStaked.Add(pos.TotalStaked, amount)
+	pos.TotalStakedUSD.Add(pos.TotalStakedUSD, usdValue)
+	pos.UpdatedAt = time.Now().UTC()
+
+	return s.repo.Update(ctx, pos)
+}
+
+// RecordUnstake subtracts the returned asset from the principal and the
+// burned receipt token from the receipt balance. May close position.
+//
+// Redemptions include accrued rewards, so the principal never goes below zero.
+func (s *Service) RecordUnstake(ctx context.Context, positionID uuid.UUID, amount, usdValue, receiptAmount *big.Int) error {
+	pos, err := s.getPosition(ctx, positionID)
+	if err != nil {
+		return err
+	}
+
+	pos.StakedAmount.Sub(pos.StakedAmount, amount)
+	if pos.StakedAmount.Sign() < 0 {
+		pos.StakedAmount.SetInt64(0)
+	}
+	pos.ReceiptAmount.Sub(pos.ReceiptAmount, receiptAmount)
+	pos.TotalUnstaked.Add(pos.TotalUnstaked, amount)
+	pos.TotalUnstakedUSD.Add(pos.TotalUnstakedUSD, usdValue)
+	pos.UpdatedAt = time.Now().UTC()
+
+	if pos.ShouldClose() {
+		s.closePosition(pos)
+	}
+
+	return s.repo.Update(ctx, pos)
+}
+
+// RecordReward adds to rewards earned. Rebasing rewards are paid in the
+// receipt token and also raise the receipt balance.
+func (s *Service) RecordReward(ctx context.Context, positionID uuid.UUID, amount, usdValue *big.Int) error {
+	pos, err := s.getPosition(ctx, positionID)
+	if err != nil {
+		return err
+	}
+
+	pos.RewardsAmount.Add(pos.RewardsAmount, amount)
+	pos.RewardsUSD.Add(pos.RewardsUSD, usdValue)
+	if pos.Rebasing {
+		pos.ReceiptAmount.Add(pos.ReceiptAmount, amount)
+	}
+	pos.UpdatedAt = time.Now().UTC()
+
+	return s.repo.Update(ctx, pos)
+}
+
+// ListActiveRebasing returns a wallet's active positions held in a rebasing
+// receipt token.
+func (s *Service) ListActiveRebasing(ctx context.Context, walletID uuid.UUID) ([]*StakingPosition, error) {
+	positions, err := s.repo.ListActiveByWallet(ctx, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("list active positions: %w", err)
+	}
+
+	var result []*StakingPosition
+	for _, pos := range positions {
+		if pos.Rebasing {
+			result = append(result, pos)
+		}
+	}
+	return result, nil
+}
+
+// GetByID returns a position by ID.
+func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*StakingPosition, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListByUser returns positions for a user, with optional filters.
+func (s *Service) ListByUser(ctx context.Context, userID uuid.UUID, status *Status, walletID *uuid.UUID, chainID *string) ([]*StakingPosition, error) {
+	return s.repo.ListByUser(ctx, userID, status, walletID, chainID)
+}
+
+// GetAccessible returns a position on a wallet the user can view. Positions
+// the user cannot view read as ErrPositionNotFound.
+func (s *Service) GetAccessible(ctx context.Context, userID, id uuid.UUID) (*StakingPosition, error) {
+	pos, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get position: %w", err)
+	}
+	if pos == nil {
+		return nil, ErrPositionNotFound
+	}
+
+	ok, err := wallet.CanView(ctx, s.wallets, pos.WalletID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("check wallet access: %w", err)
+	}
+	if !ok {
+		return nil, ErrPositionNotFound
+	}
+	return pos, nil
+}
+
+// ListAccessible returns positions on the wallets the user can view through
+// their workspaces, with optional filters.
+func (s *Service) ListAccessible(ctx context.Context, userID uuid.UUID, status *Status, walletID *uuid.UUID, chainID *string) ([]*StakingPosition, error) {
+	walletIDs, err := wallet.AccessibleIDs(ctx, s.wallets, userID, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve accessible wallets: %w", err)
+	}
+	if len(walletIDs) == 0 {
+		return nil, nil
+	}
+	return s.repo.ListByWallets(ctx, walletIDs, status, chainID)
+}
+
+func (s *Service) getPosition(ctx context.Context, id uuid.UUID) (*StakingPosition, error) {
+	pos, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get position: %w", err)
+	}
+	if pos == nil {
+		return nil, fmt.Errorf("position not found: %s", id)
+	}
+	return pos, nil
+}
+
+func (s *Service) closePosition(pos *StakingPosition) {
+	now := time.Now().UTC()
+	pos.Status = StatusClosed
+	pos.ClosedAt = &now
+
+	s.logger.Info("staking position closed",
+		"position_id", pos.ID,
+		"rewards_usd", pos.RewardsUSD,
+	)
+}
@@ -0,0 +1,228 @@
+package stakingposition
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// mockRepo is an in-memory implementation of Repository for testing.
+type mockRepo struct {
+	positions map[uuid.UUID]*StakingPosition
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{positions: make(map[uuid.UUID]*StakingPosition)}
+}
+
+func (r *mockRepo) Create(_ context.Context, pos *StakingPosition) error {
+	r.positions[pos.ID] = pos
+	return nil
+}
+
+func (r *mockRepo) Update(_ context.Context, pos *StakingPosition) error {
+	r.positions[pos.ID] = pos
+	return nil
+}
+
+func (r *mockRepo) GetByID(_ context.Context, id uuid.UUID) (*StakingPosition, error) {
+	pos, ok := r.positions[id]
+	if !ok {
+		return nil, nil
+	}
+	return pos, nil
+}
+
+func (r *mockRepo) FindActiveByWalletAndAsset(_ context.Context, walletID uuid.UUID, protocol, chainID, asset string) (*StakingPosition, error) {
+	for _, pos := range r.positions {
+		if pos.WalletID == walletID && pos.Protocol == protocol && pos.ChainID == chainID &&
+			pos.Asset == asset && pos.Status == StatusActive {
+			return pos, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *mockRepo) ListActiveByWallet(_ context.Context, walletID uuid.UUID) ([]*StakingPosition, error) {
+	var result []*StakingPosition
+	for _, pos := range r.positions {
+		if pos.WalletID == walletID && pos.Status == StatusActive {
+			result = append(result, pos)
+		}
+	}
+	return result, nil
+}
+
+func (r *mockRepo) ListByUser(_ context.Context, userID uuid.UUID, status *Status, walletID *uuid.UUID, chainID *string) ([]*StakingPosition, error) {
+	var result []*StakingPosition
+	for _, pos := range r.positions {
+		if pos.UserID != userID {
+			continue
+		}
+		if status != nil && pos.Status != *status {
+			continue
+		}
+		if walletID != nil && pos.WalletID != *walletID {
+			continue
+		}
+		if chainID != nil && pos.ChainID != *chainID {
+			continue
+		}
+		result = append(result, pos)
+	}
+	return result, nil
+}
+
+func (r *mockRepo) ListByWallets(_ context.Context, walletIDs []uuid.UUID, status *Status, chainID *string) ([]*StakingPosition, error) {
+	var result []*StakingPosition
+	for _, pos := range r.positions {
+		if !slices.Contains(walletIDs, pos.WalletID) {
+			continue
+		}
+		if status != nil && pos.Status != *status {
+			continue
+		}
+		if chainID != nil && pos.ChainID != *chainID {
+			continue
+		}
+		result = append(result, pos)
+	}
+	return result, nil
+}
+
+// mockWallets maps each wallet to the users who can view it
+type mockWallets map[uuid.UUID][]uuid.UUID
+
+func (m mockWallets) GetByID(_ context.Context, id, userID uuid.UUID) (*wallet.Wallet, error) {
+	viewers, ok := m[id]
+	if !ok {
+		return nil, wallet.ErrWalletNotFound
+	}
+	if !slices.Contains(viewers, userID) {
+		return nil, wallet.ErrUnauthorizedAccess
+	}
+	return &wallet.Wallet{ID: id}, nil
+}
+
+func (m mockWallets) List(_ context.Context, userID uuid.UUID) ([]*wallet.Wallet, error) {
+	var result []*wallet.Wallet
+	for id, viewers := range m {
+		if slices.Contains(viewers, userID) {
+			result = append(result, &wallet.Wallet{ID: id})
+		}
+	}
+	return result, nil
+}
+
+func newTestService() (*Service, *mockRepo) {
+	repo := newMockRepo()
+	log := logger.New("test", io.Discard)
+	svc := NewService(repo, mockWallets{}, log)
+	return svc, repo
+}
+
+func TestFindOrCreate_LiquidStaking(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+	userID, walletID := uuid.New(), uuid.New()
+
+	pos, err := svc.FindOrCreate(ctx, userID, walletID, "Lido", "ethereum", "ETH", 18, "stETH", 18, "0xsteth", time.Now())
+	require.NoError(t, err)
+	assert.True(t, pos.IsLiquid())
+	assert.True(t, pos.Rebasing, "stETH rebases")
+
+	again, err := svc.FindOrCreate(ctx, userID, walletID, "Lido", "ethereum", "ETH", 18, "stETH", 18, "0xsteth", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, pos.ID, again.ID, "should return existing position")
+
+	native, err := svc.FindOrCreate(ctx, userID, walletID, "Cosmos Hub", "cosmos", "ATOM", 6, "", 0, "", time.Now())
+	require.NoError(t, err)
+	assert.False(t, native.IsLiquid())
+	assert.False(t, native.Rebasing)
+}
+
+func TestRecordReward_RebasingRaisesReceiptBalance(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := context.Background()
+
+	pos, err := svc.FindOrCreate(ctx, uuid.New(), uuid.New(), "Lido", "ethereum", "ETH", 18, "stETH", 18, "", time.Now())
+	require.NoError(t, err)
+	require.NoError(t, svc.RecordStake(ctx, pos.ID, big.NewInt(1000), big.NewInt(300), big.NewInt(1000)))
+	require.NoError(t, svc.RecordReward(ctx, pos.ID, big.NewInt(40), big.NewInt(12)))
+
+	updated := repo.positions[pos.ID]
+	assert.Equal(t, big.NewInt(1000), updated.StakedAmount)
+	assert.Equal(t, big.NewInt(1040), updated.ReceiptAmount)
+	assert.Equal(t, big.NewInt(40), updated.RewardsAmount)
+	assert.Equal(t, big.NewInt(12), updated.RewardsUSD)
+
+	rebasing, err := svc.ListActiveRebasing(ctx, pos.WalletID)
+	require.NoError(t, err)
+	require.Len(t, rebasing, 1)
+
+	// Redeeming the whole receipt balance returns principal plus rewards
+	require.NoError(t, svc.RecordUnstake(ctx, pos.ID, big.NewInt(1040), big.NewInt(320), big.NewInt(1040)))
+	assert.Equal(t, StatusClosed, updated.Status)
+	assert.NotNil(t, updated.ClosedAt)
+	assert.Equal(t, 0, updated.StakedAmount.Sign())
+}
+
+func TestRecordUnstake_NativePartial(t *testing.T) {
+	svc, repo := newTestService()
+	ctx := context.Background()
+
+	pos, err := svc.FindOrCreate(ctx, uuid.New(), uuid.New(), "Solana", "solana", "SOL", 9, "", 0, "", time.Now())
+	require.NoError(t, err)
+	require.NoError(t, svc.RecordStake(ctx, pos.ID, big.NewInt(500), big.NewInt(50), big.NewInt(0)))
+	require.NoError(t, svc.RecordReward(ctx, pos.ID, big.NewInt(5), big.NewInt(1)))
+	require.NoError(t, svc.RecordUnstake(ctx, pos.ID, big.NewInt(200), big.NewInt(25), big.NewInt(0)))
+
+	updated := repo.positions[pos.ID]
+	assert.Equal(t, big.NewInt(300), updated.StakedAmount)
+	assert.Equal(t, 0, updated.ReceiptAmount.Sign(), "native rewards do not touch the receipt balance")
+	assert.Equal(t, StatusActive, updated.Status)
+}
+
+func TestGetPosition_NotFound(t *testing.T) {
+	svc, _ := newTestService()
+
+	err := svc.RecordStake(context.Background(), uuid.New(), big.NewInt(1), big.NewInt(1), big.NewInt(0))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "position not found")
+}
+
+func TestListAccessible_IncludesSharedWalletPositions(t *testing.T) {
+	repo := newMockRepo()
+	ctx := context.Background()
+	owner, member, outsider, walletID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	svc := NewService(repo, mockWallets{walletID: {owner, member}}, logger.New("test", io.Discard))
+
+	pos, err := svc.FindOrCreate(ctx, owner, walletID, "Lido", "ethereum", "ETH", 18, "stETH", 18, "0xsteth", time.Now())
+	require.NoError(t, err)
+
+	positions, err := svc.ListAccessible(ctx, member, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	assert.Equal(t, pos.ID, positions[0].ID)
+
+	got, err := svc.GetAccessible(ctx, member, pos.ID)
+	require.NoError(t, err)
+	assert.Equal(t, pos.ID, got.ID)
+
+	positions, err = svc.ListAccessible(ctx, outsider, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, positions)
+
+	_, err = svc.GetAccessible(ctx, outsider, pos.ID)
+	assert.ErrorIs(t, err, ErrPositionNotFound)
+}
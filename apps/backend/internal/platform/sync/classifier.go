@@ -1,6 +1,10 @@
 package sync
 
-import "github.com/kislikjeka/moontrack/internal/ledger"
+import (
+	"strings"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+)
 
 // Classifier maps decoded blockchain transactions to ledger transaction types
 type Classifier struct{}
@@ -11,26 +15,45 @@ func NewClassifier() *Classifier {
 }
 
 // Classify determines the ledger TransactionType for a decoded transaction.
-// Returns empty string for transactions that should be skipped (e.g. approve, NFT-only).
+// Returns empty string for transactions that should be skipped (e.g. approve, no transfers).
 func (c *Classifier) Classify(tx DecodedTransaction) ledger.TransactionType {
-	if len(tx.Transfers) == 0 && tx.OperationType != OpApprove {
-		return "" // no fungible transfers to process (e.g., NFT-only transaction)
+	if len(tx.Transfers) == 0 && len(tx.NFTTransfers) == 0 && tx.OperationType != OpApprove {
+		return "" // no token movements to process
 	}
 
-	// Uniswap V3 LP-specific classification
-	if c.isUniswapV3(tx.Protocol) {
-		if lpType := c.classifyLP(tx); lpType != "" {
+	// DEX liquidity provision, per the LP protocol registry
+	if proto, ok := lpProtocolFor(tx); ok {
+		if lpType := c.classifyLP(tx, proto); lpType != "" {
 			return lpType
 		}
 	}
 
-	// AAVE lending protocol
-	if c.isAAVE(tx.Protocol) {
-		if lt := c.classifyLending(tx); lt != "" {
+	// NFT trades, mints and transfers (LP position NFTs are handled above)
+	if len(tx.NFTTransfers) > 0 {
+		return c.classifyNFT(tx)
+	}
+
+	// Lending markets, per the lending protocol registry
+	if proto, ok := lendingProtocolFor(tx); ok {
+		if lt := c.classifyLending(tx, proto); lt != "" {
 			return lt
 		}
 	}
 
+	// Native and liquid staking protocols
+	if c.isStaking(tx.Protocol) {
+		if st := c.classifyStaking(tx); st != "" {
+			return st
+		}
+	}
+
+	// Perpetuals margin, per the derivatives protocol registry
+	if proto, ok := derivativesProtocolFor(tx); ok {
+		if dt := c.classifyDerivative(tx, proto); dt != "" {
+			return dt
+		}
+	}
+
 	switch tx.OperationType {
 	case OpTrade:
 		return ledger.TxTypeSwap
@@ -57,18 +80,19 @@ func (c *Classifier) Classify(tx DecodedTransaction) ledger.TransactionType {
 	}
 }
 
-func (c *Classifier) isUniswapV3(protocol string) bool {
-	return protocol == "Uniswap V3"
-}
-
-func (c *Classifier) classifyLP(tx DecodedTransaction) ledger.TransactionType {
+func (c *Classifier) classifyLP(tx DecodedTransaction, proto LPProtocol) ledger.TransactionType {
 	switch tx.OperationType {
 	case OpDeposit, OpMint:
 		return ledger.TxTypeLPDeposit
 	case OpWithdraw, OpBurn:
 		return ledger.TxTypeLPWithdraw
+	case OpClaim:
+		if proto.FeeClaims {
+			return ledger.TxTypeLPClaimFees
+		}
+		return "" // incentive rewards stay generic DeFi claims
 	case OpReceive:
-		if c.hasClaimAct(tx.Acts) {
+		if proto.FeeClaims && c.hasClaimAct(tx.Acts) {
 			return ledger.TxTypeLPClaimFees
 		}
 		return "" // fall through to default classification
@@ -77,6 +101,33 @@ func (c *Classifier) classifyLP(tx DecodedTransaction) ledger.TransactionType {
 	}
 }
 
+// classifyDerivative maps a single margin transfer to opening (margin out)
+// or closing (margin back in) a position. Funding claims count only where the
+// protocol pays funding out separately; other claims (GMX staking rewards)
+// stay generic DeFi claims. The protocol's own tokens and anything moving
+// more than one asset, such as buying into a liquidity pool, fall through to
+// default classification.
+func (c *Classifier) classifyDerivative(tx DecodedTransaction, proto DerivativesProtocol) ledger.TransactionType {
+	if len(tx.Transfers) != 1 || proto.isProtocolToken(tx.Transfers[0].AssetSymbol) {
+		return ""
+	}
+	if tx.OperationType == OpClaim {
+		if proto.FundingClaims && tx.Transfers[0].Direction == DirectionIn {
+			return ledger.TxTypeDerivativeFunding
+		}
+		return ""
+	}
+
+	switch tx.Transfers[0].Direction {
+	case DirectionOut:
+		return ledger.TxTypeDerivativeOpen
+	case DirectionIn:
+		return ledger.TxTypeDerivativeClose
+	default:
+		return ""
+	}
+}
+
 func (c *Classifier) hasClaimAct(acts []string) bool {
 	for _, act := range acts {
 		if act == "claim" {
@@ -116,49 +167,227 @@ func (c *Classifier) classifyExecute(tx DecodedTransaction) ledger.TransactionTy
 	}
 }
 
-func (c *Classifier) isAAVE(protocol string) bool {
-	return protocol == "AAVE" || protocol == "Aave" || protocol == "Aave V3" || protocol == "Aave V2"
-}
+// classifyLending maps lending operations to supply/withdraw/borrow/repay/claim
+// and liquidation. The operation type decides when it names a lending
+// operation, then the transaction's acts; otherwise the type is inferred from
+// the transfers.
+func (c *Classifier) classifyLending(tx DecodedTransaction, proto LendingProtocol) ledger.TransactionType {
+	if isLendingLiquidation(tx, proto) {
+		return ledger.TxTypeLendingLiquidation
+	}
 
-func (c *Classifier) classifyLending(tx DecodedTransaction) ledger.TransactionType {
-	switch tx.OperationType {
+	op := tx.OperationType
+	switch op {
+	case OpDeposit, OpMint, OpWithdraw, OpBurn, OpClaim, OpBorrow, OpRepay:
+	default:
+		op = lendingActOperation(tx.Acts)
+	}
+
+	switch op {
 	case OpDeposit, OpMint:
 		return ledger.TxTypeLendingSupply
 	case OpWithdraw, OpBurn:
 		return ledger.TxTypeLendingWithdraw
 	case OpClaim:
 		return ledger.TxTypeLendingClaim
-	case OpReceive:
-		if c.hasClaimAct(tx.Acts) {
-			return ledger.TxTypeLendingClaim
-		}
+	case OpBorrow:
 		return ledger.TxTypeLendingBorrow
-	case OpSend:
+	case OpRepay:
 		return ledger.TxTypeLendingRepay
 	default:
-		return c.classifyLendingFromTransfers(tx)
+		return c.classifyLendingFromTransfers(tx, proto)
 	}
 }
 
-func (c *Classifier) classifyLendingFromTransfers(tx DecodedTransaction) ledger.TransactionType {
-	hasIn := false
-	hasOut := false
+// isLendingLiquidation reports whether someone else liquidated the wallet's
+// position. Zerion may name the act; otherwise a liquidation shows up as
+// receipt tokens leaving the wallet in a transaction it did not send (no
+// fee), with no underlying asset moving. On protocols with debt tokens the
+// repaid debt must burn too, so a plain receipt transfer is not mistaken for
+// one.
+func isLendingLiquidation(tx DecodedTransaction, proto LendingProtocol) bool {
+	for _, act := range tx.Acts {
+		if lendingLiquidationActs[act] {
+			return true
+		}
+	}
+	if tx.Fee != nil {
+		return false
+	}
+
+	var receiptOut, debtOut bool
 	for _, t := range tx.Transfers {
-		if t.Direction == DirectionIn {
-			hasIn = true
+		switch {
+		case !proto.isReceiptToken(t.AssetSymbol):
+			return false
+		case t.Direction != DirectionOut:
+		case proto.isDebtToken(t.AssetSymbol):
+			debtOut = true
+		default:
+			receiptOut = true
 		}
-		if t.Direction == DirectionOut {
-			hasOut = true
+	}
+	return receiptOut && (debtOut || len(proto.DebtPrefixes) == 0)
+}
+
+// classifyLendingFromTransfers infers a lending operation from the directions
+// of the underlying assets and of the protocol's receipt tokens. A supply
+// sends the asset and mints the receipt (aToken, cToken); a withdrawal burns
+// the receipt and returns the asset. Debt tokens move with the borrowed asset,
+// so they are ignored like receipts.
+func (c *Classifier) classifyLendingFromTransfers(tx DecodedTransaction, proto LendingProtocol) ledger.TransactionType {
+	var assetIn, assetOut, receiptIn, receiptOut bool
+	for _, t := range tx.Transfers {
+		receipt := proto.isReceiptToken(t.AssetSymbol)
+		switch {
+		case t.Direction == DirectionIn && receipt:
+			receiptIn = true
+		case t.Direction == DirectionIn:
+			assetIn = true
+		case t.Direction == DirectionOut && receipt:
+			receiptOut = true
+		case t.Direction == DirectionOut:
+			assetOut = true
 		}
 	}
-	if hasIn && !hasOut {
+
+	switch {
+	case assetOut && receiptIn:
+		return ledger.TxTypeLendingSupply
+	case assetIn && receiptOut:
+		return ledger.TxTypeLendingWithdraw
+	case assetIn && assetOut:
+		return ledger.TxTypeLendingSupply
+	case assetIn:
 		return ledger.TxTypeLendingBorrow
-	}
-	if hasOut && !hasIn {
+	case assetOut:
 		return ledger.TxTypeLendingRepay
+	default:
+		return "" // only receipt tokens moved, e.g. an aToken transfer
 	}
-	if hasIn && hasOut {
-		return ledger.TxTypeLendingSupply
+}
+
+// stakingProtocols are the staking protocols recognised by name
+var stakingProtocols = map[string]bool{
+	"Lido":           true,
+	"Rocket Pool":    true,
+	"Frax Ether":     true,
+	"StakeWise":      true,
+	"Stader":         true,
+	"ether.fi":       true,
+	"Swell":          true,
+	"Origin Ether":   true,
+	"Mantle Staking": true,
+	"Ethereum 2.0":   true,
+}
+
+// liquidStakingTokens are receipt tokens minted by liquid-staking protocols
+var liquidStakingTokens = map[string]bool{
+	"STETH":   true,
+	"WSTETH":  true,
+	"RETH":    true,
+	"CBETH":   true,
+	"FRXETH":  true,
+	"SFRXETH": true,
+	"OSETH":   true,
+	"ETHX":    true,
+	"EETH":    true,
+	"WEETH":   true,
+	"SWETH":   true,
+	"OETH":    true,
+	"METH":    true,
+}
+
+func (c *Classifier) isStaking(protocol string) bool {
+	return stakingProtocols[protocol]
+}
+
+// isLiquidStakingToken reports whether the symbol is a liquid-staking receipt token
+func isLiquidStakingToken(symbol string) bool {
+	return liquidStakingTokens[strings.ToUpper(symbol)]
+}
+
+// classifyStaking maps staking operations to stake/unstake/staking_reward.
+// A stake must send the staked asset and an unstake must return it; anything
+// else (e.g. a withdrawal request that only burns the receipt token) falls
+// through to the default classification.
+func (c *Classifier) classifyStaking(tx DecodedTransaction) ledger.TransactionType {
+	var assetIn, assetOut, receiptIn, receiptOut bool
+	for _, t := range tx.Transfers {
+		receipt := isLiquidStakingToken(t.AssetSymbol)
+		switch {
+		case t.Direction == DirectionIn && receipt:
+			receiptIn = true
+		case t.Direction == DirectionIn:
+			assetIn = true
+		case t.Direction == DirectionOut && receipt:
+			receiptOut = true
+		case t.Direction == DirectionOut:
+			assetOut = true
+		}
+	}
+
+	switch tx.OperationType {
+	case OpDeposit, OpMint:
+		if assetOut {
+			return ledger.TxTypeStake
+		}
+	case OpWithdraw, OpBurn:
+		if assetIn {
+			return ledger.TxTypeUnstake
+		}
+	case OpTrade:
+		if assetOut && receiptIn {
+			return ledger.TxTypeStake
+		}
+		if receiptOut && assetIn {
+			return ledger.TxTypeUnstake
+		}
+	case OpClaim:
+		if (assetIn || receiptIn) && !assetOut && !receiptOut {
+			return ledger.TxTypeStakingReward
+		}
+	case OpReceive:
+		if c.hasClaimAct(tx.Acts) {
+			return ledger.TxTypeStakingReward
+		}
 	}
 	return ""
 }
+
+// classifyNFT maps transactions that move NFTs. Fungible tokens leaving the
+// wallet alongside an incoming NFT are its price; fungible tokens arriving
+// alongside an outgoing NFT are the sale proceeds.
+func (c *Classifier) classifyNFT(tx DecodedTransaction) ledger.TransactionType {
+	var nftIn, nftOut bool
+	for _, t := range tx.NFTTransfers {
+		if t.Direction == DirectionIn {
+			nftIn = true
+		} else {
+			nftOut = true
+		}
+	}
+	var paid, received bool
+	for _, t := range tx.Transfers {
+		if t.Direction == DirectionIn {
+			received = true
+		} else {
+			paid = true
+		}
+	}
+
+	switch {
+	case nftIn && nftOut:
+		return "" // NFT-for-NFT swaps have no fungible price to value them by
+	case nftIn && tx.OperationType == OpMint:
+		return ledger.TxTypeNFTMint
+	case nftIn && paid:
+		return ledger.TxTypeNFTBuy
+	case nftIn:
+		return ledger.TxTypeNFTTransferIn
+	case nftOut && received:
+		return ledger.TxTypeNFTSell
+	default:
+		return ledger.TxTypeNFTTransferOut
+	}
+}
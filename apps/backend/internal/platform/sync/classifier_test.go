@@ -173,11 +173,97 @@ func TestClassify_AaveDeposit_IsLendingSupply(t *testing.T) {
 	assert.Equal(t, ledger.TxTypeLendingSupply, c.Classify(tx))
 }
 
-func TestClassify_NonAAVEDeposit_StaysDeFi(t *testing.T) {
+func TestClassify_CompoundCTokenMint_IsLendingSupply(t *testing.T) {
 	c := sync.NewClassifier()
 	tx := sync.DecodedTransaction{
-		OperationType: sync.OpDeposit,
+		OperationType: sync.OpTrade,
 		Protocol:      "Compound",
+		Transfers: []sync.DecodedTransfer{
+			{Direction: sync.DirectionOut, AssetSymbol: "USDC", Amount: big.NewInt(1)},
+			{Direction: sync.DirectionIn, AssetSymbol: "cUSDC", Amount: big.NewInt(1)},
+		},
+	}
+	assert.Equal(t, ledger.TxTypeLendingSupply, c.Classify(tx))
+}
+
+func TestClassify_CompoundCTokenRedeem_IsLendingWithdraw(t *testing.T) {
+	c := sync.NewClassifier()
+	tx := sync.DecodedTransaction{
+		OperationType: sync.OpTrade,
+		Protocol:      "Compound",
+		Transfers: []sync.DecodedTransfer{
+			{Direction: sync.DirectionOut, AssetSymbol: "cETH", Amount: big.NewInt(1)},
+			{Direction: sync.DirectionIn, AssetSymbol: "ETH", Amount: big.NewInt(1)},
+		},
+	}
+	assert.Equal(t, ledger.TxTypeLendingWithdraw, c.Classify(tx))
+}
+
+func TestClassify_CompoundCbETHIsNotAReceipt(t *testing.T) {
+	c := sync.NewClassifier()
+	tx := sync.DecodedTransaction{
+		OperationType: sync.OpSend,
+		Protocol:      "Compound V3",
+		Transfers:     []sync.DecodedTransfer{{Direction: sync.DirectionOut, AssetSymbol: "cbETH", Amount: big.NewInt(1)}},
+	}
+	assert.Equal(t, ledger.TxTypeLendingRepay, c.Classify(tx))
+}
+
+func TestClassify_MorphoActDecides(t *testing.T) {
+	c := sync.NewClassifier()
+	tx := sync.DecodedTransaction{
+		OperationType: sync.OpExecute,
+		Protocol:      "Morpho",
+		Acts:          []string{"deposit"},
+		Transfers:     []sync.DecodedTransfer{{Direction: sync.DirectionOut, AssetSymbol: "WETH", Amount: big.NewInt(1)}},
+	}
+	assert.Equal(t, ledger.TxTypeLendingSupply, c.Classify(tx))
+}
+
+func TestClassify_ReceiptTokenTransfer_StaysTransfer(t *testing.T) {
+	c := sync.NewClassifier()
+	tx := sync.DecodedTransaction{
+		OperationType: sync.OpSend,
+		Protocol:      "Spark",
+		Transfers:     []sync.DecodedTransfer{{Direction: sync.DirectionOut, AssetSymbol: "spWETH", Amount: big.NewInt(1)}},
+	}
+	assert.Equal(t, ledger.TxTypeTransferOut, c.Classify(tx))
+}
+
+func TestClassify_AaveLiquidation(t *testing.T) {
+	c := sync.NewClassifier()
+	// The liquidator sent the transaction, so the wallet paid no fee
+	tx := sync.DecodedTransaction{
+		OperationType: sync.OpSend,
+		Protocol:      "Aave V3",
+		Transfers: []sync.DecodedTransfer{
+			{Direction: sync.DirectionOut, AssetSymbol: "aEthWETH", Amount: big.NewInt(1)},
+			{Direction: sync.DirectionOut, AssetSymbol: "variableDebtEthUSDC", Amount: big.NewInt(1)},
+		},
+	}
+	assert.Equal(t, ledger.TxTypeLendingLiquidation, c.Classify(tx))
+
+	// The same burns in a transaction the wallet sent are not a liquidation
+	tx.Fee = &sync.DecodedFee{AssetSymbol: "ETH", Amount: big.NewInt(1), Decimals: 18}
+	assert.NotEqual(t, ledger.TxTypeLendingLiquidation, c.Classify(tx))
+}
+
+func TestClassify_LiquidationActDecides(t *testing.T) {
+	c := sync.NewClassifier()
+	tx := sync.DecodedTransaction{
+		OperationType: sync.OpExecute,
+		Protocol:      "Morpho Blue",
+		Acts:          []string{"liquidate"},
+		Transfers:     []sync.DecodedTransfer{{Direction: sync.DirectionOut, AssetSymbol: "WETH", Amount: big.NewInt(1)}},
+	}
+	assert.Equal(t, ledger.TxTypeLendingLiquidation, c.Classify(tx))
+}
+
+func TestClassify_NonLendingDeposit_StaysDeFi(t *testing.T) {
+	c := sync.NewClassifier()
+	tx := sync.DecodedTransaction{
+		OperationType: sync.OpDeposit,
+		Protocol:      "Yearn",
 		Transfers:     []sync.DecodedTransfer{{Direction: sync.DirectionOut, AssetSymbol: "ETH", Amount: big.NewInt(1)}},
 	}
 	assert.Equal(t, ledger.TxTypeDefiDeposit, c.Classify(tx))
@@ -193,3 +279,162 @@ func TestClassify_ReceiveNonClaim_StaysTransferIn(t *testing.T) {
 	}
 	assert.Equal(t, ledger.TxTypeTransferIn, c.Classify(tx))
 }
+
+func TestClassify_LidoDeposit_IsStake(t *testing.T) {
+	c := sync.NewClassifier()
+	tx := sync.DecodedTransaction{
+		OperationType: sync.OpDeposit,
+		Protocol:      "Lido",
+		Transfers: []sync.DecodedTransfer{
+			{Direction: sync.DirectionOut, AssetSymbol: "ETH", Amount: big.NewInt(1)},
+			{Direction: sync.DirectionIn, AssetSymbol: "stETH", Amount: big.NewInt(1)},
+		},
+	}
+	assert.Equal(t, ledger.TxTypeStake, c.Classify(tx))
+}
+
+func TestClassify_RocketPoolTrade_IsUnstake(t *testing.T) {
+	c := sync.NewClassifier()
+	tx := sync.DecodedTransaction{
+		OperationType: sync.OpTrade,
+		Protocol:      "Rocket Pool",
+		Transfers: []sync.DecodedTransfer{
+			{Direction: sync.DirectionOut, AssetSymbol: "rETH", Amount: big.NewInt(1)},
+			{Direction: sync.DirectionIn, AssetSymbol: "ETH", Amount: big.NewInt(1)},
+		},
+	}
+	assert.Equal(t, ledger.TxTypeUnstake, c.Classify(tx))
+}
+
+func TestClassify_StakingClaim_IsStakingReward(t *testing.T) {
+	c := sync.NewClassifier()
+	tx := sync.DecodedTransaction{
+		OperationType: sync.OpClaim,
+		Protocol:      "Ethereum 2.0",
+		Transfers:     []sync.DecodedTransfer{{Direction: sync.DirectionIn, AssetSymbol: "ETH", Amount: big.NewInt(1)}},
+	}
+	assert.Equal(t, ledger.TxTypeStakingReward, c.Classify(tx))
+}
+
+func TestClassify_StakingProtocolSwap_StaysSwap(t *testing.T) {
+	c := sync.NewClassifier()
+	tx := sync.DecodedTransaction{
+		OperationType: sync.OpTrade,
+		Protocol:      "Lido",
+		Transfers: []sync.DecodedTransfer{
+			{Direction: sync.DirectionOut, AssetSymbol: "LDO", Amount: big.NewInt(1)},
+			{Direction: sync.DirectionIn, AssetSymbol: "USDC", Amount: big.NewInt(1)},
+		},
+	}
+	assert.Equal(t, ledger.TxTypeSwap, c.Classify(tx))
+}
+
+func nftTransfer(dir sync.TransferDirection) sync.DecodedNFTTransfer {
+	return sync.DecodedNFTTransfer{ContractAddress: "0xbayc", TokenID: "7", Standard: "erc721", Quantity: big.NewInt(1), Direction: dir}
+}
+
+func TestClassify_NFT(t *testing.T) {
+	c := sync.NewClassifier()
+	ethOut := sync.DecodedTransfer{Direction: sync.DirectionOut, AssetSymbol: "ETH", Amount: big.NewInt(1)}
+	ethIn := sync.DecodedTransfer{Direction: sync.DirectionIn, AssetSymbol: "WETH", Amount: big.NewInt(1)}
+
+	tests := []struct {
+		name      string
+		opType    sync.OperationType
+		transfers []sync.DecodedTransfer
+		nfts      []sync.DecodedNFTTransfer
+		expected  ledger.TransactionType
+	}{
+		{"buy", sync.OpTrade, []sync.DecodedTransfer{ethOut}, []sync.DecodedNFTTransfer{nftTransfer(sync.DirectionIn)}, ledger.TxTypeNFTBuy},
+		{"sell", sync.OpTrade, []sync.DecodedTransfer{ethIn}, []sync.DecodedNFTTransfer{nftTransfer(sync.DirectionOut)}, ledger.TxTypeNFTSell},
+		{"paid mint", sync.OpMint, []sync.DecodedTransfer{ethOut}, []sync.DecodedNFTTransfer{nftTransfer(sync.DirectionIn)}, ledger.TxTypeNFTMint},
+		{"free mint", sync.OpMint, nil, []sync.DecodedNFTTransfer{nftTransfer(sync.DirectionIn)}, ledger.TxTypeNFTMint},
+		{"receive", sync.OpReceive, nil, []sync.DecodedNFTTransfer{nftTransfer(sync.DirectionIn)}, ledger.TxTypeNFTTransferIn},
+		{"send", sync.OpSend, nil, []sync.DecodedNFTTransfer{nftTransfer(sync.DirectionOut)}, ledger.TxTypeNFTTransferOut},
+		{"nft for nft", sync.OpTrade, nil, []sync.DecodedNFTTransfer{nftTransfer(sync.DirectionIn), nftTransfer(sync.DirectionOut)}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := sync.DecodedTransaction{OperationType: tt.opType, Transfers: tt.transfers, NFTTransfers: tt.nfts}
+			assert.Equal(t, tt.expected, c.Classify(tx))
+		})
+	}
+}
+
+func TestClassify_UniV3PositionNFT_StaysLP(t *testing.T) {
+	c := sync.NewClassifier()
+	tx := sync.DecodedTransaction{
+		OperationType: sync.OpDeposit,
+		Protocol:      "Uniswap V3",
+		Transfers:     []sync.DecodedTransfer{{Direction: sync.DirectionOut, AssetSymbol: "ETH", Amount: big.NewInt(1)}},
+		NFTTransfers:  []sync.DecodedNFTTransfer{nftTransfer(sync.DirectionIn)},
+	}
+	assert.Equal(t, ledger.TxTypeLPDeposit, c.Classify(tx))
+}
+
+func TestClassify_LPProtocolRegistry(t *testing.T) {
+	c := sync.NewClassifier()
+	out := []sync.DecodedTransfer{{Direction: sync.DirectionOut, AssetSymbol: "WETH", Amount: big.NewInt(1)}}
+	in := []sync.DecodedTransfer{{Direction: sync.DirectionIn, AssetSymbol: "USDC", Amount: big.NewInt(1)}}
+
+	tests := []struct {
+		name     string
+		protocol string
+		opType   sync.OperationType
+		acts     []string
+		expected ledger.TransactionType
+	}{
+		{"uniswap v2 deposit", "Uniswap V2", sync.OpDeposit, nil, ledger.TxTypeLPDeposit},
+		{"curve withdraw", "Curve", sync.OpWithdraw, nil, ledger.TxTypeLPWithdraw},
+		{"balancer mint", "Balancer V2", sync.OpMint, nil, ledger.TxTypeLPDeposit},
+		{"pancakeswap burn", "PancakeSwap", sync.OpBurn, nil, ledger.TxTypeLPWithdraw},
+		{"aerodrome fee claim", "Aerodrome", sync.OpClaim, nil, ledger.TxTypeLPClaimFees},
+		{"velodrome fee receive", "Velodrome V2", sync.OpReceive, []string{"claim"}, ledger.TxTypeLPClaimFees},
+		// Curve fees compound into the LP token; claims are gauge rewards
+		{"curve reward claim", "Curve", sync.OpClaim, nil, ledger.TxTypeDefiClaim},
+		{"uniswap v2 swap", "Uniswap V2", sync.OpTrade, nil, ledger.TxTypeSwap},
+		{"unknown dex deposit", "Some DEX", sync.OpDeposit, nil, ledger.TxTypeDefiDeposit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transfers := out
+			if tt.opType == sync.OpClaim || tt.opType == sync.OpReceive {
+				transfers = in
+			}
+			tx := sync.DecodedTransaction{OperationType: tt.opType, Protocol: tt.protocol, Acts: tt.acts, Transfers: transfers}
+			assert.Equal(t, tt.expected, c.Classify(tx))
+		})
+	}
+}
+
+func TestClassify_Derivatives(t *testing.T) {
+	c := sync.NewClassifier()
+	usdc := func(dir sync.TransferDirection) sync.DecodedTransfer {
+		return sync.DecodedTransfer{Direction: dir, AssetSymbol: "USDC", Amount: big.NewInt(1)}
+	}
+
+	tests := []struct {
+		name      string
+		op        sync.OperationType
+		protocol  string
+		transfers []sync.DecodedTransfer
+		want      ledger.TransactionType
+	}{
+		{"margin posted", sync.OpDeposit, "GMX V2", []sync.DecodedTransfer{usdc(sync.DirectionOut)}, ledger.TxTypeDerivativeOpen},
+		{"margin withdrawn", sync.OpWithdraw, "GMX V2", []sync.DecodedTransfer{usdc(sync.DirectionIn)}, ledger.TxTypeDerivativeClose},
+		{"funding claimed", sync.OpClaim, "GMX V2", []sync.DecodedTransfer{usdc(sync.DirectionIn)}, ledger.TxTypeDerivativeFunding},
+		{"hyperliquid bridge deposit", sync.OpSend, "Hyperliquid", []sync.DecodedTransfer{usdc(sync.DirectionOut)}, ledger.TxTypeDerivativeOpen},
+		{"hyperliquid claim has no funding", sync.OpClaim, "Hyperliquid", []sync.DecodedTransfer{usdc(sync.DirectionIn)}, ledger.TxTypeDefiClaim},
+		{"GMX staked is not margin", sync.OpDeposit, "GMX", []sync.DecodedTransfer{{Direction: sync.DirectionOut, AssetSymbol: "GMX", Amount: big.NewInt(1)}}, ledger.TxTypeDefiDeposit},
+		{"swap falls through", sync.OpTrade, "GMX V2", []sync.DecodedTransfer{usdc(sync.DirectionOut), {Direction: sync.DirectionIn, AssetSymbol: "ETH", Amount: big.NewInt(1)}}, ledger.TxTypeSwap},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := sync.DecodedTransaction{OperationType: tt.op, Protocol: tt.protocol, Transfers: tt.transfers}
+			assert.Equal(t, tt.want, c.Classify(tx))
+		})
+	}
+}
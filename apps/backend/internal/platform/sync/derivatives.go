@@ -0,0 +1,141 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/kislikjeka/moontrack/internal/platform/derivativeposition"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// derivativeClose is margin coming back from a perpetuals protocol, resolved
+// against the margin on record. The wallet only sees what it received, so
+// it releases the margin on record up to that amount: anything received
+// above it is realized profit.
+//
+// A withdrawal smaller than the margin is taken as a partial close at no PnL.
+// Losses are realized once the margin is closed out by a derivative_close
+// recorded by hand.
+type derivativeClose struct {
+	position *derivativeposition.DerivativePosition
+	transfer *DecodedTransfer
+	released *big.Int
+}
+
+func (c *derivativeClose) receivedUSD() *big.Int {
+	return usdValueOrZero(c.transfer.Amount, c.transfer.USDPrice, c.transfer.Decimals)
+}
+
+func (c *derivativeClose) releasedUSD() *big.Int {
+	return usdValueOrZero(c.released, c.transfer.USDPrice, c.transfer.Decimals)
+}
+
+// resolveDerivativeClose matches the margin received to the wallet's active
+// position on the protocol in the same asset
+func (p *ZerionProcessor) resolveDerivativeClose(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) (*derivativeClose, error) {
+	if p.derivativePositionSvc == nil {
+		return nil, errors.New("derivative positions are not tracked")
+	}
+	t := p.findTransfer(tx.Transfers, DirectionIn)
+	if t == nil || t.Direction != DirectionIn {
+		return nil, errors.New("no margin received")
+	}
+
+	pos, err := p.derivativePositionSvc.FindActive(ctx, w.ID, tx.Protocol, tx.ChainID, t.AssetSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find active derivative position: %w", err)
+	}
+	if pos == nil || pos.Margin.Sign() <= 0 {
+		return nil, fmt.Errorf("no %s margin posted on %s", t.AssetSymbol, tx.Protocol)
+	}
+
+	released := new(big.Int).Set(t.Amount)
+	if released.Cmp(pos.Margin) > 0 {
+		released.Set(pos.Margin)
+	}
+	return &derivativeClose{position: pos, transfer: t, released: released}, nil
+}
+
+// --- Derivatives data builders ---
+
+// buildDerivativeData describes the single margin transfer moving in dir
+func (p *ZerionProcessor) buildDerivativeData(w *wallet.Wallet, tx DecodedTransaction, dir TransferDirection) map[string]interface{} {
+	data := p.buildBaseData(w, tx)
+	if t := p.findTransfer(tx.Transfers, dir); t != nil {
+		p.setLendingAssetFields(data, t)
+	}
+	return data
+}
+
+func (p *ZerionProcessor) buildDerivativeCloseData(w *wallet.Wallet, tx DecodedTransaction, c *derivativeClose) map[string]interface{} {
+	data := p.buildBaseData(w, tx)
+	p.setLendingAssetFields(data, c.transfer)
+	data["margin_amount"] = money.NewBigInt(c.released).String()
+	if c.position.Market != "" {
+		data["market"] = c.position.Market
+	}
+	return data
+}
+
+// --- Derivative position post-processing ---
+
+func (p *ZerionProcessor) handleDerivativeOpen(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) {
+	t := p.findTransfer(tx.Transfers, DirectionOut)
+	if t == nil {
+		p.logger.Warn("derivative open: no outgoing transfer", "tx_hash", tx.TxHash)
+		return
+	}
+
+	pos, err := p.derivativePositionSvc.FindOrCreate(ctx, w.UserID, w.ID,
+		tx.Protocol, tx.ChainID, "", t.AssetSymbol, t.Decimals, t.ContractAddress, tx.MinedAt,
+	)
+	if err != nil {
+		p.logger.Error("derivative open: failed to find or create position", "tx_hash", tx.TxHash, "error", err)
+		return
+	}
+
+	if err := p.derivativePositionSvc.RecordOpen(ctx, pos.ID, t.Amount, p.calcLendingUSD(t)); err != nil {
+		p.logger.Error("derivative open: failed to record", "tx_hash", tx.TxHash, "position_id", pos.ID, "error", err)
+	}
+}
+
+func (p *ZerionProcessor) handleDerivativeClose(ctx context.Context, tx DecodedTransaction, c *derivativeClose) {
+	if c == nil {
+		return
+	}
+	err := p.derivativePositionSvc.RecordClose(ctx, c.position.ID,
+		c.transfer.Amount, c.receivedUSD(),
+		c.released, c.releasedUSD(), tx.MinedAt,
+	)
+	if err != nil {
+		p.logger.Error("derivative close: failed to record", "tx_hash", tx.TxHash, "position_id", c.position.ID, "error", err)
+	}
+}
+
+// handleDerivativeFunding credits claimed funding to the active position in
+// the claimed asset. Funding claimed after the position closed has no
+// position to credit.
+func (p *ZerionProcessor) handleDerivativeFunding(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) {
+	t := p.findTransfer(tx.Transfers, DirectionIn)
+	if t == nil {
+		p.logger.Warn("derivative funding: no incoming transfer", "tx_hash", tx.TxHash)
+		return
+	}
+
+	pos, err := p.derivativePositionSvc.FindActive(ctx, w.ID, tx.Protocol, tx.ChainID, t.AssetSymbol)
+	if err != nil {
+		p.logger.Error("derivative funding: failed to find position", "tx_hash", tx.TxHash, "error", err)
+		return
+	}
+	if pos == nil {
+		p.logger.Debug("derivative funding: no active position", "tx_hash", tx.TxHash, "asset", t.AssetSymbol)
+		return
+	}
+
+	if err := p.derivativePositionSvc.RecordFundingClaim(ctx, pos.ID, t.Amount, p.calcLendingUSD(t)); err != nil {
+		p.logger.Error("derivative funding: failed to record", "tx_hash", tx.TxHash, "position_id", pos.ID, "error", err)
+	}
+}
@@ -0,0 +1,41 @@
+package sync
+
+import "strings"
+
+// DerivativesProtocol describes how a perpetuals protocol moves margin. The
+// wallet only sees margin going in and coming back; trades, PnL and funding
+// settle inside the protocol.
+type DerivativesProtocol struct {
+	// FundingClaims is set when funding received is claimed into the wallet
+	// rather than settled against the margin (GMX V2)
+	FundingClaims bool
+	// Tokens are the protocol's own tokens, which are staked or pooled
+	// rather than posted as margin (GMX, GLP, GM)
+	Tokens []string
+}
+
+var gmxTokens = []string{"GMX", "esGMX", "bnGMX", "sGMX", "GLP", "sGLP", "fsGLP", "GM", "GLV"}
+
+// derivativesProtocols are the perpetuals protocols recognised by name
+var derivativesProtocols = map[string]DerivativesProtocol{
+	"GMX":         {Tokens: gmxTokens},
+	"GMX V1":      {Tokens: gmxTokens},
+	"GMX V2":      {FundingClaims: true, Tokens: gmxTokens},
+	"Hyperliquid": {Tokens: []string{"HYPE"}},
+}
+
+// derivativesProtocolFor returns the derivatives semantics for a transaction's protocol
+func derivativesProtocolFor(tx DecodedTransaction) (DerivativesProtocol, bool) {
+	proto, ok := derivativesProtocols[tx.Protocol]
+	return proto, ok
+}
+
+// isProtocolToken reports whether symbol is one of the protocol's own tokens
+func (d DerivativesProtocol) isProtocolToken(symbol string) bool {
+	for _, t := range d.Tokens {
+		if strings.EqualFold(t, symbol) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package sync_test
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/derivativeposition"
+	"github.com/kislikjeka/moontrack/internal/platform/sync"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+type MockDerivativePositionService struct {
+	mock.Mock
+}
+
+func (m *MockDerivativePositionService) FindOrCreate(ctx context.Context, userID, walletID uuid.UUID, protocol, chainID, market, asset string, assetDecimals int, assetContract string, openedAt time.Time) (*derivativeposition.DerivativePosition, error) {
+	args := m.Called(ctx, userID, walletID, protocol, chainID, market, asset, assetDecimals, assetContract, openedAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*derivativeposition.DerivativePosition), args.Error(1)
+}
+
+func (m *MockDerivativePositionService) FindActive(ctx context.Context, walletID uuid.UUID, protocol, chainID, asset string) (*derivativeposition.DerivativePosition, error) {
+	args := m.Called(ctx, walletID, protocol, chainID, asset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*derivativeposition.DerivativePosition), args.Error(1)
+}
+
+func (m *MockDerivativePositionService) RecordOpen(ctx context.Context, positionID uuid.UUID, amount, usdValue *big.Int) error {
+	return m.Called(ctx, positionID, amount, usdValue).Error(0)
+}
+
+func (m *MockDerivativePositionService) RecordClose(ctx context.Context, positionID uuid.UUID, received, receivedUSD, released, releasedUSD *big.Int, at time.Time) error {
+	return m.Called(ctx, positionID, received, receivedUSD, released, releasedUSD, at).Error(0)
+}
+
+func (m *MockDerivativePositionService) RecordFundingClaim(ctx context.Context, positionID uuid.UUID, amount, usdValue *big.Int) error {
+	return m.Called(ctx, positionID, amount, usdValue).Error(0)
+}
+
+func newDerivativeProcessor(ledgerSvc sync.LedgerService, derivativeSvc sync.DerivativePositionService) *sync.ZerionProcessor {
+	return sync.NewZerionProcessor(new(MockWalletRepository), ledgerSvc, nil, nil, nil, derivativeSvc, logger.New("test", os.Stdout))
+}
+
+func usdcTransfer(dir sync.TransferDirection, amount int64) sync.DecodedTransfer {
+	return sync.DecodedTransfer{
+		AssetSymbol:     "USDC",
+		ContractAddress: "0xusdc",
+		Decimals:        6,
+		Amount:          big.NewInt(amount),
+		Direction:       dir,
+		USDPrice:        big.NewInt(100_000_000),
+	}
+}
+
+func TestZerionProcessor_DerivativeOpen_PostsMargin(t *testing.T) {
+	ctx := context.Background()
+	w := newTestWallet(uuid.New(), "0x1111111111111111111111111111111111111111")
+
+	ledgerSvc := new(MockLedgerService)
+	ledgerSvc.On("RecordTransaction", ctx, ledger.TxTypeDerivativeOpen, "zerion", mock.Anything, mock.Anything, mock.Anything).
+		Return(&ledger.Transaction{ID: uuid.New()}, nil)
+
+	tx := newDecodedTransaction(sync.OpDeposit, []sync.DecodedTransfer{usdcTransfer(sync.DirectionOut, 1_000_000_000)})
+	tx.ChainID = "arbitrum"
+	tx.Protocol = "GMX V2"
+
+	pos := &derivativeposition.DerivativePosition{ID: uuid.New()}
+	derivativeSvc := new(MockDerivativePositionService)
+	derivativeSvc.On("FindOrCreate", ctx, w.UserID, w.ID, "GMX V2", "arbitrum", "", "USDC", 6, "0xusdc", tx.MinedAt).Return(pos, nil)
+	derivativeSvc.On("RecordOpen", ctx, pos.ID, big.NewInt(1_000_000_000), big.NewInt(100_000_000_000)).Return(nil)
+
+	require.NoError(t, newDerivativeProcessor(ledgerSvc, derivativeSvc).ProcessTransaction(ctx, w, tx))
+
+	require.Len(t, ledgerSvc.recordedTransactions, 1)
+	rawData := ledgerSvc.recordedTransactions[0].RawData
+	assert.Equal(t, "GMX V2", rawData["protocol"])
+	assert.Equal(t, "USDC", rawData["asset"])
+	assert.Equal(t, "1000000000", rawData["amount"])
+	derivativeSvc.AssertExpectations(t)
+}
+
+func TestZerionProcessor_DerivativeClose_RealizesProfit(t *testing.T) {
+	ctx := context.Background()
+	w := newTestWallet(uuid.New(), "0x1111111111111111111111111111111111111111")
+
+	ledgerSvc := new(MockLedgerService)
+	ledgerSvc.On("RecordTransaction", ctx, ledger.TxTypeDerivativeClose, "zerion", mock.Anything, mock.Anything, mock.Anything).
+		Return(&ledger.Transaction{ID: uuid.New()}, nil)
+
+	tx := newDecodedTransaction(sync.OpWithdraw, []sync.DecodedTransfer{usdcTransfer(sync.DirectionIn, 1_200_000_000)})
+	tx.ChainID = "arbitrum"
+	tx.Protocol = "GMX V2"
+
+	// 1000 USDC of margin comes back as 1200 USDC
+	pos := &derivativeposition.DerivativePosition{ID: uuid.New(), Market: "ETH/USD", Margin: big.NewInt(1_000_000_000)}
+	derivativeSvc := new(MockDerivativePositionService)
+	derivativeSvc.On("FindActive", ctx, w.ID, "GMX V2", "arbitrum", "USDC").Return(pos, nil)
+	derivativeSvc.On("RecordClose", ctx, pos.ID,
+		big.NewInt(1_200_000_000), big.NewInt(120_000_000_000),
+		big.NewInt(1_000_000_000), big.NewInt(100_000_000_000), tx.MinedAt,
+	).Return(nil)
+
+	require.NoError(t, newDerivativeProcessor(ledgerSvc, derivativeSvc).ProcessTransaction(ctx, w, tx))
+
+	require.Len(t, ledgerSvc.recordedTransactions, 1)
+	rawData := ledgerSvc.recordedTransactions[0].RawData
+	assert.Equal(t, "1200000000", rawData["amount"])
+	assert.Equal(t, "1000000000", rawData["margin_amount"])
+	assert.Equal(t, "ETH/USD", rawData["market"])
+	derivativeSvc.AssertExpectations(t)
+}
+
+func TestZerionProcessor_DerivativeClose_NoPositionFallsBackToWithdraw(t *testing.T) {
+	ctx := context.Background()
+	w := newTestWallet(uuid.New(), "0x1111111111111111111111111111111111111111")
+
+	ledgerSvc := new(MockLedgerService)
+	ledgerSvc.On("RecordTransaction", ctx, ledger.TxTypeDefiWithdraw, "zerion", mock.Anything, mock.Anything, mock.Anything).
+		Return(&ledger.Transaction{ID: uuid.New()}, nil)
+
+	tx := newDecodedTransaction(sync.OpWithdraw, []sync.DecodedTransfer{usdcTransfer(sync.DirectionIn, 1_200_000_000)})
+	tx.ChainID = "arbitrum"
+	tx.Protocol = "GMX V2"
+
+	derivativeSvc := new(MockDerivativePositionService)
+	derivativeSvc.On("FindActive", ctx, w.ID, "GMX V2", "arbitrum", "USDC").Return(nil, nil)
+
+	require.NoError(t, newDerivativeProcessor(ledgerSvc, derivativeSvc).ProcessTransaction(ctx, w, tx))
+
+	require.Len(t, ledgerSvc.recordedTransactions, 1)
+	assert.NotContains(t, ledgerSvc.recordedTransactions[0].RawData, "margin_amount")
+	derivativeSvc.AssertNotCalled(t, "RecordClose", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestZerionProcessor_DerivativeFunding_Claimed(t *testing.T) {
+	ctx := context.Background()
+	w := newTestWallet(uuid.New(), "0x1111111111111111111111111111111111111111")
+
+	ledgerSvc := new(MockLedgerService)
+	ledgerSvc.On("RecordTransaction", ctx, ledger.TxTypeDerivativeFunding, "zerion", mock.Anything, mock.Anything, mock.Anything).
+		Return(&ledger.Transaction{ID: uuid.New()}, nil)
+
+	tx := newDecodedTransaction(sync.OpClaim, []sync.DecodedTransfer{usdcTransfer(sync.DirectionIn, 5_000_000)})
+	tx.ChainID = "arbitrum"
+	tx.Protocol = "GMX V2"
+
+	pos := &derivativeposition.DerivativePosition{ID: uuid.New(), Margin: big.NewInt(1_000_000_000)}
+	derivativeSvc := new(MockDerivativePositionService)
+	derivativeSvc.On("FindActive", ctx, w.ID, "GMX V2", "arbitrum", "USDC").Return(pos, nil)
+	derivativeSvc.On("RecordFundingClaim", ctx, pos.ID, big.NewInt(5_000_000), big.NewInt(500_000_000)).Return(nil)
+
+	require.NoError(t, newDerivativeProcessor(ledgerSvc, derivativeSvc).ProcessTransaction(ctx, w, tx))
+
+	require.Len(t, ledgerSvc.recordedTransactions, 1)
+	assert.Equal(t, true, ledgerSvc.recordedTransactions[0].RawData["claimed"])
+	derivativeSvc.AssertExpectations(t)
+}
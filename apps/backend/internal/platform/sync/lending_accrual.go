@@ -0,0 +1,182 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/lendingposition"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// LendingAccrual books interest on active lending positions and refreshes
+// their liquidation risk. Supplied balances (aTokens) and debts (debt tokens)
+// grow every block without a transfer, so interest never appears in the
+// transaction feed; it is the difference between the balances the protocol
+// reports and the amounts the position has booked.
+type LendingAccrual struct {
+	provider   LendingPositionDataProvider
+	ledgerSvc  LedgerService
+	lendingSvc LendingPositionService
+	logger     *logger.Logger
+}
+
+// NewLendingAccrual creates a new LendingAccrual
+func NewLendingAccrual(provider LendingPositionDataProvider, ledgerSvc LedgerService, lendingSvc LendingPositionService, log *logger.Logger) *LendingAccrual {
+	return &LendingAccrual{
+		provider:   provider,
+		ledgerSvc:  ledgerSvc,
+		lendingSvc: lendingSvc,
+		logger:     log.WithField("component", "lending_accrual"),
+	}
+}
+
+// Accrue records lending_interest for supplied balances and
+// lending_borrow_interest for debts that grew past the booked amounts, then
+// stores each position's LTV, health factor and liquidation price at current
+// prices. Returns the number of interest transactions recorded.
+func (a *LendingAccrual) Accrue(ctx context.Context, w *wallet.Wallet) (int, error) {
+	active := lendingposition.StatusActive
+	positions, err := a.lendingSvc.ListByUser(ctx, w.UserID, &active, &w.ID, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list active lending positions: %w", err)
+	}
+	if len(positions) == 0 {
+		return 0, nil
+	}
+
+	onChain, err := a.provider.GetLendingPositions(ctx, w.Address)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get on-chain lending positions: %w", err)
+	}
+
+	now := time.Now().UTC()
+	recorded := 0
+	for _, pos := range positions {
+		account := findOnChainLendingPosition(onChain, pos.ChainID, pos.Protocol)
+		if account == nil {
+			continue
+		}
+
+		// Prices are keyed by leg ID; legs the protocol no longer reports
+		// are left unpriced
+		prices := make(map[uuid.UUID]*big.Int, len(pos.Legs))
+		for _, leg := range pos.Legs {
+			tokens := account.Supplied
+			if leg.Side == lendingposition.SideBorrow {
+				tokens = account.Borrowed
+			}
+			oc := findOnChainToken(tokens, leg.Contract, leg.Asset)
+			if oc == nil {
+				continue
+			}
+			prices[leg.ID] = oc.USDPrice
+
+			if ok := a.accrue(ctx, w, pos, leg, oc, now); ok {
+				recorded++
+			}
+		}
+
+		if err := a.lendingSvc.RecordRisk(ctx, pos.ID, prices, now); err != nil {
+			a.logger.Error("failed to record lending risk", "position_id", pos.ID, "error", err)
+		}
+	}
+
+	return recorded, nil
+}
+
+// accrue books the growth of one leg's on-chain balance over its booked
+// amount. Reports whether an interest transaction was recorded.
+func (a *LendingAccrual) accrue(ctx context.Context, w *wallet.Wallet, pos *lendingposition.LendingPosition, leg *lendingposition.Leg, oc *OnChainPosition, now time.Time) bool {
+	if oc.Quantity == nil {
+		return false
+	}
+	booked := leg.Amount
+	if booked == nil {
+		booked = big.NewInt(0)
+	}
+
+	delta := new(big.Int).Sub(oc.Quantity, booked)
+	if delta.Sign() <= 0 {
+		// A balance below the booked amount is not interest (e.g. a
+		// liquidation or a rounding wei); leave it to the transaction feed
+		return false
+	}
+
+	txType := ledger.TxTypeLendingInterest
+	if leg.Side == lendingposition.SideBorrow {
+		txType = ledger.TxTypeLendingBorrowInterest
+	}
+
+	decimals := leg.Decimals
+	if decimals == 0 {
+		decimals = oc.Decimals
+	}
+
+	// The leg and its on-chain balance identify the accrual, so re-running the
+	// same sync produces the same external ID and is rejected as a duplicate.
+	ref := fmt.Sprintf("%s:%s:%s", txType, leg.ID, oc.Quantity.String())
+
+	data := map[string]interface{}{
+		"wallet_id":        w.ID.String(),
+		"tx_hash":          ref,
+		"chain_id":         pos.ChainID,
+		"occurred_at":      now.Format(time.RFC3339),
+		"protocol":         pos.Protocol,
+		"asset":            leg.Asset,
+		"amount":           money.NewBigInt(delta).String(),
+		"decimals":         decimals,
+		"contract_address": leg.Contract,
+	}
+	usd := big.NewInt(0)
+	if oc.USDPrice != nil {
+		data["usd_price"] = oc.USDPrice.String()
+		usd = money.CalcUSDValue(delta, oc.USDPrice, decimals)
+	}
+
+	if _, err := a.ledgerSvc.RecordTransaction(ctx, txType, "lending_accrual", &ref, now, data); err != nil {
+		if !isDuplicateError(err) {
+			a.logger.Error("failed to record lending interest",
+				"wallet_id", w.ID, "position_id", pos.ID, "type", txType, "asset", leg.Asset, "delta", delta.String(), "error", err)
+		}
+		return false
+	}
+
+	asset := lendingposition.AssetInfo{Symbol: leg.Asset, Decimals: decimals, Contract: leg.Contract}
+	if err := a.lendingSvc.RecordInterest(ctx, pos.ID, leg.Side, asset, delta, usd); err != nil {
+		a.logger.Error("failed to update position interest", "position_id", pos.ID, "asset", leg.Asset, "error", err)
+	}
+	return true
+}
+
+func findOnChainLendingPosition(positions []OnChainLendingPosition, chainID, protocol string) *OnChainLendingPosition {
+	for i := range positions {
+		if positions[i].ChainID == chainID && strings.EqualFold(positions[i].Protocol, protocol) {
+			return &positions[i]
+		}
+	}
+	return nil
+}
+
+// findOnChainToken matches by contract when both sides know it, else by symbol
+func findOnChainToken(tokens []OnChainPosition, contract, symbol string) *OnChainPosition {
+	for i := range tokens {
+		if contract != "" && tokens[i].ContractAddress != "" {
+			if strings.EqualFold(tokens[i].ContractAddress, contract) {
+				return &tokens[i]
+			}
+			continue
+		}
+		if strings.EqualFold(tokens[i].AssetSymbol, symbol) {
+			return &tokens[i]
+		}
+	}
+	return nil
+}
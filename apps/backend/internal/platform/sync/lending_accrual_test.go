@@ -0,0 +1,174 @@
+package sync_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/lendingposition"
+	"github.com/kislikjeka/moontrack/internal/platform/sync"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+type MockLendingPositionService struct {
+	mock.Mock
+}
+
+func (m *MockLendingPositionService) FindOrCreate(ctx context.Context, userID, walletID uuid.UUID, protocol, chainID string, openedAt time.Time) (*lendingposition.LendingPosition, error) {
+	args := m.Called(ctx, userID, walletID, protocol, chainID, openedAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*lendingposition.LendingPosition), args.Error(1)
+}
+
+func (m *MockLendingPositionService) RecordSupply(ctx context.Context, positionID uuid.UUID, asset lendingposition.AssetInfo, amount, usdValue *big.Int) error {
+	return m.Called(ctx, positionID, asset, amount, usdValue).Error(0)
+}
+
+func (m *MockLendingPositionService) RecordWithdraw(ctx context.Context, positionID uuid.UUID, asset lendingposition.AssetInfo, amount, usdValue *big.Int) error {
+	return m.Called(ctx, positionID, asset, amount, usdValue).Error(0)
+}
+
+func (m *MockLendingPositionService) RecordBorrow(ctx context.Context, positionID uuid.UUID, asset lendingposition.AssetInfo, amount, usdValue *big.Int) error {
+	return m.Called(ctx, positionID, asset, amount, usdValue).Error(0)
+}
+
+func (m *MockLendingPositionService) RecordRepay(ctx context.Context, positionID uuid.UUID, asset lendingposition.AssetInfo, amount, usdValue *big.Int) error {
+	return m.Called(ctx, positionID, asset, amount, usdValue).Error(0)
+}
+
+func (m *MockLendingPositionService) RecordClaim(ctx context.Context, positionID uuid.UUID, usdValue *big.Int) error {
+	return m.Called(ctx, positionID, usdValue).Error(0)
+}
+
+func (m *MockLendingPositionService) RecordLiquidation(ctx context.Context, positionID uuid.UUID, collateral lendingposition.AssetInfo, seized, seizedUSD *big.Int, debt lendingposition.AssetInfo, repaid, repaidUSD *big.Int, at time.Time) error {
+	return m.Called(ctx, positionID, collateral, seized, seizedUSD, debt, repaid, repaidUSD, at).Error(0)
+}
+
+func (m *MockLendingPositionService) RecordInterest(ctx context.Context, positionID uuid.UUID, side lendingposition.Side, asset lendingposition.AssetInfo, amount, usdValue *big.Int) error {
+	return m.Called(ctx, positionID, side, asset, amount, usdValue).Error(0)
+}
+
+func (m *MockLendingPositionService) RecordRisk(ctx context.Context, positionID uuid.UUID, prices map[uuid.UUID]*big.Int, at time.Time) error {
+	return m.Called(ctx, positionID, prices, at).Error(0)
+}
+
+func (m *MockLendingPositionService) ListByUser(ctx context.Context, userID uuid.UUID, status *lendingposition.Status, walletID *uuid.UUID, chainID *string) ([]*lendingposition.LendingPosition, error) {
+	args := m.Called(ctx, userID, status, walletID, chainID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*lendingposition.LendingPosition), args.Error(1)
+}
+
+type fakeLendingProvider struct {
+	positions []sync.OnChainLendingPosition
+}
+
+func (f *fakeLendingProvider) GetLendingPositions(_ context.Context, _ string) ([]sync.OnChainLendingPosition, error) {
+	return f.positions, nil
+}
+
+func newLendingLeg(posID uuid.UUID, side lendingposition.Side, asset string, decimals int, contract string, amount *big.Int) *lendingposition.Leg {
+	return &lendingposition.Leg{
+		ID: uuid.New(), PositionID: posID, Side: side,
+		Asset: asset, Decimals: decimals, Contract: contract, Amount: amount,
+	}
+}
+
+func TestLendingAccrual_BooksSupplyAndBorrowInterest(t *testing.T) {
+	ctx := context.Background()
+	w := newTestWallet(uuid.New(), "0xabc")
+	pos := &lendingposition.LendingPosition{
+		ID:       uuid.New(),
+		WalletID: w.ID,
+		ChainID:  "ethereum",
+		Protocol: "Aave V3",
+		Status:   lendingposition.StatusActive,
+	}
+	weth := newLendingLeg(pos.ID, lendingposition.SideSupply, "WETH", 18, "0xweth", big.NewInt(1e18))
+	wbtc := newLendingLeg(pos.ID, lendingposition.SideSupply, "WBTC", 8, "0xwbtc", big.NewInt(10_000_000))
+	usdc := newLendingLeg(pos.ID, lendingposition.SideBorrow, "USDC", 6, "0xusdc", big.NewInt(1_000_000_000))
+	pos.Legs = []*lendingposition.Leg{weth, wbtc, usdc}
+
+	wethPrice := big.NewInt(200_000_000_000)
+	wbtcPrice := big.NewInt(6_000_000_000_000)
+	usdcPrice := big.NewInt(100_000_000)
+
+	provider := &fakeLendingProvider{positions: []sync.OnChainLendingPosition{{
+		ChainID:  "ethereum",
+		Protocol: "Aave V3",
+		Supplied: []sync.OnChainPosition{
+			{ChainID: "ethereum", AssetSymbol: "WETH", ContractAddress: "0xweth", Decimals: 18, Quantity: big.NewInt(1_002e15), USDPrice: wethPrice},
+			{ChainID: "ethereum", AssetSymbol: "WBTC", ContractAddress: "0xwbtc", Decimals: 8, Quantity: big.NewInt(10_000_000), USDPrice: wbtcPrice},
+		},
+		Borrowed: []sync.OnChainPosition{{ChainID: "ethereum", AssetSymbol: "USDC", ContractAddress: "0xusdc", Decimals: 6, Quantity: big.NewInt(1_005_000_000), USDPrice: usdcPrice}},
+	}}}
+
+	lendingSvc := new(MockLendingPositionService)
+	lendingSvc.On("ListByUser", ctx, w.UserID, mock.Anything, &w.ID, (*string)(nil)).Return([]*lendingposition.LendingPosition{pos}, nil)
+	// 0.002 WETH at $2000 = $4; 5 USDC at $1 = $5; WBTC did not grow
+	lendingSvc.On("RecordInterest", ctx, pos.ID, lendingposition.SideSupply,
+		lendingposition.AssetInfo{Symbol: "WETH", Decimals: 18, Contract: "0xweth"}, big.NewInt(2e15), big.NewInt(400_000_000)).Return(nil)
+	lendingSvc.On("RecordInterest", ctx, pos.ID, lendingposition.SideBorrow,
+		lendingposition.AssetInfo{Symbol: "USDC", Decimals: 6, Contract: "0xusdc"}, big.NewInt(5_000_000), big.NewInt(500_000_000)).Return(nil)
+	lendingSvc.On("RecordRisk", ctx, pos.ID, map[uuid.UUID]*big.Int{
+		weth.ID: wethPrice,
+		wbtc.ID: wbtcPrice,
+		usdc.ID: usdcPrice,
+	}, mock.Anything).Return(nil)
+
+	ledgerSvc := new(MockLedgerService)
+	ledgerSvc.On("RecordTransaction", ctx, mock.Anything, "lending_accrual", mock.Anything, mock.Anything, mock.Anything).
+		Return(&ledger.Transaction{ID: uuid.New()}, nil)
+
+	accrual := sync.NewLendingAccrual(provider, ledgerSvc, lendingSvc, logger.NewDefault("test"))
+	n, err := accrual.Accrue(ctx, w)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	require.Len(t, ledgerSvc.recordedTransactions, 2)
+	assert.Equal(t, ledger.TxTypeLendingInterest, ledgerSvc.recordedTransactions[0].TxType)
+	assert.Equal(t, "2000000000000000", ledgerSvc.recordedTransactions[0].RawData["amount"])
+	assert.Equal(t, ledger.TxTypeLendingBorrowInterest, ledgerSvc.recordedTransactions[1].TxType)
+	assert.Equal(t, "USDC", ledgerSvc.recordedTransactions[1].RawData["asset"])
+	lendingSvc.AssertExpectations(t)
+}
+
+func TestLendingAccrual_NoGrowth_OnlyRefreshesRisk(t *testing.T) {
+	ctx := context.Background()
+	w := newTestWallet(uuid.New(), "0xabc")
+	pos := &lendingposition.LendingPosition{
+		ID: uuid.New(), WalletID: w.ID, ChainID: "ethereum", Protocol: "Aave V3",
+		Status: lendingposition.StatusActive,
+	}
+	eth := newLendingLeg(pos.ID, lendingposition.SideSupply, "ETH", 18, "", big.NewInt(1e18))
+	pos.Legs = []*lendingposition.Leg{eth}
+
+	provider := &fakeLendingProvider{positions: []sync.OnChainLendingPosition{{
+		ChainID:  "ethereum",
+		Protocol: "aave v3",
+		Supplied: []sync.OnChainPosition{{ChainID: "ethereum", AssetSymbol: "ETH", Quantity: big.NewInt(1e18 - 1)}},
+	}}}
+
+	lendingSvc := new(MockLendingPositionService)
+	lendingSvc.On("ListByUser", ctx, w.UserID, mock.Anything, &w.ID, (*string)(nil)).Return([]*lendingposition.LendingPosition{pos}, nil)
+	lendingSvc.On("RecordRisk", ctx, pos.ID, map[uuid.UUID]*big.Int{eth.ID: nil}, mock.Anything).Return(nil)
+
+	ledgerSvc := new(MockLedgerService)
+
+	accrual := sync.NewLendingAccrual(provider, ledgerSvc, lendingSvc, logger.NewDefault("test"))
+	n, err := accrual.Accrue(ctx, w)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Empty(t, ledgerSvc.recordedTransactions)
+	lendingSvc.AssertExpectations(t)
+}
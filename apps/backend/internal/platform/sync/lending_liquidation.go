@@ -0,0 +1,216 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/kislikjeka/moontrack/internal/platform/lendingposition"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// lendingLiquidation is a liquidation resolved against the wallet's lending
+// account. The wallet only sees its receipt and debt tokens burn, so the
+// collateral seized and the debt repaid are expressed in the underlying
+// assets of the account's legs.
+type lendingLiquidation struct {
+	position *lendingposition.LendingPosition
+
+	collateral  lendingposition.AssetInfo
+	seized      *big.Int
+	seizedPrice *big.Int // USD scaled by 1e8, nil if unknown
+
+	debt        lendingposition.AssetInfo
+	repaid      *big.Int
+	repaidPrice *big.Int // USD scaled by 1e8, nil if unknown
+}
+
+func (l *lendingLiquidation) seizedUSD() *big.Int {
+	return usdValueOrZero(l.seized, l.seizedPrice, l.collateral.Decimals)
+}
+
+func (l *lendingLiquidation) repaidUSD() *big.Int {
+	return usdValueOrZero(l.repaid, l.repaidPrice, l.debt.Decimals)
+}
+
+// resolveLendingLiquidation matches the receipt token burned to a supply leg
+// and the debt token burned to a borrow leg of the wallet's active account.
+// Exchange-rate receipts (cTokens) are converted through their USD value.
+// Without a debt token the account's only debt is taken as repaid at the
+// value seized, as the penalty cannot be told apart.
+func (p *ZerionProcessor) resolveLendingLiquidation(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) (*lendingLiquidation, error) {
+	proto, ok := lendingProtocolFor(tx)
+	if !ok {
+		return nil, fmt.Errorf("unknown lending protocol %q", tx.Protocol)
+	}
+	if p.lendingPositionSvc == nil {
+		return nil, errors.New("lending positions are not tracked")
+	}
+
+	var receipt, debtToken *DecodedTransfer
+	for i := range tx.Transfers {
+		t := &tx.Transfers[i]
+		if t.Direction != DirectionOut {
+			continue
+		}
+		switch {
+		case proto.isDebtToken(t.AssetSymbol):
+			debtToken = t
+		case proto.isReceiptToken(t.AssetSymbol):
+			receipt = t
+		}
+	}
+	if receipt == nil {
+		return nil, errors.New("no receipt token seized")
+	}
+
+	pos, err := p.findActiveLendingPosition(ctx, w, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	supply := matchLendingLeg(pos, lendingposition.SideSupply, tokenUnderlying(receipt.AssetSymbol, proto.ReceiptPrefixes))
+	if supply == nil {
+		return nil, fmt.Errorf("no collateral leg for %s", receipt.AssetSymbol)
+	}
+	liq := &lendingLiquidation{
+		position:    pos,
+		collateral:  legAsset(supply),
+		seized:      receipt.Amount,
+		seizedPrice: receipt.USDPrice,
+	}
+	if proto.Receipt == LendingReceiptExchangeRate {
+		// cTokens trade at a growing rate to the underlying; value the
+		// seized cTokens and buy back the underlying at the leg's price
+		liq.seizedPrice = supply.USDPrice
+		liq.seized = unitsForUSD(usdValueOrZero(receipt.Amount, receipt.USDPrice, receipt.Decimals), supply.USDPrice, supply.Decimals)
+		if liq.seized == nil {
+			return nil, fmt.Errorf("no price to convert %s to %s", receipt.AssetSymbol, supply.Asset)
+		}
+	} else if liq.seizedPrice == nil {
+		liq.seizedPrice = supply.USDPrice
+	}
+
+	if debtToken != nil {
+		borrow := matchLendingLeg(pos, lendingposition.SideBorrow, tokenUnderlying(debtToken.AssetSymbol, proto.DebtPrefixes))
+		if borrow == nil {
+			return nil, fmt.Errorf("no debt leg for %s", debtToken.AssetSymbol)
+		}
+		liq.debt = legAsset(borrow)
+		liq.repaid = debtToken.Amount
+		liq.repaidPrice = debtToken.USDPrice
+		if liq.repaidPrice == nil {
+			liq.repaidPrice = borrow.USDPrice
+		}
+		return liq, nil
+	}
+
+	var borrow *lendingposition.Leg
+	for _, l := range pos.LegsBySide(lendingposition.SideBorrow) {
+		if l.Amount.Sign() <= 0 {
+			continue
+		}
+		if borrow != nil {
+			return nil, errors.New("several debts and no debt token to tell which was repaid")
+		}
+		borrow = l
+	}
+	if borrow == nil {
+		return nil, errors.New("no outstanding debt")
+	}
+	liq.debt = legAsset(borrow)
+	liq.repaidPrice = borrow.USDPrice
+	liq.repaid = unitsForUSD(liq.seizedUSD(), borrow.USDPrice, borrow.Decimals)
+	if liq.repaid == nil {
+		return nil, fmt.Errorf("no price to value the %s repaid", borrow.Asset)
+	}
+	return liq, nil
+}
+
+// findActiveLendingPosition returns the wallet's active account on the
+// transaction's protocol and chain
+func (p *ZerionProcessor) findActiveLendingPosition(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) (*lendingposition.LendingPosition, error) {
+	active := lendingposition.StatusActive
+	positions, err := p.lendingPositionSvc.ListByUser(ctx, w.UserID, &active, &w.ID, &tx.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active lending positions: %w", err)
+	}
+	for _, pos := range positions {
+		if pos.Protocol == tx.Protocol {
+			return pos, nil
+		}
+	}
+	return nil, errors.New("no active lending position")
+}
+
+// matchLendingLeg returns the leg on side whose asset ends the symbol left
+// after stripping a receipt or debt prefix (WETH for EthWETH), preferring the
+// longest match so ETH does not claim EthWETH
+func matchLendingLeg(pos *lendingposition.LendingPosition, side lendingposition.Side, underlying string) *lendingposition.Leg {
+	var best *lendingposition.Leg
+	for _, l := range pos.LegsBySide(side) {
+		if !strings.HasSuffix(strings.ToUpper(underlying), strings.ToUpper(l.Asset)) {
+			continue
+		}
+		if best == nil || len(l.Asset) > len(best.Asset) {
+			best = l
+		}
+	}
+	return best
+}
+
+func legAsset(l *lendingposition.Leg) lendingposition.AssetInfo {
+	return lendingposition.AssetInfo{Symbol: l.Asset, Decimals: l.Decimals, Contract: l.Contract}
+}
+
+func usdValueOrZero(amount, usdPrice *big.Int, decimals int) *big.Int {
+	if amount == nil || usdPrice == nil {
+		return big.NewInt(0)
+	}
+	return money.CalcUSDValue(amount, usdPrice, decimals)
+}
+
+// unitsForUSD returns the base units of an asset worth usdValue at usdPrice,
+// or nil if either is missing
+func unitsForUSD(usdValue, usdPrice *big.Int, decimals int) *big.Int {
+	if usdValue.Sign() <= 0 || usdPrice == nil || usdPrice.Sign() <= 0 {
+		return nil
+	}
+	units := new(big.Int).Mul(usdValue, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	return units.Div(units, usdPrice)
+}
+
+// --- Liquidation data builder and post-processing ---
+
+func (p *ZerionProcessor) buildLendingLiquidationData(w *wallet.Wallet, tx DecodedTransaction, liq *lendingLiquidation) map[string]interface{} {
+	data := p.buildBaseData(w, tx)
+	data["asset"] = liq.collateral.Symbol
+	data["amount"] = money.NewBigInt(liq.seized).String()
+	data["decimals"] = liq.collateral.Decimals
+	data["contract_address"] = liq.collateral.Contract
+	if liq.seizedPrice != nil {
+		data["usd_price"] = liq.seizedPrice.String()
+	}
+	data["debt_asset"] = liq.debt.Symbol
+	data["debt_amount"] = money.NewBigInt(liq.repaid).String()
+	data["debt_decimals"] = liq.debt.Decimals
+	data["debt_contract_address"] = liq.debt.Contract
+	if liq.repaidPrice != nil {
+		data["debt_usd_price"] = liq.repaidPrice.String()
+	}
+	return data
+}
+
+func (p *ZerionProcessor) handleLendingLiquidation(ctx context.Context, tx DecodedTransaction, liq *lendingLiquidation) {
+	err := p.lendingPositionSvc.RecordLiquidation(ctx, liq.position.ID,
+		liq.collateral, liq.seized, liq.seizedUSD(),
+		liq.debt, liq.repaid, liq.repaidUSD(),
+		tx.MinedAt,
+	)
+	if err != nil {
+		p.logger.Error("lending liquidation: failed to record", "tx_hash", tx.TxHash, "position_id", liq.position.ID, "error", err)
+	}
+}
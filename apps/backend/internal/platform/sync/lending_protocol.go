@@ -0,0 +1,135 @@
+package sync
+
+import (
+	"strings"
+	"unicode"
+)
+
+// LendingReceiptKind describes what a lending protocol gives a supplier in
+// return for the supplied asset
+type LendingReceiptKind string
+
+const (
+	// LendingReceiptRebasing protocols mint a receipt token 1:1 whose balance
+	// grows with interest (Aave aTokens, Spark spTokens, Compound V3 base supply)
+	LendingReceiptRebasing LendingReceiptKind = "rebasing"
+	// LendingReceiptExchangeRate protocols mint a receipt token at an exchange
+	// rate that grows with interest; the balance stays fixed (Compound V2 cTokens)
+	LendingReceiptExchangeRate LendingReceiptKind = "exchange_rate"
+	// LendingReceiptNone protocols track balances in the protocol contract and
+	// move no receipt token (Morpho Blue markets, Compound V3 collateral)
+	LendingReceiptNone LendingReceiptKind = "none"
+)
+
+// LendingProtocol describes the token semantics of a lending market. Receipt
+// and debt tokens only mirror the position, so classification and position
+// tracking look past them to the underlying asset that actually moved.
+type LendingProtocol struct {
+	Receipt LendingReceiptKind
+	// ReceiptPrefixes are the symbol prefixes of the protocol's receipt
+	// tokens (aEthUSDC, cUSDC, spDAI)
+	ReceiptPrefixes []string
+	// DebtPrefixes are the symbol prefixes of the protocol's debt tokens
+	// (variableDebtEthUSDC); protocols without debt tokens leave it empty
+	DebtPrefixes []string
+}
+
+var (
+	aaveLending = LendingProtocol{
+		Receipt:         LendingReceiptRebasing,
+		ReceiptPrefixes: []string{"a"},
+		DebtPrefixes:    []string{"variableDebt", "stableDebt"},
+	}
+	sparkLending = LendingProtocol{
+		Receipt:         LendingReceiptRebasing,
+		ReceiptPrefixes: []string{"sp"},
+		DebtPrefixes:    []string{"variableDebt", "stableDebt"},
+	}
+	compoundV2Lending = LendingProtocol{
+		Receipt:         LendingReceiptExchangeRate,
+		ReceiptPrefixes: []string{"c"},
+	}
+	compoundV3Lending = LendingProtocol{
+		Receipt:         LendingReceiptRebasing,
+		ReceiptPrefixes: []string{"c"},
+	}
+	morphoLending = LendingProtocol{
+		Receipt: LendingReceiptNone,
+	}
+)
+
+// lendingProtocols are the lending protocols recognised by name
+var lendingProtocols = map[string]LendingProtocol{
+	"AAVE":        aaveLending,
+	"Aave":        aaveLending,
+	"Aave V2":     aaveLending,
+	"Aave V3":     aaveLending,
+	"Spark":       sparkLending,
+	"Spark Lend":  sparkLending,
+	"SparkLend":   sparkLending,
+	"Compound":    compoundV2Lending,
+	"Compound V2": compoundV2Lending,
+	"Compound V3": compoundV3Lending,
+	"Morpho":      morphoLending,
+	"Morpho Blue": morphoLending,
+}
+
+// lendingProtocolFor returns the lending semantics for a transaction's protocol
+func lendingProtocolFor(tx DecodedTransaction) (LendingProtocol, bool) {
+	proto, ok := lendingProtocols[tx.Protocol]
+	return proto, ok
+}
+
+// isReceiptToken reports whether symbol is one of the protocol's receipt or
+// debt tokens: a known prefix followed by the capitalised underlying symbol,
+// so cUSDC and aEthWETH match while cbETH and AAVE do not.
+func (p LendingProtocol) isReceiptToken(symbol string) bool {
+	return tokenUnderlying(symbol, p.ReceiptPrefixes) != "" || p.isDebtToken(symbol)
+}
+
+// isDebtToken reports whether symbol is one of the protocol's debt tokens
+func (p LendingProtocol) isDebtToken(symbol string) bool {
+	return tokenUnderlying(symbol, p.DebtPrefixes) != ""
+}
+
+// tokenUnderlying strips the first matching prefix from a receipt or debt
+// token symbol, or returns empty if none matches. What remains may still
+// carry a market tag (EthUSDC for aEthUSDC).
+func tokenUnderlying(symbol string, prefixes []string) string {
+	for _, prefix := range prefixes {
+		rest, ok := strings.CutPrefix(symbol, prefix)
+		if !ok || rest == "" {
+			continue
+		}
+		if r := []rune(rest)[0]; unicode.IsUpper(r) || unicode.IsDigit(r) {
+			return rest
+		}
+	}
+	return ""
+}
+
+// lendingActTypes maps Zerion act types to lending operations
+var lendingActTypes = map[string]OperationType{
+	"deposit":  OpDeposit,
+	"withdraw": OpWithdraw,
+	"borrow":   OpBorrow,
+	"repay":    OpRepay,
+	"claim":    OpClaim,
+}
+
+// lendingLiquidationActs are the Zerion act types of a liquidation
+var lendingLiquidationActs = map[string]bool{
+	"liquidate":   true,
+	"liquidation": true,
+}
+
+// lendingActOperation returns the lending operation named by the first act
+// that has one, or empty
+func lendingActOperation(acts []string) OperationType {
+	for _, act := range acts {
+		if op, ok := lendingActTypes[act]; ok {
+			return op
+		}
+	}
+	return ""
+}
@@ -34,11 +34,14 @@ func newMockLPPositionService() *MockLPPositionService {
 }
 
 func (m *MockLPPositionService) FindOrCreate(ctx context.Context, userID, walletID uuid.UUID, chainID, protocol, nftTokenID, contractAddress string, token0, token1 lpposition.TokenInfo, openedAt time.Time) (*lpposition.LPPosition, error) {
-	// Check if position already exists by NFT token ID
+	// Check if position already exists by NFT token ID, or open in the pool
 	for _, pos := range m.positions {
 		if pos.NFTTokenID == nftTokenID && nftTokenID != "" {
 			return pos, nil
 		}
+		if nftTokenID == "" && contractAddress != "" && pos.ContractAddress == contractAddress && pos.Status == lpposition.StatusOpen {
+			return pos, nil
+		}
 	}
 
 	// Create new position
@@ -80,7 +83,7 @@ func (m *MockLPPositionService) FindOrCreate(ctx context.Context, userID, wallet
 func (m *MockLPPositionService) FindOpenByTokenPair(ctx context.Context, walletID uuid.UUID, chainID, protocol, token0, token1 string) (*lpposition.LPPosition, error) {
 	for _, pos := range m.positions {
 		if pos.WalletID == walletID && pos.ChainID == chainID && pos.Protocol == protocol && pos.Status == lpposition.StatusOpen {
-			if (pos.Token0Symbol == token0 && pos.Token1Symbol == token1) || (pos.Token0Symbol == token1 && pos.Token1Symbol == token0) {
+			if (pos.Token0Contract == token0 && pos.Token1Contract == token1) || (pos.Token0Contract == token1 && pos.Token1Contract == token0) {
 				return pos, nil
 			}
 		}
@@ -88,7 +91,17 @@ func (m *MockLPPositionService) FindOpenByTokenPair(ctx context.Context, walletI
 	return nil, nil
 }
 
-func (m *MockLPPositionService) RecordDeposit(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int) error {
+func (m *MockLPPositionService) FindOpenByContract(ctx context.Context, walletID uuid.UUID, chainID, contractAddress string) (*lpposition.LPPosition, error) {
+	for _, pos := range m.positions {
+		if pos.WalletID == walletID && pos.ChainID == chainID && pos.NFTTokenID == "" &&
+			pos.ContractAddress == contractAddress && pos.Status == lpposition.StatusOpen {
+			return pos, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockLPPositionService) RecordDeposit(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int, at time.Time) error {
 	pos := m.positions[positionID]
 	if pos == nil {
 		return nil
@@ -99,7 +112,7 @@ func (m *MockLPPositionService) RecordDeposit(ctx context.Context, positionID uu
 	return nil
 }
 
-func (m *MockLPPositionService) RecordWithdraw(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int) error {
+func (m *MockLPPositionService) RecordWithdraw(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int, at time.Time) error {
 	pos := m.positions[positionID]
 	if pos == nil {
 		return nil
@@ -110,7 +123,7 @@ func (m *MockLPPositionService) RecordWithdraw(ctx context.Context, positionID u
 	return nil
 }
 
-func (m *MockLPPositionService) RecordClaimFees(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int) error {
+func (m *MockLPPositionService) RecordClaimFees(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int, at time.Time) error {
 	pos := m.positions[positionID]
 	if pos == nil {
 		return nil
@@ -121,6 +134,43 @@ func (m *MockLPPositionService) RecordClaimFees(ctx context.Context, positionID
 	return nil
 }
 
+func (m *MockLPPositionService) ListByUser(ctx context.Context, userID uuid.UUID, status *lpposition.Status, walletID *uuid.UUID, chainID *string) ([]*lpposition.LPPosition, error) {
+	var result []*lpposition.LPPosition
+	for _, pos := range m.positions {
+		if pos.UserID != userID || (status != nil && pos.Status != *status) || (walletID != nil && pos.WalletID != *walletID) {
+			continue
+		}
+		result = append(result, pos)
+	}
+	return result, nil
+}
+
+func (m *MockLPPositionService) RecordValuation(ctx context.Context, positionID uuid.UUID, valueUSD *big.Int, at time.Time) error {
+	pos := m.positions[positionID]
+	if pos == nil {
+		return nil
+	}
+	pos.CurrentValueUSD = valueUSD
+	pos.ValuedAt = &at
+	return nil
+}
+
+func (m *MockLPPositionService) RecordRangeState(ctx context.Context, positionID uuid.UUID, state lpposition.RangeState, at time.Time) error {
+	pos := m.positions[positionID]
+	if pos == nil {
+		return nil
+	}
+	inRange := state.InRange()
+	pos.TickLower = &state.TickLower
+	pos.TickUpper = &state.TickUpper
+	pos.CurrentTick = &state.CurrentTick
+	pos.InRange = &inRange
+	pos.UncollectedFeesToken0 = state.UncollectedToken0
+	pos.UncollectedFeesToken1 = state.UncollectedToken1
+	pos.RangeUpdatedAt = &at
+	return nil
+}
+
 var _ sync.LPPositionService = (*MockLPPositionService)(nil)
 
 // =============================================================================
@@ -142,7 +192,7 @@ func TestSync_LP_FullLifecycle(t *testing.T) {
 	ledgerSvc.On("RecordTransaction", ctx, mock.Anything, "zerion", mock.Anything, mock.Anything, mock.Anything).
 		Return(&ledger.Transaction{ID: uuid.New()}, nil)
 
-	processor := sync.NewZerionProcessor(walletRepo, ledgerSvc, lpSvc, nil, log)
+	processor := sync.NewZerionProcessor(walletRepo, ledgerSvc, lpSvc, nil, nil, nil, log)
 	w := newTestWallet(userID, walletAddr)
 
 	// ─── Step 1: LP Deposit ───────────────────────────────────────────────
@@ -340,7 +390,7 @@ func TestSync_LP_UniV3Mint_ClassifiesAsDeposit(t *testing.T) {
 	ledgerSvc.On("RecordTransaction", ctx, mock.Anything, "zerion", mock.Anything, mock.Anything, mock.Anything).
 		Return(&ledger.Transaction{ID: uuid.New()}, nil)
 
-	processor := sync.NewZerionProcessor(walletRepo, ledgerSvc, lpSvc, nil, log)
+	processor := sync.NewZerionProcessor(walletRepo, ledgerSvc, lpSvc, nil, nil, nil, log)
 	w := newTestWallet(userID, walletAddr)
 
 	// Mint operation on Uniswap V3 should classify as lp_deposit
@@ -386,7 +436,7 @@ func TestSync_LP_AaveDeposit_IsLendingSupply(t *testing.T) {
 	ledgerSvc.On("RecordTransaction", ctx, mock.Anything, "zerion", mock.Anything, mock.Anything, mock.Anything).
 		Return(&ledger.Transaction{ID: uuid.New()}, nil)
 
-	processor := sync.NewZerionProcessor(walletRepo, ledgerSvc, lpSvc, nil, log)
+	processor := sync.NewZerionProcessor(walletRepo, ledgerSvc, lpSvc, nil, nil, nil, log)
 	w := newTestWallet(userID, walletAddr)
 
 	// Aave deposit should be classified as lending_supply, not defi_deposit or lp_deposit
@@ -417,3 +467,60 @@ func TestSync_LP_AaveDeposit_IsLendingSupply(t *testing.T) {
 	assert.Equal(t, ledger.TxTypeLendingSupply, ledgerSvc.recordedTransactions[0].TxType)
 	assert.Len(t, lpSvc.positions, 0, "no LP position should be created for AAVE lending")
 }
+
+func TestSync_LP_FungiblePool_KeyedByLPToken(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	walletAddr := "0x1111111111111111111111111111111111111111"
+
+	walletRepo := new(MockWalletRepository)
+	ledgerSvc := new(MockLedgerService)
+	lpSvc := newMockLPPositionService()
+	log := logger.New("test", os.Stdout)
+
+	ledgerSvc.On("RecordTransaction", ctx, mock.Anything, "zerion", mock.Anything, mock.Anything, mock.Anything).
+		Return(&ledger.Transaction{ID: uuid.New()}, nil)
+
+	processor := sync.NewZerionProcessor(walletRepo, ledgerSvc, lpSvc, nil, nil, nil, log)
+	w := newTestWallet(userID, walletAddr)
+
+	underlying := func(dir sync.TransferDirection, weth, usdc int64) []sync.DecodedTransfer {
+		return []sync.DecodedTransfer{
+			{AssetSymbol: "WETH", ContractAddress: "0xweth", Decimals: 18, Amount: big.NewInt(weth), Direction: dir, USDPrice: big.NewInt(1)},
+			{AssetSymbol: "USDC", ContractAddress: "0xusdc", Decimals: 6, Amount: big.NewInt(usdc), Direction: dir, USDPrice: big.NewInt(1)},
+		}
+	}
+	lpToken := func(dir sync.TransferDirection) sync.DecodedTransfer {
+		return sync.DecodedTransfer{AssetSymbol: "UNI-V2", ContractAddress: "0xpair", Decimals: 18, Amount: big.NewInt(50), Direction: dir}
+	}
+
+	deposit := sync.DecodedTransaction{
+		ID: "v2-deposit", TxHash: "0xd", ChainID: "ethereum", OperationType: sync.OpDeposit, Protocol: "Uniswap V2",
+		Transfers: append(underlying(sync.DirectionOut, 100, 200), lpToken(sync.DirectionIn)),
+		MinedAt:   time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+	topUp := deposit
+	topUp.ID = "v2-deposit-2"
+	withdraw := sync.DecodedTransaction{
+		ID: "v2-withdraw", TxHash: "0xw", ChainID: "ethereum", OperationType: sync.OpWithdraw, Protocol: "Uniswap V2",
+		Transfers: append(underlying(sync.DirectionIn, 150, 300), lpToken(sync.DirectionOut)),
+		MinedAt:   time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	for _, tx := range []sync.DecodedTransaction{deposit, topUp, withdraw} {
+		require.NoError(t, processor.ProcessTransaction(ctx, w, tx))
+	}
+
+	require.Len(t, lpSvc.positions, 1, "deposits into the same pool share one position")
+	var pos *lpposition.LPPosition
+	for _, p := range lpSvc.positions {
+		pos = p
+	}
+	assert.Equal(t, "0xpair", pos.ContractAddress)
+	assert.Empty(t, pos.NFTTokenID)
+	assert.Equal(t, "WETH", pos.Token0Symbol)
+	assert.Equal(t, "USDC", pos.Token1Symbol)
+	assert.Equal(t, big.NewInt(200), pos.TotalDepositedToken0)
+	assert.Equal(t, big.NewInt(150), pos.TotalWithdrawnToken0)
+	assert.Equal(t, ledger.TxTypeLPWithdraw, ledgerSvc.recordedTransactions[2].TxType)
+}
@@ -0,0 +1,77 @@
+package sync
+
+// LPPositionKind describes how a DEX represents a liquidity position
+type LPPositionKind string
+
+const (
+	// LPKindNFT positions are ERC-721 tokens, one per position (Uniswap V3 and forks)
+	LPKindNFT LPPositionKind = "nft"
+	// LPKindFungible positions are ERC-20 pool shares, one LP token per pool
+	LPKindFungible LPPositionKind = "fungible"
+)
+
+// LPProtocol describes the LP semantics of a DEX. Deposits and withdrawals
+// are detected from the operation type; claims count as fee collection only
+// when the protocol pays fees out separately.
+type LPProtocol struct {
+	Kind LPPositionKind
+	// FeeClaims is set when swap fees are collected by a claim. Otherwise
+	// fees compound into the LP token and claims are incentive rewards,
+	// which stay generic DeFi claims.
+	FeeClaims bool
+}
+
+// lpProtocols are the LP protocols recognised by name
+var lpProtocols = map[string]LPProtocol{
+	"Uniswap V3":           {Kind: LPKindNFT, FeeClaims: true},
+	"Uniswap V2":           {Kind: LPKindFungible},
+	"SushiSwap":            {Kind: LPKindFungible},
+	"PancakeSwap":          {Kind: LPKindFungible},
+	"PancakeSwap V2":       {Kind: LPKindFungible},
+	"PancakeSwap V3":       {Kind: LPKindNFT, FeeClaims: true},
+	"Curve":                {Kind: LPKindFungible},
+	"Balancer":             {Kind: LPKindFungible},
+	"Balancer V2":          {Kind: LPKindFungible},
+	"Velodrome":            {Kind: LPKindFungible, FeeClaims: true},
+	"Velodrome V2":         {Kind: LPKindFungible, FeeClaims: true},
+	"Velodrome Slipstream": {Kind: LPKindNFT, FeeClaims: true},
+	"Aerodrome":            {Kind: LPKindFungible, FeeClaims: true},
+	"Aerodrome Slipstream": {Kind: LPKindNFT, FeeClaims: true},
+}
+
+// lpProtocolFor returns the LP semantics for a transaction's protocol.
+// A transaction carrying a position NFT is an NFT position even when the
+// protocol name covers fungible pool versions too.
+func lpProtocolFor(tx DecodedTransaction) (LPProtocol, bool) {
+	proto, ok := lpProtocols[tx.Protocol]
+	if !ok {
+		return LPProtocol{}, false
+	}
+	if tx.NFTTokenID != "" {
+		proto.Kind = LPKindNFT
+	}
+	return proto, true
+}
+
+// isFungibleLP reports whether tx belongs to a fungible-pool LP protocol
+func isFungibleLP(tx DecodedTransaction) bool {
+	proto, ok := lpProtocolFor(tx)
+	return ok && proto.Kind == LPKindFungible
+}
+
+// lpTokenContract returns the LP token of a fungible-pool transaction: the
+// only token moving in dir (in for deposits, out for withdrawals). Returns
+// empty when the pool share cannot be told apart from the underlying tokens.
+func lpTokenContract(transfers []DecodedTransfer, dir TransferDirection) string {
+	var contract string
+	for _, t := range transfers {
+		if t.Direction != dir {
+			continue
+		}
+		if t.ContractAddress == "" || (contract != "" && contract != t.ContractAddress) {
+			return ""
+		}
+		contract = t.ContractAddress
+	}
+	return contract
+}
@@ -0,0 +1,64 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kislikjeka/moontrack/internal/platform/lpposition"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// LPRangeTracker refreshes the tick range, in-range flag and uncollected fees
+// of open NFT-based LP positions after each sync. Positions without an NFT
+// token ID (fungible LP tokens) have no range and are skipped.
+type LPRangeTracker struct {
+	provider LPRangeDataProvider
+	lpSvc    LPPositionService
+	logger   *logger.Logger
+}
+
+// NewLPRangeTracker creates a new LPRangeTracker
+func NewLPRangeTracker(provider LPRangeDataProvider, lpSvc LPPositionService, log *logger.Logger) *LPRangeTracker {
+	return &LPRangeTracker{
+		provider: provider,
+		lpSvc:    lpSvc,
+		logger:   log.WithField("component", "lp_range"),
+	}
+}
+
+// Refresh records a range snapshot for every open NFT position in the wallet.
+// Returns the number of positions refreshed.
+func (t *LPRangeTracker) Refresh(ctx context.Context, w *wallet.Wallet) (int, error) {
+	open := lpposition.StatusOpen
+	positions, err := t.lpSvc.ListByUser(ctx, w.UserID, &open, &w.ID, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list open LP positions: %w", err)
+	}
+
+	now := time.Now().UTC()
+	refreshed := 0
+	for _, pos := range positions {
+		if pos.NFTTokenID == "" {
+			continue
+		}
+
+		state, err := t.provider.GetLPRangeState(ctx, pos.ChainID, pos.Protocol, pos.NFTTokenID, w.Address)
+		if err != nil {
+			t.logger.Warn("failed to read LP range state", "position_id", pos.ID, "error", err)
+			continue
+		}
+		if state == nil {
+			continue
+		}
+
+		if err := t.lpSvc.RecordRangeState(ctx, pos.ID, *state, now); err != nil {
+			t.logger.Error("failed to record LP range state", "position_id", pos.ID, "error", err)
+			continue
+		}
+		refreshed++
+	}
+
+	return refreshed, nil
+}
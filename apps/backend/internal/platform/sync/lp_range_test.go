@@ -0,0 +1,76 @@
+package sync_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/platform/lpposition"
+	"github.com/kislikjeka/moontrack/internal/platform/sync"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// fakeRangeProvider serves range snapshots keyed by NFT token ID
+type fakeRangeProvider struct {
+	states map[string]*lpposition.RangeState
+	err    error
+	calls  int
+}
+
+func (f *fakeRangeProvider) GetLPRangeState(ctx context.Context, chainID, protocol, nftTokenID, owner string) (*lpposition.RangeState, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.states[nftTokenID], nil
+}
+
+func TestLPRangeTracker_RecordsStateForNFTPositions(t *testing.T) {
+	w := newTestWallet(uuid.New(), "0xabc")
+	lpSvc := newMockLPPositionService()
+	pos := addOpenLPPosition(lpSvc, w.UserID, w.ID)
+	fungible := addOpenLPPosition(lpSvc, w.UserID, w.ID)
+	fungible.NFTTokenID = ""
+
+	provider := &fakeRangeProvider{states: map[string]*lpposition.RangeState{
+		pos.NFTTokenID: {
+			TickLower:         -887220,
+			TickUpper:         -200000,
+			CurrentTick:       -199000,
+			UncollectedToken0: big.NewInt(123),
+			UncollectedToken1: big.NewInt(456),
+		},
+	}}
+
+	refreshed, err := sync.NewLPRangeTracker(provider, lpSvc, logger.NewDefault("test")).Refresh(context.Background(), w)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, refreshed)
+	assert.Equal(t, 1, provider.calls)
+	require.NotNil(t, pos.InRange)
+	assert.False(t, *pos.InRange)
+	assert.Equal(t, -199000, *pos.CurrentTick)
+	assert.Equal(t, big.NewInt(123), pos.UncollectedFeesToken0)
+	assert.Equal(t, big.NewInt(456), pos.UncollectedFeesToken1)
+	assert.NotNil(t, pos.RangeUpdatedAt)
+	assert.Nil(t, fungible.RangeUpdatedAt)
+}
+
+func TestLPRangeTracker_SkipsProviderFailures(t *testing.T) {
+	w := newTestWallet(uuid.New(), "0xabc")
+	lpSvc := newMockLPPositionService()
+	pos := addOpenLPPosition(lpSvc, w.UserID, w.ID)
+
+	provider := &fakeRangeProvider{err: errors.New("rpc down")}
+
+	refreshed, err := sync.NewLPRangeTracker(provider, lpSvc, logger.NewDefault("test")).Refresh(context.Background(), w)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, refreshed)
+	assert.Nil(t, pos.RangeUpdatedAt)
+}
@@ -0,0 +1,142 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/kislikjeka/moontrack/internal/platform/lpposition"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// LPValuation refreshes the current value of open LP positions after each
+// sync so unrealized PnL and APR stay live. Values come from the provider's
+// DeFi positions when a position can be matched unambiguously, otherwise from
+// the position's remaining tokens rebalanced to current market prices.
+type LPValuation struct {
+	lpProvider LPPositionDataProvider // nilable — model-only valuation without it
+	assetSvc   AssetService           // nilable — provider-only valuation without it
+	lpSvc      LPPositionService
+	logger     *logger.Logger
+}
+
+// NewLPValuation creates a new LPValuation
+func NewLPValuation(lpProvider LPPositionDataProvider, assetSvc AssetService, lpSvc LPPositionService, log *logger.Logger) *LPValuation {
+	return &LPValuation{
+		lpProvider: lpProvider,
+		assetSvc:   assetSvc,
+		lpSvc:      lpSvc,
+		logger:     log.WithField("component", "lp_valuation"),
+	}
+}
+
+// Revalue records a valuation for every open LP position in the wallet.
+// Returns the number of positions valued.
+func (v *LPValuation) Revalue(ctx context.Context, w *wallet.Wallet) (int, error) {
+	open := lpposition.StatusOpen
+	positions, err := v.lpSvc.ListByUser(ctx, w.UserID, &open, &w.ID, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list open LP positions: %w", err)
+	}
+	if len(positions) == 0 {
+		return 0, nil
+	}
+
+	var onChain []OnChainLPPosition
+	if v.lpProvider != nil {
+		onChain, err = v.lpProvider.GetLPPositions(ctx, w.Address)
+		if err != nil {
+			v.logger.Warn("failed to get on-chain LP positions, falling back to model",
+				"wallet_id", w.ID, "error", err)
+		}
+	}
+
+	now := time.Now().UTC()
+	valued := 0
+	for _, pos := range positions {
+		value, ok := matchOnChainValue(pos, positions, onChain)
+		if !ok {
+			value, ok = v.modelValue(ctx, pos)
+		}
+		if !ok {
+			continue
+		}
+
+		if err := v.lpSvc.RecordValuation(ctx, pos.ID, value, now); err != nil {
+			v.logger.Error("failed to record LP valuation", "position_id", pos.ID, "error", err)
+			continue
+		}
+		valued++
+	}
+
+	return valued, nil
+}
+
+// matchOnChainValue returns the provider's value for pos when exactly one
+// on-chain position holds its token pair and no other open position in the
+// wallet shares that pair; otherwise the value cannot be attributed.
+func matchOnChainValue(pos *lpposition.LPPosition, open []*lpposition.LPPosition, onChain []OnChainLPPosition) (*big.Int, bool) {
+	var match *OnChainLPPosition
+	for i := range onChain {
+		if !samePool(pos, onChain[i].ChainID, onChain[i].Protocol) || !holdsPair(onChain[i], pos.Token0Symbol, pos.Token1Symbol) {
+			continue
+		}
+		if match != nil {
+			return nil, false
+		}
+		match = &onChain[i]
+	}
+	if match == nil || match.ValueUSD == nil {
+		return nil, false
+	}
+
+	for _, other := range open {
+		if other.ID != pos.ID && samePool(other, pos.ChainID, pos.Protocol) && holdsPair(*match, other.Token0Symbol, other.Token1Symbol) {
+			return nil, false
+		}
+	}
+	return match.ValueUSD, true
+}
+
+func samePool(pos *lpposition.LPPosition, chainID, protocol string) bool {
+	return pos.ChainID == chainID && strings.EqualFold(pos.Protocol, protocol)
+}
+
+func holdsPair(p OnChainLPPosition, symbol0, symbol1 string) bool {
+	var has0, has1 bool
+	for _, t := range p.Tokens {
+		has0 = has0 || strings.EqualFold(t.AssetSymbol, symbol0)
+		has1 = has1 || strings.EqualFold(t.AssetSymbol, symbol1)
+	}
+	return has0 && has1
+}
+
+// modelValue values the position's estimated pool holdings at market prices
+func (v *LPValuation) modelValue(ctx context.Context, pos *lpposition.LPPosition) (*big.Int, bool) {
+	if v.assetSvc == nil {
+		return nil, false
+	}
+
+	price0 := v.price(ctx, pos.Token0Symbol)
+	price1 := v.price(ctx, pos.Token1Symbol)
+	if price0.Sign() == 0 || price1.Sign() == 0 {
+		v.logger.Debug("skipping LP valuation without prices", "position_id", pos.ID)
+		return nil, false
+	}
+
+	held0, held1 := lpposition.EstimateHoldings(pos, price0, price1)
+	value := money.CalcUSDValue(held0, price0, pos.Token0Decimals)
+	return value.Add(value, money.CalcUSDValue(held1, price1, pos.Token1Decimals)), true
+}
+
+func (v *LPValuation) price(ctx context.Context, symbol string) *big.Int {
+	price, err := v.assetSvc.GetPriceBySymbol(ctx, symbol)
+	if err != nil || price == nil {
+		return big.NewInt(0)
+	}
+	return price
+}
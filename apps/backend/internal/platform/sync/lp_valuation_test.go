@@ -0,0 +1,102 @@
+package sync_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/platform/lpposition"
+	"github.com/kislikjeka/moontrack/internal/platform/sync"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+type stubLPProvider struct {
+	positions []sync.OnChainLPPosition
+}
+
+func (s *stubLPProvider) GetLPPositions(ctx context.Context, address string) ([]sync.OnChainLPPosition, error) {
+	return s.positions, nil
+}
+
+type stubPrices map[string]*big.Int
+
+func (s stubPrices) GetPriceBySymbol(ctx context.Context, symbol string) (*big.Int, error) {
+	return s[symbol], nil
+}
+
+func addOpenLPPosition(lpSvc *MockLPPositionService, userID, walletID uuid.UUID) *lpposition.LPPosition {
+	pos, _ := lpSvc.FindOrCreate(context.Background(), userID, walletID, "ethereum", "Uniswap V3", uuid.NewString(), "",
+		lpposition.TokenInfo{Symbol: "WETH", Decimals: 18},
+		lpposition.TokenInfo{Symbol: "USDC", Decimals: 6},
+		time.Now().Add(-30*24*time.Hour))
+	pos.TotalDepositedToken0 = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil) // 1 WETH
+	pos.TotalDepositedToken1 = big.NewInt(2000_000_000)                              // 2000 USDC
+	return pos
+}
+
+func newLPValuation(provider sync.LPPositionDataProvider, prices sync.AssetService, lpSvc *MockLPPositionService) *sync.LPValuation {
+	return sync.NewLPValuation(provider, prices, lpSvc, logger.NewDefault("test"))
+}
+
+func TestLPValuation_UsesMatchingOnChainPosition(t *testing.T) {
+	w := newTestWallet(uuid.New(), "0xabc")
+	lpSvc := newMockLPPositionService()
+	pos := addOpenLPPosition(lpSvc, w.UserID, w.ID)
+
+	provider := &stubLPProvider{positions: []sync.OnChainLPPosition{{
+		ChainID:  "ethereum",
+		Protocol: "uniswap v3",
+		Tokens:   []sync.OnChainPosition{{AssetSymbol: "WETH"}, {AssetSymbol: "USDC"}},
+		ValueUSD: big.NewInt(4321_00000000),
+	}}}
+
+	valued, err := newLPValuation(provider, nil, lpSvc).Revalue(context.Background(), w)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, valued)
+	assert.Equal(t, big.NewInt(4321_00000000), pos.CurrentValueUSD)
+	assert.NotNil(t, pos.ValuedAt)
+}
+
+func TestLPValuation_FallsBackToModelWhenAmbiguous(t *testing.T) {
+	w := newTestWallet(uuid.New(), "0xabc")
+	lpSvc := newMockLPPositionService()
+	first := addOpenLPPosition(lpSvc, w.UserID, w.ID)
+	second := addOpenLPPosition(lpSvc, w.UserID, w.ID)
+
+	// One on-chain pool cannot be split between two tracked positions
+	provider := &stubLPProvider{positions: []sync.OnChainLPPosition{{
+		ChainID:  "ethereum",
+		Protocol: "Uniswap V3",
+		Tokens:   []sync.OnChainPosition{{AssetSymbol: "WETH"}, {AssetSymbol: "USDC"}},
+		ValueUSD: big.NewInt(9999_00000000),
+	}}}
+	prices := stubPrices{"WETH": big.NewInt(2000_00000000), "USDC": big.NewInt(1_00000000)}
+
+	valued, err := newLPValuation(provider, prices, lpSvc).Revalue(context.Background(), w)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, valued)
+	// 1 WETH + 2000 USDC at an unchanged price is worth $4000
+	for _, pos := range []*lpposition.LPPosition{first, second} {
+		require.NotNil(t, pos.CurrentValueUSD)
+		assert.InDelta(t, 4000_00000000, pos.CurrentValueUSD.Int64(), 100)
+	}
+}
+
+func TestLPValuation_SkipsWithoutData(t *testing.T) {
+	w := newTestWallet(uuid.New(), "0xabc")
+	lpSvc := newMockLPPositionService()
+	pos := addOpenLPPosition(lpSvc, w.UserID, w.ID)
+
+	valued, err := newLPValuation(nil, stubPrices{}, lpSvc).Revalue(context.Background(), w)
+
+	require.NoError(t, err)
+	assert.Zero(t, valued)
+	assert.Nil(t, pos.CurrentValueUSD)
+}
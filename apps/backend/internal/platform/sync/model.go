@@ -62,6 +62,26 @@ func (f *AssetFlow) NetFlow() *big.Int {
 	return new(big.Int).Sub(f.Inflow, f.Outflow)
 }
 
+// OnChainLPPosition is a liquidity position from Zerion Positions API, with
+// one entry per underlying token
+type OnChainLPPosition struct {
+	ChainID     string
+	Protocol    string
+	PoolAddress string // empty if unknown
+	Tokens      []OnChainPosition
+	ValueUSD    *big.Int // USD scaled by 1e8
+}
+
+// OnChainLendingPosition is a wallet's account on a lending protocol from
+// Zerion Positions API: supplied and borrowed balances, accrued interest
+// included
+type OnChainLendingPosition struct {
+	ChainID  string
+	Protocol string
+	Supplied []OnChainPosition
+	Borrowed []OnChainPosition
+}
+
 // OnChainPosition represents an on-chain token balance from Zerion Positions API
 type OnChainPosition struct {
 	ChainID         string
@@ -10,8 +10,10 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/derivativeposition"
 	"github.com/kislikjeka/moontrack/internal/platform/lendingposition"
 	"github.com/kislikjeka/moontrack/internal/platform/lpposition"
+	"github.com/kislikjeka/moontrack/internal/platform/stakingposition"
 	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 )
 
@@ -26,6 +28,9 @@ const (
 // LedgerService defines the interface for ledger operations needed by sync
 type LedgerService interface {
 	RecordTransaction(ctx context.Context, transactionType ledger.TransactionType, source string, externalID *string, occurredAt time.Time, rawData map[string]interface{}) (*ledger.Transaction, error)
+
+	// GetBalance returns the ledger balance of a wallet asset account
+	GetBalance(ctx context.Context, walletID uuid.UUID, chain string, assetID string) (*big.Int, error)
 }
 
 // WalletRepository defines wallet data access for sync operations
@@ -63,6 +68,25 @@ type PositionDataProvider interface {
 	GetPositions(ctx context.Context, address string) ([]OnChainPosition, error)
 }
 
+// LPPositionDataProvider fetches DeFi liquidity positions from an external API.
+// Position providers may optionally implement it.
+type LPPositionDataProvider interface {
+	GetLPPositions(ctx context.Context, address string) ([]OnChainLPPosition, error)
+}
+
+// LendingPositionDataProvider fetches lending protocol balances, which grow
+// with accrued interest. Position providers may optionally implement it.
+type LendingPositionDataProvider interface {
+	GetLendingPositions(ctx context.Context, address string) ([]OnChainLendingPosition, error)
+}
+
+// LPRangeDataProvider reads the on-chain tick range, current pool tick and
+// uncollected fees of a concentrated-liquidity position NFT. Returns nil when
+// the chain or protocol is not supported.
+type LPRangeDataProvider interface {
+	GetLPRangeState(ctx context.Context, chainID, protocol, nftTokenID, owner string) (*lpposition.RangeState, error)
+}
+
 // isDuplicateError checks if the error is due to a unique constraint violation (PostgreSQL error code 23505)
 func isDuplicateError(err error) bool {
 	if err == nil {
@@ -86,6 +110,8 @@ const (
 	OpApprove  OperationType = "approve"
 	OpMint     OperationType = "mint"
 	OpBurn     OperationType = "burn"
+	OpBorrow   OperationType = "borrow"
+	OpRepay    OperationType = "repay"
 )
 
 // DecodedTransaction represents a fully decoded blockchain transaction
@@ -101,6 +127,7 @@ type DecodedTransaction struct {
 	Status        string   // "confirmed", "pending", "failed"
 	NFTTokenID    string   // Uniswap V3 NFT position ID, empty if not applicable
 	Acts          []string // Action types from Zerion acts array (e.g., ["claim", "execute"])
+	NFTTransfers  []DecodedNFTTransfer
 }
 
 // DecodedTransfer represents a single token movement within a decoded transaction
@@ -117,6 +144,19 @@ type DecodedTransfer struct {
 	IconURL         string            // Token icon URL, empty if unavailable
 }
 
+// DecodedNFTTransfer represents a single NFT movement within a decoded transaction
+type DecodedNFTTransfer struct {
+	ContractAddress string            // Lowercase collection contract address
+	TokenID         string
+	Name            string            // Token or collection name, empty if unknown
+	Standard        string            // "erc721" or "erc1155"
+	Quantity        *big.Int          // Number of tokens (1 for ERC-721, never nil)
+	Direction       TransferDirection // "in" or "out"
+	Sender          string            // Lowercase address
+	Recipient       string            // Lowercase address
+	USDPrice        *big.Int          // USD value per token scaled by 1e8, nil if unavailable
+}
+
 // DecodedFee represents the gas fee for a decoded transaction
 type DecodedFee struct {
 	AssetSymbol string
@@ -135,20 +175,46 @@ type TransactionDataProvider interface {
 // LPPositionService manages LP position lifecycle
 type LPPositionService interface {
 	FindOrCreate(ctx context.Context, userID, walletID uuid.UUID, chainID, protocol, nftTokenID, contractAddress string, token0, token1 lpposition.TokenInfo, openedAt time.Time) (*lpposition.LPPosition, error)
-	FindOpenByTokenPair(ctx context.Context, walletID uuid.UUID, chainID, protocol, token0, token1 string) (*lpposition.LPPosition, error)
-	RecordDeposit(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int) error
-	RecordWithdraw(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int) error
-	RecordClaimFees(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int) error
+	FindOpenByTokenPair(ctx context.Context, walletID uuid.UUID, chainID, protocol, token0Contract, token1Contract string) (*lpposition.LPPosition, error)
+	FindOpenByContract(ctx context.Context, walletID uuid.UUID, chainID, contractAddress string) (*lpposition.LPPosition, error)
+	RecordDeposit(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int, at time.Time) error
+	RecordWithdraw(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int, at time.Time) error
+	RecordClaimFees(ctx context.Context, positionID uuid.UUID, token0Amt, token1Amt, usdValue *big.Int, at time.Time) error
+	ListByUser(ctx context.Context, userID uuid.UUID, status *lpposition.Status, walletID *uuid.UUID, chainID *string) ([]*lpposition.LPPosition, error)
+	RecordValuation(ctx context.Context, positionID uuid.UUID, valueUSD *big.Int, at time.Time) error
+	RecordRangeState(ctx context.Context, positionID uuid.UUID, state lpposition.RangeState, at time.Time) error
 }
 
-// LendingPositionService manages lending position lifecycle
+// LendingPositionService manages lending accounts and their supply and borrow legs
 type LendingPositionService interface {
-	FindOrCreate(ctx context.Context, userID, walletID uuid.UUID, protocol, chainID, supplyAsset string, supplyDecimals int, supplyContract string, openedAt time.Time) (*lendingposition.LendingPosition, error)
-	RecordSupply(ctx context.Context, positionID uuid.UUID, amount, usdValue *big.Int) error
-	RecordWithdraw(ctx context.Context, positionID uuid.UUID, amount, usdValue *big.Int) error
-	RecordBorrow(ctx context.Context, positionID uuid.UUID, borrowAsset string, borrowDecimals int, borrowContract string, amount, usdValue *big.Int) error
-	RecordRepay(ctx context.Context, positionID uuid.UUID, amount, usdValue *big.Int) error
+	FindOrCreate(ctx context.Context, userID, walletID uuid.UUID, protocol, chainID string, openedAt time.Time) (*lendingposition.LendingPosition, error)
+	RecordSupply(ctx context.Context, positionID uuid.UUID, asset lendingposition.AssetInfo, amount, usdValue *big.Int) error
+	RecordWithdraw(ctx context.Context, positionID uuid.UUID, asset lendingposition.AssetInfo, amount, usdValue *big.Int) error
+	RecordBorrow(ctx context.Context, positionID uuid.UUID, asset lendingposition.AssetInfo, amount, usdValue *big.Int) error
+	RecordRepay(ctx context.Context, positionID uuid.UUID, asset lendingposition.AssetInfo, amount, usdValue *big.Int) error
 	RecordClaim(ctx context.Context, positionID uuid.UUID, usdValue *big.Int) error
+	RecordLiquidation(ctx context.Context, positionID uuid.UUID, collateral lendingposition.AssetInfo, seized, seizedUSD *big.Int, debt lendingposition.AssetInfo, repaid, repaidUSD *big.Int, at time.Time) error
+	RecordInterest(ctx context.Context, positionID uuid.UUID, side lendingposition.Side, asset lendingposition.AssetInfo, amount, usdValue *big.Int) error
+	RecordRisk(ctx context.Context, positionID uuid.UUID, prices map[uuid.UUID]*big.Int, at time.Time) error
+	ListByUser(ctx context.Context, userID uuid.UUID, status *lendingposition.Status, walletID *uuid.UUID, chainID *string) ([]*lendingposition.LendingPosition, error)
+}
+
+// StakingPositionService manages staking position lifecycle
+type StakingPositionService interface {
+	FindOrCreate(ctx context.Context, userID, walletID uuid.UUID, protocol, chainID, asset string, assetDecimals int, receiptAsset string, receiptDecimals int, receiptContract string, openedAt time.Time) (*stakingposition.StakingPosition, error)
+	RecordStake(ctx context.Context, positionID uuid.UUID, amount, usdValue, receiptAmount *big.Int) error
+	RecordUnstake(ctx context.Context, positionID uuid.UUID, amount, usdValue, receiptAmount *big.Int) error
+	RecordReward(ctx context.Context, positionID uuid.UUID, amount, usdValue *big.Int) error
+	ListActiveRebasing(ctx context.Context, walletID uuid.UUID) ([]*stakingposition.StakingPosition, error)
+}
+
+// DerivativePositionService manages derivative position lifecycle
+type DerivativePositionService interface {
+	FindOrCreate(ctx context.Context, userID, walletID uuid.UUID, protocol, chainID, market, asset string, assetDecimals int, assetContract string, openedAt time.Time) (*derivativeposition.DerivativePosition, error)
+	FindActive(ctx context.Context, walletID uuid.UUID, protocol, chainID, asset string) (*derivativeposition.DerivativePosition, error)
+	RecordOpen(ctx context.Context, positionID uuid.UUID, amount, usdValue *big.Int) error
+	RecordClose(ctx context.Context, positionID uuid.UUID, received, receivedUSD, released, releasedUSD *big.Int, at time.Time) error
+	RecordFundingClaim(ctx context.Context, positionID uuid.UUID, amount, usdValue *big.Int) error
 }
 
 // AssetService defines asset operations for sync
@@ -24,6 +24,10 @@ type Service struct {
 	collector       *Collector
 	reconciler      *Reconciler
 	processor       *Processor
+	stakingAccrual  *StakingAccrual
+	lendingAccrual  *LendingAccrual
+	lpValuation     *LPValuation
+	lpRangeTracker  *LPRangeTracker
 	logger          *logger.Logger
 	wg              sync.WaitGroup
 	stopCh          chan struct{}
@@ -44,6 +48,9 @@ func NewService(
 	zerionAssetRepo ZerionAssetRepository,
 	lpPositionSvc LPPositionService,
 	lendingPositionSvc LendingPositionService,
+	stakingPositionSvc StakingPositionService,
+	derivativePositionSvc DerivativePositionService,
+	lpRangeProvider LPRangeDataProvider,
 ) *Service {
 	if config == nil {
 		config = DefaultConfig()
@@ -52,7 +59,7 @@ func NewService(
 
 	var zerionProc *ZerionProcessor
 	if zerionProvider != nil {
-		zerionProc = NewZerionProcessor(walletRepo, ledgerSvc, lpPositionSvc, lendingPositionSvc, logger)
+		zerionProc = NewZerionProcessor(walletRepo, ledgerSvc, lpPositionSvc, lendingPositionSvc, stakingPositionSvc, derivativePositionSvc, logger)
 	}
 
 	svc := &Service{
@@ -75,6 +82,19 @@ func NewService(
 	if posProvider != nil && rawTxRepo != nil {
 		svc.reconciler = NewReconciler(rawTxRepo, posProvider, walletRepo, zerionAssetRepo, logger)
 	}
+	if posProvider != nil && stakingPositionSvc != nil {
+		svc.stakingAccrual = NewStakingAccrual(posProvider, ledgerSvc, stakingPositionSvc, logger)
+	}
+	if lendingProvider, ok := posProvider.(LendingPositionDataProvider); ok && lendingPositionSvc != nil {
+		svc.lendingAccrual = NewLendingAccrual(lendingProvider, ledgerSvc, lendingPositionSvc, logger)
+	}
+	if lpPositionSvc != nil {
+		lpProvider, _ := posProvider.(LPPositionDataProvider)
+		svc.lpValuation = NewLPValuation(lpProvider, assetSvc, lpPositionSvc, logger)
+	}
+	if lpRangeProvider != nil && lpPositionSvc != nil {
+		svc.lpRangeTracker = NewLPRangeTracker(lpRangeProvider, lpPositionSvc, logger)
+	}
 
 	return svc
 }
@@ -266,6 +286,46 @@ func (s *Service) syncWallet(ctx context.Context, w *wallet.Wallet) error {
 		}
 	}
 
+	// Book rebasing staking rewards that accrued without a transaction
+	if s.stakingAccrual != nil {
+		accrued, err := s.stakingAccrual.Accrue(ctx, w)
+		if err != nil {
+			s.logger.Error("staking accrual failed", "wallet_id", w.ID, "error", err)
+		} else if accrued > 0 {
+			s.logger.Info("staking accrual complete", "wallet_id", w.ID, "rewards_recorded", accrued)
+		}
+	}
+
+	// Book lending interest and refresh health factor, LTV and liquidation price
+	if s.lendingAccrual != nil {
+		accrued, err := s.lendingAccrual.Accrue(ctx, w)
+		if err != nil {
+			s.logger.Error("lending accrual failed", "wallet_id", w.ID, "error", err)
+		} else if accrued > 0 {
+			s.logger.Info("lending accrual complete", "wallet_id", w.ID, "interest_recorded", accrued)
+		}
+	}
+
+	// Refresh live value, unrealized PnL and APR of open LP positions
+	if s.lpValuation != nil {
+		valued, err := s.lpValuation.Revalue(ctx, w)
+		if err != nil {
+			s.logger.Error("LP valuation failed", "wallet_id", w.ID, "error", err)
+		} else if valued > 0 {
+			s.logger.Info("LP valuation complete", "wallet_id", w.ID, "positions_valued", valued)
+		}
+	}
+
+	// Refresh tick range, in-range flag and uncollected fees of NFT LP positions
+	if s.lpRangeTracker != nil {
+		refreshed, err := s.lpRangeTracker.Refresh(ctx, w)
+		if err != nil {
+			s.logger.Error("LP range refresh failed", "wallet_id", w.ID, "error", err)
+		} else if refreshed > 0 {
+			s.logger.Info("LP range refresh complete", "wallet_id", w.ID, "positions_refreshed", refreshed)
+		}
+	}
+
 	// Reset sync phase to idle after completion
 	_ = s.walletRepo.SetSyncPhase(ctx, w.ID, string(SyncPhaseIdle))
 
@@ -66,9 +66,9 @@ func setupIntegrationTest(t *testing.T) *testEnv {
 
 	// Create ledger service with transfer handlers
 	registry := ledger.NewRegistry()
-	registry.Register(transfer.NewTransferInHandler(walletRepo, log))
-	registry.Register(transfer.NewTransferOutHandler(walletRepo, log))
-	registry.Register(transfer.NewInternalTransferHandler(walletRepo, log))
+	registry.Register(transfer.NewTransferInHandler(walletRepo, nil, log))
+	registry.Register(transfer.NewTransferOutHandler(walletRepo, nil, log))
+	registry.Register(transfer.NewInternalTransferHandler(walletRepo, nil, log))
 	ledgerSvc := ledger.NewService(ledgerRepo, registry, log)
 
 	// Create mock Zerion provider
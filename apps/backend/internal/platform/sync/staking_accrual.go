@@ -0,0 +1,131 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/stakingposition"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// StakingAccrual books rebasing-token rewards. Tokens such as stETH grow in
+// the holder's wallet without any transfer, so the growth never appears in the
+// transaction feed; it is detected by comparing the on-chain balance of the
+// receipt token with the ledger balance.
+type StakingAccrual struct {
+	posProvider PositionDataProvider
+	ledgerSvc   LedgerService
+	stakingSvc  StakingPositionService
+	logger      *logger.Logger
+}
+
+// NewStakingAccrual creates a new StakingAccrual
+func NewStakingAccrual(posProvider PositionDataProvider, ledgerSvc LedgerService, stakingSvc StakingPositionService, log *logger.Logger) *StakingAccrual {
+	return &StakingAccrual{
+		posProvider: posProvider,
+		ledgerSvc:   ledgerSvc,
+		stakingSvc:  stakingSvc,
+		logger:      log.WithField("component", "staking_accrual"),
+	}
+}
+
+// Accrue records a staking_reward for every active rebasing position whose
+// on-chain receipt balance exceeds the ledger balance. Returns the number of
+// rewards recorded.
+func (a *StakingAccrual) Accrue(ctx context.Context, w *wallet.Wallet) (int, error) {
+	positions, err := a.stakingSvc.ListActiveRebasing(ctx, w.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list rebasing positions: %w", err)
+	}
+	if len(positions) == 0 {
+		return 0, nil
+	}
+
+	onChain, err := a.posProvider.GetPositions(ctx, w.Address)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get on-chain positions: %w", err)
+	}
+
+	recorded := 0
+	for _, pos := range positions {
+		oc := findOnChainPosition(onChain, pos.ChainID, pos.ReceiptAsset)
+		if oc == nil || oc.Quantity == nil {
+			continue
+		}
+
+		booked, err := a.ledgerSvc.GetBalance(ctx, w.ID, pos.ChainID, pos.ReceiptAsset)
+		if err != nil {
+			a.logger.Error("failed to get ledger balance",
+				"wallet_id", w.ID, "position_id", pos.ID, "asset", pos.ReceiptAsset, "error", err)
+			continue
+		}
+		if booked == nil {
+			booked = big.NewInt(0)
+		}
+
+		delta := new(big.Int).Sub(oc.Quantity, booked)
+		if delta.Sign() <= 0 {
+			continue
+		}
+
+		if err := a.recordReward(ctx, w, pos, oc, delta); err != nil {
+			if isDuplicateError(err) {
+				continue
+			}
+			a.logger.Error("failed to record rebasing reward",
+				"wallet_id", w.ID, "position_id", pos.ID, "delta", delta.String(), "error", err)
+			continue
+		}
+		recorded++
+	}
+
+	return recorded, nil
+}
+
+func (a *StakingAccrual) recordReward(ctx context.Context, w *wallet.Wallet, pos *stakingposition.StakingPosition, oc *OnChainPosition, delta *big.Int) error {
+	now := time.Now().UTC()
+	// The on-chain balance identifies the accrual, so re-running the same sync
+	// produces the same external ID and is rejected as a duplicate.
+	ref := fmt.Sprintf("rebase:%s:%s", pos.ID, oc.Quantity.String())
+
+	data := map[string]interface{}{
+		"wallet_id":        w.ID.String(),
+		"tx_hash":          ref,
+		"chain_id":         pos.ChainID,
+		"occurred_at":      now.Format(time.RFC3339),
+		"protocol":         pos.Protocol,
+		"asset":            pos.ReceiptAsset,
+		"amount":           money.NewBigInt(delta).String(),
+		"decimals":         pos.ReceiptDecimals,
+		"contract_address": pos.ReceiptContract,
+	}
+	usd := big.NewInt(0)
+	if oc.USDPrice != nil {
+		data["usd_price"] = oc.USDPrice.String()
+		usd = money.CalcUSDValue(delta, oc.USDPrice, pos.ReceiptDecimals)
+	}
+
+	if _, err := a.ledgerSvc.RecordTransaction(ctx, ledger.TxTypeStakingReward, "staking_accrual", &ref, now, data); err != nil {
+		return err
+	}
+
+	if err := a.stakingSvc.RecordReward(ctx, pos.ID, delta, usd); err != nil {
+		a.logger.Error("failed to update position rewards", "position_id", pos.ID, "error", err)
+	}
+	return nil
+}
+
+func findOnChainPosition(positions []OnChainPosition, chainID, symbol string) *OnChainPosition {
+	for i := range positions {
+		if positions[i].ChainID == chainID && strings.EqualFold(positions[i].AssetSymbol, symbol) {
+			return &positions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package sync_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/stakingposition"
+	"github.com/kislikjeka/moontrack/internal/platform/sync"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+type MockStakingPositionService struct {
+	mock.Mock
+}
+
+func (m *MockStakingPositionService) FindOrCreate(ctx context.Context, userID, walletID uuid.UUID, protocol, chainID, asset string, assetDecimals int, receiptAsset string, receiptDecimals int, receiptContract string, openedAt time.Time) (*stakingposition.StakingPosition, error) {
+	args := m.Called(ctx, userID, walletID, protocol, chainID, asset, assetDecimals, receiptAsset, receiptDecimals, receiptContract, openedAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*stakingposition.StakingPosition), args.Error(1)
+}
+
+func (m *MockStakingPositionService) RecordStake(ctx context.Context, positionID uuid.UUID, amount, usdValue, receiptAmount *big.Int) error {
+	return m.Called(ctx, positionID, amount, usdValue, receiptAmount).Error(0)
+}
+
+func (m *MockStakingPositionService) RecordUnstake(ctx context.Context, positionID uuid.UUID, amount, usdValue, receiptAmount *big.Int) error {
+	return m.Called(ctx, positionID, amount, usdValue, receiptAmount).Error(0)
+}
+
+func (m *MockStakingPositionService) RecordReward(ctx context.Context, positionID uuid.UUID, amount, usdValue *big.Int) error {
+	return m.Called(ctx, positionID, amount, usdValue).Error(0)
+}
+
+func (m *MockStakingPositionService) ListActiveRebasing(ctx context.Context, walletID uuid.UUID) ([]*stakingposition.StakingPosition, error) {
+	args := m.Called(ctx, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*stakingposition.StakingPosition), args.Error(1)
+}
+
+func TestStakingAccrual_BooksRebaseDelta(t *testing.T) {
+	ctx := context.Background()
+	w := newTestWallet(uuid.New(), "0xabc")
+	pos := &stakingposition.StakingPosition{
+		ID:              uuid.New(),
+		WalletID:        w.ID,
+		Protocol:        "Lido",
+		ChainID:         "ethereum",
+		Asset:           "ETH",
+		ReceiptAsset:    "stETH",
+		ReceiptDecimals: 18,
+		Rebasing:        true,
+	}
+
+	stakingSvc := new(MockStakingPositionService)
+	stakingSvc.On("ListActiveRebasing", ctx, w.ID).Return([]*stakingposition.StakingPosition{pos}, nil)
+	// 0.005 stETH at $3,000 is $15, scaled by 10^8
+	stakingSvc.On("RecordReward", ctx, pos.ID, big.NewInt(5e15), big.NewInt(1_500_000_000)).Return(nil)
+
+	posProvider := new(MockPositionDataProvider)
+	posProvider.On("GetPositions", ctx, w.Address).Return([]sync.OnChainPosition{
+		{ChainID: "ethereum", AssetSymbol: "STETH", Decimals: 18, Quantity: big.NewInt(1_005e15), USDPrice: big.NewInt(300000000000)},
+	}, nil)
+
+	ledgerSvc := new(MockLedgerService)
+	ledgerSvc.On("GetBalance", ctx, w.ID, "ethereum", "stETH").Return(big.NewInt(1e18), nil)
+	ledgerSvc.On("RecordTransaction", ctx, ledger.TxTypeStakingReward, "staking_accrual", mock.Anything, mock.Anything, mock.Anything).
+		Return(&ledger.Transaction{ID: uuid.New()}, nil)
+
+	accrual := sync.NewStakingAccrual(posProvider, ledgerSvc, stakingSvc, logger.NewDefault("test"))
+	n, err := accrual.Accrue(ctx, w)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	require.Len(t, ledgerSvc.recordedTransactions, 1)
+	data := ledgerSvc.recordedTransactions[0].RawData
+	assert.Equal(t, "stETH", data["asset"])
+	assert.Equal(t, "5000000000000000", data["amount"])
+	assert.Equal(t, "300000000000", data["usd_price"])
+	stakingSvc.AssertExpectations(t)
+}
+
+func TestStakingAccrual_NoDelta_RecordsNothing(t *testing.T) {
+	ctx := context.Background()
+	w := newTestWallet(uuid.New(), "0xabc")
+	pos := &stakingposition.StakingPosition{
+		ID: uuid.New(), WalletID: w.ID, ChainID: "ethereum", ReceiptAsset: "stETH", Rebasing: true,
+	}
+
+	stakingSvc := new(MockStakingPositionService)
+	stakingSvc.On("ListActiveRebasing", ctx, w.ID).Return([]*stakingposition.StakingPosition{pos}, nil)
+
+	posProvider := new(MockPositionDataProvider)
+	posProvider.On("GetPositions", ctx, w.Address).Return([]sync.OnChainPosition{
+		{ChainID: "ethereum", AssetSymbol: "stETH", Quantity: big.NewInt(1e18)},
+	}, nil)
+
+	ledgerSvc := new(MockLedgerService)
+	ledgerSvc.On("GetBalance", ctx, w.ID, "ethereum", "stETH").Return(big.NewInt(1e18), nil)
+
+	accrual := sync.NewStakingAccrual(posProvider, ledgerSvc, stakingSvc, logger.NewDefault("test"))
+	n, err := accrual.Accrue(ctx, w)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Empty(t, ledgerSvc.recordedTransactions)
+	stakingSvc.AssertNotCalled(t, "RecordReward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -2,6 +2,7 @@ package sync_test
 
 import (
 	"context"
+	"math/big"
 	"time"
 
 	"github.com/google/uuid"
@@ -99,6 +100,14 @@ func (m *MockLedgerService) RecordTransaction(ctx context.Context, transactionTy
 	return args.Get(0).(*ledger.Transaction), args.Error(1)
 }
 
+func (m *MockLedgerService) GetBalance(ctx context.Context, walletID uuid.UUID, chain string, assetID string) (*big.Int, error) {
+	args := m.Called(ctx, walletID, chain, assetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*big.Int), args.Error(1)
+}
+
 // =============================================================================
 // Mock Asset Service
 // =============================================================================
@@ -9,7 +9,9 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/lendingposition"
 	"github.com/kislikjeka/moontrack/internal/platform/lpposition"
+	"github.com/kislikjeka/moontrack/internal/platform/stakingposition"
 	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 	"github.com/kislikjeka/moontrack/pkg/money"
@@ -18,25 +20,29 @@ import (
 // ZerionProcessor handles decoded transaction classification and ledger recording
 // for transactions fetched via the Zerion API.
 type ZerionProcessor struct {
-	walletRepo         WalletRepository
-	ledgerSvc          LedgerService
-	lpPositionSvc      LPPositionService
-	lendingPositionSvc LendingPositionService
-	classifier         *Classifier
-	logger             *logger.Logger
-	addressCache       map[string][]uuid.UUID
+	walletRepo            WalletRepository
+	ledgerSvc             LedgerService
+	lpPositionSvc         LPPositionService
+	lendingPositionSvc    LendingPositionService
+	stakingPositionSvc    StakingPositionService
+	derivativePositionSvc DerivativePositionService
+	classifier            *Classifier
+	logger                *logger.Logger
+	addressCache          map[string][]uuid.UUID
 }
 
 // NewZerionProcessor creates a new ZerionProcessor.
-func NewZerionProcessor(walletRepo WalletRepository, ledgerSvc LedgerService, lpPositionSvc LPPositionService, lendingPositionSvc LendingPositionService, logger *logger.Logger) *ZerionProcessor {
+func NewZerionProcessor(walletRepo WalletRepository, ledgerSvc LedgerService, lpPositionSvc LPPositionService, lendingPositionSvc LendingPositionService, stakingPositionSvc StakingPositionService, derivativePositionSvc DerivativePositionService, logger *logger.Logger) *ZerionProcessor {
 	return &ZerionProcessor{
-		walletRepo:         walletRepo,
-		ledgerSvc:          ledgerSvc,
-		lpPositionSvc:      lpPositionSvc,
-		lendingPositionSvc: lendingPositionSvc,
-		classifier:         NewClassifier(),
-		logger:             logger,
-		addressCache:       make(map[string][]uuid.UUID),
+		walletRepo:            walletRepo,
+		ledgerSvc:             ledgerSvc,
+		lpPositionSvc:         lpPositionSvc,
+		lendingPositionSvc:    lendingPositionSvc,
+		stakingPositionSvc:    stakingPositionSvc,
+		derivativePositionSvc: derivativePositionSvc,
+		classifier:            NewClassifier(),
+		logger:                logger,
+		addressCache:          make(map[string][]uuid.UUID),
 	}
 }
 
@@ -65,6 +71,8 @@ func (p *ZerionProcessor) ProcessTransaction(ctx context.Context, w *wallet.Wall
 	}
 
 	var data map[string]interface{}
+	var liquidation *lendingLiquidation
+	var marginClose *derivativeClose
 	externalID := tx.ID
 
 	switch txType {
@@ -98,6 +106,41 @@ func (p *ZerionProcessor) ProcessTransaction(ctx context.Context, w *wallet.Wall
 		data = p.buildLendingRepayData(w, tx)
 	case ledger.TxTypeLendingClaim:
 		data = p.buildLendingClaimData(w, tx)
+	case ledger.TxTypeLendingLiquidation:
+		liq, err := p.resolveLendingLiquidation(ctx, w, tx)
+		if err != nil {
+			p.logger.Warn("lending liquidation: cannot resolve, skipping", "tx_hash", tx.TxHash, "error", err)
+			return nil
+		}
+		liquidation = liq
+		data = p.buildLendingLiquidationData(w, tx, liq)
+	case ledger.TxTypeStake:
+		data = p.buildStakeData(w, tx)
+	case ledger.TxTypeUnstake:
+		data = p.buildUnstakeData(w, tx)
+	case ledger.TxTypeStakingReward:
+		data = p.buildStakingRewardData(w, tx)
+	case ledger.TxTypeDerivativeOpen:
+		data = p.buildDerivativeData(w, tx, DirectionOut)
+	case ledger.TxTypeDerivativeClose:
+		mc, err := p.resolveDerivativeClose(ctx, w, tx)
+		if err != nil {
+			// Margin posted before tracking began cannot be told apart from
+			// PnL; record the withdrawal as before
+			p.logger.Warn("derivative close: no margin on record, recording as DeFi withdraw", "tx_hash", tx.TxHash, "error", err)
+			txType = ledger.TxTypeDefiWithdraw
+			data = p.buildDeFiWithdrawData(w, tx)
+			break
+		}
+		marginClose = mc
+		data = p.buildDerivativeCloseData(w, tx, mc)
+	case ledger.TxTypeDerivativeFunding:
+		data = p.buildDerivativeData(w, tx, DirectionIn)
+		data["claimed"] = true
+	case ledger.TxTypeNFTBuy, ledger.TxTypeNFTMint, ledger.TxTypeNFTTransferIn:
+		data = p.buildNFTData(w, tx, DirectionIn)
+	case ledger.TxTypeNFTSell, ledger.TxTypeNFTTransferOut:
+		data = p.buildNFTData(w, tx, DirectionOut)
 	default:
 		p.logger.Warn("unhandled transaction type", "type", txType, "tx_hash", tx.TxHash)
 		return nil
@@ -139,6 +182,32 @@ func (p *ZerionProcessor) ProcessTransaction(ctx context.Context, w *wallet.Wall
 			p.handleLendingRepay(ctx, w, tx)
 		case ledger.TxTypeLendingClaim:
 			p.handleLendingClaim(ctx, w, tx)
+		case ledger.TxTypeLendingLiquidation:
+			p.handleLendingLiquidation(ctx, tx, liquidation)
+		}
+	}
+
+	// Post-process staking transactions: update staking position aggregates
+	if p.stakingPositionSvc != nil {
+		switch txType {
+		case ledger.TxTypeStake:
+			p.handleStake(ctx, w, tx)
+		case ledger.TxTypeUnstake:
+			p.handleUnstake(ctx, w, tx)
+		case ledger.TxTypeStakingReward:
+			p.handleStakingReward(ctx, w, tx)
+		}
+	}
+
+	// Post-process derivatives transactions: update derivative position aggregates
+	if p.derivativePositionSvc != nil {
+		switch txType {
+		case ledger.TxTypeDerivativeOpen:
+			p.handleDerivativeOpen(ctx, w, tx)
+		case ledger.TxTypeDerivativeClose:
+			p.handleDerivativeClose(ctx, tx, marginClose)
+		case ledger.TxTypeDerivativeFunding:
+			p.handleDerivativeFunding(ctx, w, tx)
 		}
 	}
 
@@ -235,8 +304,14 @@ func (p *ZerionProcessor) handleLPDeposit(ctx context.Context, w *wallet.Wallet,
 		return
 	}
 
+	// Fungible pools are keyed by the LP token received
+	var contractAddress string
+	if tx.NFTTokenID == "" && isFungibleLP(tx) {
+		contractAddress = lpTokenContract(tx.Transfers, DirectionIn)
+	}
+
 	chainID := tx.ChainID
-	pos, err := p.lpPositionSvc.FindOrCreate(ctx, w.UserID, w.ID, chainID, tx.Protocol, tx.NFTTokenID, "",
+	pos, err := p.lpPositionSvc.FindOrCreate(ctx, w.UserID, w.ID, chainID, tx.Protocol, tx.NFTTokenID, contractAddress,
 		lpposition.TokenInfo{Symbol: token0.AssetSymbol, Contract: token0.ContractAddress, Decimals: token0.Decimals},
 		lpposition.TokenInfo{Symbol: token1.AssetSymbol, Contract: token1.ContractAddress, Decimals: token1.Decimals},
 		tx.MinedAt,
@@ -247,7 +322,7 @@ func (p *ZerionProcessor) handleLPDeposit(ctx context.Context, w *wallet.Wallet,
 	}
 
 	token0Amt, token1Amt, usdValue := p.calcLPAmounts(tx.Transfers, DirectionOut, pos)
-	if err := p.lpPositionSvc.RecordDeposit(ctx, pos.ID, token0Amt, token1Amt, usdValue); err != nil {
+	if err := p.lpPositionSvc.RecordDeposit(ctx, pos.ID, token0Amt, token1Amt, usdValue, tx.MinedAt); err != nil {
 		p.logger.Error("LP deposit: failed to record deposit", "tx_hash", tx.TxHash, "position_id", pos.ID, "error", err)
 	}
 }
@@ -259,20 +334,8 @@ func (p *ZerionProcessor) handleLPWithdraw(ctx context.Context, w *wallet.Wallet
 		return
 	}
 
-	chainID := tx.ChainID
-
-	// Try to find position by NFT token ID first, then by token pair
-	var pos *lpposition.LPPosition
-	var err error
-	if tx.NFTTokenID != "" {
-		pos, err = p.lpPositionSvc.FindOrCreate(ctx, w.UserID, w.ID, chainID, tx.Protocol, tx.NFTTokenID, "",
-			lpposition.TokenInfo{Symbol: token0.AssetSymbol, Contract: token0.ContractAddress, Decimals: token0.Decimals},
-			lpposition.TokenInfo{Symbol: token1.AssetSymbol, Contract: token1.ContractAddress, Decimals: token1.Decimals},
-			tx.MinedAt,
-		)
-	} else {
-		pos, err = p.lpPositionSvc.FindOpenByTokenPair(ctx, w.ID, chainID, tx.Protocol, token0.AssetSymbol, token1.AssetSymbol)
-	}
+	// The LP token of a fungible pool is returned on withdrawal
+	pos, err := p.findLPPosition(ctx, w, tx, token0, token1, DirectionOut)
 	if err != nil {
 		p.logger.Error("LP withdraw: failed to find position", "tx_hash", tx.TxHash, "error", err)
 		return
@@ -283,7 +346,7 @@ func (p *ZerionProcessor) handleLPWithdraw(ctx context.Context, w *wallet.Wallet
 	}
 
 	token0Amt, token1Amt, usdValue := p.calcLPAmounts(tx.Transfers, DirectionIn, pos)
-	if err := p.lpPositionSvc.RecordWithdraw(ctx, pos.ID, token0Amt, token1Amt, usdValue); err != nil {
+	if err := p.lpPositionSvc.RecordWithdraw(ctx, pos.ID, token0Amt, token1Amt, usdValue, tx.MinedAt); err != nil {
 		p.logger.Error("LP withdraw: failed to record withdraw", "tx_hash", tx.TxHash, "position_id", pos.ID, "error", err)
 	}
 }
@@ -295,19 +358,8 @@ func (p *ZerionProcessor) handleLPClaimFees(ctx context.Context, w *wallet.Walle
 		return
 	}
 
-	chainID := tx.ChainID
-
-	var pos *lpposition.LPPosition
-	var err error
-	if tx.NFTTokenID != "" {
-		pos, err = p.lpPositionSvc.FindOrCreate(ctx, w.UserID, w.ID, chainID, tx.Protocol, tx.NFTTokenID, "",
-			lpposition.TokenInfo{Symbol: token0.AssetSymbol, Contract: token0.ContractAddress, Decimals: token0.Decimals},
-			lpposition.TokenInfo{Symbol: token1.AssetSymbol, Contract: token1.ContractAddress, Decimals: token1.Decimals},
-			tx.MinedAt,
-		)
-	} else {
-		pos, err = p.lpPositionSvc.FindOpenByTokenPair(ctx, w.ID, chainID, tx.Protocol, token0.AssetSymbol, token1.AssetSymbol)
-	}
+	// Fee claims move no LP token, so fungible pools resolve by token pair
+	pos, err := p.findLPPosition(ctx, w, tx, token0, token1, DirectionOut)
 	if err != nil {
 		p.logger.Error("LP claim fees: failed to find position", "tx_hash", tx.TxHash, "error", err)
 		return
@@ -318,11 +370,35 @@ func (p *ZerionProcessor) handleLPClaimFees(ctx context.Context, w *wallet.Walle
 	}
 
 	token0Amt, token1Amt, usdValue := p.calcLPAmounts(tx.Transfers, DirectionIn, pos)
-	if err := p.lpPositionSvc.RecordClaimFees(ctx, pos.ID, token0Amt, token1Amt, usdValue); err != nil {
+	if err := p.lpPositionSvc.RecordClaimFees(ctx, pos.ID, token0Amt, token1Amt, usdValue, tx.MinedAt); err != nil {
 		p.logger.Error("LP claim fees: failed to record claim", "tx_hash", tx.TxHash, "position_id", pos.ID, "error", err)
 	}
 }
 
+// findLPPosition resolves the position a withdrawal or claim applies to: by
+// NFT token ID, then by the fungible pool's LP token moving in lpTokenDir,
+// then by the oldest open position holding the token pair.
+func (p *ZerionProcessor) findLPPosition(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction, token0, token1 DecodedTransfer, lpTokenDir TransferDirection) (*lpposition.LPPosition, error) {
+	if tx.NFTTokenID != "" {
+		return p.lpPositionSvc.FindOrCreate(ctx, w.UserID, w.ID, tx.ChainID, tx.Protocol, tx.NFTTokenID, "",
+			lpposition.TokenInfo{Symbol: token0.AssetSymbol, Contract: token0.ContractAddress, Decimals: token0.Decimals},
+			lpposition.TokenInfo{Symbol: token1.AssetSymbol, Contract: token1.ContractAddress, Decimals: token1.Decimals},
+			tx.MinedAt,
+		)
+	}
+
+	if isFungibleLP(tx) {
+		if contract := lpTokenContract(tx.Transfers, lpTokenDir); contract != "" {
+			pos, err := p.lpPositionSvc.FindOpenByContract(ctx, w.ID, tx.ChainID, contract)
+			if err != nil || pos != nil {
+				return pos, err
+			}
+		}
+	}
+
+	return p.lpPositionSvc.FindOpenByTokenPair(ctx, w.ID, tx.ChainID, tx.Protocol, token0.ContractAddress, token1.ContractAddress)
+}
+
 // extractTokenPair extracts token0 and token1 from transfers matching the given direction.
 // Returns up to two unique tokens. If only one token, token1 is returned with empty symbol.
 func (p *ZerionProcessor) extractTokenPair(transfers []DecodedTransfer, dir TransferDirection) (DecodedTransfer, DecodedTransfer) {
@@ -597,7 +673,7 @@ func (p *ZerionProcessor) buildSingleTransfer(t DecodedTransfer) map[string]inte
 func (p *ZerionProcessor) buildLendingSupplyData(w *wallet.Wallet, tx DecodedTransaction) map[string]interface{} {
 	data := p.buildBaseData(w, tx)
 	// Supply: the outgoing transfer is the asset being supplied
-	if t := p.findTransfer(tx.Transfers, DirectionOut); t != nil {
+	if t := p.findLendingTransfer(tx, DirectionOut); t != nil {
 		p.setLendingAssetFields(data, t)
 	}
 	return data
@@ -606,7 +682,7 @@ func (p *ZerionProcessor) buildLendingSupplyData(w *wallet.Wallet, tx DecodedTra
 func (p *ZerionProcessor) buildLendingWithdrawData(w *wallet.Wallet, tx DecodedTransaction) map[string]interface{} {
 	data := p.buildBaseData(w, tx)
 	// Withdraw: the incoming transfer is the asset being withdrawn
-	if t := p.findTransfer(tx.Transfers, DirectionIn); t != nil {
+	if t := p.findLendingTransfer(tx, DirectionIn); t != nil {
 		p.setLendingAssetFields(data, t)
 	}
 	return data
@@ -615,7 +691,7 @@ func (p *ZerionProcessor) buildLendingWithdrawData(w *wallet.Wallet, tx DecodedT
 func (p *ZerionProcessor) buildLendingBorrowData(w *wallet.Wallet, tx DecodedTransaction) map[string]interface{} {
 	data := p.buildBaseData(w, tx)
 	// Borrow: the incoming transfer is the borrowed asset
-	if t := p.findTransfer(tx.Transfers, DirectionIn); t != nil {
+	if t := p.findLendingTransfer(tx, DirectionIn); t != nil {
 		p.setLendingAssetFields(data, t)
 	}
 	return data
@@ -624,7 +700,7 @@ func (p *ZerionProcessor) buildLendingBorrowData(w *wallet.Wallet, tx DecodedTra
 func (p *ZerionProcessor) buildLendingRepayData(w *wallet.Wallet, tx DecodedTransaction) map[string]interface{} {
 	data := p.buildBaseData(w, tx)
 	// Repay: the outgoing transfer is the asset being repaid
-	if t := p.findTransfer(tx.Transfers, DirectionOut); t != nil {
+	if t := p.findLendingTransfer(tx, DirectionOut); t != nil {
 		p.setLendingAssetFields(data, t)
 	}
 	return data
@@ -633,7 +709,7 @@ func (p *ZerionProcessor) buildLendingRepayData(w *wallet.Wallet, tx DecodedTran
 func (p *ZerionProcessor) buildLendingClaimData(w *wallet.Wallet, tx DecodedTransaction) map[string]interface{} {
 	data := p.buildBaseData(w, tx)
 	// Claim: the incoming transfer is the reward/interest
-	if t := p.findTransfer(tx.Transfers, DirectionIn); t != nil {
+	if t := p.findLendingTransfer(tx, DirectionIn); t != nil {
 		p.setLendingAssetFields(data, t)
 	}
 	return data
@@ -651,6 +727,20 @@ func (p *ZerionProcessor) findTransfer(transfers []DecodedTransfer, dir Transfer
 	return nil
 }
 
+// findLendingTransfer returns the first transfer in dir of an underlying
+// asset, looking past the protocol's receipt and debt tokens
+func (p *ZerionProcessor) findLendingTransfer(tx DecodedTransaction, dir TransferDirection) *DecodedTransfer {
+	if proto, ok := lendingProtocolFor(tx); ok {
+		for i := range tx.Transfers {
+			t := &tx.Transfers[i]
+			if t.Direction == dir && !proto.isReceiptToken(t.AssetSymbol) {
+				return t
+			}
+		}
+	}
+	return p.findTransfer(tx.Transfers, dir)
+}
+
 func (p *ZerionProcessor) setLendingAssetFields(data map[string]interface{}, t *DecodedTransfer) {
 	data["asset"] = t.AssetSymbol
 	data["amount"] = money.NewBigInt(t.Amount).String()
@@ -663,145 +753,252 @@ func (p *ZerionProcessor) setLendingAssetFields(data map[string]interface{}, t *
 
 // --- Lending position post-processing ---
 
+// lendingRecorder applies one asset movement to a lending position
+type lendingRecorder func(ctx context.Context, positionID uuid.UUID, asset lendingposition.AssetInfo, amount, usdValue *big.Int) error
+
 func (p *ZerionProcessor) handleLendingSupply(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) {
-	t := p.findTransfer(tx.Transfers, DirectionOut)
+	// Supply: the outgoing transfer is the collateral
+	p.handleLendingMove(ctx, w, tx, "supply", DirectionOut, p.lendingPositionSvc.RecordSupply)
+}
+
+func (p *ZerionProcessor) handleLendingWithdraw(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) {
+	// Withdraw: the incoming transfer is the collateral released
+	p.handleLendingMove(ctx, w, tx, "withdraw", DirectionIn, p.lendingPositionSvc.RecordWithdraw)
+}
+
+func (p *ZerionProcessor) handleLendingBorrow(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) {
+	// Borrow: the incoming transfer is the borrowed asset
+	p.handleLendingMove(ctx, w, tx, "borrow", DirectionIn, p.lendingPositionSvc.RecordBorrow)
+}
+
+func (p *ZerionProcessor) handleLendingRepay(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) {
+	// Repay: the outgoing transfer is the debt repaid
+	p.handleLendingMove(ctx, w, tx, "repay", DirectionOut, p.lendingPositionSvc.RecordRepay)
+}
+
+// handleLendingMove records the transfer in dir on the wallet's lending
+// account for the protocol and chain. Each asset is its own leg, so an
+// account can hold several collaterals and debts.
+func (p *ZerionProcessor) handleLendingMove(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction, op string, dir TransferDirection, record lendingRecorder) {
+	t := p.findLendingTransfer(tx, dir)
 	if t == nil {
-		p.logger.Warn("lending supply: no outgoing transfer", "tx_hash", tx.TxHash)
+		p.logger.Warn("lending "+op+": no transfer", "tx_hash", tx.TxHash, "direction", dir)
 		return
 	}
 
-	pos, err := p.lendingPositionSvc.FindOrCreate(ctx, w.UserID, w.ID,
-		tx.Protocol, tx.ChainID, t.AssetSymbol,
-		t.Decimals, t.ContractAddress, tx.MinedAt,
-	)
+	pos, err := p.lendingPositionSvc.FindOrCreate(ctx, w.UserID, w.ID, tx.Protocol, tx.ChainID, tx.MinedAt)
 	if err != nil {
-		p.logger.Error("lending supply: failed to find or create position", "tx_hash", tx.TxHash, "error", err)
+		p.logger.Error("lending "+op+": failed to find or create position", "tx_hash", tx.TxHash, "error", err)
 		return
 	}
 
-	usdValue := p.calcLendingUSD(t)
-	if err := p.lendingPositionSvc.RecordSupply(ctx, pos.ID, t.Amount, usdValue); err != nil {
-		p.logger.Error("lending supply: failed to record", "tx_hash", tx.TxHash, "position_id", pos.ID, "error", err)
+	asset := lendingposition.AssetInfo{Symbol: t.AssetSymbol, Decimals: t.Decimals, Contract: t.ContractAddress}
+	if err := record(ctx, pos.ID, asset, t.Amount, p.calcLendingUSD(t)); err != nil {
+		p.logger.Error("lending "+op+": failed to record", "tx_hash", tx.TxHash, "position_id", pos.ID, "error", err)
 	}
 }
 
-func (p *ZerionProcessor) handleLendingWithdraw(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) {
-	t := p.findTransfer(tx.Transfers, DirectionIn)
+func (p *ZerionProcessor) handleLendingClaim(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) {
+	t := p.findLendingTransfer(tx, DirectionIn)
 	if t == nil {
-		p.logger.Warn("lending withdraw: no incoming transfer", "tx_hash", tx.TxHash)
+		p.logger.Warn("lending claim: no incoming transfer", "tx_hash", tx.TxHash)
 		return
 	}
 
-	pos, err := p.lendingPositionSvc.FindOrCreate(ctx, w.UserID, w.ID,
-		tx.Protocol, tx.ChainID, t.AssetSymbol,
-		t.Decimals, t.ContractAddress, tx.MinedAt,
-	)
+	pos, err := p.lendingPositionSvc.FindOrCreate(ctx, w.UserID, w.ID, tx.Protocol, tx.ChainID, tx.MinedAt)
 	if err != nil {
-		p.logger.Error("lending withdraw: failed to find position", "tx_hash", tx.TxHash, "error", err)
+		p.logger.Error("lending claim: failed to find position", "tx_hash", tx.TxHash, "error", err)
 		return
 	}
 
 	usdValue := p.calcLendingUSD(t)
-	if err := p.lendingPositionSvc.RecordWithdraw(ctx, pos.ID, t.Amount, usdValue); err != nil {
-		p.logger.Error("lending withdraw: failed to record", "tx_hash", tx.TxHash, "position_id", pos.ID, "error", err)
+	if err := p.lendingPositionSvc.RecordClaim(ctx, pos.ID, usdValue); err != nil {
+		p.logger.Error("lending claim: failed to record", "tx_hash", tx.TxHash, "position_id", pos.ID, "error", err)
 	}
 }
 
-func (p *ZerionProcessor) handleLendingBorrow(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) {
-	t := p.findTransfer(tx.Transfers, DirectionIn)
-	if t == nil {
-		p.logger.Warn("lending borrow: no incoming transfer", "tx_hash", tx.TxHash)
-		return
+// calcLendingUSD values a transfer in USD scaled by 1e8. Amounts are in the
+// asset's base units, so legs of different decimals aggregate correctly.
+func (p *ZerionProcessor) calcLendingUSD(t *DecodedTransfer) *big.Int {
+	if t.USDPrice != nil && t.Amount != nil {
+		return money.CalcUSDValue(t.Amount, t.USDPrice, t.Decimals)
 	}
+	return big.NewInt(0)
+}
 
-	// Find the supply position — borrow needs an existing supply position
-	// Look for any active position for this wallet+protocol+chain
-	supplyTransfer := p.findTransfer(tx.Transfers, DirectionOut)
-	supplyAsset := ""
-	if supplyTransfer != nil {
-		supplyAsset = supplyTransfer.AssetSymbol
+// --- Staking data builders ---
+
+func (p *ZerionProcessor) buildStakeData(w *wallet.Wallet, tx DecodedTransaction) map[string]interface{} {
+	data := p.buildBaseData(w, tx)
+	// Stake: the outgoing asset is staked; liquid staking mints an incoming receipt token
+	if t := p.findStakingTransfer(tx.Transfers, DirectionOut, false); t != nil {
+		p.setLendingAssetFields(data, t)
 	}
+	if r := p.findStakingTransfer(tx.Transfers, DirectionIn, true); r != nil {
+		p.setReceiptFields(data, r)
+	}
+	return data
+}
 
-	// If no supply asset in this tx, try to find existing active position
-	if supplyAsset == "" {
-		// Borrow without supply in same tx — find existing position by protocol+chain
-		pos, err := p.lendingPositionSvc.FindOrCreate(ctx, w.UserID, w.ID,
-			tx.Protocol, tx.ChainID, t.AssetSymbol,
-			t.Decimals, t.ContractAddress, tx.MinedAt,
-		)
-		if err != nil {
-			p.logger.Error("lending borrow: failed to find position", "tx_hash", tx.TxHash, "error", err)
-			return
-		}
-		usdValue := p.calcLendingUSD(t)
-		if err := p.lendingPositionSvc.RecordBorrow(ctx, pos.ID, t.AssetSymbol, t.Decimals, t.ContractAddress, t.Amount, usdValue); err != nil {
-			p.logger.Error("lending borrow: failed to record", "tx_hash", tx.TxHash, "position_id", pos.ID, "error", err)
+func (p *ZerionProcessor) buildUnstakeData(w *wallet.Wallet, tx DecodedTransaction) map[string]interface{} {
+	data := p.buildBaseData(w, tx)
+	// Unstake: the incoming asset is returned; liquid staking burns an outgoing receipt token
+	if t := p.findStakingTransfer(tx.Transfers, DirectionIn, false); t != nil {
+		p.setLendingAssetFields(data, t)
+	}
+	if r := p.findStakingTransfer(tx.Transfers, DirectionOut, true); r != nil {
+		p.setReceiptFields(data, r)
+	}
+	return data
+}
+
+func (p *ZerionProcessor) buildStakingRewardData(w *wallet.Wallet, tx DecodedTransaction) map[string]interface{} {
+	data := p.buildBaseData(w, tx)
+	if t := p.findTransfer(tx.Transfers, DirectionIn); t != nil {
+		p.setLendingAssetFields(data, t)
+	}
+	return data
+}
+
+// findStakingTransfer returns the first transfer in the given direction that
+// is (or is not) a liquid-staking receipt token. Unlike findTransfer it never
+// falls back to a transfer of the other kind.
+func (p *ZerionProcessor) findStakingTransfer(transfers []DecodedTransfer, dir TransferDirection, receipt bool) *DecodedTransfer {
+	for i := range transfers {
+		if transfers[i].Direction == dir && isLiquidStakingToken(transfers[i].AssetSymbol) == receipt {
+			return &transfers[i]
 		}
+	}
+	return nil
+}
+
+func (p *ZerionProcessor) setReceiptFields(data map[string]interface{}, t *DecodedTransfer) {
+	data["receipt_asset"] = t.AssetSymbol
+	data["receipt_amount"] = money.NewBigInt(t.Amount).String()
+	data["receipt_decimals"] = t.Decimals
+	data["receipt_contract"] = t.ContractAddress
+	if t.USDPrice != nil {
+		data["receipt_usd_price"] = t.USDPrice.String()
+	}
+}
+
+// --- Staking position post-processing ---
+
+func (p *ZerionProcessor) handleStake(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) {
+	t := p.findStakingTransfer(tx.Transfers, DirectionOut, false)
+	if t == nil {
+		p.logger.Warn("stake: no outgoing transfer", "tx_hash", tx.TxHash)
 		return
 	}
+	r := p.findStakingTransfer(tx.Transfers, DirectionIn, true)
 
-	pos, err := p.lendingPositionSvc.FindOrCreate(ctx, w.UserID, w.ID,
-		tx.Protocol, tx.ChainID, supplyAsset,
-		supplyTransfer.Decimals, supplyTransfer.ContractAddress, tx.MinedAt,
-	)
+	pos, err := p.findOrCreateStakingPosition(ctx, w, tx, t, r)
 	if err != nil {
-		p.logger.Error("lending borrow: failed to find position", "tx_hash", tx.TxHash, "error", err)
+		p.logger.Error("stake: failed to find or create position", "tx_hash", tx.TxHash, "error", err)
 		return
 	}
 
-	usdValue := p.calcLendingUSD(t)
-	if err := p.lendingPositionSvc.RecordBorrow(ctx, pos.ID, t.AssetSymbol, t.Decimals, t.ContractAddress, t.Amount, usdValue); err != nil {
-		p.logger.Error("lending borrow: failed to record", "tx_hash", tx.TxHash, "position_id", pos.ID, "error", err)
+	if err := p.stakingPositionSvc.RecordStake(ctx, pos.ID, t.Amount, p.calcLendingUSD(t), receiptAmount(r)); err != nil {
+		p.logger.Error("stake: failed to record", "tx_hash", tx.TxHash, "position_id", pos.ID, "error", err)
 	}
 }
 
-func (p *ZerionProcessor) handleLendingRepay(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) {
-	t := p.findTransfer(tx.Transfers, DirectionOut)
+func (p *ZerionProcessor) handleUnstake(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) {
+	t := p.findStakingTransfer(tx.Transfers, DirectionIn, false)
 	if t == nil {
-		p.logger.Warn("lending repay: no outgoing transfer", "tx_hash", tx.TxHash)
+		p.logger.Warn("unstake: no incoming transfer", "tx_hash", tx.TxHash)
 		return
 	}
+	r := p.findStakingTransfer(tx.Transfers, DirectionOut, true)
 
-	pos, err := p.lendingPositionSvc.FindOrCreate(ctx, w.UserID, w.ID,
-		tx.Protocol, tx.ChainID, t.AssetSymbol,
-		t.Decimals, t.ContractAddress, tx.MinedAt,
-	)
+	pos, err := p.findOrCreateStakingPosition(ctx, w, tx, t, r)
 	if err != nil {
-		p.logger.Error("lending repay: failed to find position", "tx_hash", tx.TxHash, "error", err)
+		p.logger.Error("unstake: failed to find position", "tx_hash", tx.TxHash, "error", err)
 		return
 	}
 
-	usdValue := p.calcLendingUSD(t)
-	if err := p.lendingPositionSvc.RecordRepay(ctx, pos.ID, t.Amount, usdValue); err != nil {
-		p.logger.Error("lending repay: failed to record", "tx_hash", tx.TxHash, "position_id", pos.ID, "error", err)
+	if err := p.stakingPositionSvc.RecordUnstake(ctx, pos.ID, t.Amount, p.calcLendingUSD(t), receiptAmount(r)); err != nil {
+		p.logger.Error("unstake: failed to record", "tx_hash", tx.TxHash, "position_id", pos.ID, "error", err)
 	}
 }
 
-func (p *ZerionProcessor) handleLendingClaim(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) {
+func (p *ZerionProcessor) handleStakingReward(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction) {
 	t := p.findTransfer(tx.Transfers, DirectionIn)
 	if t == nil {
-		p.logger.Warn("lending claim: no incoming transfer", "tx_hash", tx.TxHash)
+		p.logger.Warn("staking reward: no incoming transfer", "tx_hash", tx.TxHash)
+		return
+	}
+	// Positions are keyed by the staked asset; receipt-token rewards have no position to credit
+	if isLiquidStakingToken(t.AssetSymbol) {
 		return
 	}
 
-	pos, err := p.lendingPositionSvc.FindOrCreate(ctx, w.UserID, w.ID,
-		tx.Protocol, tx.ChainID, t.AssetSymbol,
-		t.Decimals, t.ContractAddress, tx.MinedAt,
-	)
+	pos, err := p.findOrCreateStakingPosition(ctx, w, tx, t, nil)
 	if err != nil {
-		p.logger.Error("lending claim: failed to find position", "tx_hash", tx.TxHash, "error", err)
+		p.logger.Error("staking reward: failed to find position", "tx_hash", tx.TxHash, "error", err)
 		return
 	}
 
-	usdValue := p.calcLendingUSD(t)
-	if err := p.lendingPositionSvc.RecordClaim(ctx, pos.ID, usdValue); err != nil {
-		p.logger.Error("lending claim: failed to record", "tx_hash", tx.TxHash, "position_id", pos.ID, "error", err)
+	if err := p.stakingPositionSvc.RecordReward(ctx, pos.ID, t.Amount, p.calcLendingUSD(t)); err != nil {
+		p.logger.Error("staking reward: failed to record", "tx_hash", tx.TxHash, "position_id", pos.ID, "error", err)
 	}
 }
 
-func (p *ZerionProcessor) calcLendingUSD(t *DecodedTransfer) *big.Int {
-	if t.USDPrice != nil && t.Amount != nil {
-		return new(big.Int).Mul(t.Amount, t.USDPrice)
+func (p *ZerionProcessor) findOrCreateStakingPosition(ctx context.Context, w *wallet.Wallet, tx DecodedTransaction, asset, receipt *DecodedTransfer) (*stakingposition.StakingPosition, error) {
+	var receiptAsset, receiptContract string
+	var receiptDecimals int
+	if receipt != nil {
+		receiptAsset, receiptDecimals, receiptContract = receipt.AssetSymbol, receipt.Decimals, receipt.ContractAddress
 	}
-	return big.NewInt(0)
+	return p.stakingPositionSvc.FindOrCreate(ctx, w.UserID, w.ID,
+		tx.Protocol, tx.ChainID, asset.AssetSymbol, asset.Decimals,
+		receiptAsset, receiptDecimals, receiptContract, tx.MinedAt,
+	)
+}
+
+func receiptAmount(r *DecodedTransfer) *big.Int {
+	if r == nil {
+		return big.NewInt(0)
+	}
+	return r.Amount
+}
+
+// --- NFT data builders ---
+
+// buildNFTData collects the NFTs moving in nftDir and the fungible transfers
+// moving the other way, which pay for them (buy, mint) or are the proceeds
+// of a sale.
+func (p *ZerionProcessor) buildNFTData(w *wallet.Wallet, tx DecodedTransaction, nftDir TransferDirection) map[string]interface{} {
+	data := p.buildBaseData(w, tx)
+
+	nfts := make([]map[string]interface{}, 0, len(tx.NFTTransfers))
+	for _, t := range tx.NFTTransfers {
+		if t.Direction != nftDir {
+			continue
+		}
+		item := map[string]interface{}{
+			"contract_address": t.ContractAddress,
+			"token_id":         t.TokenID,
+			"name":             t.Name,
+			"standard":         t.Standard,
+			"quantity":         money.NewBigInt(t.Quantity).String(),
+		}
+		if t.USDPrice != nil {
+			item["usd_price"] = t.USDPrice.String()
+		}
+		nfts = append(nfts, item)
+	}
+	data["nfts"] = nfts
+
+	var payments []map[string]interface{}
+	for _, t := range tx.Transfers {
+		if t.Direction == nftDir {
+			continue
+		}
+		payments = append(payments, p.buildSingleTransfer(t))
+	}
+	if len(payments) > 0 {
+		data["payments"] = payments
+	}
+	return data
 }
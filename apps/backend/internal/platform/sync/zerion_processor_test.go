@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/lendingposition"
 	"github.com/kislikjeka/moontrack/internal/platform/sync"
 	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/pkg/logger"
@@ -25,7 +26,7 @@ import (
 
 func newZerionProcessor(walletRepo sync.WalletRepository, ledgerSvc sync.LedgerService) *sync.ZerionProcessor {
 	log := logger.New("test", os.Stdout)
-	return sync.NewZerionProcessor(walletRepo, ledgerSvc, nil, nil, log)
+	return sync.NewZerionProcessor(walletRepo, ledgerSvc, nil, nil, nil, nil, log)
 }
 
 func newDecodedTransaction(opType sync.OperationType, transfers []sync.DecodedTransfer) sync.DecodedTransaction {
@@ -480,6 +481,139 @@ func TestZerionProcessor_DeFiWithdraw(t *testing.T) {
 	assert.Equal(t, "ETH", rawData["asset"])
 }
 
+func TestZerionProcessor_LendingBorrow_SkipsDebtToken(t *testing.T) {
+	ctx := context.Background()
+	walletAddr := "0x1111111111111111111111111111111111111111"
+
+	walletRepo := new(MockWalletRepository)
+	ledgerSvc := new(MockLedgerService)
+	ledgerSvc.On("RecordTransaction", ctx, ledger.TxTypeLendingBorrow, "zerion", mock.Anything, mock.Anything, mock.Anything).
+		Return(&ledger.Transaction{ID: uuid.New()}, nil)
+
+	processor := newZerionProcessor(walletRepo, ledgerSvc)
+	w := newTestWallet(uuid.New(), walletAddr)
+
+	// Spark mints the debt token alongside the borrowed DAI
+	tx := newDecodedTransaction(sync.OpReceive, []sync.DecodedTransfer{
+		{AssetSymbol: "variableDebtDAI", Decimals: 18, Amount: big.NewInt(8e18), Direction: sync.DirectionIn, Recipient: walletAddr},
+		{AssetSymbol: "DAI", ContractAddress: "0xdai", Decimals: 18, Amount: big.NewInt(8e18), Direction: sync.DirectionIn, Recipient: walletAddr, USDPrice: big.NewInt(100_000_000)},
+	})
+	tx.Protocol = "Spark"
+
+	err := processor.ProcessTransaction(ctx, w, tx)
+	require.NoError(t, err)
+
+	require.Len(t, ledgerSvc.recordedTransactions, 1)
+	rawData := ledgerSvc.recordedTransactions[0].RawData
+	assert.Equal(t, "DAI", rawData["asset"])
+	assert.Equal(t, "0xdai", rawData["contract_address"])
+}
+
+func TestZerionProcessor_LendingLiquidation_Aave(t *testing.T) {
+	ctx := context.Background()
+	walletAddr := "0x1111111111111111111111111111111111111111"
+	w := newTestWallet(uuid.New(), walletAddr)
+
+	pos := &lendingposition.LendingPosition{ID: uuid.New(), WalletID: w.ID, ChainID: "ethereum", Protocol: "Aave V3", Status: lendingposition.StatusActive}
+	eth := newLendingLeg(pos.ID, lendingposition.SideSupply, "ETH", 18, "", big.NewInt(1e18))
+	weth := newLendingLeg(pos.ID, lendingposition.SideSupply, "WETH", 18, "0xweth", big.NewInt(2e18))
+	usdc := newLendingLeg(pos.ID, lendingposition.SideBorrow, "USDC", 6, "0xusdc", big.NewInt(3_000_000_000))
+	pos.Legs = []*lendingposition.Leg{eth, weth, usdc}
+
+	walletRepo := new(MockWalletRepository)
+	ledgerSvc := new(MockLedgerService)
+	ledgerSvc.On("RecordTransaction", ctx, ledger.TxTypeLendingLiquidation, "zerion", mock.Anything, mock.Anything, mock.Anything).
+		Return(&ledger.Transaction{ID: uuid.New()}, nil)
+
+	lendingSvc := new(MockLendingPositionService)
+	lendingSvc.On("ListByUser", ctx, w.UserID, mock.Anything, &w.ID, mock.Anything).Return([]*lendingposition.LendingPosition{pos}, nil)
+	// 0.5 WETH ($1000) seized for 950 USDC of debt
+	lendingSvc.On("RecordLiquidation", ctx, pos.ID,
+		lendingposition.AssetInfo{Symbol: "WETH", Decimals: 18, Contract: "0xweth"}, big.NewInt(5e17), big.NewInt(100_000_000_000),
+		lendingposition.AssetInfo{Symbol: "USDC", Decimals: 6, Contract: "0xusdc"}, big.NewInt(950_000_000), big.NewInt(95_000_000_000),
+		mock.Anything).Return(nil)
+
+	processor := sync.NewZerionProcessor(walletRepo, ledgerSvc, nil, lendingSvc, nil, nil, logger.New("test", os.Stdout))
+
+	// Sent by the liquidator: no fee, only the aToken and debt token burn
+	tx := newDecodedTransaction(sync.OpSend, []sync.DecodedTransfer{
+		{AssetSymbol: "aEthWETH", Decimals: 18, Amount: big.NewInt(5e17), Direction: sync.DirectionOut, USDPrice: big.NewInt(200_000_000_000)},
+		{AssetSymbol: "variableDebtEthUSDC", Decimals: 6, Amount: big.NewInt(950_000_000), Direction: sync.DirectionOut, USDPrice: big.NewInt(100_000_000)},
+	})
+	tx.Protocol = "Aave V3"
+
+	require.NoError(t, processor.ProcessTransaction(ctx, w, tx))
+
+	require.Len(t, ledgerSvc.recordedTransactions, 1)
+	rawData := ledgerSvc.recordedTransactions[0].RawData
+	assert.Equal(t, "WETH", rawData["asset"])
+	assert.Equal(t, "500000000000000000", rawData["amount"])
+	assert.Equal(t, "USDC", rawData["debt_asset"])
+	assert.Equal(t, "950000000", rawData["debt_amount"])
+	assert.NotContains(t, rawData, "fee_amount")
+	lendingSvc.AssertExpectations(t)
+}
+
+func TestZerionProcessor_LendingLiquidation_CompoundV2(t *testing.T) {
+	ctx := context.Background()
+	w := newTestWallet(uuid.New(), "0x1111111111111111111111111111111111111111")
+
+	pos := &lendingposition.LendingPosition{ID: uuid.New(), WalletID: w.ID, ChainID: "ethereum", Protocol: "Compound", Status: lendingposition.StatusActive}
+	eth := newLendingLeg(pos.ID, lendingposition.SideSupply, "ETH", 18, "", big.NewInt(2e18))
+	eth.USDPrice = big.NewInt(200_000_000_000)
+	usdc := newLendingLeg(pos.ID, lendingposition.SideBorrow, "USDC", 6, "0xusdc", big.NewInt(3_000_000_000))
+	usdc.USDPrice = big.NewInt(100_000_000)
+	pos.Legs = []*lendingposition.Leg{eth, usdc}
+
+	walletRepo := new(MockWalletRepository)
+	ledgerSvc := new(MockLedgerService)
+	ledgerSvc.On("RecordTransaction", ctx, ledger.TxTypeLendingLiquidation, "zerion", mock.Anything, mock.Anything, mock.Anything).
+		Return(&ledger.Transaction{ID: uuid.New()}, nil)
+
+	lendingSvc := new(MockLendingPositionService)
+	lendingSvc.On("ListByUser", ctx, w.UserID, mock.Anything, &w.ID, mock.Anything).Return([]*lendingposition.LendingPosition{pos}, nil)
+	// 50 cETH at $40 is 1 ETH; Compound has no debt token, so the only
+	// debt is repaid at the value seized
+	lendingSvc.On("RecordLiquidation", ctx, pos.ID,
+		lendingposition.AssetInfo{Symbol: "ETH", Decimals: 18}, big.NewInt(1e18), big.NewInt(200_000_000_000),
+		lendingposition.AssetInfo{Symbol: "USDC", Decimals: 6, Contract: "0xusdc"}, big.NewInt(2_000_000_000), big.NewInt(200_000_000_000),
+		mock.Anything).Return(nil)
+
+	processor := sync.NewZerionProcessor(walletRepo, ledgerSvc, nil, lendingSvc, nil, nil, logger.New("test", os.Stdout))
+
+	tx := newDecodedTransaction(sync.OpSend, []sync.DecodedTransfer{
+		{AssetSymbol: "cETH", Decimals: 8, Amount: big.NewInt(5_000_000_000), Direction: sync.DirectionOut, USDPrice: big.NewInt(4_000_000_000)},
+	})
+	tx.Protocol = "Compound"
+
+	require.NoError(t, processor.ProcessTransaction(ctx, w, tx))
+
+	require.Len(t, ledgerSvc.recordedTransactions, 1)
+	assert.Equal(t, "ETH", ledgerSvc.recordedTransactions[0].RawData["asset"])
+	lendingSvc.AssertExpectations(t)
+}
+
+func TestZerionProcessor_LendingLiquidation_NoPositionSkipped(t *testing.T) {
+	ctx := context.Background()
+	w := newTestWallet(uuid.New(), "0x1111111111111111111111111111111111111111")
+
+	walletRepo := new(MockWalletRepository)
+	ledgerSvc := new(MockLedgerService)
+	lendingSvc := new(MockLendingPositionService)
+	lendingSvc.On("ListByUser", ctx, w.UserID, mock.Anything, &w.ID, mock.Anything).Return([]*lendingposition.LendingPosition{}, nil)
+
+	processor := sync.NewZerionProcessor(walletRepo, ledgerSvc, nil, lendingSvc, nil, nil, logger.New("test", os.Stdout))
+
+	tx := newDecodedTransaction(sync.OpSend, []sync.DecodedTransfer{
+		{AssetSymbol: "aEthWETH", Decimals: 18, Amount: big.NewInt(5e17), Direction: sync.DirectionOut},
+		{AssetSymbol: "variableDebtEthUSDC", Decimals: 6, Amount: big.NewInt(950_000_000), Direction: sync.DirectionOut},
+	})
+	tx.Protocol = "Aave V3"
+
+	require.NoError(t, processor.ProcessTransaction(ctx, w, tx))
+	assert.Empty(t, ledgerSvc.recordedTransactions)
+}
+
 func TestZerionProcessor_DeFiClaim(t *testing.T) {
 	ctx := context.Background()
 	userID := uuid.New()
@@ -519,3 +653,47 @@ func TestZerionProcessor_DeFiClaim(t *testing.T) {
 	assert.Equal(t, "Aave V3", rawData["protocol"])
 	assert.Equal(t, "AAVE", rawData["asset"])
 }
+
+func TestZerionProcessor_NFTBuy(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	walletAddr := "0x1111111111111111111111111111111111111111"
+
+	walletRepo := new(MockWalletRepository)
+	ledgerSvc := new(MockLedgerService)
+
+	ledgerSvc.On("RecordTransaction", ctx, ledger.TxTypeNFTBuy, "zerion", mock.Anything, mock.Anything, mock.Anything).
+		Return(&ledger.Transaction{ID: uuid.New()}, nil)
+
+	processor := newZerionProcessor(walletRepo, ledgerSvc)
+	w := newTestWallet(userID, walletAddr)
+
+	tx := newDecodedTransaction(sync.OpTrade, []sync.DecodedTransfer{newOutgoingTransfer("0xseaport")})
+	tx.Protocol = "OpenSea"
+	tx.NFTTransfers = []sync.DecodedNFTTransfer{{
+		ContractAddress: "0xbayc",
+		TokenID:         "7",
+		Name:            "Bored Ape #7",
+		Standard:        "erc721",
+		Quantity:        big.NewInt(1),
+		Direction:       sync.DirectionIn,
+		Sender:          "0xseller",
+		Recipient:       walletAddr,
+	}}
+
+	err := processor.ProcessTransaction(ctx, w, tx)
+	require.NoError(t, err)
+
+	require.Len(t, ledgerSvc.recordedTransactions, 1)
+	rawData := ledgerSvc.recordedTransactions[0].RawData
+
+	nfts := rawData["nfts"].([]map[string]interface{})
+	require.Len(t, nfts, 1)
+	assert.Equal(t, "0xbayc", nfts[0]["contract_address"])
+	assert.Equal(t, "7", nfts[0]["token_id"])
+	assert.Equal(t, "1", nfts[0]["quantity"])
+
+	payments := rawData["payments"].([]map[string]interface{})
+	require.Len(t, payments, 1)
+	assert.Equal(t, "ETH", payments[0]["asset_symbol"])
+}
@@ -0,0 +1,65 @@
+package taxlot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/kislikjeka/moontrack/internal/ledger"
+)
+
+// LotHistory is a tax lot together with every disposal made from it.
+type LotHistory struct {
+	Lot       *ledger.TaxLot
+	WalletID  uuid.UUID
+	Disposals []*ledger.LotDisposal
+}
+
+// GetLotHistory returns all lots, open and closed, with their disposals across
+// every wallet the user can view. Lots carry their ChainID.
+func (s *Service) GetLotHistory(ctx context.Context, userID uuid.UUID) ([]*LotHistory, error) {
+	walletMap, _, err := s.getAccountsForUser(ctx, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*LotHistory
+	for walletID := range walletMap {
+		accounts, err := s.ledgerRepo.FindAccountsByWallet(ctx, walletID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find accounts for wallet %s: %w", walletID, err)
+		}
+		for _, acc := range accounts {
+			if acc.Type != ledger.AccountTypeCryptoWallet {
+				continue
+			}
+			lots, err := s.taxLotRepo.GetLotsByAccount(ctx, acc.ID, acc.AssetID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get lots for account %s: %w", acc.ID, err)
+			}
+			for _, lot := range lots {
+				if acc.ChainID != nil {
+					lot.ChainID = *acc.ChainID
+				}
+				disposals, err := s.taxLotRepo.GetDisposalsByLot(ctx, lot.ID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get disposals for lot %s: %w", lot.ID, err)
+				}
+				result = append(result, &LotHistory{Lot: lot, WalletID: walletID, Disposals: disposals})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// IsPurchase reports whether the lot is a new acquisition rather than units
+// moved between the user's own accounts by an internal transfer, lending,
+// staking or derivatives margin.
+func (h *LotHistory) IsPurchase() bool {
+	return h.Lot.LinkedSourceLotID == nil &&
+		h.Lot.AutoCostBasisSource != ledger.CostBasisLinkedTransfer &&
+		h.Lot.AutoCostBasisSource != ledger.CostBasisLendingCarryOver &&
+		h.Lot.AutoCostBasisSource != ledger.CostBasisStakingCarryOver &&
+		h.Lot.AutoCostBasisSource != ledger.CostBasisMarginCarryOver
+}
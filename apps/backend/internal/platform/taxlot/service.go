@@ -12,7 +12,9 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
 	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 	"github.com/kislikjeka/moontrack/pkg/money"
 )
@@ -49,26 +51,32 @@ type DisposalDetail struct {
 type Service struct {
 	taxLotRepo     ledger.TaxLotRepository
 	ledgerRepo     ledger.Repository
+	rebuilder      *ledger.TaxLotRebuilder
 	walletRepo     wallet.Repository
+	access         wallet.AccessChecker
+	audit          audit.Recorder // optional
 	logger         *logger.Logger
 	lastWACRefresh time.Time
 	wacRefreshMu   sync.Mutex
 }
 
 // NewService creates a new tax lot service.
-func NewService(taxLotRepo ledger.TaxLotRepository, ledgerRepo ledger.Repository, walletRepo wallet.Repository, log *logger.Logger) *Service {
+func NewService(taxLotRepo ledger.TaxLotRepository, ledgerRepo ledger.Repository, walletRepo wallet.Repository, access wallet.AccessChecker, recorder audit.Recorder, log *logger.Logger) *Service {
 	return &Service{
 		taxLotRepo: taxLotRepo,
 		ledgerRepo: ledgerRepo,
+		rebuilder:  ledger.NewTaxLotRebuilder(taxLotRepo, ledgerRepo, log),
 		walletRepo: walletRepo,
+		access:     access,
+		audit:      recorder,
 		logger:     log.WithField("component", "taxlot"),
 	}
 }
 
-// GetLotsByWallet returns tax lots for a wallet+asset, verifying ownership.
+// GetLotsByWallet returns tax lots for a wallet+asset, verifying read access.
 func (s *Service) GetLotsByWallet(ctx context.Context, userID, walletID uuid.UUID, asset string, chainID string) ([]*ledger.TaxLot, error) {
-	// Verify wallet ownership
-	if _, err := s.verifyWalletOwnership(ctx, userID, walletID); err != nil {
+	// Verify the user can view the wallet
+	if _, err := s.verifyWalletAccess(ctx, userID, walletID, workspace.RoleViewer); err != nil {
 		return nil, err
 	}
 
@@ -147,8 +155,8 @@ func (s *Service) OverrideCostBasis(ctx context.Context, userID uuid.UUID, lotID
 		return fmt.Errorf("failed to get tax lot: %w", err)
 	}
 
-	// Verify ownership: lot → account → wallet → user
-	if _, err := s.verifyLotOwnership(txCtx, userID, lot.AccountID); err != nil {
+	// Verify edit access: lot → account → wallet → workspace member
+	if _, err := s.verifyLotAccess(txCtx, userID, lot.AccountID, workspace.RoleEditor); err != nil {
 		return err
 	}
 
@@ -182,6 +190,22 @@ func (s *Service) OverrideCostBasis(ctx context.Context, userID uuid.UUID, lotID
 		"reason", reason,
 	)
 
+	if s.audit != nil {
+		before := map[string]any{"cost_basis_per_unit": nil, "reason": nil}
+		if lot.OverrideCostBasisPerUnit != nil {
+			before["cost_basis_per_unit"] = lot.OverrideCostBasisPerUnit.String()
+			before["reason"] = lot.OverrideReason
+		}
+		s.audit.Record(ctx, audit.Event{
+			ActorID:    userID,
+			Action:     audit.ActionLotOverride,
+			TargetType: audit.TargetTaxLot,
+			TargetID:   lotID.String(),
+			Before:     before,
+			After:      map[string]any{"cost_basis_per_unit": costBasis.String(), "reason": reason},
+		})
+	}
+
 	if err := s.ForceRefreshWAC(ctx); err != nil {
 		s.logger.Warn("failed to refresh WAC after override", "lot_id", lotID, "error", err)
 	}
@@ -189,6 +213,100 @@ func (s *Service) OverrideCostBasis(ctx context.Context, userID uuid.UUID, lotID
 	return nil
 }
 
+// RebuildLots recomputes lots and disposals from the ledger, replaying every
+// transaction in date order. Without walletID it covers every wallet the user
+// can edit; a non-empty asset limits the rebuild to that asset.
+// Cost-basis overrides are kept on the lot recreated for the same transaction.
+func (s *Service) RebuildLots(ctx context.Context, userID uuid.UUID, walletID *uuid.UUID, asset string) error {
+	var wallets []*wallet.Wallet
+	if walletID != nil {
+		w, err := s.verifyWalletAccess(ctx, userID, *walletID, workspace.RoleEditor)
+		if err != nil {
+			return err
+		}
+		wallets = []*wallet.Wallet{w}
+	} else {
+		accessible, err := s.walletRepo.GetAccessibleByUserID(ctx, userID, nil)
+		if err != nil {
+			return fmt.Errorf("failed to get wallets for user: %w", err)
+		}
+		for _, w := range accessible {
+			if err := s.authorize(ctx, w.WorkspaceID, userID, workspace.RoleEditor); err != nil {
+				if errors.Is(err, ErrWalletNotOwned) {
+					continue
+				}
+				return err
+			}
+			wallets = append(wallets, w)
+		}
+	}
+
+	var accountIDs []uuid.UUID
+	for _, w := range wallets {
+		accounts, err := s.ledgerRepo.FindAccountsByWallet(ctx, w.ID)
+		if err != nil {
+			return fmt.Errorf("failed to find accounts for wallet %s: %w", w.ID, err)
+		}
+		for _, acc := range accounts {
+			accountIDs = append(accountIDs, acc.ID)
+		}
+	}
+	if len(accountIDs) == 0 {
+		return nil
+	}
+
+	txCtx, err := s.ledgerRepo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer s.ledgerRepo.RollbackTx(txCtx)
+
+	if asset == "" {
+		err = s.rebuilder.RebuildAccounts(txCtx, accountIDs)
+	} else {
+		pairs := make([]ledger.AccountAsset, 0, len(accountIDs))
+		for _, id := range accountIDs {
+			pairs = append(pairs, ledger.AccountAsset{AccountID: id, Asset: asset})
+		}
+		err = s.rebuilder.Rebuild(txCtx, pairs, time.Time{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to rebuild tax lots: %w", err)
+	}
+
+	if err := s.ledgerRepo.CommitTx(txCtx); err != nil {
+		return fmt.Errorf("failed to commit rebuild: %w", err)
+	}
+
+	s.logger.Info("tax lots rebuilt",
+		"user_id", userID,
+		"wallets", len(wallets),
+		"accounts", len(accountIDs),
+		"asset", asset,
+	)
+
+	if s.audit != nil {
+		event := audit.Event{
+			ActorID:    userID,
+			Action:     audit.ActionLotRebuild,
+			TargetType: audit.TargetUser,
+			TargetID:   userID.String(),
+			After:      map[string]any{"wallets": len(wallets), "asset": asset},
+		}
+		if walletID != nil {
+			event.TargetType = audit.TargetWallet
+			event.TargetID = walletID.String()
+		}
+		s.audit.Record(ctx, event)
+	}
+
+	if err := s.ForceRefreshWAC(ctx); err != nil {
+		s.logger.Warn("failed to refresh WAC after rebuild", "user_id", userID, "error", err)
+	}
+
+	return nil
+}
+
 // GetLotImpactByTransaction returns all lot acquisitions and disposals for a transaction.
 func (s *Service) GetLotImpactByTransaction(ctx context.Context, userID, txID uuid.UUID) (*TransactionLotImpact, error) {
 	acquired, err := s.taxLotRepo.GetLotsByTransaction(ctx, txID)
@@ -205,10 +323,10 @@ func (s *Service) GetLotImpactByTransaction(ctx context.Context, userID, txID uu
 		return &TransactionLotImpact{HasLotImpact: false}, nil
 	}
 
-	// Verify ownership via at least one lot or disposal's lot
+	// Verify read access via at least one lot or disposal's lot
 	ownershipVerified := false
 	for _, lot := range acquired {
-		if _, err := s.verifyLotOwnership(ctx, userID, lot.AccountID); err == nil {
+		if _, err := s.verifyLotAccess(ctx, userID, lot.AccountID, workspace.RoleViewer); err == nil {
 			ownershipVerified = true
 			break
 		}
@@ -223,7 +341,7 @@ func (s *Service) GetLotImpactByTransaction(ctx context.Context, userID, txID uu
 		}
 
 		if !ownershipVerified {
-			if _, err := s.verifyLotOwnership(ctx, userID, lot.AccountID); err == nil {
+			if _, err := s.verifyLotAccess(ctx, userID, lot.AccountID, workspace.RoleViewer); err == nil {
 				ownershipVerified = true
 			}
 		}
@@ -359,8 +477,8 @@ func (s *Service) GetWAC(ctx context.Context, userID uuid.UUID, walletID *uuid.U
 	return result, nil
 }
 
-// verifyLotOwnership checks lot → account → wallet → user chain.
-func (s *Service) verifyLotOwnership(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (*wallet.Wallet, error) {
+// verifyLotAccess checks lot → account → wallet → workspace role chain.
+func (s *Service) verifyLotAccess(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, required workspace.Role) (*wallet.Wallet, error) {
 	account, err := s.ledgerRepo.GetAccount(ctx, accountID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get account: %w", err)
@@ -375,15 +493,18 @@ func (s *Service) verifyLotOwnership(ctx context.Context, userID uuid.UUID, acco
 		return nil, fmt.Errorf("failed to get wallet: %w", err)
 	}
 
-	if w.UserID != userID {
-		return nil, ErrLotNotOwned
+	if err := s.authorize(ctx, w.WorkspaceID, userID, required); err != nil {
+		if errors.Is(err, ErrWalletNotOwned) {
+			return nil, ErrLotNotOwned
+		}
+		return nil, err
 	}
 
 	return w, nil
 }
 
-// verifyWalletOwnership checks that a wallet belongs to the user.
-func (s *Service) verifyWalletOwnership(ctx context.Context, userID uuid.UUID, walletID uuid.UUID) (*wallet.Wallet, error) {
+// verifyWalletAccess checks that the user holds the required role in the wallet's workspace.
+func (s *Service) verifyWalletAccess(ctx context.Context, userID uuid.UUID, walletID uuid.UUID, required workspace.Role) (*wallet.Wallet, error) {
 	w, err := s.walletRepo.GetByID(ctx, walletID)
 	if err != nil {
 		if errors.Is(err, wallet.ErrWalletNotFound) {
@@ -392,27 +513,39 @@ func (s *Service) verifyWalletOwnership(ctx context.Context, userID uuid.UUID, w
 		return nil, fmt.Errorf("failed to get wallet: %w", err)
 	}
 
-	if w.UserID != userID {
-		return nil, ErrWalletNotOwned
+	if err := s.authorize(ctx, w.WorkspaceID, userID, required); err != nil {
+		return nil, err
 	}
 
 	return w, nil
 }
 
-// getAccountsForUser returns a wallet lookup map and all account IDs for a user's wallets.
+// authorize maps workspace access denials to ErrWalletNotOwned.
+func (s *Service) authorize(ctx context.Context, workspaceID, userID uuid.UUID, required workspace.Role) error {
+	err := s.access.Authorize(ctx, workspaceID, userID, required)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, workspace.ErrNotMember) || errors.Is(err, workspace.ErrInsufficientRole) {
+		return ErrWalletNotOwned
+	}
+	return fmt.Errorf("failed to check workspace access: %w", err)
+}
+
+// getAccountsForUser returns a wallet lookup map and all account IDs for wallets the user can access.
 // If walletID is non-nil, only that wallet is included.
 func (s *Service) getAccountsForUser(ctx context.Context, userID uuid.UUID, walletID *uuid.UUID) (map[uuid.UUID]*wallet.Wallet, []uuid.UUID, error) {
 	var wallets []*wallet.Wallet
 
 	if walletID != nil {
-		w, err := s.verifyWalletOwnership(ctx, userID, *walletID)
+		w, err := s.verifyWalletAccess(ctx, userID, *walletID, workspace.RoleViewer)
 		if err != nil {
 			return nil, nil, err
 		}
 		wallets = []*wallet.Wallet{w}
 	} else {
 		var err error
-		wallets, err = s.walletRepo.GetByUserID(ctx, userID)
+		wallets, err = s.walletRepo.GetAccessibleByUserID(ctx, userID, nil)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get wallets for user: %w", err)
 		}
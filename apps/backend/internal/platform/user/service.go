@@ -6,19 +6,22 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
 
 // Service handles user business logic
 type Service struct {
 	repo   Repository
+	audit  audit.Recorder // optional
 	logger *logger.Logger
 }
 
 // NewService creates a new user service
-func NewService(repo Repository, log *logger.Logger) *Service {
+func NewService(repo Repository, recorder audit.Recorder, log *logger.Logger) *Service {
 	return &Service{
 		repo:   repo,
+		audit:  recorder,
 		logger: log.WithField("component", "user"),
 	}
 }
@@ -82,6 +85,7 @@ func (s *Service) Login(ctx context.Context, email, password string) (*User, err
 	// Check password
 	if err := user.CheckPassword(password); err != nil {
 		s.logger.Warn("login failed", "email", email)
+		s.recordLogin(ctx, user.ID, audit.ActionLoginFailed)
 		return nil, err
 	}
 
@@ -94,6 +98,7 @@ func (s *Service) Login(ctx context.Context, email, password string) (*User, err
 	}
 
 	s.logger.Info("user logged in", "user_id", user.ID)
+	s.recordLogin(ctx, user.ID, audit.ActionLogin)
 
 	return user, nil
 }
@@ -107,3 +112,17 @@ func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
 func (s *Service) GetByEmail(ctx context.Context, email string) (*User, error) {
 	return s.repo.GetByEmail(ctx, email)
 }
+
+// recordLogin adds a login attempt to the account's audit trail.
+// Attempts for unknown emails have no account to attach to and are only logged.
+func (s *Service) recordLogin(ctx context.Context, userID uuid.UUID, action audit.Action) {
+	if s.audit == nil {
+		return
+	}
+	s.audit.Record(ctx, audit.Event{
+		ActorID:    userID,
+		Action:     action,
+		TargetType: audit.TargetUser,
+		TargetID:   userID.String(),
+	})
+}
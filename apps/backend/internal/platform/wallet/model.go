@@ -30,6 +30,7 @@ func (s SyncStatus) IsValid() bool {
 type Wallet struct {
 	ID            uuid.UUID  `json:"id" db:"id"`
 	UserID        uuid.UUID  `json:"user_id" db:"user_id"`
+	WorkspaceID   uuid.UUID  `json:"workspace_id" db:"workspace_id"` // Workspace that owns the wallet; Nil means the creator's personal workspace
 	Name          string     `json:"name" db:"name"`
 	Address       string     `json:"address" db:"address"`           // Required EVM address (0x...)
 	SyncStatus    SyncStatus `json:"sync_status" db:"sync_status"`   // Sync state
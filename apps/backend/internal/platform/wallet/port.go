@@ -2,9 +2,13 @@ package wallet
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
 )
 
 // Repository defines the interface for wallet data access
@@ -18,6 +22,10 @@ type Repository interface {
 	// GetByUserID retrieves all wallets for a user
 	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*Wallet, error)
 
+	// GetAccessibleByUserID retrieves all wallets in workspaces the user belongs to,
+	// optionally restricted to a single workspace
+	GetAccessibleByUserID(ctx context.Context, userID uuid.UUID, workspaceID *uuid.UUID) ([]*Wallet, error)
+
 	// Update updates an existing wallet
 	Update(ctx context.Context, wallet *Wallet) error
 
@@ -48,3 +56,70 @@ type Repository interface {
 	// SetSyncError marks a wallet sync as failed with an error message
 	SetSyncError(ctx context.Context, walletID uuid.UUID, errMsg string) error
 }
+
+// AccessChecker resolves a user's workspace permissions for wallet operations
+type AccessChecker interface {
+	// Authorize returns workspace.ErrNotMember or workspace.ErrInsufficientRole when access is denied
+	Authorize(ctx context.Context, workspaceID, userID uuid.UUID, required workspace.Role) error
+}
+
+// CheckAccess verifies that the user holds at least the required role in the
+// wallet's workspace. Denials are returned as ErrUnauthorizedAccess.
+func CheckAccess(ctx context.Context, access AccessChecker, w *Wallet, userID uuid.UUID, required workspace.Role) error {
+	return authorize(ctx, access, w.WorkspaceID, userID, required)
+}
+
+func authorize(ctx context.Context, access AccessChecker, workspaceID, userID uuid.UUID, required workspace.Role) error {
+	err := access.Authorize(ctx, workspaceID, userID, required)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, workspace.ErrNotMember) || errors.Is(err, workspace.ErrInsufficientRole) {
+		return ErrUnauthorizedAccess
+	}
+	return fmt.Errorf("failed to check workspace access: %w", err)
+}
+
+// Reader resolves wallets through the caller's workspace access
+type Reader interface {
+	// GetByID returns the wallet if the user can view it
+	GetByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Wallet, error)
+
+	// List returns all wallets the user can view across their workspaces
+	List(ctx context.Context, userID uuid.UUID) ([]*Wallet, error)
+}
+
+// CanView reports whether the user can view the wallet. A missing wallet
+// reads as not viewable, so callers can't probe for wallet IDs.
+func CanView(ctx context.Context, wallets Reader, walletID, userID uuid.UUID) (bool, error) {
+	_, err := wallets.GetByID(ctx, walletID, userID)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, ErrUnauthorizedAccess) || errors.Is(err, ErrWalletNotFound) {
+		return false, nil
+	}
+	return false, err
+}
+
+// AccessibleIDs returns the IDs of the wallets the user can view, narrowed to
+// walletID when it is set. A wallet the user cannot view yields no IDs.
+func AccessibleIDs(ctx context.Context, wallets Reader, userID uuid.UUID, walletID *uuid.UUID) ([]uuid.UUID, error) {
+	if walletID != nil {
+		ok, err := CanView(ctx, wallets, *walletID, userID)
+		if err != nil || !ok {
+			return nil, err
+		}
+		return []uuid.UUID{*walletID}, nil
+	}
+
+	ws, err := wallets.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, len(ws))
+	for i, w := range ws {
+		ids[i] = w.ID
+	}
+	return ids, nil
+}
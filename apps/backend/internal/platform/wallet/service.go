@@ -5,19 +5,25 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
 	"github.com/kislikjeka/moontrack/pkg/logger"
 )
 
 // Service provides business logic for wallet operations
 type Service struct {
 	repo   Repository
+	access AccessChecker
+	audit  audit.Recorder // optional
 	logger *logger.Logger
 }
 
 // NewService creates a new wallet service
-func NewService(repo Repository, log *logger.Logger) *Service {
+func NewService(repo Repository, access AccessChecker, recorder audit.Recorder, log *logger.Logger) *Service {
 	return &Service{
 		repo:   repo,
+		access: access,
+		audit:  recorder,
 		logger: log.WithField("component", "wallet"),
 	}
 }
@@ -39,6 +45,13 @@ func (s *Service) Create(ctx context.Context, wallet *Wallet) (*Wallet, error) {
 		return nil, ErrDuplicateWalletName
 	}
 
+	// Adding a wallet to a shared workspace requires editor rights there
+	if wallet.WorkspaceID != uuid.Nil {
+		if err := s.authorize(ctx, wallet.WorkspaceID, wallet.UserID, workspace.RoleEditor); err != nil {
+			return nil, err
+		}
+	}
+
 	// Check if wallet with same address already exists for user
 	addrExists, err := s.repo.ExistsByUserAndAddress(ctx, wallet.UserID, wallet.Address)
 	if err != nil {
@@ -57,29 +70,49 @@ func (s *Service) Create(ctx context.Context, wallet *Wallet) (*Wallet, error) {
 	}
 
 	s.logger.Info("wallet created", "wallet_id", wallet.ID, "user_id", wallet.UserID)
+	s.record(ctx, wallet.UserID, audit.ActionWalletCreate, wallet.ID, nil, wallet)
 
 	return wallet, nil
 }
 
-// GetByID retrieves a wallet by ID and validates user ownership
+// GetByID retrieves a wallet by ID and validates the user can view it
 func (s *Service) GetByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Wallet, error) {
+	return s.GetWithRole(ctx, id, userID, workspace.RoleViewer)
+}
+
+// GetWithRole retrieves a wallet by ID and validates the user holds at least
+// the required role in the wallet's workspace
+func (s *Service) GetWithRole(ctx context.Context, id uuid.UUID, userID uuid.UUID, required workspace.Role) (*Wallet, error) {
 	wallet, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	// Verify wallet belongs to requesting user
-	if wallet.UserID != userID {
-		s.logger.Warn("unauthorized wallet access", "wallet_id", id, "user_id", userID)
-		return nil, ErrUnauthorizedAccess
+	if err := s.authorize(ctx, wallet.WorkspaceID, userID, required); err != nil {
+		s.logger.Warn("unauthorized wallet access", "wallet_id", id, "user_id", userID, "required_role", required)
+		return nil, err
 	}
 
 	return wallet, nil
 }
 
-// List retrieves all wallets for a user
+// List retrieves all wallets the user can access across their workspaces
 func (s *Service) List(ctx context.Context, userID uuid.UUID) ([]*Wallet, error) {
-	wallets, err := s.repo.GetByUserID(ctx, userID)
+	wallets, err := s.repo.GetAccessibleByUserID(ctx, userID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallets: %w", err)
+	}
+
+	return wallets, nil
+}
+
+// ListByWorkspace retrieves the wallets of a single workspace the user belongs to
+func (s *Service) ListByWorkspace(ctx context.Context, userID, workspaceID uuid.UUID) ([]*Wallet, error) {
+	if err := s.authorize(ctx, workspaceID, userID, workspace.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	wallets, err := s.repo.GetAccessibleByUserID(ctx, userID, &workspaceID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list wallets: %w", err)
 	}
@@ -94,19 +127,15 @@ func (s *Service) Update(ctx context.Context, wallet *Wallet, userID uuid.UUID)
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Get existing wallet to verify ownership
-	existing, err := s.repo.GetByID(ctx, wallet.ID)
+	// Get existing wallet to verify edit permission
+	existing, err := s.GetWithRole(ctx, wallet.ID, userID, workspace.RoleEditor)
 	if err != nil {
 		return nil, err
 	}
 
-	if existing.UserID != userID {
-		return nil, ErrUnauthorizedAccess
-	}
-
-	// Check if new name conflicts with existing wallet
+	// Check if new name conflicts with another wallet of the same creator
 	if wallet.Name != existing.Name {
-		exists, err := s.repo.ExistsByUserAndName(ctx, userID, wallet.Name)
+		exists, err := s.repo.ExistsByUserAndName(ctx, existing.UserID, wallet.Name)
 		if err != nil {
 			return nil, fmt.Errorf("failed to check wallet name: %w", err)
 		}
@@ -116,8 +145,9 @@ func (s *Service) Update(ctx context.Context, wallet *Wallet, userID uuid.UUID)
 		}
 	}
 
-	// Preserve user ID from existing wallet
+	// Preserve creator and workspace from existing wallet
 	wallet.UserID = existing.UserID
+	wallet.WorkspaceID = existing.WorkspaceID
 
 	// Update wallet
 	if err := s.repo.Update(ctx, wallet); err != nil {
@@ -125,29 +155,52 @@ func (s *Service) Update(ctx context.Context, wallet *Wallet, userID uuid.UUID)
 	}
 
 	s.logger.Info("wallet updated", "wallet_id", wallet.ID, "user_id", userID)
+	s.record(ctx, userID, audit.ActionWalletUpdate, wallet.ID, existing, wallet)
 
 	return wallet, nil
 }
 
 // Delete deletes a wallet
 func (s *Service) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
-	// Get existing wallet to verify ownership
-	wallet, err := s.repo.GetByID(ctx, id)
+	// Only workspace owners can delete wallets
+	existing, err := s.GetWithRole(ctx, id, userID, workspace.RoleOwner)
 	if err != nil {
 		return err
 	}
 
-	if wallet.UserID != userID {
-		s.logger.Warn("unauthorized wallet access", "wallet_id", id, "user_id", userID)
-		return ErrUnauthorizedAccess
-	}
-
 	// Delete wallet
 	if err := s.repo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete wallet: %w", err)
 	}
 
 	s.logger.Info("wallet deleted", "wallet_id", id, "user_id", userID)
+	s.record(ctx, userID, audit.ActionWalletDelete, id, existing, nil)
 
 	return nil
 }
+
+// authorize checks the user's workspace role, mapping denials to ErrUnauthorizedAccess
+func (s *Service) authorize(ctx context.Context, workspaceID, userID uuid.UUID, required workspace.Role) error {
+	return authorize(ctx, s.access, workspaceID, userID, required)
+}
+
+// record reports a wallet mutation to the audit log, if one is configured
+func (s *Service) record(ctx context.Context, actorID uuid.UUID, action audit.Action, walletID uuid.UUID, before, after *Wallet) {
+	if s.audit == nil {
+		return
+	}
+	event := audit.Event{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: audit.TargetWallet,
+		TargetID:   walletID.String(),
+	}
+	// Assign only non-nil pointers so absent states are stored as NULL, not "null"
+	if before != nil {
+		event.Before = before
+	}
+	if after != nil {
+		event.After = after
+	}
+	s.audit.Record(ctx, event)
+}
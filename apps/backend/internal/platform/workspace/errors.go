@@ -0,0 +1,28 @@
+package workspace
+
+import "errors"
+
+var (
+	// Validation errors
+	ErrInvalidUserID = errors.New("invalid user ID")
+	ErrMissingName   = errors.New("workspace name is required")
+	ErrNameTooLong   = errors.New("workspace name exceeds 100 characters")
+	ErrInvalidRole   = errors.New("invalid workspace role")
+	ErrInvalidEmail  = errors.New("invalid email address")
+
+	// Access errors
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	ErrNotMember         = errors.New("user is not a member of this workspace")
+	ErrInsufficientRole  = errors.New("insufficient workspace role")
+
+	// Membership errors
+	ErrAlreadyMember     = errors.New("user is already a member of this workspace")
+	ErrMemberNotFound    = errors.New("workspace member not found")
+	ErrOwnerImmutable    = errors.New("workspace owner cannot be removed or demoted")
+	ErrPersonalWorkspace = errors.New("personal workspaces cannot be shared")
+
+	// Invitation errors
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvitationExpired  = errors.New("invitation has expired or was already used")
+	ErrInvitationEmail    = errors.New("invitation was sent to a different email address")
+)
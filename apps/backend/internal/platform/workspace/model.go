@@ -0,0 +1,100 @@
+package workspace
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role is a member's permission level within a workspace
+type Role string
+
+const (
+	RoleOwner  Role = "owner"  // Full control, including members and wallet deletion
+	RoleEditor Role = "editor" // Can add/edit wallets and record transactions
+	RoleViewer Role = "viewer" // Read-only access
+)
+
+// IsValid checks if the role is valid
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleOwner, RoleEditor, RoleViewer:
+		return true
+	}
+	return false
+}
+
+// rank orders roles by privilege so that higher roles imply lower ones
+func (r Role) rank() int {
+	switch r {
+	case RoleOwner:
+		return 3
+	case RoleEditor:
+		return 2
+	case RoleViewer:
+		return 1
+	}
+	return 0
+}
+
+// Allows reports whether the role grants at least the required permission level
+func (r Role) Allows(required Role) bool {
+	return r.rank() > 0 && r.rank() >= required.rank()
+}
+
+// Workspace is a shared portfolio that owns wallets and has members
+type Workspace struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	OwnerID    uuid.UUID `json:"owner_id"`
+	IsPersonal bool      `json:"is_personal"` // Default workspace created for every user
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// Role is the requesting user's role, populated by membership queries
+	Role Role `json:"role,omitempty"`
+}
+
+// Validate validates workspace fields for creation
+func (w *Workspace) Validate() error {
+	if w.OwnerID == uuid.Nil {
+		return ErrInvalidUserID
+	}
+	w.Name = strings.TrimSpace(w.Name)
+	if w.Name == "" {
+		return ErrMissingName
+	}
+	if len(w.Name) > 100 {
+		return ErrNameTooLong
+	}
+	return nil
+}
+
+// Member is a user's membership in a workspace
+type Member struct {
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Email       string    `json:"email,omitempty"` // Populated on listing
+	Role        Role      `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Invitation is a pending email invitation to join a workspace
+type Invitation struct {
+	ID          uuid.UUID  `json:"id"`
+	WorkspaceID uuid.UUID  `json:"workspace_id"`
+	Email       string     `json:"email"`
+	Role        Role       `json:"role"`
+	Token       string     `json:"-"`
+	InvitedBy   uuid.UUID  `json:"invited_by"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+	AcceptedBy  *uuid.UUID `json:"accepted_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// IsPending returns true if the invitation can still be accepted
+func (i *Invitation) IsPending(now time.Time) bool {
+	return i.AcceptedAt == nil && now.Before(i.ExpiresAt)
+}
@@ -0,0 +1,57 @@
+package workspace
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Repository defines the interface for workspace data access.
+// Lookups return nil, nil when the record does not exist.
+type Repository interface {
+	// Create inserts a workspace together with its owner membership
+	Create(ctx context.Context, ws *Workspace) error
+
+	// GetByID retrieves a workspace by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*Workspace, error)
+
+	// GetPersonal retrieves the user's personal workspace
+	GetPersonal(ctx context.Context, userID uuid.UUID) (*Workspace, error)
+
+	// ListByUser retrieves all workspaces the user is a member of, with Role set
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*Workspace, error)
+
+	// GetMember retrieves a single membership
+	GetMember(ctx context.Context, workspaceID, userID uuid.UUID) (*Member, error)
+
+	// ListMembers retrieves all members of a workspace
+	ListMembers(ctx context.Context, workspaceID uuid.UUID) ([]*Member, error)
+
+	// AddMember inserts a membership
+	AddMember(ctx context.Context, member *Member) error
+
+	// UpdateMemberRole changes a member's role
+	UpdateMemberRole(ctx context.Context, workspaceID, userID uuid.UUID, role Role) error
+
+	// RemoveMember deletes a membership
+	RemoveMember(ctx context.Context, workspaceID, userID uuid.UUID) error
+
+	// CreateInvitation inserts an invitation
+	CreateInvitation(ctx context.Context, inv *Invitation) error
+
+	// GetInvitationByToken retrieves an invitation by its secret token
+	GetInvitationByToken(ctx context.Context, token string) (*Invitation, error)
+
+	// ListPendingInvitations retrieves unaccepted, unexpired invitations for a workspace
+	ListPendingInvitations(ctx context.Context, workspaceID uuid.UUID) ([]*Invitation, error)
+
+	// AcceptInvitation marks a pending invitation accepted by the member and
+	// inserts the membership in one transaction. Returns ErrInvitationExpired
+	// if the invitation was already used or has expired.
+	AcceptInvitation(ctx context.Context, id uuid.UUID, member *Member) error
+}
+
+// InvitationSender delivers invitation emails
+type InvitationSender interface {
+	SendInvitation(ctx context.Context, inv *Invitation, ws *Workspace) error
+}
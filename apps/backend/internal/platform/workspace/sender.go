@@ -0,0 +1,31 @@
+package workspace
+
+import (
+	"context"
+
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// LogSender is an InvitationSender that writes the invitation to the log
+// instead of delivering email. Used until an SMTP provider is configured.
+type LogSender struct {
+	logger *logger.Logger
+}
+
+// NewLogSender creates a new log-backed invitation sender
+func NewLogSender(log *logger.Logger) *LogSender {
+	return &LogSender{logger: log.WithField("component", "workspace_invitations")}
+}
+
+// SendInvitation logs the invitation recipient. The token is a bearer
+// secret and is never logged.
+func (s *LogSender) SendInvitation(_ context.Context, inv *Invitation, ws *Workspace) error {
+	s.logger.Info("workspace invitation",
+		"invitation_id", inv.ID,
+		"email", inv.Email,
+		"workspace", ws.Name,
+		"role", inv.Role,
+		"expires_at", inv.ExpiresAt,
+	)
+	return nil
+}
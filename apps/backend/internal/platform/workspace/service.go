@@ -0,0 +1,378 @@
+package workspace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// invitationTTL is how long an invitation link stays valid
+const invitationTTL = 7 * 24 * time.Hour
+
+// Service provides business logic for workspaces, membership and invitations
+type Service struct {
+	repo   Repository
+	sender InvitationSender
+	audit  audit.Recorder // optional
+	logger *logger.Logger
+}
+
+// NewService creates a new workspace service
+func NewService(repo Repository, sender InvitationSender, recorder audit.Recorder, log *logger.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		sender: sender,
+		audit:  recorder,
+		logger: log.WithField("component", "workspace"),
+	}
+}
+
+// Authorize verifies that the user holds at least the required role in the workspace.
+// Returns ErrNotMember or ErrInsufficientRole when access is denied.
+func (s *Service) Authorize(ctx context.Context, workspaceID, userID uuid.UUID, required Role) error {
+	_, err := s.authorize(ctx, workspaceID, userID, required)
+	return err
+}
+
+func (s *Service) authorize(ctx context.Context, workspaceID, userID uuid.UUID, required Role) (*Member, error) {
+	member, err := s.repo.GetMember(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace member: %w", err)
+	}
+	if member == nil {
+		return nil, ErrNotMember
+	}
+	if !member.Role.Allows(required) {
+		return nil, ErrInsufficientRole
+	}
+	return member, nil
+}
+
+// Create creates a shared workspace owned by the user
+func (s *Service) Create(ctx context.Context, ownerID uuid.UUID, name string) (*Workspace, error) {
+	ws := &Workspace{
+		ID:      uuid.New(),
+		Name:    name,
+		OwnerID: ownerID,
+	}
+	if err := ws.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := s.repo.Create(ctx, ws); err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	ws.Role = RoleOwner
+
+	s.logger.Info("workspace created", "workspace_id", ws.ID, "owner_id", ownerID)
+	return ws, nil
+}
+
+// PersonalWorkspace returns the user's personal workspace, creating it if needed
+func (s *Service) PersonalWorkspace(ctx context.Context, userID uuid.UUID) (*Workspace, error) {
+	ws, err := s.repo.GetPersonal(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get personal workspace: %w", err)
+	}
+	if ws != nil {
+		ws.Role = RoleOwner
+		return ws, nil
+	}
+
+	ws = &Workspace{
+		ID:         uuid.New(),
+		Name:       "Personal",
+		OwnerID:    userID,
+		IsPersonal: true,
+	}
+	if err := s.repo.Create(ctx, ws); err != nil {
+		return nil, fmt.Errorf("failed to create personal workspace: %w", err)
+	}
+	ws.Role = RoleOwner
+	return ws, nil
+}
+
+// List returns all workspaces the user belongs to
+func (s *Service) List(ctx context.Context, userID uuid.UUID) ([]*Workspace, error) {
+	workspaces, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	return workspaces, nil
+}
+
+// Get returns a workspace the user is a member of
+func (s *Service) Get(ctx context.Context, workspaceID, userID uuid.UUID) (*Workspace, error) {
+	member, err := s.authorize(ctx, workspaceID, userID, RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err := s.getWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	ws.Role = member.Role
+	return ws, nil
+}
+
+// ListMembers returns the members of a workspace visible to the user
+func (s *Service) ListMembers(ctx context.Context, workspaceID, userID uuid.UUID) ([]*Member, error) {
+	if _, err := s.authorize(ctx, workspaceID, userID, RoleViewer); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListMembers(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	return members, nil
+}
+
+// ListInvitations returns pending invitations; only owners can see them
+func (s *Service) ListInvitations(ctx context.Context, workspaceID, userID uuid.UUID) ([]*Invitation, error) {
+	if _, err := s.authorize(ctx, workspaceID, userID, RoleOwner); err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.repo.ListPendingInvitations(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+// Invite creates an invitation for the given email and sends it.
+// Only owners can invite, and invitations cannot grant the owner role.
+func (s *Service) Invite(ctx context.Context, workspaceID, inviterID uuid.UUID, email string, role Role) (*Invitation, error) {
+	if role != RoleEditor && role != RoleViewer {
+		return nil, ErrInvalidRole
+	}
+
+	addr, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return nil, ErrInvalidEmail
+	}
+
+	if _, err := s.authorize(ctx, workspaceID, inviterID, RoleOwner); err != nil {
+		return nil, err
+	}
+
+	ws, err := s.getWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if ws.IsPersonal {
+		return nil, ErrPersonalWorkspace
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+
+	now := time.Now().UTC()
+	inv := &Invitation{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		Email:       strings.ToLower(addr.Address),
+		Role:        role,
+		Token:       token,
+		InvitedBy:   inviterID,
+		ExpiresAt:   now.Add(invitationTTL),
+		CreatedAt:   now,
+	}
+
+	if err := s.repo.CreateInvitation(ctx, inv); err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	if s.sender != nil {
+		if err := s.sender.SendInvitation(ctx, inv, ws); err != nil {
+			// The invitation is stored; the owner can resend or share the link manually
+			s.logger.Warn("failed to send invitation email", "invitation_id", inv.ID, "error", err)
+		}
+	}
+
+	s.logger.Info("workspace invitation created", "workspace_id", workspaceID, "invitation_id", inv.ID, "role", role)
+	s.record(ctx, inviterID, audit.ActionWorkspaceInvite, workspaceID, nil,
+		map[string]any{"invitation_id": inv.ID, "email": inv.Email, "role": inv.Role})
+	return inv, nil
+}
+
+// AcceptInvitation adds the user to the invited workspace.
+// The user's email must match the address the invitation was sent to.
+func (s *Service) AcceptInvitation(ctx context.Context, token string, userID uuid.UUID, email string) (*Workspace, error) {
+	inv, err := s.repo.GetInvitationByToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if inv == nil {
+		return nil, ErrInvitationNotFound
+	}
+	if !inv.IsPending(time.Now().UTC()) {
+		return nil, ErrInvitationExpired
+	}
+	if !strings.EqualFold(inv.Email, strings.TrimSpace(email)) {
+		return nil, ErrInvitationEmail
+	}
+
+	existing, err := s.repo.GetMember(ctx, inv.WorkspaceID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace member: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrAlreadyMember
+	}
+
+	member := &Member{
+		WorkspaceID: inv.WorkspaceID,
+		UserID:      userID,
+		Role:        inv.Role,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := s.repo.AcceptInvitation(ctx, inv.ID, member); err != nil {
+		if errors.Is(err, ErrInvitationExpired) || errors.Is(err, ErrAlreadyMember) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	ws, err := s.getWorkspace(ctx, inv.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	ws.Role = inv.Role
+
+	s.logger.Info("workspace invitation accepted", "workspace_id", inv.WorkspaceID, "user_id", userID, "role", inv.Role)
+	s.record(ctx, userID, audit.ActionWorkspaceJoin, inv.WorkspaceID, nil,
+		map[string]any{"invitation_id": inv.ID, "user_id": userID, "role": inv.Role})
+	return ws, nil
+}
+
+// UpdateMemberRole changes a member's role. Only owners can change roles,
+// and the workspace owner's own role is fixed.
+func (s *Service) UpdateMemberRole(ctx context.Context, workspaceID, actorID, memberID uuid.UUID, role Role) error {
+	if role != RoleEditor && role != RoleViewer {
+		return ErrInvalidRole
+	}
+
+	if _, err := s.authorize(ctx, workspaceID, actorID, RoleOwner); err != nil {
+		return err
+	}
+
+	ws, err := s.getWorkspace(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	if ws.OwnerID == memberID {
+		return ErrOwnerImmutable
+	}
+
+	member, err := s.repo.GetMember(ctx, workspaceID, memberID)
+	if err != nil {
+		return fmt.Errorf("failed to get workspace member: %w", err)
+	}
+	if member == nil {
+		return ErrMemberNotFound
+	}
+
+	previous := member.Role
+	if err := s.repo.UpdateMemberRole(ctx, workspaceID, memberID, role); err != nil {
+		return fmt.Errorf("failed to update member role: %w", err)
+	}
+
+	s.logger.Info("workspace member role updated", "workspace_id", workspaceID, "user_id", memberID, "role", role)
+	s.record(ctx, actorID, audit.ActionMemberRoleUpdate, workspaceID,
+		map[string]any{"user_id": memberID, "role": previous},
+		map[string]any{"user_id": memberID, "role": role})
+	return nil
+}
+
+// RemoveMember removes a member. Owners can remove anyone but themselves;
+// any other member can remove only themselves (leave the workspace).
+func (s *Service) RemoveMember(ctx context.Context, workspaceID, actorID, memberID uuid.UUID) error {
+	required := RoleOwner
+	if actorID == memberID {
+		required = RoleViewer
+	}
+	if _, err := s.authorize(ctx, workspaceID, actorID, required); err != nil {
+		return err
+	}
+
+	ws, err := s.getWorkspace(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	if ws.OwnerID == memberID {
+		return ErrOwnerImmutable
+	}
+
+	member, err := s.repo.GetMember(ctx, workspaceID, memberID)
+	if err != nil {
+		return fmt.Errorf("failed to get workspace member: %w", err)
+	}
+	if member == nil {
+		return ErrMemberNotFound
+	}
+
+	if err := s.repo.RemoveMember(ctx, workspaceID, memberID); err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+
+	s.logger.Info("workspace member removed", "workspace_id", workspaceID, "user_id", memberID, "removed_by", actorID)
+	s.record(ctx, actorID, audit.ActionMemberRemove, workspaceID,
+		map[string]any{"user_id": memberID, "role": member.Role}, nil)
+	return nil
+}
+
+// record reports a membership change to the audit log, if one is configured
+func (s *Service) record(ctx context.Context, actorID uuid.UUID, action audit.Action, workspaceID uuid.UUID, before, after map[string]any) {
+	if s.audit == nil {
+		return
+	}
+	event := audit.Event{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: audit.TargetWorkspace,
+		TargetID:   workspaceID.String(),
+	}
+	// Assign only non-nil maps so absent states are stored as NULL, not "null"
+	if before != nil {
+		event.Before = before
+	}
+	if after != nil {
+		event.After = after
+	}
+	s.audit.Record(ctx, event)
+}
+
+func (s *Service) getWorkspace(ctx context.Context, workspaceID uuid.UUID) (*Workspace, error) {
+	ws, err := s.repo.GetByID(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if ws == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+	return ws, nil
+}
+
+// generateToken returns a random 32-byte hex token for invitation links
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,419 @@
+package workspace
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+type memberKey struct {
+	workspaceID uuid.UUID
+	userID      uuid.UUID
+}
+
+// mockRepo is an in-memory implementation of Repository for testing.
+type mockRepo struct {
+	workspaces  map[uuid.UUID]*Workspace
+	members     map[memberKey]*Member
+	invitations map[uuid.UUID]*Invitation
+
+	// acceptErr, when set, is returned by AcceptInvitation as if another
+	// request had claimed the invitation first
+	acceptErr error
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{
+		workspaces:  make(map[uuid.UUID]*Workspace),
+		members:     make(map[memberKey]*Member),
+		invitations: make(map[uuid.UUID]*Invitation),
+	}
+}
+
+func (r *mockRepo) Create(_ context.Context, ws *Workspace) error {
+	r.workspaces[ws.ID] = ws
+	r.members[memberKey{ws.ID, ws.OwnerID}] = &Member{WorkspaceID: ws.ID, UserID: ws.OwnerID, Role: RoleOwner}
+	return nil
+}
+
+func (r *mockRepo) GetByID(_ context.Context, id uuid.UUID) (*Workspace, error) {
+	ws, ok := r.workspaces[id]
+	if !ok {
+		return nil, nil
+	}
+	cp := *ws
+	return &cp, nil
+}
+
+func (r *mockRepo) GetPersonal(_ context.Context, userID uuid.UUID) (*Workspace, error) {
+	for _, ws := range r.workspaces {
+		if ws.OwnerID == userID && ws.IsPersonal {
+			return ws, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *mockRepo) ListByUser(_ context.Context, userID uuid.UUID) ([]*Workspace, error) {
+	var result []*Workspace
+	for key, m := range r.members {
+		if key.userID == userID {
+			cp := *r.workspaces[key.workspaceID]
+			cp.Role = m.Role
+			result = append(result, &cp)
+		}
+	}
+	return result, nil
+}
+
+func (r *mockRepo) GetMember(_ context.Context, workspaceID, userID uuid.UUID) (*Member, error) {
+	m, ok := r.members[memberKey{workspaceID, userID}]
+	if !ok {
+		return nil, nil
+	}
+	return m, nil
+}
+
+func (r *mockRepo) ListMembers(_ context.Context, workspaceID uuid.UUID) ([]*Member, error) {
+	var result []*Member
+	for key, m := range r.members {
+		if key.workspaceID == workspaceID {
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
+func (r *mockRepo) AddMember(_ context.Context, member *Member) error {
+	key := memberKey{member.WorkspaceID, member.UserID}
+	if _, ok := r.members[key]; ok {
+		return ErrAlreadyMember
+	}
+	r.members[key] = member
+	return nil
+}
+
+func (r *mockRepo) UpdateMemberRole(_ context.Context, workspaceID, userID uuid.UUID, role Role) error {
+	m, ok := r.members[memberKey{workspaceID, userID}]
+	if !ok {
+		return ErrMemberNotFound
+	}
+	m.Role = role
+	return nil
+}
+
+func (r *mockRepo) RemoveMember(_ context.Context, workspaceID, userID uuid.UUID) error {
+	key := memberKey{workspaceID, userID}
+	if _, ok := r.members[key]; !ok {
+		return ErrMemberNotFound
+	}
+	delete(r.members, key)
+	return nil
+}
+
+func (r *mockRepo) CreateInvitation(_ context.Context, inv *Invitation) error {
+	r.invitations[inv.ID] = inv
+	return nil
+}
+
+func (r *mockRepo) GetInvitationByToken(_ context.Context, token string) (*Invitation, error) {
+	for _, inv := range r.invitations {
+		if inv.Token == token {
+			return inv, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *mockRepo) ListPendingInvitations(_ context.Context, workspaceID uuid.UUID) ([]*Invitation, error) {
+	var result []*Invitation
+	for _, inv := range r.invitations {
+		if inv.WorkspaceID == workspaceID && inv.IsPending(time.Now().UTC()) {
+			result = append(result, inv)
+		}
+	}
+	return result, nil
+}
+
+func (r *mockRepo) AcceptInvitation(_ context.Context, id uuid.UUID, member *Member) error {
+	if r.acceptErr != nil {
+		return r.acceptErr
+	}
+	inv, ok := r.invitations[id]
+	if !ok || !inv.IsPending(time.Now().UTC()) {
+		return ErrInvitationExpired
+	}
+	key := memberKey{member.WorkspaceID, member.UserID}
+	if _, ok := r.members[key]; ok {
+		return ErrAlreadyMember
+	}
+	now := time.Now().UTC()
+	inv.AcceptedAt = &now
+	inv.AcceptedBy = &member.UserID
+	r.members[key] = member
+	return nil
+}
+
+// mockSender records sent invitations.
+type mockSender struct {
+	sent []*Invitation
+}
+
+func (s *mockSender) SendInvitation(_ context.Context, inv *Invitation, _ *Workspace) error {
+	s.sent = append(s.sent, inv)
+	return nil
+}
+
+// mockRecorder collects audit events.
+type mockRecorder struct {
+	events []audit.Event
+}
+
+func (r *mockRecorder) Record(_ context.Context, event audit.Event) {
+	r.events = append(r.events, event)
+}
+
+func newTestService() (*Service, *mockRepo, *mockSender) {
+	repo := newMockRepo()
+	sender := &mockSender{}
+	log := logger.New("test", io.Discard)
+	return NewService(repo, sender, nil, log), repo, sender
+}
+
+func TestRole_Allows(t *testing.T) {
+	assert.True(t, RoleOwner.Allows(RoleOwner))
+	assert.True(t, RoleOwner.Allows(RoleViewer))
+	assert.True(t, RoleEditor.Allows(RoleEditor))
+	assert.True(t, RoleEditor.Allows(RoleViewer))
+	assert.False(t, RoleEditor.Allows(RoleOwner))
+	assert.True(t, RoleViewer.Allows(RoleViewer))
+	assert.False(t, RoleViewer.Allows(RoleEditor))
+	assert.False(t, Role("admin").Allows(RoleViewer))
+}
+
+func TestService_Create_OwnerMembership(t *testing.T) {
+	svc, repo, _ := newTestService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+
+	ws, err := svc.Create(ctx, ownerID, "  Family  ")
+	require.NoError(t, err)
+	assert.Equal(t, "Family", ws.Name)
+	assert.Equal(t, RoleOwner, ws.Role)
+
+	m, _ := repo.GetMember(ctx, ws.ID, ownerID)
+	require.NotNil(t, m)
+	assert.Equal(t, RoleOwner, m.Role)
+}
+
+func TestService_Create_EmptyName(t *testing.T) {
+	svc, _, _ := newTestService()
+
+	_, err := svc.Create(context.Background(), uuid.New(), " ")
+	assert.ErrorIs(t, err, ErrMissingName)
+}
+
+func TestService_Authorize(t *testing.T) {
+	svc, repo, _ := newTestService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+	viewerID := uuid.New()
+
+	ws, err := svc.Create(ctx, ownerID, "Shared")
+	require.NoError(t, err)
+	require.NoError(t, repo.AddMember(ctx, &Member{WorkspaceID: ws.ID, UserID: viewerID, Role: RoleViewer}))
+
+	assert.NoError(t, svc.Authorize(ctx, ws.ID, ownerID, RoleOwner))
+	assert.NoError(t, svc.Authorize(ctx, ws.ID, viewerID, RoleViewer))
+	assert.ErrorIs(t, svc.Authorize(ctx, ws.ID, viewerID, RoleEditor), ErrInsufficientRole)
+	assert.ErrorIs(t, svc.Authorize(ctx, ws.ID, uuid.New(), RoleViewer), ErrNotMember)
+}
+
+func TestService_PersonalWorkspace_CreatedOnce(t *testing.T) {
+	svc, _, _ := newTestService()
+	ctx := context.Background()
+	userID := uuid.New()
+
+	first, err := svc.PersonalWorkspace(ctx, userID)
+	require.NoError(t, err)
+	assert.True(t, first.IsPersonal)
+
+	second, err := svc.PersonalWorkspace(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+}
+
+func TestService_InviteAndAccept(t *testing.T) {
+	svc, _, sender := newTestService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+	inviteeID := uuid.New()
+
+	ws, err := svc.Create(ctx, ownerID, "Shared")
+	require.NoError(t, err)
+
+	inv, err := svc.Invite(ctx, ws.ID, ownerID, "Friend@Example.com", RoleEditor)
+	require.NoError(t, err)
+	assert.Equal(t, "friend@example.com", inv.Email)
+	assert.Len(t, inv.Token, 64)
+	require.Len(t, sender.sent, 1)
+
+	// Wrong account cannot accept
+	_, err = svc.AcceptInvitation(ctx, inv.Token, uuid.New(), "other@example.com")
+	assert.ErrorIs(t, err, ErrInvitationEmail)
+
+	joined, err := svc.AcceptInvitation(ctx, inv.Token, inviteeID, "friend@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, ws.ID, joined.ID)
+	assert.Equal(t, RoleEditor, joined.Role)
+	assert.NoError(t, svc.Authorize(ctx, ws.ID, inviteeID, RoleEditor))
+
+	// Invitations are single-use
+	_, err = svc.AcceptInvitation(ctx, inv.Token, inviteeID, "friend@example.com")
+	assert.ErrorIs(t, err, ErrInvitationExpired)
+}
+
+func TestService_AcceptInvitation_LostRaceAddsNoMember(t *testing.T) {
+	svc, repo, _ := newTestService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+	inviteeID := uuid.New()
+
+	ws, err := svc.Create(ctx, ownerID, "Shared")
+	require.NoError(t, err)
+	inv, err := svc.Invite(ctx, ws.ID, ownerID, "friend@example.com", RoleViewer)
+	require.NoError(t, err)
+
+	repo.acceptErr = ErrInvitationExpired
+	_, err = svc.AcceptInvitation(ctx, inv.Token, inviteeID, "friend@example.com")
+	assert.ErrorIs(t, err, ErrInvitationExpired)
+	assert.ErrorIs(t, svc.Authorize(ctx, ws.ID, inviteeID, RoleViewer), ErrNotMember)
+}
+
+func TestService_Invite_Restrictions(t *testing.T) {
+	svc, repo, _ := newTestService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+	editorID := uuid.New()
+
+	ws, err := svc.Create(ctx, ownerID, "Shared")
+	require.NoError(t, err)
+	require.NoError(t, repo.AddMember(ctx, &Member{WorkspaceID: ws.ID, UserID: editorID, Role: RoleEditor}))
+
+	_, err = svc.Invite(ctx, ws.ID, editorID, "a@example.com", RoleViewer)
+	assert.ErrorIs(t, err, ErrInsufficientRole)
+
+	_, err = svc.Invite(ctx, ws.ID, ownerID, "a@example.com", RoleOwner)
+	assert.ErrorIs(t, err, ErrInvalidRole)
+
+	_, err = svc.Invite(ctx, ws.ID, ownerID, "not-an-email", RoleViewer)
+	assert.ErrorIs(t, err, ErrInvalidEmail)
+
+	personal, err := svc.PersonalWorkspace(ctx, ownerID)
+	require.NoError(t, err)
+	_, err = svc.Invite(ctx, personal.ID, ownerID, "a@example.com", RoleViewer)
+	assert.ErrorIs(t, err, ErrPersonalWorkspace)
+}
+
+func TestService_AcceptInvitation_Expired(t *testing.T) {
+	svc, repo, _ := newTestService()
+	ctx := context.Background()
+
+	inv := &Invitation{
+		ID:          uuid.New(),
+		WorkspaceID: uuid.New(),
+		Email:       "late@example.com",
+		Role:        RoleViewer,
+		Token:       "expired-token",
+		ExpiresAt:   time.Now().UTC().Add(-time.Hour),
+	}
+	require.NoError(t, repo.CreateInvitation(ctx, inv))
+
+	_, err := svc.AcceptInvitation(ctx, "expired-token", uuid.New(), "late@example.com")
+	assert.ErrorIs(t, err, ErrInvitationExpired)
+}
+
+func TestService_UpdateMemberRole(t *testing.T) {
+	svc, repo, _ := newTestService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+	memberID := uuid.New()
+
+	ws, err := svc.Create(ctx, ownerID, "Shared")
+	require.NoError(t, err)
+	require.NoError(t, repo.AddMember(ctx, &Member{WorkspaceID: ws.ID, UserID: memberID, Role: RoleViewer}))
+
+	require.NoError(t, svc.UpdateMemberRole(ctx, ws.ID, ownerID, memberID, RoleEditor))
+	assert.NoError(t, svc.Authorize(ctx, ws.ID, memberID, RoleEditor))
+
+	assert.ErrorIs(t, svc.UpdateMemberRole(ctx, ws.ID, ownerID, ownerID, RoleViewer), ErrOwnerImmutable)
+	assert.ErrorIs(t, svc.UpdateMemberRole(ctx, ws.ID, memberID, memberID, RoleViewer), ErrInsufficientRole)
+}
+
+func TestService_RemoveMember(t *testing.T) {
+	svc, repo, _ := newTestService()
+	ctx := context.Background()
+	ownerID := uuid.New()
+	aliceID := uuid.New()
+	bobID := uuid.New()
+
+	ws, err := svc.Create(ctx, ownerID, "Shared")
+	require.NoError(t, err)
+	require.NoError(t, repo.AddMember(ctx, &Member{WorkspaceID: ws.ID, UserID: aliceID, Role: RoleEditor}))
+	require.NoError(t, repo.AddMember(ctx, &Member{WorkspaceID: ws.ID, UserID: bobID, Role: RoleViewer}))
+
+	// Non-owners cannot remove others
+	assert.ErrorIs(t, svc.RemoveMember(ctx, ws.ID, aliceID, bobID), ErrInsufficientRole)
+
+	// Members can leave
+	require.NoError(t, svc.RemoveMember(ctx, ws.ID, bobID, bobID))
+	assert.ErrorIs(t, svc.Authorize(ctx, ws.ID, bobID, RoleViewer), ErrNotMember)
+
+	// Owner can remove others but not themselves
+	require.NoError(t, svc.RemoveMember(ctx, ws.ID, ownerID, aliceID))
+	assert.ErrorIs(t, svc.RemoveMember(ctx, ws.ID, ownerID, ownerID), ErrOwnerImmutable)
+}
+
+func TestService_MembershipChangesAreAudited(t *testing.T) {
+	repo := newMockRepo()
+	recorder := &mockRecorder{}
+	svc := NewService(repo, &mockSender{}, recorder, logger.New("test", io.Discard))
+	ctx := context.Background()
+	ownerID := uuid.New()
+	memberID := uuid.New()
+
+	ws, err := svc.Create(ctx, ownerID, "Shared")
+	require.NoError(t, err)
+	inv, err := svc.Invite(ctx, ws.ID, ownerID, "member@example.com", RoleViewer)
+	require.NoError(t, err)
+	_, err = svc.AcceptInvitation(ctx, inv.Token, memberID, "member@example.com")
+	require.NoError(t, err)
+	require.NoError(t, svc.UpdateMemberRole(ctx, ws.ID, ownerID, memberID, RoleEditor))
+	require.NoError(t, svc.RemoveMember(ctx, ws.ID, ownerID, memberID))
+
+	require.Len(t, recorder.events, 4)
+	actions := make([]audit.Action, 0, len(recorder.events))
+	for _, e := range recorder.events {
+		assert.Equal(t, audit.TargetWorkspace, e.TargetType)
+		assert.Equal(t, ws.ID.String(), e.TargetID)
+		actions = append(actions, e.Action)
+	}
+	assert.Equal(t, []audit.Action{
+		audit.ActionWorkspaceInvite, audit.ActionWorkspaceJoin, audit.ActionMemberRoleUpdate, audit.ActionMemberRemove,
+	}, actions)
+
+	assert.Equal(t, memberID, recorder.events[1].ActorID)
+	roleChange := recorder.events[2]
+	assert.Equal(t, ownerID, roleChange.ActorID)
+	assert.Equal(t, map[string]any{"user_id": memberID, "role": RoleViewer}, roleChange.Before)
+	assert.Equal(t, map[string]any{"user_id": memberID, "role": RoleEditor}, roleChange.After)
+	assert.Nil(t, recorder.events[3].After)
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
+	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+)
+
+// AuditServiceInterface defines audit log read operations for the HTTP handler
+type AuditServiceInterface interface {
+	List(ctx context.Context, actorID uuid.UUID, filter audit.Filter) ([]*audit.Entry, int, error)
+}
+
+// AuditHandler serves the authenticated user's audit trail
+type AuditHandler struct {
+	svc AuditServiceInterface
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(svc AuditServiceInterface) *AuditHandler {
+	return &AuditHandler{svc: svc}
+}
+
+// AuditEntryResponse represents an audit entry in the API response
+type AuditEntryResponse struct {
+	ID         string          `json:"id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	IP         string          `json:"ip,omitempty"`
+	UserAgent  string          `json:"user_agent,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	CreatedAt  string          `json:"created_at"`
+}
+
+// AuditListResponse represents a paginated list of audit entries
+type AuditListResponse struct {
+	Entries  []AuditEntryResponse `json:"entries"`
+	Total    int                  `json:"total"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"page_size"`
+}
+
+// ListAuditLog handles GET /audit-log
+// Query params: action, target_type, target_id, start_date, end_date (RFC3339), page, page_size
+func (h *AuditHandler) ListAuditLog(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	query := r.URL.Query()
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(query.Get("page_size"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 50
+	}
+
+	filter := audit.Filter{
+		Action:     audit.Action(query.Get("action")),
+		TargetType: query.Get("target_type"),
+		TargetID:   query.Get("target_id"),
+		Limit:      pageSize,
+		Offset:     (page - 1) * pageSize,
+	}
+
+	if v := query.Get("start_date"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid start_date format (use RFC3339)")
+			return
+		}
+		filter.From = &t
+	}
+	if v := query.Get("end_date"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid end_date format (use RFC3339)")
+			return
+		}
+		filter.To = &t
+	}
+
+	entries, total, err := h.svc.List(r.Context(), userID, filter)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch audit log")
+		return
+	}
+
+	resp := AuditListResponse{
+		Entries:  make([]AuditEntryResponse, 0, len(entries)),
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, AuditEntryResponse{
+			ID:         e.ID.String(),
+			Action:     string(e.Action),
+			TargetType: e.TargetType,
+			TargetID:   e.TargetID,
+			Before:     e.Before,
+			After:      e.After,
+			IP:         e.IP,
+			UserAgent:  e.UserAgent,
+			RequestID:  e.RequestID,
+			CreatedAt:  e.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
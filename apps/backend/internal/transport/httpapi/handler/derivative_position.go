@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/derivativeposition"
+	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+)
+
+// DerivativePositionServiceInterface defines derivative position operations for the HTTP handler
+type DerivativePositionServiceInterface interface {
+	GetAccessible(ctx context.Context, userID, id uuid.UUID) (*derivativeposition.DerivativePosition, error)
+	ListAccessible(ctx context.Context, userID uuid.UUID, status *derivativeposition.Status, walletID *uuid.UUID, chainID *string) ([]*derivativeposition.DerivativePosition, error)
+}
+
+// DerivativePositionHandler handles derivative position HTTP requests
+type DerivativePositionHandler struct {
+	svc DerivativePositionServiceInterface
+}
+
+// NewDerivativePositionHandler creates a new derivative position handler
+func NewDerivativePositionHandler(svc DerivativePositionServiceInterface) *DerivativePositionHandler {
+	return &DerivativePositionHandler{svc: svc}
+}
+
+// DerivativePositionResponse represents a derivative position in the API response
+type DerivativePositionResponse struct {
+	ID       string `json:"id"`
+	WalletID string `json:"wallet_id"`
+	ChainID  string `json:"chain_id"`
+	Protocol string `json:"protocol"`
+	Market   string `json:"market,omitempty"`
+
+	Asset         string `json:"asset"`
+	AssetDecimals int    `json:"asset_decimals"`
+	AssetContract string `json:"asset_contract,omitempty"`
+	Margin        string `json:"margin"`
+
+	TotalDeposited    string `json:"total_deposited"`
+	TotalWithdrawn    string `json:"total_withdrawn"`
+	TotalDepositedUSD string `json:"total_deposited_usd"`
+	TotalWithdrawnUSD string `json:"total_withdrawn_usd"`
+
+	RealizedPnL    string `json:"realized_pnl"`
+	RealizedPnLUSD string `json:"realized_pnl_usd"`
+
+	FundingPaid        string `json:"funding_paid"`
+	FundingReceived    string `json:"funding_received"`
+	FundingPaidUSD     string `json:"funding_paid_usd"`
+	FundingReceivedUSD string `json:"funding_received_usd"`
+
+	NetPnLUSD string `json:"net_pnl_usd"`
+
+	Status   string  `json:"status"`
+	OpenedAt string  `json:"opened_at"`
+	ClosedAt *string `json:"closed_at,omitempty"`
+}
+
+// ListPositions handles GET /derivatives/positions
+func (h *DerivativePositionHandler) ListPositions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var statusFilter *derivativeposition.Status
+	if s := r.URL.Query().Get("status"); s != "" {
+		st := derivativeposition.Status(s)
+		statusFilter = &st
+	}
+
+	var walletIDFilter *uuid.UUID
+	if wid := r.URL.Query().Get("wallet_id"); wid != "" {
+		parsed, err := uuid.Parse(wid)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid wallet_id")
+			return
+		}
+		walletIDFilter = &parsed
+	}
+
+	var chainIDFilter *string
+	if cid := r.URL.Query().Get("chain_id"); cid != "" {
+		chainIDFilter = &cid
+	}
+
+	positions, err := h.svc.ListAccessible(r.Context(), userID, statusFilter, walletIDFilter, chainIDFilter)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to list derivative positions")
+		return
+	}
+
+	response := make([]DerivativePositionResponse, len(positions))
+	for i, pos := range positions {
+		response[i] = toDerivativePositionResponse(pos)
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// GetPosition handles GET /derivatives/positions/{id}
+func (h *DerivativePositionHandler) GetPosition(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	posID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid position ID")
+		return
+	}
+
+	pos, err := h.svc.GetAccessible(r.Context(), userID, posID)
+	if err != nil {
+		if errors.Is(err, derivativeposition.ErrPositionNotFound) {
+			respondWithError(w, http.StatusNotFound, "derivative position not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "failed to get derivative position")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toDerivativePositionResponse(pos))
+}
+
+func toDerivativePositionResponse(pos *derivativeposition.DerivativePosition) DerivativePositionResponse {
+	resp := DerivativePositionResponse{
+		ID:       pos.ID.String(),
+		WalletID: pos.WalletID.String(),
+		ChainID:  pos.ChainID,
+		Protocol: pos.Protocol,
+		Market:   pos.Market,
+
+		Asset:         pos.Asset,
+		AssetDecimals: pos.AssetDecimals,
+		AssetContract: pos.AssetContract,
+		Margin:        bigIntStr(pos.Margin),
+
+		TotalDeposited:    bigIntStr(pos.TotalDeposited),
+		TotalWithdrawn:    bigIntStr(pos.TotalWithdrawn),
+		TotalDepositedUSD: bigIntStr(pos.TotalDepositedUSD),
+		TotalWithdrawnUSD: bigIntStr(pos.TotalWithdrawnUSD),
+
+		RealizedPnL:    bigIntStr(pos.RealizedPnL),
+		RealizedPnLUSD: bigIntStr(pos.RealizedPnLUSD),
+
+		FundingPaid:        bigIntStr(pos.FundingPaid),
+		FundingReceived:    bigIntStr(pos.FundingReceived),
+		FundingPaidUSD:     bigIntStr(pos.FundingPaidUSD),
+		FundingReceivedUSD: bigIntStr(pos.FundingReceivedUSD),
+
+		NetPnLUSD: bigIntStr(pos.NetPnLUSD()),
+
+		Status:   string(pos.Status),
+		OpenedAt: pos.OpenedAt.Format(time.RFC3339),
+	}
+
+	if pos.ClosedAt != nil {
+		s := pos.ClosedAt.Format(time.RFC3339)
+		resp.ClosedAt = &s
+	}
+
+	return resp
+}
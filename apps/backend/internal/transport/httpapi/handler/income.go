@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/module/income"
+	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// IncomeServiceInterface defines income operations for the HTTP handler
+type IncomeServiceInterface interface {
+	Classify(ctx context.Context, userID, txID uuid.UUID, category income.Category) (*income.Classification, error)
+	Unclassify(ctx context.Context, userID, txID uuid.UUID) error
+	ListEvents(ctx context.Context, userID uuid.UUID, year int, category *income.Category) ([]*income.Event, error)
+	GetReport(ctx context.Context, userID uuid.UUID, year int) (*income.Report, error)
+}
+
+// IncomeHandler handles income classification and the annual income report
+type IncomeHandler struct {
+	svc      IncomeServiceInterface
+	resolver *money.DecimalResolver
+}
+
+// NewIncomeHandler creates a new income handler
+func NewIncomeHandler(svc IncomeServiceInterface, resolver *money.DecimalResolver) *IncomeHandler {
+	return &IncomeHandler{svc: svc, resolver: resolver}
+}
+
+// ClassifyIncomeRequest represents the income classification request
+type ClassifyIncomeRequest struct {
+	Category string `json:"category"`
+}
+
+// IncomeClassificationResponse represents a transaction's income classification
+type IncomeClassificationResponse struct {
+	TransactionID string `json:"transaction_id"`
+	Category      string `json:"category"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+// IncomeEventResponse represents one asset received as income
+type IncomeEventResponse struct {
+	TransactionID   string `json:"transaction_id"`
+	TransactionType string `json:"transaction_type"`
+	WalletID        string `json:"wallet_id"`
+	OccurredAt      string `json:"occurred_at"`
+	Category        string `json:"category"`
+	Source          string `json:"source"` // "default" or "user"
+	AssetID         string `json:"asset_id"`
+	Amount          string `json:"amount"`
+	USDValue        string `json:"usd_value"` // Fair market value at receipt
+}
+
+// IncomeAssetResponse represents the income received in one asset
+type IncomeAssetResponse struct {
+	AssetID  string `json:"asset_id"`
+	Amount   string `json:"amount"`
+	USDValue string `json:"usd_value"`
+	Count    int    `json:"count"`
+}
+
+// IncomeCategoryResponse represents the income of one category
+type IncomeCategoryResponse struct {
+	Category string                `json:"category"`
+	USDValue string                `json:"usd_value"`
+	Assets   []IncomeAssetResponse `json:"assets"`
+}
+
+// IncomeReportResponse represents the annual income report
+type IncomeReportResponse struct {
+	Year       int                      `json:"year"`
+	From       string                   `json:"from"`
+	To         string                   `json:"to"`
+	TotalUSD   string                   `json:"total_usd"`
+	Categories []IncomeCategoryResponse `json:"categories"`
+}
+
+// ClassifyIncome handles PUT /transactions/{id}/income
+func (h *IncomeHandler) ClassifyIncome(w http.ResponseWriter, r *http.Request) {
+	userID, txID, ok := h.transactionParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req ClassifyIncomeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	c, err := h.svc.Classify(r.Context(), userID, txID, income.Category(req.Category))
+	if err != nil {
+		respondIncomeError(w, err, "failed to classify transaction")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, IncomeClassificationResponse{
+		TransactionID: c.TransactionID.String(),
+		Category:      string(c.Category),
+		UpdatedAt:     c.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+// UnclassifyIncome handles DELETE /transactions/{id}/income
+func (h *IncomeHandler) UnclassifyIncome(w http.ResponseWriter, r *http.Request) {
+	userID, txID, ok := h.transactionParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Unclassify(r.Context(), userID, txID); err != nil {
+		respondIncomeError(w, err, "failed to remove classification")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetIncomeEvents handles GET /income/events?year=&category=
+func (h *IncomeHandler) GetIncomeEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	year, ok := parseReportYear(w, r)
+	if !ok {
+		return
+	}
+	var category *income.Category
+	if c := r.URL.Query().Get("category"); c != "" {
+		parsed := income.Category(c)
+		category = &parsed
+	}
+
+	events, err := h.svc.ListEvents(r.Context(), userID, year, category)
+	if err != nil {
+		respondIncomeError(w, err, "failed to fetch income events")
+		return
+	}
+
+	resp := make([]IncomeEventResponse, 0, len(events))
+	for _, e := range events {
+		resp = append(resp, IncomeEventResponse{
+			TransactionID:   e.TransactionID.String(),
+			TransactionType: e.TransactionType.String(),
+			WalletID:        e.WalletID.String(),
+			OccurredAt:      e.OccurredAt.Format(time.RFC3339),
+			Category:        string(e.Category),
+			Source:          string(e.Source),
+			AssetID:         e.AssetID,
+			Amount:          money.FromBaseUnits(e.Amount, h.resolveDecimals(r.Context(), e.AssetID)),
+			USDValue:        money.FormatUSD(e.USDValue),
+		})
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// GetIncomeReport handles GET /income/report?year=
+func (h *IncomeHandler) GetIncomeReport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	year, ok := parseReportYear(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.svc.GetReport(r.Context(), userID, year)
+	if err != nil {
+		respondIncomeError(w, err, "failed to build income report")
+		return
+	}
+
+	resp := IncomeReportResponse{
+		Year:       report.Year,
+		From:       report.From.Format(time.RFC3339),
+		To:         report.To.Format(time.RFC3339),
+		TotalUSD:   money.FormatUSD(report.TotalUSD),
+		Categories: make([]IncomeCategoryResponse, 0, len(report.Categories)),
+	}
+	for _, c := range report.Categories {
+		cr := IncomeCategoryResponse{
+			Category: string(c.Category),
+			USDValue: money.FormatUSD(c.USDValue),
+			Assets:   make([]IncomeAssetResponse, 0, len(c.Assets)),
+		}
+		for _, a := range c.Assets {
+			cr.Assets = append(cr.Assets, IncomeAssetResponse{
+				AssetID:  a.AssetID,
+				Amount:   money.FromBaseUnits(a.Amount, h.resolveDecimals(r.Context(), a.AssetID)),
+				USDValue: money.FormatUSD(a.USDValue),
+				Count:    a.Count,
+			})
+		}
+		resp.Categories = append(resp.Categories, cr)
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (h *IncomeHandler) transactionParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	txID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid transaction ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, txID, true
+}
+
+// resolveDecimals uses the resolver if available, otherwise falls back to the hardcoded map.
+func (h *IncomeHandler) resolveDecimals(ctx context.Context, symbol string) int {
+	if h.resolver != nil {
+		return h.resolver.ResolveSymbolOnly(ctx, symbol)
+	}
+	return money.GetDecimals(symbol)
+}
+
+// parseReportYear reads the year query parameter, defaulting to the current UTC year
+func parseReportYear(w http.ResponseWriter, r *http.Request) (int, bool) {
+	raw := r.URL.Query().Get("year")
+	if raw == "" {
+		return time.Now().UTC().Year(), true
+	}
+	year, err := strconv.Atoi(raw)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, income.ErrInvalidYear.Error())
+		return 0, false
+	}
+	return year, true
+}
+
+func respondIncomeError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, income.ErrTransactionNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, income.ErrAccessDenied):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, income.ErrNotClassifiable),
+		errors.Is(err, income.ErrInvalidCategory),
+		errors.Is(err, income.ErrInvalidYear):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, fallback)
+	}
+}
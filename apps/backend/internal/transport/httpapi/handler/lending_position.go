@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
@@ -10,12 +11,13 @@ import (
 
 	"github.com/kislikjeka/moontrack/internal/platform/lendingposition"
 	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+	"github.com/kislikjeka/moontrack/pkg/money"
 )
 
 // LendingPositionServiceInterface defines lending position operations for the HTTP handler
 type LendingPositionServiceInterface interface {
-	GetByID(ctx context.Context, id uuid.UUID) (*lendingposition.LendingPosition, error)
-	ListByUser(ctx context.Context, userID uuid.UUID, status *lendingposition.Status, walletID *uuid.UUID, chainID *string) ([]*lendingposition.LendingPosition, error)
+	GetAccessible(ctx context.Context, userID, id uuid.UUID) (*lendingposition.LendingPosition, error)
+	ListAccessible(ctx context.Context, userID uuid.UUID, status *lendingposition.Status, walletID *uuid.UUID, chainID *string) ([]*lendingposition.LendingPosition, error)
 }
 
 // LendingPositionHandler handles lending position HTTP requests
@@ -35,20 +37,8 @@ type LendingPositionResponse struct {
 	ChainID  string `json:"chain_id"`
 	Protocol string `json:"protocol"`
 
-	SupplyAsset    string `json:"supply_asset"`
-	SupplyAmount   string `json:"supply_amount"`
-	SupplyDecimals int    `json:"supply_decimals"`
-	SupplyContract string `json:"supply_contract,omitempty"`
-
-	BorrowAsset    string `json:"borrow_asset,omitempty"`
-	BorrowAmount   string `json:"borrow_amount"`
-	BorrowDecimals int    `json:"borrow_decimals,omitempty"`
-	BorrowContract string `json:"borrow_contract,omitempty"`
-
-	TotalSupplied  string `json:"total_supplied"`
-	TotalWithdrawn string `json:"total_withdrawn"`
-	TotalBorrowed  string `json:"total_borrowed"`
-	TotalRepaid    string `json:"total_repaid"`
+	// One leg per supplied (collateral) or borrowed (debt) asset
+	Legs []LendingLegResponse `json:"legs"`
 
 	TotalSuppliedUSD  string `json:"total_supplied_usd"`
 	TotalWithdrawnUSD string `json:"total_withdrawn_usd"`
@@ -56,10 +46,49 @@ type LendingPositionResponse struct {
 	TotalRepaidUSD    string `json:"total_repaid_usd"`
 
 	InterestEarnedUSD string `json:"interest_earned_usd"`
+	InterestPaidUSD   string `json:"interest_paid_usd"`
+
+	// Collateral seized by liquidators and the penalty paid on it
+	TotalLiquidatedUSD    string  `json:"total_liquidated_usd"`
+	LiquidationPenaltyUSD string  `json:"liquidation_penalty_usd"`
+	LastLiquidatedAt      *string `json:"last_liquidated_at,omitempty"`
 
 	Status   string  `json:"status"`
 	OpenedAt string  `json:"opened_at"`
 	ClosedAt *string `json:"closed_at,omitempty"`
+
+	// Liquidation risk at current prices, refreshed on each sync. The health
+	// factor is a decimal string (e.g. "1.85"); below 1 the position can be
+	// liquidated.
+	SupplyValueUSD          *string `json:"supply_value_usd,omitempty"`
+	BorrowValueUSD          *string `json:"borrow_value_usd,omitempty"`
+	LiquidationThresholdBps *int    `json:"liquidation_threshold_bps,omitempty"`
+	LTVBps                  *int    `json:"ltv_bps,omitempty"`
+	HealthFactor            *string `json:"health_factor,omitempty"`
+	RiskUpdatedAt           *string `json:"risk_updated_at,omitempty"`
+}
+
+// LendingLegResponse represents one asset of a lending position
+type LendingLegResponse struct {
+	ID       string `json:"id"`
+	Side     string `json:"side"`
+	Asset    string `json:"asset"`
+	Decimals int    `json:"decimals"`
+	Contract string `json:"contract,omitempty"`
+
+	Amount      string `json:"amount"`
+	TotalIn     string `json:"total_in"`
+	TotalOut    string `json:"total_out"`
+	TotalInUSD  string `json:"total_in_usd"`
+	TotalOutUSD string `json:"total_out_usd"`
+	Interest    string `json:"interest"`
+	InterestUSD string `json:"interest_usd"`
+
+	// Market value at the last refresh. The liquidation price is set on
+	// supply legs whose price drop alone could liquidate the position.
+	USDPrice            *string `json:"usd_price,omitempty"`
+	ValueUSD            *string `json:"value_usd,omitempty"`
+	LiquidationPriceUSD *string `json:"liquidation_price_usd,omitempty"`
 }
 
 // ListPositions handles GET /lending/positions
@@ -91,7 +120,7 @@ func (h *LendingPositionHandler) ListPositions(w http.ResponseWriter, r *http.Re
 		chainIDFilter = &cid
 	}
 
-	positions, err := h.svc.ListByUser(r.Context(), userID, statusFilter, walletIDFilter, chainIDFilter)
+	positions, err := h.svc.ListAccessible(r.Context(), userID, statusFilter, walletIDFilter, chainIDFilter)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "failed to list lending positions")
 		return
@@ -107,7 +136,7 @@ func (h *LendingPositionHandler) ListPositions(w http.ResponseWriter, r *http.Re
 
 // GetPosition handles GET /lending/positions/{id}
 func (h *LendingPositionHandler) GetPosition(w http.ResponseWriter, r *http.Request) {
-	_, ok := middleware.GetUserIDFromContext(r.Context())
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
 		respondWithError(w, http.StatusUnauthorized, "unauthorized")
 		return
@@ -120,15 +149,15 @@ func (h *LendingPositionHandler) GetPosition(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	pos, err := h.svc.GetByID(r.Context(), posID)
+	pos, err := h.svc.GetAccessible(r.Context(), userID, posID)
 	if err != nil {
+		if errors.Is(err, lendingposition.ErrPositionNotFound) {
+			respondWithError(w, http.StatusNotFound, "lending position not found")
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "failed to get lending position")
 		return
 	}
-	if pos == nil {
-		respondWithError(w, http.StatusNotFound, "lending position not found")
-		return
-	}
 
 	respondWithJSON(w, http.StatusOK, toLendingPositionResponse(pos))
 }
@@ -140,20 +169,7 @@ func toLendingPositionResponse(pos *lendingposition.LendingPosition) LendingPosi
 		ChainID:  pos.ChainID,
 		Protocol: pos.Protocol,
 
-		SupplyAsset:    pos.SupplyAsset,
-		SupplyAmount:   bigIntStr(pos.SupplyAmount),
-		SupplyDecimals: pos.SupplyDecimals,
-		SupplyContract: pos.SupplyContract,
-
-		BorrowAsset:    pos.BorrowAsset,
-		BorrowAmount:   bigIntStr(pos.BorrowAmount),
-		BorrowDecimals: pos.BorrowDecimals,
-		BorrowContract: pos.BorrowContract,
-
-		TotalSupplied:  bigIntStr(pos.TotalSupplied),
-		TotalWithdrawn: bigIntStr(pos.TotalWithdrawn),
-		TotalBorrowed:  bigIntStr(pos.TotalBorrowed),
-		TotalRepaid:    bigIntStr(pos.TotalRepaid),
+		Legs: make([]LendingLegResponse, len(pos.Legs)),
 
 		TotalSuppliedUSD:  bigIntStr(pos.TotalSuppliedUSD),
 		TotalWithdrawnUSD: bigIntStr(pos.TotalWithdrawnUSD),
@@ -161,15 +177,79 @@ func toLendingPositionResponse(pos *lendingposition.LendingPosition) LendingPosi
 		TotalRepaidUSD:    bigIntStr(pos.TotalRepaidUSD),
 
 		InterestEarnedUSD: bigIntStr(pos.InterestEarnedUSD),
+		InterestPaidUSD:   bigIntStr(pos.InterestPaidUSD),
+
+		TotalLiquidatedUSD:    bigIntStr(pos.TotalLiquidatedUSD),
+		LiquidationPenaltyUSD: bigIntStr(pos.LiquidationPenaltyUSD),
 
 		Status:   string(pos.Status),
 		OpenedAt: pos.OpenedAt.Format(time.RFC3339),
+
+		LiquidationThresholdBps: pos.LiquidationThresholdBps,
+		LTVBps:                  pos.LTVBps,
 	}
 
 	if pos.ClosedAt != nil {
 		s := pos.ClosedAt.Format(time.RFC3339)
 		resp.ClosedAt = &s
 	}
+	if pos.LastLiquidatedAt != nil {
+		s := pos.LastLiquidatedAt.Format(time.RFC3339)
+		resp.LastLiquidatedAt = &s
+	}
+	if pos.SupplyValueUSD != nil {
+		s := pos.SupplyValueUSD.String()
+		resp.SupplyValueUSD = &s
+	}
+	if pos.BorrowValueUSD != nil {
+		s := pos.BorrowValueUSD.String()
+		resp.BorrowValueUSD = &s
+	}
+	if pos.HealthFactor != nil {
+		s := money.FromBaseUnits(pos.HealthFactor, 4)
+		resp.HealthFactor = &s
+	}
+	if pos.RiskUpdatedAt != nil {
+		s := pos.RiskUpdatedAt.Format(time.RFC3339)
+		resp.RiskUpdatedAt = &s
+	}
+
+	for i, l := range pos.Legs {
+		resp.Legs[i] = toLendingLegResponse(l)
+	}
+
+	return resp
+}
+
+func toLendingLegResponse(l *lendingposition.Leg) LendingLegResponse {
+	resp := LendingLegResponse{
+		ID:       l.ID.String(),
+		Side:     string(l.Side),
+		Asset:    l.Asset,
+		Decimals: l.Decimals,
+		Contract: l.Contract,
+
+		Amount:      bigIntStr(l.Amount),
+		TotalIn:     bigIntStr(l.TotalIn),
+		TotalOut:    bigIntStr(l.TotalOut),
+		TotalInUSD:  bigIntStr(l.TotalInUSD),
+		TotalOutUSD: bigIntStr(l.TotalOutUSD),
+		Interest:    bigIntStr(l.Interest),
+		InterestUSD: bigIntStr(l.InterestUSD),
+	}
+
+	if l.USDPrice != nil {
+		s := l.USDPrice.String()
+		resp.USDPrice = &s
+	}
+	if l.ValueUSD != nil {
+		s := l.ValueUSD.String()
+		resp.ValueUSD = &s
+	}
+	if l.LiquidationPriceUSD != nil {
+		s := l.LiquidationPriceUSD.String()
+		resp.LiquidationPriceUSD = &s
+	}
 
 	return resp
 }
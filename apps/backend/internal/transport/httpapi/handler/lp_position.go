@@ -2,6 +2,9 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"math/big"
 	"net/http"
@@ -16,18 +19,30 @@ import (
 
 // LPPositionServiceInterface defines LP position operations for the HTTP handler
 type LPPositionServiceInterface interface {
-	GetByID(ctx context.Context, id uuid.UUID) (*lpposition.LPPosition, error)
-	ListByUser(ctx context.Context, userID uuid.UUID, status *lpposition.Status, walletID *uuid.UUID, chainID *string) ([]*lpposition.LPPosition, error)
+	GetAccessible(ctx context.Context, userID, id uuid.UUID) (*lpposition.LPPosition, error)
+	ListAccessible(ctx context.Context, userID uuid.UUID, status *lpposition.Status, walletID *uuid.UUID, chainID *string) ([]*lpposition.LPPosition, error)
+}
+
+// LPSimulatorInterface defines concentrated-liquidity range simulation for the HTTP handler
+type LPSimulatorInterface interface {
+	SimulatePosition(ctx context.Context, userID, positionID uuid.UUID, req lpposition.SimulationRequest) (*lpposition.Simulation, error)
+}
+
+// LPPerformanceInterface defines impermanent loss analysis for the HTTP handler
+type LPPerformanceInterface interface {
+	GetPerformance(ctx context.Context, userID, positionID uuid.UUID) (*lpposition.Performance, error)
 }
 
 // LPPositionHandler handles LP position HTTP requests
 type LPPositionHandler struct {
-	svc LPPositionServiceInterface
+	svc         LPPositionServiceInterface
+	simulator   LPSimulatorInterface
+	performance LPPerformanceInterface
 }
 
 // NewLPPositionHandler creates a new LP position handler
-func NewLPPositionHandler(svc LPPositionServiceInterface) *LPPositionHandler {
-	return &LPPositionHandler{svc: svc}
+func NewLPPositionHandler(svc LPPositionServiceInterface, simulator LPSimulatorInterface, performance LPPerformanceInterface) *LPPositionHandler {
+	return &LPPositionHandler{svc: svc, simulator: simulator, performance: performance}
 }
 
 // LPPositionResponse represents a single LP position in the API response
@@ -66,6 +81,22 @@ type LPPositionResponse struct {
 
 	RealizedPnLUSD *string `json:"realized_pnl_usd,omitempty"`
 	APRBps         *int    `json:"apr_bps,omitempty"`
+
+	// Live metrics for open positions, refreshed on each sync
+	CurrentValueUSD  *string `json:"current_value_usd,omitempty"`
+	UnrealizedPnLUSD *string `json:"unrealized_pnl_usd,omitempty"`
+	FeeAPRBps        *int    `json:"fee_apr_bps,omitempty"`
+	TotalAPRBps      *int    `json:"total_apr_bps,omitempty"`
+	ValuedAt         *string `json:"valued_at,omitempty"`
+
+	// On-chain range state for NFT positions, refreshed on each sync
+	TickLower             *int    `json:"tick_lower,omitempty"`
+	TickUpper             *int    `json:"tick_upper,omitempty"`
+	CurrentTick           *int    `json:"current_tick,omitempty"`
+	InRange               *bool   `json:"in_range,omitempty"`
+	UncollectedFeesToken0 *string `json:"uncollected_fees_token0,omitempty"`
+	UncollectedFeesToken1 *string `json:"uncollected_fees_token1,omitempty"`
+	RangeUpdatedAt        *string `json:"range_updated_at,omitempty"`
 }
 
 // ListPositions handles GET /lp/positions
@@ -98,7 +129,7 @@ func (h *LPPositionHandler) ListPositions(w http.ResponseWriter, r *http.Request
 		chainIDFilter = &cid
 	}
 
-	positions, err := h.svc.ListByUser(r.Context(), userID, statusFilter, walletIDFilter, chainIDFilter)
+	positions, err := h.svc.ListAccessible(r.Context(), userID, statusFilter, walletIDFilter, chainIDFilter)
 	if err != nil {
 		slog.Error("failed to list LP positions", "error", err, "user_id", userID)
 		respondWithError(w, http.StatusInternalServerError, "failed to list LP positions")
@@ -115,7 +146,7 @@ func (h *LPPositionHandler) ListPositions(w http.ResponseWriter, r *http.Request
 
 // GetPosition handles GET /lp/positions/{id}
 func (h *LPPositionHandler) GetPosition(w http.ResponseWriter, r *http.Request) {
-	_, ok := middleware.GetUserIDFromContext(r.Context())
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
 		respondWithError(w, http.StatusUnauthorized, "unauthorized")
 		return
@@ -128,18 +159,288 @@ func (h *LPPositionHandler) GetPosition(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	pos, err := h.svc.GetByID(r.Context(), posID)
+	pos, err := h.svc.GetAccessible(r.Context(), userID, posID)
 	if err != nil {
+		if errors.Is(err, lpposition.ErrPositionNotFound) {
+			respondWithError(w, http.StatusNotFound, "LP position not found")
+			return
+		}
 		slog.Error("failed to get LP position", "error", err, "position_id", posID)
 		respondWithError(w, http.StatusInternalServerError, "failed to get LP position")
 		return
 	}
-	if pos == nil {
-		respondWithError(w, http.StatusNotFound, "LP position not found")
+
+	respondWithJSON(w, http.StatusOK, toLPPositionResponse(pos))
+}
+
+// LPPerformanceResponse compares an LP position with holding its deposits
+type LPPerformanceResponse struct {
+	PositionID  string `json:"position_id"`
+	ValuedAt    string `json:"valued_at"`
+	Token0Price string `json:"token0_price"`
+	Token1Price string `json:"token1_price"`
+
+	HODLValueUSD string `json:"hodl_value_usd"`
+	LPValueUSD   string `json:"lp_value_usd"`
+	Estimated    bool   `json:"estimated"`
+
+	ImpermanentLossUSD string `json:"impermanent_loss_usd"`
+	ImpermanentLossBps int    `json:"impermanent_loss_bps"`
+	FeesUSD            string `json:"fees_usd"`
+	NetUSD             string `json:"net_usd"`
+}
+
+// GetPerformance handles GET /lp/positions/{id}/performance
+func (h *LPPositionHandler) GetPerformance(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, toLPPositionResponse(pos))
+	posID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid position ID")
+		return
+	}
+
+	perf, err := h.performance.GetPerformance(r.Context(), userID, posID)
+	if err != nil {
+		switch {
+		case errors.Is(err, lpposition.ErrPositionNotFound):
+			respondWithError(w, http.StatusNotFound, "LP position not found")
+		case errors.Is(err, lpposition.ErrMissingPrices):
+			respondWithError(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			slog.Error("failed to get LP performance", "error", err, "position_id", posID)
+			respondWithError(w, http.StatusInternalServerError, "failed to get LP performance")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, LPPerformanceResponse{
+		PositionID:  posID.String(),
+		ValuedAt:    perf.ValuedAt.Format(time.RFC3339),
+		Token0Price: bigIntStr(perf.Token0Price),
+		Token1Price: bigIntStr(perf.Token1Price),
+
+		HODLValueUSD: bigIntStr(perf.HODLValueUSD),
+		LPValueUSD:   bigIntStr(perf.LPValueUSD),
+		Estimated:    perf.Estimated,
+
+		ImpermanentLossUSD: bigIntStr(perf.ImpermanentLossUSD),
+		ImpermanentLossBps: perf.ImpermanentLossBps,
+		FeesUSD:            bigIntStr(perf.FeesUSD),
+		NetUSD:             bigIntStr(perf.NetUSD),
+	})
+}
+
+// SimulateLPPositionRequest is the body of POST /lp/positions/{id}/simulate.
+// Prices are decimal strings quoted as token1 per token0, at most
+// lpposition.MaxPricePoints of them; liquidity and token1_usd_price (USD
+// scaled by 10^8) are base-unit integers.
+type SimulateLPPositionRequest struct {
+	TickLower  *int   `json:"tick_lower,omitempty"`
+	TickUpper  *int   `json:"tick_upper,omitempty"`
+	PriceLower string `json:"price_lower,omitempty"`
+	PriceUpper string `json:"price_upper,omitempty"`
+
+	Liquidity      string `json:"liquidity,omitempty"`
+	CurrentPrice   string `json:"current_price,omitempty"`
+	Token1USDPrice string `json:"token1_usd_price,omitempty"`
+
+	Prices     []string `json:"prices,omitempty"`
+	GridMin    string   `json:"grid_min,omitempty"`
+	GridMax    string   `json:"grid_max,omitempty"`
+	GridPoints int      `json:"grid_points,omitempty"`
+}
+
+// LPSimulationPointResponse is the position at a single price
+type LPSimulationPointResponse struct {
+	Price    string `json:"price"`
+	Tick     int    `json:"tick"`
+	Amount0  string `json:"amount0"`
+	Amount1  string `json:"amount1"`
+	ValueUSD string `json:"value_usd"`
+	InRange  bool   `json:"in_range"`
+}
+
+// LPSimulationResponse represents a range simulation in the API response
+type LPSimulationResponse struct {
+	PositionID     string `json:"position_id"`
+	Token0Symbol   string `json:"token0_symbol"`
+	Token1Symbol   string `json:"token1_symbol"`
+	Token0Decimals int    `json:"token0_decimals"`
+	Token1Decimals int    `json:"token1_decimals"`
+
+	TickLower  int    `json:"tick_lower"`
+	TickUpper  int    `json:"tick_upper"`
+	PriceLower string `json:"price_lower"`
+	PriceUpper string `json:"price_upper"`
+	Liquidity  string `json:"liquidity"`
+
+	Current    LPSimulationPointResponse   `json:"current"`
+	BelowRange LPSimulationPointResponse   `json:"below_range"`
+	AboveRange LPSimulationPointResponse   `json:"above_range"`
+	Points     []LPSimulationPointResponse `json:"points"`
+	Curve      []LPSimulationPointResponse `json:"curve"`
+}
+
+// SimulatePosition handles POST /lp/positions/{id}/simulate
+func (h *LPPositionHandler) SimulatePosition(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	posID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid position ID")
+		return
+	}
+
+	var body SimulateLPPositionRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if len(body.Prices) > lpposition.MaxPricePoints {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("too many prices: at most %d", lpposition.MaxPricePoints))
+		return
+	}
+
+	req, field, err := body.toSimulationRequest()
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid "+field)
+		return
+	}
+
+	sim, err := h.simulator.SimulatePosition(r.Context(), userID, posID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, lpposition.ErrPositionNotFound):
+			respondWithError(w, http.StatusNotFound, "LP position not found")
+		case errors.Is(err, lpposition.ErrRangeRequired),
+			errors.Is(err, lpposition.ErrInvalidRange),
+			errors.Is(err, lpposition.ErrTickOutOfRange),
+			errors.Is(err, lpposition.ErrPriceOutOfRange),
+			errors.Is(err, lpposition.ErrInvalidPrice),
+			errors.Is(err, lpposition.ErrPriceUnavailable),
+			errors.Is(err, lpposition.ErrNoLiquidity),
+			errors.Is(err, lpposition.ErrInvalidGrid),
+			errors.Is(err, lpposition.ErrTooManyPrices):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			slog.Error("failed to simulate LP position", "error", err, "position_id", posID)
+			respondWithError(w, http.StatusInternalServerError, "failed to simulate LP position")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toLPSimulationResponse(sim))
+}
+
+// toSimulationRequest parses the string fields, returning the offending field name on error
+func (b SimulateLPPositionRequest) toSimulationRequest() (lpposition.SimulationRequest, string, error) {
+	req := lpposition.SimulationRequest{
+		TickLower:  b.TickLower,
+		TickUpper:  b.TickUpper,
+		GridPoints: b.GridPoints,
+	}
+
+	prices := []struct {
+		field string
+		value string
+		dst   **big.Float
+	}{
+		{"price_lower", b.PriceLower, &req.PriceLower},
+		{"price_upper", b.PriceUpper, &req.PriceUpper},
+		{"current_price", b.CurrentPrice, &req.CurrentPrice},
+		{"grid_min", b.GridMin, &req.GridMin},
+		{"grid_max", b.GridMax, &req.GridMax},
+	}
+	for _, p := range prices {
+		if p.value == "" {
+			continue
+		}
+		v, err := lpposition.ParsePrice(p.value)
+		if err != nil {
+			return req, p.field, err
+		}
+		*p.dst = v
+	}
+
+	for _, s := range b.Prices {
+		v, err := lpposition.ParsePrice(s)
+		if err != nil {
+			return req, "prices", err
+		}
+		req.Prices = append(req.Prices, v)
+	}
+
+	ints := []struct {
+		field string
+		value string
+		dst   **big.Int
+	}{
+		{"liquidity", b.Liquidity, &req.Liquidity},
+		{"token1_usd_price", b.Token1USDPrice, &req.Token1USDRate},
+	}
+	for _, n := range ints {
+		if n.value == "" {
+			continue
+		}
+		v, ok := new(big.Int).SetString(n.value, 10)
+		if !ok || v.Sign() < 0 {
+			return req, n.field, errors.New("not a non-negative integer")
+		}
+		*n.dst = v
+	}
+
+	return req, "", nil
+}
+
+func toLPSimulationResponse(sim *lpposition.Simulation) LPSimulationResponse {
+	pos := sim.Position
+	resp := LPSimulationResponse{
+		PositionID:     pos.ID.String(),
+		Token0Symbol:   pos.Token0Symbol,
+		Token1Symbol:   pos.Token1Symbol,
+		Token0Decimals: pos.Token0Decimals,
+		Token1Decimals: pos.Token1Decimals,
+
+		TickLower:  sim.Range.TickLower,
+		TickUpper:  sim.Range.TickUpper,
+		PriceLower: lpposition.FormatPrice(sim.PriceLower),
+		PriceUpper: lpposition.FormatPrice(sim.PriceUpper),
+		Liquidity:  bigIntStr(sim.Liquidity),
+
+		Current:    toLPSimulationPointResponse(sim.Current),
+		BelowRange: toLPSimulationPointResponse(sim.BelowRange),
+		AboveRange: toLPSimulationPointResponse(sim.AboveRange),
+		Points:     make([]LPSimulationPointResponse, len(sim.Points)),
+		Curve:      make([]LPSimulationPointResponse, len(sim.Curve)),
+	}
+	for i, p := range sim.Points {
+		resp.Points[i] = toLPSimulationPointResponse(p)
+	}
+	for i, p := range sim.Curve {
+		resp.Curve[i] = toLPSimulationPointResponse(p)
+	}
+	return resp
+}
+
+func toLPSimulationPointResponse(p lpposition.SimulationPoint) LPSimulationPointResponse {
+	return LPSimulationPointResponse{
+		Price:    lpposition.FormatPrice(p.Price),
+		Tick:     p.Tick,
+		Amount0:  bigIntStr(p.Amount0),
+		Amount1:  bigIntStr(p.Amount1),
+		ValueUSD: bigIntStr(p.ValueUSD),
+		InRange:  p.InRange,
+	}
 }
 
 func toLPPositionResponse(pos *lpposition.LPPosition) LPPositionResponse {
@@ -175,6 +476,14 @@ func toLPPositionResponse(pos *lpposition.LPPosition) LPPositionResponse {
 		Status:   string(pos.Status),
 		OpenedAt: pos.OpenedAt.Format(time.RFC3339),
 		APRBps:   pos.APRBps,
+
+		FeeAPRBps:   pos.FeeAPRBps,
+		TotalAPRBps: pos.TotalAPRBps,
+
+		TickLower:   pos.TickLower,
+		TickUpper:   pos.TickUpper,
+		CurrentTick: pos.CurrentTick,
+		InRange:     pos.InRange,
 	}
 
 	if pos.ClosedAt != nil {
@@ -185,6 +494,30 @@ func toLPPositionResponse(pos *lpposition.LPPosition) LPPositionResponse {
 		s := pos.RealizedPnLUSD.String()
 		resp.RealizedPnLUSD = &s
 	}
+	if pos.CurrentValueUSD != nil {
+		s := pos.CurrentValueUSD.String()
+		resp.CurrentValueUSD = &s
+	}
+	if pos.UnrealizedPnLUSD != nil {
+		s := pos.UnrealizedPnLUSD.String()
+		resp.UnrealizedPnLUSD = &s
+	}
+	if pos.ValuedAt != nil {
+		s := pos.ValuedAt.Format(time.RFC3339)
+		resp.ValuedAt = &s
+	}
+	if pos.UncollectedFeesToken0 != nil {
+		s := pos.UncollectedFeesToken0.String()
+		resp.UncollectedFeesToken0 = &s
+	}
+	if pos.UncollectedFeesToken1 != nil {
+		s := pos.UncollectedFeesToken1.String()
+		resp.UncollectedFeesToken1 = &s
+	}
+	if pos.RangeUpdatedAt != nil {
+		s := pos.RangeUpdatedAt.Format(time.RFC3339)
+		resp.RangeUpdatedAt = &s
+	}
 
 	return resp
 }
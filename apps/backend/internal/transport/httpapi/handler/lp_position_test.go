@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kislikjeka/moontrack/internal/platform/lpposition"
+	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+)
+
+type mockLPSimulator struct {
+	mock.Mock
+}
+
+func (m *mockLPSimulator) SimulatePosition(ctx context.Context, userID, positionID uuid.UUID, req lpposition.SimulationRequest) (*lpposition.Simulation, error) {
+	args := m.Called(ctx, userID, positionID, req)
+	sim, _ := args.Get(0).(*lpposition.Simulation)
+	return sim, args.Error(1)
+}
+
+func simulateRequest(posID, userID uuid.UUID, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/lp/positions/"+posID.String()+"/simulate", strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", posID.String())
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, middleware.UserIDKey, userID)
+	return r.WithContext(ctx)
+}
+
+func TestSimulatePosition_RejectsTooManyPrices(t *testing.T) {
+	prices := make([]string, lpposition.MaxPricePoints+1)
+	for i := range prices {
+		prices[i] = "1"
+	}
+	body, err := json.Marshal(SimulateLPPositionRequest{Prices: prices})
+	require.NoError(t, err)
+
+	simulator := &mockLPSimulator{}
+	h := NewLPPositionHandler(nil, simulator, nil)
+	w := httptest.NewRecorder()
+	h.SimulatePosition(w, simulateRequest(uuid.New(), uuid.New(), string(body)))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	simulator.AssertNotCalled(t, "SimulatePosition", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSimulatePosition_RespondsFromSimulatedPosition(t *testing.T) {
+	userID := uuid.New()
+	pos := &lpposition.LPPosition{ID: uuid.New(), Token0Symbol: "WETH", Token1Symbol: "USDC", Token0Decimals: 18, Token1Decimals: 6}
+	point := lpposition.SimulationPoint{Price: big.NewFloat(2000), Amount0: big.NewInt(1), Amount1: big.NewInt(1), ValueUSD: big.NewInt(1)}
+
+	simulator := &mockLPSimulator{}
+	simulator.On("SimulatePosition", mock.Anything, userID, pos.ID, mock.Anything).Return(&lpposition.Simulation{
+		Position:   pos,
+		Range:      lpposition.PriceRange{TickLower: -100, TickUpper: 100},
+		PriceLower: big.NewFloat(1500),
+		PriceUpper: big.NewFloat(2500),
+		Liquidity:  big.NewInt(1_000_000),
+		Current:    point,
+		BelowRange: point,
+		AboveRange: point,
+	}, nil)
+
+	// The simulator authorizes the read, so the position service is not consulted
+	h := NewLPPositionHandler(nil, simulator, nil)
+	w := httptest.NewRecorder()
+	h.SimulatePosition(w, simulateRequest(pos.ID, userID, `{}`))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp LPSimulationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, pos.ID.String(), resp.PositionID)
+	assert.Equal(t, "WETH", resp.Token0Symbol)
+}
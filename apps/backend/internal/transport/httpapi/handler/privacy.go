@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/privacy"
+	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+)
+
+// PrivacyServiceInterface defines data export and erasure operations
+type PrivacyServiceInterface interface {
+	Export(ctx context.Context, userID uuid.UUID) (*privacy.Export, error)
+	DeleteAccount(ctx context.Context, userID uuid.UUID, password string) error
+}
+
+// PrivacyHandler handles the /me data export and account deletion endpoints
+type PrivacyHandler struct {
+	svc PrivacyServiceInterface
+}
+
+// NewPrivacyHandler creates a new privacy handler
+func NewPrivacyHandler(svc PrivacyServiceInterface) *PrivacyHandler {
+	return &PrivacyHandler{svc: svc}
+}
+
+// DeleteAccountRequest represents the account deletion request
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// ExportData handles GET /me/export
+// Returns a ZIP archive by default, or a single JSON document with ?format=json
+func (h *PrivacyHandler) ExportData(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "zip" && format != "json" {
+		respondWithError(w, http.StatusBadRequest, "format must be zip or json")
+		return
+	}
+
+	export, err := h.svc.Export(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to export data")
+		return
+	}
+
+	filename := fmt.Sprintf("moontrack-export-%s", export.GeneratedAt.Format("20060102-150405"))
+	w.Header().Set("Cache-Control", "no-store")
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		export.WriteJSON(w) //nolint:errcheck // headers already sent
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+	export.WriteZip(w) //nolint:errcheck // headers already sent
+}
+
+// DeleteAccount handles DELETE /me
+func (h *PrivacyHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.svc.DeleteAccount(r.Context(), userID, req.Password); err != nil {
+		switch {
+		case errors.Is(err, privacy.ErrPasswordRequired):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, privacy.ErrInvalidPassword):
+			respondWithError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, privacy.ErrUserNotFound):
+			respondWithError(w, http.StatusNotFound, err.Error())
+		default:
+			respondWithError(w, http.StatusInternalServerError, "failed to delete account")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
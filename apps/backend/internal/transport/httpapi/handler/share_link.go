@@ -0,0 +1,388 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/module/sharing"
+	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+)
+
+// ShareLinkServiceInterface defines share link operations for the HTTP handler
+type ShareLinkServiceInterface interface {
+	Create(ctx context.Context, userID uuid.UUID, params sharing.CreateParams) (*sharing.ShareLink, string, error)
+	List(ctx context.Context, userID uuid.UUID) ([]*sharing.ShareLink, error)
+	Revoke(ctx context.Context, userID, linkID uuid.UUID) error
+	Resolve(ctx context.Context, token string) (*sharing.ShareLink, error)
+	GetPortfolio(ctx context.Context, link *sharing.ShareLink) (*sharing.PortfolioSnapshot, error)
+	GetWallets(ctx context.Context, link *sharing.ShareLink) (*sharing.PortfolioSnapshot, error)
+	GetLots(ctx context.Context, link *sharing.ShareLink) ([]sharing.LotShare, error)
+}
+
+// ShareLinkHandler handles share link management and the public shared views
+type ShareLinkHandler struct {
+	svc ShareLinkServiceInterface
+}
+
+// NewShareLinkHandler creates a new share link handler
+func NewShareLinkHandler(svc ShareLinkServiceInterface) *ShareLinkHandler {
+	return &ShareLinkHandler{svc: svc}
+}
+
+// CreateShareLinkRequest represents the share link creation request
+type CreateShareLinkRequest struct {
+	Label          string   `json:"label"`
+	Scopes         []string `json:"scopes"`
+	WalletIDs      []string `json:"wallet_ids,omitempty"` // Empty shares all wallets
+	HideAmounts    bool     `json:"hide_amounts"`
+	ExpiresInHours int      `json:"expires_in_hours,omitempty"` // Defaults to 7 days
+}
+
+// ShareLinkResponse represents a share link in the API response
+type ShareLinkResponse struct {
+	ID             string   `json:"id"`
+	Label          string   `json:"label"`
+	Scopes         []string `json:"scopes"`
+	WalletIDs      []string `json:"wallet_ids"`
+	HideAmounts    bool     `json:"hide_amounts"`
+	Active         bool     `json:"active"`
+	ExpiresAt      string   `json:"expires_at"`
+	RevokedAt      string   `json:"revoked_at,omitempty"`
+	LastAccessedAt string   `json:"last_accessed_at,omitempty"`
+	CreatedAt      string   `json:"created_at"`
+	Token          string   `json:"token,omitempty"` // Only returned on creation
+}
+
+// SharedLinkInfoResponse describes what a share token exposes
+type SharedLinkInfoResponse struct {
+	Label       string   `json:"label"`
+	Scopes      []string `json:"scopes"`
+	HideAmounts bool     `json:"hide_amounts"`
+	ExpiresAt   string   `json:"expires_at"`
+}
+
+// SharedPortfolioResponse represents a shared portfolio snapshot.
+// Amount fields are omitted when the link hides amounts.
+type SharedPortfolioResponse struct {
+	TotalUSDValue string                 `json:"total_usd_value,omitempty"`
+	HideAmounts   bool                   `json:"hide_amounts"`
+	Assets        []SharedAssetResponse  `json:"assets"`
+	Wallets       []SharedWalletResponse `json:"wallets,omitempty"`
+	GeneratedAt   string                 `json:"generated_at"`
+}
+
+// SharedAssetResponse represents an asset position in a shared view
+type SharedAssetResponse struct {
+	AssetID  string `json:"asset_id"`
+	ChainID  string `json:"chain_id,omitempty"`
+	Amount   string `json:"amount,omitempty"`
+	USDValue string `json:"usd_value,omitempty"`
+	Price    string `json:"price"`
+	Decimals int    `json:"decimals"`
+	Percent  string `json:"percent"`
+}
+
+// SharedWalletResponse represents a wallet in a shared view
+type SharedWalletResponse struct {
+	WalletID   string                `json:"wallet_id"`
+	WalletName string                `json:"wallet_name"`
+	USDValue   string                `json:"usd_value,omitempty"`
+	Percent    string                `json:"percent"`
+	Assets     []SharedAssetResponse `json:"assets"`
+}
+
+// SharedLotResponse represents a tax lot in a shared view
+type SharedLotResponse struct {
+	WalletID          string `json:"wallet_id"`
+	ChainID           string `json:"chain_id,omitempty"`
+	Asset             string `json:"asset"`
+	AcquiredAt        string `json:"acquired_at"`
+	QuantityAcquired  string `json:"quantity_acquired,omitempty"`
+	QuantityRemaining string `json:"quantity_remaining,omitempty"`
+	RemainingPercent  string `json:"remaining_percent"`
+	CostBasisPerUnit  string `json:"cost_basis_per_unit"`
+	CostBasisSource   string `json:"cost_basis_source"`
+}
+
+// CreateShareLink handles POST /share-links
+func (h *ShareLinkHandler) CreateShareLink(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req CreateShareLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	params := sharing.CreateParams{
+		Label:       req.Label,
+		HideAmounts: req.HideAmounts,
+		TTL:         time.Duration(req.ExpiresInHours) * time.Hour,
+	}
+	if req.ExpiresInHours < 0 {
+		respondWithError(w, http.StatusBadRequest, sharing.ErrInvalidTTL.Error())
+		return
+	}
+	for _, s := range req.Scopes {
+		params.Scopes = append(params.Scopes, sharing.Scope(s))
+	}
+	for _, raw := range req.WalletIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid wallet ID")
+			return
+		}
+		params.WalletIDs = append(params.WalletIDs, id)
+	}
+
+	link, token, err := h.svc.Create(r.Context(), userID, params)
+	if err != nil {
+		switch {
+		case errors.Is(err, sharing.ErrMissingScope),
+			errors.Is(err, sharing.ErrInvalidScope),
+			errors.Is(err, sharing.ErrInvalidTTL),
+			errors.Is(err, sharing.ErrLabelTooLong),
+			errors.Is(err, sharing.ErrWalletNotVisible):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondWithError(w, http.StatusInternalServerError, "failed to create share link")
+		}
+		return
+	}
+
+	resp := toShareLinkResponse(link)
+	resp.Token = token
+	respondWithJSON(w, http.StatusCreated, resp)
+}
+
+// ListShareLinks handles GET /share-links
+func (h *ShareLinkHandler) ListShareLinks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	links, err := h.svc.List(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch share links")
+		return
+	}
+
+	resp := make([]ShareLinkResponse, 0, len(links))
+	for _, link := range links {
+		resp = append(resp, toShareLinkResponse(link))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// RevokeShareLink handles DELETE /share-links/{id}
+func (h *ShareLinkHandler) RevokeShareLink(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	linkID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid share link ID")
+		return
+	}
+
+	if err := h.svc.Revoke(r.Context(), userID, linkID); err != nil {
+		if errors.Is(err, sharing.ErrLinkNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "failed to revoke share link")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSharedInfo handles GET /shared/{token} (public)
+func (h *ShareLinkHandler) GetSharedInfo(w http.ResponseWriter, r *http.Request) {
+	link, ok := h.resolve(w, r)
+	if !ok {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, SharedLinkInfoResponse{
+		Label:       link.Label,
+		Scopes:      scopeStrings(link.Scopes),
+		HideAmounts: link.HideAmounts,
+		ExpiresAt:   link.ExpiresAt.Format(time.RFC3339),
+	})
+}
+
+// GetSharedPortfolio handles GET /shared/{token}/portfolio (public)
+func (h *ShareLinkHandler) GetSharedPortfolio(w http.ResponseWriter, r *http.Request) {
+	link, ok := h.resolve(w, r)
+	if !ok {
+		return
+	}
+
+	snap, err := h.svc.GetPortfolio(r.Context(), link)
+	if err != nil {
+		respondSharedError(w, err, "failed to fetch shared portfolio")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toSharedPortfolioResponse(snap))
+}
+
+// GetSharedWallets handles GET /shared/{token}/wallets (public)
+func (h *ShareLinkHandler) GetSharedWallets(w http.ResponseWriter, r *http.Request) {
+	link, ok := h.resolve(w, r)
+	if !ok {
+		return
+	}
+
+	snap, err := h.svc.GetWallets(r.Context(), link)
+	if err != nil {
+		respondSharedError(w, err, "failed to fetch shared wallets")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toSharedPortfolioResponse(snap))
+}
+
+// GetSharedLots handles GET /shared/{token}/lots (public)
+func (h *ShareLinkHandler) GetSharedLots(w http.ResponseWriter, r *http.Request) {
+	link, ok := h.resolve(w, r)
+	if !ok {
+		return
+	}
+
+	lots, err := h.svc.GetLots(r.Context(), link)
+	if err != nil {
+		respondSharedError(w, err, "failed to fetch shared lots")
+		return
+	}
+
+	resp := make([]SharedLotResponse, 0, len(lots))
+	for _, lot := range lots {
+		resp = append(resp, SharedLotResponse{
+			WalletID:          lot.WalletID.String(),
+			ChainID:           lot.ChainID,
+			Asset:             lot.Asset,
+			AcquiredAt:        lot.AcquiredAt.Format(time.RFC3339),
+			QuantityAcquired:  optionalBigIntStr(lot.QuantityAcquired),
+			QuantityRemaining: optionalBigIntStr(lot.QuantityRemaining),
+			RemainingPercent:  lot.RemainingPercent,
+			CostBasisPerUnit:  bigIntStr(lot.CostBasisPerUnit),
+			CostBasisSource:   lot.CostBasisSource,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// resolve validates the token from the URL and writes an error response on failure
+func (h *ShareLinkHandler) resolve(w http.ResponseWriter, r *http.Request) (*sharing.ShareLink, bool) {
+	link, err := h.svc.Resolve(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		respondSharedError(w, err, "failed to resolve share link")
+		return nil, false
+	}
+	// Shared snapshots change with prices; never let intermediaries cache them
+	w.Header().Set("Cache-Control", "no-store")
+	return link, true
+}
+
+func respondSharedError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, sharing.ErrInvalidToken):
+		respondWithError(w, http.StatusNotFound, "share link not found")
+	case errors.Is(err, sharing.ErrLinkInactive):
+		respondWithError(w, http.StatusGone, err.Error())
+	case errors.Is(err, sharing.ErrScopeNotShared):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+func toShareLinkResponse(link *sharing.ShareLink) ShareLinkResponse {
+	resp := ShareLinkResponse{
+		ID:          link.ID.String(),
+		Label:       link.Label,
+		Scopes:      scopeStrings(link.Scopes),
+		WalletIDs:   make([]string, 0, len(link.WalletIDs)),
+		HideAmounts: link.HideAmounts,
+		Active:      link.IsActive(time.Now().UTC()),
+		ExpiresAt:   link.ExpiresAt.Format(time.RFC3339),
+		CreatedAt:   link.CreatedAt.Format(time.RFC3339),
+	}
+	for _, id := range link.WalletIDs {
+		resp.WalletIDs = append(resp.WalletIDs, id.String())
+	}
+	if link.RevokedAt != nil {
+		resp.RevokedAt = link.RevokedAt.Format(time.RFC3339)
+	}
+	if link.LastAccessedAt != nil {
+		resp.LastAccessedAt = link.LastAccessedAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+func toSharedPortfolioResponse(snap *sharing.PortfolioSnapshot) SharedPortfolioResponse {
+	resp := SharedPortfolioResponse{
+		TotalUSDValue: optionalBigIntStr(snap.TotalUSDValue),
+		HideAmounts:   snap.HideAmounts,
+		Assets:        toSharedAssetResponses(snap.Assets),
+		GeneratedAt:   snap.GeneratedAt.Format(time.RFC3339),
+	}
+	for _, ws := range snap.Wallets {
+		resp.Wallets = append(resp.Wallets, SharedWalletResponse{
+			WalletID:   ws.WalletID.String(),
+			WalletName: ws.WalletName,
+			USDValue:   optionalBigIntStr(ws.USDValue),
+			Percent:    ws.Percent,
+			Assets:     toSharedAssetResponses(ws.Assets),
+		})
+	}
+	return resp
+}
+
+func toSharedAssetResponses(assets []sharing.AssetShare) []SharedAssetResponse {
+	resp := make([]SharedAssetResponse, 0, len(assets))
+	for _, a := range assets {
+		resp = append(resp, SharedAssetResponse{
+			AssetID:  a.AssetID,
+			ChainID:  a.ChainID,
+			Amount:   optionalBigIntStr(a.Amount),
+			USDValue: optionalBigIntStr(a.USDValue),
+			Price:    bigIntStr(a.Price),
+			Decimals: a.Decimals,
+			Percent:  a.Percent,
+		})
+	}
+	return resp
+}
+
+func scopeStrings(scopes []sharing.Scope) []string {
+	result := make([]string, len(scopes))
+	for i, s := range scopes {
+		result[i] = string(s)
+	}
+	return result
+}
+
+// optionalBigIntStr returns an empty string for nil so hidden amounts are omitted
+func optionalBigIntStr(v *big.Int) string {
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/stakingposition"
+	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+)
+
+// StakingPositionServiceInterface defines staking position operations for the HTTP handler
+type StakingPositionServiceInterface interface {
+	GetAccessible(ctx context.Context, userID, id uuid.UUID) (*stakingposition.StakingPosition, error)
+	ListAccessible(ctx context.Context, userID uuid.UUID, status *stakingposition.Status, walletID *uuid.UUID, chainID *string) ([]*stakingposition.StakingPosition, error)
+}
+
+// StakingPositionHandler handles staking position HTTP requests
+type StakingPositionHandler struct {
+	svc StakingPositionServiceInterface
+}
+
+// NewStakingPositionHandler creates a new staking position handler
+func NewStakingPositionHandler(svc StakingPositionServiceInterface) *StakingPositionHandler {
+	return &StakingPositionHandler{svc: svc}
+}
+
+// StakingPositionResponse represents a staking position in the API response
+type StakingPositionResponse struct {
+	ID       string `json:"id"`
+	WalletID string `json:"wallet_id"`
+	ChainID  string `json:"chain_id"`
+	Protocol string `json:"protocol"`
+
+	Asset         string `json:"asset"`
+	AssetDecimals int    `json:"asset_decimals"`
+	StakedAmount  string `json:"staked_amount"`
+
+	ReceiptAsset    string `json:"receipt_asset,omitempty"`
+	ReceiptDecimals int    `json:"receipt_decimals,omitempty"`
+	ReceiptContract string `json:"receipt_contract,omitempty"`
+	ReceiptAmount   string `json:"receipt_amount"`
+	Rebasing        bool   `json:"rebasing"`
+
+	TotalStaked      string `json:"total_staked"`
+	TotalUnstaked    string `json:"total_unstaked"`
+	TotalStakedUSD   string `json:"total_staked_usd"`
+	TotalUnstakedUSD string `json:"total_unstaked_usd"`
+
+	RewardsAmount string `json:"rewards_amount"`
+	RewardsUSD    string `json:"rewards_usd"`
+
+	Status   string  `json:"status"`
+	OpenedAt string  `json:"opened_at"`
+	ClosedAt *string `json:"closed_at,omitempty"`
+}
+
+// ListPositions handles GET /staking/positions
+func (h *StakingPositionHandler) ListPositions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var statusFilter *stakingposition.Status
+	if s := r.URL.Query().Get("status"); s != "" {
+		st := stakingposition.Status(s)
+		statusFilter = &st
+	}
+
+	var walletIDFilter *uuid.UUID
+	if wid := r.URL.Query().Get("wallet_id"); wid != "" {
+		parsed, err := uuid.Parse(wid)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid wallet_id")
+			return
+		}
+		walletIDFilter = &parsed
+	}
+
+	var chainIDFilter *string
+	if cid := r.URL.Query().Get("chain_id"); cid != "" {
+		chainIDFilter = &cid
+	}
+
+	positions, err := h.svc.ListAccessible(r.Context(), userID, statusFilter, walletIDFilter, chainIDFilter)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to list staking positions")
+		return
+	}
+
+	response := make([]StakingPositionResponse, len(positions))
+	for i, pos := range positions {
+		response[i] = toStakingPositionResponse(pos)
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// GetPosition handles GET /staking/positions/{id}
+func (h *StakingPositionHandler) GetPosition(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	posID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid position ID")
+		return
+	}
+
+	pos, err := h.svc.GetAccessible(r.Context(), userID, posID)
+	if err != nil {
+		if errors.Is(err, stakingposition.ErrPositionNotFound) {
+			respondWithError(w, http.StatusNotFound, "staking position not found")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "failed to get staking position")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toStakingPositionResponse(pos))
+}
+
+func toStakingPositionResponse(pos *stakingposition.StakingPosition) StakingPositionResponse {
+	resp := StakingPositionResponse{
+		ID:       pos.ID.String(),
+		WalletID: pos.WalletID.String(),
+		ChainID:  pos.ChainID,
+		Protocol: pos.Protocol,
+
+		Asset:         pos.Asset,
+		AssetDecimals: pos.AssetDecimals,
+		StakedAmount:  bigIntStr(pos.StakedAmount),
+
+		ReceiptAsset:    pos.ReceiptAsset,
+		ReceiptDecimals: pos.ReceiptDecimals,
+		ReceiptContract: pos.ReceiptContract,
+		ReceiptAmount:   bigIntStr(pos.ReceiptAmount),
+		Rebasing:        pos.Rebasing,
+
+		TotalStaked:      bigIntStr(pos.TotalStaked),
+		TotalUnstaked:    bigIntStr(pos.TotalUnstaked),
+		TotalStakedUSD:   bigIntStr(pos.TotalStakedUSD),
+		TotalUnstakedUSD: bigIntStr(pos.TotalUnstakedUSD),
+
+		RewardsAmount: bigIntStr(pos.RewardsAmount),
+		RewardsUSD:    bigIntStr(pos.RewardsUSD),
+
+		Status:   string(pos.Status),
+		OpenedAt: pos.OpenedAt.Format(time.RFC3339),
+	}
+
+	if pos.ClosedAt != nil {
+		s := pos.ClosedAt.Format(time.RFC3339)
+		resp.ClosedAt = &s
+	}
+
+	return resp
+}
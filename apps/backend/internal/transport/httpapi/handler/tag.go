@@ -0,0 +1,371 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/module/tagging"
+	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// TagServiceInterface defines tag and note operations for the HTTP handler
+type TagServiceInterface interface {
+	CreateTag(ctx context.Context, userID uuid.UUID, params tagging.TagParams) (*tagging.Tag, error)
+	ListTags(ctx context.Context, userID uuid.UUID) ([]*tagging.Tag, error)
+	UpdateTag(ctx context.Context, userID, tagID uuid.UUID, params tagging.TagParams) (*tagging.Tag, error)
+	DeleteTag(ctx context.Context, userID, tagID uuid.UUID) error
+	GetAnnotations(ctx context.Context, userID, txID uuid.UUID) (*tagging.Annotations, error)
+	SetTransactionTags(ctx context.Context, userID, txID uuid.UUID, tagIDs []uuid.UUID) (*tagging.Annotations, error)
+	SetNote(ctx context.Context, userID, txID uuid.UUID, body string) (*tagging.Annotations, error)
+	GetFlows(ctx context.Context, userID uuid.UUID, q tagging.FlowQuery) ([]*tagging.TagFlow, error)
+}
+
+// TagHandler handles user tags, notes and per-tag flow reports
+type TagHandler struct {
+	svc TagServiceInterface
+}
+
+// NewTagHandler creates a new tag handler
+func NewTagHandler(svc TagServiceInterface) *TagHandler {
+	return &TagHandler{svc: svc}
+}
+
+// TagRequest represents the tag create/update request
+type TagRequest struct {
+	Name     string `json:"name"`
+	Category string `json:"category,omitempty"`
+	Color    string `json:"color,omitempty"` // #rrggbb
+}
+
+// SetTransactionTagsRequest replaces the tags on a transaction
+type SetTransactionTagsRequest struct {
+	TagIDs []string `json:"tag_ids"`
+}
+
+// SetNoteRequest sets the note on a transaction; an empty note removes it
+type SetNoteRequest struct {
+	Note string `json:"note"`
+}
+
+// TagResponse represents a tag in the API response
+type TagResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Category  string `json:"category"`
+	Color     string `json:"color"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// AnnotationsResponse represents the user's tags and note on a transaction
+type AnnotationsResponse struct {
+	TransactionID string        `json:"transaction_id"`
+	Tags          []TagResponse `json:"tags"`
+	Note          string        `json:"note"`
+	NoteUpdatedAt string        `json:"note_updated_at,omitempty"`
+}
+
+// TagFlowResponse represents USD in/out for one tag in one period
+type TagFlowResponse struct {
+	TagID            string `json:"tag_id"`
+	TagName          string `json:"tag_name"`
+	Category         string `json:"category"`
+	PeriodStart      string `json:"period_start"`
+	USDIn            string `json:"usd_in"`
+	USDOut           string `json:"usd_out"`
+	Net              string `json:"net"`
+	TransactionCount int    `json:"transaction_count"`
+}
+
+// CreateTag handles POST /tags
+func (h *TagHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	tag, err := h.svc.CreateTag(r.Context(), userID, tagging.TagParams(req))
+	if err != nil {
+		respondTagError(w, err, "failed to create tag")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, toTagResponse(tag))
+}
+
+// ListTags handles GET /tags
+func (h *TagHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	tags, err := h.svc.ListTags(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch tags")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toTagResponses(tags))
+}
+
+// UpdateTag handles PUT /tags/{id}
+func (h *TagHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	tagID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid tag ID")
+		return
+	}
+
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	tag, err := h.svc.UpdateTag(r.Context(), userID, tagID, tagging.TagParams(req))
+	if err != nil {
+		respondTagError(w, err, "failed to update tag")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toTagResponse(tag))
+}
+
+// DeleteTag handles DELETE /tags/{id}
+func (h *TagHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	tagID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid tag ID")
+		return
+	}
+
+	if err := h.svc.DeleteTag(r.Context(), userID, tagID); err != nil {
+		respondTagError(w, err, "failed to delete tag")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTagFlows handles GET /tags/flows?period=month&from=&to=&tag_id=
+//
+// Defaults to monthly periods over the last 12 months. from and to are RFC3339.
+func (h *TagHandler) GetTagFlows(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	query := r.URL.Query()
+	q := tagging.FlowQuery{
+		Period: tagging.PeriodMonth,
+		To:     time.Now().UTC(),
+	}
+	if p := query.Get("period"); p != "" {
+		q.Period = tagging.Period(p)
+	}
+	if s := query.Get("to"); s != "" {
+		to, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid to date, expected RFC3339")
+			return
+		}
+		q.To = to
+	}
+	q.From = q.To.AddDate(-1, 0, 0)
+	if s := query.Get("from"); s != "" {
+		from, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid from date, expected RFC3339")
+			return
+		}
+		q.From = from
+	}
+	for _, raw := range query["tag_id"] {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid tag_id")
+			return
+		}
+		q.TagIDs = append(q.TagIDs, id)
+	}
+
+	flows, err := h.svc.GetFlows(r.Context(), userID, q)
+	if err != nil {
+		respondTagError(w, err, "failed to fetch tag flows")
+		return
+	}
+
+	resp := make([]TagFlowResponse, 0, len(flows))
+	for _, f := range flows {
+		resp = append(resp, TagFlowResponse{
+			TagID:            f.TagID.String(),
+			TagName:          f.TagName,
+			Category:         f.Category,
+			PeriodStart:      f.PeriodStart.Format(time.RFC3339),
+			USDIn:            money.FormatUSD(f.USDIn),
+			USDOut:           money.FormatUSD(f.USDOut),
+			Net:              money.FormatUSD(f.Net()),
+			TransactionCount: f.TransactionCount,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// GetAnnotations handles GET /transactions/{id}/annotations
+func (h *TagHandler) GetAnnotations(w http.ResponseWriter, r *http.Request) {
+	userID, txID, ok := h.transactionParams(w, r)
+	if !ok {
+		return
+	}
+
+	ann, err := h.svc.GetAnnotations(r.Context(), userID, txID)
+	if err != nil {
+		respondTagError(w, err, "failed to fetch annotations")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toAnnotationsResponse(ann))
+}
+
+// SetTransactionTags handles PUT /transactions/{id}/tags
+func (h *TagHandler) SetTransactionTags(w http.ResponseWriter, r *http.Request) {
+	userID, txID, ok := h.transactionParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req SetTransactionTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	tagIDs := make([]uuid.UUID, 0, len(req.TagIDs))
+	for _, raw := range req.TagIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid tag ID")
+			return
+		}
+		tagIDs = append(tagIDs, id)
+	}
+
+	ann, err := h.svc.SetTransactionTags(r.Context(), userID, txID, tagIDs)
+	if err != nil {
+		respondTagError(w, err, "failed to set transaction tags")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toAnnotationsResponse(ann))
+}
+
+// SetNote handles PUT /transactions/{id}/note
+func (h *TagHandler) SetNote(w http.ResponseWriter, r *http.Request) {
+	userID, txID, ok := h.transactionParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req SetNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	ann, err := h.svc.SetNote(r.Context(), userID, txID, req.Note)
+	if err != nil {
+		respondTagError(w, err, "failed to save note")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toAnnotationsResponse(ann))
+}
+
+func (h *TagHandler) transactionParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	txID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid transaction ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, txID, true
+}
+
+func respondTagError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, tagging.ErrTagNotFound),
+		errors.Is(err, tagging.ErrTransactionNotVisible):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, tagging.ErrDuplicateTag):
+		respondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, tagging.ErrTagNameRequired),
+		errors.Is(err, tagging.ErrTagNameTooLong),
+		errors.Is(err, tagging.ErrCategoryTooLong),
+		errors.Is(err, tagging.ErrInvalidColor),
+		errors.Is(err, tagging.ErrTooManyTags),
+		errors.Is(err, tagging.ErrNoteTooLong),
+		errors.Is(err, tagging.ErrInvalidPeriod),
+		errors.Is(err, tagging.ErrInvalidRange):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+func toTagResponse(tag *tagging.Tag) TagResponse {
+	return TagResponse{
+		ID:        tag.ID.String(),
+		Name:      tag.Name,
+		Category:  tag.Category,
+		Color:     tag.Color,
+		CreatedAt: tag.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: tag.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toTagResponses(tags []*tagging.Tag) []TagResponse {
+	resp := make([]TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		resp = append(resp, toTagResponse(tag))
+	}
+	return resp
+}
+
+func toAnnotationsResponse(ann *tagging.Annotations) AnnotationsResponse {
+	resp := AnnotationsResponse{
+		TransactionID: ann.TransactionID.String(),
+		Tags:          toTagResponses(ann.Tags),
+	}
+	if ann.Note != nil {
+		resp.Note = ann.Note.Body
+		resp.NoteUpdatedAt = ann.Note.UpdatedAt.Format(time.RFC3339)
+	}
+	return resp
+}
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/module/taxprofile"
+	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// TaxProfileServiceInterface defines tax profile operations for the HTTP handler
+type TaxProfileServiceInterface interface {
+	GetProfile(ctx context.Context, userID uuid.UUID) (*taxprofile.Profile, error)
+	SetProfile(ctx context.Context, userID uuid.UUID, jurisdiction taxprofile.Jurisdiction) (*taxprofile.Profile, error)
+	GetReport(ctx context.Context, userID uuid.UUID, year int) (*taxprofile.Report, error)
+}
+
+// TaxProfileHandler handles tax profiles and the capital gains report
+type TaxProfileHandler struct {
+	svc      TaxProfileServiceInterface
+	resolver *money.DecimalResolver
+}
+
+// NewTaxProfileHandler creates a new tax profile handler
+func NewTaxProfileHandler(svc TaxProfileServiceInterface, resolver *money.DecimalResolver) *TaxProfileHandler {
+	return &TaxProfileHandler{svc: svc, resolver: resolver}
+}
+
+// SetTaxProfileRequest represents the tax profile update request
+type SetTaxProfileRequest struct {
+	Jurisdiction string `json:"jurisdiction"`
+}
+
+// TaxRulesResponse represents the rules a jurisdiction applies
+type TaxRulesResponse struct {
+	Jurisdiction       string `json:"jurisdiction"`
+	Method             string `json:"method"`
+	LongTermAfterYears int    `json:"long_term_after_years,omitempty"`
+	TaxYearStart       string `json:"tax_year_start"` // MM-DD
+}
+
+// TaxProfileResponse represents a user's tax profile
+type TaxProfileResponse struct {
+	Jurisdiction string           `json:"jurisdiction"`
+	Rules        TaxRulesResponse `json:"rules"`
+	UpdatedAt    *string          `json:"updated_at,omitempty"` // Absent until the user sets a profile
+}
+
+// CapitalGainsDisposalResponse represents one matched part of a disposal
+type CapitalGainsDisposalResponse struct {
+	TransactionID string  `json:"transaction_id"`
+	Asset         string  `json:"asset"`
+	DisposedAt    string  `json:"disposed_at"`
+	Quantity      string  `json:"quantity"`
+	Proceeds      string  `json:"proceeds"`
+	Cost          string  `json:"cost"`
+	Gain          string  `json:"gain"`
+	Rule          string  `json:"rule"`
+	LotID         *string `json:"lot_id,omitempty"`
+	AcquiredAt    *string `json:"acquired_at,omitempty"`
+	Term          string  `json:"term,omitempty"`
+}
+
+// Section104PoolResponse represents an asset's pool at the end of the tax year
+type Section104PoolResponse struct {
+	Asset    string `json:"asset"`
+	Quantity string `json:"quantity"`
+	Cost     string `json:"cost"`
+}
+
+// CapitalGainsReportResponse represents the capital gains report of a tax year
+type CapitalGainsReportResponse struct {
+	Rules         TaxRulesResponse               `json:"rules"`
+	TaxYear       int                            `json:"tax_year"`
+	From          string                         `json:"from"`
+	To            string                         `json:"to"`
+	TotalProceeds string                         `json:"total_proceeds"`
+	TotalCost     string                         `json:"total_cost"`
+	TotalGain     string                         `json:"total_gain"`
+	ShortTermGain *string                        `json:"short_term_gain,omitempty"`
+	LongTermGain  *string                        `json:"long_term_gain,omitempty"`
+	Disposals     []CapitalGainsDisposalResponse `json:"disposals"`
+	Pools         []Section104PoolResponse       `json:"pools,omitempty"`
+}
+
+// GetTaxProfile handles GET /tax/profile
+func (h *TaxProfileHandler) GetTaxProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	p, err := h.svc.GetProfile(r.Context(), userID)
+	if err != nil {
+		respondTaxProfileError(w, err, "failed to fetch tax profile")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toTaxProfileResponse(p))
+}
+
+// SetTaxProfile handles PUT /tax/profile
+func (h *TaxProfileHandler) SetTaxProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req SetTaxProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	p, err := h.svc.SetProfile(r.Context(), userID, taxprofile.Jurisdiction(req.Jurisdiction))
+	if err != nil {
+		respondTaxProfileError(w, err, "failed to update tax profile")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, toTaxProfileResponse(p))
+}
+
+// GetCapitalGainsReport handles GET /tax/capital-gains?year=
+//
+// year is the calendar year the tax year starts in; 2024 is 6 April 2024 to
+// 5 April 2025 under the UK profile.
+func (h *TaxProfileHandler) GetCapitalGainsReport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	year := time.Now().UTC().Year()
+	if raw := r.URL.Query().Get("year"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, taxprofile.ErrInvalidYear.Error())
+			return
+		}
+		year = parsed
+	}
+
+	report, err := h.svc.GetReport(r.Context(), userID, year)
+	if err != nil {
+		respondTaxProfileError(w, err, "failed to build capital gains report")
+		return
+	}
+
+	resp := CapitalGainsReportResponse{
+		Rules:         toTaxRulesResponse(report.Rules),
+		TaxYear:       report.TaxYear,
+		From:          report.From.Format(time.RFC3339),
+		To:            report.To.Format(time.RFC3339),
+		TotalProceeds: money.FormatUSD(report.TotalProceeds),
+		TotalCost:     money.FormatUSD(report.TotalCost),
+		TotalGain:     money.FormatUSD(report.TotalGain),
+		Disposals:     make([]CapitalGainsDisposalResponse, 0, len(report.Disposals)),
+	}
+	if report.ShortTermGain != nil {
+		short, long := money.FormatUSD(report.ShortTermGain), money.FormatUSD(report.LongTermGain)
+		resp.ShortTermGain, resp.LongTermGain = &short, &long
+	}
+	for _, d := range report.Disposals {
+		dr := CapitalGainsDisposalResponse{
+			TransactionID: d.TransactionID.String(),
+			Asset:         d.Asset,
+			DisposedAt:    d.DisposedAt.Format(time.RFC3339),
+			Quantity:      money.FromBaseUnits(d.Quantity, h.resolveDecimals(r.Context(), d.Asset)),
+			Proceeds:      money.FormatUSD(d.Proceeds),
+			Cost:          money.FormatUSD(d.Cost),
+			Gain:          money.FormatUSD(d.Gain),
+			Rule:          string(d.Rule),
+			Term:          string(d.Term),
+		}
+		if d.LotID != nil {
+			lotID := d.LotID.String()
+			dr.LotID = &lotID
+		}
+		if d.AcquiredAt != nil {
+			acquiredAt := d.AcquiredAt.Format(time.RFC3339)
+			dr.AcquiredAt = &acquiredAt
+		}
+		resp.Disposals = append(resp.Disposals, dr)
+	}
+	for _, p := range report.Pools {
+		resp.Pools = append(resp.Pools, Section104PoolResponse{
+			Asset:    p.Asset,
+			Quantity: money.FromBaseUnits(p.Quantity, h.resolveDecimals(r.Context(), p.Asset)),
+			Cost:     money.FormatUSD(p.Cost),
+		})
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// resolveDecimals uses the resolver if available, otherwise falls back to the hardcoded map.
+func (h *TaxProfileHandler) resolveDecimals(ctx context.Context, symbol string) int {
+	if h.resolver != nil {
+		return h.resolver.ResolveSymbolOnly(ctx, symbol)
+	}
+	return money.GetDecimals(symbol)
+}
+
+func toTaxProfileResponse(p *taxprofile.Profile) TaxProfileResponse {
+	resp := TaxProfileResponse{
+		Jurisdiction: string(p.Jurisdiction),
+		Rules:        toTaxRulesResponse(p.Rules()),
+	}
+	if !p.UpdatedAt.IsZero() {
+		updatedAt := p.UpdatedAt.Format(time.RFC3339)
+		resp.UpdatedAt = &updatedAt
+	}
+	return resp
+}
+
+func toTaxRulesResponse(rules taxprofile.Rules) TaxRulesResponse {
+	return TaxRulesResponse{
+		Jurisdiction:       string(rules.Jurisdiction),
+		Method:             string(rules.Method),
+		LongTermAfterYears: rules.LongTermAfterYears,
+		TaxYearStart:       time.Date(2000, rules.TaxYearStartMonth, rules.TaxYearStartDay, 0, 0, 0, 0, time.UTC).Format("01-02"),
+	}
+}
+
+func respondTaxProfileError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, taxprofile.ErrInvalidJurisdiction),
+		errors.Is(err, taxprofile.ErrInvalidYear):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, fallback)
+	}
+}
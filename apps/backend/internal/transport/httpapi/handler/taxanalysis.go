@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/module/taxanalysis"
+	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+	"github.com/kislikjeka/moontrack/pkg/money"
+)
+
+// TaxAnalysisServiceInterface defines tax analysis operations for the HTTP handler
+type TaxAnalysisServiceInterface interface {
+	GetWashSales(ctx context.Context, userID uuid.UUID, year int) ([]*taxanalysis.WashSale, error)
+	GetHarvestCandidates(ctx context.Context, userID uuid.UUID, rates taxanalysis.Rates) (*taxanalysis.HarvestReport, error)
+}
+
+// TaxAnalysisHandler handles wash-sale and tax-loss harvesting reports
+type TaxAnalysisHandler struct {
+	svc      TaxAnalysisServiceInterface
+	resolver *money.DecimalResolver
+}
+
+// NewTaxAnalysisHandler creates a new tax analysis handler
+func NewTaxAnalysisHandler(svc TaxAnalysisServiceInterface, resolver *money.DecimalResolver) *TaxAnalysisHandler {
+	return &TaxAnalysisHandler{svc: svc, resolver: resolver}
+}
+
+// WashSaleReplacementResponse represents a purchase that replaced sold units
+type WashSaleReplacementResponse struct {
+	LotID                    string `json:"lot_id"`
+	TransactionID            string `json:"transaction_id"`
+	WalletID                 string `json:"wallet_id"`
+	AcquiredAt               string `json:"acquired_at"`
+	Quantity                 string `json:"quantity"`
+	BasisAdjustment          string `json:"basis_adjustment"`
+	AdjustedCostBasisPerUnit string `json:"adjusted_cost_basis_per_unit"`
+}
+
+// WashSaleResponse represents a loss sale caught by the wash-sale rule
+type WashSaleResponse struct {
+	DisposalID       string                        `json:"disposal_id"`
+	TransactionID    string                        `json:"transaction_id"`
+	LotID            string                        `json:"lot_id"`
+	WalletID         string                        `json:"wallet_id"`
+	Asset            string                        `json:"asset"`
+	DisposedAt       string                        `json:"disposed_at"`
+	QuantityDisposed string                        `json:"quantity_disposed"`
+	Loss             string                        `json:"loss"`
+	DisallowedLoss   string                        `json:"disallowed_loss"`
+	Replacements     []WashSaleReplacementResponse `json:"replacements"`
+}
+
+// WashSaleReportResponse represents the wash sales of a tax year
+type WashSaleReportResponse struct {
+	Year                int                `json:"year"`
+	TotalDisallowedLoss string             `json:"total_disallowed_loss"`
+	WashSales           []WashSaleResponse `json:"wash_sales"`
+}
+
+// HarvestCandidateResponse represents an open lot with an unrealized loss
+type HarvestCandidateResponse struct {
+	LotID             string `json:"lot_id"`
+	WalletID          string `json:"wallet_id"`
+	ChainID           string `json:"chain_id"`
+	Asset             string `json:"asset"`
+	AcquiredAt        string `json:"acquired_at"`
+	QuantityRemaining string `json:"quantity_remaining"`
+	CostBasisPerUnit  string `json:"cost_basis_per_unit"`
+	CurrentPrice      string `json:"current_price"`
+	UnrealizedLoss    string `json:"unrealized_loss"`
+	Term              string `json:"term"` // "short" or "long"
+	EstimatedSaving   string `json:"estimated_saving"`
+	WashSaleRisk      bool   `json:"wash_sale_risk"`
+}
+
+// HarvestReportResponse represents the tax-loss harvesting report
+type HarvestReportResponse struct {
+	ShortTermRate        float64                    `json:"short_term_rate"`
+	LongTermRate         float64                    `json:"long_term_rate"`
+	TotalUnrealizedLoss  string                     `json:"total_unrealized_loss"`
+	TotalEstimatedSaving string                     `json:"total_estimated_saving"`
+	PricedAt             string                     `json:"priced_at"`
+	Candidates           []HarvestCandidateResponse `json:"candidates"`
+}
+
+// GetWashSales handles GET /tax/wash-sales?year=
+func (h *TaxAnalysisHandler) GetWashSales(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	year, ok := parseReportYear(w, r)
+	if !ok {
+		return
+	}
+
+	washSales, err := h.svc.GetWashSales(r.Context(), userID, year)
+	if err != nil {
+		respondTaxAnalysisError(w, err, "failed to detect wash sales")
+		return
+	}
+
+	total := new(big.Int)
+	resp := WashSaleReportResponse{Year: year, WashSales: make([]WashSaleResponse, 0, len(washSales))}
+	for _, ws := range washSales {
+		decimals := h.resolveDecimals(r.Context(), ws.Asset)
+		total.Add(total, ws.DisallowedLoss)
+		wr := WashSaleResponse{
+			DisposalID:       ws.DisposalID.String(),
+			TransactionID:    ws.TransactionID.String(),
+			LotID:            ws.LotID.String(),
+			WalletID:         ws.WalletID.String(),
+			Asset:            ws.Asset,
+			DisposedAt:       ws.DisposedAt.Format(time.RFC3339),
+			QuantityDisposed: money.FromBaseUnits(ws.QuantityDisposed, decimals),
+			Loss:             money.FormatUSD(ws.Loss),
+			DisallowedLoss:   money.FormatUSD(ws.DisallowedLoss),
+			Replacements:     make([]WashSaleReplacementResponse, 0, len(ws.Replacements)),
+		}
+		for _, rp := range ws.Replacements {
+			wr.Replacements = append(wr.Replacements, WashSaleReplacementResponse{
+				LotID:                    rp.LotID.String(),
+				TransactionID:            rp.TransactionID.String(),
+				WalletID:                 rp.WalletID.String(),
+				AcquiredAt:               rp.AcquiredAt.Format(time.RFC3339),
+				Quantity:                 money.FromBaseUnits(rp.Quantity, decimals),
+				BasisAdjustment:          money.FormatUSD(rp.BasisAdjustment),
+				AdjustedCostBasisPerUnit: money.FormatUSD(rp.AdjustedCostBasisPerUnit),
+			})
+		}
+		resp.WashSales = append(resp.WashSales, wr)
+	}
+	resp.TotalDisallowedLoss = money.FormatUSD(total)
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// GetHarvestCandidates handles GET /tax/harvest?short_term_rate=&long_term_rate=
+//
+// Rates are fractions, e.g. 0.24; omitted rates use the service defaults.
+func (h *TaxAnalysisHandler) GetHarvestCandidates(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	rates := taxanalysis.DefaultRates
+	for param, target := range map[string]*int64{
+		"short_term_rate": &rates.ShortTermBps,
+		"long_term_rate":  &rates.LongTermBps,
+	} {
+		raw := r.URL.Query().Get(param)
+		if raw == "" {
+			continue
+		}
+		rate, err := strconv.ParseFloat(raw, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			respondWithError(w, http.StatusBadRequest, taxanalysis.ErrInvalidRate.Error())
+			return
+		}
+		*target = int64(rate*10000 + 0.5)
+	}
+
+	report, err := h.svc.GetHarvestCandidates(r.Context(), userID, rates)
+	if err != nil {
+		respondTaxAnalysisError(w, err, "failed to build harvest report")
+		return
+	}
+
+	resp := HarvestReportResponse{
+		ShortTermRate:        float64(report.Rates.ShortTermBps) / 10000,
+		LongTermRate:         float64(report.Rates.LongTermBps) / 10000,
+		TotalUnrealizedLoss:  money.FormatUSD(report.TotalUnrealizedLoss),
+		TotalEstimatedSaving: money.FormatUSD(report.TotalEstimatedSaving),
+		PricedAt:             report.PricedAt.Format(time.RFC3339),
+		Candidates:           make([]HarvestCandidateResponse, 0, len(report.Candidates)),
+	}
+	for _, c := range report.Candidates {
+		term := "short"
+		if c.LongTerm {
+			term = "long"
+		}
+		resp.Candidates = append(resp.Candidates, HarvestCandidateResponse{
+			LotID:             c.LotID.String(),
+			WalletID:          c.WalletID.String(),
+			ChainID:           c.ChainID,
+			Asset:             c.Asset,
+			AcquiredAt:        c.AcquiredAt.Format(time.RFC3339),
+			QuantityRemaining: money.FromBaseUnits(c.QuantityRemaining, h.resolveDecimals(r.Context(), c.Asset)),
+			CostBasisPerUnit:  money.FormatUSD(c.CostBasisPerUnit),
+			CurrentPrice:      money.FormatUSD(c.CurrentPrice),
+			UnrealizedLoss:    money.FormatUSD(c.UnrealizedLoss),
+			Term:              term,
+			EstimatedSaving:   money.FormatUSD(c.EstimatedSaving),
+			WashSaleRisk:      c.WashSaleRisk,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// resolveDecimals uses the resolver if available, otherwise falls back to the hardcoded map.
+func (h *TaxAnalysisHandler) resolveDecimals(ctx context.Context, symbol string) int {
+	if h.resolver != nil {
+		return h.resolver.ResolveSymbolOnly(ctx, symbol)
+	}
+	return money.GetDecimals(symbol)
+}
+
+func respondTaxAnalysisError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, taxanalysis.ErrInvalidYear),
+		errors.Is(err, taxanalysis.ErrInvalidRate):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, fallback)
+	}
+}
@@ -22,6 +22,7 @@ type TaxLotServiceInterface interface {
 	OverrideCostBasis(ctx context.Context, userID uuid.UUID, lotID uuid.UUID, costBasis *big.Int, reason string) error
 	GetWAC(ctx context.Context, userID uuid.UUID, walletID *uuid.UUID) ([]taxlot.WACPosition, error)
 	GetLotImpactByTransaction(ctx context.Context, userID, txID uuid.UUID) (*taxlot.TransactionLotImpact, error)
+	RebuildLots(ctx context.Context, userID uuid.UUID, walletID *uuid.UUID, asset string) error
 }
 
 // TaxLotHandler handles tax lot HTTP requests.
@@ -110,6 +111,13 @@ type OverrideCostBasisRequest struct {
 	Reason           string `json:"reason"`
 }
 
+// RebuildLotsRequest is the JSON request body for rebuilding tax lots.
+// Both fields are optional; empty means every editable wallet / every asset.
+type RebuildLotsRequest struct {
+	WalletID string `json:"wallet_id"`
+	Asset    string `json:"asset"`
+}
+
 // --- Handlers ---
 
 // GetLots handles GET /lots?wallet_id={id}&asset={asset}
@@ -221,6 +229,44 @@ func (h *TaxLotHandler) OverrideCostBasis(w http.ResponseWriter, r *http.Request
 	respondWithJSON(w, http.StatusOK, map[string]string{"status": "override applied"})
 }
 
+// RebuildLots handles POST /lots/rebuild
+func (h *TaxLotHandler) RebuildLots(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req RebuildLotsRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	var walletID *uuid.UUID
+	if req.WalletID != "" {
+		id, err := uuid.Parse(req.WalletID)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid wallet_id")
+			return
+		}
+		walletID = &id
+	}
+
+	if err := h.taxLotService.RebuildLots(r.Context(), userID, walletID, req.Asset); err != nil {
+		if errors.Is(err, taxlot.ErrWalletNotOwned) {
+			respondWithError(w, http.StatusForbidden, "access denied")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "failed to rebuild tax lots")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "rebuilt"})
+}
+
 // GetWAC handles GET /positions/wac?wallet_id={id}
 func (h *TaxLotHandler) GetWAC(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
@@ -3,10 +3,12 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -14,6 +16,9 @@ import (
 
 	"github.com/kislikjeka/moontrack/internal/ledger"
 	"github.com/kislikjeka/moontrack/internal/module/transactions"
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
 	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
 	"github.com/kislikjeka/moontrack/pkg/money"
 )
@@ -26,12 +31,15 @@ type RegistryServiceInterface interface {
 // LedgerServiceInterface defines the interface for ledger operations needed by TransactionHandler
 type LedgerServiceInterface interface {
 	RecordTransaction(ctx context.Context, transactionType ledger.TransactionType, source string, externalID *string, occurredAt time.Time, rawData map[string]interface{}) (*ledger.Transaction, error)
+	AmendTransaction(ctx context.Context, txID uuid.UUID, expectedVersion int, occurredAt time.Time, rawData map[string]interface{}) (*ledger.Transaction, error)
+	ReverseTransaction(ctx context.Context, txID uuid.UUID, reason string) (*ledger.Transaction, error)
 }
 
 // TransactionServiceInterface defines the interface for transaction read operations
 type TransactionServiceInterface interface {
-	ListTransactions(ctx context.Context, filters ledger.TransactionFilters) ([]transactions.TransactionListItem, error)
+	ListTransactions(ctx context.Context, filters ledger.TransactionFilters) (*transactions.TransactionList, error)
 	GetTransaction(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*transactions.TransactionDetail, error)
+	VerifyWalletAccess(ctx context.Context, walletID, userID uuid.UUID, required workspace.Role) (*wallet.Wallet, error)
 }
 
 // TransactionHandler handles transaction-related HTTP requests
@@ -39,14 +47,16 @@ type TransactionHandler struct {
 	ledgerService      LedgerServiceInterface
 	transactionService TransactionServiceInterface
 	registryService    RegistryServiceInterface
+	audit              audit.Recorder // optional
 }
 
 // NewTransactionHandler creates a new transaction handler
-func NewTransactionHandler(ledgerService LedgerServiceInterface, transactionService TransactionServiceInterface, registrySvc RegistryServiceInterface) *TransactionHandler {
+func NewTransactionHandler(ledgerService LedgerServiceInterface, transactionService TransactionServiceInterface, registrySvc RegistryServiceInterface, recorder audit.Recorder) *TransactionHandler {
 	return &TransactionHandler{
 		ledgerService:      ledgerService,
 		transactionService: transactionService,
 		registryService:    registrySvc,
+		audit:              recorder,
 	}
 }
 
@@ -63,6 +73,18 @@ type CreateTransactionRequest struct {
 	Data        map[string]interface{} `json:"data,omitempty"` // Additional transaction-specific data
 }
 
+// ReverseTransactionRequest is the optional body of a reversal
+type ReverseTransactionRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// UpdateTransactionRequest represents a manual transaction amendment.
+// Version must be the version the client last read.
+type UpdateTransactionRequest struct {
+	CreateTransactionRequest
+	Version int `json:"version"`
+}
+
 // TransactionResponse represents a transaction response (used for create/single)
 type TransactionResponse struct {
 	ID           string                 `json:"id"`
@@ -70,6 +92,7 @@ type TransactionResponse struct {
 	Source       string                 `json:"source"`
 	ExternalID   *string                `json:"external_id,omitempty"`
 	Status       string                 `json:"status"`
+	Version      int                    `json:"version"`
 	OccurredAt   string                 `json:"occurred_at"`
 	RecordedAt   string                 `json:"recorded_at"`
 	RawData      map[string]interface{} `json:"raw_data,omitempty"`
@@ -100,12 +123,13 @@ type TransactionListResponse struct {
 	Total        int                           `json:"total"`
 	Page         int                           `json:"page"`
 	PageSize     int                           `json:"page_size"`
+	NextCursor   string                        `json:"next_cursor,omitempty"` // Pass as cursor for the next page
 }
 
 // CreateTransaction handles POST /transactions
 func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context (set by JWT middleware)
-	_, ok := middleware.GetUserIDFromContext(r.Context())
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
 		respondWithError(w, http.StatusUnauthorized, "unauthorized")
 		return
@@ -117,30 +141,97 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	input, ok := h.manualTransactionData(w, r, userID, &req)
+	if !ok {
+		return
+	}
+
+	// Record transaction via ledger service
+	transaction, err := h.ledgerService.RecordTransaction(r.Context(), input.txType, "manual", nil, input.occurredAt, input.data)
+	if err != nil {
+		// Handle specific errors
+		if err.Error() == "wallet not found" {
+			respondWithError(w, http.StatusNotFound, "wallet not found")
+			return
+		}
+		if err.Error() == "insufficient balance" {
+			respondWithError(w, http.StatusBadRequest, "insufficient balance")
+			return
+		}
+		if err.Error() == "transaction type not registered" || err.Error() == "transaction type not supported: handler not registered" {
+			respondWithError(w, http.StatusBadRequest, "invalid transaction type")
+			return
+		}
+
+		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to create transaction: %v", err))
+		return
+	}
+
+	// Convert to response
+	response := toTransactionResponse(transaction)
+
+	if h.audit != nil {
+		h.audit.Record(r.Context(), audit.Event{
+			ActorID:    userID,
+			Action:     audit.ActionTransactionCreate,
+			TargetType: audit.TargetTransaction,
+			TargetID:   transaction.ID.String(),
+			After:      response,
+		})
+	}
+
+	respondWithJSON(w, http.StatusCreated, response)
+}
+
+// manualTransaction is a validated manual transaction request
+type manualTransaction struct {
+	txType     ledger.TransactionType
+	occurredAt time.Time
+	data       map[string]interface{}
+}
+
+// manualTransactionData validates a manual transaction request and builds the
+// raw data for the ledger handler. On failure it writes the error response
+// and returns false.
+func (h *TransactionHandler) manualTransactionData(w http.ResponseWriter, r *http.Request, userID uuid.UUID, req *CreateTransactionRequest) (*manualTransaction, bool) {
 	// Validate transaction type
 	if req.Type == "" {
 		respondWithError(w, http.StatusBadRequest, "transaction type is required")
-		return
+		return nil, false
 	}
 
 	txType := ledger.TransactionType(req.Type)
 	if !txType.IsValid() {
 		respondWithError(w, http.StatusBadRequest, "invalid transaction type")
-		return
+		return nil, false
 	}
 
 	// Parse wallet ID
 	walletID, err := uuid.Parse(req.WalletID)
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "invalid wallet ID")
-		return
+		return nil, false
+	}
+
+	// Recording transactions requires editor rights in the wallet's workspace
+	if _, err := h.transactionService.VerifyWalletAccess(r.Context(), walletID, userID, workspace.RoleEditor); err != nil {
+		if errors.Is(err, transactions.ErrWalletNotFound) {
+			respondWithError(w, http.StatusNotFound, "wallet not found")
+			return nil, false
+		}
+		if errors.Is(err, transactions.ErrAccessDenied) {
+			respondWithError(w, http.StatusForbidden, "access denied")
+			return nil, false
+		}
+		respondWithError(w, http.StatusInternalServerError, "failed to verify wallet access")
+		return nil, false
 	}
 
 	// Parse occurred_at timestamp
 	occurredAt, err := time.Parse(time.RFC3339, req.OccurredAt)
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "invalid occurred_at format (use RFC3339)")
-		return
+		return nil, false
 	}
 
 	// Determine the asset ID to use for price lookup and decimals
@@ -155,7 +246,7 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 	amount, err := money.ToBaseUnits(req.Amount, decimals)
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "invalid amount format")
-		return
+		return nil, false
 	}
 
 	// Parse USD rate if provided (always use 8 decimals for USD)
@@ -164,7 +255,7 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 		usdRate, err = money.ToBaseUnits(*req.USDRate, 8)
 		if err != nil {
 			respondWithError(w, http.StatusBadRequest, "invalid USD rate format")
-			return
+			return nil, false
 		}
 	}
 
@@ -192,33 +283,214 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 		}
 	}
 
-	// Record transaction via ledger service
-	transaction, err := h.ledgerService.RecordTransaction(r.Context(), txType, "manual", nil, occurredAt, txData)
+	return &manualTransaction{txType: txType, occurredAt: occurredAt, data: txData}, true
+}
+
+// UpdateTransaction handles PUT /transactions/{id}
+// Manual transactions are amended by replacement: the original is reversed and
+// the corrected version recorded under a new ID with the next version.
+// Only types with a registered ledger handler can be amended.
+func (h *TransactionHandler) UpdateTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
-		// Handle specific errors
-		if err.Error() == "wallet not found" {
-			respondWithError(w, http.StatusNotFound, "wallet not found")
+		respondWithError(w, http.StatusBadRequest, "invalid transaction ID")
+		return
+	}
+
+	var req UpdateTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Version < 1 {
+		respondWithError(w, http.StatusBadRequest, "version is required")
+		return
+	}
+
+	original, err := h.transactionService.GetTransaction(r.Context(), id, userID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	if original.Source != "manual" {
+		respondWithError(w, http.StatusBadRequest, "only manual transactions can be edited")
+		return
+	}
+	if !isEditableTransactionType(ledger.TransactionType(original.Type)) {
+		respondWithError(w, http.StatusBadRequest, "transaction type cannot be edited")
+		return
+	}
+	if req.Type == "" {
+		req.Type = original.Type
+	}
+	if req.Type != original.Type {
+		respondWithError(w, http.StatusBadRequest, "transaction type cannot be changed")
+		return
+	}
+
+	// Editing removes the original from its wallet, so it needs editor rights there too
+	originalWalletID, err := uuid.Parse(original.WalletID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to verify wallet access")
+		return
+	}
+	if _, err := h.transactionService.VerifyWalletAccess(r.Context(), originalWalletID, userID, workspace.RoleEditor); err != nil {
+		if errors.Is(err, transactions.ErrAccessDenied) {
+			respondWithError(w, http.StatusForbidden, "access denied")
 			return
 		}
-		if err.Error() == "insufficient balance" {
+		respondWithError(w, http.StatusInternalServerError, "failed to verify wallet access")
+		return
+	}
+
+	input, ok := h.manualTransactionData(w, r, userID, &req.CreateTransactionRequest)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.ledgerService.AmendTransaction(r.Context(), id, req.Version, input.occurredAt, input.data)
+	if err != nil {
+		var negErr *ledger.NegativeBalanceError
+		switch {
+		case errors.Is(err, ledger.ErrVersionConflict), errors.Is(err, ledger.ErrTransactionAlreadyReversed):
+			respondWithError(w, http.StatusConflict, "transaction was modified, reload and try again")
+		case errors.Is(err, ledger.ErrLotConsumed):
+			respondWithError(w, http.StatusConflict, "tax lots from this transaction were consumed by an earlier-dated disposal")
+		case errors.Is(err, ledger.ErrTransactionNotReversible):
+			respondWithError(w, http.StatusBadRequest, "transaction cannot be edited")
+		case errors.As(err, &negErr):
 			respondWithError(w, http.StatusBadRequest, "insufficient balance")
+		case err.Error() == "transaction type not supported: handler not registered":
+			respondWithError(w, http.StatusBadRequest, "invalid transaction type")
+		default:
+			respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to update transaction: %v", err))
+		}
+		return
+	}
+
+	response := toTransactionResponse(transaction)
+
+	if h.audit != nil {
+		h.audit.Record(r.Context(), audit.Event{
+			ActorID:    userID,
+			Action:     audit.ActionTransactionUpdate,
+			TargetType: audit.TargetTransaction,
+			TargetID:   id.String(),
+			Before:     original,
+			After:      response,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// ReverseTransaction handles POST /transactions/{id}/reverse
+// It voids a manual transaction by posting a compensating transaction; the
+// original stays in the ledger. Returns the reversal.
+func (h *TransactionHandler) ReverseTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid transaction ID")
+		return
+	}
+
+	var req ReverseTransactionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid request body")
 			return
 		}
-		if err.Error() == "transaction type not registered" || err.Error() == "transaction type not supported: handler not registered" {
-			respondWithError(w, http.StatusBadRequest, "invalid transaction type")
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if len(reason) > 500 {
+		respondWithError(w, http.StatusBadRequest, "reason must be at most 500 characters")
+		return
+	}
+
+	original, err := h.transactionService.GetTransaction(r.Context(), id, userID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	if original.Source != "manual" {
+		respondWithError(w, http.StatusBadRequest, "only manual transactions can be reversed")
+		return
+	}
+
+	walletID, err := uuid.Parse(original.WalletID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to verify wallet access")
+		return
+	}
+	if _, err := h.transactionService.VerifyWalletAccess(r.Context(), walletID, userID, workspace.RoleEditor); err != nil {
+		if errors.Is(err, transactions.ErrAccessDenied) {
+			respondWithError(w, http.StatusForbidden, "access denied")
 			return
 		}
+		respondWithError(w, http.StatusInternalServerError, "failed to verify wallet access")
+		return
+	}
 
-		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to create transaction: %v", err))
+	reversal, err := h.ledgerService.ReverseTransaction(r.Context(), id, reason)
+	if err != nil {
+		var negErr *ledger.NegativeBalanceError
+		switch {
+		case errors.Is(err, ledger.ErrTransactionAlreadyReversed):
+			respondWithError(w, http.StatusConflict, "transaction is already reversed")
+		case errors.Is(err, ledger.ErrLotConsumed):
+			respondWithError(w, http.StatusConflict, "tax lots from this transaction were consumed by a later disposal")
+		case errors.Is(err, ledger.ErrTransactionNotReversible):
+			respondWithError(w, http.StatusBadRequest, "transaction cannot be reversed")
+		case errors.As(err, &negErr):
+			respondWithError(w, http.StatusBadRequest, "insufficient balance")
+		default:
+			respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to reverse transaction: %v", err))
+		}
 		return
 	}
 
-	// Convert to response
-	response := toTransactionResponse(transaction)
-	respondWithJSON(w, http.StatusCreated, response)
+	response := toTransactionResponse(reversal)
+
+	if h.audit != nil {
+		h.audit.Record(r.Context(), audit.Event{
+			ActorID:    userID,
+			Action:     audit.ActionTransactionReverse,
+			TargetType: audit.TargetTransaction,
+			TargetID:   id.String(),
+			Before:     original,
+			After:      response,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// isEditableTransactionType reports whether manual transactions of the type can
+// be amended. Amendment rebuilds the entries through the type's ledger handler,
+// and only asset adjustments have one registered.
+func isEditableTransactionType(t ledger.TransactionType) bool {
+	return t == ledger.TxTypeAssetAdjustment
 }
 
 // GetTransactions handles GET /transactions
+//
+// Filters: wallet_id, type, tag_id, asset, chain, protocol, tx_hash,
+// counterparty, direction, min_usd, max_usd, q (free text), start_date and
+// end_date. Sorting: sort=occurred_at|recorded_at, order=desc|asc.
+// Pages with page/page_size, or with the returned next_cursor, which stays
+// stable while new transactions arrive.
 func (h *TransactionHandler) GetTransactions(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
@@ -267,6 +539,76 @@ func (h *TransactionHandler) GetTransactions(w http.ResponseWriter, r *http.Requ
 		filters.Type = &txType
 	}
 
+	if tagID := query.Get("tag_id"); tagID != "" {
+		tagUUID, err := uuid.Parse(tagID)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid tag_id")
+			return
+		}
+		filters.TagID = &tagUUID
+	}
+
+	for param, target := range map[string]**string{
+		"asset":        &filters.AssetID,
+		"chain":        &filters.ChainID,
+		"protocol":     &filters.Protocol,
+		"tx_hash":      &filters.TxHash,
+		"counterparty": &filters.Counterparty,
+		"q":            &filters.Search,
+	} {
+		if v := strings.TrimSpace(query.Get(param)); v != "" {
+			*target = &v
+		}
+	}
+
+	if direction := query.Get("direction"); direction != "" {
+		if len(ledger.TransactionTypesWithDirection(direction)) == 0 {
+			respondWithError(w, http.StatusBadRequest, "invalid direction (use in, out, internal, swap or adjustment)")
+			return
+		}
+		filters.Direction = &direction
+	}
+
+	for param, target := range map[string]**big.Int{
+		"min_usd": &filters.MinUSD,
+		"max_usd": &filters.MaxUSD,
+	} {
+		if v := query.Get(param); v != "" {
+			usd, err := money.ToBaseUnits(v, 8)
+			if err != nil {
+				respondWithError(w, http.StatusBadRequest, "invalid "+param)
+				return
+			}
+			*target = usd
+		}
+	}
+
+	if sortBy := ledger.TransactionSortField(query.Get("sort")); sortBy != "" {
+		if !sortBy.IsValid() {
+			respondWithError(w, http.StatusBadRequest, "invalid sort (use occurred_at or recorded_at)")
+			return
+		}
+		filters.SortBy = sortBy
+	}
+
+	switch query.Get("order") {
+	case "", "desc":
+	case "asc":
+		filters.SortAsc = true
+	default:
+		respondWithError(w, http.StatusBadRequest, "invalid order (use asc or desc)")
+		return
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		c, err := ledger.ParseTransactionCursor(cursor)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		filters.Cursor = c
+	}
+
 	if startDate != "" {
 		if _, err := time.Parse(time.RFC3339, startDate); err != nil {
 			respondWithError(w, http.StatusBadRequest, "invalid start_date format (use RFC3339)")
@@ -284,14 +626,12 @@ func (h *TransactionHandler) GetTransactions(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Get enriched transactions via transaction service
-	txns, err := h.transactionService.ListTransactions(r.Context(), filters)
+	list, err := h.transactionService.ListTransactions(r.Context(), filters)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "failed to fetch transactions")
 		return
 	}
-
-	// For now, total is just the length of results. In production, we'd need a count query
-	total := len(txns)
+	txns := list.Items
 
 	// Convert DTOs to response
 	txResponses := make([]TransactionListItemResponse, len(txns))
@@ -316,9 +656,10 @@ func (h *TransactionHandler) GetTransactions(w http.ResponseWriter, r *http.Requ
 
 	response := TransactionListResponse{
 		Transactions: txResponses,
-		Total:        total,
+		Total:        list.Total,
 		Page:         page,
 		PageSize:     pageSize,
+		NextCursor:   list.NextCursor,
 	}
 
 	respondWithJSON(w, http.StatusOK, response)
@@ -365,6 +706,7 @@ func toTransactionResponse(tx *ledger.Transaction) TransactionResponse {
 		Source:       tx.Source,
 		ExternalID:   tx.ExternalID,
 		Status:       string(tx.Status),
+		Version:      tx.Version,
 		OccurredAt:   tx.OccurredAt.Format(time.RFC3339),
 		RecordedAt:   tx.RecordedAt.Format(time.RFC3339),
 		RawData:      tx.RawData,
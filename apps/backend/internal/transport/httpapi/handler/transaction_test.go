@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/kislikjeka/moontrack/internal/ledger"
+	"github.com/kislikjeka/moontrack/internal/module/transactions"
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
+	"github.com/kislikjeka/moontrack/internal/platform/wallet"
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
+	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+)
+
+type mockLedgerService struct {
+	mock.Mock
+}
+
+func (m *mockLedgerService) RecordTransaction(ctx context.Context, transactionType ledger.TransactionType, source string, externalID *string, occurredAt time.Time, rawData map[string]interface{}) (*ledger.Transaction, error) {
+	args := m.Called(ctx, transactionType, source, externalID, occurredAt, rawData)
+	tx, _ := args.Get(0).(*ledger.Transaction)
+	return tx, args.Error(1)
+}
+
+func (m *mockLedgerService) AmendTransaction(ctx context.Context, txID uuid.UUID, expectedVersion int, occurredAt time.Time, rawData map[string]interface{}) (*ledger.Transaction, error) {
+	args := m.Called(ctx, txID, expectedVersion, occurredAt, rawData)
+	tx, _ := args.Get(0).(*ledger.Transaction)
+	return tx, args.Error(1)
+}
+
+func (m *mockLedgerService) ReverseTransaction(ctx context.Context, txID uuid.UUID, reason string) (*ledger.Transaction, error) {
+	args := m.Called(ctx, txID, reason)
+	tx, _ := args.Get(0).(*ledger.Transaction)
+	return tx, args.Error(1)
+}
+
+type mockRecorder struct {
+	events []audit.Event
+}
+
+func (r *mockRecorder) Record(_ context.Context, event audit.Event) {
+	r.events = append(r.events, event)
+}
+
+type mockTransactionService struct {
+	mock.Mock
+}
+
+func (m *mockTransactionService) ListTransactions(ctx context.Context, filters ledger.TransactionFilters) (*transactions.TransactionList, error) {
+	args := m.Called(ctx, filters)
+	list, _ := args.Get(0).(*transactions.TransactionList)
+	return list, args.Error(1)
+}
+
+func (m *mockTransactionService) GetTransaction(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*transactions.TransactionDetail, error) {
+	args := m.Called(ctx, id, userID)
+	detail, _ := args.Get(0).(*transactions.TransactionDetail)
+	return detail, args.Error(1)
+}
+
+func (m *mockTransactionService) VerifyWalletAccess(ctx context.Context, walletID, userID uuid.UUID, required workspace.Role) (*wallet.Wallet, error) {
+	args := m.Called(ctx, walletID, userID, required)
+	w, _ := args.Get(0).(*wallet.Wallet)
+	return w, args.Error(1)
+}
+
+func updateTransactionRequest(txID, userID uuid.UUID, body string) *http.Request {
+	return transactionRequest(http.MethodPut, "/transactions/"+txID.String(), txID, userID, body)
+}
+
+func transactionRequest(method, target string, txID, userID uuid.UUID, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", txID.String())
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, middleware.UserIDKey, userID)
+	return r.WithContext(ctx)
+}
+
+func TestUpdateTransaction_RejectsTypesWithoutLedgerHandler(t *testing.T) {
+	for _, txType := range []ledger.TransactionType{ledger.TxTypeManualIncome, ledger.TxTypeManualOutcome} {
+		t.Run(string(txType), func(t *testing.T) {
+			txID, userID := uuid.New(), uuid.New()
+			ledgerSvc := &mockLedgerService{}
+			txSvc := &mockTransactionService{}
+			txSvc.On("GetTransaction", mock.Anything, txID, userID).Return(&transactions.TransactionDetail{
+				TransactionListItem: transactions.TransactionListItem{ID: txID.String(), Type: string(txType)},
+				Source:              "manual",
+				Version:             1,
+			}, nil)
+
+			h := NewTransactionHandler(ledgerSvc, txSvc, nil, nil)
+			w := httptest.NewRecorder()
+			h.UpdateTransaction(w, updateTransactionRequest(txID, userID, `{"amount":"5","version":1}`))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "transaction type cannot be edited")
+			ledgerSvc.AssertNotCalled(t, "AmendTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestReverseTransaction_RecordsAudit(t *testing.T) {
+	txID, userID, walletID := uuid.New(), uuid.New(), uuid.New()
+	ledgerSvc := &mockLedgerService{}
+	txSvc := &mockTransactionService{}
+	txSvc.On("GetTransaction", mock.Anything, txID, userID).Return(&transactions.TransactionDetail{
+		TransactionListItem: transactions.TransactionListItem{ID: txID.String(), Type: string(ledger.TxTypeManualIncome), WalletID: walletID.String()},
+		Source:              "manual",
+		Version:             1,
+	}, nil)
+	txSvc.On("VerifyWalletAccess", mock.Anything, walletID, userID, workspace.RoleEditor).Return(&wallet.Wallet{ID: walletID}, nil)
+	reversal := &ledger.Transaction{ID: uuid.New(), Type: ledger.TxTypeReversal, Source: ledger.ReversalSource, Status: ledger.TransactionStatusCompleted}
+	ledgerSvc.On("ReverseTransaction", mock.Anything, txID, "duplicate entry").Return(reversal, nil)
+
+	recorder := &mockRecorder{}
+	h := NewTransactionHandler(ledgerSvc, txSvc, nil, recorder)
+	w := httptest.NewRecorder()
+	h.ReverseTransaction(w, transactionRequest(http.MethodPost, "/transactions/"+txID.String()+"/reverse", txID, userID, `{"reason":" duplicate entry "}`))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	ledgerSvc.AssertExpectations(t)
+	if assert.Len(t, recorder.events, 1) {
+		assert.Equal(t, audit.ActionTransactionReverse, recorder.events[0].Action)
+		assert.Equal(t, txID.String(), recorder.events[0].TargetID)
+		assert.Equal(t, userID, recorder.events[0].ActorID)
+	}
+}
+
+func TestReverseTransaction_RejectsSyncedTransactions(t *testing.T) {
+	txID, userID := uuid.New(), uuid.New()
+	ledgerSvc := &mockLedgerService{}
+	txSvc := &mockTransactionService{}
+	txSvc.On("GetTransaction", mock.Anything, txID, userID).Return(&transactions.TransactionDetail{
+		TransactionListItem: transactions.TransactionListItem{ID: txID.String(), Type: "transfer_in"},
+		Source:              "zerion",
+	}, nil)
+
+	h := NewTransactionHandler(ledgerSvc, txSvc, nil, nil)
+	w := httptest.NewRecorder()
+	h.ReverseTransaction(w, transactionRequest(http.MethodPost, "/transactions/"+txID.String()+"/reverse", txID, userID, ``))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	ledgerSvc.AssertNotCalled(t, "ReverseTransaction", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
 	"github.com/kislikjeka/moontrack/internal/platform/wallet"
 	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
 )
@@ -18,6 +19,7 @@ import (
 type WalletServiceInterface interface {
 	Create(ctx context.Context, w *wallet.Wallet) (*wallet.Wallet, error)
 	List(ctx context.Context, userID uuid.UUID) ([]*wallet.Wallet, error)
+	ListByWorkspace(ctx context.Context, userID, workspaceID uuid.UUID) ([]*wallet.Wallet, error)
 	GetByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*wallet.Wallet, error)
 	Update(ctx context.Context, w *wallet.Wallet, userID uuid.UUID) (*wallet.Wallet, error)
 	Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
@@ -32,20 +34,23 @@ type SyncServiceInterface interface {
 type WalletHandler struct {
 	walletService WalletServiceInterface
 	syncService   SyncServiceInterface
+	audit         audit.Recorder // optional
 }
 
 // NewWalletHandler creates a new wallet handler
-func NewWalletHandler(walletService WalletServiceInterface, syncService SyncServiceInterface) *WalletHandler {
+func NewWalletHandler(walletService WalletServiceInterface, syncService SyncServiceInterface, recorder audit.Recorder) *WalletHandler {
 	return &WalletHandler{
 		walletService: walletService,
 		syncService:   syncService,
+		audit:         recorder,
 	}
 }
 
 // CreateWalletRequest represents the wallet creation request
 type CreateWalletRequest struct {
-	Name    string `json:"name"`
-	Address string `json:"address"`
+	Name        string `json:"name"`
+	Address     string `json:"address"`
+	WorkspaceID string `json:"workspace_id,omitempty"` // Defaults to the user's personal workspace
 }
 
 // UpdateWalletRequest represents the wallet update request
@@ -57,6 +62,7 @@ type UpdateWalletRequest struct {
 type WalletResponse struct {
 	ID              string   `json:"id"`
 	UserID          string   `json:"user_id"`
+	WorkspaceID     string   `json:"workspace_id"`
 	Name            string   `json:"name"`
 	Address         string   `json:"address"`
 	SupportedChains []string `json:"supported_chains"`
@@ -94,6 +100,15 @@ func (h *WalletHandler) CreateWallet(w http.ResponseWriter, r *http.Request) {
 		Address: req.Address,
 	}
 
+	if req.WorkspaceID != "" {
+		workspaceID, err := uuid.Parse(req.WorkspaceID)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid workspace_id")
+			return
+		}
+		wlt.WorkspaceID = workspaceID
+	}
+
 	// Create wallet via service
 	createdWallet, err := h.walletService.Create(r.Context(), wlt)
 	if err != nil {
@@ -121,6 +136,10 @@ func (h *WalletHandler) CreateWallet(w http.ResponseWriter, r *http.Request) {
 			respondWithError(w, http.StatusUnauthorized, "user not found, please re-login")
 			return
 		}
+		if errors.Is(err, wallet.ErrUnauthorizedAccess) {
+			respondWithError(w, http.StatusForbidden, "access denied")
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, fmt.Sprintf("failed to create wallet: %v", err))
 		return
 	}
@@ -139,9 +158,24 @@ func (h *WalletHandler) GetWallets(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get wallets via service
-	wallets, err := h.walletService.List(r.Context(), userID)
+	// Get wallets via service, optionally scoped to one workspace
+	var wallets []*wallet.Wallet
+	var err error
+	if wsID := r.URL.Query().Get("workspace_id"); wsID != "" {
+		workspaceID, parseErr := uuid.Parse(wsID)
+		if parseErr != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid workspace_id")
+			return
+		}
+		wallets, err = h.walletService.ListByWorkspace(r.Context(), userID, workspaceID)
+	} else {
+		wallets, err = h.walletService.List(r.Context(), userID)
+	}
 	if err != nil {
+		if errors.Is(err, wallet.ErrUnauthorizedAccess) {
+			respondWithError(w, http.StatusForbidden, "access denied")
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "failed to fetch wallets")
 		return
 	}
@@ -301,7 +335,7 @@ func (h *WalletHandler) TriggerSync(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verify wallet belongs to user
+	// Verify the user can view the wallet (any workspace member may trigger a sync)
 	_, err = h.walletService.GetByID(r.Context(), walletID, userID)
 	if err != nil {
 		if errors.Is(err, wallet.ErrWalletNotFound) {
@@ -312,13 +346,22 @@ func (h *WalletHandler) TriggerSync(w http.ResponseWriter, r *http.Request) {
 			respondWithError(w, http.StatusForbidden, "access denied")
 			return
 		}
-		respondWithError(w, http.StatusInternalServerError, "failed to verify wallet ownership")
+		respondWithError(w, http.StatusInternalServerError, "failed to verify wallet access")
 		return
 	}
 
 	// Trigger sync in background (collect phase can take minutes for large wallets)
 	go h.syncService.SyncWallet(context.Background(), walletID)
 
+	if h.audit != nil {
+		h.audit.Record(r.Context(), audit.Event{
+			ActorID:    userID,
+			Action:     audit.ActionWalletSync,
+			TargetType: audit.TargetWallet,
+			TargetID:   walletID.String(),
+		})
+	}
+
 	respondWithJSON(w, http.StatusAccepted, map[string]string{"status": "sync started"})
 }
 
@@ -327,6 +370,7 @@ func toWalletResponse(wlt *wallet.Wallet) WalletResponse {
 	resp := WalletResponse{
 		ID:              wlt.ID.String(),
 		UserID:          wlt.UserID.String(),
+		WorkspaceID:     wlt.WorkspaceID.String(),
 		Name:            wlt.Name,
 		Address:         wlt.Address,
 		SupportedChains: wallet.GetSupportedChains(),
@@ -0,0 +1,349 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/kislikjeka/moontrack/internal/platform/workspace"
+	"github.com/kislikjeka/moontrack/internal/transport/httpapi/middleware"
+)
+
+// WorkspaceServiceInterface defines workspace operations for the HTTP handler
+type WorkspaceServiceInterface interface {
+	Create(ctx context.Context, ownerID uuid.UUID, name string) (*workspace.Workspace, error)
+	List(ctx context.Context, userID uuid.UUID) ([]*workspace.Workspace, error)
+	Get(ctx context.Context, workspaceID, userID uuid.UUID) (*workspace.Workspace, error)
+	ListMembers(ctx context.Context, workspaceID, userID uuid.UUID) ([]*workspace.Member, error)
+	ListInvitations(ctx context.Context, workspaceID, userID uuid.UUID) ([]*workspace.Invitation, error)
+	Invite(ctx context.Context, workspaceID, inviterID uuid.UUID, email string, role workspace.Role) (*workspace.Invitation, error)
+	AcceptInvitation(ctx context.Context, token string, userID uuid.UUID, email string) (*workspace.Workspace, error)
+	UpdateMemberRole(ctx context.Context, workspaceID, actorID, memberID uuid.UUID, role workspace.Role) error
+	RemoveMember(ctx context.Context, workspaceID, actorID, memberID uuid.UUID) error
+}
+
+// WorkspaceHandler handles workspace HTTP requests
+type WorkspaceHandler struct {
+	svc WorkspaceServiceInterface
+}
+
+// NewWorkspaceHandler creates a new workspace handler
+func NewWorkspaceHandler(svc WorkspaceServiceInterface) *WorkspaceHandler {
+	return &WorkspaceHandler{svc: svc}
+}
+
+// CreateWorkspaceRequest represents the workspace creation request
+type CreateWorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+// InviteMemberRequest represents the invitation request
+type InviteMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// UpdateMemberRoleRequest represents the member role change request
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// WorkspaceResponse represents a workspace in the API response
+type WorkspaceResponse struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	OwnerID    string `json:"owner_id"`
+	IsPersonal bool   `json:"is_personal"`
+	Role       string `json:"role"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// WorkspaceMemberResponse represents a workspace member in the API response
+type WorkspaceMemberResponse struct {
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"created_at"`
+}
+
+// WorkspaceInvitationResponse represents an invitation in the API response
+type WorkspaceInvitationResponse struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	ExpiresAt string `json:"expires_at"`
+	Token     string `json:"token,omitempty"` // Only returned on creation so the link can be shared manually
+}
+
+// ListWorkspaces handles GET /workspaces
+func (h *WorkspaceHandler) ListWorkspaces(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	workspaces, err := h.svc.List(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to fetch workspaces")
+		return
+	}
+
+	resp := make([]WorkspaceResponse, 0, len(workspaces))
+	for _, ws := range workspaces {
+		resp = append(resp, toWorkspaceResponse(ws))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// CreateWorkspace handles POST /workspaces
+func (h *WorkspaceHandler) CreateWorkspace(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req CreateWorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	ws, err := h.svc.Create(r.Context(), userID, req.Name)
+	if err != nil {
+		if errors.Is(err, workspace.ErrMissingName) || errors.Is(err, workspace.ErrNameTooLong) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "failed to create workspace")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, toWorkspaceResponse(ws))
+}
+
+// GetWorkspace handles GET /workspaces/{id}
+func (h *WorkspaceHandler) GetWorkspace(w http.ResponseWriter, r *http.Request) {
+	userID, workspaceID, ok := h.parseWorkspaceRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ws, err := h.svc.Get(r.Context(), workspaceID, userID)
+	if err != nil {
+		respondWorkspaceError(w, err, "failed to fetch workspace")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toWorkspaceResponse(ws))
+}
+
+// ListMembers handles GET /workspaces/{id}/members
+func (h *WorkspaceHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	userID, workspaceID, ok := h.parseWorkspaceRequest(w, r)
+	if !ok {
+		return
+	}
+
+	members, err := h.svc.ListMembers(r.Context(), workspaceID, userID)
+	if err != nil {
+		respondWorkspaceError(w, err, "failed to fetch members")
+		return
+	}
+
+	resp := make([]WorkspaceMemberResponse, 0, len(members))
+	for _, m := range members {
+		resp = append(resp, WorkspaceMemberResponse{
+			UserID:    m.UserID.String(),
+			Email:     m.Email,
+			Role:      string(m.Role),
+			CreatedAt: m.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// UpdateMemberRole handles PUT /workspaces/{id}/members/{userId}
+func (h *WorkspaceHandler) UpdateMemberRole(w http.ResponseWriter, r *http.Request) {
+	userID, workspaceID, ok := h.parseWorkspaceRequest(w, r)
+	if !ok {
+		return
+	}
+
+	memberID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	var req UpdateMemberRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.svc.UpdateMemberRole(r.Context(), workspaceID, userID, memberID, workspace.Role(req.Role)); err != nil {
+		respondWorkspaceError(w, err, "failed to update member role")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveMember handles DELETE /workspaces/{id}/members/{userId}
+func (h *WorkspaceHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	userID, workspaceID, ok := h.parseWorkspaceRequest(w, r)
+	if !ok {
+		return
+	}
+
+	memberID, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid user ID")
+		return
+	}
+
+	if err := h.svc.RemoveMember(r.Context(), workspaceID, userID, memberID); err != nil {
+		respondWorkspaceError(w, err, "failed to remove member")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListInvitations handles GET /workspaces/{id}/invitations
+func (h *WorkspaceHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	userID, workspaceID, ok := h.parseWorkspaceRequest(w, r)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.svc.ListInvitations(r.Context(), workspaceID, userID)
+	if err != nil {
+		respondWorkspaceError(w, err, "failed to fetch invitations")
+		return
+	}
+
+	resp := make([]WorkspaceInvitationResponse, 0, len(invitations))
+	for _, inv := range invitations {
+		resp = append(resp, toInvitationResponse(inv, false))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// InviteMember handles POST /workspaces/{id}/invitations
+func (h *WorkspaceHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
+	userID, workspaceID, ok := h.parseWorkspaceRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req InviteMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	inv, err := h.svc.Invite(r.Context(), workspaceID, userID, req.Email, workspace.Role(req.Role))
+	if err != nil {
+		respondWorkspaceError(w, err, "failed to create invitation")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, toInvitationResponse(inv, true))
+}
+
+// AcceptInvitation handles POST /workspaces/invitations/{token}/accept
+func (h *WorkspaceHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	email, _ := middleware.GetUserEmailFromContext(r.Context())
+
+	token := chi.URLParam(r, "token")
+	if token == "" {
+		respondWithError(w, http.StatusBadRequest, "invitation token is required")
+		return
+	}
+
+	ws, err := h.svc.AcceptInvitation(r.Context(), token, userID, email)
+	if err != nil {
+		respondWorkspaceError(w, err, "failed to accept invitation")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toWorkspaceResponse(ws))
+}
+
+func (h *WorkspaceHandler) parseWorkspaceRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	workspaceID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid workspace ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, workspaceID, true
+}
+
+// respondWorkspaceError maps workspace domain errors to HTTP status codes
+func respondWorkspaceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, workspace.ErrNotMember), errors.Is(err, workspace.ErrWorkspaceNotFound):
+		respondWithError(w, http.StatusNotFound, "workspace not found")
+	case errors.Is(err, workspace.ErrInsufficientRole):
+		respondWithError(w, http.StatusForbidden, "access denied")
+	case errors.Is(err, workspace.ErrInvalidRole),
+		errors.Is(err, workspace.ErrInvalidEmail),
+		errors.Is(err, workspace.ErrPersonalWorkspace),
+		errors.Is(err, workspace.ErrOwnerImmutable):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, workspace.ErrMemberNotFound), errors.Is(err, workspace.ErrInvitationNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, workspace.ErrAlreadyMember):
+		respondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, workspace.ErrInvitationExpired):
+		respondWithError(w, http.StatusGone, err.Error())
+	case errors.Is(err, workspace.ErrInvitationEmail):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+func toWorkspaceResponse(ws *workspace.Workspace) WorkspaceResponse {
+	return WorkspaceResponse{
+		ID:         ws.ID.String(),
+		Name:       ws.Name,
+		OwnerID:    ws.OwnerID.String(),
+		IsPersonal: ws.IsPersonal,
+		Role:       string(ws.Role),
+		CreatedAt:  ws.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toInvitationResponse(inv *workspace.Invitation, includeToken bool) WorkspaceInvitationResponse {
+	resp := WorkspaceInvitationResponse{
+		ID:        inv.ID.String(),
+		Email:     inv.Email,
+		Role:      string(inv.Role),
+		ExpiresAt: inv.ExpiresAt.Format(time.RFC3339),
+	}
+	if includeToken {
+		resp.Token = inv.Token
+	}
+	return resp
+}
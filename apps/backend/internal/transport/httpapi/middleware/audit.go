@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/kislikjeka/moontrack/internal/platform/audit"
+)
+
+// AuditContext attaches the client IP and user agent to the request context
+// so audited services can record where a mutation came from. It runs after
+// ClientIP.
+func AuditContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := audit.WithRequestMeta(r.Context(), audit.RequestMeta{
+			IP:        clientIP(r),
+			UserAgent: r.UserAgent(),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
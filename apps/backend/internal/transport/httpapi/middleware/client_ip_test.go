@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resolvedIP(t *testing.T, trusted []string, remoteAddr string, forwardedFor ...string) string {
+	t.Helper()
+	prefixes, err := ParseTrustedProxies(trusted)
+	require.NoError(t, err)
+
+	var got string
+	h := ClientIP(prefixes)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = clientIP(r)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	for _, v := range forwardedFor {
+		req.Header.Add("X-Forwarded-For", v)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	return got
+}
+
+func TestClientIP_IgnoresForwardedForFromUntrustedPeer(t *testing.T) {
+	assert.Equal(t, "198.51.100.4", resolvedIP(t, nil, "198.51.100.4:5555", "203.0.113.9"))
+	assert.Equal(t, "198.51.100.4", resolvedIP(t, []string{"10.0.0.0/8"}, "198.51.100.4:5555", "203.0.113.9"))
+}
+
+func TestClientIP_TrustedProxyChain(t *testing.T) {
+	trusted := []string{"10.0.0.0/8", "172.16.0.1"}
+
+	// The client-supplied leftmost hop is ignored: the first untrusted hop
+	// from the right is the address the trusted proxy saw
+	assert.Equal(t, "203.0.113.9", resolvedIP(t, trusted, "10.0.0.2:443", "1.2.3.4, 203.0.113.9"))
+	assert.Equal(t, "203.0.113.9", resolvedIP(t, trusted, "10.0.0.2:443", "1.2.3.4, 203.0.113.9", "172.16.0.1"))
+
+	// Without a forwarded hop the proxy itself is the client
+	assert.Equal(t, "10.0.0.2", resolvedIP(t, trusted, "10.0.0.2:443"))
+}
+
+func TestClientIP_RotatingForwardedForSharesRateLimitKey(t *testing.T) {
+	store := &memoryStore{counts: map[string]int{}}
+	h := ClientIP(nil)(newLimitedHandler(store))
+
+	for _, fwd := range []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"} {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.RemoteAddr = "198.51.100.4:5555"
+		req.Header.Set("X-Forwarded-For", fwd)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, 2, store.counts["test:ip:198.51.100.4"])
+	assert.Len(t, store.counts, 1)
+}
+
+func TestParseTrustedProxies_RejectsInvalid(t *testing.T) {
+	_, err := ParseTrustedProxies([]string{"not-an-ip"})
+	assert.Error(t, err)
+}
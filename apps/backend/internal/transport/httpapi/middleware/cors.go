@@ -25,6 +25,11 @@ func CORS(allowedOrigins []string) func(http.Handler) http.Handler {
 		},
 		ExposedHeaders: []string{
 			"Link",
+			"RateLimit-Limit",
+			"RateLimit-Remaining",
+			"RateLimit-Reset",
+			"RateLimit-Policy",
+			"Retry-After",
 		},
 		AllowCredentials: true,
 		MaxAge:           300, // 5 minutes
@@ -61,13 +61,7 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	go rl.cleanupVisitors()
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Get IP address (handle X-Forwarded-For for proxies)
-		ip := r.Header.Get("X-Forwarded-For")
-		if ip == "" {
-			ip = r.RemoteAddr
-		}
-
-		limiter := rl.getVisitor(ip)
+		limiter := rl.getVisitor(clientIP(r))
 		if !limiter.Allow() {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusTooManyRequests)
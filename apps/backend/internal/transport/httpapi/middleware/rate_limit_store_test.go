@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kislikjeka/moontrack/pkg/logger"
+)
+
+// memoryStore is a fixed-window RateLimitStore for tests
+type memoryStore struct {
+	counts map[string]int
+	err    error
+}
+
+func (s *memoryStore) Allow(_ context.Context, key string, limit int, window time.Duration) (bool, int, time.Duration, error) {
+	if s.err != nil {
+		return false, 0, 0, s.err
+	}
+	if s.counts[key] >= limit {
+		return false, 0, window, nil
+	}
+	s.counts[key]++
+	return true, limit - s.counts[key], window, nil
+}
+
+func newLimitedHandler(store RateLimitStore) http.Handler {
+	policy := RateLimitPolicy{Name: "test", Limit: 2, Window: 1500 * time.Millisecond}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	return DistributedRateLimit(store, policy, logger.New("test", io.Discard))(ok)
+}
+
+func TestDistributedRateLimit_HeadersAndRejection(t *testing.T) {
+	store := &memoryStore{counts: map[string]int{}}
+	h := newLimitedHandler(store)
+
+	for i, wantRemaining := range []string{"1", "0"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusOK, rec.Code, "request %d", i)
+		assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, wantRemaining, rec.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "2", rec.Header().Get("RateLimit-Reset"), "reset rounds up")
+		assert.Equal(t, "2;w=1", rec.Header().Get("RateLimit-Policy"))
+		assert.Empty(t, rec.Header().Get("Retry-After"))
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+}
+
+func TestDistributedRateLimit_KeysByUserThenIP(t *testing.T) {
+	store := &memoryStore{counts: map[string]int{}}
+	h := newLimitedHandler(store)
+
+	userID := uuid.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "198.51.100.4:5555"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	h.ServeHTTP(httptest.NewRecorder(), req.WithContext(context.WithValue(req.Context(), UserIDKey, userID)))
+
+	assert.Equal(t, 1, store.counts["test:ip:198.51.100.4"])
+	assert.Equal(t, 1, store.counts["test:user:"+userID.String()])
+}
+
+func TestDistributedRateLimit_FailsOpen(t *testing.T) {
+	h := newLimitedHandler(&memoryStore{err: errors.New("connection refused")})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+}
@@ -2,6 +2,7 @@ package httpapi
 
 import (
 	"net/http"
+	"net/netip"
 
 	"github.com/go-chi/chi/v5"
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
@@ -24,7 +25,23 @@ type Config struct {
 	TaxLotHandler      *handler.TaxLotHandler
 	LPPositionHandler      *handler.LPPositionHandler
 	LendingPositionHandler *handler.LendingPositionHandler
+	StakingPositionHandler *handler.StakingPositionHandler
+	DerivativePositionHandler *handler.DerivativePositionHandler
+	WorkspaceHandler       *handler.WorkspaceHandler
+	ShareLinkHandler       *handler.ShareLinkHandler
+	AuditHandler           *handler.AuditHandler
+	PrivacyHandler         *handler.PrivacyHandler
+	TagHandler             *handler.TagHandler
+	IncomeHandler          *handler.IncomeHandler
+	TaxAnalysisHandler     *handler.TaxAnalysisHandler
+	TaxProfileHandler      *handler.TaxProfileHandler
 	JWTMiddleware          func(http.Handler) http.Handler
+	// RateLimitStore enables distributed per-user, per-route-group quotas on
+	// top of the per-process IP limiter. When nil, only the IP limiter applies.
+	RateLimitStore middleware.RateLimitStore
+	// TrustedProxies are the proxies whose X-Forwarded-For header is believed
+	// when resolving the client IP. When empty, the connection address is used.
+	TrustedProxies []netip.Prefix
 }
 
 // NewRouter creates a new HTTP router
@@ -35,8 +52,18 @@ func NewRouter(cfg Config) *chi.Mux {
 	r.Use(chimiddleware.RequestID)
 	r.Use(middleware.Recovery(cfg.Logger))
 	r.Use(middleware.Logger(cfg.Logger))
+	r.Use(middleware.ClientIP(cfg.TrustedProxies))
+	r.Use(middleware.AuditContext)
 	r.Use(middleware.CORS(cfg.AllowedOrigins))
 	r.Use(chimiddleware.Compress(5))
+
+	// limit applies a route group quota when a distributed store is configured
+	limit := func(policy middleware.RateLimitPolicy) func(http.Handler) http.Handler {
+		if cfg.RateLimitStore == nil {
+			return func(next http.Handler) http.Handler { return next }
+		}
+		return middleware.DistributedRateLimit(cfg.RateLimitStore, policy, cfg.Logger)
+	}
 	r.Use(middleware.RateLimit()) // Rate limiting: 100 req/s with burst of 20
 
 	// Health check endpoints (no authentication required)
@@ -57,14 +84,26 @@ func NewRouter(cfg Config) *chi.Mux {
 	r.Route("/api/v1", func(r chi.Router) {
 		// Auth routes (public - no authentication required)
 		if cfg.AuthHandler != nil {
-			r.Post("/auth/register", cfg.AuthHandler.Register)
-			r.Post("/auth/login", cfg.AuthHandler.Login)
+			r.With(limit(middleware.RateLimitAuth)).Post("/auth/register", cfg.AuthHandler.Register)
+			r.With(limit(middleware.RateLimitAuth)).Post("/auth/login", cfg.AuthHandler.Login)
+		}
+
+		// Shared views (public - authorized by the share token in the URL)
+		if cfg.ShareLinkHandler != nil {
+			r.Group(func(r chi.Router) {
+				r.Use(limit(middleware.RateLimitShared))
+				r.Get("/shared/{token}", cfg.ShareLinkHandler.GetSharedInfo)
+				r.Get("/shared/{token}/portfolio", cfg.ShareLinkHandler.GetSharedPortfolio)
+				r.Get("/shared/{token}/wallets", cfg.ShareLinkHandler.GetSharedWallets)
+				r.Get("/shared/{token}/lots", cfg.ShareLinkHandler.GetSharedLots)
+			})
 		}
 
 		// Protected routes (require JWT authentication)
 		if cfg.JWTMiddleware != nil {
 			r.Group(func(r chi.Router) {
 				r.Use(cfg.JWTMiddleware)
+				r.Use(limit(middleware.RateLimitAPI)) // Keyed by user ID, so runs after JWT
 
 				// Wallet routes
 				if cfg.WalletHandler != nil {
@@ -73,7 +112,39 @@ func NewRouter(cfg Config) *chi.Mux {
 					r.Get("/wallets/{id}", cfg.WalletHandler.GetWallet)
 					r.Put("/wallets/{id}", cfg.WalletHandler.UpdateWallet)
 					r.Delete("/wallets/{id}", cfg.WalletHandler.DeleteWallet)
-					r.Post("/wallets/{id}/sync", cfg.WalletHandler.TriggerSync)
+					r.With(limit(middleware.RateLimitSync)).Post("/wallets/{id}/sync", cfg.WalletHandler.TriggerSync)
+				}
+
+				// Workspace routes
+				if cfg.WorkspaceHandler != nil {
+					r.Get("/workspaces", cfg.WorkspaceHandler.ListWorkspaces)
+					r.Post("/workspaces", cfg.WorkspaceHandler.CreateWorkspace)
+					r.Post("/workspaces/invitations/{token}/accept", cfg.WorkspaceHandler.AcceptInvitation)
+					r.Get("/workspaces/{id}", cfg.WorkspaceHandler.GetWorkspace)
+					r.Get("/workspaces/{id}/members", cfg.WorkspaceHandler.ListMembers)
+					r.Put("/workspaces/{id}/members/{userId}", cfg.WorkspaceHandler.UpdateMemberRole)
+					r.Delete("/workspaces/{id}/members/{userId}", cfg.WorkspaceHandler.RemoveMember)
+					r.Get("/workspaces/{id}/invitations", cfg.WorkspaceHandler.ListInvitations)
+					r.Post("/workspaces/{id}/invitations", cfg.WorkspaceHandler.InviteMember)
+				}
+
+				// Share link management routes
+				if cfg.ShareLinkHandler != nil {
+					r.Post("/share-links", cfg.ShareLinkHandler.CreateShareLink)
+					r.Get("/share-links", cfg.ShareLinkHandler.ListShareLinks)
+					r.Delete("/share-links/{id}", cfg.ShareLinkHandler.RevokeShareLink)
+				}
+
+				// Account data routes
+				if cfg.PrivacyHandler != nil {
+					r.Get("/me/export", cfg.PrivacyHandler.ExportData)
+					// Requires the password, so it shares the stricter auth quota
+					r.With(limit(middleware.RateLimitAuth)).Delete("/me", cfg.PrivacyHandler.DeleteAccount)
+				}
+
+				// Audit log routes
+				if cfg.AuditHandler != nil {
+					r.Get("/audit-log", cfg.AuditHandler.ListAuditLog)
 				}
 
 				// Transaction routes
@@ -81,6 +152,41 @@ func NewRouter(cfg Config) *chi.Mux {
 					r.Post("/transactions", cfg.TransactionHandler.CreateTransaction)
 					r.Get("/transactions", cfg.TransactionHandler.GetTransactions)
 					r.Get("/transactions/{id}", cfg.TransactionHandler.GetTransaction)
+					r.Put("/transactions/{id}", cfg.TransactionHandler.UpdateTransaction)
+					r.Post("/transactions/{id}/reverse", cfg.TransactionHandler.ReverseTransaction)
+				}
+
+				// Tag and note routes
+				if cfg.TagHandler != nil {
+					r.Get("/tags", cfg.TagHandler.ListTags)
+					r.Post("/tags", cfg.TagHandler.CreateTag)
+					r.Get("/tags/flows", cfg.TagHandler.GetTagFlows)
+					r.Put("/tags/{id}", cfg.TagHandler.UpdateTag)
+					r.Delete("/tags/{id}", cfg.TagHandler.DeleteTag)
+					r.Get("/transactions/{id}/annotations", cfg.TagHandler.GetAnnotations)
+					r.Put("/transactions/{id}/tags", cfg.TagHandler.SetTransactionTags)
+					r.Put("/transactions/{id}/note", cfg.TagHandler.SetNote)
+				}
+
+				// Income routes
+				if cfg.IncomeHandler != nil {
+					r.Get("/income/report", cfg.IncomeHandler.GetIncomeReport)
+					r.Get("/income/events", cfg.IncomeHandler.GetIncomeEvents)
+					r.Put("/transactions/{id}/income", cfg.IncomeHandler.ClassifyIncome)
+					r.Delete("/transactions/{id}/income", cfg.IncomeHandler.UnclassifyIncome)
+				}
+
+				// Tax analysis routes
+				if cfg.TaxAnalysisHandler != nil {
+					r.Get("/tax/wash-sales", cfg.TaxAnalysisHandler.GetWashSales)
+					r.Get("/tax/harvest", cfg.TaxAnalysisHandler.GetHarvestCandidates)
+				}
+
+				// Tax profile and capital gains routes
+				if cfg.TaxProfileHandler != nil {
+					r.Get("/tax/profile", cfg.TaxProfileHandler.GetTaxProfile)
+					r.Put("/tax/profile", cfg.TaxProfileHandler.SetTaxProfile)
+					r.Get("/tax/capital-gains", cfg.TaxProfileHandler.GetCapitalGainsReport)
 				}
 
 				// Portfolio routes
@@ -93,6 +199,7 @@ func NewRouter(cfg Config) *chi.Mux {
 				if cfg.TaxLotHandler != nil {
 					r.Get("/lots", cfg.TaxLotHandler.GetLots)
 					r.Put("/lots/{id}/override", cfg.TaxLotHandler.OverrideCostBasis)
+					r.Post("/lots/rebuild", cfg.TaxLotHandler.RebuildLots)
 					r.Get("/positions/wac", cfg.TaxLotHandler.GetWAC)
 					r.Get("/transactions/{id}/lots", cfg.TaxLotHandler.GetTransactionLots)
 				}
@@ -101,6 +208,8 @@ func NewRouter(cfg Config) *chi.Mux {
 				if cfg.LPPositionHandler != nil {
 					r.Get("/lp/positions", cfg.LPPositionHandler.ListPositions)
 					r.Get("/lp/positions/{id}", cfg.LPPositionHandler.GetPosition)
+					r.Get("/lp/positions/{id}/performance", cfg.LPPositionHandler.GetPerformance)
+					r.Post("/lp/positions/{id}/simulate", cfg.LPPositionHandler.SimulatePosition)
 				}
 
 					// Lending Position routes
@@ -109,6 +218,18 @@ func NewRouter(cfg Config) *chi.Mux {
 					r.Get("/lending/positions/{id}", cfg.LendingPositionHandler.GetPosition)
 				}
 
+				// Staking Position routes
+				if cfg.StakingPositionHandler != nil {
+					r.Get("/staking/positions", cfg.StakingPositionHandler.ListPositions)
+					r.Get("/staking/positions/{id}", cfg.StakingPositionHandler.GetPosition)
+				}
+
+				// Derivative Position routes
+				if cfg.DerivativePositionHandler != nil {
+					r.Get("/derivatives/positions", cfg.DerivativePositionHandler.ListPositions)
+					r.Get("/derivatives/positions/{id}", cfg.DerivativePositionHandler.GetPosition)
+				}
+
 				// Asset routes (unified)
 				if cfg.AssetHandler != nil {
 					r.Route("/assets", func(r chi.Router) {
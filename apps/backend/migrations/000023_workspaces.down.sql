@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS trg_wallets_assign_workspace ON wallets;
+DROP FUNCTION IF EXISTS assign_personal_workspace();
+
+DROP INDEX IF EXISTS idx_wallets_workspace;
+ALTER TABLE wallets DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_invitations;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
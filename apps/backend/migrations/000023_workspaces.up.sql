@@ -0,0 +1,97 @@
+-- Workspaces: shared portfolios that own wallets
+CREATE TABLE workspaces (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(100) NOT NULL,
+    owner_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    is_personal BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Each user has at most one personal workspace
+CREATE UNIQUE INDEX idx_workspaces_personal ON workspaces(owner_id) WHERE is_personal;
+
+CREATE TABLE workspace_members (
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role         VARCHAR(10) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX idx_workspace_members_user ON workspace_members(user_id);
+
+CREATE TABLE workspace_invitations (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    email        VARCHAR(255) NOT NULL,
+    role         VARCHAR(10) NOT NULL CHECK (role IN ('editor', 'viewer')),
+    token        VARCHAR(64) NOT NULL UNIQUE,
+    invited_by   UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at   TIMESTAMP NOT NULL,
+    accepted_at  TIMESTAMP,
+    accepted_by  UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_workspace_invitations_workspace ON workspace_invitations(workspace_id) WHERE accepted_at IS NULL;
+
+-- Backfill a personal workspace for every existing user
+INSERT INTO workspaces (owner_id, name, is_personal)
+SELECT id, 'Personal', TRUE FROM users;
+
+INSERT INTO workspace_members (workspace_id, user_id, role)
+SELECT id, owner_id, 'owner' FROM workspaces WHERE is_personal;
+
+-- Wallets belong to a workspace
+ALTER TABLE wallets ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE;
+
+UPDATE wallets w
+SET workspace_id = ws.id
+FROM workspaces ws
+WHERE ws.owner_id = w.user_id AND ws.is_personal;
+
+ALTER TABLE wallets ALTER COLUMN workspace_id SET NOT NULL;
+
+CREATE INDEX idx_wallets_workspace ON wallets(workspace_id);
+
+-- Wallets inserted without a workspace land in the creator's personal workspace
+CREATE OR REPLACE FUNCTION assign_personal_workspace()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_workspace_id UUID;
+BEGIN
+    IF NEW.workspace_id IS NOT NULL THEN
+        RETURN NEW;
+    END IF;
+
+    SELECT id INTO v_workspace_id
+    FROM workspaces
+    WHERE owner_id = NEW.user_id AND is_personal;
+
+    IF v_workspace_id IS NULL THEN
+        INSERT INTO workspaces (owner_id, name, is_personal)
+        VALUES (NEW.user_id, 'Personal', TRUE)
+        ON CONFLICT (owner_id) WHERE is_personal DO NOTHING
+        RETURNING id INTO v_workspace_id;
+
+        IF v_workspace_id IS NULL THEN
+            -- Lost a race with a concurrent insert
+            SELECT id INTO v_workspace_id
+            FROM workspaces
+            WHERE owner_id = NEW.user_id AND is_personal;
+        ELSE
+            INSERT INTO workspace_members (workspace_id, user_id, role)
+            VALUES (v_workspace_id, NEW.user_id, 'owner');
+        END IF;
+    END IF;
+
+    NEW.workspace_id := v_workspace_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_wallets_assign_workspace
+    BEFORE INSERT ON wallets
+    FOR EACH ROW
+    EXECUTE FUNCTION assign_personal_workspace();
@@ -0,0 +1,18 @@
+CREATE TABLE share_links (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id          UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    label            VARCHAR(100) NOT NULL DEFAULT '',
+    scopes           TEXT[] NOT NULL,
+    wallet_ids       UUID[] NOT NULL DEFAULT '{}',
+    hide_amounts     BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at       TIMESTAMPTZ NOT NULL,
+    revoked_at       TIMESTAMPTZ,
+    last_accessed_at TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT share_links_scopes_check CHECK (
+        cardinality(scopes) > 0 AND scopes <@ ARRAY['portfolio', 'wallets', 'lots']::TEXT[]
+    )
+);
+
+CREATE INDEX idx_share_links_user ON share_links(user_id, created_at DESC);
@@ -0,0 +1,28 @@
+-- Append-only trail of user-initiated mutations.
+-- actor_id intentionally has no foreign key: entries outlive the account they describe.
+CREATE TABLE audit_log (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    actor_id    UUID NOT NULL,
+    action      VARCHAR(100) NOT NULL,
+    target_type VARCHAR(50) NOT NULL,
+    target_id   VARCHAR(255) NOT NULL DEFAULT '',
+    before      JSONB,
+    after       JSONB,
+    ip          VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent  TEXT NOT NULL DEFAULT '',
+    request_id  VARCHAR(100) NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_audit_log_actor ON audit_log(actor_id, created_at DESC);
+CREATE INDEX idx_audit_log_target ON audit_log(target_type, target_id);
+
+CREATE OR REPLACE FUNCTION audit_log_immutable() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_immutable();
@@ -0,0 +1,12 @@
+-- Allow account erasure to remove a user's audit trail. Deletes are only
+-- permitted when the deleting transaction opts in with
+--   SELECT set_config('moontrack.user_erasure', 'on', true)
+-- Updates remain forbidden.
+CREATE OR REPLACE FUNCTION audit_log_immutable() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND current_setting('moontrack.user_erasure', true) = 'on' THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
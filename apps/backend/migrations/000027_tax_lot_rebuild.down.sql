@@ -0,0 +1,4 @@
+ALTER TABLE tax_lots DROP CONSTRAINT tax_lots_linked_source_lot_id_fkey;
+ALTER TABLE tax_lots
+    ADD CONSTRAINT tax_lots_linked_source_lot_id_fkey
+    FOREIGN KEY (linked_source_lot_id) REFERENCES tax_lots(id);
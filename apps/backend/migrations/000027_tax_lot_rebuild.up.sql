@@ -0,0 +1,6 @@
+-- Rebuilding lots deletes and recreates them, so a transfer lot must not pin
+-- the source lot it was linked to. The link is cleared instead.
+ALTER TABLE tax_lots DROP CONSTRAINT tax_lots_linked_source_lot_id_fkey;
+ALTER TABLE tax_lots
+    ADD CONSTRAINT tax_lots_linked_source_lot_id_fkey
+    FOREIGN KEY (linked_source_lot_id) REFERENCES tax_lots(id) ON DELETE SET NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS transaction_notes;
+DROP TABLE IF EXISTS transaction_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,31 @@
+-- User-defined tags and notes on ledger transactions. Annotations are private
+-- to the user who made them and live outside the immutable ledger tables.
+CREATE TABLE tags (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name        VARCHAR(50) NOT NULL,
+    category    VARCHAR(50) NOT NULL DEFAULT '',
+    color       VARCHAR(7) NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX idx_tags_user_name ON tags (user_id, lower(name));
+
+CREATE TABLE transaction_tags (
+    transaction_id  UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    tag_id          UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (transaction_id, tag_id)
+);
+
+-- Tag filter and per-tag aggregation
+CREATE INDEX idx_transaction_tags_tag ON transaction_tags (tag_id, transaction_id);
+
+CREATE TABLE transaction_notes (
+    transaction_id  UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    user_id         UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body            TEXT NOT NULL CHECK (length(body) <= 5000),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (transaction_id, user_id)
+);
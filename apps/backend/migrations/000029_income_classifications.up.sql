@@ -0,0 +1,11 @@
+-- User classifications of acquisitions as taxable income. Reward claims are
+-- income by default; a row here overrides their category or marks another
+-- incoming transaction (e.g. an airdrop synced as transfer_in) as income.
+CREATE TABLE income_classifications (
+    transaction_id  UUID PRIMARY KEY REFERENCES transactions(id) ON DELETE CASCADE,
+    category        VARCHAR(20) NOT NULL
+                    CHECK (category IN ('reward', 'staking', 'airdrop', 'interest', 'salary')),
+    classified_by   UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
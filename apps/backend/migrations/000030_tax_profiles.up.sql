@@ -0,0 +1,9 @@
+-- Per-user tax jurisdiction. It selects the matching method (FIFO lots or
+-- UK Section 104 pooling), holding-period terms and tax year of the capital
+-- gains report. Users without a row report under the US rules.
+CREATE TABLE tax_profiles (
+    user_id       UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    jurisdiction  VARCHAR(2) NOT NULL CHECK (jurisdiction IN ('US', 'UK')),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
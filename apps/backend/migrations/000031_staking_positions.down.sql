@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS staking_positions;
+
+UPDATE lot_disposals SET disposal_type = 'internal_transfer'
+WHERE disposal_type = 'staking_transfer';
+UPDATE tax_lots SET auto_cost_basis_source = 'linked_transfer'
+WHERE auto_cost_basis_source = 'staking_carry_over';
+
+ALTER TABLE lot_disposals DROP CONSTRAINT IF EXISTS lot_disposals_disposal_type_check;
+ALTER TABLE lot_disposals ADD CONSTRAINT lot_disposals_disposal_type_check
+    CHECK (disposal_type IN ('sale', 'internal_transfer', 'gas_fee', 'lending_transfer'));
+
+ALTER TABLE tax_lots DROP CONSTRAINT IF EXISTS tax_lots_auto_cost_basis_source_check;
+ALTER TABLE tax_lots ADD CONSTRAINT tax_lots_auto_cost_basis_source_check
+    CHECK (auto_cost_basis_source IN (
+        'swap_price', 'fmv_at_transfer', 'linked_transfer', 'genesis_approximation',
+        'lending_carry_over'
+    ));
@@ -0,0 +1,51 @@
+-- Allow the lending and staking carry-over values written by the tax lot hook
+ALTER TABLE tax_lots DROP CONSTRAINT IF EXISTS tax_lots_auto_cost_basis_source_check;
+ALTER TABLE tax_lots ADD CONSTRAINT tax_lots_auto_cost_basis_source_check
+    CHECK (auto_cost_basis_source IN (
+        'swap_price', 'fmv_at_transfer', 'linked_transfer', 'genesis_approximation',
+        'lending_carry_over', 'staking_carry_over'
+    ));
+
+ALTER TABLE lot_disposals DROP CONSTRAINT IF EXISTS lot_disposals_disposal_type_check;
+ALTER TABLE lot_disposals ADD CONSTRAINT lot_disposals_disposal_type_check
+    CHECK (disposal_type IN ('sale', 'internal_transfer', 'gas_fee', 'lending_transfer', 'staking_transfer'));
+
+-- Create staking_positions table
+CREATE TABLE staking_positions (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id         UUID NOT NULL REFERENCES users(id),
+    wallet_id       UUID NOT NULL REFERENCES wallets(id),
+    chain_id        VARCHAR(50) NOT NULL,
+    protocol        VARCHAR(100) NOT NULL,
+
+    asset                VARCHAR(50) NOT NULL,
+    asset_decimals       SMALLINT NOT NULL DEFAULT 18,
+    staked_amount        NUMERIC(78,0) NOT NULL DEFAULT 0,
+
+    receipt_asset        VARCHAR(50),
+    receipt_decimals     SMALLINT NOT NULL DEFAULT 18,
+    receipt_contract     VARCHAR(255),
+    receipt_amount       NUMERIC(78,0) NOT NULL DEFAULT 0,
+    rebasing             BOOLEAN NOT NULL DEFAULT false,
+
+    total_staked         NUMERIC(78,0) NOT NULL DEFAULT 0,
+    total_unstaked       NUMERIC(78,0) NOT NULL DEFAULT 0,
+    total_staked_usd     NUMERIC(78,0) NOT NULL DEFAULT 0,
+    total_unstaked_usd   NUMERIC(78,0) NOT NULL DEFAULT 0,
+
+    rewards_amount       NUMERIC(78,0) NOT NULL DEFAULT 0,
+    rewards_usd          NUMERIC(78,0) NOT NULL DEFAULT 0,
+
+    status          VARCHAR(20) NOT NULL DEFAULT 'active',
+    opened_at       TIMESTAMPTZ NOT NULL,
+    closed_at       TIMESTAMPTZ,
+
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_staking_positions_user_id ON staking_positions(user_id);
+CREATE INDEX idx_staking_positions_wallet_id ON staking_positions(wallet_id);
+CREATE UNIQUE INDEX idx_staking_positions_unique_active
+    ON staking_positions(wallet_id, protocol, chain_id, asset)
+    WHERE status = 'active';
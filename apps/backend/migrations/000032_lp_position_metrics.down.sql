@@ -0,0 +1,9 @@
+ALTER TABLE lp_positions
+    DROP COLUMN IF EXISTS capital_usd,
+    DROP COLUMN IF EXISTS capital_usd_seconds,
+    DROP COLUMN IF EXISTS capital_updated_at,
+    DROP COLUMN IF EXISTS current_value_usd,
+    DROP COLUMN IF EXISTS unrealized_pnl_usd,
+    DROP COLUMN IF EXISTS fee_apr_bps,
+    DROP COLUMN IF EXISTS total_apr_bps,
+    DROP COLUMN IF EXISTS valued_at;
@@ -0,0 +1,10 @@
+-- Time-weighted capital and live metrics for open LP positions
+ALTER TABLE lp_positions
+    ADD COLUMN capital_usd          NUMERIC(78,0) NOT NULL DEFAULT 0,
+    ADD COLUMN capital_usd_seconds  NUMERIC(78,0) NOT NULL DEFAULT 0,
+    ADD COLUMN capital_updated_at   TIMESTAMPTZ,
+    ADD COLUMN current_value_usd    NUMERIC(78,0),
+    ADD COLUMN unrealized_pnl_usd   NUMERIC(78,0),
+    ADD COLUMN fee_apr_bps          INTEGER,
+    ADD COLUMN total_apr_bps        INTEGER,
+    ADD COLUMN valued_at            TIMESTAMPTZ;
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_lp_positions_out_of_range;
+
+ALTER TABLE lp_positions
+    DROP COLUMN IF EXISTS tick_lower,
+    DROP COLUMN IF EXISTS tick_upper,
+    DROP COLUMN IF EXISTS current_tick,
+    DROP COLUMN IF EXISTS in_range,
+    DROP COLUMN IF EXISTS uncollected_fees_token0,
+    DROP COLUMN IF EXISTS uncollected_fees_token1,
+    DROP COLUMN IF EXISTS range_updated_at;
@@ -0,0 +1,11 @@
+-- On-chain range state and uncollected fees for NFT LP positions
+ALTER TABLE lp_positions
+    ADD COLUMN tick_lower              INTEGER,
+    ADD COLUMN tick_upper              INTEGER,
+    ADD COLUMN current_tick            INTEGER,
+    ADD COLUMN in_range                BOOLEAN,
+    ADD COLUMN uncollected_fees_token0 NUMERIC(78,0),
+    ADD COLUMN uncollected_fees_token1 NUMERIC(78,0),
+    ADD COLUMN range_updated_at        TIMESTAMPTZ;
+
+CREATE INDEX idx_lp_positions_out_of_range ON lp_positions(user_id) WHERE status = 'open' AND in_range = FALSE;
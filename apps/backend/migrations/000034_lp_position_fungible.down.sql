@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_lp_positions_open_contract;
@@ -0,0 +1,4 @@
+-- Fungible pools (Uniswap V2, Curve, Balancer, ...) are keyed by LP token
+-- contract: at most one open position per wallet and pool
+CREATE UNIQUE INDEX idx_lp_positions_open_contract ON lp_positions(wallet_id, chain_id, contract_address)
+    WHERE nft_token_id IS NULL AND contract_address IS NOT NULL AND status = 'open';
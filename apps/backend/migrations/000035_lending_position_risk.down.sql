@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_lending_positions_at_risk;
+
+ALTER TABLE lending_positions
+    DROP COLUMN IF EXISTS interest_paid_usd,
+    DROP COLUMN IF EXISTS supply_value_usd,
+    DROP COLUMN IF EXISTS borrow_value_usd,
+    DROP COLUMN IF EXISTS liquidation_threshold_bps,
+    DROP COLUMN IF EXISTS ltv_bps,
+    DROP COLUMN IF EXISTS health_factor,
+    DROP COLUMN IF EXISTS liquidation_price_usd,
+    DROP COLUMN IF EXISTS risk_updated_at;
@@ -0,0 +1,13 @@
+-- Accrued borrow interest and liquidation risk for lending positions
+ALTER TABLE lending_positions
+    ADD COLUMN interest_paid_usd         NUMERIC(78,0) NOT NULL DEFAULT 0,
+    ADD COLUMN supply_value_usd          NUMERIC(78,0),
+    ADD COLUMN borrow_value_usd          NUMERIC(78,0),
+    ADD COLUMN liquidation_threshold_bps INTEGER,
+    ADD COLUMN ltv_bps                   INTEGER,
+    ADD COLUMN health_factor             NUMERIC(78,0),
+    ADD COLUMN liquidation_price_usd     NUMERIC(78,0),
+    ADD COLUMN risk_updated_at           TIMESTAMPTZ;
+
+-- health_factor is scaled by 1e4; positions below 1.1 are close to liquidation
+CREATE INDEX idx_lending_positions_at_risk ON lending_positions(user_id) WHERE status = 'active' AND health_factor < 11000;
@@ -0,0 +1,45 @@
+-- Best effort: each account keeps only its earliest supply and borrow leg
+ALTER TABLE lending_positions
+    ADD COLUMN supply_asset          VARCHAR(50),
+    ADD COLUMN supply_amount         NUMERIC(78,0) NOT NULL DEFAULT 0,
+    ADD COLUMN supply_decimals       SMALLINT NOT NULL DEFAULT 18,
+    ADD COLUMN supply_contract       VARCHAR(255),
+    ADD COLUMN borrow_asset          VARCHAR(50),
+    ADD COLUMN borrow_amount         NUMERIC(78,0) NOT NULL DEFAULT 0,
+    ADD COLUMN borrow_decimals       SMALLINT NOT NULL DEFAULT 18,
+    ADD COLUMN borrow_contract       VARCHAR(255),
+    ADD COLUMN total_supplied        NUMERIC(78,0) NOT NULL DEFAULT 0,
+    ADD COLUMN total_withdrawn       NUMERIC(78,0) NOT NULL DEFAULT 0,
+    ADD COLUMN total_borrowed        NUMERIC(78,0) NOT NULL DEFAULT 0,
+    ADD COLUMN total_repaid          NUMERIC(78,0) NOT NULL DEFAULT 0,
+    ADD COLUMN liquidation_price_usd NUMERIC(78,0);
+
+UPDATE lending_positions p SET
+    supply_asset = l.asset, supply_amount = l.amount, supply_decimals = l.decimals,
+    supply_contract = l.contract, total_supplied = l.total_in, total_withdrawn = l.total_out,
+    liquidation_price_usd = l.liquidation_price_usd
+FROM (
+    SELECT DISTINCT ON (position_id) *
+    FROM lending_position_legs
+    WHERE side = 'supply'
+    ORDER BY position_id, created_at, id
+) l
+WHERE l.position_id = p.id;
+
+UPDATE lending_positions p SET
+    borrow_asset = l.asset, borrow_amount = l.amount, borrow_decimals = l.decimals,
+    borrow_contract = l.contract, total_borrowed = l.total_in, total_repaid = l.total_out
+FROM (
+    SELECT DISTINCT ON (position_id) *
+    FROM lending_position_legs
+    WHERE side = 'borrow'
+    ORDER BY position_id, created_at, id
+) l
+WHERE l.position_id = p.id;
+
+DROP INDEX IF EXISTS idx_lending_positions_unique_active;
+CREATE UNIQUE INDEX idx_lending_positions_unique_active
+    ON lending_positions(wallet_id, protocol, chain_id, supply_asset, borrow_asset)
+    WHERE status = 'active';
+
+DROP TABLE IF EXISTS lending_position_legs;
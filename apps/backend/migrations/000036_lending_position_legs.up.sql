@@ -0,0 +1,167 @@
+-- A lending position becomes an account per wallet, protocol and chain holding
+-- any number of supplied (collateral) and borrowed (debt) assets, one leg each
+CREATE TABLE lending_position_legs (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    position_id     UUID NOT NULL REFERENCES lending_positions(id) ON DELETE CASCADE,
+    side            VARCHAR(10) NOT NULL CHECK (side IN ('supply', 'borrow')),
+    asset           VARCHAR(50) NOT NULL,
+    decimals        SMALLINT NOT NULL DEFAULT 18,
+    contract        VARCHAR(255),
+
+    amount          NUMERIC(78,0) NOT NULL DEFAULT 0,
+    total_in        NUMERIC(78,0) NOT NULL DEFAULT 0,
+    total_out       NUMERIC(78,0) NOT NULL DEFAULT 0,
+    total_in_usd    NUMERIC(78,0) NOT NULL DEFAULT 0,
+    total_out_usd   NUMERIC(78,0) NOT NULL DEFAULT 0,
+
+    interest        NUMERIC(78,0) NOT NULL DEFAULT 0,
+    interest_usd    NUMERIC(78,0) NOT NULL DEFAULT 0,
+
+    usd_price             NUMERIC(78,0),
+    value_usd             NUMERIC(78,0),
+    liquidation_price_usd NUMERIC(78,0),
+
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_lending_position_legs_position_id ON lending_position_legs(position_id);
+
+-- Each single-pair row becomes a supply leg and, when it borrowed, a borrow leg.
+-- Accrued interest was folded into the balance, so a leg's interest is the
+-- balance beyond its net flows; interest_earned_usd (claims included, the old
+-- row did not split them) and interest_paid_usd carry its USD value
+INSERT INTO lending_position_legs (
+    position_id, side, asset, decimals, contract,
+    amount, total_in, total_out, total_in_usd, total_out_usd,
+    interest, interest_usd,
+    liquidation_price_usd, created_at, updated_at
+)
+SELECT id, 'supply', supply_asset, supply_decimals, supply_contract,
+       supply_amount, total_supplied, total_withdrawn, total_supplied_usd, total_withdrawn_usd,
+       GREATEST(supply_amount - total_supplied + total_withdrawn, 0), interest_earned_usd,
+       liquidation_price_usd, created_at, updated_at
+FROM lending_positions
+WHERE supply_asset IS NOT NULL;
+
+INSERT INTO lending_position_legs (
+    position_id, side, asset, decimals, contract,
+    amount, total_in, total_out, total_in_usd, total_out_usd,
+    interest, interest_usd,
+    created_at, updated_at
+)
+SELECT id, 'borrow', borrow_asset, borrow_decimals, borrow_contract,
+       borrow_amount, total_borrowed, total_repaid, total_borrowed_usd, total_repaid_usd,
+       GREATEST(borrow_amount - total_borrowed + total_repaid, 0), interest_paid_usd,
+       created_at, updated_at
+FROM lending_positions
+WHERE borrow_asset IS NOT NULL;
+
+-- Active rows of the same wallet, protocol and chain merge into the earliest
+CREATE TEMP TABLE lending_position_merge AS
+SELECT id, first_value(id) OVER (
+           PARTITION BY wallet_id, protocol, chain_id
+           ORDER BY opened_at, created_at, id
+       ) AS keep_id
+FROM lending_positions
+WHERE status = 'active';
+
+DELETE FROM lending_position_merge WHERE id = keep_id;
+
+UPDATE lending_positions p SET
+    opened_at           = LEAST(p.opened_at, s.opened_at),
+    total_supplied_usd  = p.total_supplied_usd + s.total_supplied_usd,
+    total_withdrawn_usd = p.total_withdrawn_usd + s.total_withdrawn_usd,
+    total_borrowed_usd  = p.total_borrowed_usd + s.total_borrowed_usd,
+    total_repaid_usd    = p.total_repaid_usd + s.total_repaid_usd,
+    interest_earned_usd = p.interest_earned_usd + s.interest_earned_usd,
+    interest_paid_usd   = p.interest_paid_usd + s.interest_paid_usd,
+    updated_at          = now()
+FROM (
+    SELECT m.keep_id,
+           MIN(lp.opened_at) AS opened_at,
+           SUM(lp.total_supplied_usd) AS total_supplied_usd,
+           SUM(lp.total_withdrawn_usd) AS total_withdrawn_usd,
+           SUM(lp.total_borrowed_usd) AS total_borrowed_usd,
+           SUM(lp.total_repaid_usd) AS total_repaid_usd,
+           SUM(lp.interest_earned_usd) AS interest_earned_usd,
+           SUM(lp.interest_paid_usd) AS interest_paid_usd
+    FROM lending_positions lp
+    JOIN lending_position_merge m ON m.id = lp.id
+    GROUP BY m.keep_id
+) s
+WHERE p.id = s.keep_id;
+
+UPDATE lending_position_legs l SET position_id = m.keep_id
+FROM lending_position_merge m
+WHERE l.position_id = m.id;
+
+DELETE FROM lending_positions WHERE id IN (SELECT id FROM lending_position_merge);
+
+-- Legs of the same asset on the same side collapse into one
+CREATE TEMP TABLE lending_leg_merge AS
+SELECT id, first_value(id) OVER (
+           PARTITION BY position_id, side, UPPER(asset)
+           ORDER BY created_at, id
+       ) AS keep_id
+FROM lending_position_legs;
+
+UPDATE lending_position_legs l SET
+    contract      = COALESCE(l.contract, s.contract),
+    amount        = s.amount,
+    total_in      = s.total_in,
+    total_out     = s.total_out,
+    total_in_usd  = s.total_in_usd,
+    total_out_usd = s.total_out_usd,
+    interest      = s.interest,
+    interest_usd  = s.interest_usd,
+    updated_at    = now()
+FROM (
+    SELECT m.keep_id,
+           MAX(x.contract) AS contract,
+           SUM(x.amount) AS amount,
+           SUM(x.total_in) AS total_in,
+           SUM(x.total_out) AS total_out,
+           SUM(x.total_in_usd) AS total_in_usd,
+           SUM(x.total_out_usd) AS total_out_usd,
+           SUM(x.interest) AS interest,
+           SUM(x.interest_usd) AS interest_usd
+    FROM lending_position_legs x
+    JOIN lending_leg_merge m ON m.id = x.id
+    GROUP BY m.keep_id
+    HAVING COUNT(*) > 1
+) s
+WHERE l.id = s.keep_id;
+
+DELETE FROM lending_position_legs
+WHERE id IN (SELECT id FROM lending_leg_merge WHERE id <> keep_id);
+
+-- Merged accounts are repriced on the next sync
+UPDATE lending_positions SET
+    supply_value_usd = NULL, borrow_value_usd = NULL,
+    liquidation_threshold_bps = NULL, ltv_bps = NULL,
+    health_factor = NULL, risk_updated_at = NULL
+WHERE id IN (SELECT keep_id FROM lending_position_merge);
+
+DROP INDEX IF EXISTS idx_lending_positions_unique_active;
+CREATE UNIQUE INDEX idx_lending_positions_unique_active
+    ON lending_positions(wallet_id, protocol, chain_id)
+    WHERE status = 'active';
+
+ALTER TABLE lending_positions
+    DROP COLUMN supply_asset,
+    DROP COLUMN supply_amount,
+    DROP COLUMN supply_decimals,
+    DROP COLUMN supply_contract,
+    DROP COLUMN borrow_asset,
+    DROP COLUMN borrow_amount,
+    DROP COLUMN borrow_decimals,
+    DROP COLUMN borrow_contract,
+    DROP COLUMN total_supplied,
+    DROP COLUMN total_withdrawn,
+    DROP COLUMN total_borrowed,
+    DROP COLUMN total_repaid,
+    DROP COLUMN liquidation_price_usd;
+
+DROP TABLE lending_leg_merge;
+DROP TABLE lending_position_merge;
@@ -0,0 +1,11 @@
+ALTER TABLE lending_positions
+    DROP COLUMN IF EXISTS total_liquidated_usd,
+    DROP COLUMN IF EXISTS liquidation_penalty_usd,
+    DROP COLUMN IF EXISTS last_liquidated_at;
+
+UPDATE lot_disposals SET disposal_type = 'sale'
+WHERE disposal_type = 'liquidation';
+
+ALTER TABLE lot_disposals DROP CONSTRAINT IF EXISTS lot_disposals_disposal_type_check;
+ALTER TABLE lot_disposals ADD CONSTRAINT lot_disposals_disposal_type_check
+    CHECK (disposal_type IN ('sale', 'internal_transfer', 'gas_fee', 'lending_transfer', 'staking_transfer'));
@@ -0,0 +1,10 @@
+-- Collateral seized in a liquidation is disposed of as a taxable sale
+ALTER TABLE lot_disposals DROP CONSTRAINT IF EXISTS lot_disposals_disposal_type_check;
+ALTER TABLE lot_disposals ADD CONSTRAINT lot_disposals_disposal_type_check
+    CHECK (disposal_type IN ('sale', 'internal_transfer', 'gas_fee', 'lending_transfer', 'staking_transfer', 'liquidation'));
+
+-- Liquidations booked against lending positions
+ALTER TABLE lending_positions
+    ADD COLUMN total_liquidated_usd    NUMERIC(78,0) NOT NULL DEFAULT 0,
+    ADD COLUMN liquidation_penalty_usd NUMERIC(78,0) NOT NULL DEFAULT 0,
+    ADD COLUMN last_liquidated_at      TIMESTAMPTZ;
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -31,6 +32,13 @@ type Config struct {
 
 	// Zerion API configuration (for blockchain sync and DeFi data)
 	ZerionAPIKey string
+
+	// EVM JSON-RPC endpoints keyed by chain, e.g. "ethereum=https://...,base=https://..."
+	EVMRPCURLs map[string]string
+
+	// Reverse proxies allowed to set X-Forwarded-For, as IPs or CIDR ranges,
+	// e.g. "10.0.0.0/8,172.16.0.1"
+	TrustedProxies []string
 }
 
 // Load loads configuration from environment variables
@@ -45,6 +53,8 @@ func Load() (*Config, error) {
 		CoinGeckoAPIKey:  getEnv("COINGECKO_API_KEY", ""),
 		SyncPollInterval: getEnvAsDuration("SYNC_POLL_INTERVAL", 5*time.Minute),
 		ZerionAPIKey:     getEnv("ZERION_API_KEY", ""),
+		EVMRPCURLs:       getEnvAsMap("EVM_RPC_URLS"),
+		TrustedProxies:   getEnvAsList("TRUSTED_PROXIES"),
 	}
 
 	// Validate required configuration
@@ -124,3 +134,28 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getEnvAsMap parses a comma-separated list of key=value pairs; malformed
+// entries are skipped
+func getEnvAsMap(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || k == "" || v == "" {
+			continue
+		}
+		result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return result
+}
+
+// getEnvAsList parses a comma-separated list, skipping empty entries
+func getEnvAsList(key string) []string {
+	var result []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -5,6 +5,10 @@ import "strings"
 // GetDecimals returns the number of decimal places for an asset by symbol or CoinGecko ID.
 // Returns 8 as default for unknown assets.
 func GetDecimals(assetID string) int {
+	// NFT asset IDs (NFT:{chain}:{contract}:{token_id}) count whole tokens
+	if strings.HasPrefix(assetID, "NFT:") {
+		return 0
+	}
 	if d, ok := knownDecimals[strings.ToLower(assetID)]; ok {
 		return d
 	}
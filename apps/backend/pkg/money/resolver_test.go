@@ -154,3 +154,8 @@ func TestCacheKey_UpperCasesSymbol(t *testing.T) {
 	require.Equal(t, "ETH", cacheKey("ETH", ""))
 	require.Equal(t, "ETH:ethereum", cacheKey("eth", "ethereum"))
 }
+
+func TestResolve_NFTAssetHasZeroDecimals(t *testing.T) {
+	r := NewDecimalResolver(newMockSource(map[string]int{}))
+	assert.Equal(t, 0, r.Resolve(context.Background(), "NFT:ethereum:0xabc:1", "ethereum"))
+}
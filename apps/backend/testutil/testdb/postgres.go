@@ -86,6 +86,7 @@ func NewTestDB(ctx context.Context) (*TestDB, error) {
 func (db *TestDB) Reset(ctx context.Context) error {
 	// Truncate tables in reverse dependency order
 	tables := []string{
+		"audit_log",
 		"account_balances",
 		"entries",
 		"transactions",